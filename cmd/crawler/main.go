@@ -5,6 +5,9 @@ import (
 	"os"
 
 	"github.com/masahif/linktadoru/internal/cmd"
+	// Storage backends selectable with storage_driver
+	_ "github.com/masahif/linktadoru/internal/storage/memory"
+	_ "github.com/masahif/linktadoru/internal/storage/redis"
 )
 
 // Version information set by build flags
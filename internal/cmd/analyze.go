@@ -0,0 +1,1078 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// analyzeCmd groups reports computed from an existing crawl database
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Analyze the results of a crawl",
+}
+
+// analyzeBrokenLinksCmd lists broken link targets with the links pointing at them
+var analyzeBrokenLinksCmd = &cobra.Command{
+	Use:   "broken-links",
+	Short: "List broken link targets with every page and anchor text linking to them",
+	Long: `List the URLs of the crawled site that answered with status 400 or above
+or could not be fetched, with every page linking to each and the anchor text
+used, so the links can be fixed at their source. Targets with the most links
+come first.
+
+Table and CSV output have one row per link; JSON output has one object per
+target with its list of sources. Links to other sites, as verified by
+--check-external or the check command, are included with --include-external.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeBrokenLinks,
+}
+
+// analyzeFeedsCmd lists pages exposing RSS/Atom/JSON feeds
+var analyzeFeedsCmd = &cobra.Command{
+	Use:   "feeds",
+	Short: "List pages exposing syndication feeds via <link rel=\"alternate\">",
+	Args:  cobra.NoArgs,
+	RunE:  runAnalyzeFeeds,
+}
+
+// analyzeHostsCmd summarizes pages per host or registrable domain
+var analyzeHostsCmd = &cobra.Command{
+	Use:   "hosts",
+	Short: "Summarize crawled pages per registrable domain (or per host)",
+	Long: `Summarize crawled pages per registrable domain, so all subdomains of
+example.com are reported together. Domains are determined with the public
+suffix list (shop.example.co.uk groups under example.co.uk). Use
+--group-by host for one row per host name.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeHosts,
+}
+
+// analyzeSchemaCmd lists HTML pages lacking expected structured data types
+var analyzeSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "List HTML pages missing expected structured data (JSON-LD / microdata) types",
+	Long: `List HTML pages that do not declare every type given with --expect, such
+as Product or BreadcrumbList, together with the types they do declare. Types
+are matched without the schema.org prefix, from JSON-LD blocks and top-level
+microdata items. Without --expect, pages declaring no structured data at all
+are listed.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeSchema,
+}
+
+// analyzeImagesCmd audits image alt text and sizes
+var analyzeImagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "List images with missing or empty alt text, or over the size limits",
+	Long: `List <img> elements with a missing alt attribute (missing_alt), an empty
+one (empty_alt, acceptable for decorative images) or a size over the limits
+(oversized). --max-bytes applies to images whose size is known because the
+crawl fetched them; --max-dimension applies to the declared width and height.
+A limit of 0 disables that check.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeImages,
+}
+
+// analyzePageRankCmd computes and stores PageRank over the internal link graph
+var analyzePageRankCmd = &cobra.Command{
+	Use:   "pagerank",
+	Short: "Compute PageRank over internal links and list pages by score",
+	Long: `Compute PageRank over the internal links of the crawl to show how link
+equity flows through the site. Links marked rel="nofollow" pass no equity.
+Scores sum to 1 over all pages and are stored in the page_metrics table,
+replacing earlier scores, so they can be joined with other page data:
+
+  SELECT p.url, m.pagerank FROM page_metrics m JOIN pages p ON p.id = m.page_id
+
+The report lists pages by score with their count of followed internal inlinks.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzePageRank,
+}
+
+// analyzeCanonicalsCmd lists canonical URLs that redirect, fail or chain
+var analyzeCanonicalsCmd = &cobra.Command{
+	Use:   "canonicals",
+	Short: "List canonical URLs that redirect, are broken or chain to another canonical",
+	Long: `List crawled pages whose canonical URL is not a final, working page:
+
+  redirects  the canonical URL redirects; TARGET is where the redirects end
+  broken     the canonical URL answered 4xx/5xx or could not be fetched
+  chain      the canonical page declares yet another canonical (A -> B -> C);
+             TARGET is that URL
+
+A page appears once per issue. Canonical URLs the crawl did not fetch are not
+judged; crawl with --follow-canonical to fetch them.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeCanonicals,
+}
+
+// analyzeDepthCmd computes click depths and lists pages buried too deep
+var analyzeDepthCmd = &cobra.Command{
+	Use:   "depth",
+	Short: "Compute click depth from the seed URLs and list pages deeper than --max-depth",
+	Long: `Compute the click depth of every page, the fewest links followed from a
+seed URL to reach it, by a breadth-first search over the stored link graph
+(asset links excluded). Depths are stored in page_metrics.click_depth,
+replacing earlier ones; -1 marks pages no seed URL links to.
+
+The report lists crawled and queued pages deeper than --max-depth, deepest
+first, followed by the unreachable ones. Depth is counted from the seed URLs
+recorded by the crawls on the database, or from the --root URLs.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeDepth,
+}
+
+// analyzeOrphansCmd compares sitemaps with the link graph
+var analyzeOrphansCmd = &cobra.Command{
+	Use:   "orphans",
+	Short: "Compare sitemaps with the link graph to find orphan and unlisted pages",
+	Long: `Compare the URLs listed in sitemaps with the internal links of the crawl.
+
+  orphan          listed, but no other crawled page links to it (including
+                  listed URLs the crawl never found)
+  not_in_sitemap  a crawled 2xx HTML page the sitemaps do not list
+
+Each --sitemap is a local file or http(s) URL holding an XML sitemap, a
+sitemap index (its child sitemaps are read too) or a plain-text list of URLs,
+one per line; gzip-compressed files are read as well. Listed URLs are
+normalized the way the crawler stores them, using the configured URL
+normalization rules.`,
+	Example: `  linktadoru analyze orphans --sitemap https://example.com/sitemap.xml
+  linktadoru analyze orphans --sitemap urls.txt --format csv`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeOrphans,
+}
+
+// analyzeRedirectsCmd lists redirect chains that loop or have too many hops
+var analyzeRedirectsCmd = &cobra.Command{
+	Use:   "redirects",
+	Short: "List redirect chains that loop or are longer than --max-hops",
+	Long: `List the redirect chains followed while crawling that are worth fixing:
+
+  loop      the chain redirects back to a URL already in it (the crawler
+            stops once a redirect repeats)
+  too_long  the chain has more than --max-hops redirects; links should point
+            at the final URL directly
+
+CHAIN shows every hop as the redirect status followed by the URL it points
+at. --all lists every chain, flagged or not. The hops are stored in the
+redirects table.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeRedirects,
+}
+
+// analyzeAccessibilityCmd summarizes the accessibility issues found while crawling
+var analyzeAccessibilityCmd = &cobra.Command{
+	Use:   "accessibility",
+	Short: "Summarize accessibility issues found in page HTML (crawl with --accessibility-checks)",
+	Long: `Summarize the accessibility issues found in the static HTML of the pages of
+a crawl run with accessibility_checks on, as the number of pages and elements
+with each issue:
+
+  missing_alt    <img> without an alt attribute
+  missing_label  form control without a <label>, aria-label, aria-labelledby
+                 or title
+  empty_link     link without text, an image with alt text or an ARIA name
+  empty_button   button without text, a value or an ARIA name
+  missing_lang   <html> without a lang attribute
+  heading_jump   heading more than one level below the previous one (h2 to h4)
+
+--details lists every issue with its page and element instead, optionally
+only those of the kinds given with --issue. These checks need no browser and
+are a first pass only: contrast, focus order and ARIA roles are not checked.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeAccessibility,
+}
+
+// analyzeCertificatesCmd lists TLS certificates that expire soon
+var analyzeCertificatesCmd = &cobra.Command{
+	Use:   "certificates",
+	Short: "List TLS certificates of crawled hosts expiring within --days",
+	Long: `List the TLS certificates of the HTTPS hosts contacted while crawling that
+expire within --days days, expired ones included, soonest first. DAYS_LEFT is
+negative once a certificate has expired. --all lists every certificate.
+
+Each host's leaf certificate is recorded with the first page fetched from it
+and again when it changes, in the tls_certificates table.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeCertificates,
+}
+
+// analyzeLanguageCmd compares declared and detected page languages
+var analyzeLanguageCmd = &cobra.Command{
+	Use:   "language",
+	Short: "List pages whose <html lang> is missing or differs from the detected language",
+	Long: `Compare the language each HTML page declares in <html lang> with the
+language detected from its main text:
+
+  missing_lang  the page declares no language
+  mismatch      the declared primary language (en for en-US) is not the
+                detected one
+
+LANGUAGE is the ISO 639-1 code of the detected language, empty when the text
+is too short or mixed to tell. Chinese, Japanese, Korean, Russian, Ukrainian,
+Greek, Arabic, Persian, Hebrew, Thai and Hindi are told apart by script;
+English, German, French, Spanish, Italian, Portuguese, Dutch, Swedish, Polish,
+Turkish and Indonesian by their most frequent words. --all lists every page.
+Pages crawled before languages were stored need to be crawled again.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeLanguage,
+}
+
+// analyzeDuplicatesCmd groups pages sharing a title, description or content hash
+var analyzeDuplicatesCmd = &cobra.Command{
+	Use:   "duplicates",
+	Short: "Group pages sharing a title, meta description or content hash",
+	Long: `Group the successfully crawled pages that share a title, a meta
+description or a content hash. Pages with the same title or description
+compete with each other in search results; pages with the same content hash
+are byte-identical copies served under several URLs.
+
+Each group is reported with its page count and up to --examples of its URLs.
+Titles and descriptions of an encrypted database are compared decrypted. Use
+` + "`analyze near-duplicates`" + ` to find pages whose text is only nearly the same.`,
+	Example: `  linktadoru analyze duplicates
+  linktadoru analyze duplicates --by title,meta_description --examples 0 --format csv`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeDuplicates,
+}
+
+// analyzeNearDuplicatesCmd clusters pages with near-identical text
+var analyzeNearDuplicatesCmd = &cobra.Command{
+	Use:   "near-duplicates",
+	Short: "Cluster pages whose main text is identical or nearly so",
+	Long: `Cluster the completed HTML pages whose main text is the same or nearly the
+same, such as copies of a page that differ only by a timestamp, a session
+token in the URL or a few changed words, which an exact content_hash misses.
+
+Each page stores a 64-bit SimHash of the 3-word sequences of its main text
+(navigation, headers, footers and scripts left out). Pages whose hashes differ
+in at most --max-distance bits are near duplicates; a cluster holds the pages
+linked by such pairs. DISTANCE is the number of bits a page's hash differs
+from that of the cluster's first page. Pages with fewer than --min-words words
+are left out, since short texts collide easily. Pages crawled before
+SimHashes were stored need to be crawled again.`,
+	Example: `  linktadoru analyze near-duplicates
+  linktadoru analyze near-duplicates --max-distance 0 --format csv`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeNearDuplicates,
+}
+
+// analyzePerformanceCmd reports response time percentiles
+var analyzePerformanceCmd = &cobra.Command{
+	Use:   "performance",
+	Short: "Report TTFB and download time percentiles per host or section",
+	Long: `Report the 50th, 90th and 99th percentiles of the time to first byte and
+the download time of the completed pages, in milliseconds. The first row
+covers the whole crawl and is followed by one row per host, or with
+--group-by section per host and first path segment (example.com/blog/), most
+pages first. Percentiles use the nearest-rank method.
+
+--slowest N lists the N pages with the longest time to first byte plus
+download time instead.`,
+	Example: `  linktadoru analyze performance --group-by section
+  linktadoru analyze performance --slowest 20 --format csv`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzePerformance,
+}
+
+// analyzeWeightCmd lists pages exceeding the page weight budgets
+var analyzeWeightCmd = &cobra.Command{
+	Use:   "weight",
+	Short: "List pages whose HTML and assets exceed the page weight budgets (crawl with --crawl-assets)",
+	Long: `List the completed HTML pages of a crawl run with crawl_assets on whose
+weight exceeds a budget, heaviest first. A page's weight is the size of its
+HTML plus the sizes of the stylesheets, scripts, images and icons it loads,
+each asset counted once:
+
+  total       HTML and assets together (--page-weight-budget)
+  script      scripts (--script-weight-budget)
+  stylesheet  stylesheets (--stylesheet-weight-budget)
+  image       images and icons (--image-weight-budget)
+
+Budgets are in bytes and default to the page_weight_budget,
+script_weight_budget, stylesheet_weight_budget and image_weight_budget
+settings; 0 means no budget. UNMEASURED counts the assets without a recorded
+size, such as failed or out-of-scope ones, which add nothing to the weight.
+--all lists every page. With --fail-on-budget the command exits with a
+non-zero status when any page exceeds a budget, for CI pipelines.`,
+	Example: `  linktadoru analyze weight --page-weight-budget 2000000 --script-weight-budget 500000
+  linktadoru analyze weight --all --format csv`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyzeWeight,
+}
+
+// --group-by values for analyze hosts
+const (
+	groupByDomain = "domain"
+	groupByHost   = "host"
+
+	// groupBySection groups analyze performance rows by host and first path segment
+	groupBySection = "section"
+)
+
+func init() {
+	analyzeCmd.PersistentFlags().StringP("database", "d", "./linktadoru.db", "Path to SQLite database file")
+	analyzeCmd.PersistentFlags().String("results-database", "", "Path to the separate results database, if the crawl used one")
+	analyzeCmd.PersistentFlags().String("format", formatTable, "Output format: table, csv or json")
+	analyzeHostsCmd.Flags().String("group-by", groupByDomain, "Group rows by registrable 'domain' or by 'host'")
+	analyzeSchemaCmd.Flags().StringSlice("expect", []string{}, "Structured data types every page should declare, e.g. 'Product,BreadcrumbList'")
+	analyzeImagesCmd.Flags().Int64("max-bytes", 200*1024, "Report crawled images larger than this many bytes (0=disabled)")
+	analyzeImagesCmd.Flags().Int("max-dimension", 2560, "Report images declaring a width or height above this many pixels (0=disabled)")
+	analyzePageRankCmd.Flags().Float64("damping", 0.85, "Probability of following a link rather than jumping to a random page")
+	analyzePageRankCmd.Flags().Int("iterations", 100, "Maximum number of power iterations")
+	analyzePageRankCmd.Flags().Int("limit", 0, "List only the N highest-ranked pages (0=all)")
+	analyzeDepthCmd.Flags().Int("max-depth", 3, "Report pages more than this many clicks from a seed URL")
+	analyzeDepthCmd.Flags().StringSlice("root", []string{}, "URLs depth is counted from (default: the crawl's seed URLs)")
+	analyzeOrphansCmd.Flags().StringSlice("sitemap", []string{}, "Sitemap or URL list files or URLs to compare with the crawl (required)")
+	_ = analyzeOrphansCmd.MarkFlagRequired("sitemap")
+	analyzeRedirectsCmd.Flags().Int("max-hops", 1, "Flag chains with more than this many redirects")
+	analyzeRedirectsCmd.Flags().Bool("all", false, "List every redirect chain, not only flagged ones")
+	analyzeBrokenLinksCmd.Flags().Bool("include-external", false, "Also list broken links to other sites")
+	analyzeAccessibilityCmd.Flags().Bool("details", false, "List every issue with its page and element instead of the summary")
+	analyzeAccessibilityCmd.Flags().StringSlice("issue", []string{}, "With --details, list only these issues, e.g. 'missing_alt,empty_link'")
+	analyzeCertificatesCmd.Flags().Int("days", 30, "List certificates expiring within this many days")
+	analyzeCertificatesCmd.Flags().Bool("all", false, "List every certificate, not only expiring ones")
+	analyzeLanguageCmd.Flags().Bool("all", false, "List every page, not only those with an issue")
+	analyzeDuplicatesCmd.Flags().StringSlice("by", storage.DuplicateFields, "Values to group pages by: "+strings.Join(storage.DuplicateFields, ", "))
+	analyzeDuplicatesCmd.Flags().Int("examples", 3, "Most example URLs listed per group (0=all)")
+	analyzeNearDuplicatesCmd.Flags().Int("max-distance", 3, "Most SimHash bits near duplicates may differ in (0-63; 0=identical text)")
+	analyzeNearDuplicatesCmd.Flags().Int("min-words", 50, "Leave out pages with fewer words in their main text")
+	analyzePerformanceCmd.Flags().String("group-by", groupByHost, "Group rows by 'host' or by 'section' (host and first path segment)")
+	analyzePerformanceCmd.Flags().Int("slowest", 0, "List the N slowest pages instead of percentiles (0=disabled)")
+	analyzeWeightCmd.Flags().Int64("page-weight-budget", 0, "Flag pages whose HTML and assets exceed this many bytes (default: page_weight_budget)")
+	analyzeWeightCmd.Flags().Int64("script-weight-budget", 0, "Flag pages whose scripts exceed this many bytes (default: script_weight_budget)")
+	analyzeWeightCmd.Flags().Int64("stylesheet-weight-budget", 0, "Flag pages whose stylesheets exceed this many bytes (default: stylesheet_weight_budget)")
+	analyzeWeightCmd.Flags().Int64("image-weight-budget", 0, "Flag pages whose images and icons exceed this many bytes (default: image_weight_budget)")
+	analyzeWeightCmd.Flags().Bool("all", false, "List every page, not only those over a budget")
+	analyzeWeightCmd.Flags().Bool("fail-on-budget", false, "Exit with a non-zero status when a page exceeds a budget (default: fail_on_budget)")
+	analyzeCmd.AddCommand(analyzeAccessibilityCmd)
+	analyzeCmd.AddCommand(analyzeBrokenLinksCmd)
+	analyzeCmd.AddCommand(analyzeCanonicalsCmd)
+	analyzeCmd.AddCommand(analyzeCertificatesCmd)
+	analyzeCmd.AddCommand(analyzeDepthCmd)
+	analyzeCmd.AddCommand(analyzeDuplicatesCmd)
+	analyzeCmd.AddCommand(analyzeFeedsCmd)
+	analyzeCmd.AddCommand(analyzeHostsCmd)
+	analyzeCmd.AddCommand(analyzeImagesCmd)
+	analyzeCmd.AddCommand(analyzeLanguageCmd)
+	analyzeCmd.AddCommand(analyzeNearDuplicatesCmd)
+	analyzeCmd.AddCommand(analyzeOrphansCmd)
+	analyzeCmd.AddCommand(analyzePageRankCmd)
+	analyzeCmd.AddCommand(analyzePerformanceCmd)
+	analyzeCmd.AddCommand(analyzeRedirectsCmd)
+	analyzeCmd.AddCommand(analyzeSchemaCmd)
+	analyzeCmd.AddCommand(analyzeWeightCmd)
+	rootCmd.AddCommand(analyzeCmd)
+}
+
+func runAnalyzeBrokenLinks(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	includeExternal, _ := cmd.Flags().GetBool("include-external")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	targets, err := store.GetBrokenTargets(includeExternal)
+	if err != nil {
+		return err
+	}
+	if targets == nil {
+		targets = []storage.BrokenTarget{}
+	}
+
+	var rows [][]string
+	for _, target := range targets {
+		for _, source := range target.Sources {
+			rows = append(rows, []string{target.TargetURL, strconv.Itoa(target.StatusCode), target.ErrorMessage, source.SourceURL, source.AnchorText})
+		}
+	}
+	return writeReport(cmd.OutOrStdout(), format, []string{"TARGET", "STATUS_CODE", "ERROR", "SOURCE", "ANCHOR_TEXT"}, rows, targets)
+}
+
+func runAnalyzeFeeds(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	feeds, err := store.GetFeedPages()
+	if err != nil {
+		return err
+	}
+	if feeds == nil {
+		feeds = []storage.FeedPage{}
+	}
+
+	rows := make([][]string, 0, len(feeds))
+	for _, feed := range feeds {
+		rows = append(rows, []string{feed.PageURL, feed.FeedURL, feed.FeedType, feed.FeedTitle})
+	}
+	return writeReport(cmd.OutOrStdout(), format, []string{"PAGE", "FEED", "TYPE", "TITLE"}, rows, feeds)
+}
+
+func runAnalyzeHosts(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	groupBy, _ := cmd.Flags().GetString("group-by")
+	if groupBy != groupByDomain && groupBy != groupByHost {
+		return fmt.Errorf("unsupported --group-by '%s': must be domain or host", groupBy)
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	stats, err := store.GetHostStats(groupBy == groupByDomain)
+	if err != nil {
+		return err
+	}
+
+	name := "HOST"
+	if groupBy == groupByDomain {
+		name = "DOMAIN"
+	}
+	headers := []string{name, "HOSTS", "PAGES", "COMPLETED", "ERRORS", "SKIPPED", "PENDING", "AVG_TTFB_MS", "BYTES"}
+	rows := make([][]string, 0, len(stats))
+	for _, s := range stats {
+		rows = append(rows, []string{
+			s.Host,
+			strconv.Itoa(s.Hosts),
+			strconv.Itoa(s.Pages),
+			strconv.Itoa(s.Completed),
+			strconv.Itoa(s.Errors),
+			strconv.Itoa(s.Skipped),
+			strconv.Itoa(s.Pending),
+			strconv.FormatFloat(s.AvgTTFBMs, 'f', 0, 64),
+			strconv.FormatInt(s.Bytes, 10),
+		})
+	}
+	return writeReport(cmd.OutOrStdout(), format, headers, rows, stats)
+}
+
+func runAnalyzeSchema(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	expected, _ := cmd.Flags().GetStringSlice("expect")
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	pages, err := store.GetSchemaCoverage(expected)
+	if err != nil {
+		return err
+	}
+	if pages == nil {
+		pages = []storage.SchemaCoverage{}
+	}
+
+	rows := make([][]string, 0, len(pages))
+	for _, page := range pages {
+		rows = append(rows, []string{page.URL, strings.Join(page.Types, " "), strings.Join(page.Missing, " ")})
+	}
+	return writeReport(cmd.OutOrStdout(), format, []string{"URL", "TYPES", "MISSING"}, rows, pages)
+}
+
+func runAnalyzeImages(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	maxBytes, _ := cmd.Flags().GetInt64("max-bytes")
+	maxDimension, _ := cmd.Flags().GetInt("max-dimension")
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	issues, err := store.GetImageIssues(maxBytes, maxDimension)
+	if err != nil {
+		return err
+	}
+	if issues == nil {
+		issues = []storage.ImageIssue{}
+	}
+
+	rows := make([][]string, 0, len(issues))
+	for _, issue := range issues {
+		rows = append(rows, []string{
+			issue.PageURL,
+			issue.ImageURL,
+			issue.Issue,
+			strconv.Itoa(issue.Width),
+			strconv.Itoa(issue.Height),
+			strconv.FormatInt(issue.Bytes, 10),
+		})
+	}
+	headers := []string{"PAGE", "IMAGE", "ISSUE", "WIDTH", "HEIGHT", "BYTES"}
+	return writeReport(cmd.OutOrStdout(), format, headers, rows, issues)
+}
+
+func runAnalyzePageRank(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	damping, _ := cmd.Flags().GetFloat64("damping")
+	iterations, _ := cmd.Flags().GetInt("iterations")
+	limit, _ := cmd.Flags().GetInt("limit")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	if damping <= 0 || damping >= 1 {
+		return fmt.Errorf("--damping must be between 0 and 1, got %g", damping)
+	}
+	if iterations < 1 {
+		return fmt.Errorf("--iterations must be at least 1, got %d", iterations)
+	}
+
+	store, err := openExistingStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	if _, err := store.ComputePageRank(damping, iterations); err != nil {
+		return err
+	}
+	entries, err := store.GetPageRanks(limit)
+	if err != nil {
+		return err
+	}
+	if entries == nil {
+		entries = []storage.PageRankEntry{}
+	}
+
+	rows := make([][]string, 0, len(entries))
+	for i, entry := range entries {
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1),
+			entry.URL,
+			strconv.FormatFloat(entry.PageRank, 'g', 6, 64),
+			strconv.Itoa(entry.Inlinks),
+		})
+	}
+	return writeReport(cmd.OutOrStdout(), format, []string{"RANK", "URL", "PAGERANK", "INLINKS"}, rows, entries)
+}
+
+func runAnalyzeDepth(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	maxDepth, _ := cmd.Flags().GetInt("max-depth")
+	roots, _ := cmd.Flags().GetStringSlice("root")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	if maxDepth < 0 {
+		return fmt.Errorf("--max-depth must not be negative, got %d", maxDepth)
+	}
+
+	store, err := openExistingStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	if _, err := store.ComputeClickDepths(roots); err != nil {
+		return err
+	}
+	pages, err := store.GetDeepPages(maxDepth)
+	if err != nil {
+		return err
+	}
+	if pages == nil {
+		pages = []storage.DeepPage{}
+	}
+
+	rows := make([][]string, 0, len(pages))
+	for _, page := range pages {
+		depth := strconv.Itoa(page.Depth)
+		if page.Depth < 0 {
+			depth = "unreachable"
+		}
+		rows = append(rows, []string{page.URL, depth, strconv.Itoa(page.StatusCode)})
+	}
+	return writeReport(cmd.OutOrStdout(), format, []string{"URL", "DEPTH", "STATUS_CODE"}, rows, pages)
+}
+
+func runAnalyzeOrphans(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	sources, _ := cmd.Flags().GetStringSlice("sitemap")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	urls, err := loadSitemapURLs(cmd.Context(), cfg, sources)
+	if err != nil {
+		return err
+	}
+	issues, err := store.GetSitemapIssues(urls)
+	if err != nil {
+		return err
+	}
+	if issues == nil {
+		issues = []storage.SitemapIssue{}
+	}
+
+	rows := make([][]string, 0, len(issues))
+	for _, issue := range issues {
+		rows = append(rows, []string{issue.Issue, issue.URL, issue.Status, strconv.Itoa(issue.StatusCode), strconv.Itoa(issue.Inlinks)})
+	}
+	return writeReport(cmd.OutOrStdout(), format, []string{"ISSUE", "URL", "STATUS", "STATUS_CODE", "INLINKS"}, rows, issues)
+}
+
+func runAnalyzeRedirects(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	maxHops, _ := cmd.Flags().GetInt("max-hops")
+	all, _ := cmd.Flags().GetBool("all")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	if maxHops < 1 {
+		return fmt.Errorf("--max-hops must be at least 1, got %d", maxHops)
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	chains, err := store.GetRedirectChains(maxHops, all)
+	if err != nil {
+		return err
+	}
+	if chains == nil {
+		chains = []storage.RedirectChain{}
+	}
+
+	rows := make([][]string, 0, len(chains))
+	for _, chain := range chains {
+		steps := make([]string, 0, len(chain.Hops))
+		for _, hop := range chain.Hops {
+			steps = append(steps, fmt.Sprintf("%d %s", hop.StatusCode, hop.ToURL))
+		}
+		rows = append(rows, []string{
+			chain.URL,
+			strconv.Itoa(len(chain.Hops)),
+			chain.FinalURL,
+			strconv.Itoa(chain.StatusCode),
+			strings.Join(chain.Issues, " "),
+			strings.Join(steps, " -> "),
+		})
+	}
+	return writeReport(cmd.OutOrStdout(), format, []string{"URL", "HOPS", "FINAL_URL", "STATUS_CODE", "ISSUES", "CHAIN"}, rows, chains)
+}
+
+func runAnalyzeAccessibility(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	details, _ := cmd.Flags().GetBool("details")
+	issues, _ := cmd.Flags().GetStringSlice("issue")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	if details {
+		found, err := store.GetAccessibilityIssues(issues)
+		if err != nil {
+			return err
+		}
+		if found == nil {
+			found = []storage.PageAccessibilityIssue{}
+		}
+		rows := make([][]string, 0, len(found))
+		for _, issue := range found {
+			rows = append(rows, []string{issue.URL, issue.Issue, issue.Element})
+		}
+		return writeReport(cmd.OutOrStdout(), format, []string{"URL", "ISSUE", "ELEMENT"}, rows, found)
+	}
+
+	summary, err := store.GetAccessibilitySummary()
+	if err != nil {
+		return err
+	}
+	if summary == nil {
+		summary = []storage.AccessibilitySummary{}
+	}
+	rows := make([][]string, 0, len(summary))
+	for _, row := range summary {
+		rows = append(rows, []string{row.Issue, strconv.Itoa(row.Pages), strconv.Itoa(row.Occurrences)})
+	}
+	return writeReport(cmd.OutOrStdout(), format, []string{"ISSUE", "PAGES", "OCCURRENCES"}, rows, summary)
+}
+
+func runAnalyzeCertificates(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	days, _ := cmd.Flags().GetInt("days")
+	all, _ := cmd.Flags().GetBool("all")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	if days < 0 {
+		return fmt.Errorf("--days must not be negative, got %d", days)
+	}
+	if all {
+		days = -1
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	certs, err := store.GetExpiringCertificates(days, time.Now())
+	if err != nil {
+		return err
+	}
+	if certs == nil {
+		certs = []storage.Certificate{}
+	}
+
+	rows := make([][]string, 0, len(certs))
+	for _, cert := range certs {
+		rows = append(rows, []string{
+			cert.Host,
+			cert.NotAfter.Format(time.DateOnly),
+			strconv.Itoa(cert.DaysLeft),
+			cert.Issuer,
+			cert.Subject,
+			strings.Join(cert.DNSNames, " "),
+		})
+	}
+	return writeReport(cmd.OutOrStdout(), format, []string{"HOST", "EXPIRES", "DAYS_LEFT", "ISSUER", "SUBJECT", "SANS"}, rows, certs)
+}
+
+func runAnalyzeLanguage(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	all, _ := cmd.Flags().GetBool("all")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	pages, err := store.GetPageLanguages(all)
+	if err != nil {
+		return err
+	}
+	if pages == nil {
+		pages = []storage.PageLanguage{}
+	}
+
+	rows := make([][]string, 0, len(pages))
+	for _, page := range pages {
+		rows = append(rows, []string{page.URL, page.HTMLLang, page.Language, page.Issue})
+	}
+	return writeReport(cmd.OutOrStdout(), format, []string{"URL", "HTML_LANG", "LANGUAGE", "ISSUE"}, rows, pages)
+}
+
+func runAnalyzeDuplicates(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	fields, _ := cmd.Flags().GetStringSlice("by")
+	examples, _ := cmd.Flags().GetInt("examples")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if !slices.Contains(storage.DuplicateFields, field) {
+			return fmt.Errorf("unknown --by value %q: expected %s", field, strings.Join(storage.DuplicateFields, ", "))
+		}
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	groups, err := store.GetDuplicates(fields, examples)
+	if err != nil {
+		return err
+	}
+	if groups == nil {
+		groups = []storage.DuplicateGroup{}
+	}
+
+	rows := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		rows = append(rows, []string{group.Field, group.Value, strconv.Itoa(group.Pages), strings.Join(group.URLs, " ")})
+	}
+	return writeReport(cmd.OutOrStdout(), format, []string{"FIELD", "VALUE", "PAGES", "EXAMPLES"}, rows, groups)
+}
+
+func runAnalyzeNearDuplicates(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	maxDistance, _ := cmd.Flags().GetInt("max-distance")
+	minWords, _ := cmd.Flags().GetInt("min-words")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	if maxDistance < 0 || maxDistance > 63 {
+		return fmt.Errorf("--max-distance must be between 0 and 63, got %d", maxDistance)
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	duplicates, err := store.GetNearDuplicates(maxDistance, minWords)
+	if err != nil {
+		return err
+	}
+	if duplicates == nil {
+		duplicates = []storage.NearDuplicate{}
+	}
+
+	rows := make([][]string, 0, len(duplicates))
+	for _, page := range duplicates {
+		rows = append(rows, []string{strconv.Itoa(page.Cluster), page.URL, strconv.Itoa(page.WordCount), strconv.Itoa(page.Distance)})
+	}
+	return writeReport(cmd.OutOrStdout(), format, []string{"CLUSTER", "URL", "WORDS", "DISTANCE"}, rows, duplicates)
+}
+
+func runAnalyzePerformance(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	groupBy, _ := cmd.Flags().GetString("group-by")
+	slowest, _ := cmd.Flags().GetInt("slowest")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	if groupBy != groupByHost && groupBy != groupBySection {
+		return fmt.Errorf("unsupported --group-by '%s': must be host or section", groupBy)
+	}
+	if slowest < 0 {
+		return fmt.Errorf("--slowest must not be negative, got %d", slowest)
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	if slowest > 0 {
+		pages, err := store.GetSlowestPages(slowest)
+		if err != nil {
+			return err
+		}
+		if pages == nil {
+			pages = []storage.SlowPage{}
+		}
+		rows := make([][]string, 0, len(pages))
+		for _, page := range pages {
+			rows = append(rows, []string{
+				page.URL,
+				strconv.Itoa(page.StatusCode),
+				strconv.Itoa(page.TTFBMs),
+				strconv.Itoa(page.DownloadTimeMs),
+				strconv.FormatInt(page.Bytes, 10),
+			})
+		}
+		return writeReport(cmd.OutOrStdout(), format, []string{"URL", "STATUS_CODE", "TTFB_MS", "DOWNLOAD_MS", "BYTES"}, rows, pages)
+	}
+
+	stats, err := store.GetPerformanceStats(groupBy == groupBySection)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(stats))
+	for _, row := range stats {
+		rows = append(rows, []string{
+			row.Group,
+			strconv.Itoa(row.Pages),
+			strconv.Itoa(row.TTFBP50),
+			strconv.Itoa(row.TTFBP90),
+			strconv.Itoa(row.TTFBP99),
+			strconv.Itoa(row.DownloadP50),
+			strconv.Itoa(row.DownloadP90),
+			strconv.Itoa(row.DownloadP99),
+		})
+	}
+	headers := []string{strings.ToUpper(groupBy), "PAGES", "TTFB_P50", "TTFB_P90", "TTFB_P99", "DOWNLOAD_P50", "DOWNLOAD_P90", "DOWNLOAD_P99"}
+	return writeReport(cmd.OutOrStdout(), format, headers, rows, stats)
+}
+
+func runAnalyzeWeight(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	all, _ := cmd.Flags().GetBool("all")
+	if cmd.Flags().Changed("fail-on-budget") {
+		cfg.FailOnBudget, _ = cmd.Flags().GetBool("fail-on-budget")
+	}
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	budgets := weightBudgets(cmd, cfg)
+	if budgets.Total < 0 || budgets.Script < 0 || budgets.Stylesheet < 0 || budgets.Image < 0 {
+		return fmt.Errorf("budgets must not be negative")
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	weights, err := store.GetPageWeights(budgets, all)
+	if err != nil {
+		return err
+	}
+	if weights == nil {
+		weights = []storage.PageWeight{}
+	}
+
+	rows := make([][]string, 0, len(weights))
+	over := 0
+	for _, w := range weights {
+		rows = append(rows, []string{
+			w.URL,
+			strconv.FormatInt(w.TotalBytes, 10),
+			strconv.FormatInt(w.HTMLBytes, 10),
+			strconv.FormatInt(w.ScriptBytes, 10),
+			strconv.FormatInt(w.StylesheetBytes, 10),
+			strconv.FormatInt(w.ImageBytes, 10),
+			strconv.Itoa(w.Assets),
+			strconv.Itoa(w.Unmeasured),
+			strings.Join(w.OverBudget, " "),
+		})
+		if len(w.OverBudget) > 0 {
+			over++
+		}
+	}
+	headers := []string{"URL", "TOTAL", "HTML", "SCRIPTS", "STYLESHEETS", "IMAGES", "ASSETS", "UNMEASURED", "OVER_BUDGET"}
+	if err := writeReport(cmd.OutOrStdout(), format, headers, rows, weights); err != nil {
+		return err
+	}
+	if over > 0 && cfg.FailOnBudget {
+		cmd.SilenceUsage = true // Not a usage error
+		return errBudgetExceeded
+	}
+	return nil
+}
+
+func runAnalyzeCanonicals(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	issues, err := store.GetCanonicalIssues()
+	if err != nil {
+		return err
+	}
+	if issues == nil {
+		issues = []storage.CanonicalIssue{}
+	}
+
+	rows := make([][]string, 0, len(issues))
+	for _, issue := range issues {
+		rows = append(rows, []string{issue.Issue, issue.URL, issue.CanonicalURL, strconv.Itoa(issue.StatusCode), issue.Target})
+	}
+	return writeReport(cmd.OutOrStdout(), format, []string{"ISSUE", "URL", "CANONICAL", "STATUS_CODE", "TARGET"}, rows, issues)
+}
@@ -0,0 +1,745 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+func TestAnalyzeFeedsCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "feeds.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/blog"})
+	item, _ := store.GetNextFromQueue()
+	err = store.SavePageResult(item.ID, &crawler.PageData{
+		URL:         item.URL,
+		StatusCode:  200,
+		HTTPHeaders: map[string]string{},
+		CrawledAt:   time.Now(),
+		Alternates: []crawler.AlternateLink{
+			{URL: "https://example.com/feed.xml", Kind: "feed", Type: "application/rss+xml", Title: "Blog"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save page: %v", err)
+	}
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "feeds", "--database", dbPath})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze feeds failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "https://example.com/feed.xml") {
+		t.Errorf("Table output missing feed: %q", out.String())
+	}
+
+	out.Reset()
+	rootCmd.SetArgs([]string{"analyze", "feeds", "--database", dbPath, "--format", "json"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze feeds --format json failed: %v", err)
+	}
+	var feeds []storage.FeedPage
+	if err := json.Unmarshal(out.Bytes(), &feeds); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if len(feeds) != 1 || feeds[0].PageURL != "https://example.com/blog" {
+		t.Errorf("Unexpected JSON output: %+v", feeds)
+	}
+
+	rootCmd.SetArgs([]string{"analyze", "feeds", "--database", dbPath, "--format", "xml"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestAnalyzeHostsCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "hosts.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://www.example.com/", "https://shop.example.com/", "https://other.org/"})
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = analyzeHostsCmd.Flags().Set("group-by", groupByDomain)
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "hosts", "--database", dbPath, "--format", "json"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze hosts failed: %v", err)
+	}
+	var stats []storage.HostStats
+	if err := json.Unmarshal(out.Bytes(), &stats); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if len(stats) != 2 || stats[0].Host != "example.com" || stats[0].Hosts != 2 || stats[0].Pending != 2 {
+		t.Errorf("Unexpected domain stats: %+v", stats)
+	}
+
+	out.Reset()
+	rootCmd.SetArgs([]string{"analyze", "hosts", "--database", dbPath, "--format", "table", "--group-by", "host"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze hosts --group-by host failed: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "HOST") || !strings.Contains(out.String(), "shop.example.com") {
+		t.Errorf("Unexpected table output: %q", out.String())
+	}
+
+	rootCmd.SetArgs([]string{"analyze", "hosts", "--database", dbPath, "--group-by", "tld"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("Expected an error for an unknown --group-by")
+	}
+}
+
+func TestAnalyzeSchemaCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "schema.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/"})
+	item, err := store.GetNextFromQueue()
+	if err != nil || item == nil {
+		t.Fatalf("Failed to dequeue: %v", err)
+	}
+	page := &crawler.PageData{
+		URL:         item.URL,
+		StatusCode:  200,
+		HTTPHeaders: map[string]string{},
+		CrawledAt:   time.Now(),
+		Text:        &crawler.PageText{WordCount: 10},
+		Structured:  []crawler.StructuredData{{Format: "json-ld", Types: []string{"WebSite"}, Payload: "{}", Valid: true}},
+	}
+	if err := store.SavePageResult(item.ID, page); err != nil {
+		t.Fatalf("Failed to save page: %v", err)
+	}
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = analyzeSchemaCmd.Flags().Lookup("expect").Value.(interface{ Replace([]string) error }).Replace(nil)
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "schema", "--database", dbPath, "--format", "json", "--expect", "WebSite,Organization"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze schema failed: %v", err)
+	}
+	var pages []storage.SchemaCoverage
+	if err := json.Unmarshal(out.Bytes(), &pages); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if len(pages) != 1 || len(pages[0].Missing) != 1 || pages[0].Missing[0] != "Organization" {
+		t.Errorf("Unexpected schema report: %+v", pages)
+	}
+}
+
+func TestAnalyzeImagesCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "images.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/"})
+	item, err := store.GetNextFromQueue()
+	if err != nil || item == nil {
+		t.Fatalf("Failed to dequeue: %v", err)
+	}
+	page := &crawler.PageData{
+		URL:         item.URL,
+		StatusCode:  200,
+		HTTPHeaders: map[string]string{},
+		CrawledAt:   time.Now(),
+		Images: []crawler.Image{
+			{URL: "https://example.com/logo.png", Alt: "Logo", HasAlt: true},
+			{URL: "https://example.com/banner.png", Width: 3000},
+		},
+	}
+	if err := store.SavePageResult(item.ID, page); err != nil {
+		t.Fatalf("Failed to save page: %v", err)
+	}
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "images", "--database", dbPath, "--format", "json"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze images failed: %v", err)
+	}
+	var issues []storage.ImageIssue
+	if err := json.Unmarshal(out.Bytes(), &issues); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if len(issues) != 2 || issues[0].Issue != storage.ImageIssueMissingAlt || issues[1].Issue != storage.ImageIssueOversized {
+		t.Errorf("Unexpected image issues: %+v", issues)
+	}
+}
+
+func TestAnalyzePageRankCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "pagerank.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/"})
+	_ = store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/about", LinkType: "internal"},
+		{SourceURL: "https://example.com/about", TargetURL: "https://example.com/", LinkType: "internal"},
+		{SourceURL: "https://example.com/about", TargetURL: "https://example.com/contact", LinkType: "internal"},
+	})
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "pagerank", "--database", dbPath, "--format", "csv", "--limit", "1"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze pagerank failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || lines[0] != "RANK,URL,PAGERANK,INLINKS" || !strings.HasPrefix(lines[1], "1,https://example.com/about,") {
+		t.Errorf("Unexpected report: %q", out.String())
+	}
+
+	rootCmd.SetArgs([]string{"analyze", "pagerank", "--database", dbPath, "--damping", "1"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("Expected error for damping 1")
+	}
+	_ = analyzePageRankCmd.Flags().Set("damping", "0.85")
+	_ = analyzeCmd.PersistentFlags().Set("format", formatTable)
+}
+
+func TestAnalyzeDepthCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "depth.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/", "https://example.com/orphan"})
+	_ = store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/a", LinkType: "internal"},
+		{SourceURL: "https://example.com/a", TargetURL: "https://example.com/b", LinkType: "internal"},
+	})
+	_ = store.AddToQueue([]string{"https://example.com/b"})
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = analyzeCmd.PersistentFlags().Set("format", formatTable)
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "depth", "--database", dbPath, "--format", "csv", "--max-depth", "1"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze depth failed: %v", err)
+	}
+	want := "URL,DEPTH,STATUS_CODE\nhttps://example.com/b,2,0\nhttps://example.com/orphan,unreachable,0\n"
+	if out.String() != want {
+		t.Errorf("Unexpected report %q, want %q", out.String(), want)
+	}
+}
+
+func TestAnalyzeOrphansCommand(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "orphans.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/"})
+	_ = store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/a", LinkType: "internal"},
+	})
+	_ = store.Close()
+
+	// A sitemap index served over HTTP, pointing at a gzipped child sitemap
+	child := `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>https://example.com/a</loc></url><url><loc>https://example.com/lost</loc></url></urlset>`
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><sitemap><loc>%s/pages.xml.gz</loc></sitemap></sitemapindex>`, server.URL)
+	})
+	mux.HandleFunc("/pages.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte(child))
+		_ = gz.Close()
+	})
+	// A plain-text URL list on disk
+	listPath := filepath.Join(dir, "urls.txt")
+	if err := os.WriteFile(listPath, []byte("# home\nhttps://example.com/\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write URL list: %v", err)
+	}
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = analyzeCmd.PersistentFlags().Set("format", formatTable)
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "orphans", "--database", dbPath, "--format", "csv",
+		"--sitemap", server.URL + "/sitemap.xml", "--sitemap", listPath})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze orphans failed: %v", err)
+	}
+	// /a is listed and linked; the home page is listed but nothing links to it
+	want := "ISSUE,URL,STATUS,STATUS_CODE,INLINKS\n" +
+		"orphan,https://example.com/,pending,0,0\n" +
+		"orphan,https://example.com/lost,,0,0\n"
+	if out.String() != want {
+		t.Errorf("Unexpected report %q, want %q", out.String(), want)
+	}
+}
+
+func TestAnalyzeBrokenLinksCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "broken.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/gone", AnchorText: "Gone", LinkType: "internal"},
+		{SourceURL: "https://example.com/a", TargetURL: "https://example.com/gone", AnchorText: "Old, page", LinkType: "internal"},
+	})
+	_ = store.AddToQueue([]string{"https://example.com/gone"})
+	item, _ := store.GetNextFromQueue()
+	_ = store.SavePageResult(item.ID, &crawler.PageData{URL: item.URL, StatusCode: 404, HTTPHeaders: map[string]string{}, CrawledAt: time.Now()})
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = analyzeCmd.PersistentFlags().Set("format", formatTable)
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "broken-links", "--database", dbPath, "--format", "csv"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze broken-links failed: %v", err)
+	}
+	want := "TARGET,STATUS_CODE,ERROR,SOURCE,ANCHOR_TEXT\n" +
+		"https://example.com/gone,404,,https://example.com/,Gone\n" +
+		"https://example.com/gone,404,,https://example.com/a,\"Old, page\"\n"
+	if out.String() != want {
+		t.Errorf("Unexpected report %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	rootCmd.SetArgs([]string{"analyze", "broken-links", "--database", dbPath, "--format", "json"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze broken-links failed: %v", err)
+	}
+	var targets []storage.BrokenTarget
+	if err := json.Unmarshal(out.Bytes(), &targets); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", out.String(), err)
+	}
+	if len(targets) != 1 || len(targets[0].Sources) != 2 {
+		t.Errorf("Expected one target with two sources, got %+v", targets)
+	}
+}
+
+func TestAnalyzeRedirectsCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "redirects.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/old"})
+	item, _ := store.GetNextFromQueue()
+	_ = store.SavePageResult(item.ID, &crawler.PageData{URL: item.URL, StatusCode: 200, HTTPHeaders: map[string]string{}, CrawledAt: time.Now(),
+		Redirects: []crawler.RedirectHop{
+			{StatusCode: 301, FromURL: "https://example.com/old", ToURL: "http://example.com/new"},
+			{StatusCode: 301, FromURL: "http://example.com/new", ToURL: "https://example.com/new"},
+		}})
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = analyzeCmd.PersistentFlags().Set("format", formatTable)
+		_ = analyzeRedirectsCmd.Flags().Set("max-hops", "1")
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "redirects", "--database", dbPath, "--format", "csv"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze redirects failed: %v", err)
+	}
+	want := "URL,HOPS,FINAL_URL,STATUS_CODE,ISSUES,CHAIN\n" +
+		"https://example.com/old,2,https://example.com/new,200,too_long,301 http://example.com/new -> 301 https://example.com/new\n"
+	if out.String() != want {
+		t.Errorf("Unexpected report %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	rootCmd.SetArgs([]string{"analyze", "redirects", "--database", dbPath, "--format", "csv", "--max-hops", "2"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze redirects failed: %v", err)
+	}
+	if out.String() != "URL,HOPS,FINAL_URL,STATUS_CODE,ISSUES,CHAIN\n" {
+		t.Errorf("Expected no flagged chains, got %q", out.String())
+	}
+}
+
+func TestAnalyzeCanonicalsCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "canonicals.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/a", "https://example.com/gone"})
+	for _, page := range []*crawler.PageData{
+		{URL: "https://example.com/a", StatusCode: 200, CanonicalURL: "https://example.com/gone"},
+		{URL: "https://example.com/gone", StatusCode: 410},
+	} {
+		item, _ := store.GetNextFromQueue()
+		page.HTTPHeaders, page.CrawledAt = map[string]string{}, time.Now()
+		_ = store.SavePageResult(item.ID, page)
+	}
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = analyzeCmd.PersistentFlags().Set("format", formatTable)
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "canonicals", "--database", dbPath, "--format", "csv"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze canonicals failed: %v", err)
+	}
+	want := "ISSUE,URL,CANONICAL,STATUS_CODE,TARGET\nbroken,https://example.com/a,https://example.com/gone,410,\n"
+	if out.String() != want {
+		t.Errorf("Unexpected report %q, want %q", out.String(), want)
+	}
+}
+
+func TestAnalyzeCertificatesCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "certificates.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	expires := time.Now().UTC().AddDate(0, 0, 5).Add(time.Hour)
+	_ = store.AddToQueue([]string{"https://example.com/"})
+	item, _ := store.GetNextFromQueue()
+	_ = store.SavePageResult(item.ID, &crawler.PageData{URL: item.URL, StatusCode: 200, HTTPHeaders: map[string]string{}, CrawledAt: time.Now(),
+		Certificates: []crawler.TLSCertificate{
+			{Host: "example.com", Subject: "CN=example.com", Issuer: "CN=Test CA", DNSNames: []string{"example.com", "www.example.com"},
+				NotAfter: expires, Fingerprint: "a"},
+			{Host: "cdn.example.com", Subject: "CN=cdn.example.com", Issuer: "CN=Test CA", NotAfter: expires.AddDate(1, 0, 0), Fingerprint: "b"},
+		}})
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = analyzeCmd.PersistentFlags().Set("format", formatTable)
+		_ = analyzeCertificatesCmd.Flags().Set("days", "30")
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "certificates", "--database", dbPath, "--format", "csv", "--days", "7"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze certificates failed: %v", err)
+	}
+	want := "HOST,EXPIRES,DAYS_LEFT,ISSUER,SUBJECT,SANS\n" +
+		"example.com," + expires.Format(time.DateOnly) + ",5,CN=Test CA,CN=example.com,example.com www.example.com\n"
+	if out.String() != want {
+		t.Errorf("Unexpected report %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	rootCmd.SetArgs([]string{"analyze", "certificates", "--database", dbPath, "--format", "csv", "--days", "3"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze certificates failed: %v", err)
+	}
+	if out.String() != "HOST,EXPIRES,DAYS_LEFT,ISSUER,SUBJECT,SANS\n" {
+		t.Errorf("Expected no expiring certificates, got %q", out.String())
+	}
+}
+
+func TestAnalyzeAccessibilityCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "accessibility.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/"})
+	item, _ := store.GetNextFromQueue()
+	_ = store.SavePageResult(item.ID, &crawler.PageData{URL: item.URL, StatusCode: 200, HTTPHeaders: map[string]string{}, CrawledAt: time.Now(),
+		Accessibility: []crawler.AccessibilityIssue{
+			{Issue: "empty_link", Element: `<a href="/x">`},
+			{Issue: "heading_jump", Element: "<h4> after <h2>"},
+		}})
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = analyzeCmd.PersistentFlags().Set("format", formatTable)
+		_ = analyzeAccessibilityCmd.Flags().Set("details", "false")
+		_ = analyzeAccessibilityCmd.Flags().Set("issue", "")
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "accessibility", "--database", dbPath, "--format", "csv"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze accessibility failed: %v", err)
+	}
+	want := "ISSUE,PAGES,OCCURRENCES\nempty_link,1,1\nheading_jump,1,1\n"
+	if out.String() != want {
+		t.Errorf("Unexpected summary %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	rootCmd.SetArgs([]string{"analyze", "accessibility", "--database", dbPath, "--format", "csv", "--details", "--issue", "empty_link"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze accessibility failed: %v", err)
+	}
+	want = "URL,ISSUE,ELEMENT\nhttps://example.com/,empty_link,\"<a href=\"\"/x\"\">\"\n"
+	if out.String() != want {
+		t.Errorf("Unexpected details %q, want %q", out.String(), want)
+	}
+}
+
+func TestAnalyzeWeightCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "weight.db")
+	writeWeightCrawl(t, dbPath)
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = analyzeCmd.PersistentFlags().Set("format", formatTable)
+		for _, name := range []string{"script-weight-budget", "all", "fail-on-budget"} {
+			flag := analyzeWeightCmd.Flags().Lookup(name)
+			_ = flag.Value.Set(flag.DefValue)
+			flag.Changed = false
+		}
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "weight", "--database", dbPath, "--format", "csv", "--script-weight-budget", "300000"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze weight failed: %v", err)
+	}
+	want := "URL,TOTAL,HTML,SCRIPTS,STYLESHEETS,IMAGES,ASSETS,UNMEASURED,OVER_BUDGET\n" +
+		"https://example.com/,405000,5000,400000,0,0,1,0,script\n"
+	if out.String() != want {
+		t.Errorf("Unexpected report %q, want %q", out.String(), want)
+	}
+
+	// Over budget pages fail the command for CI
+	rootCmd.SetArgs([]string{"analyze", "weight", "--database", dbPath, "--script-weight-budget", "300000", "--fail-on-budget"})
+	if err := rootCmd.Execute(); !errors.Is(err, errBudgetExceeded) {
+		t.Errorf("Expected errBudgetExceeded, got %v", err)
+	}
+	rootCmd.SetArgs([]string{"analyze", "weight", "--database", dbPath, "--script-weight-budget", "500000", "--fail-on-budget"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Errorf("Expected the page to be within budget, got %v", err)
+	}
+}
+
+func TestAnalyzePerformanceCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "performance.db")
+	writeCrawl(t, dbPath, map[string]*crawler.PageData{
+		"https://example.com/blog/a": {StatusCode: 200, TTFB: 100 * time.Millisecond, DownloadTime: 20 * time.Millisecond},
+		"https://example.com/blog/b": {StatusCode: 200, TTFB: 300 * time.Millisecond, DownloadTime: 40 * time.Millisecond},
+		"https://example.com/":       {StatusCode: 200, TTFB: 50 * time.Millisecond, DownloadTime: 10 * time.Millisecond},
+	})
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = analyzeCmd.PersistentFlags().Set("format", formatTable)
+		_ = analyzePerformanceCmd.Flags().Set("group-by", groupByHost)
+		_ = analyzePerformanceCmd.Flags().Set("slowest", "0")
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "performance", "--database", dbPath, "--format", "csv", "--group-by", "section"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze performance failed: %v", err)
+	}
+	want := "SECTION,PAGES,TTFB_P50,TTFB_P90,TTFB_P99,DOWNLOAD_P50,DOWNLOAD_P90,DOWNLOAD_P99\n" +
+		"(all),3,100,300,300,20,40,40\n" +
+		"example.com/blog/,2,100,300,300,20,40,40\n" +
+		"example.com/,1,50,50,50,10,10,10\n"
+	if out.String() != want {
+		t.Errorf("Unexpected report %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	rootCmd.SetArgs([]string{"analyze", "performance", "--database", dbPath, "--format", "csv", "--slowest", "1"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze performance --slowest failed: %v", err)
+	}
+	if want := "URL,STATUS_CODE,TTFB_MS,DOWNLOAD_MS,BYTES\nhttps://example.com/blog/b,200,300,40,0\n"; out.String() != want {
+		t.Errorf("Unexpected slowest pages %q, want %q", out.String(), want)
+	}
+}
+
+func TestAnalyzeDuplicatesCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "duplicates.db")
+	writeCrawl(t, dbPath, map[string]*crawler.PageData{
+		"https://example.com/a": {StatusCode: 200, Title: "Shop", ContentHash: "h1"},
+		"https://example.com/b": {StatusCode: 200, Title: "Shop", ContentHash: "h1"},
+		"https://example.com/c": {StatusCode: 200, Title: "Shop", ContentHash: "h2"},
+	})
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = analyzeCmd.PersistentFlags().Set("format", formatTable)
+		_ = analyzeDuplicatesCmd.Flags().Lookup("by").Value.(interface{ Replace([]string) error }).Replace(storage.DuplicateFields)
+		_ = analyzeDuplicatesCmd.Flags().Set("examples", "3")
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "duplicates", "--database", dbPath, "--format", "csv", "--examples", "2"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze duplicates failed: %v", err)
+	}
+	want := "FIELD,VALUE,PAGES,EXAMPLES\n" +
+		"title,Shop,3,https://example.com/a https://example.com/b\n" +
+		"content_hash,h1,2,https://example.com/a https://example.com/b\n"
+	if out.String() != want {
+		t.Errorf("Unexpected report %q, want %q", out.String(), want)
+	}
+
+	rootCmd.SetArgs([]string{"analyze", "duplicates", "--database", dbPath, "--by", "body"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "--by") {
+		t.Errorf("Expected a --by error, got %v", err)
+	}
+}
+
+func TestAnalyzeNearDuplicatesCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "near-duplicates.db")
+	writeCrawl(t, dbPath, map[string]*crawler.PageData{
+		"https://example.com/a":       {StatusCode: 200, Text: &crawler.PageText{WordCount: 120, SimHash: 0xff00}},
+		"https://example.com/a?sid=1": {StatusCode: 200, Text: &crawler.PageText{WordCount: 121, SimHash: 0xff01}},
+		"https://example.com/b":       {StatusCode: 200, Text: &crawler.PageText{WordCount: 300, SimHash: 0xf0f0f0f0}},
+	})
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = analyzeCmd.PersistentFlags().Set("format", formatTable)
+		_ = analyzeNearDuplicatesCmd.Flags().Set("max-distance", "3")
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "near-duplicates", "--database", dbPath, "--format", "csv"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze near-duplicates failed: %v", err)
+	}
+	want := "CLUSTER,URL,WORDS,DISTANCE\n" +
+		"1,https://example.com/a,120,0\n" +
+		"1,https://example.com/a?sid=1,121,1\n"
+	if out.String() != want {
+		t.Errorf("Unexpected report %q, want %q", out.String(), want)
+	}
+
+	rootCmd.SetArgs([]string{"analyze", "near-duplicates", "--database", dbPath, "--max-distance", "64"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "--max-distance") {
+		t.Errorf("Expected a --max-distance error, got %v", err)
+	}
+}
+
+func TestAnalyzeLanguageCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "language.db")
+	writeCrawl(t, dbPath, map[string]*crawler.PageData{
+		"https://example.com/":    {StatusCode: 200, Text: &crawler.PageText{Language: "en", HTMLLang: "en"}},
+		"https://example.com/de/": {StatusCode: 200, Text: &crawler.PageText{Language: "de", HTMLLang: "en"}},
+	})
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = analyzeCmd.PersistentFlags().Set("format", formatTable)
+		_ = analyzeLanguageCmd.Flags().Set("all", "false")
+	}()
+
+	rootCmd.SetArgs([]string{"analyze", "language", "--database", dbPath, "--format", "csv"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze language failed: %v", err)
+	}
+	if want := "URL,HTML_LANG,LANGUAGE,ISSUE\nhttps://example.com/de/,en,de,mismatch\n"; out.String() != want {
+		t.Errorf("Unexpected report %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	rootCmd.SetArgs([]string{"analyze", "language", "--database", dbPath, "--format", "csv", "--all"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("analyze language --all failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "https://example.com/,en,en,\n") {
+		t.Errorf("Expected every page with --all, got %q", out.String())
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/logging"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// checkCmd crawls a site and reports its broken links
+var checkCmd = &cobra.Command{
+	Use:   "check [URLs...]",
+	Short: "Crawl a site and report broken internal and external links",
+	Long: `Crawl a site, verify every internal link by crawling it and every external
+link with a HEAD request, then report the broken ones with their source page,
+anchor text and status code.
+
+Progress goes to stderr so the report can be redirected. Without URLs, an
+interrupted check resumes from the existing database.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runCheck,
+}
+
+func init() {
+	checkCmd.Flags().StringP("database", "d", "./linktadoru.db", "Path to SQLite database file")
+	checkCmd.Flags().String("results-database", "", "Path to the separate results database, if the crawl used one")
+	checkCmd.Flags().String("format", formatCSV, "Output format: table, csv or json")
+	checkCmd.Flags().StringP("output", "o", "", "Write the report to this file instead of stdout")
+	checkCmd.Flags().IntP("concurrency", "c", 2, "Number of concurrent workers")
+	checkCmd.Flags().IntP("limit", "l", 0, "Stop after N pages (0=unlimited)")
+	rootCmd.AddCommand(checkCmd)
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	cfg.SeedURLs = args
+	cfg.CheckExternal = config.CheckExternalHead
+	headerConflicts, err := loadHeaders(cmd, cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.UserAgent == "LinkTadoru/1.0" {
+		cfg.UserAgent = generateUserAgent()
+	}
+	cfg.UserAgent = cfg.UserAgentWithInfo()
+	if cmd.Flags().Changed("concurrency") {
+		cfg.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+	}
+	if cmd.Flags().Changed("limit") {
+		cfg.Limit, _ = cmd.Flags().GetInt("limit")
+	}
+	format, _ := cmd.Flags().GetString("format")
+	outputPath, _ := cmd.Flags().GetString("output")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+
+	// Keep stdout free for the report
+	logConfig := logging.Config{
+		Level:      logging.ParseLevel(cfg.LogLevel),
+		FilePath:   cfg.LogFile,
+		MaxSize:    int64(cfg.LogMaxSize),
+		MaxBackups: cfg.LogMaxBackups,
+		Console:    cfg.LogConsole,
+		Output:     cmd.ErrOrStderr(),
+	}
+	if err := logging.SetDefault(logConfig); err != nil {
+		return fmt.Errorf("failed to initialize logging: %w", err)
+	}
+	warnHeaderConflicts(headerConflicts)
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if len(cfg.SeedURLs) == 0 {
+		if _, err := os.Stat(cfg.DatabasePath); os.IsNotExist(err) {
+			return fmt.Errorf("no URLs provided and no existing database found at %s", cfg.DatabasePath)
+		}
+	}
+
+	if _, err := executeCrawl(cmd, cfg, cmd.ErrOrStderr()); err != nil {
+		return err
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	broken, err := store.GetBrokenLinks()
+	if err != nil {
+		return err
+	}
+	if broken == nil {
+		broken = []storage.BrokenLink{}
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Found %d broken links\n", len(broken))
+
+	var out io.Writer = cmd.OutOrStdout()
+	if outputPath != "" {
+		file, err := os.Create(outputPath) // #nosec G304 -- path comes from the command line
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		out = file
+	}
+	return writeBrokenLinks(out, format, broken)
+}
+
+// writeBrokenLinks writes a broken-link report in the requested format
+func writeBrokenLinks(w io.Writer, format string, links []storage.BrokenLink) error {
+	rows := make([][]string, 0, len(links))
+	for _, link := range links {
+		rows = append(rows, []string{
+			link.SourceURL, link.TargetURL, link.AnchorText, link.LinkType,
+			strconv.Itoa(link.StatusCode), link.ErrorMessage,
+		})
+	}
+	return writeReport(w, format, []string{"SOURCE", "TARGET", "ANCHOR", "TYPE", "STATUS", "ERROR"}, rows, links)
+}
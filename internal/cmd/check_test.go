@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+func TestCheckCommand(t *testing.T) {
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+		}
+	}))
+	defer external.Close()
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprintf(w, `<html><body>
+				<a href="/about">About</a>
+				<a href="/missing">Old page</a>
+				<a href="%s/ok">Partner</a>
+				<a href="%s/gone">Retired partner</a>
+			</body></html>`, external.URL, external.URL)
+		case "/about":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><body>About</body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer site.Close()
+
+	dir := t.TempDir()
+	reportPath := filepath.Join(dir, "broken.json")
+
+	var stderr bytes.Buffer
+	rootCmd.SetErr(&stderr)
+	defer func() {
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	rootCmd.SetArgs([]string{"check", site.URL + "/",
+		"--database", filepath.Join(dir, "check.db"),
+		"--format", "json", "--output", reportPath})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("check failed: %v\n%s", err, stderr.String())
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var broken []storage.BrokenLink
+	if err := json.Unmarshal(data, &broken); err != nil {
+		t.Fatalf("Invalid JSON report: %v\n%s", err, data)
+	}
+
+	want := map[string]int{
+		site.URL + "/missing":  http.StatusNotFound,
+		external.URL + "/gone": http.StatusGone,
+	}
+	if len(broken) != len(want) {
+		t.Fatalf("Expected %d broken links, got %+v", len(want), broken)
+	}
+	for _, link := range broken {
+		if want[link.TargetURL] != link.StatusCode {
+			t.Errorf("Unexpected broken link %+v", link)
+		}
+		if link.SourceURL != site.URL+"/" {
+			t.Errorf("Expected source %s, got %s", site.URL+"/", link.SourceURL)
+		}
+	}
+}
+
+func TestCheckCommandRejectsFormat(t *testing.T) {
+	defer rootCmd.SetArgs(nil)
+	rootCmd.SetArgs([]string{"check", "https://example.com", "--format", "xml",
+		"--database", filepath.Join(t.TempDir(), "check.db")})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("Expected an unsupported format to fail before crawling")
+	}
+}
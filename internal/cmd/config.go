@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+// errInvalidConfig is returned by `config validate` when a problem is found
+var errInvalidConfig = errors.New("the configuration is invalid")
+
+// configCmd groups configuration subcommands
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Configuration commands",
+}
+
+// configValidateCmd checks a configuration without crawling
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration and report every problem found",
+	Long: `Load the configuration the crawl command would use — the config file, LT_
+environment variables and LT_HEADER_* headers — and check it without
+crawling: every setting is validated, include, exclude, render, redaction and
+webhook patterns are compiled, and the environment variables credentials are
+read from must be set. Their values are never printed.
+
+Every problem is reported at once, and the command exits with status 1 when
+there is one, so a configuration can be checked in CI before it is deployed.`,
+	Example: `  linktadoru config validate
+  linktadoru config validate --config crawls/shop.yml`,
+	Args: cobra.NoArgs,
+	RunE: runConfigValidate,
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	problems := configProblems(cmd)
+	if len(problems) == 0 {
+		_, _ = fmt.Fprintln(out, "Configuration is valid")
+		return nil
+	}
+
+	for _, problem := range problems {
+		_, _ = fmt.Fprintf(out, "  - %s\n", problem)
+	}
+	noun := "problems"
+	if len(problems) == 1 {
+		noun = "problem"
+	}
+	_, _ = fmt.Fprintf(out, "%d configuration %s found\n", len(problems), noun)
+	cmd.SilenceUsage = true // Not a usage error
+	return errInvalidConfig
+}
+
+// configProblems loads the configuration like the crawl command and returns
+// every problem found in it
+func configProblems(cmd *cobra.Command) []string {
+	var problems []string
+
+	// initConfig ignores a config file it cannot read; here that is a problem
+	if err := viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if cfgFile != "" || !errors.As(err, &notFound) {
+			problems = append(problems, fmt.Sprintf("failed to read config file: %v", err))
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	if err := viper.Unmarshal(cfg); err != nil {
+		return append(problems, fmt.Sprintf("failed to unmarshal config: %v", err))
+	}
+	if _, err := loadHeaders(cmd, cfg); err != nil {
+		problems = append(problems, err.Error())
+	}
+	for _, err := range cfg.Problems() {
+		problems = append(problems, err.Error())
+	}
+	for _, unset := range cfg.UnsetEnvVars() {
+		problems = append(problems, fmt.Sprintf("environment variable not set: %s", unset))
+	}
+	return problems
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestConfigValidateCommand(t *testing.T) {
+	dir := t.TempDir()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		cfgFile = ""
+		viper.Reset()
+	}()
+
+	validate := func(content string) error {
+		t.Helper()
+		path := filepath.Join(dir, "linktadoru.yml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		out.Reset()
+		viper.Reset()
+		rootCmd.SetArgs([]string{"config", "validate", "--config", path})
+		return rootCmd.Execute()
+	}
+
+	if err := validate("concurrency: 4\ninclude_patterns:\n  - \"^https://example\\\\.com/\"\n"); err != nil {
+		t.Fatalf("Expected a valid configuration, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Configuration is valid") {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+
+	// Every problem is reported, and the secret's variable by name only
+	t.Setenv("LT_TEST_TOKEN", "")
+	err := validate(`concurrency: 0
+include_patterns: ["("]
+exclude_patterns: ["["]
+auth:
+  type: bearer
+  bearer:
+    token_env: LT_TEST_TOKEN
+`)
+	if !errors.Is(err, errInvalidConfig) {
+		t.Fatalf("Expected errInvalidConfig, got %v", err)
+	}
+	report := out.String()
+	for _, want := range []string{
+		"concurrency must be greater than 0",
+		"include pattern '('",
+		"exclude pattern '['",
+		"bearer auth requires token",
+		"environment variable not set: auth.bearer.token_env (LT_TEST_TOKEN)",
+		"5 configuration problems found",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected %q in report:\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// dbCmd groups database maintenance subcommands
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Database maintenance commands",
+}
+
+// dbMigrateCmd upgrades a database to the schema of this build
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade a crawl database to the current schema version",
+	Long: `Upgrade a database written by an older release to the schema of this
+build. The pending migration steps are applied in order in one transaction,
+so a failed upgrade leaves the database as it was, and each applied step is
+listed. Crawls upgrade the database they write to in the same way; reporting
+commands refuse older databases until they are migrated.`,
+	Args: cobra.NoArgs,
+	RunE: runDBMigrate,
+}
+
+// dbMaintainCmd prunes, compacts and reports on a database
+var dbMaintainCmd = &cobra.Command{
+	Use:   "maintain",
+	Short: "Prune old error rows, vacuum and analyze a crawl database",
+	Long: `Keep a long-lived crawl database small and fast: delete crawl_errors rows
+older than --prune-errors (when set), rebuild the database files with VACUUM,
+refresh the query planner statistics with ANALYZE, and report the space each
+table takes.
+
+VACUUM needs free disk space about the size of the database and blocks
+crawls writing to it, so run maintenance between crawls; it is refused while
+another process is crawling the database.`,
+	Args: cobra.NoArgs,
+	RunE: runDBMaintain,
+}
+
+func init() {
+	dbMaintainCmd.Flags().Duration("prune-errors", 0, "Delete crawl error rows older than this, e.g. 720h (0=keep all)")
+	dbMaintainCmd.Flags().Bool("no-vacuum", false, "Skip VACUUM")
+	dbMaintainCmd.Flags().String("format", formatTable, "Table size report format: table, csv or json")
+	dbCmd.PersistentFlags().StringP("database", "d", "./linktadoru.db", "Path to SQLite database file")
+	dbCmd.PersistentFlags().String("results-database", "", "Path to the separate results database, if the crawl used one")
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbCmd.AddCommand(dbMaintainCmd)
+	rootCmd.AddCommand(dbCmd)
+}
+
+// loadSubcommandConfig builds the configuration for a subcommand. It applies
+// the config file and LT_ environment variables like the crawl command, then
+// --database and --results-database flags given to the subcommand.
+func loadSubcommandConfig(cmd *cobra.Command) (*config.CrawlConfig, error) {
+	cfg := config.DefaultConfig()
+	if err := viper.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	if flag := cmd.Flags().Lookup("database"); flag != nil && flag.Changed {
+		cfg.DatabasePath = flag.Value.String()
+	}
+	if flag := cmd.Flags().Lookup("results-database"); flag != nil && flag.Changed {
+		cfg.ResultsDatabasePath = flag.Value.String()
+	}
+	return cfg, nil
+}
+
+// openStorage opens the configured database, attaching the results database when one is set
+func openStorage(cfg *config.CrawlConfig) (*storage.SQLiteStorage, error) {
+	return storage.NewSQLiteStorageWithResults(cfg.DatabasePath, cfg.ResultsDatabasePath, cfg.GetDatabasePassphrase())
+}
+
+// openExistingStorage opens the configured database, failing if it does not exist
+func openExistingStorage(cfg *config.CrawlConfig) (*storage.SQLiteStorage, error) {
+	if _, err := os.Stat(cfg.DatabasePath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("database not found at %s", cfg.DatabasePath)
+	}
+	store, err := openStorage(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", cfg.DatabasePath, err)
+	}
+	return store, nil
+}
+
+// openReadOnlyStorage opens the configured database for a reporting command,
+// which never migrates or writes to it. It fails if the database does not
+// exist or was written with an older schema.
+func openReadOnlyStorage(cfg *config.CrawlConfig) (*storage.SQLiteStorage, error) {
+	if _, err := os.Stat(cfg.DatabasePath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("database not found at %s", cfg.DatabasePath)
+	}
+	store, err := storage.OpenSQLiteStorageReadOnly(cfg.DatabasePath, cfg.ResultsDatabasePath, cfg.GetDatabasePassphrase())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", cfg.DatabasePath, err)
+	}
+	return store, nil
+}
+
+func runDBMigrate(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(cfg.DatabasePath); os.IsNotExist(err) {
+		return fmt.Errorf("database not found at %s", cfg.DatabasePath)
+	}
+	// The pending steps are applied in one transaction; on failure the
+	// database is left as it was
+	store, err := storage.MigrateSQLiteStorage(cfg.DatabasePath, cfg.ResultsDatabasePath, cfg.GetDatabasePassphrase())
+	if err != nil {
+		return fmt.Errorf("failed to migrate database %s: %w", cfg.DatabasePath, err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if _, err := store.RecordToolVersion(toolVersion()); err != nil {
+		return fmt.Errorf("failed to record tool version: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	applied := store.AppliedMigrations()
+	for _, name := range applied {
+		fmt.Fprintf(out, "Applied migration: %s\n", name)
+	}
+	if len(applied) == 0 {
+		fmt.Fprintf(out, "Database %s is already at schema version %d\n", cfg.DatabasePath, storage.SchemaVersion)
+	} else {
+		fmt.Fprintf(out, "Database %s is at schema version %d\n", cfg.DatabasePath, storage.SchemaVersion)
+	}
+	return nil
+}
+
+func runDBMaintain(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	pruneAge, _ := cmd.Flags().GetDuration("prune-errors")
+	noVacuum, _ := cmd.Flags().GetBool("no-vacuum")
+	format, _ := cmd.Flags().GetString("format")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	if pruneAge < 0 {
+		return fmt.Errorf("--prune-errors must not be negative, got %s", pruneAge)
+	}
+
+	store, err := openExistingStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	// Progress goes to stderr so the size report can be redirected
+	progress := cmd.ErrOrStderr()
+	if pruneAge > 0 {
+		pruned, err := store.PruneErrors(time.Now().Add(-pruneAge))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(progress, "Pruned %d crawl error rows older than %s\n", pruned, pruneAge)
+	}
+	if !noVacuum {
+		live, err := store.LiveCrawlProcesses()
+		if err != nil {
+			return err
+		}
+		if len(live) > 0 {
+			return fmt.Errorf("a crawl is running on %s (process %s); run maintenance after it ends or pass --no-vacuum",
+				cfg.DatabasePath, live[0].ID)
+		}
+		if err := store.Vacuum(); err != nil {
+			return err
+		}
+		fmt.Fprintln(progress, "Vacuumed database")
+	}
+	if err := store.Analyze(); err != nil {
+		return err
+	}
+	fmt.Fprintln(progress, "Analyzed database")
+
+	sizes, err := store.TableSizes()
+	if err != nil {
+		return err
+	}
+	if sizes == nil {
+		sizes = []storage.TableSize{}
+	}
+	rows := make([][]string, 0, len(sizes))
+	for _, size := range sizes {
+		rows = append(rows, []string{size.Database, size.Table, strconv.FormatInt(size.Bytes, 10)})
+	}
+	headers := []string{"DATABASE", "TABLE", "BYTES"}
+	return writeReport(cmd.OutOrStdout(), format, headers, rows, sizes)
+}
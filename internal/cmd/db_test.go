@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+func TestDBMigrateCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "migrate.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"db", "migrate", "--database", dbPath})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("db migrate failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "already at schema version") {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+
+	rootCmd.SetArgs([]string{"db", "migrate", "--database", filepath.Join(t.TempDir(), "missing.db")})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("Expected error for missing database")
+	}
+}
+
+func TestReportingLeavesOlderDatabaseUnchanged(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "old.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/"})
+	if err := store.SetMeta("schema_version", "13"); err != nil {
+		t.Fatalf("Failed to set schema version: %v", err)
+	}
+	_ = store.Close()
+	before, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to read database: %v", err)
+	}
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	rootCmd.SetArgs([]string{"stats", "--database", dbPath})
+	err = rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "linktadoru db migrate") {
+		t.Errorf("Expected stats to refuse the older database naming db migrate, got %v", err)
+	}
+	after, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to read database: %v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("stats changed the older database")
+	}
+
+	// db migrate applies the pending steps, after which stats reads it
+	rootCmd.SetArgs([]string{"db", "migrate", "--database", dbPath})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("db migrate failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Applied migration: ") {
+		t.Errorf("Expected the applied steps to be listed, got %q", out.String())
+	}
+	rootCmd.SetArgs([]string{"stats", "--database", dbPath})
+	if err := rootCmd.Execute(); err != nil {
+		t.Errorf("stats failed after db migrate: %v", err)
+	}
+}
+
+func TestDBMaintainCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "maintain.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.Close()
+
+	var out, progress bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&progress)
+	rootCmd.SetArgs([]string{"db", "maintain", "--database", dbPath, "--prune-errors", "720h", "--format", "csv"})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("db maintain failed: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "DATABASE,TABLE,BYTES\n") || !strings.Contains(out.String(), "main,pages,") {
+		t.Errorf("Unexpected report: %q", out.String())
+	}
+	for _, want := range []string{"Pruned 0 crawl error rows", "Vacuumed", "Analyzed"} {
+		if !strings.Contains(progress.String(), want) {
+			t.Errorf("Expected progress %q, got %q", want, progress.String())
+		}
+	}
+}
+
+func TestDBMaintainRefusesVacuumDuringCrawl(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "busy.db")
+
+	// A crawl process holding a claim on the database
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	_ = store.AddToQueue([]string{"https://example.com/"})
+	if _, err := store.GetNextFromQueue(); err != nil {
+		t.Fatalf("GetNextFromQueue failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs([]string{"db", "maintain", "--database", dbPath})
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	err = rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "a crawl is running") {
+		t.Errorf("Expected vacuum to be refused during a crawl, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// publishRuntimeVars adds the goroutine count to the memstats that expvar
+// publishes on its own. expvar panics on a second Publish of a name, hence
+// the Once.
+var publishRuntimeVars = sync.OnceFunc(func() {
+	expvar.Publish("goroutines", expvar.Func(func() any { return runtime.NumGoroutine() }))
+})
+
+// hiddenDebugVars are expvar variables the debug server does not serve:
+// cmdline holds os.Args, which carry --auth-password, --serve-token and
+// other secrets given as flags
+var hiddenDebugVars = map[string]bool{"cmdline": true}
+
+// debugHandler serves the pprof profiles under /debug/pprof/ and the runtime
+// stats of expvar under /debug/vars. The command line is served by neither.
+func debugHandler() http.Handler {
+	publishRuntimeVars()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", http.NotFound)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/vars", serveDebugVars)
+	return mux
+}
+
+// serveDebugVars writes the expvar variables as expvar.Handler does, leaving
+// out hiddenDebugVars
+func serveDebugVars(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	fmt.Fprint(w, "{\n")
+	first := true
+	expvar.Do(func(kv expvar.KeyValue) {
+		if hiddenDebugVars[kv.Key] {
+			return
+		}
+		if !first {
+			fmt.Fprint(w, ",\n")
+		}
+		first = false
+		fmt.Fprintf(w, "%q: %s", kv.Key, kv.Value)
+	})
+	fmt.Fprint(w, "\n}\n")
+}
+
+// startDebugServer serves debugHandler on addr in the background until the
+// returned function is called
+func startDebugServer(addr string, out io.Writer) (stop func(), err error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	srv := &http.Server{
+		Handler:           debugHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Debug server failed", "error", err)
+		}
+	}()
+	fmt.Fprintf(out, "Serving pprof and runtime stats on http://%s/debug/\n", listener.Addr())
+	if !isLoopbackAddress(addr) {
+		slog.Warn("The debug server has no authentication and is reachable from other hosts; profiles can reveal crawl data from memory. Bind it to 127.0.0.1",
+			"address", addr)
+	}
+
+	// Profiles still being recorded are cut off rather than waited for
+	return func() { _ = srv.Close() }, nil
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	ts := httptest.NewServer(debugHandler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/debug/pprof/goroutine?debug=1")
+	if err != nil {
+		t.Fatalf("GET goroutine profile failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "goroutine profile:") {
+		t.Errorf("Goroutine profile = %d %q", resp.StatusCode, body)
+	}
+
+	resp, err = http.Get(ts.URL + "/debug/vars")
+	if err != nil {
+		t.Fatalf("GET /debug/vars failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var vars map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		t.Fatalf("Failed to decode /debug/vars: %v", err)
+	}
+	for _, name := range []string{"memstats", "goroutines"} {
+		if _, ok := vars[name]; !ok {
+			t.Errorf("/debug/vars has no %q", name)
+		}
+	}
+
+	// Creating a second handler must not publish the variables again
+	debugHandler()
+}
+
+func TestDebugHandlerHidesCommandLine(t *testing.T) {
+	const secret = "debug-s3cr3t-token"
+	args := os.Args
+	os.Args = append(slices.Clone(args), "--serve-token", secret, "--auth-password="+secret)
+	defer func() { os.Args = args }()
+
+	ts := httptest.NewServer(debugHandler())
+	defer ts.Close()
+
+	for _, path := range []string{"/debug/pprof/cmdline", "/debug/vars", "/debug/pprof/"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if strings.Contains(string(body), secret) {
+			t.Errorf("%s reveals the command line: %s", path, body)
+		}
+		if path == "/debug/vars" {
+			var vars map[string]json.RawMessage
+			if err := json.Unmarshal(body, &vars); err != nil {
+				t.Fatalf("Failed to decode /debug/vars: %v\n%s", err, body)
+			}
+			if _, ok := vars["cmdline"]; ok {
+				t.Error("/debug/vars still has cmdline")
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// errDiffFound is returned by `diff --exit-code` when the crawls differ
+var errDiffFound = errors.New("the crawls differ")
+
+// diffCmd compares two crawl databases
+var diffCmd = &cobra.Command{
+	Use:   "diff OLD.db NEW.db",
+	Short: "Report pages added, removed or changed between two crawls",
+	Long: `Compare two crawls of a site, e.g. before and after a release, page by page:
+pages only the new crawl reached (added), pages only the old crawl reached
+(removed), pages whose HTTP status code changed (or that now fail or are
+skipped), and pages completed in both crawls whose title, meta description or
+content hash changed.
+
+Only pages that were crawled, failed or were skipped count; URLs still queued
+or only seen as link targets are ignored. A summary goes to stderr so the
+report can be redirected. --exit-code makes the command fail when the crawls
+differ, for regression checks in CI.`,
+	Example: `  linktadoru diff before.db after.db
+  linktadoru diff before.db after.db --changes status,title --format csv
+  linktadoru diff before.db after.db --changes removed,status --exit-code`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDiff,
+}
+
+func init() {
+	diffCmd.Flags().String("format", formatTable, "Output format: table, csv or json")
+	diffCmd.Flags().StringSlice("changes", nil, "Kinds of change to report: "+strings.Join(storage.DiffKinds, ", ")+" (default all)")
+	diffCmd.Flags().StringP("output", "o", "", "Write the report to this file instead of stdout")
+	diffCmd.Flags().Bool("exit-code", false, "Exit with an error when a reported change is found")
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	kinds, _ := cmd.Flags().GetStringSlice("changes")
+	outputPath, _ := cmd.Flags().GetString("output")
+	exitCode, _ := cmd.Flags().GetBool("exit-code")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	selected, err := diffKinds(kinds)
+	if err != nil {
+		return err
+	}
+
+	snapshots := make([]map[string]storage.PageSnapshot, len(args))
+	for i, path := range args {
+		dbCfg := *cfg
+		dbCfg.DatabasePath = path
+		dbCfg.ResultsDatabasePath = ""
+		store, err := openReadOnlyStorage(&dbCfg)
+		if err != nil {
+			return err
+		}
+		snapshots[i], err = store.GetPageSnapshots()
+		_ = store.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+
+	changes := []storage.PageChange{}
+	counts := make(map[string]int)
+	for _, change := range storage.DiffCrawls(snapshots[0], snapshots[1]) {
+		if selected[change.Change] {
+			changes = append(changes, change)
+			counts[change.Change]++
+		}
+	}
+	var summary []string
+	for _, kind := range storage.DiffKinds {
+		if selected[kind] {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[kind], kind))
+		}
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Compared %d with %d pages: %s\n",
+		len(snapshots[0]), len(snapshots[1]), strings.Join(summary, ", "))
+
+	var out io.Writer = cmd.OutOrStdout()
+	if outputPath != "" {
+		file, err := os.Create(outputPath) // #nosec G304 -- path comes from the command line
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		out = file
+	}
+	rows := make([][]string, 0, len(changes))
+	for _, change := range changes {
+		rows = append(rows, []string{change.Change, change.URL, change.Old, change.New})
+	}
+	if err := writeReport(out, format, []string{"CHANGE", "URL", "OLD", "NEW"}, rows, changes); err != nil {
+		return err
+	}
+
+	if exitCode && len(changes) > 0 {
+		cmd.SilenceUsage = true // Not a usage error
+		return errDiffFound
+	}
+	return nil
+}
+
+// diffKinds returns the set of change kinds to report; none means all of them
+func diffKinds(kinds []string) (map[string]bool, error) {
+	if len(kinds) == 0 {
+		kinds = storage.DiffKinds
+	}
+	selected := make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		valid := false
+		for _, known := range storage.DiffKinds {
+			valid = valid || kind == known
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown change '%s': must be one of %s", kind, strings.Join(storage.DiffKinds, ", "))
+		}
+		selected[kind] = true
+	}
+	return selected, nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// writeCrawl saves completed pages, by URL, into a new database at path
+func writeCrawl(t *testing.T, path string, pages map[string]*crawler.PageData) {
+	t.Helper()
+	store, err := storage.NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	var urls []string
+	for url := range pages {
+		urls = append(urls, url)
+	}
+	_ = store.AddToQueue(urls)
+	for range urls {
+		item, _ := store.GetNextFromQueue()
+		page := pages[item.URL]
+		page.URL, page.HTTPHeaders, page.CrawledAt = item.URL, map[string]string{}, time.Now()
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save page: %v", err)
+		}
+	}
+}
+
+func TestDiffCommand(t *testing.T) {
+	dir := t.TempDir()
+	oldPath, newPath := filepath.Join(dir, "old.db"), filepath.Join(dir, "new.db")
+	writeCrawl(t, oldPath, map[string]*crawler.PageData{
+		"https://example.com/":    {StatusCode: 200, Title: "Home"},
+		"https://example.com/old": {StatusCode: 200, Title: "Old"},
+	})
+	writeCrawl(t, newPath, map[string]*crawler.PageData{
+		"https://example.com/":    {StatusCode: 200, Title: "Start"},
+		"https://example.com/new": {StatusCode: 200, Title: "New"},
+	})
+
+	resetChanges := func() {
+		_ = diffCmd.Flags().Lookup("changes").Value.(interface{ Replace([]string) error }).Replace(nil)
+	}
+	var out, errOut bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&errOut)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+		_ = diffCmd.Flags().Set("format", formatTable)
+		_ = diffCmd.Flags().Set("exit-code", "false")
+		resetChanges()
+	}()
+
+	rootCmd.SetArgs([]string{"diff", oldPath, newPath, "--format", "csv"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+	want := "CHANGE,URL,OLD,NEW\n" +
+		"added,https://example.com/new,,200\n" +
+		"removed,https://example.com/old,200,\n" +
+		"title,https://example.com/,Home,Start\n"
+	if out.String() != want {
+		t.Errorf("Unexpected report:\n%s", out.String())
+	}
+	if summary := errOut.String(); !strings.Contains(summary, "Compared 2 with 2 pages: 1 added, 1 removed, 0 status, 1 title") {
+		t.Errorf("Unexpected summary: %q", summary)
+	}
+
+	// Only status changes are selected, and there are none
+	out.Reset()
+	rootCmd.SetArgs([]string{"diff", oldPath, newPath, "--changes", "status", "--exit-code"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Expected no status changes, got %v", err)
+	}
+
+	resetChanges()
+	rootCmd.SetArgs([]string{"diff", oldPath, newPath, "--changes", "removed", "--exit-code"})
+	if err := rootCmd.Execute(); !errors.Is(err, errDiffFound) {
+		t.Errorf("Expected errDiffFound, got %v", err)
+	}
+
+	resetChanges()
+	rootCmd.SetArgs([]string{"diff", oldPath, newPath, "--changes", "headers"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "unknown change") {
+		t.Errorf("Expected an unknown change error, got %v", err)
+	}
+}
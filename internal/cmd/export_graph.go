@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// Graph formats accepted by `export graph --format`
+const (
+	graphFormatGraphML = "graphml"
+	graphFormatDOT     = "dot"
+	graphFormatGEXF    = "gexf"
+)
+
+// exportGraphCmd writes the link graph for graph visualization tools
+var exportGraphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export the link graph as GraphML, DOT or GEXF",
+	Long: `Export the link graph of a crawl for Gephi, Cytoscape, Graphviz and other
+graph tools. Pages are nodes with their url, crawl status, HTTP status code,
+title and click depth; links are directed edges with their link type and rel
+attribute.
+
+Click depth is the fewest links to follow from a root page (-1 when no root
+links to the page). The roots are the --root URLs, by default the seed URLs
+of the crawls on the database.
+
+Asset links (stylesheets, scripts, images) are left out unless
+--include-assets is set.`,
+	Example: `  linktadoru export graph --format graphml --out site.graphml
+  linktadoru export graph --format dot | dot -Tsvg > site.svg`,
+	Args: cobra.NoArgs,
+	RunE: runExportGraph,
+}
+
+func init() {
+	exportGraphCmd.Flags().String("format", graphFormatGraphML, "Output format: graphml, dot or gexf")
+	exportGraphCmd.Flags().StringP("out", "o", "", "Write the graph to this file instead of stdout")
+	exportGraphCmd.Flags().StringSlice("root", []string{}, "URLs click depth is counted from (default: the crawl's seed URLs)")
+	exportGraphCmd.Flags().Bool("include-assets", false, "Include links to stylesheets, scripts, images and icons")
+	exportCmd.AddCommand(exportGraphCmd)
+}
+
+func runExportGraph(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	outPath, _ := cmd.Flags().GetString("out")
+	roots, _ := cmd.Flags().GetStringSlice("root")
+	includeAssets, _ := cmd.Flags().GetBool("include-assets")
+
+	var write func(io.Writer, *storage.LinkGraph) error
+	switch format {
+	case graphFormatGraphML:
+		write = writeGraphML
+	case graphFormatDOT:
+		write = writeDOT
+	case graphFormatGEXF:
+		write = writeGEXF
+	default:
+		return fmt.Errorf("unsupported format '%s': must be one of graphml, dot, gexf", format)
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	graph, err := store.LoadLinkGraph(includeAssets)
+	if err != nil {
+		return err
+	}
+	if len(roots) == 0 {
+		if roots, err = store.CrawlRoots(); err != nil {
+			return err
+		}
+	}
+	graph.SetDepths(roots)
+
+	out := cmd.OutOrStdout()
+	if outPath != "" {
+		file, err := os.Create(outPath) // #nosec G304 -- path comes from the command line
+		if err != nil {
+			return fmt.Errorf("failed to create graph file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		out = file
+	}
+	bw := bufio.NewWriter(out)
+	if err := write(bw, graph); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if outPath != "" {
+		recordArtifact(cfg.DatabasePath, "link_graph", outPath)
+	}
+	return nil
+}
+
+// writeGraphML writes the graph as GraphML
+func writeGraphML(w io.Writer, graph *storage.LinkGraph) error {
+	ew := &errWriter{w: w}
+	ew.printf(`<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="url" for="node" attr.name="url" attr.type="string"/>
+  <key id="status" for="node" attr.name="status" attr.type="string"/>
+  <key id="status_code" for="node" attr.name="status_code" attr.type="int"/>
+  <key id="title" for="node" attr.name="title" attr.type="string"/>
+  <key id="depth" for="node" attr.name="depth" attr.type="int"/>
+  <key id="link_type" for="edge" attr.name="link_type" attr.type="string"/>
+  <key id="rel" for="edge" attr.name="rel" attr.type="string"/>
+  <graph id="links" edgedefault="directed">
+`)
+	for _, node := range graph.Nodes {
+		ew.printf(`    <node id="n%d">`, node.ID)
+		ew.printf(`<data key="url">%s</data><data key="status">%s</data>`, xmlText(node.URL), xmlText(node.Status))
+		if node.StatusCode != 0 {
+			ew.printf(`<data key="status_code">%d</data>`, node.StatusCode)
+		}
+		if node.Title != "" {
+			ew.printf(`<data key="title">%s</data>`, xmlText(node.Title))
+		}
+		ew.printf("<data key=\"depth\">%d</data></node>\n", node.Depth)
+	}
+	for i, edge := range graph.Edges {
+		ew.printf(`    <edge id="e%d" source="n%d" target="n%d">`, i+1, edge.Source, edge.Target)
+		ew.printf(`<data key="link_type">%s</data>`, xmlText(edge.LinkType))
+		if edge.Rel != "" {
+			ew.printf(`<data key="rel">%s</data>`, xmlText(edge.Rel))
+		}
+		ew.printf("</edge>\n")
+	}
+	ew.printf("  </graph>\n</graphml>\n")
+	return ew.err
+}
+
+// writeDOT writes the graph in the Graphviz DOT language
+func writeDOT(w io.Writer, graph *storage.LinkGraph) error {
+	ew := &errWriter{w: w}
+	ew.printf("digraph links {\n")
+	for _, node := range graph.Nodes {
+		ew.printf("  n%d [label=%s, url=%s, status=%s", node.ID, dotString(node.URL), dotString(node.URL), dotString(node.Status))
+		if node.StatusCode != 0 {
+			ew.printf(", status_code=%d", node.StatusCode)
+		}
+		if node.Title != "" {
+			ew.printf(", title=%s", dotString(node.Title))
+		}
+		ew.printf(", depth=%d];\n", node.Depth)
+	}
+	for _, edge := range graph.Edges {
+		ew.printf("  n%d -> n%d [link_type=%s", edge.Source, edge.Target, dotString(edge.LinkType))
+		if edge.Rel != "" {
+			ew.printf(", rel=%s", dotString(edge.Rel))
+		}
+		ew.printf("];\n")
+	}
+	ew.printf("}\n")
+	return ew.err
+}
+
+// writeGEXF writes the graph as GEXF 1.3, the native format of Gephi
+func writeGEXF(w io.Writer, graph *storage.LinkGraph) error {
+	ew := &errWriter{w: w}
+	ew.printf(`<?xml version="1.0" encoding="UTF-8"?>
+<gexf xmlns="http://gexf.net/1.3" version="1.3">
+  <graph defaultedgetype="directed">
+    <attributes class="node">
+      <attribute id="status" title="status" type="string"/>
+      <attribute id="status_code" title="status_code" type="integer"/>
+      <attribute id="title" title="title" type="string"/>
+      <attribute id="depth" title="depth" type="integer"/>
+    </attributes>
+    <attributes class="edge">
+      <attribute id="link_type" title="link_type" type="string"/>
+      <attribute id="rel" title="rel" type="string"/>
+    </attributes>
+    <nodes>
+`)
+	for _, node := range graph.Nodes {
+		ew.printf(`      <node id="%d" label="%s"><attvalues>`, node.ID, xmlText(node.URL))
+		ew.printf(`<attvalue for="status" value="%s"/>`, xmlText(node.Status))
+		if node.StatusCode != 0 {
+			ew.printf(`<attvalue for="status_code" value="%d"/>`, node.StatusCode)
+		}
+		if node.Title != "" {
+			ew.printf(`<attvalue for="title" value="%s"/>`, xmlText(node.Title))
+		}
+		ew.printf("<attvalue for=\"depth\" value=\"%d\"/></attvalues></node>\n", node.Depth)
+	}
+	ew.printf("    </nodes>\n    <edges>\n")
+	for i, edge := range graph.Edges {
+		ew.printf(`      <edge id="%d" source="%d" target="%d"><attvalues>`, i+1, edge.Source, edge.Target)
+		ew.printf(`<attvalue for="link_type" value="%s"/>`, xmlText(edge.LinkType))
+		if edge.Rel != "" {
+			ew.printf(`<attvalue for="rel" value="%s"/>`, xmlText(edge.Rel))
+		}
+		ew.printf("</attvalues></edge>\n")
+	}
+	ew.printf("    </edges>\n  </graph>\n</gexf>\n")
+	return ew.err
+}
+
+// errWriter formats to w until the first write error, which it keeps
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, args...)
+	}
+}
+
+// xmlText escapes s for XML character data and attribute values
+func xmlText(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// dotEscaper escapes the characters DOT strings cannot hold literally
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+
+// dotString quotes s as a DOT string
+func dotString(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+func TestExportGraphCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "graph.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/"})
+	item, _ := store.GetNextFromQueue()
+	err = store.SavePageResult(item.ID, &crawler.PageData{
+		URL:         item.URL,
+		StatusCode:  200,
+		Title:       `Tom & "Jerry"`,
+		HTTPHeaders: map[string]string{},
+		CrawledAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to save page: %v", err)
+	}
+	_ = store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/about", LinkType: "internal"},
+	})
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	for _, format := range []string{"graphml", "gexf"} {
+		out.Reset()
+		rootCmd.SetArgs([]string{"export", "graph", "--database", dbPath, "--format", format})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("export graph --format %s failed: %v", format, err)
+		}
+		var doc struct {
+			XMLName xml.Name
+		}
+		if err := xml.Unmarshal(out.Bytes(), &doc); err != nil || doc.XMLName.Local != format {
+			t.Errorf("Invalid %s output (%v): %s", format, err, out.String())
+		}
+		if !strings.Contains(out.String(), "Tom &amp; &#34;Jerry&#34;") {
+			t.Errorf("Expected the escaped title in %s output: %s", format, out.String())
+		}
+	}
+
+	out.Reset()
+	rootCmd.SetArgs([]string{"export", "graph", "--database", dbPath, "--format", "dot"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("export graph --format dot failed: %v", err)
+	}
+	for _, want := range []string{
+		`title="Tom & \"Jerry\"", depth=0];`,
+		`label="https://example.com/about"`,
+		`depth=1];`,
+		`n1 -> n2 [link_type="internal"];`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in DOT output: %s", want, out.String())
+		}
+	}
+
+	rootCmd.SetArgs([]string{"export", "graph", "--database", dbPath, "--format", "svg"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+func TestExportCommand(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "export.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/"})
+	item, _ := store.GetNextFromQueue()
+	err = store.SavePageResult(item.ID, &crawler.PageData{
+		URL:         item.URL,
+		StatusCode:  200,
+		Title:       "Home, sweet home",
+		HTTPHeaders: map[string]string{},
+		CrawledAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to save page: %v", err)
+	}
+	_ = store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/about", LinkType: "internal"},
+	})
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		resetExportFlags()
+	}()
+
+	outPath := filepath.Join(dir, "pages.csv")
+	rootCmd.SetArgs([]string{"export", "--database", dbPath, "--table", "pages", "--columns", "url,title",
+		"--status", "completed", "--out", outPath})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if want := "url,title\nhttps://example.com/,\"Home, sweet home\"\n"; string(data) != want {
+		t.Errorf("Unexpected export %q, want %q", data, want)
+	}
+
+	resetExportFlags()
+	rootCmd.SetArgs([]string{"export", "--database", dbPath, "--table", "links", "--columns", "source_url,target_url,target_status",
+		"--status", "discovered", "--out", ""})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("export links failed: %v", err)
+	}
+	if want := "source_url,target_url,target_status\nhttps://example.com/,https://example.com/about,discovered\n"; out.String() != want {
+		t.Errorf("Unexpected links export %q, want %q", out.String(), want)
+	}
+
+	// JSONL keeps the column order
+	out.Reset()
+	resetExportFlags()
+	rootCmd.SetArgs([]string{"export", "--database", dbPath, "--table", "pages", "--format", "jsonl",
+		"--columns", "url,status_code,title,meta_description"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("export --format jsonl failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 || lines[0] != `{"url":"https://example.com/","status_code":200,"title":"Home, sweet home","meta_description":""}` {
+		t.Errorf("Unexpected JSONL export %q", out.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil || record["url"] != "https://example.com/about" || record["title"] != nil {
+		t.Errorf("Unexpected JSONL record %q (%v)", lines[1], err)
+	}
+
+	resetExportFlags()
+	rootCmd.SetArgs([]string{"export", "--database", dbPath, "--table", "sessions", "--format", "csv"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("Expected error for unknown table")
+	}
+}
+
+// resetExportFlags clears the slice flags, which append across Execute calls
+func resetExportFlags() {
+	for _, name := range []string{"columns", "status"} {
+		flag := exportCmd.Flags().Lookup(name)
+		_ = flag.Value.(interface{ Replace([]string) error }).Replace(nil)
+		flag.Changed = false
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+// Environment variables suggested by `init` for the secrets of each auth type
+const (
+	initPasswordEnv = "LT_AUTH_BASIC_PASSWORD"
+	initTokenEnv    = "LT_AUTH_BEARER_TOKEN"
+	initAPIKeyEnv   = "LT_AUTH_API_KEY_VALUE"
+)
+
+//go:embed init.yml.tmpl
+var initConfigTemplate string
+
+// initTemplate renders the configuration file written by `init`
+var initTemplate = template.Must(template.New("init").Funcs(template.FuncMap{
+	"quote": func(v any) string { return strconv.Quote(fmt.Sprint(v)) },
+}).Parse(initConfigTemplate))
+
+// initCmd writes a commented configuration file to start from
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a commented linktadoru.yml to start from",
+	Long: `Write a configuration file listing every setting with its default and a
+comment explaining it, so a new crawl starts from a complete file rather than
+a blank one.
+
+With --interactive the seed URL, concurrency and authentication are asked
+for first. Secrets are never written to the file: the file names the
+environment variables to read them from, and ` + "`linktadoru config validate`" + `
+reports the ones that are not set. An existing file is only replaced with
+--force.`,
+	Example: `  linktadoru init
+  linktadoru init --interactive
+  linktadoru init --seed https://example.com/ --concurrency 4 -o crawls/example.yml`,
+	Args: cobra.NoArgs,
+	RunE: runInit,
+}
+
+// initAnswers are the values filled into the configuration template
+type initAnswers struct {
+	SeedURLs    []string
+	Concurrency int
+	Auth        config.Auth
+}
+
+func init() {
+	initCmd.Flags().StringP("output", "o", "linktadoru.yml", "Path of the configuration file to write")
+	initCmd.Flags().Bool("force", false, "Overwrite an existing file")
+	initCmd.Flags().BoolP("interactive", "i", false, "Ask for the seed URL, concurrency and authentication")
+	initCmd.Flags().StringSlice("seed", nil, "Seed URLs to write into the file")
+	initCmd.Flags().Int("concurrency", config.DefaultConfig().Concurrency, "Number of concurrent workers to write into the file")
+	rootCmd.AddCommand(initCmd)
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	path, _ := cmd.Flags().GetString("output")
+	force, _ := cmd.Flags().GetBool("force")
+	interactive, _ := cmd.Flags().GetBool("interactive")
+	seeds, _ := cmd.Flags().GetStringSlice("seed")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%s already exists; use --force to overwrite it", path)
+	}
+
+	answers := initAnswers{SeedURLs: seeds, Concurrency: concurrency}
+	if interactive {
+		if err := askInitAnswers(cmd.InOrStdin(), cmd.OutOrStdout(), &answers); err != nil {
+			return err
+		}
+	}
+	for _, seed := range answers.SeedURLs {
+		if err := checkSeedURL(seed); err != nil {
+			return err
+		}
+	}
+	if answers.Concurrency <= 0 {
+		return config.ErrInvalidConcurrency
+	}
+
+	var buf strings.Builder
+	if err := initTemplate.Execute(&buf, answers); err != nil {
+		return fmt.Errorf("failed to render configuration: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(buf.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n", path)
+	return nil
+}
+
+// askInitAnswers prompts for the seed URL, concurrency and authentication,
+// keeping the current answer when a prompt is left empty
+func askInitAnswers(in io.Reader, out io.Writer, answers *initAnswers) error {
+	scanner := bufio.NewScanner(in)
+	ask := func(prompt, current string) (string, error) {
+		if current != "" {
+			prompt = fmt.Sprintf("%s [%s]", prompt, current)
+		}
+		_, _ = fmt.Fprintf(out, "%s: ", prompt)
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", fmt.Errorf("failed to read answer: %w", err)
+			}
+			return current, nil
+		}
+		if answer := strings.TrimSpace(scanner.Text()); answer != "" {
+			return answer, nil
+		}
+		return current, nil
+	}
+
+	seed, err := ask("Seed URL", strings.Join(answers.SeedURLs, ","))
+	if err != nil {
+		return err
+	}
+	answers.SeedURLs = nil
+	for _, seed := range strings.Split(seed, ",") {
+		if seed = strings.TrimSpace(seed); seed != "" {
+			answers.SeedURLs = append(answers.SeedURLs, seed)
+		}
+	}
+
+	concurrency, err := ask("Concurrency", strconv.Itoa(answers.Concurrency))
+	if err != nil {
+		return err
+	}
+	if answers.Concurrency, err = strconv.Atoi(concurrency); err != nil {
+		return fmt.Errorf("invalid concurrency %q", concurrency)
+	}
+
+	authType, err := ask("Authentication (none, basic, bearer, api-key, ntlm, negotiate)", "none")
+	if err != nil {
+		return err
+	}
+	answers.Auth = config.Auth{}
+	switch config.AuthType(authType) {
+	case "none":
+	case config.BasicAuthType, config.NTLMAuthType, config.NegotiateAuthType:
+		basic := &config.BasicAuth{}
+		if basic.Username, err = ask("Username", ""); err != nil {
+			return err
+		}
+		if basic.PasswordEnv, err = ask("Environment variable holding the password", initPasswordEnv); err != nil {
+			return err
+		}
+		answers.Auth = config.Auth{Type: config.AuthType(authType), Basic: basic}
+	case config.BearerAuthType:
+		bearer := &config.BearerAuth{}
+		if bearer.TokenEnv, err = ask("Environment variable holding the token", initTokenEnv); err != nil {
+			return err
+		}
+		answers.Auth = config.Auth{Type: config.BearerAuthType, Bearer: bearer}
+	case config.APIKeyAuthType:
+		apikey := &config.APIKeyAuth{}
+		if apikey.Header, err = ask("API key header", "X-API-Key"); err != nil {
+			return err
+		}
+		if apikey.ValueEnv, err = ask("Environment variable holding the API key", initAPIKeyEnv); err != nil {
+			return err
+		}
+		answers.Auth = config.Auth{Type: config.APIKeyAuthType, APIKey: apikey}
+	default:
+		return fmt.Errorf("unsupported authentication type: %s", authType)
+	}
+	return nil
+}
+
+// checkSeedURL rejects seed URLs the crawler could not fetch
+func checkSeedURL(seed string) error {
+	u, err := url.Parse(seed)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid seed URL %q: expected an http or https URL", seed)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+// loadInitConfig reads a file written by init the way the crawl command does
+func loadInitConfig(t *testing.T, path string) *config.CrawlConfig {
+	t.Helper()
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	cfg := config.DefaultConfig()
+	if err := v.Unmarshal(cfg); err != nil {
+		t.Fatalf("Failed to unmarshal %s: %v", path, err)
+	}
+	return cfg
+}
+
+func TestInitCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "linktadoru.yml")
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetIn(nil)
+		rootCmd.SetArgs(nil)
+		_ = initCmd.Flags().Set("force", "false")
+		_ = initCmd.Flags().Set("interactive", "false")
+		_ = initCmd.Flags().Set("concurrency", "2")
+		_ = initCmd.Flags().Lookup("seed").Value.(interface{ Replace([]string) error }).Replace(nil)
+	}()
+
+	rootCmd.SetArgs([]string{"init", "-o", path, "--seed", "https://example.com/", "--concurrency", "4"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	cfg := loadInitConfig(t, path)
+	if strings.Join(cfg.SeedURLs, ",") != "https://example.com/" || cfg.Concurrency != 4 {
+		t.Errorf("Unexpected seed URLs %v and concurrency %d", cfg.SeedURLs, cfg.Concurrency)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected the written configuration to be valid, got %v", err)
+	}
+
+	// An existing file is kept unless --force is given
+	rootCmd.SetArgs([]string{"init", "-o", path})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected init to refuse overwriting, got %v", err)
+	}
+
+	// Interactive answers, with the password named by environment variable
+	rootCmd.SetIn(strings.NewReader("https://example.com/, https://example.org/\n3\nbasic\ncrawler\n\n"))
+	rootCmd.SetArgs([]string{"init", "-o", path, "--force", "--interactive"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("interactive init failed: %v", err)
+	}
+	cfg = loadInitConfig(t, path)
+	if strings.Join(cfg.SeedURLs, ",") != "https://example.com/,https://example.org/" || cfg.Concurrency != 3 {
+		t.Errorf("Unexpected seed URLs %v and concurrency %d", cfg.SeedURLs, cfg.Concurrency)
+	}
+	if cfg.Auth == nil || cfg.Auth.Type != config.BasicAuthType || cfg.Auth.Basic == nil ||
+		cfg.Auth.Basic.Username != "crawler" || cfg.Auth.Basic.PasswordEnv != initPasswordEnv || cfg.Auth.Basic.Password != "" {
+		t.Errorf("Unexpected auth: %+v", cfg.Auth)
+	}
+	t.Setenv(initPasswordEnv, "secret")
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected the written configuration to be valid, got %v", err)
+	}
+}
+
+func TestInitTemplateCoversExample(t *testing.T) {
+	example, err := os.ReadFile("../../linktadoru.yml.example")
+	if err != nil {
+		t.Fatalf("Failed to read example: %v", err)
+	}
+	// Every setting of the example, set or commented out, is in the template
+	key := regexp.MustCompile(`(?m)^(?:# )?([a-z_]+):`)
+	for _, match := range key.FindAllStringSubmatch(string(example), -1) {
+		if !regexp.MustCompile(`(?m)^(?:# )?` + match[1] + `:`).MatchString(initConfigTemplate) {
+			t.Errorf("Setting %s of linktadoru.yml.example is missing from init.yml.tmpl", match[1])
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// manifestSuffix replaces the database file extension in the manifest name
+const manifestSuffix = ".manifest.json"
+
+// Manifest summarizes a crawl run so downstream automation can discover its
+// outputs. Exports, graphs and reports later written from the database are
+// added to its artifacts.
+type Manifest struct {
+	SessionID       string            `json:"session_id"`
+	RunID           string            `json:"run_id,omitempty"` // UUID sent in run_header, if enabled
+	ToolVersion     string            `json:"tool_version"`
+	ConfigHash      string            `json:"config_hash"`
+	SeedURLs        []string          `json:"seed_urls"`
+	StartedAt       time.Time         `json:"started_at"`
+	FinishedAt      time.Time         `json:"finished_at"`
+	DurationSeconds float64           `json:"duration_seconds"`
+	StopReason      string            `json:"stop_reason"`      // completed, limit, timeout_total or interrupted
+	RemainingSample []string          `json:"remaining_sample"` // Oldest URLs left in the queue
+	RequestDelay    ManifestDelay     `json:"request_delay"`
+	Totals          ManifestTotals    `json:"totals"`
+	Artifacts       map[string]string `json:"artifacts"` // Artifact name -> path
+}
+
+// ManifestTotals contains page counts at the end of a run
+type ManifestTotals struct {
+	PagesCrawled int `json:"pages_crawled"`
+	Errors       int `json:"errors"`
+	Pending      int `json:"pending"`
+	Completed    int `json:"completed"`
+	ErrorPages   int `json:"error_pages"`
+	URLsDropped  int `json:"urls_dropped"`
+	Remaining    int `json:"remaining"` // Pending or in-flight URLs left when the run stopped
+}
+
+// ManifestDelay records the per-host delay settings used for a run
+type ManifestDelay struct {
+	Seconds       float64 `json:"seconds"`        // Configured base delay
+	JitterPercent float64 `json:"jitter_percent"` // Random ± variation applied to each delay
+	MinSeconds    float64 `json:"min_seconds"`    // Shortest delay jitter can produce
+	MaxSeconds    float64 `json:"max_seconds"`    // Longest delay jitter can produce
+}
+
+// newSessionID returns an identifier for a crawl run, sortable by start time
+func newSessionID(startedAt time.Time) string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%x", startedAt.UTC().Format("20060102T150405Z"), suffix)
+}
+
+// configHash returns a SHA-256 hash of the effective configuration, so runs
+// with identical settings can be recognized
+func configHash(cfg *config.CrawlConfig) (string, error) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// manifestPath returns the manifest location for a database path: next to
+// the database and named after it, so databases sharing a directory keep
+// their own manifests
+func manifestPath(databasePath string) string {
+	return strings.TrimSuffix(databasePath, filepath.Ext(databasePath)) + manifestSuffix
+}
+
+// buildManifest assembles the manifest for a finished run. Queue totals are
+// read back from the database; failing to read them leaves them zero.
+func buildManifest(cfg *config.CrawlConfig, sessionID string, stats crawler.CrawlStats, startedAt, finishedAt time.Time) (*Manifest, error) {
+	hash, err := configHash(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	lowest, highest := cfg.DelayBounds()
+	m := &Manifest{
+		SessionID:       sessionID,
+		RunID:           stats.RunID,
+		ToolVersion:     toolVersion(),
+		ConfigHash:      hash,
+		SeedURLs:        cfg.SeedURLs,
+		StartedAt:       startedAt.UTC(),
+		FinishedAt:      finishedAt.UTC(),
+		DurationSeconds: finishedAt.Sub(startedAt).Seconds(),
+		StopReason:      stats.StopReason,
+		RemainingSample: stats.RemainingSample,
+		RequestDelay: ManifestDelay{
+			Seconds:       cfg.RequestDelay,
+			JitterPercent: cfg.RequestJitter,
+			MinSeconds:    lowest.Seconds(),
+			MaxSeconds:    highest.Seconds(),
+		},
+		Totals: ManifestTotals{
+			PagesCrawled: stats.PagesCrawled,
+			Errors:       stats.ErrorCount,
+			URLsDropped:  stats.URLsDropped,
+			Remaining:    stats.RemainingQueued,
+		},
+		Artifacts: map[string]string{
+			"database": cfg.DatabasePath,
+		},
+	}
+	if m.SeedURLs == nil {
+		m.SeedURLs = []string{}
+	}
+	if m.RemainingSample == nil {
+		m.RemainingSample = []string{}
+	}
+	if cfg.LogFile != "" {
+		m.Artifacts["log_file"] = cfg.LogFile
+	}
+	if cfg.ResultsDatabasePath != "" {
+		m.Artifacts["results_database"] = cfg.ResultsDatabasePath
+	}
+
+	// Other storage drivers keep no database file to reopen
+	if storage.DriverName(cfg) != storage.DriverSQLite {
+		return m, nil
+	}
+	store, err := openReadOnlyStorage(cfg)
+	if err == nil {
+		pending, _, completed, errorPages, qerr := store.GetQueueStatus()
+		if qerr == nil {
+			m.Totals.Pending = pending
+			m.Totals.Completed = completed
+			m.Totals.ErrorPages = errorPages
+		}
+		_ = store.Close()
+	}
+
+	return m, nil
+}
+
+// writeManifest writes the manifest as indented JSON and returns its path
+func writeManifest(m *Manifest, databasePath string) (string, error) {
+	path := manifestPath(databasePath)
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return "", fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return path, nil
+}
+
+// recordArtifact adds a file written from a crawl database, such as an export
+// or a report, to the manifest of the database's last run under name. A
+// database without a manifest is left alone; a failure is only logged, since
+// the file itself was written.
+func recordArtifact(databasePath, name, path string) {
+	manifest := manifestPath(databasePath)
+	data, err := os.ReadFile(manifest) // #nosec G304 -- derived from the database path
+	if os.IsNotExist(err) {
+		return
+	}
+	var m Manifest
+	if err == nil {
+		err = json.Unmarshal(data, &m)
+	}
+	if err == nil {
+		if m.Artifacts == nil {
+			m.Artifacts = map[string]string{}
+		}
+		m.Artifacts[name] = path
+		_, err = writeManifest(&m, databasePath)
+	}
+	if err != nil {
+		slog.Warn("Failed to record artifact in the crawl manifest", "manifest", manifest, "artifact", name, "error", err)
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+func TestWriteManifest(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.DatabasePath = filepath.Join(tempDir, "crawl.db")
+	cfg.SeedURLs = []string{"https://example.com"}
+	cfg.RequestDelay = 1
+	cfg.RequestJitter = 10
+
+	store, err := storage.NewSQLiteStorage(cfg.DatabasePath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	if err := store.AddToQueue([]string{"https://example.com", "https://example.com/a"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	_ = store.Close()
+
+	startedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	sessionID := newSessionID(startedAt)
+	if !strings.HasPrefix(sessionID, "20240102T030405Z-") {
+		t.Errorf("Unexpected session ID format: %s", sessionID)
+	}
+
+	stats := crawler.CrawlStats{
+		PagesCrawled:    3,
+		ErrorCount:      1,
+		StopReason:      crawler.StopReasonLimit,
+		RemainingQueued: 2,
+		RemainingSample: []string{"https://example.com", "https://example.com/a"},
+	}
+	m, err := buildManifest(cfg, sessionID, stats, startedAt, startedAt.Add(90*time.Second))
+	if err != nil {
+		t.Fatalf("Failed to build manifest: %v", err)
+	}
+
+	path, err := writeManifest(m, cfg.DatabasePath)
+	if err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	if path != filepath.Join(tempDir, "crawl.manifest.json") {
+		t.Errorf("Unexpected manifest path: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	var got Manifest
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+
+	if got.SessionID != sessionID {
+		t.Errorf("Expected session ID %s, got %s", sessionID, got.SessionID)
+	}
+	if got.DurationSeconds != 90 {
+		t.Errorf("Expected duration 90s, got %v", got.DurationSeconds)
+	}
+	if got.Totals.PagesCrawled != 3 || got.Totals.Errors != 1 || got.Totals.Pending != 2 {
+		t.Errorf("Unexpected totals: %+v", got.Totals)
+	}
+	if got.StopReason != crawler.StopReasonLimit || got.Totals.Remaining != 2 || len(got.RemainingSample) != 2 {
+		t.Errorf("Unexpected stop summary: reason %q, remaining %d, sample %v", got.StopReason, got.Totals.Remaining, got.RemainingSample)
+	}
+	if got.RequestDelay != (ManifestDelay{Seconds: 1, JitterPercent: 10, MinSeconds: 0.9, MaxSeconds: 1.1}) {
+		t.Errorf("Unexpected request delay: %+v", got.RequestDelay)
+	}
+	if got.Artifacts["database"] != cfg.DatabasePath {
+		t.Errorf("Expected database artifact %s, got %s", cfg.DatabasePath, got.Artifacts["database"])
+	}
+	if len(got.ConfigHash) != 64 {
+		t.Errorf("Expected SHA-256 config hash, got %q", got.ConfigHash)
+	}
+
+	// The hash only changes with the configuration
+	other, _ := buildManifest(cfg, sessionID, stats, startedAt, startedAt)
+	if other.ConfigHash != got.ConfigHash {
+		t.Error("Expected identical configurations to hash identically")
+	}
+	cfg.Concurrency++
+	changed, _ := buildManifest(cfg, sessionID, stats, startedAt, startedAt)
+	if changed.ConfigHash == got.ConfigHash {
+		t.Error("Expected a configuration change to change the hash")
+	}
+}
+
+func TestExportRecordsArtifact(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "crawl.db")
+	writeCrawl(t, dbPath, map[string]*crawler.PageData{"https://example.com/": {StatusCode: 200}})
+	if _, err := writeManifest(&Manifest{SessionID: "run", Artifacts: map[string]string{"database": dbPath}}, dbPath); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	// Another database in the directory has a manifest of its own
+	otherPath := filepath.Join(dir, "other.db")
+	if _, err := writeManifest(&Manifest{SessionID: "other"}, otherPath); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	defer func() {
+		rootCmd.SetArgs(nil)
+		resetExportFlags()
+	}()
+	outPath := filepath.Join(dir, "pages.csv")
+	rootCmd.SetArgs([]string{"export", "--database", dbPath, "--table", "pages", "--out", outPath})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	data, err := os.ReadFile(manifestPath(dbPath))
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	var got Manifest
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+	if got.SessionID != "run" || got.Artifacts["export_pages"] != outPath || got.Artifacts["database"] != dbPath {
+		t.Errorf("Unexpected manifest: %+v", got)
+	}
+	if data, _ := os.ReadFile(manifestPath(otherPath)); strings.Contains(string(data), outPath) {
+		t.Errorf("The other database's manifest should be untouched, got %s", data)
+	}
+}
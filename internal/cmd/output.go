@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Report output formats accepted by --format
+const (
+	formatTable = "table"
+	formatCSV   = "csv"
+	formatJSON  = "json"
+)
+
+// checkFormat returns an error if writeReport does not support format, so
+// commands can reject a bad --format before doing any work
+func checkFormat(format string) error {
+	switch format {
+	case formatTable, formatCSV, formatJSON, "":
+		return nil
+	default:
+		return fmt.Errorf("unsupported format '%s': must be one of table, csv, json", format)
+	}
+}
+
+// writeReport writes tabular report data in the requested format. Table and
+// CSV output use headers and rows; JSON output encodes records, which should
+// carry the same data with JSON field tags.
+func writeReport(w io.Writer, format string, headers []string, rows [][]string, records any) error {
+	switch format {
+	case formatTable, "":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(headers); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	default:
+		return checkFormat(format)
+	}
+}
+
+// Structured output formats accepted by --format of --show-config and status.
+// Text is the annotated, human-oriented form; YAML and JSON carry only the
+// data, for wrappers and UIs. -o/--output always names a file to write, never
+// a format.
+const (
+	formatText = "text"
+	formatYAML = "yaml"
+)
+
+// checkStructuredFormat returns an error if writeStructured does not support format
+func checkStructuredFormat(format string) error {
+	switch format {
+	case formatText, formatYAML, formatJSON, "":
+		return nil
+	default:
+		return fmt.Errorf("unsupported format '%s': must be one of text, yaml, json", format)
+	}
+}
+
+// writeStructured writes v as YAML or JSON. JSON is converted from the YAML
+// encoding, so both use the yaml field names (e.g. database_path) and the same
+// value forms (durations as "30s").
+func writeStructured(w io.Writer, format string, v any) error {
+	return encodeStructured(w, format, v, false)
+}
+
+// writeStructuredDocument writes v as one document of a stream, such as a
+// refresh of a watched status: a compact JSON value on a single line, or a
+// YAML document starting with "---".
+func writeStructuredDocument(w io.Writer, format string, v any) error {
+	return encodeStructured(w, format, v, true)
+}
+
+func encodeStructured(w io.Writer, format string, v any, stream bool) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s output: %w", format, err)
+	}
+
+	switch format {
+	case formatYAML:
+		if stream {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		_, err = w.Write(data)
+		return err
+	case formatJSON:
+		var value any
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("failed to convert output to JSON: %w", err)
+		}
+		encoder := json.NewEncoder(w)
+		if !stream {
+			encoder.SetIndent("", "  ")
+		}
+		return encoder.Encode(value)
+	default:
+		return checkStructuredFormat(format)
+	}
+}
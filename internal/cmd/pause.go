@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/server"
+)
+
+const (
+	// pauseFileSuffix names the pause file of a database: crawl.db.pause
+	pauseFileSuffix = ".pause"
+	// pauseFilePollInterval is how often a crawl checks for its pause file
+	pauseFilePollInterval = time.Second
+	// controlRequestTimeout bounds a request to a control API server
+	controlRequestTimeout = 10 * time.Second
+)
+
+// pauseCmd pauses a running crawl
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause a running crawl: stop claiming URLs and finish pages in flight",
+	Long: `Pause the crawl running on a database. Workers stop claiming new URLs;
+pages being fetched are finished and saved, so the queue in the database is
+consistent and the process can be stopped and restarted safely.
+
+Without --server, pause creates a pause file next to the database
+(crawl.db.pause), which the crawl checks every second. The file stays until
+'linktadoru resume', so a crawl restarted in the meantime starts paused.
+With --server, the crawl of a 'linktadoru --serve' process is paused through
+its control API.`,
+	Example: `  linktadoru pause -d crawl.db
+  linktadoru pause --server http://127.0.0.1:8080`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error { return runPauseResume(cmd, true) },
+}
+
+// resumeCmd resumes a paused crawl
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume a crawl paused with 'linktadoru pause'",
+	Long: `Resume a paused crawl. Without --server, the pause file next to the
+database is removed and the crawl claims URLs again within a second. With
+--server, the crawl of a 'linktadoru --serve' process is resumed through its
+control API.`,
+	Example: `  linktadoru resume -d crawl.db
+  linktadoru resume --server http://127.0.0.1:8080`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error { return runPauseResume(cmd, false) },
+}
+
+func init() {
+	for _, c := range []*cobra.Command{pauseCmd, resumeCmd} {
+		c.Flags().StringP("database", "d", "./linktadoru.db", "Path to SQLite database file of the crawl")
+		c.Flags().String("server", "", "Base URL of a control API server, e.g. http://127.0.0.1:8080, instead of the pause file")
+		rootCmd.AddCommand(c)
+	}
+}
+
+func runPauseResume(cmd *cobra.Command, pause bool) error {
+	out := cmd.OutOrStdout()
+	if serverURL, _ := cmd.Flags().GetString("server"); serverURL != "" {
+		action := "resume"
+		if pause {
+			action = "pause"
+		}
+		status, err := postControlAction(cmd.Context(), serverURL, action)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Crawl %s: %d pages crawled, %d queued\n", status.State, status.PagesCrawled, status.Queue.Pending)
+		return nil
+	}
+
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	path := pauseFilePath(cfg.DatabasePath)
+	if !pause {
+		if err := os.Remove(path); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("the crawl on %s is not paused (no %s)", cfg.DatabasePath, path)
+			}
+			return fmt.Errorf("failed to remove pause file: %w", err)
+		}
+		fmt.Fprintf(out, "Removed %s; the crawl resumes within %v\n", path, pauseFilePollInterval)
+		return nil
+	}
+
+	if _, err := os.Stat(cfg.DatabasePath); os.IsNotExist(err) {
+		return fmt.Errorf("database not found at %s", cfg.DatabasePath)
+	}
+	content := fmt.Sprintf("paused at %s\n", time.Now().UTC().Format(time.RFC3339))
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to create pause file: %w", err)
+	}
+	fmt.Fprintf(out, "Created %s; the crawl stops claiming URLs within %v and finishes pages in flight\n",
+		path, pauseFilePollInterval)
+	fmt.Fprintf(out, "Run 'linktadoru resume -d %s' to continue\n", cfg.DatabasePath)
+	return nil
+}
+
+// postControlAction posts a crawl action (pause, resume, ...) to a control
+// API server and returns the new status
+func postControlAction(ctx context.Context, serverURL, action string) (*server.Status, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, controlRequestTimeout)
+	defer cancel()
+
+	url := strings.TrimSuffix(serverURL, "/") + "/api/crawl/" + action
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server URL %q: %w", serverURL, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the control API: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read control API response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			return nil, fmt.Errorf("control API refused to %s: %s", action, apiErr.Error)
+		}
+		return nil, fmt.Errorf("control API refused to %s: %s", action, resp.Status)
+	}
+	var status server.Status
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, fmt.Errorf("invalid control API response: %w", err)
+	}
+	return &status, nil
+}
+
+// pauseFilePath returns the pause file of the database at databasePath
+func pauseFilePath(databasePath string) string {
+	return databasePath + pauseFileSuffix
+}
+
+// watchPauseFile pauses c while the pause file at path exists, until ctx is
+// done. Only changes are acted on, so a crawl paused or resumed by other
+// means is left alone until the file is created or removed; a file that
+// exists when the watch starts pauses the crawl right away.
+func watchPauseFile(ctx context.Context, path string, c crawler.Crawler) {
+	ticker := time.NewTicker(pauseFilePollInterval)
+	defer ticker.Stop()
+	paused := false
+	for {
+		_, err := os.Stat(path)
+		switch exists := err == nil; {
+		case exists && !paused:
+			slog.Info("Pause file found; pausing the crawl", "path", path)
+			c.Pause()
+			paused = true
+		case !exists && paused:
+			slog.Info("Pause file removed; resuming the crawl", "path", path)
+			c.Resume()
+			paused = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestPauseResumeFile(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "crawl.db")
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	rootCmd.SetArgs([]string{"pause", "-d", dbPath, "--server", ""})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "database not found") {
+		t.Errorf("Expected a missing database error, got %v", err)
+	}
+
+	if err := os.WriteFile(dbPath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("pause failed: %v", err)
+	}
+	if _, err := os.Stat(dbPath + ".pause"); err != nil {
+		t.Errorf("Expected a pause file: %v", err)
+	}
+
+	rootCmd.SetArgs([]string{"resume", "-d", dbPath, "--server", ""})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if _, err := os.Stat(dbPath + ".pause"); !os.IsNotExist(err) {
+		t.Errorf("Expected the pause file to be removed, got %v", err)
+	}
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "not paused") {
+		t.Errorf("Expected resuming twice to fail, got %v", err)
+	}
+}
+
+func TestPauseServer(t *testing.T) {
+	var calls []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/api/crawl/resume" {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error": "no crawl is running"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"state": "paused", "pages_crawled": 3, "queue": {"pending": 7}}`))
+	}))
+	defer ts.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	rootCmd.SetArgs([]string{"pause", "--server", ts.URL + "/"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("pause failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Crawl paused: 3 pages crawled, 7 queued") {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+
+	rootCmd.SetArgs([]string{"resume", "--server", ts.URL})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "no crawl is running") {
+		t.Errorf("Expected the API error, got %v", err)
+	}
+	if strings.Join(calls, ",") != "POST /api/crawl/pause,POST /api/crawl/resume" {
+		t.Errorf("Unexpected calls: %v", calls)
+	}
+}
+
+// pauseRecorder is a crawler that only records pauses
+type pauseRecorder struct {
+	crawler.Crawler
+	paused atomic.Bool
+}
+
+func (p *pauseRecorder) Pause()       { p.paused.Store(true) }
+func (p *pauseRecorder) Resume()      { p.paused.Store(false) }
+func (p *pauseRecorder) Paused() bool { return p.paused.Load() }
+
+func TestWatchPauseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.db.pause")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	c := &pauseRecorder{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		watchPauseFile(ctx, path, c)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// A pause file left from before the start pauses right away
+	waitFor(t, c.Paused)
+	_ = os.Remove(path)
+	waitFor(t, func() bool { return !c.Paused() })
+}
+
+// waitFor polls cond until it holds, failing the test after a few seconds
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Condition not met in time")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// Report formats accepted by `report --format`
+const (
+	reportFormatHTML     = "html"
+	reportFormatMarkdown = "markdown"
+)
+
+// reportMaxHops is the redirect chain length reported as too long
+const reportMaxHops = 1
+
+//go:embed report.html.tmpl
+var reportHTML string
+
+//go:embed report.md.tmpl
+var reportMarkdown string
+
+// reportCmd writes a shareable summary of a crawl
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Write a self-contained HTML or Markdown summary of a crawl",
+	Long: `Write a summary of a crawl for people who will not open the database:
+page totals, the HTTP status code breakdown, the slowest pages, broken links
+with the pages linking to them, titles shared by several pages, and redirect
+chains that loop or take more than one hop.
+
+The HTML report is a single file with no external resources, so it can be
+attached to a ticket or mailed. The format follows the --out extension (.md
+for Markdown) unless --format is given. Each list is cut to --limit entries.`,
+	Example: `  linktadoru report --out report.html
+  linktadoru report -d crawl.db --format markdown > report.md`,
+	Args: cobra.NoArgs,
+	RunE: runReport,
+}
+
+func init() {
+	reportCmd.Flags().StringP("database", "d", "./linktadoru.db", "Path to SQLite database file")
+	reportCmd.Flags().String("results-database", "", "Path to the separate results database, if the crawl used one")
+	reportCmd.Flags().String("format", "", "Output format: html or markdown (default: from the --out extension, else html)")
+	reportCmd.Flags().StringP("out", "o", "", "Write the report to this file instead of stdout")
+	reportCmd.Flags().Int("limit", 20, "Most entries shown in each list")
+	rootCmd.AddCommand(reportCmd)
+}
+
+// crawlReport is the data the report templates render
+type crawlReport struct {
+	GeneratedAt string
+	Database    string
+	Roots       []string
+	StopReason  string
+
+	Totals          *storage.ReportTotals
+	StatusCodes     []storage.StatusCodeCount
+	Slowest         []storage.SlowPage
+	BrokenLinks     []storage.BrokenTarget
+	BrokenTotal     int
+	DuplicateTitles []storage.DuplicateTitle
+	DuplicatesTotal int
+	Redirects       []storage.RedirectChain
+	RedirectsTotal  int
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	outPath, _ := cmd.Flags().GetString("out")
+	limit, _ := cmd.Flags().GetInt("limit")
+	if format == "" {
+		format = reportFormatHTML
+		if ext := strings.ToLower(filepath.Ext(outPath)); ext == ".md" || ext == ".markdown" {
+			format = reportFormatMarkdown
+		}
+	}
+	if format != reportFormatHTML && format != reportFormatMarkdown {
+		return fmt.Errorf("unsupported format '%s': must be one of html, markdown", format)
+	}
+	if limit < 1 {
+		return fmt.Errorf("--limit must be at least 1, got %d", limit)
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	report, err := buildReport(store, limit)
+	if err != nil {
+		return err
+	}
+	report.Database = cfg.DatabasePath
+
+	out := cmd.OutOrStdout()
+	if outPath != "" {
+		file, err := os.Create(outPath) // #nosec G304 -- path comes from the command line
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		out = file
+	}
+	bw := bufio.NewWriter(out)
+	if err := writeCrawlReport(bw, format, report); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if outPath != "" {
+		recordArtifact(cfg.DatabasePath, "report", outPath)
+	}
+	return nil
+}
+
+// buildReport gathers the report data, cutting each list to limit entries
+func buildReport(store *storage.SQLiteStorage, limit int) (*crawlReport, error) {
+	report := &crawlReport{
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	var err error
+	if report.Roots, err = store.CrawlRoots(); err != nil {
+		return nil, err
+	}
+	if report.StopReason, err = store.GetMeta(crawler.MetaStopReason); err != nil {
+		return nil, err
+	}
+	if report.Totals, err = store.GetReportTotals(); err != nil {
+		return nil, err
+	}
+	if report.StatusCodes, err = store.GetStatusCodeCounts(); err != nil {
+		return nil, err
+	}
+	if report.Slowest, err = store.GetSlowestPages(limit); err != nil {
+		return nil, err
+	}
+
+	broken, err := store.GetBrokenTargets(false)
+	if err != nil {
+		return nil, err
+	}
+	report.BrokenTotal = len(broken)
+	report.BrokenLinks = broken[:min(limit, len(broken))]
+
+	duplicates, err := store.GetDuplicateTitles()
+	if err != nil {
+		return nil, err
+	}
+	report.DuplicatesTotal = len(duplicates)
+	report.DuplicateTitles = duplicates[:min(limit, len(duplicates))]
+
+	redirects, err := store.GetRedirectChains(reportMaxHops, false)
+	if err != nil {
+		return nil, err
+	}
+	report.RedirectsTotal = len(redirects)
+	report.Redirects = redirects[:min(limit, len(redirects))]
+	return report, nil
+}
+
+// reportFuncs are the helpers shared by both report templates
+var reportFuncs = map[string]any{
+	"bytes":   formatReportBytes,
+	"md":      markdownCell,
+	"join":    strings.Join,
+	"div100":  func(n int) int { return n / 100 },
+	"percent": func(n, total int) string { return fmt.Sprintf("%.1f%%", 100*float64(n)/float64(max(total, 1))) },
+}
+
+// writeCrawlReport renders report in format
+func writeCrawlReport(w io.Writer, format string, report *crawlReport) error {
+	if format == reportFormatMarkdown {
+		tmpl := texttemplate.Must(texttemplate.New("report").Funcs(reportFuncs).Parse(reportMarkdown))
+		return tmpl.Execute(w, report)
+	}
+	tmpl := htmltemplate.Must(htmltemplate.New("report").Funcs(reportFuncs).Parse(reportHTML))
+	return tmpl.Execute(w, report)
+}
+
+// formatReportBytes formats a byte count with a binary unit
+func formatReportBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// markdownEscaper escapes text for a Markdown table cell
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r", "", "\n", " ", "<", "&lt;", ">", "&gt;")
+
+// markdownCell escapes s for a Markdown table cell
+func markdownCell(s string) string {
+	return markdownEscaper.Replace(s)
+}
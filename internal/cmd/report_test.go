@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+func TestReportCommand(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "report.db")
+
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	urls := []string{"https://example.com/", "https://example.com/a", "https://example.com/b", "https://example.com/missing"}
+	_ = store.AddToQueue(urls)
+	pages := map[string]*crawler.PageData{
+		urls[0]: {StatusCode: 200, Title: "Home", TTFB: 300 * time.Millisecond},
+		urls[1]: {StatusCode: 200, Title: "<Shop> | Deals", TTFB: 50 * time.Millisecond},
+		urls[2]: {StatusCode: 200, Title: "<Shop> | Deals", TTFB: 60 * time.Millisecond, Redirects: []crawler.RedirectHop{
+			{StatusCode: 301, FromURL: "https://example.com/b", ToURL: "https://example.com/b/"},
+			{StatusCode: 301, FromURL: "https://example.com/b/", ToURL: "https://example.com/b/index"},
+		}},
+		urls[3]: {StatusCode: 404, TTFB: 10 * time.Millisecond},
+	}
+	for range urls {
+		item, _ := store.GetNextFromQueue()
+		page := pages[item.URL]
+		page.URL, page.HTTPHeaders, page.CrawledAt = item.URL, map[string]string{}, time.Now()
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save page: %v", err)
+		}
+	}
+	_ = store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: urls[0], TargetURL: urls[3], AnchorText: "Gone", LinkType: "internal"},
+	})
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	htmlPath := filepath.Join(dir, "report.html")
+	rootCmd.SetArgs([]string{"report", "--database", dbPath, "--out", htmlPath})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	data, err := os.ReadFile(htmlPath)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	html := string(data)
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"&lt;Shop&gt; | Deals",
+		"Broken links (1)",
+		"https://example.com/missing",
+		"Redirect issues (1)",
+		"too_long",
+		`style="width: 75.0%"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected %q in HTML report", want)
+		}
+	}
+	if strings.Contains(html, "<Shop>") || strings.Contains(html, "ZgotmplZ") {
+		t.Errorf("HTML report is not escaped properly")
+	}
+
+	// The .md extension selects Markdown
+	mdPath := filepath.Join(dir, "report.md")
+	rootCmd.SetArgs([]string{"report", "--database", dbPath, "--out", mdPath})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	data, _ = os.ReadFile(mdPath)
+	md := string(data)
+	for _, want := range []string{
+		"# Crawl report",
+		"| 200 | 3 | 75.0% |",
+		"| https://example.com/ | 200 | 300 ms |",
+		"| &lt;Shop&gt; \\| Deals | 2 | https://example.com/a<br>https://example.com/b |",
+		"| https://example.com/missing | 404 | https://example.com/ – “Gone” |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected %q in Markdown report:\n%s", want, md)
+		}
+	}
+
+	rootCmd.SetArgs([]string{"report", "--database", dbPath, "--out", "", "--format", "pdf"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// requeueCmd puts failed pages back in the queue
+var requeueCmd = &cobra.Command{
+	Use:   "requeue",
+	Short: "Put failed pages back in the queue to fetch them again",
+	Long: `Set pages whose fetch failed back to pending, so a running crawl fetches
+them again, or the next run on the database does. Without filters every
+failed page is requeued; each filter narrows the selection:
+
+  --error-type   the type of the page's last error, e.g. network_error
+  --max-retries  pages that failed fewer times than this
+  --match        a regular expression the page URL must match
+  --url          only these URLs
+
+--dry-run lists the selected pages without changing the database.`,
+	Example: `  linktadoru requeue -d crawl.db
+  linktadoru requeue --error-type network_error --max-retries 3 --match '/blog/.*'
+  linktadoru requeue --error-type timeout --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runRequeue,
+}
+
+func init() {
+	requeueCmd.Flags().StringP("database", "d", "./linktadoru.db", "Path to SQLite database file of the crawl")
+	requeueCmd.Flags().StringSlice("error-type", []string{}, "Only requeue pages whose last error has one of these types")
+	requeueCmd.Flags().Int("max-retries", 0, "Only requeue pages that failed fewer times than this (0=no limit)")
+	requeueCmd.Flags().String("match", "", "Only requeue pages whose URL matches this regular expression")
+	requeueCmd.Flags().StringSlice("url", []string{}, "Only requeue these URLs")
+	requeueCmd.Flags().Bool("dry-run", false, "List the pages that would be requeued without requeueing them")
+	rootCmd.AddCommand(requeueCmd)
+}
+
+func runRequeue(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	var filter storage.RequeueFilter
+	filter.ErrorTypes, _ = cmd.Flags().GetStringSlice("error-type")
+	filter.MaxRetries, _ = cmd.Flags().GetInt("max-retries")
+	filter.URLs, _ = cmd.Flags().GetStringSlice("url")
+	match, _ := cmd.Flags().GetString("match")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if filter.MaxRetries < 0 {
+		return fmt.Errorf("max-retries must not be negative, got %d", filter.MaxRetries)
+	}
+	if match != "" {
+		if filter.Match, err = regexp.Compile(match); err != nil {
+			return fmt.Errorf("invalid --match pattern: %w", err)
+		}
+	}
+
+	store, err := openExistingStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	out := cmd.OutOrStdout()
+	if dryRun {
+		pages, err := store.GetErrorPages(filter)
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, 0, len(pages))
+		for _, page := range pages {
+			rows = append(rows, []string{page.URL, page.ErrorType, strconv.Itoa(page.Failures)})
+		}
+		if err := writeReport(out, formatTable, []string{"URL", "ERROR_TYPE", "FAILURES"}, rows, pages); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%d failed pages would be requeued\n", len(pages))
+		return nil
+	}
+
+	n, err := store.RequeueErrors(filter)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Requeued %d failed pages in %s\n", n, cfg.DatabasePath)
+	if n > 0 {
+		fmt.Fprintf(out, "A running crawl fetches them again; otherwise run 'linktadoru -d %s' to resume\n", cfg.DatabasePath)
+	}
+	return nil
+}
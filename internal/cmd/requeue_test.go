@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+func TestRequeueCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "requeue.db")
+	writeCrawl(t, dbPath, map[string]*crawler.PageData{"https://example.com/": {StatusCode: 200}})
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	errorTypes := map[string]string{
+		"https://example.com/blog/a": "network_error",
+		"https://example.com/blog/b": "timeout",
+		"https://example.com/shop/a": "network_error",
+	}
+	_ = store.AddToQueue([]string{"https://example.com/blog/a", "https://example.com/blog/b", "https://example.com/shop/a"})
+	for range errorTypes {
+		item, _ := store.GetNextFromQueue()
+		_ = store.SavePageError(item.ID, errorTypes[item.URL], "failed")
+	}
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = requeueCmd.Flags().Set("match", "")
+		_ = requeueCmd.Flags().Set("max-retries", "0")
+		_ = requeueCmd.Flags().Set("dry-run", "false")
+		flag := requeueCmd.Flags().Lookup("error-type")
+		_ = flag.Value.(interface{ Replace([]string) error }).Replace(nil)
+		requeueCmd.Flags().Lookup("database").Changed = false
+	}()
+
+	args := []string{"requeue", "-d", dbPath, "--error-type", "network_error", "--max-retries", "3", "--match", "/blog/.*"}
+	rootCmd.SetArgs(append(args, "--dry-run"))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("requeue --dry-run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "https://example.com/blog/a  network_error  1") ||
+		!strings.Contains(out.String(), "1 failed pages would be requeued") {
+		t.Errorf("Unexpected dry run:\n%s", out.String())
+	}
+
+	out.Reset()
+	_ = requeueCmd.Flags().Set("dry-run", "false")
+	rootCmd.SetArgs(args)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("requeue failed: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "Requeued 1 failed pages") {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+	store, err = storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	for url, want := range map[string]string{
+		"https://example.com/blog/a": "pending",
+		"https://example.com/blog/b": "error",
+		"https://example.com/shop/a": "error",
+	} {
+		if status, _ := store.GetURLStatus(url); status != want {
+			t.Errorf("Status of %s = %q, want %q", url, status, want)
+		}
+	}
+
+	rootCmd.SetArgs([]string{"requeue", "-d", dbPath, "--match", "("})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid --match pattern") {
+		t.Errorf("Expected an invalid pattern error, got %v", err)
+	}
+}
@@ -3,7 +3,10 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 
@@ -18,8 +21,13 @@ import (
 	"github.com/masahif/linktadoru/internal/crawler"
 	"github.com/masahif/linktadoru/internal/logging"
 	"github.com/masahif/linktadoru/internal/storage"
+	"github.com/masahif/linktadoru/internal/tracing"
 )
 
+// tracingShutdownTimeout bounds how long the spans not yet exported may take
+// to flush when the crawl ends
+const tracingShutdownTimeout = 5 * time.Second
+
 var (
 	cfgFile   string
 	version   string
@@ -58,22 +66,51 @@ func init() {
 
 	// Configuration management flags
 	rootCmd.Flags().Bool("show-config", false, "Display current configuration in YAML format and exit")
+	rootCmd.Flags().String("format", formatText, "Format of --show-config: text (annotated YAML), yaml or json")
 
 	// Basic crawling flags (updated defaults)
 	rootCmd.Flags().IntP("concurrency", "c", 2, "Number of concurrent workers")
+	rootCmd.Flags().Int("min-concurrency", 0, "Fewest workers kept by the autoscaler (0 = 1)")
+	rootCmd.Flags().Int("max-concurrency", 0, "Most workers started by the autoscaler (0 = fixed concurrency)")
 	rootCmd.Flags().Float64P("delay", "r", 0.1, "Delay between requests in seconds")
+	rootCmd.Flags().Float64("jitter", 0, "Vary the delay at random by up to this many percent (0-100)")
 	rootCmd.Flags().DurationP("timeout", "t", 30*time.Second, "HTTP request timeout")
 	rootCmd.Flags().StringP("user-agent", "u", "LinkTadoru/1.0", "HTTP User-Agent header")
+	rootCmd.Flags().String("crawler-info-url", "", "URL of a page describing this crawl, added to the User-Agent as '(+URL)'")
 	rootCmd.Flags().Bool("ignore-robots-txt", false, "Ignore robots.txt rules")
+	rootCmd.Flags().Bool("respect-x-robots-tag", false, "Do not queue links of pages served with X-Robots-Tag: nofollow")
+	rootCmd.Flags().Bool("follow-canonical", false, "Queue a page's canonical URL instead of its links when the two differ")
+	rootCmd.Flags().Int("pagination-depth", 0, "Follow at most N pagination links (rel=next/prev, ?page=N) in a row (0=unlimited)")
+	rootCmd.Flags().Bool("respect-nofollow", false, "Do not queue rel=\"nofollow\" links or links of meta robots nofollow pages")
 	rootCmd.Flags().Bool("follow-external-hosts", false, "Allow crawling external hosts")
+	rootCmd.Flags().Bool("include-subdomains", false, "Also crawl subdomains of seed hosts (e.g. www., blog.)")
+	rootCmd.Flags().String("check-external", "none", "Verify links outside the crawl scope: 'none' or 'head' (HEAD request, no content crawl)")
+	rootCmd.Flags().Int("external-checkers", 2, "Goroutines verifying out-of-scope links, apart from the page workers")
 	rootCmd.Flags().IntP("limit", "l", 0, "Stop after N pages (0=unlimited)")
+	rootCmd.Flags().Duration("timeout-total", 0, "Stop the whole crawl gracefully after this long, e.g. 2h (0=no limit)")
+	rootCmd.Flags().Duration("shutdown-timeout", 10*time.Second, "On Ctrl-C or SIGTERM, let pages in flight finish for up to this long (0=cancel them at once)")
+	rootCmd.Flags().Int("max-retries", 2, "Times a page failing transiently (timeout, connection reset, 5xx) is fetched again (0=never)")
+	rootCmd.Flags().Duration("retry-backoff", time.Second, "Wait before the first retry round, doubled for each further round")
+	rootCmd.Flags().Duration("stale-processing-timeout", 0, "At startup, requeue pages left 'processing' longer than this by a crashed run (0=all of them)")
+	rootCmd.Flags().Int("max-queue-size", 0, "Maximum pending URLs; further discoveries are dropped (0=unlimited)")
+	rootCmd.Flags().Int64("max-response-size", 0, "Maximum response body size in bytes (0=unlimited)")
+	rootCmd.Flags().Int("seen-url-cache-size", 1000000, "Queued URLs remembered in memory to skip database lookups (0=disabled)")
+	rootCmd.Flags().Int("queue-batch-size", 1, "Queue items each worker claims per database call")
+	rootCmd.Flags().String("queue-order", "host", "Dequeue order: host (rotate across hosts) or fifo")
+	rootCmd.Flags().Int("write-buffer-size", 0, "Processed pages buffered for background writers (0 = write synchronously)")
+	rootCmd.Flags().Int("write-workers", 1, "Goroutines persisting buffered results")
+	rootCmd.Flags().Int("max-url-length", 0, "Skip URLs longer than this many bytes as spider traps (0=unlimited)")
+	rootCmd.Flags().Int("max-path-segments", 0, "Skip URLs with more path segments as spider traps (0=unlimited)")
+	rootCmd.Flags().Int("max-repeated-segments", 0, "Skip URLs repeating one path segment more often as spider traps (0=unlimited)")
+	rootCmd.Flags().Int("max-query-params", 0, "Skip URLs with more query parameters as spider traps (0=unlimited)")
+	rootCmd.Flags().Int("max-query-variants", 0, "Skip URLs once a path was crawled with this many query strings (0=unlimited)")
 
 	// Authentication type flag
-	rootCmd.Flags().String("auth-type", "", "Authentication type: 'basic', 'bearer', or 'api-key'")
+	rootCmd.Flags().String("auth-type", "", "Authentication type: 'basic', 'bearer', 'api-key', 'ntlm', or 'negotiate'")
 
 	// Basic authentication flags
-	rootCmd.Flags().String("auth-username", "", "Username for basic authentication")
-	rootCmd.Flags().String("auth-password", "", "Password for basic authentication")
+	rootCmd.Flags().String("auth-username", "", "Username for basic/ntlm/negotiate authentication")
+	rootCmd.Flags().String("auth-password", "", "Password for basic/ntlm/negotiate authentication")
 
 	// Bearer authentication flags
 	rootCmd.Flags().String("auth-token", "", "Bearer token for authorization header")
@@ -81,16 +118,75 @@ func init() {
 	// API Key authentication flags
 	rootCmd.Flags().String("auth-header", "", "API key header name (e.g., X-API-Key)")
 	rootCmd.Flags().String("auth-value", "", "API key header value")
+	rootCmd.Flags().StringSlice("auth-hosts", []string{}, "Hosts credentials are sent to, e.g. 'example.com,*.example.com' (default: seed hosts)")
 
 	// HTTP Headers flags
 	rootCmd.Flags().StringSliceP("header", "H", []string{}, "Custom HTTP headers in 'Name: Value' format (use multiple times for multiple headers)")
+	rootCmd.Flags().String("run-header", "", "Stamp every request with a per-run UUID in this header, e.g. 'X-LinkTadoru-Run'")
+
+	// TLS flags
+	rootCmd.Flags().String("tls-client-cert", "", "PEM client certificate file for mutual TLS")
+	rootCmd.Flags().String("tls-client-key", "", "PEM private key file for the client certificate")
+	rootCmd.Flags().String("tls-ca-file", "", "PEM CA bundle to trust in addition to system roots")
+	rootCmd.Flags().Bool("tls-insecure-skip-verify", false, "Disable TLS certificate verification (staging only)")
+
+	// Recording flags
+	rootCmd.Flags().String("record-dir", "", "Save every response in this directory for offline replay")
+	rootCmd.Flags().String("replay-dir", "", "Crawl the responses recorded in this directory instead of the network")
+
+	// DNS flags
+	rootCmd.Flags().Duration("dns-cache-ttl", 0, "Cache DNS lookups for this long (0=disabled)")
+	rootCmd.Flags().String("dns-resolver", "", "DNS server 'host:port' to use instead of the system resolver")
+	rootCmd.Flags().StringToString("dns-override", map[string]string{}, "Resolve a host to a fixed IP, as 'host=ip' (use multiple times)")
 
 	// URL filtering flags
+	rootCmd.Flags().StringSlice("allowed-hosts", []string{}, "Hosts to crawl, e.g. 'example.com,*.example.com' (replaces seed-host scoping)")
+	rootCmd.Flags().StringSlice("blocked-hosts", []string{}, "Hosts never to crawl, e.g. '*.ads.example.com'")
+	rootCmd.Flags().StringSlice("allowed-content-types", []string{}, "Media types to download, e.g. 'text/html,application/xhtml+xml'")
+	rootCmd.Flags().StringSlice("blocked-content-types", []string{}, "Media types never downloaded, e.g. 'image/*,video/*,application/zip'")
+	rootCmd.Flags().Bool("hash-assets", false, "Store a SHA-256 hash of non-HTML responses (images, PDFs) for duplicate and change detection")
+	rootCmd.Flags().Bool("crawl-assets", false, "Also fetch stylesheets, scripts, images and icons referenced by pages and record their status")
+	rootCmd.Flags().Int64("page-weight-budget", 0, "With --crawl-assets, flag pages whose HTML and assets exceed this many bytes (0=no budget)")
+	rootCmd.Flags().Int64("script-weight-budget", 0, "Flag pages whose scripts exceed this many bytes (0=no budget)")
+	rootCmd.Flags().Int64("stylesheet-weight-budget", 0, "Flag pages whose stylesheets exceed this many bytes (0=no budget)")
+	rootCmd.Flags().Int64("image-weight-budget", 0, "Flag pages whose images and icons exceed this many bytes (0=no budget)")
+	rootCmd.Flags().Bool("fail-on-budget", false, "Exit with a non-zero status when a page exceeds a page weight budget")
+	rootCmd.Flags().Bool("accessibility-checks", false, "Record basic accessibility issues of HTML pages (alt text, labels, empty links and buttons, lang, heading order)")
 	rootCmd.Flags().StringSlice("include-patterns", []string{}, "Regex patterns for URLs to include")
 	rootCmd.Flags().StringSlice("exclude-patterns", []string{}, "Regex patterns for URLs to exclude")
+	rootCmd.Flags().StringSlice("strip-query-params", []string{}, "Query parameters removed from URLs before queueing (glob patterns, e.g. utm_*)")
+	rootCmd.Flags().StringSlice("keep-query-params", []string{}, "Only keep these query parameters (glob patterns); all others are removed")
+	rootCmd.Flags().String("trailing-slash", "keep", "Final slash of URL paths: 'keep', 'add' or 'remove'")
+	rootCmd.Flags().StringSlice("directory-index", []string{}, "File names dropped from the end of URL paths (e.g. index.html)")
+
+	// Scripting flags
+	rootCmd.Flags().String("script", "", "Lua script defining should_crawl(url), rewrite_url(url) and/or extract(url, html)")
+	rootCmd.Flags().Duration("script-timeout", time.Second, "How long one call of a script function may run")
+
+	// JavaScript rendering flags
+	rootCmd.Flags().StringSlice("render-patterns", []string{}, "Regex patterns of URLs rendered in a headless browser before parsing")
+	rootCmd.Flags().Bool("render-fallback", false, "Render pages without links that look like single-page applications")
+	rootCmd.Flags().Duration("render-timeout", 30*time.Second, "How long the browser waits for a page to reach network idle")
+	rootCmd.Flags().String("browser-path", "", "Chrome or Chromium executable (default: looked up in PATH)")
+	rootCmd.Flags().String("browser-url", "", "DevTools WebSocket URL of a running browser to use instead of launching one")
 
 	// Database flags
+	rootCmd.Flags().String("storage-driver", "sqlite", "Storage backend to crawl into (a driver compiled into this binary)")
 	rootCmd.Flags().StringP("database", "d", "./linktadoru.db", "Path to SQLite database file")
+	rootCmd.Flags().String("results-database", "", "Store crawl results in this separate SQLite file, keeping the queue database small")
+	rootCmd.Flags().Bool("encrypt-database", false, "Encrypt sensitive database columns (passphrase from LT_DATABASE_PASSPHRASE)")
+	rootCmd.Flags().String("redis-url", "redis://localhost:6379/0", "Redis server of the shared queue and results (storage driver redis)")
+	rootCmd.Flags().String("redis-key-prefix", "linktadoru", "Prefix of the crawl's Redis keys, so several crawls can share a server")
+	rootCmd.Flags().Duration("host-lease", 30*time.Second, "How long a worker keeps a host to itself after claiming one of its URLs (storage driver redis; 0=no leases)")
+
+	// Control API flags
+	rootCmd.Flags().String("serve", "", "Serve the control API on this address, e.g. 127.0.0.1:8080, and keep running between crawls")
+	rootCmd.Flags().String("serve-grpc", "", "Serve the gRPC control API on this address, e.g. 127.0.0.1:9090, and keep running between crawls")
+	rootCmd.Flags().String("serve-token", "", "Bearer token the control APIs require (prefer LT_SERVE_TOKEN)")
+
+	// Tracing and profiling flags
+	rootCmd.Flags().String("otlp-endpoint", "", "Export OpenTelemetry traces to this OTLP/HTTP collector, e.g. http://localhost:4318")
+	rootCmd.Flags().String("debug-addr", "", "Serve pprof profiles and runtime stats on this address, e.g. 127.0.0.1:6060")
 
 	// Bind basic flags to viper
 	bindFlags := []struct {
@@ -98,22 +194,94 @@ func init() {
 		flagName string
 	}{
 		{"concurrency", "concurrency"},
+		{"min_concurrency", "min-concurrency"},
+		{"max_concurrency", "max-concurrency"},
 		{"request_delay", "delay"},
+		{"request_jitter", "jitter"},
 		{"request_timeout", "timeout"},
 		{"user_agent", "user-agent"},
+		{"crawler_info_url", "crawler-info-url"},
 		{"ignore_robots_txt", "ignore-robots-txt"},
+		{"respect_x_robots_tag", "respect-x-robots-tag"},
+		{"respect_nofollow", "respect-nofollow"},
+		{"follow_canonical", "follow-canonical"},
+		{"pagination_depth", "pagination-depth"},
 		{"follow_external_hosts", "follow-external-hosts"},
+		{"include_subdomains", "include-subdomains"},
+		{"check_external", "check-external"},
+		{"external_checkers", "external-checkers"},
 		{"limit", "limit"},
+		{"max_queue_size", "max-queue-size"},
+		{"timeout_total", "timeout-total"},
+		{"shutdown_timeout", "shutdown-timeout"},
+		{"max_retries", "max-retries"},
+		{"retry_backoff", "retry-backoff"},
+		{"stale_processing_timeout", "stale-processing-timeout"},
+		{"max_response_size", "max-response-size"},
+		{"seen_url_cache_size", "seen-url-cache-size"},
+		{"queue_batch_size", "queue-batch-size"},
+		{"queue_order", "queue-order"},
+		{"write_buffer_size", "write-buffer-size"},
+		{"write_workers", "write-workers"},
+		{"max_url_length", "max-url-length"},
+		{"max_path_segments", "max-path-segments"},
+		{"max_repeated_segments", "max-repeated-segments"},
+		{"max_query_params", "max-query-params"},
+		{"max_query_variants", "max-query-variants"},
+		{"allowed_hosts", "allowed-hosts"},
+		{"blocked_hosts", "blocked-hosts"},
+		{"allowed_content_types", "allowed-content-types"},
+		{"blocked_content_types", "blocked-content-types"},
+		{"hash_assets", "hash-assets"},
+		{"crawl_assets", "crawl-assets"},
+		{"page_weight_budget", "page-weight-budget"},
+		{"script_weight_budget", "script-weight-budget"},
+		{"stylesheet_weight_budget", "stylesheet-weight-budget"},
+		{"image_weight_budget", "image-weight-budget"},
+		{"fail_on_budget", "fail-on-budget"},
+		{"accessibility_checks", "accessibility-checks"},
 		{"include_patterns", "include-patterns"},
 		{"exclude_patterns", "exclude-patterns"},
+		{"strip_query_params", "strip-query-params"},
+		{"keep_query_params", "keep-query-params"},
+		{"trailing_slash", "trailing-slash"},
+		{"directory_index", "directory-index"},
+		{"script", "script"},
+		{"script_timeout", "script-timeout"},
+		{"render_patterns", "render-patterns"},
+		{"render_fallback", "render-fallback"},
+		{"render_timeout", "render-timeout"},
+		{"browser_path", "browser-path"},
+		{"browser_url", "browser-url"},
+		{"run_header", "run-header"},
+		{"storage_driver", "storage-driver"},
 		{"database_path", "database"},
-		{"headers", "header"},
+		{"results_database_path", "results-database"},
+		{"database_encryption", "encrypt-database"},
+		{"redis_url", "redis-url"},
+		{"redis_key_prefix", "redis-key-prefix"},
+		{"host_lease", "host-lease"},
+		{"serve", "serve"},
+		{"serve_grpc", "serve-grpc"},
+		{"serve_token", "serve-token"},
+		{"otlp_endpoint", "otlp-endpoint"},
+		{"debug_addr", "debug-addr"},
+		{"tls_client_cert", "tls-client-cert"},
+		{"tls_client_key", "tls-client-key"},
+		{"tls_ca_file", "tls-ca-file"},
+		{"tls_insecure_skip_verify", "tls-insecure-skip-verify"},
+		{"record_dir", "record-dir"},
+		{"replay_dir", "replay-dir"},
+		{"dns_cache_ttl", "dns-cache-ttl"},
+		{"dns_resolver", "dns-resolver"},
+		{"dns_overrides", "dns-override"},
 		{"auth.type", "auth-type"},
 		{"auth.basic.username", "auth-username"},
 		{"auth.basic.password", "auth-password"},
 		{"auth.bearer.token", "auth-token"},
 		{"auth.apikey.header", "auth-header"},
 		{"auth.apikey.value", "auth-value"},
+		{"auth.hosts", "auth-hosts"},
 	}
 
 	for _, bind := range bindFlags {
@@ -146,6 +314,14 @@ func initConfig() {
 	}
 }
 
+// toolVersion returns the version recorded in databases and manifests
+func toolVersion() string {
+	if version == "" {
+		return "dev"
+	}
+	return version
+}
+
 func generateUserAgent() string {
 	if version != "" && version != "dev" {
 		return fmt.Sprintf("LinkTadoru/%s", version)
@@ -153,7 +329,7 @@ func generateUserAgent() string {
 	return "LinkTadoru/dev"
 }
 
-func showCurrentConfig(cfg *config.CrawlConfig) error {
+func showCurrentConfig(w io.Writer, format string, cfg *config.CrawlConfig) error {
 	if cfg == nil {
 		return fmt.Errorf("configuration is nil")
 	}
@@ -164,25 +340,30 @@ func showCurrentConfig(cfg *config.CrawlConfig) error {
 		fmt.Fprintf(os.Stderr, "Displaying configuration anyway...\n\n")
 	}
 
+	// Machine-readable output carries the configuration only
+	if format != formatText && format != "" {
+		return writeStructured(w, format, cfg)
+	}
+
 	yamlData, err := yaml.Marshal(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal configuration to YAML: %w", err)
 	}
 
 	// Add header comment to the output
-	fmt.Printf("# Current LinkTadoru Configuration\n")
-	fmt.Printf("# Generated at: %s\n", time.Now().Format(time.RFC3339))
-	fmt.Printf("# Configuration file search paths: ./linktadoru.yml\n")
-	fmt.Printf("# Environment variables prefix: LT_\n\n")
+	fmt.Fprintf(w, "# Current LinkTadoru Configuration\n")
+	fmt.Fprintf(w, "# Generated at: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(w, "# Configuration file search paths: ./linktadoru.yml\n")
+	fmt.Fprintf(w, "# Environment variables prefix: LT_\n\n")
 
-	fmt.Print(string(yamlData))
+	fmt.Fprint(w, string(yamlData))
 
 	// Add footer with additional information
-	fmt.Printf("\n# Configuration source priority:\n")
-	fmt.Printf("# 1. Command-line arguments (highest priority)\n")
-	fmt.Printf("# 2. Environment variables (LT_ prefix)\n")
-	fmt.Printf("# 3. Configuration file (linktadoru.yml)\n")
-	fmt.Printf("# 4. Default values (lowest priority)\n")
+	fmt.Fprintf(w, "\n# Configuration source priority:\n")
+	fmt.Fprintf(w, "# 1. Command-line arguments (highest priority)\n")
+	fmt.Fprintf(w, "# 2. Environment variables (LT_ prefix)\n")
+	fmt.Fprintf(w, "# 3. Configuration file (linktadoru.yml)\n")
+	fmt.Fprintf(w, "# 4. Default values (lowest priority)\n")
 
 	return nil
 }
@@ -194,25 +375,35 @@ func runCrawler(cmd *cobra.Command, args []string) error {
 
 	cfg := config.DefaultConfig()
 
-	// Set seed URLs from command line arguments
-	cfg.SeedURLs = args
-
 	// Override with viper values
 	if err := viper.Unmarshal(cfg); err != nil {
 		return fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	// Load headers from environment variables (Issue #8 specification)
-	cfg.LoadHeadersFromEnv()
+	// Seed URLs given on the command line replace the config file's seed_urls
+	if len(args) > 0 {
+		cfg.SeedURLs = args
+	}
+
+	// Merge custom headers by precedence: config file < LT_HEADER_* < -H flags
+	headerConflicts, err := loadHeaders(cmd, cfg)
+	if err != nil {
+		return err
+	}
 
 	// Update User-Agent with dynamic version if not explicitly set
 	if !cmd.Flags().Changed("user-agent") && cfg.UserAgent == "LinkTadoru/1.0" {
 		cfg.UserAgent = generateUserAgent()
 	}
+	cfg.UserAgent = cfg.UserAgentWithInfo()
 
 	// Handle --show-config: display current configuration and exit
 	if showConfig {
-		return showCurrentConfig(cfg)
+		format, _ := cmd.Flags().GetString("format")
+		if err := checkStructuredFormat(format); err != nil {
+			return err
+		}
+		return showCurrentConfig(cmd.OutOrStdout(), format, cfg)
 	}
 
 	// Initialize logging
@@ -226,23 +417,51 @@ func runCrawler(cmd *cobra.Command, args []string) error {
 	if err := logging.SetDefault(logConfig); err != nil {
 		return fmt.Errorf("failed to initialize logging: %w", err)
 	}
+	warnHeaderConflicts(headerConflicts)
 
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.OTLPEndpoint, toolVersion())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Warn("Failed to flush traces", "error", err)
+		}
+	}()
+
+	if cfg.DebugAddr != "" {
+		stopDebug, err := startDebugServer(cfg.DebugAddr, os.Stdout)
+		if err != nil {
+			return err
+		}
+		defer stopDebug()
+	}
+
+	// A control API server starts idle when there is nothing to crawl yet
+	if cfg.Serve != "" || cfg.ServeGRPC != "" {
+		return serveCrawls(cmd, cfg, os.Stdout)
+	}
+
 	// Validate startup conditions: prevent running without URLs and without existing database
 	if len(cfg.SeedURLs) == 0 {
 		// No seed URLs provided, check if database exists for resume
-		if _, err := os.Stat(cfg.DatabasePath); os.IsNotExist(err) {
-			return fmt.Errorf("no URLs provided and no existing database found at %s\nUsage: %s [URLs...] or ensure database exists for resume operation",
-				cfg.DatabasePath, os.Args[0])
+		if storage.DriverName(cfg) == storage.DriverSQLite {
+			if _, err := os.Stat(cfg.DatabasePath); os.IsNotExist(err) {
+				return fmt.Errorf("no URLs provided and no existing database found at %s\nUsage: %s [URLs...] or ensure database exists for resume operation",
+					cfg.DatabasePath, os.Args[0])
+			}
 		}
 
 		// Database exists, but let's check if it has any queued items
 		// Create a temporary storage instance to check queue status
-		tempStorage, err := storage.NewSQLiteStorage(cfg.DatabasePath)
+		tempStorage, err := storage.Open(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to open database %s: %w", cfg.DatabasePath, err)
 		}
@@ -268,12 +487,6 @@ func runCrawler(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Resuming crawl from existing database: %s\n", cfg.DatabasePath)
 	}
 
-	// Create database directory if it doesn't exist
-	dbDir := filepath.Dir(cfg.DatabasePath)
-	if err := os.MkdirAll(dbDir, 0750); err != nil {
-		return fmt.Errorf("failed to create database directory: %w", err)
-	}
-
 	fmt.Printf("Starting crawler with configuration:\n")
 	if len(cfg.SeedURLs) > 0 {
 		fmt.Printf("  Seed URLs: %v\n", cfg.SeedURLs)
@@ -284,32 +497,186 @@ func runCrawler(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Concurrency: %d\n", cfg.Concurrency)
 	fmt.Printf("  Request Delay: %v\n", cfg.RequestDelay)
 	fmt.Printf("  Database: %s\n", cfg.DatabasePath)
+	if cfg.ResultsDatabasePath != "" {
+		fmt.Printf("  Results Database: %s\n", cfg.ResultsDatabasePath)
+	}
+	if cfg.DatabaseEncryption {
+		fmt.Printf("  Database Encryption: enabled\n")
+	}
 	fmt.Printf("  Ignore Robots.txt: %t\n", cfg.IgnoreRobotsTxt)
 
 	// Display auth status without exposing credentials
 	if username, password := cfg.GetBasicAuthCredentials(); username != "" && password != "" {
-		fmt.Printf("  Authentication: Basic (username: %s)\n", username)
+		authLabel := "Basic"
+		switch cfg.Auth.Type {
+		case config.NTLMAuthType:
+			authLabel = "NTLM"
+		case config.NegotiateAuthType:
+			authLabel = "Negotiate"
+		}
+		fmt.Printf("  Authentication: %s (username: %s)\n", authLabel, username)
 	} else {
 		fmt.Printf("  Authentication: None\n")
 	}
 
-	// Initialize and start the crawler
-	crawler, err := initializeCrawler(cfg)
+	if _, err := executeCrawl(cmd, cfg, os.Stdout); err != nil {
+		return err
+	}
+	return checkWeightBudgets(cmd, cfg, os.Stdout)
+}
+
+// loadHeaders merges LT_HEADER_* environment variables and, when given, -H
+// flags over the headers from the configuration file. -H is merged rather
+// than bound through viper so that it overrides individual headers instead of
+// replacing the whole list.
+func loadHeaders(cmd *cobra.Command, cfg *config.CrawlConfig) ([]config.HeaderConflict, error) {
+	conflicts, err := cfg.LoadHeadersFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("invalid header environment variable: %w", err)
+	}
+	if flag := cmd.Flags().Lookup("header"); flag != nil && flag.Changed {
+		flagHeaders, _ := cmd.Flags().GetStringSlice("header")
+		conflicts = append(conflicts, cfg.MergeHeaders(config.HeaderSourceFlag, flagHeaders)...)
+	}
+	return conflicts, nil
+}
+
+// warnHeaderConflicts logs headers whose value was overridden by a higher-precedence source
+func warnHeaderConflicts(conflicts []config.HeaderConflict) {
+	for _, conflict := range conflicts {
+		slog.Warn("Custom header overridden", "header", conflict.Name, "source", conflict.Source, "overridden", conflict.Overridden)
+	}
+}
+
+// executeCrawl runs a crawl with cfg, prints a short summary to out and writes
+// the run manifest next to the database
+func executeCrawl(cmd *cobra.Command, cfg *config.CrawlConfig, out io.Writer) (crawler.CrawlStats, error) {
+	if err := createDatabaseDirs(cfg); err != nil {
+		return crawler.CrawlStats{}, err
+	}
+
+	// Initialize and start the crawler. The storage is closed last, once
+	// every result has been written.
+	c, store, err := initializeCrawler(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to initialize crawler: %w", err)
+		return crawler.CrawlStats{}, fmt.Errorf("failed to initialize crawler: %w", err)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			slog.Error("Failed to close database", "error", err)
+		}
+	}()
+	defer func() { _ = c.Stop() }()
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// Ctrl-C and SIGTERM stop the crawl gracefully: no new URLs are claimed,
+	// pages in flight are finished or requeued and buffered results are saved
+	ctx, stop := notifyShutdown(ctx, out, cfg.ShutdownTimeout)
+	defer stop()
+	return runCrawl(ctx, cfg, c, out)
+}
+
+// createDatabaseDirs creates the directories of the database files if they don't exist
+func createDatabaseDirs(cfg *config.CrawlConfig) error {
+	if err := os.MkdirAll(filepath.Dir(cfg.DatabasePath), 0750); err != nil {
+		return fmt.Errorf("failed to create database directory: %w", err)
+	}
+	if cfg.ResultsDatabasePath != "" {
+		if err := os.MkdirAll(filepath.Dir(cfg.ResultsDatabasePath), 0750); err != nil {
+			return fmt.Errorf("failed to create results database directory: %w", err)
+		}
+	}
+	return nil
+}
+
+// runCrawl runs the crawl c until it ends or ctx is cancelled, prints a short
+// summary to out, writes the run manifest next to the database and records
+// the session in it
+func runCrawl(ctx context.Context, cfg *config.CrawlConfig, c crawler.Crawler, out io.Writer) (crawler.CrawlStats, error) {
+	startedAt := time.Now()
+	sessionID := newSessionID(startedAt)
+
+	// Bound the whole run if requested; the crawler shuts down gracefully at the deadline
+	if cfg.TimeoutTotal > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.TimeoutTotal)
+		defer cancel()
 	}
-	defer func() { _ = crawler.Stop() }()
+
+	// Pause while the pause file of `linktadoru pause` exists
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+	go watchPauseFile(watchCtx, pauseFilePath(cfg.DatabasePath), c)
 
 	// Start crawling
-	return crawler.Start(cmd.Context(), cfg.SeedURLs)
+	crawlErr := c.Start(ctx, cfg.SeedURLs)
+	stopWatch()
+
+	stats := c.GetStats()
+	switch stats.StopReason {
+	case crawler.StopReasonTimeout:
+		fmt.Fprintf(out, "Stopped after reaching the total timeout of %v\n", cfg.TimeoutTotal)
+	case crawler.StopReasonLimit:
+		fmt.Fprintf(out, "Stopped after reaching the page limit of %d\n", cfg.Limit)
+	case crawler.StopReasonInterrupted:
+		fmt.Fprintf(out, "Stopped by interruption\n")
+	}
+	fmt.Fprintf(out, "Crawl finished: %d pages crawled, %d errors in %v\n",
+		stats.PagesCrawled, stats.ErrorCount, stats.Duration.Round(time.Second))
+	if stats.RemainingQueued > 0 {
+		fmt.Fprintf(out, "%d URLs left in the queue; run again without URLs to resume\n", stats.RemainingQueued)
+	}
+
+	// Summarize the run for downstream automation; a manifest failure does not fail the crawl
+	finishedAt := time.Now()
+	manifest, err := buildManifest(cfg, sessionID, stats, startedAt, finishedAt)
+	if err == nil {
+		var path string
+		if path, err = writeManifest(manifest, cfg.DatabasePath); err == nil {
+			slog.Info("Wrote crawl manifest", "path", path, "session_id", sessionID)
+		}
+	}
+	if err != nil {
+		slog.Warn("Failed to write crawl manifest", "error", err)
+	}
+	recordCrawlSession(cfg, sessionID, stats, startedAt, finishedAt)
+
+	return stats, crawlErr
 }
 
-// initializeCrawler creates and configures a crawler instance
-func initializeCrawler(cfg *config.CrawlConfig) (crawler.Crawler, error) {
+// initializeCrawler creates and configures a crawler instance and the
+// storage it writes to, which the caller closes
+func initializeCrawler(cfg *config.CrawlConfig) (crawler.Crawler, crawler.Storage, error) {
 	// Initialize storage
-	store, err := storage.NewSQLiteStorage(cfg.DatabasePath)
+	store, err := storage.Open(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+		return nil, nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	c, err := newCrawler(cfg, store)
+	if err != nil {
+		_ = store.Close()
+		return nil, nil, err
+	}
+	return c, store, nil
+}
+
+// newCrawler creates a crawler writing to an open store
+func newCrawler(cfg *config.CrawlConfig, store crawler.Storage) (crawler.Crawler, error) {
+	// Record this binary's version for the write session and warn when the
+	// database was last written by a different release
+	if recorder, ok := store.(interface {
+		RecordToolVersion(version string) (string, error)
+	}); ok {
+		previous, err := recorder.RecordToolVersion(toolVersion())
+		if err != nil {
+			slog.Warn("Failed to record tool version in database", "error", err)
+		} else if previous != "" && previous != toolVersion() {
+			slog.Warn("Database was last written by a different linktadoru version",
+				"database_version", previous, "current_version", toolVersion())
+		}
 	}
 
 	// Pass the complete config directly to the crawler
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -105,10 +107,11 @@ func TestInitializeCrawler(t *testing.T) {
 		Limit:           10,
 	}
 
-	crawler, err := initializeCrawler(cfg)
+	crawler, store, err := initializeCrawler(cfg)
 	if err != nil {
 		t.Fatalf("Failed to initialize crawler: %v", err)
 	}
+	defer func() { _ = store.Close() }()
 
 	if crawler == nil {
 		t.Error("Crawler should not be nil")
@@ -314,3 +317,78 @@ func TestRunCrawlerStartupValidation(t *testing.T) {
 		// (The actual runCrawler call is omitted to prevent test timeouts)
 	})
 }
+
+func TestLoadHeadersPrecedence(t *testing.T) {
+	t.Setenv("LT_HEADER_1", "X-API-Key: from-env")
+	t.Setenv("LT_HEADER_ACCEPT", "application/json")
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSliceP("header", "H", []string{}, "")
+	if err := cmd.Flags().Set("header", "X-API-Key: from-flag"); err != nil {
+		t.Fatalf("Failed to set flag: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Headers = []string{"Accept: text/html", "X-Team: web"}
+
+	conflicts, err := loadHeaders(cmd, cfg)
+	if err != nil {
+		t.Fatalf("loadHeaders failed: %v", err)
+	}
+
+	// Flags override the environment, which overrides the config file; other
+	// config file headers are kept
+	headers := map[string]bool{}
+	for _, header := range cfg.Headers {
+		headers[header] = true
+	}
+	for _, want := range []string{"Accept: application/json", "X-Team: web", "X-API-Key: from-flag"} {
+		if !headers[want] {
+			t.Errorf("Expected header %q in %v", want, cfg.Headers)
+		}
+	}
+	if len(cfg.Headers) != 3 {
+		t.Errorf("Expected 3 headers, got %v", cfg.Headers)
+	}
+	if len(conflicts) != 2 {
+		t.Errorf("Expected 2 conflicts, got %+v", conflicts)
+	}
+}
+
+func TestShowConfigOutput(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RequestTimeout = 45 * time.Second
+
+	var out bytes.Buffer
+	if err := showCurrentConfig(&out, formatJSON, cfg); err != nil {
+		t.Fatalf("JSON output failed: %v", err)
+	}
+	var parsed map[string]any
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("Invalid JSON output: %v\n%s", err, out.String())
+	}
+	if parsed["database_path"] != cfg.DatabasePath || parsed["request_timeout"] != "45s" {
+		t.Errorf("Unexpected JSON configuration: database_path=%v request_timeout=%v",
+			parsed["database_path"], parsed["request_timeout"])
+	}
+
+	out.Reset()
+	if err := showCurrentConfig(&out, formatYAML, cfg); err != nil {
+		t.Fatalf("YAML output failed: %v", err)
+	}
+	if strings.Contains(out.String(), "#") || !strings.Contains(out.String(), "request_timeout: 45s") {
+		t.Errorf("Expected plain YAML without comments, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := showCurrentConfig(&out, formatText, cfg); err != nil {
+		t.Fatalf("Text output failed: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "# Current LinkTadoru Configuration") {
+		t.Errorf("Expected annotated text output, got:\n%s", out.String())
+	}
+
+	if err := checkStructuredFormat("xml"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
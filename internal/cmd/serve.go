@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/server"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// serveShutdownTimeout bounds how long open API requests may take to finish
+// once the server is shutting down
+const serveShutdownTimeout = 5 * time.Second
+
+// serveCrawls serves the control APIs on cfg.Serve and cfg.ServeGRPC until
+// interrupted. The seed URLs, or a queue left by an earlier run, are crawled
+// right away; otherwise the server waits for a start request.
+func serveCrawls(cmd *cobra.Command, cfg *config.CrawlConfig, out io.Writer) error {
+	if storage.DriverName(cfg) != storage.DriverSQLite {
+		return fmt.Errorf("the control API requires the %s storage driver", storage.DriverSQLite)
+	}
+	if err := createDatabaseDirs(cfg); err != nil {
+		return err
+	}
+	store, err := openStorage(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open database %s: %w", cfg.DatabasePath, err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := notifyShutdown(ctx, out, cfg.ShutdownTimeout)
+	defer stop()
+
+	control := newCrawlController(ctx, cfg, store, out)
+	api := server.New(control, store)
+	api.Token = cfg.ServeToken
+
+	var srv *http.Server
+	if cfg.Serve != "" {
+		listener, err := net.Listen("tcp", cfg.Serve)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", cfg.Serve, err)
+		}
+		srv = &http.Server{
+			Handler:           api.Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+			// Event streams end when the server shuts down
+			BaseContext: func(net.Listener) context.Context { return ctx },
+		}
+		go func() {
+			if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("Control API server failed", "error", err)
+			}
+		}()
+		fmt.Fprintf(out, "Serving the control API on http://%s\n", listener.Addr())
+		warnUnauthenticated(cfg, cfg.Serve)
+	}
+
+	var grpcSrv *grpc.Server
+	if cfg.ServeGRPC != "" {
+		listener, err := net.Listen("tcp", cfg.ServeGRPC)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", cfg.ServeGRPC, err)
+		}
+		grpcSrv = grpc.NewServer(api.GRPCServerOptions()...)
+		api.RegisterGRPC(grpcSrv)
+		go func() {
+			if err := grpcSrv.Serve(listener); err != nil {
+				slog.Error("gRPC control API server failed", "error", err)
+			}
+		}()
+		fmt.Fprintf(out, "Serving the gRPC control API on %s\n", listener.Addr())
+		warnUnauthenticated(cfg, cfg.ServeGRPC)
+	}
+
+	hasWork, err := store.HasQueuedItems()
+	if err != nil {
+		return fmt.Errorf("failed to check queue status: %w", err)
+	}
+	if len(cfg.SeedURLs) > 0 || hasWork {
+		if err := control.Start(cfg.SeedURLs); err != nil {
+			return err
+		}
+	}
+
+	<-ctx.Done()
+	fmt.Fprintf(out, "Shutting down the control API\n")
+	control.wait()
+
+	if grpcSrv != nil {
+		// Status streams never end on their own, so they are cut off once
+		// the other calls have had their time
+		timer := time.AfterFunc(serveShutdownTimeout, grpcSrv.Stop)
+		grpcSrv.GracefulStop()
+		timer.Stop()
+	}
+	if srv == nil {
+		return nil
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+// warnUnauthenticated warns when a control API without a token listens on
+// an address other hosts can reach
+func warnUnauthenticated(cfg *config.CrawlConfig, addr string) {
+	if cfg.ServeToken == "" && !isLoopbackAddress(addr) {
+		slog.Warn("The control API has no authentication and is reachable from other hosts; anyone who can connect can start, stop and read crawls. Set LT_SERVE_TOKEN or bind it to 127.0.0.1",
+			"address", addr)
+	}
+}
+
+// isLoopbackAddress reports whether a listen address only accepts
+// connections from this host. An empty host listens on every interface.
+func isLoopbackAddress(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// crawlController runs the crawls of the control API one at a time, all on
+// the same open database
+type crawlController struct {
+	ctx   context.Context // Cancelled when the server stops, which stops the running crawl
+	cfg   *config.CrawlConfig
+	store *storage.SQLiteStorage
+	out   io.Writer
+
+	mu     sync.Mutex
+	crawl  crawler.Crawler    // The running crawl; nil when idle
+	cancel context.CancelFunc // Stops the running crawl
+	last   crawler.CrawlStats // Of the last finished crawl
+	wg     sync.WaitGroup
+}
+
+func newCrawlController(ctx context.Context, cfg *config.CrawlConfig, store *storage.SQLiteStorage, out io.Writer) *crawlController {
+	return &crawlController{ctx: ctx, cfg: cfg, store: store, out: out}
+}
+
+// Start starts a crawl from urls in the background, resuming the queue when
+// urls is empty
+func (c *crawlController) Start(urls []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.crawl != nil {
+		return server.ErrCrawlRunning
+	}
+
+	cfg := *c.cfg
+	cfg.SeedURLs = urls
+	crawl, err := newCrawler(&cfg, c.store)
+	if err != nil {
+		return fmt.Errorf("failed to initialize crawler: %w", err)
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.crawl, c.cancel = crawl, cancel
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer cancel()
+		stats, err := runCrawl(ctx, &cfg, crawl, c.out)
+		if err != nil {
+			slog.Error("Crawl failed", "error", err)
+		}
+		_ = crawl.Stop()
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.crawl, c.cancel, c.last = nil, nil, stats
+	}()
+	return nil
+}
+
+// Stop stops the running crawl; it ends once in-flight pages are saved
+func (c *crawlController) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.crawl == nil {
+		return server.ErrNoCrawl
+	}
+	c.cancel()
+	return nil
+}
+
+// Pause stops the running crawl from claiming new URLs
+func (c *crawlController) Pause() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.crawl == nil {
+		return server.ErrNoCrawl
+	}
+	c.crawl.Pause()
+	return nil
+}
+
+// Resume lets a paused crawl claim URLs again
+func (c *crawlController) Resume() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.crawl == nil {
+		return server.ErrNoCrawl
+	}
+	c.crawl.Resume()
+	return nil
+}
+
+// State returns the server.State* of the controller
+func (c *crawlController) State() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.crawl == nil:
+		return server.StateIdle
+	case c.crawl.Paused():
+		return server.StatePaused
+	default:
+		return server.StateRunning
+	}
+}
+
+// Stats returns the statistics of the running crawl, or of the last one when idle
+func (c *crawlController) Stats() crawler.CrawlStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.crawl != nil {
+		return c.crawl.GetStats()
+	}
+	return c.last
+}
+
+// wait blocks until the running crawl, if any, has ended
+func (c *crawlController) wait() {
+	c.wg.Wait()
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/server"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+func TestCrawlController(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body><a href="/about">About</a></body></html>`)
+	}))
+	defer site.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.DatabasePath = filepath.Join(t.TempDir(), "serve.db")
+	cfg.RequestDelay = 0
+	cfg.IgnoreRobotsTxt = true
+	store, err := storage.NewSQLiteStorage(cfg.DatabasePath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	control := newCrawlController(context.Background(), cfg, store, io.Discard)
+	if state := control.State(); state != server.StateIdle {
+		t.Errorf("Initial state = %q, want idle", state)
+	}
+	if err := control.Pause(); !errors.Is(err, server.ErrNoCrawl) {
+		t.Errorf("Pause while idle = %v, want ErrNoCrawl", err)
+	}
+
+	if err := control.Start([]string{site.URL + "/"}); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	control.wait()
+
+	// The crawl ends on its own and leaves its statistics behind
+	if state := control.State(); state != server.StateIdle {
+		t.Errorf("State after the crawl = %q, want idle", state)
+	}
+	if stats := control.Stats(); stats.PagesCrawled != 2 || stats.StopReason != "completed" {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// Another crawl runs on the same database
+	if err := control.Start(nil); err != nil {
+		t.Fatalf("Second start failed: %v", err)
+	}
+	control.wait()
+	if _, _, completed, _, _ := store.GetQueueStatus(); completed != 2 {
+		t.Errorf("Expected 2 completed pages, got %d", completed)
+	}
+}
+
+func TestIsLoopbackAddress(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:8080": true,
+		"[::1]:8080":     true,
+		"localhost:8080": true,
+		":8080":          false,
+		"0.0.0.0:8080":   false,
+		"10.0.0.5:8080":  false,
+		"example.com:80": false,
+	} {
+		if got := isLoopbackAddress(addr); got != want {
+			t.Errorf("isLoopbackAddress(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// notifyShutdown returns a context cancelled on the first SIGINT or SIGTERM,
+// which starts a graceful shutdown: pages in flight get up to drainTimeout to
+// finish. The signals are released at that point, so a second Ctrl-C ends
+// the process at once. The returned function releases them early.
+func notifyShutdown(parent context.Context, out io.Writer, drainTimeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		defer signal.Stop(signals)
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			if drainTimeout > 0 {
+				fmt.Fprintf(out, "Received %v, finishing pages in flight for up to %v; interrupt again to exit at once\n", sig, drainTimeout)
+			} else {
+				fmt.Fprintf(out, "Received %v, stopping the crawl\n", sig)
+			}
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/parser"
+)
+
+// maxSitemapFiles caps the sitemaps read through sitemap indexes, so an index
+// that lists itself or a huge tree of sitemaps cannot run away
+const maxSitemapFiles = 1000
+
+// loadSitemapURLs reads the page URLs of sitemap sources: local files or
+// http(s) URLs holding an XML sitemap, a sitemap index (whose child sitemaps
+// are read too) or a plain-text URL list, optionally gzip-compressed. URLs are
+// normalized the way the crawler stores them and returned without duplicates,
+// in the order first listed.
+func loadSitemapURLs(ctx context.Context, cfg *config.CrawlConfig, sources []string) ([]string, error) {
+	normalizer, err := crawler.NewURLNormalizer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: cfg.RequestTimeout}
+
+	var urls []string
+	seen := make(map[string]bool)
+	read := make(map[string]bool)
+	queue := append([]string(nil), sources...)
+	for len(queue) > 0 {
+		source := queue[0]
+		queue = queue[1:]
+		if read[source] {
+			continue
+		}
+		if len(read) == maxSitemapFiles {
+			return nil, fmt.Errorf("more than %d sitemaps to read", maxSitemapFiles)
+		}
+		read[source] = true
+
+		sitemap, err := readSitemap(ctx, client, cfg.UserAgentWithInfo(), source)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, sitemap.Sitemaps...)
+		for _, raw := range sitemap.URLs {
+			u, err := parser.NormalizeURL(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid URL %q in sitemap %s: %w", raw, source, err)
+			}
+			if u = normalizer.NormalizeURL(u); !seen[u] {
+				seen[u] = true
+				urls = append(urls, u)
+			}
+		}
+	}
+	return urls, nil
+}
+
+// readSitemap fetches and parses one sitemap source
+func readSitemap(ctx context.Context, client *http.Client, userAgent, source string) (*parser.Sitemap, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		file, err := os.Open(source) // #nosec G304 -- path comes from the command line
+		if err != nil {
+			return nil, fmt.Errorf("failed to open sitemap: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		return parseSitemapSource(file, source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sitemap URL %s: %w", source, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sitemap %s: %w", source, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch sitemap %s: HTTP %d", source, resp.StatusCode)
+	}
+	return parseSitemapSource(resp.Body, source)
+}
+
+// parseSitemapSource parses a sitemap, naming its source in errors
+func parseSitemapSource(r io.Reader, source string) (*parser.Sitemap, error) {
+	sitemap, err := parser.ParseSitemap(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	return sitemap, nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// statsCmd prints the inventory of an existing crawl database
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Count the crawled pages by status code, content type, host and error type",
+	Long: `Count the pages of an existing crawl database by HTTP status code, content
+type (media type without parameters), host and error type, without starting a
+crawl. Each breakdown is ordered by page count, largest first.
+
+Hosts count the queued and crawled pages; URLs only discovered as links are
+left out. Error types count the pages whose fetch failed.`,
+	Example: `  linktadoru stats
+  linktadoru stats -d crawl.db --format json
+  linktadoru stats --format csv > inventory.csv`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().StringP("database", "d", "./linktadoru.db", "Path to SQLite database file")
+	statsCmd.Flags().String("results-database", "", "Path to the separate results database, if the crawl used one")
+	statsCmd.Flags().String("format", formatTable, "Output format: table, csv or json")
+	rootCmd.AddCommand(statsCmd)
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	inventory, err := store.GetInventory()
+	if err != nil {
+		return fmt.Errorf("failed to count pages: %w", err)
+	}
+
+	rows := [][]string{}
+	for _, dimension := range storage.InventoryDimensions {
+		for _, count := range inventory.Counts(dimension) {
+			rows = append(rows, []string{dimension, count.Value, strconv.Itoa(count.Pages)})
+		}
+	}
+	return writeReport(cmd.OutOrStdout(), format, []string{"DIMENSION", "VALUE", "PAGES"}, rows, inventory)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+func TestStatsCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "stats.db")
+	writeCrawl(t, dbPath, map[string]*crawler.PageData{
+		"https://example.com/":        {StatusCode: 200},
+		"https://example.com/a":       {StatusCode: 200},
+		"https://cdn.example.com/":    {StatusCode: 200},
+		"https://example.com/missing": {StatusCode: 404},
+	})
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = statsCmd.Flags().Set("format", formatTable)
+		statsCmd.Flags().Lookup("database").Changed = false
+	}()
+
+	rootCmd.SetArgs([]string{"stats", "-d", dbPath, "--format", "csv"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("stats failed: %v", err)
+	}
+	want := "DIMENSION,VALUE,PAGES\n" +
+		"status_code,200,3\n" +
+		"status_code,404,1\n" +
+		"host,example.com,3\n" +
+		"host,cdn.example.com,1\n"
+	if out.String() != want {
+		t.Errorf("Unexpected report:\n%s", out.String())
+	}
+
+	out.Reset()
+	rootCmd.SetArgs([]string{"stats", "-d", dbPath, "--format", "json"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("stats --format json failed: %v", err)
+	}
+	var inventory storage.Inventory
+	if err := json.Unmarshal(out.Bytes(), &inventory); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(inventory.StatusCodes) != 2 || inventory.ContentTypes == nil || len(inventory.ErrorTypes) != 0 {
+		t.Errorf("Unexpected inventory: %+v", inventory)
+	}
+
+	rootCmd.SetArgs([]string{"stats", "-d", filepath.Join(t.TempDir(), "none.db")})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "database not found") {
+		t.Errorf("Expected a missing database error, got %v", err)
+	}
+}
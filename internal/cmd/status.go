@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+const (
+	// statusThroughputWindow is the span `status` measures throughput over
+	statusThroughputWindow = time.Minute
+	// statusProcessingShown is the most in-flight URLs `status` lists
+	statusProcessingShown = 10
+	// clearScreen moves the cursor home and clears the terminal between refreshes
+	clearScreen = "\033[H\033[2J"
+)
+
+// statusCmd shows the progress of a crawl from its database
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the queue, throughput and recent errors of a crawl",
+	Long: `Show the progress of a crawl from its database: the queue counts, the URLs
+being fetched, the pages completed in the last minute, the crawl processes
+with a live heartbeat and the most recent fetch errors.
+
+The database is opened with locking_mode NORMAL, so status is safe to run
+alongside a crawl in progress. With --watch the status is redrawn every
+--interval until interrupted.
+
+--format json or yaml prints the same data for scripts and UIs. With --watch
+each refresh is written as its own document, without clearing the screen:
+one JSON object per line, or YAML documents separated by "---".`,
+	Example: `  linktadoru status -d crawl.db
+  linktadoru status -d crawl.db --watch
+  linktadoru status -d crawl.db --watch --interval 10s --errors 10
+  linktadoru status -d crawl.db --watch --format json | jq .queue.pending`,
+	Args: cobra.NoArgs,
+	RunE: runStatus,
+}
+
+func init() {
+	statusCmd.Flags().StringP("database", "d", "./linktadoru.db", "Path to SQLite database file of the crawl")
+	statusCmd.Flags().String("results-database", "", "Path to the separate results database, if the crawl used one")
+	statusCmd.Flags().Bool("watch", false, "Refresh the status until interrupted")
+	statusCmd.Flags().Duration("interval", 2*time.Second, "Time between refreshes with --watch")
+	statusCmd.Flags().Int("errors", 5, "Most recent fetch errors shown")
+	statusCmd.Flags().String("format", formatText, "Output format: text, yaml or json")
+	// --db reads naturally next to a database path, so it is accepted too
+	statusCmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "db" {
+			name = "database"
+		}
+		return pflag.NormalizedName(name)
+	})
+	rootCmd.AddCommand(statusCmd)
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	watch, _ := cmd.Flags().GetBool("watch")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	errorLimit, _ := cmd.Flags().GetInt("errors")
+	format, _ := cmd.Flags().GetString("format")
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %v", interval)
+	}
+	if err := checkStructuredFormat(format); err != nil {
+		return err
+	}
+	structured := format != formatText && format != ""
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	out := cmd.OutOrStdout()
+	if !watch {
+		snapshot, err := readStatus(store, cfg.DatabasePath, errorLimit)
+		if err != nil {
+			return err
+		}
+		if structured {
+			return writeStructured(out, format, snapshot)
+		}
+		return writeStatus(out, snapshot)
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		snapshot, err := readStatus(store, cfg.DatabasePath, errorLimit)
+		if err != nil {
+			return err
+		}
+		if structured {
+			err = writeStructuredDocument(out, format, snapshot)
+		} else {
+			fmt.Fprint(out, clearScreen)
+			err = writeStatus(out, snapshot)
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// statusSnapshot is the progress of a crawl at one point in time
+type statusSnapshot struct {
+	Database       string        `yaml:"database"`
+	UpdatedAt      string        `yaml:"updated_at"` // RFC 3339, UTC
+	Queue          statusQueue   `yaml:"queue"`
+	PagesPerMinute int           `yaml:"pages_per_minute"`
+	LiveProcesses  int           `yaml:"live_processes"`
+	Processing     []string      `yaml:"processing"`
+	RecentErrors   []statusError `yaml:"recent_errors"`
+}
+
+// statusQueue counts the pages of a crawl by status
+type statusQueue struct {
+	Pending    int `yaml:"pending"`
+	Processing int `yaml:"processing"`
+	Completed  int `yaml:"completed"`
+	Errors     int `yaml:"errors"`
+}
+
+// statusError is one recent fetch error
+type statusError struct {
+	OccurredAt string `yaml:"occurred_at"`
+	ErrorType  string `yaml:"error_type"`
+	URL        string `yaml:"url"`
+	Message    string `yaml:"message"`
+}
+
+// readStatus reads one snapshot of the crawl's progress
+func readStatus(store *storage.SQLiteStorage, path string, errorLimit int) (*statusSnapshot, error) {
+	now := time.Now()
+	pending, processing, completed, errorPages, err := store.GetQueueStatus()
+	if err != nil {
+		return nil, err
+	}
+	recent, err := store.CountCrawledSince(now.Add(-statusThroughputWindow))
+	if err != nil {
+		return nil, err
+	}
+	processes, err := store.LiveCrawlProcesses()
+	if err != nil {
+		return nil, err
+	}
+	inFlight, err := store.GetProcessingItems()
+	if err != nil {
+		return nil, err
+	}
+	var recentErrors []storage.RecentError
+	if errorLimit > 0 {
+		if recentErrors, err = store.GetRecentErrors(errorLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshot := &statusSnapshot{
+		Database:       path,
+		UpdatedAt:      now.UTC().Format(time.RFC3339),
+		Queue:          statusQueue{Pending: pending, Processing: processing, Completed: completed, Errors: errorPages},
+		PagesPerMinute: recent,
+		LiveProcesses:  len(processes),
+		Processing:     []string{},
+		RecentErrors:   []statusError{},
+	}
+	for _, item := range inFlight {
+		snapshot.Processing = append(snapshot.Processing, item.URL)
+	}
+	for _, e := range recentErrors {
+		message, _, _ := strings.Cut(e.ErrorMessage, "\n")
+		snapshot.RecentErrors = append(snapshot.RecentErrors, statusError{
+			OccurredAt: e.OccurredAt, ErrorType: e.ErrorType, URL: e.URL, Message: message,
+		})
+	}
+	return snapshot, nil
+}
+
+// writeStatus prints a snapshot as text
+func writeStatus(w io.Writer, s *statusSnapshot) error {
+	updated, _ := time.Parse(time.RFC3339, s.UpdatedAt)
+	q := s.Queue
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Database:\t%s\n", s.Database)
+	fmt.Fprintf(tw, "Updated:\t%s\n", updated.Local().Format(time.DateTime))
+	fmt.Fprintf(tw, "Queue:\t%d pending, %d processing, %d completed, %d errors\n", q.Pending, q.Processing, q.Completed, q.Errors)
+	fmt.Fprintf(tw, "Throughput:\t%d pages/min\n", s.PagesPerMinute)
+	fmt.Fprintf(tw, "Processes:\t%d live\n", s.LiveProcesses)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(s.Processing) > 0 {
+		fmt.Fprintln(w, "\nProcessing:")
+		for i, url := range s.Processing {
+			if i == statusProcessingShown {
+				fmt.Fprintf(w, "  ... and %d more\n", len(s.Processing)-i)
+				break
+			}
+			fmt.Fprintf(w, "  %s\n", url)
+		}
+	}
+	if len(s.RecentErrors) > 0 {
+		fmt.Fprintln(w, "\nRecent errors:")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, e := range s.RecentErrors {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", e.OccurredAt, e.ErrorType, e.URL, e.Message)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
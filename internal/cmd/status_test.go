@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+func TestStatusCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "status.db")
+	writeCrawl(t, dbPath, map[string]*crawler.PageData{
+		"https://example.com/":  {StatusCode: 200},
+		"https://example.com/a": {StatusCode: 200},
+	})
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/down", "https://example.com/next", "https://example.com/later"})
+	item, _ := store.GetNextFromQueue()
+	_ = store.SavePageError(item.ID, "network_error", "connection refused")
+	_ = store.SaveError(&crawler.CrawlError{URL: item.URL, ErrorType: "network_error", ErrorMessage: "connection refused", OccurredAt: time.Now()})
+	inFlight, _ := store.GetNextFromQueue()
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = statusCmd.Flags().Set("watch", "false")
+		_ = statusCmd.Flags().Set("interval", "2s")
+		statusCmd.Flags().Lookup("database").Changed = false
+	}()
+
+	rootCmd.SetArgs([]string{"status", "--db", dbPath})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	for _, want := range []string{
+		"1 pending, 1 processing, 2 completed, 1 errors",
+		"Throughput:  2 pages/min",
+		"Processing:\n  " + inFlight.URL,
+		"network_error  " + item.URL + "  connection refused",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Status lacks %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), clearScreen) {
+		t.Error("Status without --watch should not clear the screen")
+	}
+
+	out.Reset()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	// A subcommand keeps the context of its first run, so set it directly
+	statusCmd.SetContext(ctx)
+	defer statusCmd.SetContext(context.Background())
+	rootCmd.SetArgs([]string{"status", "-d", dbPath, "--watch", "--interval", "10ms"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("status --watch failed: %v", err)
+	}
+	if refreshes := strings.Count(out.String(), clearScreen); refreshes < 2 {
+		t.Errorf("Expected several refreshes, got %d", refreshes)
+	}
+}
+
+func TestStatusStructuredOutput(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "status.db")
+	writeCrawl(t, dbPath, map[string]*crawler.PageData{
+		"https://example.com/": {StatusCode: 200},
+	})
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/down", "https://example.com/next"})
+	item, _ := store.GetNextFromQueue()
+	_ = store.SavePageError(item.ID, "network_error", "connection refused")
+	_ = store.SaveError(&crawler.CrawlError{URL: item.URL, ErrorType: "network_error", ErrorMessage: "connection refused\ndetails", OccurredAt: time.Now()})
+	_ = store.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = statusCmd.Flags().Set("watch", "false")
+		_ = statusCmd.Flags().Set("interval", "2s")
+		_ = statusCmd.Flags().Set("format", formatText)
+		statusCmd.Flags().Lookup("database").Changed = false
+	}()
+
+	rootCmd.SetArgs([]string{"status", "-d", dbPath, "--format", "json"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("status --format json failed: %v", err)
+	}
+	var snapshot map[string]any
+	if err := json.Unmarshal(out.Bytes(), &snapshot); err != nil {
+		t.Fatalf("Invalid JSON status: %v\n%s", err, out.String())
+	}
+	queue, _ := snapshot["queue"].(map[string]any)
+	if queue["pending"] != 1.0 || queue["completed"] != 1.0 || queue["errors"] != 1.0 || queue["processing"] != 0.0 {
+		t.Errorf("Unexpected queue: %v", snapshot["queue"])
+	}
+	if snapshot["database"] != dbPath || snapshot["pages_per_minute"] != 1.0 || snapshot["live_processes"] != 0.0 {
+		t.Errorf("Unexpected status: %v", snapshot)
+	}
+	if _, err := time.Parse(time.RFC3339, snapshot["updated_at"].(string)); err != nil {
+		t.Errorf("updated_at is not RFC 3339: %v", snapshot["updated_at"])
+	}
+	if processing, ok := snapshot["processing"].([]any); !ok || len(processing) != 0 {
+		t.Errorf("Expected an empty processing list, got %v", snapshot["processing"])
+	}
+	errs, _ := snapshot["recent_errors"].([]any)
+	if len(errs) != 1 {
+		t.Fatalf("Expected one recent error, got %v", snapshot["recent_errors"])
+	}
+	recent := errs[0].(map[string]any)
+	if recent["url"] != item.URL || recent["error_type"] != "network_error" || recent["message"] != "connection refused" {
+		t.Errorf("Unexpected recent error: %v", recent)
+	}
+
+	// With --watch every refresh is one JSON document on its own line
+	out.Reset()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	statusCmd.SetContext(ctx)
+	defer statusCmd.SetContext(context.Background())
+	rootCmd.SetArgs([]string{"status", "-d", dbPath, "--watch", "--interval", "10ms", "--format", "json"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("status --watch --format json failed: %v", err)
+	}
+	if strings.Contains(out.String(), clearScreen) {
+		t.Error("Structured output should not clear the screen")
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("Expected several refreshes, got:\n%s", out.String())
+	}
+	for _, line := range lines {
+		var refresh map[string]any
+		if err := json.Unmarshal([]byte(line), &refresh); err != nil {
+			t.Fatalf("Refresh is not one JSON document: %v\n%s", err, line)
+		}
+		if _, ok := refresh["queue"]; !ok {
+			t.Errorf("Refresh lacks the queue: %s", line)
+		}
+	}
+
+	rootCmd.SetArgs([]string{"status", "-d", dbPath, "--format", "xml"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// trendsChartWidth is the length of the longest bar of `trends --chart`
+const trendsChartWidth = 40
+
+// trendMetrics are the session totals `trends --chart` can plot
+var trendMetrics = map[string]func(storage.CrawlSession) float64{
+	"pages":         func(s storage.CrawlSession) float64 { return float64(s.Pages) },
+	"pages_crawled": func(s storage.CrawlSession) float64 { return float64(s.PagesCrawled) },
+	"error_pages":   func(s storage.CrawlSession) float64 { return float64(s.ErrorPages) },
+	"avg_ttfb_ms":   func(s storage.CrawlSession) float64 { return s.AvgTTFBMs },
+	"broken_links":  func(s storage.CrawlSession) float64 { return float64(s.BrokenLinks) },
+}
+
+// trendsCmd lists the recorded crawl sessions over time
+var trendsCmd = &cobra.Command{
+	Use:   "trends [DATABASE...]",
+	Short: "List the totals of recurring crawls over time",
+	Long: `List the crawl sessions recorded in one or more databases, oldest first,
+with the totals of the database when each run ended: completed pages, error
+pages (fetch failures and 4xx/5xx answers), the average TTFB and the number of
+broken links.
+
+Every crawl run records its session, so a site crawled on a schedule becomes a
+monitoring dataset, whether the runs share a database or each writes a new
+one: pass all the databases to see them together. Without arguments the
+configured database is read. --chart plots one total as a bar per session.`,
+	Example: `  linktadoru trends
+  linktadoru trends crawls/*.db --format csv > trends.csv
+  linktadoru trends crawls/*.db --chart broken_links`,
+	RunE: runTrends,
+}
+
+func init() {
+	trendsCmd.Flags().String("format", formatTable, "Output format: table, csv or json")
+	trendsCmd.Flags().String("chart", "", "Plot one total instead of the table: "+strings.Join(trendMetricNames(), ", "))
+	rootCmd.AddCommand(trendsCmd)
+}
+
+func runTrends(cmd *cobra.Command, args []string) error {
+	cfg, err := loadSubcommandConfig(cmd)
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	chart, _ := cmd.Flags().GetString("chart")
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	metric, ok := trendMetrics[chart]
+	if chart != "" && !ok {
+		return fmt.Errorf("unknown chart '%s': must be one of %s", chart, strings.Join(trendMetricNames(), ", "))
+	}
+
+	paths := args
+	if len(paths) == 0 {
+		paths = []string{cfg.DatabasePath}
+	}
+	sessions := []storage.CrawlSession{}
+	for _, path := range paths {
+		dbCfg := *cfg
+		if len(args) > 0 {
+			dbCfg.DatabasePath = path
+			dbCfg.ResultsDatabasePath = ""
+		}
+		store, err := openReadOnlyStorage(&dbCfg)
+		if err != nil {
+			return err
+		}
+		found, err := store.GetCrawlSessions()
+		_ = store.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		sessions = append(sessions, found...)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].StartedAt.Before(sessions[j].StartedAt) })
+
+	if chart != "" {
+		return writeTrendsChart(cmd.OutOrStdout(), sessions, metric)
+	}
+	rows := make([][]string, 0, len(sessions))
+	for _, s := range sessions {
+		rows = append(rows, []string{
+			s.ID,
+			s.FinishedAt.Local().Format(time.DateTime),
+			s.StopReason,
+			strconv.Itoa(s.PagesCrawled),
+			strconv.Itoa(s.Pages),
+			strconv.Itoa(s.ErrorPages),
+			strconv.FormatFloat(s.AvgTTFBMs, 'f', 1, 64),
+			strconv.Itoa(s.BrokenLinks),
+		})
+	}
+	headers := []string{"SESSION", "FINISHED", "STOP_REASON", "CRAWLED", "PAGES", "ERROR_PAGES", "AVG_TTFB_MS", "BROKEN_LINKS"}
+	return writeReport(cmd.OutOrStdout(), format, headers, rows, sessions)
+}
+
+// writeTrendsChart draws a horizontal bar per session, scaled to the largest value
+func writeTrendsChart(w io.Writer, sessions []storage.CrawlSession, metric func(storage.CrawlSession) float64) error {
+	largest := 0.0
+	for _, s := range sessions {
+		largest = max(largest, metric(s))
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, s := range sessions {
+		value := metric(s)
+		bar := 0
+		if largest > 0 {
+			bar = int(value / largest * trendsChartWidth)
+		}
+		fmt.Fprintf(tw, "%s\t%s %s\n", s.FinishedAt.Local().Format(time.DateTime),
+			strings.Repeat("█", bar), strconv.FormatFloat(value, 'f', -1, 64))
+	}
+	return tw.Flush()
+}
+
+// trendMetricNames returns the names of trendMetrics, sorted
+func trendMetricNames() []string {
+	names := make([]string, 0, len(trendMetrics))
+	for name := range trendMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// recordCrawlSession stores the totals of a finished run in the database for
+// `linktadoru trends`; a failure is logged and does not fail the crawl
+func recordCrawlSession(cfg *config.CrawlConfig, sessionID string, stats crawler.CrawlStats, startedAt, finishedAt time.Time) {
+	// Other storage drivers keep no database file to reopen
+	if storage.DriverName(cfg) != storage.DriverSQLite {
+		return
+	}
+	store, err := openStorage(cfg)
+	if err == nil {
+		_, err = store.RecordCrawlSession(storage.CrawlSession{
+			ID:           sessionID,
+			StartedAt:    startedAt,
+			FinishedAt:   finishedAt,
+			StopReason:   stats.StopReason,
+			PagesCrawled: stats.PagesCrawled,
+		})
+		_ = store.Close()
+	}
+	if err != nil {
+		slog.Warn("Failed to record crawl session", "error", err)
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestTrendsCommand(t *testing.T) {
+	dir := t.TempDir()
+	startedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var paths []string
+	// Listed newest first; trends orders the sessions by start time
+	for _, crawl := range []struct {
+		session string
+		week    int
+		pages   map[string]*crawler.PageData
+	}{
+		{"week2", 1, map[string]*crawler.PageData{"https://example.com/": {StatusCode: 200}, "https://example.com/a": {StatusCode: 200}}},
+		{"week1", 0, map[string]*crawler.PageData{"https://example.com/": {StatusCode: 200}, "https://example.com/a": {StatusCode: 500}}},
+	} {
+		cfg := config.DefaultConfig()
+		cfg.DatabasePath = filepath.Join(dir, crawl.session+".db")
+		writeCrawl(t, cfg.DatabasePath, crawl.pages)
+		start := startedAt.Add(time.Duration(crawl.week) * 7 * 24 * time.Hour)
+		stats := crawler.CrawlStats{PagesCrawled: 2, StopReason: crawler.StopReasonCompleted}
+		recordCrawlSession(cfg, crawl.session, stats, start, start.Add(time.Minute))
+		paths = append(paths, cfg.DatabasePath)
+	}
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		_ = trendsCmd.Flags().Set("format", formatTable)
+		_ = trendsCmd.Flags().Set("chart", "")
+	}()
+
+	rootCmd.SetArgs(append([]string{"trends", "--format", "csv"}, paths...))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("trends failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || lines[0] != "SESSION,FINISHED,STOP_REASON,CRAWLED,PAGES,ERROR_PAGES,AVG_TTFB_MS,BROKEN_LINKS" {
+		t.Fatalf("Unexpected report:\n%s", out.String())
+	}
+	if !strings.HasPrefix(lines[1], "week1,") || !strings.HasSuffix(lines[1], ",completed,2,2,1,0.0,0") {
+		t.Errorf("Unexpected first session: %s", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "week2,") || !strings.HasSuffix(lines[2], ",completed,2,2,0,0.0,0") {
+		t.Errorf("Unexpected second session: %s", lines[2])
+	}
+
+	out.Reset()
+	rootCmd.SetArgs(append([]string{"trends", "--chart", "error_pages"}, paths...))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("trends --chart failed: %v", err)
+	}
+	chart := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(chart) != 2 || !strings.HasSuffix(chart[0], strings.Repeat("█", trendsChartWidth)+" 1") || !strings.HasSuffix(chart[1], " 0") {
+		t.Errorf("Unexpected chart:\n%s", out.String())
+	}
+
+	rootCmd.SetArgs(append([]string{"trends", "--chart", "latency"}, paths...))
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "unknown chart") {
+		t.Errorf("Expected an unknown chart error, got %v", err)
+	}
+}
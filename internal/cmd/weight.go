@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// errBudgetExceeded is returned with fail_on_budget when a page exceeds a
+// page weight budget
+var errBudgetExceeded = errors.New("pages exceed page weight budgets")
+
+// weightBudgets returns the configured page weight budgets, overridden by the
+// budget flags cmd was given
+func weightBudgets(cmd *cobra.Command, cfg *config.CrawlConfig) storage.PageWeightBudgets {
+	budgets := storage.PageWeightBudgets{
+		Total:      cfg.PageWeightBudget,
+		Script:     cfg.ScriptWeightBudget,
+		Stylesheet: cfg.StylesheetWeightBudget,
+		Image:      cfg.ImageWeightBudget,
+	}
+	for _, flag := range []struct {
+		name   string
+		budget *int64
+	}{
+		{"page-weight-budget", &budgets.Total},
+		{"script-weight-budget", &budgets.Script},
+		{"stylesheet-weight-budget", &budgets.Stylesheet},
+		{"image-weight-budget", &budgets.Image},
+	} {
+		if cmd.Flags().Changed(flag.name) {
+			*flag.budget, _ = cmd.Flags().GetInt64(flag.name)
+		}
+	}
+	return budgets
+}
+
+// checkWeightBudgets lists the pages of a finished crawl that exceed the
+// configured page weight budgets on out. With fail_on_budget, it returns
+// errBudgetExceeded when there are any.
+func checkWeightBudgets(cmd *cobra.Command, cfg *config.CrawlConfig, out io.Writer) error {
+	budgets := weightBudgets(cmd, cfg)
+	// Page weights are computed from the database file
+	if budgets == (storage.PageWeightBudgets{}) || storage.DriverName(cfg) != storage.DriverSQLite {
+		return nil
+	}
+
+	store, err := openReadOnlyStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	weights, err := store.GetPageWeights(budgets, false)
+	if err != nil {
+		return err
+	}
+	if len(weights) == 0 {
+		fmt.Fprintf(out, "All pages are within the page weight budgets\n")
+		return nil
+	}
+	fmt.Fprintf(out, "%d pages exceed page weight budgets:\n", len(weights))
+	for _, w := range weights {
+		fmt.Fprintf(out, "  %s (%d bytes; over %s)\n", w.URL, w.TotalBytes, strings.Join(w.OverBudget, ", "))
+	}
+	if cfg.FailOnBudget {
+		cmd.SilenceUsage = true // Not a usage error
+		return errBudgetExceeded
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// writeWeightCrawl stores a 5000-byte page loading a 400000-byte script
+func writeWeightCrawl(t *testing.T, path string) {
+	t.Helper()
+	store, err := storage.NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	_ = store.AddToQueue([]string{"https://example.com/", "https://example.com/app.js"})
+	for _, size := range []int64{5000, 400000} {
+		item, _ := store.GetNextFromQueue()
+		contentType := "text/html"
+		if strings.HasSuffix(item.URL, ".js") {
+			contentType = "text/javascript"
+		}
+		_ = store.SavePageResult(item.ID, &crawler.PageData{URL: item.URL, StatusCode: 200, ResponseSize: size,
+			HTTPHeaders: map[string]string{"content-type": contentType}, CrawledAt: time.Now()})
+	}
+	err = store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/app.js", LinkType: crawler.LinkTypeAsset, RelAttribute: "script"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save links: %v", err)
+	}
+}
+
+func TestCheckWeightBudgets(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.DatabasePath = filepath.Join(t.TempDir(), "weight.db")
+	writeWeightCrawl(t, cfg.DatabasePath)
+
+	// Without budgets nothing is checked
+	var out bytes.Buffer
+	if err := checkWeightBudgets(&cobra.Command{}, cfg, &out); err != nil || out.Len() != 0 {
+		t.Errorf("Expected no check without budgets, got %q (%v)", out.String(), err)
+	}
+
+	cfg.PageWeightBudget = 1_000_000
+	if err := checkWeightBudgets(&cobra.Command{}, cfg, &out); err != nil || !strings.Contains(out.String(), "within the page weight budgets") {
+		t.Errorf("Expected every page within budget, got %q (%v)", out.String(), err)
+	}
+
+	out.Reset()
+	cfg.PageWeightBudget = 100_000
+	if err := checkWeightBudgets(&cobra.Command{}, cfg, &out); err != nil {
+		t.Errorf("Expected no error without fail_on_budget, got %v", err)
+	}
+	if want := "1 pages exceed page weight budgets:\n  https://example.com/ (405000 bytes; over total)\n"; out.String() != want {
+		t.Errorf("Unexpected summary %q, want %q", out.String(), want)
+	}
+
+	cfg.FailOnBudget = true
+	if err := checkWeightBudgets(&cobra.Command{}, cfg, &out); !errors.Is(err, errBudgetExceeded) {
+		t.Errorf("Expected errBudgetExceeded, got %v", err)
+	}
+}
@@ -3,12 +3,55 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
+
+	"golang.org/x/net/http/httpguts"
 )
 
+// URLFilter decides in Go whether a URL that passed the host and pattern
+// checks is crawled. Programs embedding the crawler set CrawlConfig.URLFilters
+// for scoping logic regular expressions cannot express.
+type URLFilter func(u *url.URL) bool
+
+// AllURLFilters returns a filter accepting a URL only when every filter does;
+// filters run in order and the first rejection stops the check
+func AllURLFilters(filters ...URLFilter) URLFilter {
+	return func(u *url.URL) bool {
+		for _, filter := range filters {
+			if !filter(u) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyURLFilter returns a filter accepting a URL when at least one filter
+// does; filters run in order and the first acceptance stops the check
+func AnyURLFilter(filters ...URLFilter) URLFilter {
+	return func(u *url.URL) bool {
+		for _, filter := range filters {
+			if filter(u) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// NotURLFilter returns a filter accepting exactly the URLs filter rejects
+func NotURLFilter(filter URLFilter) URLFilter {
+	return func(u *url.URL) bool { return !filter(u) }
+}
+
 // BasicAuth contains HTTP Basic Authentication credentials
 type BasicAuth struct {
 	Username    string `mapstructure:"username" yaml:"username"`         // Username for basic auth
@@ -25,6 +68,13 @@ const (
 	BasicAuthType  AuthType = "basic"
 	BearerAuthType AuthType = "bearer"
 	APIKeyAuthType AuthType = "api-key"
+
+	// NTLMAuthType and NegotiateAuthType authenticate against Windows-integrated
+	// servers (IIS, SharePoint). Both reuse the basic credential block; use
+	// DOMAIN\user for domain accounts. Negotiate is answered with NTLM only;
+	// Kerberos is not supported.
+	NTLMAuthType      AuthType = "ntlm"
+	NegotiateAuthType AuthType = "negotiate"
 )
 
 // BearerAuth represents Bearer token authentication
@@ -47,33 +97,187 @@ type Auth struct {
 	Basic  *BasicAuth  `mapstructure:"basic" yaml:"basic"`   // Basic authentication settings
 	Bearer *BearerAuth `mapstructure:"bearer" yaml:"bearer"` // Bearer authentication settings
 	APIKey *APIKeyAuth `mapstructure:"apikey" yaml:"apikey"` // API key authentication settings
+	Hosts  []string    `mapstructure:"hosts" yaml:"hosts"`   // Hosts credentials are sent to (supports *.example.com; default: seed hosts)
 }
 
+// Redaction contains rules for scrubbing personal data from stored crawl results
+type Redaction struct {
+	Patterns    []string `mapstructure:"patterns" yaml:"patterns"`         // Regex patterns whose matches are replaced in stored text
+	QueryParams []string `mapstructure:"query_params" yaml:"query_params"` // Query parameter names whose values are replaced in URLs
+	Replacement string   `mapstructure:"replacement" yaml:"replacement"`   // Replacement text (default "[REDACTED]")
+}
+
+// Webhook is an endpoint that crawl events are POSTed to as JSON
+type Webhook struct {
+	URL         string   `mapstructure:"url" yaml:"url"`                   // Endpoint the events are POSTed to
+	Events      []string `mapstructure:"events" yaml:"events"`             // Webhook* events sent (empty = all)
+	ErrorRate   float64  `mapstructure:"error_rate" yaml:"error_rate"`     // Percent of failed pages that sends error_rate (0 = never)
+	URLPatterns []string `mapstructure:"url_patterns" yaml:"url_patterns"` // Regexes of URLs whose failure sends url_failed (empty = none)
+	Headers     []string `mapstructure:"headers" yaml:"headers"`           // Extra "Name: Value" request headers, e.g. for authentication
+}
+
+// Webhook events
+const (
+	WebhookCrawlStarted  = "crawl_started"  // A crawl run began
+	WebhookCrawlFinished = "crawl_finished" // A crawl run ended, with its totals and stop reason
+	WebhookErrorRate     = "error_rate"     // The share of failed pages exceeded error_rate, sent once per run
+	WebhookURLFailed     = "url_failed"     // A page matching url_patterns failed
+)
+
+// check_external modes
+const (
+	CheckExternalNone = "none" // Out-of-scope links are recorded but never requested
+	CheckExternalHead = "head" // Out-of-scope links are verified with HEAD (or a ranged GET)
+)
+
+// trailing_slash modes
+const (
+	TrailingSlashKeep   = "keep"   // URLs are queued as linked
+	TrailingSlashAdd    = "add"    // /docs is queued as /docs/; paths naming a file (/a.pdf) are left alone
+	TrailingSlashRemove = "remove" // /docs/ is queued as /docs; the root path keeps its slash
+)
+
+// queue_order modes
+const (
+	QueueOrderHost = "host" // Claims rotate across hosts, least busy host first
+	QueueOrderFIFO = "fifo" // Claims follow discovery order regardless of host
+)
+
 // CrawlConfig holds crawler configuration
 type CrawlConfig struct {
 	// Basic crawling parameters
 	SeedURLs            []string      `mapstructure:"seed_urls" yaml:"seed_urls"`                         // Starting URLs for crawling
 	Concurrency         int           `mapstructure:"concurrency" yaml:"concurrency"`                     // Number of concurrent workers
+	MinConcurrency      int           `mapstructure:"min_concurrency" yaml:"min_concurrency"`             // Fewest workers the autoscaler keeps (0 = 1)
+	MaxConcurrency      int           `mapstructure:"max_concurrency" yaml:"max_concurrency"`             // Most workers the autoscaler starts (0 = fixed concurrency)
 	RequestDelay        float64       `mapstructure:"request_delay" yaml:"request_delay"`                 // Delay between requests
+	RequestJitter       float64       `mapstructure:"request_jitter" yaml:"request_jitter"`               // Random ±% variation applied to request_delay (0-100)
 	RequestTimeout      time.Duration `mapstructure:"request_timeout" yaml:"request_timeout"`             // HTTP request timeout
 	UserAgent           string        `mapstructure:"user_agent" yaml:"user_agent"`                       // HTTP User-Agent header
+	CrawlerInfoURL      string        `mapstructure:"crawler_info_url" yaml:"crawler_info_url"`           // Page describing the crawler, added to the User-Agent as "(+URL)"
 	IgnoreRobotsTxt     bool          `mapstructure:"ignore_robots_txt" yaml:"ignore_robots_txt"`         // Whether to ignore robots.txt
+	RespectXRobotsTag   bool          `mapstructure:"respect_x_robots_tag" yaml:"respect_x_robots_tag"`   // Do not queue links of pages served with X-Robots-Tag: nofollow
+	RespectNofollow     bool          `mapstructure:"respect_nofollow" yaml:"respect_nofollow"`           // Do not queue rel="nofollow" links or links of meta robots nofollow pages
+	FollowCanonical     bool          `mapstructure:"follow_canonical" yaml:"follow_canonical"`           // Queue a page's canonical URL instead of its links when the two differ
+	PaginationDepth     int           `mapstructure:"pagination_depth" yaml:"pagination_depth"`           // Most pagination links followed in a row (0 = unlimited)
 	FollowExternalHosts bool          `mapstructure:"follow_external_hosts" yaml:"follow_external_hosts"` // Whether to crawl external hosts
+	IncludeSubdomains   bool          `mapstructure:"include_subdomains" yaml:"include_subdomains"`       // Also crawl subdomains of seed hosts
+	CheckExternal       string        `mapstructure:"check_external" yaml:"check_external"`               // How out-of-scope links are verified: "none" or "head"
+	ExternalCheckers    int           `mapstructure:"external_checkers" yaml:"external_checkers"`         // Goroutines verifying out-of-scope links, apart from the page workers
 	Limit               int           `mapstructure:"limit" yaml:"limit"`                                 // Stop after N pages
+	TimeoutTotal        time.Duration `mapstructure:"timeout_total" yaml:"timeout_total"`                 // Stop the whole crawl after this long (0 = no limit)
+	ShutdownTimeout     time.Duration `mapstructure:"shutdown_timeout" yaml:"shutdown_timeout"`           // How long pages in flight may take to finish after an interrupt (0 = cancel them at once)
+	MaxRetries          int           `mapstructure:"max_retries" yaml:"max_retries"`                     // Times a page failing transiently (timeout, connection reset, 5xx) is fetched again (0 = never)
+	RetryBackoff        time.Duration `mapstructure:"retry_backoff" yaml:"retry_backoff"`                 // Wait before the first retry round, doubled for each further round
+	MaxQueueSize        int           `mapstructure:"max_queue_size" yaml:"max_queue_size"`               // Maximum pending URLs; further discoveries are dropped (0 = unlimited)
+	MaxResponseSize     int64         `mapstructure:"max_response_size" yaml:"max_response_size"`         // Maximum response body size in bytes (0 = unlimited)
+	SeenURLCacheSize    int           `mapstructure:"seen_url_cache_size" yaml:"seen_url_cache_size"`     // Queued URLs remembered in memory to skip database lookups (0 = disabled)
+	QueueBatchSize      int           `mapstructure:"queue_batch_size" yaml:"queue_batch_size"`           // Queue items each worker claims per database call (1 = one at a time)
+	QueueOrder          string        `mapstructure:"queue_order" yaml:"queue_order"`                     // Dequeue order: "host" rotates across hosts, "fifo" follows discovery order
+	WriteBufferSize     int           `mapstructure:"write_buffer_size" yaml:"write_buffer_size"`         // Processed pages buffered for the result writers (0 = workers write synchronously)
+	WriteWorkers        int           `mapstructure:"write_workers" yaml:"write_workers"`                 // Goroutines persisting buffered results
+
+	// Spider-trap detection (0 disables a rule); trapped URLs are skipped with reason trap_detected
+	MaxURLLength        int `mapstructure:"max_url_length" yaml:"max_url_length"`               // Longest URL crawled, in bytes
+	MaxPathSegments     int `mapstructure:"max_path_segments" yaml:"max_path_segments"`         // Most path segments in a crawled URL
+	MaxRepeatedSegments int `mapstructure:"max_repeated_segments" yaml:"max_repeated_segments"` // Most occurrences of one path segment (/a/b/a/b)
+	MaxQueryParams      int `mapstructure:"max_query_params" yaml:"max_query_params"`           // Most query parameters in a crawled URL
+	MaxQueryVariants    int `mapstructure:"max_query_variants" yaml:"max_query_variants"`       // Most query strings crawled per path in one run
 
 	// Authentication
 	Auth *Auth `mapstructure:"auth" yaml:"auth"` // Authentication configuration
 
 	// URL filtering
+	AllowedHosts    []string `mapstructure:"allowed_hosts" yaml:"allowed_hosts"`       // Hosts to crawl (supports *.example.com); replaces seed-host scoping when set
+	BlockedHosts    []string `mapstructure:"blocked_hosts" yaml:"blocked_hosts"`       // Hosts never to crawl (supports *.example.com)
 	IncludePatterns []string `mapstructure:"include_patterns" yaml:"include_patterns"` // Regex patterns for URLs to include
 	ExcludePatterns []string `mapstructure:"exclude_patterns" yaml:"exclude_patterns"` // Regex patterns for URLs to exclude
 	AllowedSchemes  []string `mapstructure:"allowed_schemes" yaml:"allowed_schemes"`   // Allowed URL schemes (e.g., https://, http://)
 
+	// Go URL filters, set only by programs embedding the crawler
+	URLFilters []URLFilter `mapstructure:"-" yaml:"-"` // Run in order after the patterns; every one must accept a URL
+
+	// URL normalization (applied to seeds and discovered links before queueing)
+	StripQueryParams []string `mapstructure:"strip_query_params" yaml:"strip_query_params"` // Query parameters removed from URLs (glob patterns, e.g. utm_*)
+	KeepQueryParams  []string `mapstructure:"keep_query_params" yaml:"keep_query_params"`   // When set, only these query parameters are kept (glob patterns)
+	TrailingSlash    string   `mapstructure:"trailing_slash" yaml:"trailing_slash"`         // "keep", "add" or "remove" the final slash of paths
+	DirectoryIndex   []string `mapstructure:"directory_index" yaml:"directory_index"`       // File names dropped from the end of paths, e.g. index.html
+
+	// Scripting
+	Script        string        `mapstructure:"script" yaml:"script"`                 // Lua script deciding which URLs to crawl, rewriting them and extracting fields (empty = disabled)
+	ScriptTimeout time.Duration `mapstructure:"script_timeout" yaml:"script_timeout"` // How long one call of a script function may run
+
+	// JavaScript rendering
+	RenderPatterns []string      `mapstructure:"render_patterns" yaml:"render_patterns"` // Regex patterns of URLs rendered in a headless browser before parsing
+	RenderTimeout  time.Duration `mapstructure:"render_timeout" yaml:"render_timeout"`   // How long the browser waits for a page to reach network idle
+	RenderFallback bool          `mapstructure:"render_fallback" yaml:"render_fallback"` // Render 200 HTML pages without links that look like single-page applications
+	BrowserPath    string        `mapstructure:"browser_path" yaml:"browser_path"`       // Chrome or Chromium executable (empty = looked up in PATH)
+	BrowserURL     string        `mapstructure:"browser_url" yaml:"browser_url"`         // DevTools WebSocket URL of a running browser to use instead of launching one
+
+	// Page checks
+	AccessibilityChecks bool `mapstructure:"accessibility_checks" yaml:"accessibility_checks"` // Record missing alt text, labels, link and button names, lang and heading jumps
+
+	// Content-type filtering (checked when response headers arrive)
+	AllowedContentTypes []string `mapstructure:"allowed_content_types" yaml:"allowed_content_types"` // Media types to download, e.g. text/html, image/* (empty = all)
+	BlockedContentTypes []string `mapstructure:"blocked_content_types" yaml:"blocked_content_types"` // Media types never downloaded
+	HashAssets          bool     `mapstructure:"hash_assets" yaml:"hash_assets"`                     // Store a SHA-256 hash of non-HTML response bodies
+	CrawlAssets         bool     `mapstructure:"crawl_assets" yaml:"crawl_assets"`                   // Also fetch stylesheets, scripts, images and icons referenced by pages
+
+	// Page weight budgets in bytes, checked after crawls with crawl_assets (0 = no budget)
+	PageWeightBudget       int64 `mapstructure:"page_weight_budget" yaml:"page_weight_budget"`             // Most bytes of a page's HTML and assets together
+	ScriptWeightBudget     int64 `mapstructure:"script_weight_budget" yaml:"script_weight_budget"`         // Most bytes of a page's scripts
+	StylesheetWeightBudget int64 `mapstructure:"stylesheet_weight_budget" yaml:"stylesheet_weight_budget"` // Most bytes of a page's stylesheets
+	ImageWeightBudget      int64 `mapstructure:"image_weight_budget" yaml:"image_weight_budget"`           // Most bytes of a page's images and icons
+	FailOnBudget           bool  `mapstructure:"fail_on_budget" yaml:"fail_on_budget"`                     // Exit with a non-zero status when a page exceeds a budget
+
 	// HTTP Headers
-	Headers []string `mapstructure:"headers" yaml:"headers"` // Custom HTTP headers
+	Headers   []string `mapstructure:"headers" yaml:"headers"`       // Custom HTTP headers
+	RunHeader string   `mapstructure:"run_header" yaml:"run_header"` // Header stamped on every request with a per-run UUID (empty = disabled)
+
+	// TLS configuration
+	TLSClientCert         string `mapstructure:"tls_client_cert" yaml:"tls_client_cert"`                   // PEM client certificate file for mutual TLS
+	TLSClientKey          string `mapstructure:"tls_client_key" yaml:"tls_client_key"`                     // PEM private key file for the client certificate
+	TLSCAFile             string `mapstructure:"tls_ca_file" yaml:"tls_ca_file"`                           // PEM CA bundle trusted in addition to system roots
+	TLSInsecureSkipVerify bool   `mapstructure:"tls_insecure_skip_verify" yaml:"tls_insecure_skip_verify"` // Disable certificate verification (staging only)
+
+	// Response recording and replay
+	RecordDir string `mapstructure:"record_dir" yaml:"record_dir"` // Directory every response is saved in for offline replay (empty = disabled)
+	ReplayDir string `mapstructure:"replay_dir" yaml:"replay_dir"` // Directory of recorded responses to crawl instead of the network (empty = disabled)
+
+	// DNS resolution
+	DNSCacheTTL  time.Duration     `mapstructure:"dns_cache_ttl" yaml:"dns_cache_ttl"` // How long resolved addresses are cached (0 = no caching)
+	DNSResolver  string            `mapstructure:"dns_resolver" yaml:"dns_resolver"`   // DNS server "host:port" to query instead of the system resolver
+	DNSOverrides map[string]string `mapstructure:"dns_overrides" yaml:"dns_overrides"` // Hosts-file-style overrides (hostname -> IP)
+
+	// Data redaction
+	Redaction *Redaction `mapstructure:"redaction" yaml:"redaction"` // Redaction rules applied before results are stored
+
+	// Notifications
+	Webhooks []Webhook `mapstructure:"webhooks" yaml:"webhooks"` // Endpoints notified of crawl events
 
 	// Database configuration
-	DatabasePath string `mapstructure:"database_path" yaml:"database_path"` // Path to SQLite database file
+	StorageDriver         string `mapstructure:"storage_driver" yaml:"storage_driver"`                   // Registered storage backend ("sqlite" or "" = built-in SQLite database)
+	DatabasePath          string `mapstructure:"database_path" yaml:"database_path"`                     // Path to SQLite database file
+	ResultsDatabasePath   string `mapstructure:"results_database_path" yaml:"results_database_path"`     // Separate SQLite file for crawl results ("" = same file)
+	DatabaseEncryption    bool   `mapstructure:"database_encryption" yaml:"database_encryption"`         // Encrypt sensitive columns at rest
+	DatabasePassphraseEnv string `mapstructure:"database_passphrase_env" yaml:"database_passphrase_env"` // Environment variable holding the encryption passphrase
+
+	// Pages left 'processing' longer than this by a crashed run are requeued at startup (0 = all of them)
+	StaleProcessingTimeout time.Duration `mapstructure:"stale_processing_timeout" yaml:"stale_processing_timeout"`
+
+	// Shared Redis frontier (storage_driver: redis)
+	RedisURL       string        `mapstructure:"redis_url" yaml:"redis_url"`               // Redis server holding the queue and results, e.g. "redis://localhost:6379/0"
+	RedisKeyPrefix string        `mapstructure:"redis_key_prefix" yaml:"redis_key_prefix"` // Prefix of the crawl's keys, so several crawls can share a server
+	HostLease      time.Duration `mapstructure:"host_lease" yaml:"host_lease"`             // How long a worker keeps a host to itself after claiming one of its URLs (0 = no leases)
+
+	// Control API
+	Serve      string `mapstructure:"serve" yaml:"serve"`           // Address the control API listens on, e.g. "127.0.0.1:8080" (empty = disabled)
+	ServeGRPC  string `mapstructure:"serve_grpc" yaml:"serve_grpc"` // Address the gRPC control API listens on, e.g. "127.0.0.1:9090" (empty = disabled)
+	ServeToken string `mapstructure:"serve_token" yaml:"-"`         // Bearer token both control APIs require (empty = no authentication); never written out
+
+	// Tracing
+	OTLPEndpoint string `mapstructure:"otlp_endpoint" yaml:"otlp_endpoint"` // OTLP/HTTP collector traces are exported to, e.g. "http://localhost:4318" (empty = disabled)
+	DebugAddr    string `mapstructure:"debug_addr" yaml:"debug_addr"`       // Address pprof and runtime stats are served on, e.g. "127.0.0.1:6060" (empty = disabled)
 
 	// Logging configuration
 	LogLevel      string `mapstructure:"log_level" yaml:"log_level"`             // Log level (debug, info, warn, error)
@@ -81,20 +285,41 @@ type CrawlConfig struct {
 	LogMaxSize    int    `mapstructure:"log_max_size" yaml:"log_max_size"`       // Max log file size in MB
 	LogMaxBackups int    `mapstructure:"log_max_backups" yaml:"log_max_backups"` // Number of old log files to keep
 	LogConsole    bool   `mapstructure:"log_console" yaml:"log_console"`         // Enable console output
+
+	headerSources map[string]string // Lower-cased header name -> source that set it (see MergeHeaders)
 }
 
 // DefaultConfig returns a configuration with default values
 func DefaultConfig() *CrawlConfig {
 	return &CrawlConfig{
-		Concurrency:         2,   // Reduced from 10 to 2
-		RequestDelay:        0.1, // 100ms in seconds // Reduced from 1s to 0.1s
-		RequestTimeout:      30 * time.Second,
-		UserAgent:           "LinkTadoru/1.0",
-		IgnoreRobotsTxt:     false,
-		FollowExternalHosts: false, // Default to same-host only for safety
-		Limit:               0,     // unlimited
-		DatabasePath:        "./linktadoru.db",
-		AllowedSchemes:      []string{"https://", "http://"}, // Default allowed URL schemes
+		Concurrency:           2,   // Reduced from 10 to 2
+		RequestDelay:          0.1, // 100ms in seconds // Reduced from 1s to 0.1s
+		RequestTimeout:        30 * time.Second,
+		UserAgent:             "LinkTadoru/1.0",
+		IgnoreRobotsTxt:       false,
+		FollowExternalHosts:   false, // Default to same-host only for safety
+		IncludeSubdomains:     false,
+		CheckExternal:         CheckExternalNone,
+		ExternalCheckers:      2,
+		TrailingSlash:         TrailingSlashKeep,
+		ShutdownTimeout:       10 * time.Second,
+		MaxRetries:            2,
+		RetryBackoff:          time.Second,
+		ScriptTimeout:         time.Second,
+		RenderTimeout:         30 * time.Second,
+		SeenURLCacheSize:      1000000,
+		QueueBatchSize:        1,
+		QueueOrder:            QueueOrderHost,
+		WriteWorkers:          1,
+		Limit:                 0, // unlimited
+		StorageDriver:         "sqlite",
+		RedisURL:              "redis://localhost:6379/0",
+		RedisKeyPrefix:        "linktadoru",
+		HostLease:             30 * time.Second,
+		DatabasePath:          "./linktadoru.db",
+		DatabaseEncryption:    false,
+		DatabasePassphraseEnv: "LT_DATABASE_PASSPHRASE",        // Passphrase is only read from the environment
+		AllowedSchemes:        []string{"https://", "http://"}, // Default allowed URL schemes
 		// Logging defaults
 		LogLevel:      "info",
 		LogFile:       "",  // Empty means no file logging by default
@@ -104,16 +329,33 @@ func DefaultConfig() *CrawlConfig {
 	}
 }
 
-// Validate checks if the configuration is valid
+// Validate checks if the configuration is valid, returning the first problem
 func (c *CrawlConfig) Validate() error {
+	if errs := c.Problems(); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// Problems checks the configuration and returns every problem found, in the
+// order Validate reports them
+func (c *CrawlConfig) Problems() []error {
+	var errs []error
+
 	// Note: SeedURLs are optional - crawler can resume from existing queue
 
 	if c.Concurrency <= 0 {
-		return ErrInvalidConcurrency
+		errs = append(errs, ErrInvalidConcurrency)
+	}
+
+	if c.MinConcurrency < 0 || c.MaxConcurrency < 0 ||
+		(c.MaxConcurrency > 0 && c.MinConcurrency > c.MaxConcurrency) ||
+		(c.MaxConcurrency == 0 && c.MinConcurrency > 0) {
+		errs = append(errs, fmt.Errorf("%w: min_concurrency=%d max_concurrency=%d", ErrInvalidConcurrencyRange, c.MinConcurrency, c.MaxConcurrency))
 	}
 
 	if c.RequestTimeout <= 0 {
-		return ErrInvalidTimeout
+		errs = append(errs, ErrInvalidTimeout)
 	}
 
 	// Enforce minimum delay of 100ms for proper queue coordination
@@ -121,21 +363,260 @@ func (c *CrawlConfig) Validate() error {
 		c.RequestDelay = 0.1 // 100ms in seconds
 	}
 
+	if c.RequestJitter < 0 || c.RequestJitter > 100 {
+		errs = append(errs, ErrInvalidRequestJitter)
+	}
+
+	if c.TimeoutTotal < 0 {
+		errs = append(errs, ErrInvalidTimeoutTotal)
+	}
+
+	if c.ShutdownTimeout < 0 {
+		errs = append(errs, ErrInvalidShutdownTimeout)
+	}
+
+	if c.MaxRetries < 0 {
+		errs = append(errs, ErrInvalidMaxRetries)
+	}
+
+	if c.RetryBackoff < 0 {
+		errs = append(errs, ErrInvalidRetryBackoff)
+	}
+
+	if c.StaleProcessingTimeout < 0 {
+		errs = append(errs, ErrInvalidStaleProcessingTimeout)
+	}
+
+	if c.HostLease < 0 {
+		errs = append(errs, ErrInvalidHostLease)
+	}
+
+	if c.Script != "" && c.ScriptTimeout <= 0 {
+		errs = append(errs, ErrInvalidScriptTimeout)
+	}
+
+	if c.RecordDir != "" && c.ReplayDir != "" {
+		errs = append(errs, ErrRecordAndReplay)
+	}
+
+	if (len(c.RenderPatterns) > 0 || c.RenderFallback) && c.RenderTimeout <= 0 {
+		errs = append(errs, ErrInvalidRenderTimeout)
+	}
+	if c.BrowserURL != "" && !strings.HasPrefix(c.BrowserURL, "ws://") && !strings.HasPrefix(c.BrowserURL, "wss://") {
+		errs = append(errs, ErrInvalidBrowserURL)
+	}
+
+	if c.MaxQueueSize < 0 {
+		errs = append(errs, ErrInvalidMaxQueueSize)
+	}
+
+	if c.MaxResponseSize < 0 {
+		errs = append(errs, ErrInvalidMaxResponseSize)
+	}
+
+	if c.SeenURLCacheSize < 0 {
+		errs = append(errs, ErrInvalidSeenURLCacheSize)
+	}
+
+	if c.QueueBatchSize < 0 {
+		errs = append(errs, ErrInvalidQueueBatchSize)
+	}
+
+	if c.WriteBufferSize < 0 {
+		errs = append(errs, ErrInvalidWriteBufferSize)
+	}
+
+	if c.WriteWorkers < 0 {
+		errs = append(errs, ErrInvalidWriteWorkers)
+	}
+
+	if c.PaginationDepth < 0 {
+		errs = append(errs, ErrInvalidPaginationDepth)
+	}
+
+	for _, limit := range []struct {
+		name  string
+		value int
+	}{
+		{"max_url_length", c.MaxURLLength},
+		{"max_path_segments", c.MaxPathSegments},
+		{"max_repeated_segments", c.MaxRepeatedSegments},
+		{"max_query_params", c.MaxQueryParams},
+		{"max_query_variants", c.MaxQueryVariants},
+	} {
+		if limit.value < 0 {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidTrapLimit, limit.name))
+		}
+	}
+
+	for _, budget := range []struct {
+		name  string
+		value int64
+	}{
+		{"page_weight_budget", c.PageWeightBudget},
+		{"script_weight_budget", c.ScriptWeightBudget},
+		{"stylesheet_weight_budget", c.StylesheetWeightBudget},
+		{"image_weight_budget", c.ImageWeightBudget},
+	} {
+		if budget.value < 0 {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidWeightBudget, budget.name))
+		}
+	}
+
+	switch c.CheckExternal {
+	case "", CheckExternalNone, CheckExternalHead:
+	default:
+		errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidCheckExternal, c.CheckExternal))
+	}
+	if c.ExternalCheckers < 0 {
+		errs = append(errs, ErrInvalidExternalCheckers)
+	}
+
+	switch c.QueueOrder {
+	case "", QueueOrderHost, QueueOrderFIFO:
+	default:
+		errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidQueueOrder, c.QueueOrder))
+	}
+
+	switch c.TrailingSlash {
+	case "", TrailingSlashKeep, TrailingSlashAdd, TrailingSlashRemove:
+	default:
+		errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidTrailingSlash, c.TrailingSlash))
+	}
+
+	if c.RunHeader != "" && !httpguts.ValidHeaderFieldName(c.RunHeader) {
+		errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidRunHeader, c.RunHeader))
+	}
+
+	if c.CrawlerInfoURL != "" {
+		if u, err := url.Parse(c.CrawlerInfoURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidCrawlerInfoURL, c.CrawlerInfoURL))
+		}
+	}
+
 	if c.DatabasePath == "" {
-		return ErrEmptyDatabasePath
+		errs = append(errs, ErrEmptyDatabasePath)
+	}
+
+	if c.ResultsDatabasePath != "" && filepath.Clean(c.ResultsDatabasePath) == filepath.Clean(c.DatabasePath) {
+		errs = append(errs, ErrSameResultsDatabasePath)
+	}
+
+	if c.DatabaseEncryption && c.GetDatabasePassphrase() == "" {
+		errs = append(errs, ErrMissingDatabasePassphrase)
+	}
+
+	if c.Serve != "" {
+		if _, _, err := net.SplitHostPort(c.Serve); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidServeAddress, c.Serve))
+		}
+	}
+
+	if c.ServeGRPC != "" {
+		if _, _, err := net.SplitHostPort(c.ServeGRPC); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidServeGRPCAddress, c.ServeGRPC))
+		}
+	}
+
+	if c.OTLPEndpoint != "" {
+		u, err := url.Parse(c.OTLPEndpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidOTLPEndpoint, c.OTLPEndpoint))
+		}
+	}
+
+	if c.DebugAddr != "" {
+		if _, _, err := net.SplitHostPort(c.DebugAddr); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidDebugAddress, c.DebugAddr))
+		}
 	}
 
 	// Validate authentication configuration
 	if err := c.validateAuth(); err != nil {
-		return err
+		errs = append(errs, err)
 	}
 
 	// Validate headers
 	if err := c.validateHeaders(); err != nil {
-		return err
+		errs = append(errs, err)
 	}
 
-	return nil
+	// Validate host lists
+	if err := c.validateHostLists(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Validate content-type filters
+	if err := c.validateContentTypes(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Validate TLS configuration
+	if err := c.validateTLS(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Validate DNS configuration
+	if err := c.validateDNS(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Validate include/exclude patterns
+	if err := c.validateURLPatterns(); err != nil {
+		errs = append(errs, err.(interface{ Unwrap() []error }).Unwrap()...)
+	}
+
+	// Validate redaction rules
+	if err := c.validateRedaction(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Validate webhooks
+	if err := c.validateWebhooks(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// GetDatabasePassphrase returns the database encryption passphrase from the
+// configured environment variable, or "" when encryption is disabled
+func (c *CrawlConfig) GetDatabasePassphrase() string {
+	if !c.DatabaseEncryption || c.DatabasePassphraseEnv == "" {
+		return ""
+	}
+	return os.Getenv(c.DatabasePassphraseEnv)
+}
+
+// UnsetEnvVars returns the settings naming an environment variable to read a
+// credential from whose variable is unset or empty, as "setting (VARIABLE)".
+// Only the names are returned, never the values.
+func (c *CrawlConfig) UnsetEnvVars() []string {
+	refs := [][2]string{}
+	if c.DatabaseEncryption {
+		refs = append(refs, [2]string{"database_passphrase_env", c.DatabasePassphraseEnv})
+	}
+	if c.Auth != nil && c.Auth.Basic != nil {
+		refs = append(refs,
+			[2]string{"auth.basic.username_env", c.Auth.Basic.UsernameEnv},
+			[2]string{"auth.basic.password_env", c.Auth.Basic.PasswordEnv})
+	}
+	if c.Auth != nil && c.Auth.Bearer != nil {
+		refs = append(refs, [2]string{"auth.bearer.token_env", c.Auth.Bearer.TokenEnv})
+	}
+	if c.Auth != nil && c.Auth.APIKey != nil {
+		refs = append(refs,
+			[2]string{"auth.apikey.header_env", c.Auth.APIKey.HeaderEnv},
+			[2]string{"auth.apikey.value_env", c.Auth.APIKey.ValueEnv})
+	}
+
+	var unset []string
+	for _, ref := range refs {
+		if key, name := ref[0], ref[1]; name != "" && os.Getenv(name) == "" {
+			unset = append(unset, fmt.Sprintf("%s (%s)", key, name))
+		}
+	}
+	return unset
 }
 
 // GetBasicAuthCredentials returns the basic auth username and password,
@@ -265,6 +746,8 @@ func (c *CrawlConfig) validateAuthTypeConfiguration() error {
 		return c.validateBearerAuth()
 	case APIKeyAuthType:
 		return c.validateAPIKeyAuth()
+	case NTLMAuthType, NegotiateAuthType:
+		return c.validateNTLMAuth()
 	default:
 		return fmt.Errorf("unsupported authentication type: %s", c.Auth.Type)
 	}
@@ -282,6 +765,18 @@ func (c *CrawlConfig) validateBasicAuth() error {
 	return nil
 }
 
+// validateNTLMAuth validates NTLM/Negotiate authentication configuration
+func (c *CrawlConfig) validateNTLMAuth() error {
+	if c.Auth.Basic == nil {
+		return fmt.Errorf("%s auth type specified but no basic credentials provided", c.Auth.Type)
+	}
+	username, password := c.GetBasicAuthCredentials()
+	if username == "" || password == "" {
+		return fmt.Errorf("%s auth requires both username and password", c.Auth.Type)
+	}
+	return nil
+}
+
 // validateBearerAuth validates bearer authentication configuration
 func (c *CrawlConfig) validateBearerAuth() error {
 	if c.Auth.Bearer == nil {
@@ -338,61 +833,191 @@ func (c *CrawlConfig) validateHeaders() error {
 	return nil
 }
 
-// LoadHeadersFromEnv loads headers from environment variables with LT_HEADER_ prefix
-// as specified in Issue #8: LT_HEADER_ACCEPT, LT_HEADER_X_CUSTOM, etc.
-func (c *CrawlConfig) LoadHeadersFromEnv() {
-	const headerPrefix = "LT_HEADER_"
+// validateTLS checks that TLS client certificate settings are complete
+func (c *CrawlConfig) validateTLS() error {
+	if (c.TLSClientCert == "") != (c.TLSClientKey == "") {
+		return fmt.Errorf("tls_client_cert and tls_client_key must be set together")
+	}
+	return nil
+}
 
-	// Get all environment variables
-	for _, env := range os.Environ() {
-		// Parse key=value
-		parts := strings.SplitN(env, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
+// validateDNS checks the DNS cache, resolver and override settings
+func (c *CrawlConfig) validateDNS() error {
+	if c.DNSCacheTTL < 0 {
+		return fmt.Errorf("dns_cache_ttl cannot be negative")
+	}
 
-		key, value := parts[0], parts[1]
+	if c.DNSResolver != "" {
+		if _, _, err := net.SplitHostPort(c.DNSResolver); err != nil {
+			return fmt.Errorf("invalid dns_resolver '%s': expected 'host:port'", c.DNSResolver)
+		}
+	}
 
-		// Check if it's a header environment variable
-		if !strings.HasPrefix(key, headerPrefix) {
-			continue
+	for host, ip := range c.DNSOverrides {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("invalid dns_overrides entry '%s': '%s' is not an IP address", host, ip)
 		}
+	}
 
-		// Extract header name (convert LT_HEADER_ACCEPT to Accept)
-		headerName := strings.TrimPrefix(key, headerPrefix)
-		if headerName == "" {
-			continue
+	return nil
+}
+
+// authHosts returns the configured auth.hosts entries
+func (c *CrawlConfig) authHosts() []string {
+	if c.Auth == nil {
+		return nil
+	}
+	return c.Auth.Hosts
+}
+
+// validateHostLists checks allowed_hosts, blocked_hosts and auth.hosts entries.
+// Entries are bare hostnames, optionally with a leading "*." wildcard for
+// subdomains.
+func (c *CrawlConfig) validateHostLists() error {
+	lists := []struct {
+		name  string
+		hosts []string
+	}{
+		{"allowed_hosts", c.AllowedHosts},
+		{"blocked_hosts", c.BlockedHosts},
+		{"auth.hosts", c.authHosts()},
+	}
+
+	for _, list := range lists {
+		for _, host := range list.hosts {
+			name := strings.TrimPrefix(host, "*.")
+			if name == "" || strings.ContainsAny(name, "*/:?# ") {
+				return fmt.Errorf("invalid %s entry '%s': expected a hostname like 'example.com' or '*.example.com'", list.name, host)
+			}
 		}
+	}
 
-		// Convert X_CUSTOM to X-Custom, ACCEPT to Accept
-		headerName = strings.ReplaceAll(headerName, "_", "-")
-		headerName = strings.ToLower(headerName)
+	return nil
+}
+
+// validateContentTypes checks allowed_content_types and blocked_content_types
+// entries are media types ("text/html") or wildcards ("image/*", "*/*")
+func (c *CrawlConfig) validateContentTypes() error {
+	lists := []struct {
+		name  string
+		types []string
+	}{
+		{"allowed_content_types", c.AllowedContentTypes},
+		{"blocked_content_types", c.BlockedContentTypes},
+	}
 
-		// Capitalize first letter and letters after hyphens
-		headerParts := strings.Split(headerName, "-")
-		for i, part := range headerParts {
-			if len(part) > 0 {
-				headerParts[i] = strings.ToUpper(string(part[0])) + part[1:]
+	for _, list := range lists {
+		for _, mediaType := range list.types {
+			trimmed := strings.TrimSpace(mediaType)
+			major, minor, ok := strings.Cut(trimmed, "/")
+			if !ok || major == "" || minor == "" || strings.ContainsAny(trimmed, "; ") || (major == "*" && minor != "*") {
+				return fmt.Errorf("invalid %s entry '%s': expected a media type like 'text/html' or 'image/*'", list.name, mediaType)
 			}
 		}
-		headerName = strings.Join(headerParts, "-")
-
-		// Add to headers list in "Name: Value" format
-		headerEntry := fmt.Sprintf("%s: %s", headerName, value)
-
-		// Check if header already exists and replace it
-		found := false
-		for i, existing := range c.Headers {
-			if strings.HasPrefix(existing, headerName+":") {
-				c.Headers[i] = headerEntry
-				found = true
-				break
+	}
+
+	return nil
+}
+
+// UsesCustomDNS reports whether any DNS cache, resolver or override is configured
+func (c *CrawlConfig) UsesCustomDNS() bool {
+	return c.DNSCacheTTL > 0 || c.DNSResolver != "" || len(c.DNSOverrides) > 0
+}
+
+// DelayBounds returns the shortest and longest delay between requests to one
+// host once request_jitter is applied
+func (c *CrawlConfig) DelayBounds() (lowest, highest time.Duration) {
+	delay := time.Duration(c.RequestDelay * float64(time.Second))
+	spread := time.Duration(float64(delay) * c.RequestJitter / 100)
+	return delay - spread, delay + spread
+}
+
+// UserAgentWithInfo returns the User-Agent with crawler_info_url appended as a
+// "(+URL)" comment, the convention site operators look for to find out who
+// runs a bot, e.g. "LinkTadoru/1.2 (+https://example.com/bot)". The
+// User-Agent is returned unchanged when no URL is set or it already names it.
+func (c *CrawlConfig) UserAgentWithInfo() string {
+	if c.CrawlerInfoURL == "" || strings.Contains(c.UserAgent, c.CrawlerInfoURL) {
+		return c.UserAgent
+	}
+	return fmt.Sprintf("%s (+%s)", c.UserAgent, c.CrawlerInfoURL)
+}
+
+// ChecksExternalLinks reports whether out-of-scope links are verified with HEAD requests
+func (c *CrawlConfig) ChecksExternalLinks() bool {
+	return c.CheckExternal == CheckExternalHead
+}
+
+// validateURLPatterns checks that all include, exclude and render patterns
+// compile, joining the errors of every pattern that does not
+func (c *CrawlConfig) validateURLPatterns() error {
+	var errs []error
+	for _, set := range []struct {
+		name     string
+		patterns []string
+	}{
+		{"include", c.IncludePatterns},
+		{"exclude", c.ExcludePatterns},
+		{"render", c.RenderPatterns},
+	} {
+		for _, pattern := range set.patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				errs = append(errs, fmt.Errorf("%w: %s pattern '%s': %w", ErrInvalidURLPattern, set.name, pattern, err))
 			}
 		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateRedaction checks that all redaction patterns compile
+func (c *CrawlConfig) validateRedaction() error {
+	if c.Redaction == nil {
+		return nil
+	}
+
+	for _, pattern := range c.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid redaction pattern '%s': %w", pattern, err)
+		}
+	}
 
-		// If not found, append
-		if !found {
-			c.Headers = append(c.Headers, headerEntry)
+	for _, param := range c.Redaction.QueryParams {
+		if strings.TrimSpace(param) == "" {
+			return fmt.Errorf("redaction query parameter name cannot be empty")
 		}
 	}
+
+	return nil
+}
+
+// validateWebhooks checks the webhook URLs, events, thresholds, patterns and
+// headers
+func (c *CrawlConfig) validateWebhooks() error {
+	for _, hook := range c.Webhooks {
+		u, err := url.Parse(hook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: %q", ErrInvalidWebhookURL, hook.URL)
+		}
+		for _, event := range hook.Events {
+			switch event {
+			case WebhookCrawlStarted, WebhookCrawlFinished, WebhookErrorRate, WebhookURLFailed:
+			default:
+				return fmt.Errorf("%w: %q", ErrInvalidWebhookEvent, event)
+			}
+		}
+		if hook.ErrorRate < 0 || hook.ErrorRate > 100 {
+			return fmt.Errorf("%w: %g", ErrInvalidWebhookErrorRate, hook.ErrorRate)
+		}
+		for _, pattern := range hook.URLPatterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid webhook url pattern '%s': %w", pattern, err)
+			}
+		}
+		for _, header := range hook.Headers {
+			if name, _, ok := strings.Cut(header, ":"); !ok || !httpguts.ValidHeaderFieldName(strings.TrimSpace(name)) {
+				return fmt.Errorf("invalid webhook header '%s': expected 'Name: Value'", header)
+			}
+		}
+	}
+	return nil
 }
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"errors"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
@@ -280,6 +282,37 @@ func TestAuthValidation(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid ntlm auth",
+			config: &CrawlConfig{
+				Concurrency:    2,
+				RequestDelay:   0.1,
+				RequestTimeout: 30 * time.Second,
+				DatabasePath:   "./test.db",
+				Auth: &Auth{
+					Type: NTLMAuthType,
+					Basic: &BasicAuth{
+						Username: `CORP\user`,
+						Password: "pass",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "negotiate auth without credentials",
+			config: &CrawlConfig{
+				Concurrency:    2,
+				RequestDelay:   0.1,
+				RequestTimeout: 30 * time.Second,
+				DatabasePath:   "./test.db",
+				Auth: &Auth{
+					Type: NegotiateAuthType,
+				},
+			},
+			wantErr: true,
+			errMsg:  "negotiate auth type specified but no basic credentials provided",
+		},
 		{
 			name: "valid bearer auth",
 			config: &CrawlConfig{
@@ -512,6 +545,29 @@ func TestLoadHeadersFromEnv(t *testing.T) {
 			},
 			expected: []string{"X-Api-Key: secret123"},
 		},
+		{
+			name: "explicit mapping keeps casing and underscores",
+			envVars: map[string]string{
+				"LT_HEADER_1": "X-API-Key: secret123",
+				"LT_HEADER_2": "X_Tenant_ID: 42",
+			},
+			expected: []string{"X-API-Key: secret123", "X_Tenant_ID: 42"},
+		},
+		{
+			name: "explicit mapping wins over derived name",
+			envVars: map[string]string{
+				"LT_HEADER_ACCEPT": "text/html",
+				"LT_HEADER_1":      "accept: application/json",
+			},
+			expected: []string{"accept: application/json"},
+		},
+		{
+			name: "empty value is treated as unset",
+			envVars: map[string]string{
+				"LT_HEADER_ACCEPT": "",
+			},
+			expected: []string{},
+		},
 		{
 			name: "non-header env vars ignored",
 			envVars: map[string]string{
@@ -539,7 +595,9 @@ func TestLoadHeadersFromEnv(t *testing.T) {
 			}
 
 			cfg := DefaultConfig()
-			cfg.LoadHeadersFromEnv()
+			if _, err := cfg.LoadHeadersFromEnv(); err != nil {
+				t.Fatalf("LoadHeadersFromEnv failed: %v", err)
+			}
 
 			// Check if we got the expected headers
 			if len(cfg.Headers) != len(tt.expected) {
@@ -573,3 +631,687 @@ func TestLoadHeadersFromEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestHeadersFromEnvRejectsMalformedMapping(t *testing.T) {
+	_, err := HeadersFromEnv([]string{"LT_HEADER_1=X-API-Key secret"})
+	if err == nil || !strings.Contains(err.Error(), "LT_HEADER_1") {
+		t.Errorf("Expected error naming LT_HEADER_1, got %v", err)
+	}
+}
+
+func TestHeadersFromEnvOrdersNumberedEntries(t *testing.T) {
+	headers, err := HeadersFromEnv([]string{"LT_HEADER_10=X-B: 2", "LT_HEADER_2=X-A: 1"})
+	if err != nil {
+		t.Fatalf("HeadersFromEnv failed: %v", err)
+	}
+	if strings.Join(headers, ",") != "X-A: 1,X-B: 2" {
+		t.Errorf("Expected numeric order, got %v", headers)
+	}
+}
+
+func TestMergeHeaders(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Headers = []string{"Accept: text/html", "X-Team: web"}
+
+	conflicts := cfg.MergeHeaders(HeaderSourceEnv, []string{"accept: application/json", "X-Env: staging"})
+	if len(conflicts) != 1 || conflicts[0].Source != HeaderSourceEnv || conflicts[0].Overridden != HeaderSourceConfig {
+		t.Errorf("Unexpected conflicts: %+v", conflicts)
+	}
+
+	// Same value from a higher source is not a conflict
+	conflicts = cfg.MergeHeaders(HeaderSourceFlag, []string{"X-Team: web", "X-Env: prod"})
+	if len(conflicts) != 1 || conflicts[0].Name != "X-Env" || conflicts[0].Overridden != HeaderSourceEnv {
+		t.Errorf("Unexpected conflicts: %+v", conflicts)
+	}
+
+	expected := []string{"accept: application/json", "X-Team: web", "X-Env: prod"}
+	if strings.Join(cfg.Headers, ",") != strings.Join(expected, ",") {
+		t.Errorf("Headers = %v, expected %v", cfg.Headers, expected)
+	}
+	if strings.Contains(conflicts[0].String(), "prod") {
+		t.Errorf("Conflict description must not reveal values: %s", conflicts[0])
+	}
+}
+
+func TestValidateRedaction(t *testing.T) {
+	tests := []struct {
+		name      string
+		redaction *Redaction
+		wantErr   bool
+	}{
+		{
+			name:      "no redaction",
+			redaction: nil,
+			wantErr:   false,
+		},
+		{
+			name: "valid patterns and params",
+			redaction: &Redaction{
+				Patterns:    []string{`[\w.+-]+@[\w-]+\.[\w.]+`},
+				QueryParams: []string{"sessionid", "token"},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "invalid pattern",
+			redaction: &Redaction{Patterns: []string{"[unclosed"}},
+			wantErr:   true,
+		},
+		{
+			name:      "empty query param",
+			redaction: &Redaction{QueryParams: []string{" "}},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Redaction = tt.redaction
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDatabaseEncryptionValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DatabaseEncryption = true
+	cfg.DatabasePassphraseEnv = "LT_TEST_DATABASE_PASSPHRASE"
+
+	t.Setenv("LT_TEST_DATABASE_PASSPHRASE", "")
+	if err := cfg.Validate(); err != ErrMissingDatabasePassphrase {
+		t.Errorf("Expected ErrMissingDatabasePassphrase, got %v", err)
+	}
+
+	t.Setenv("LT_TEST_DATABASE_PASSPHRASE", "secret")
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+	if got := cfg.GetDatabasePassphrase(); got != "secret" {
+		t.Errorf("GetDatabasePassphrase() = %q, want %q", got, "secret")
+	}
+
+	cfg.DatabaseEncryption = false
+	if got := cfg.GetDatabasePassphrase(); got != "" {
+		t.Errorf("Expected empty passphrase when encryption disabled, got %q", got)
+	}
+}
+
+func TestValidateTLS(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TLSClientCert = "client.crt"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error when tls_client_key is missing")
+	}
+
+	cfg.TLSClientKey = "client.key"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+}
+
+func TestValidateDNS(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*CrawlConfig)
+		wantErr bool
+	}{
+		{"defaults", func(c *CrawlConfig) {}, false},
+		{"valid settings", func(c *CrawlConfig) {
+			c.DNSCacheTTL = 5 * time.Minute
+			c.DNSResolver = "1.1.1.1:53"
+			c.DNSOverrides = map[string]string{"staging.example.com": "10.0.0.5"}
+		}, false},
+		{"negative ttl", func(c *CrawlConfig) { c.DNSCacheTTL = -time.Second }, true},
+		{"resolver without port", func(c *CrawlConfig) { c.DNSResolver = "1.1.1.1" }, true},
+		{"override not an IP", func(c *CrawlConfig) { c.DNSOverrides = map[string]string{"a.example": "b.example"} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(cfg)
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateHostLists(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*CrawlConfig)
+		wantErr bool
+	}{
+		{"defaults", func(c *CrawlConfig) {}, false},
+		{"valid lists", func(c *CrawlConfig) {
+			c.AllowedHosts = []string{"example.com", "*.example.com"}
+			c.BlockedHosts = []string{"ads.example.com"}
+		}, false},
+		{"empty entry", func(c *CrawlConfig) { c.AllowedHosts = []string{""} }, true},
+		{"bare wildcard", func(c *CrawlConfig) { c.BlockedHosts = []string{"*."} }, true},
+		{"wildcard in middle", func(c *CrawlConfig) { c.AllowedHosts = []string{"www.*.example.com"} }, true},
+		{"url instead of host", func(c *CrawlConfig) { c.AllowedHosts = []string{"https://example.com"} }, true},
+		{"host with path", func(c *CrawlConfig) { c.BlockedHosts = []string{"example.com/admin"} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(cfg)
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMaxQueueSize(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxQueueSize = 1000
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid max_queue_size, got %v", err)
+	}
+
+	cfg.MaxQueueSize = -1
+	if err := cfg.Validate(); err != ErrInvalidMaxQueueSize {
+		t.Errorf("Expected ErrInvalidMaxQueueSize, got %v", err)
+	}
+}
+
+func TestValidateMaxResponseSize(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxResponseSize = 10 << 20
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid max_response_size, got %v", err)
+	}
+
+	cfg.MaxResponseSize = -1
+	if err := cfg.Validate(); err != ErrInvalidMaxResponseSize {
+		t.Errorf("Expected ErrInvalidMaxResponseSize, got %v", err)
+	}
+}
+
+func TestValidateContentTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*CrawlConfig)
+		wantErr bool
+	}{
+		{"defaults", func(c *CrawlConfig) {}, false},
+		{"valid lists", func(c *CrawlConfig) {
+			c.AllowedContentTypes = []string{"text/html", " application/xhtml+xml"}
+			c.BlockedContentTypes = []string{"image/*", "*/*"}
+		}, false},
+		{"missing subtype", func(c *CrawlConfig) { c.AllowedContentTypes = []string{"text"} }, true},
+		{"parameters", func(c *CrawlConfig) { c.AllowedContentTypes = []string{"text/html; charset=utf-8"} }, true},
+		{"wildcard major only", func(c *CrawlConfig) { c.BlockedContentTypes = []string{"*/html"} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(cfg)
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTimeoutTotal(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TimeoutTotal = 2 * time.Hour
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid timeout_total, got %v", err)
+	}
+
+	cfg.TimeoutTotal = -time.Minute
+	if err := cfg.Validate(); err != ErrInvalidTimeoutTotal {
+		t.Errorf("Expected ErrInvalidTimeoutTotal, got %v", err)
+	}
+
+	cfg = DefaultConfig()
+	cfg.ShutdownTimeout = -time.Second
+	if err := cfg.Validate(); err != ErrInvalidShutdownTimeout {
+		t.Errorf("Expected ErrInvalidShutdownTimeout, got %v", err)
+	}
+
+	cfg = DefaultConfig()
+	cfg.StaleProcessingTimeout = -time.Second
+	if err := cfg.Validate(); err != ErrInvalidStaleProcessingTimeout {
+		t.Errorf("Expected ErrInvalidStaleProcessingTimeout, got %v", err)
+	}
+}
+
+func TestValidateRetries(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 0
+	cfg.RetryBackoff = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected retries to be disabled without error, got %v", err)
+	}
+
+	cfg.MaxRetries = -1
+	if err := cfg.Validate(); err != ErrInvalidMaxRetries {
+		t.Errorf("Expected ErrInvalidMaxRetries, got %v", err)
+	}
+
+	cfg = DefaultConfig()
+	cfg.RetryBackoff = -time.Second
+	if err := cfg.Validate(); err != ErrInvalidRetryBackoff {
+		t.Errorf("Expected ErrInvalidRetryBackoff, got %v", err)
+	}
+}
+
+func TestValidateCheckExternal(t *testing.T) {
+	for _, mode := range []string{"", CheckExternalNone, CheckExternalHead} {
+		cfg := DefaultConfig()
+		cfg.CheckExternal = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected check_external %q to be valid, got %v", mode, err)
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.CheckExternal = "get"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidCheckExternal) {
+		t.Errorf("Expected ErrInvalidCheckExternal, got %v", err)
+	}
+
+	cfg.CheckExternal = CheckExternalHead
+	cfg.ExternalCheckers = -1
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidExternalCheckers) {
+		t.Errorf("Expected ErrInvalidExternalCheckers, got %v", err)
+	}
+}
+
+func TestValidateTrailingSlash(t *testing.T) {
+	for _, mode := range []string{"", TrailingSlashKeep, TrailingSlashAdd, TrailingSlashRemove} {
+		cfg := DefaultConfig()
+		cfg.TrailingSlash = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected trailing_slash %q to be valid, got %v", mode, err)
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.TrailingSlash = "strip"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidTrailingSlash) {
+		t.Errorf("Expected ErrInvalidTrailingSlash, got %v", err)
+	}
+}
+
+func TestValidateConcurrencyRange(t *testing.T) {
+	valid := [][2]int{{0, 0}, {0, 16}, {2, 16}, {16, 16}}
+	for _, r := range valid {
+		cfg := DefaultConfig()
+		cfg.MinConcurrency, cfg.MaxConcurrency = r[0], r[1]
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected min/max %v to be valid, got %v", r, err)
+		}
+	}
+
+	invalid := [][2]int{{-1, 4}, {0, -1}, {8, 4}, {2, 0}}
+	for _, r := range invalid {
+		cfg := DefaultConfig()
+		cfg.MinConcurrency, cfg.MaxConcurrency = r[0], r[1]
+		if err := cfg.Validate(); !errors.Is(err, ErrInvalidConcurrencyRange) {
+			t.Errorf("Expected ErrInvalidConcurrencyRange for min/max %v, got %v", r, err)
+		}
+	}
+}
+
+func TestValidateWriteBuffer(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.WriteBufferSize = 256
+	cfg.WriteWorkers = 2
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid write buffer settings, got %v", err)
+	}
+
+	cfg.WriteBufferSize = -1
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidWriteBufferSize) {
+		t.Errorf("Expected ErrInvalidWriteBufferSize, got %v", err)
+	}
+
+	cfg.WriteBufferSize = 0
+	cfg.WriteWorkers = -1
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidWriteWorkers) {
+		t.Errorf("Expected ErrInvalidWriteWorkers, got %v", err)
+	}
+}
+
+func TestValidatePaginationDepth(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PaginationDepth = 5
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid pagination_depth, got %v", err)
+	}
+
+	cfg.PaginationDepth = -1
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidPaginationDepth) {
+		t.Errorf("Expected ErrInvalidPaginationDepth, got %v", err)
+	}
+}
+
+func TestValidateQueueOrder(t *testing.T) {
+	for _, mode := range []string{"", QueueOrderHost, QueueOrderFIFO} {
+		cfg := DefaultConfig()
+		cfg.QueueOrder = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected queue_order %q to be valid, got %v", mode, err)
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.QueueOrder = "random"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidQueueOrder) {
+		t.Errorf("Expected ErrInvalidQueueOrder, got %v", err)
+	}
+}
+
+func TestValidateURLPatterns(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.IncludePatterns = []string{"/blog/"}
+	cfg.ExcludePatterns = []string{`\.pdf$`}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid patterns, got %v", err)
+	}
+
+	cfg.ExcludePatterns = []string{"/admin/(.*"}
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidURLPattern) || !strings.Contains(err.Error(), "/admin/(.*") {
+		t.Errorf("Expected ErrInvalidURLPattern naming the pattern, got %v", err)
+	}
+}
+
+func TestValidateTrapLimits(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxURLLength, cfg.MaxQueryVariants = 2048, 500
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected positive trap limits to be valid, got %v", err)
+	}
+
+	cfg.MaxRepeatedSegments = -1
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidTrapLimit) || !strings.Contains(err.Error(), "max_repeated_segments") {
+		t.Errorf("Expected ErrInvalidTrapLimit naming max_repeated_segments, got %v", err)
+	}
+}
+
+func TestValidateWeightBudgets(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PageWeightBudget, cfg.ImageWeightBudget = 2_000_000, 1_000_000
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected positive budgets to be valid, got %v", err)
+	}
+
+	cfg.ScriptWeightBudget = -1
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidWeightBudget) || !strings.Contains(err.Error(), "script_weight_budget") {
+		t.Errorf("Expected ErrInvalidWeightBudget naming script_weight_budget, got %v", err)
+	}
+}
+
+func TestValidateRequestJitter(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RequestDelay = 2
+	cfg.RequestJitter = 25
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected jitter of 25%% to be valid, got %v", err)
+	}
+	lowest, highest := cfg.DelayBounds()
+	if lowest != 1500*time.Millisecond || highest != 2500*time.Millisecond {
+		t.Errorf("Expected bounds 1.5s-2.5s, got %v-%v", lowest, highest)
+	}
+
+	for _, jitter := range []float64{-1, 101} {
+		cfg.RequestJitter = jitter
+		if err := cfg.Validate(); err != ErrInvalidRequestJitter {
+			t.Errorf("Expected ErrInvalidRequestJitter for %v, got %v", jitter, err)
+		}
+	}
+}
+
+func TestValidateRunHeader(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RunHeader = "X-LinkTadoru-Run"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected run_header to be valid, got %v", err)
+	}
+
+	cfg.RunHeader = "X Run: id"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidRunHeader) {
+		t.Errorf("Expected ErrInvalidRunHeader, got %v", err)
+	}
+}
+
+func TestCrawlerInfoURL(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.UserAgent = "LinkTadoru/1.2"
+	if got := cfg.UserAgentWithInfo(); got != "LinkTadoru/1.2" {
+		t.Errorf("Expected the User-Agent unchanged without crawler_info_url, got %q", got)
+	}
+
+	cfg.CrawlerInfoURL = "https://example.com/bot"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected crawler_info_url to be valid, got %v", err)
+	}
+	want := "LinkTadoru/1.2 (+https://example.com/bot)"
+	if got := cfg.UserAgentWithInfo(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	// Applying it twice does not repeat the comment
+	cfg.UserAgent = want
+	if got := cfg.UserAgentWithInfo(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	for _, invalid := range []string{"example.com/bot", "ftp://example.com/bot", "https://"} {
+		cfg.CrawlerInfoURL = invalid
+		if err := cfg.Validate(); !errors.Is(err, ErrInvalidCrawlerInfoURL) {
+			t.Errorf("Expected ErrInvalidCrawlerInfoURL for %q, got %v", invalid, err)
+		}
+	}
+}
+
+func TestValidateResultsDatabasePath(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ResultsDatabasePath = "./linktadoru-results.db"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected results_database_path to be valid, got %v", err)
+	}
+
+	cfg.ResultsDatabasePath = "linktadoru.db"
+	if err := cfg.Validate(); !errors.Is(err, ErrSameResultsDatabasePath) {
+		t.Errorf("Expected ErrSameResultsDatabasePath, got %v", err)
+	}
+}
+
+func TestValidateServe(t *testing.T) {
+	for _, addr := range []string{"", ":8080", "127.0.0.1:8080", "[::1]:0"} {
+		cfg := DefaultConfig()
+		cfg.Serve = addr
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected serve %q to be valid, got %v", addr, err)
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.Serve = "8080"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidServeAddress) {
+		t.Errorf("Expected ErrInvalidServeAddress, got %v", err)
+	}
+
+	cfg = DefaultConfig()
+	cfg.ServeGRPC = "localhost"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidServeGRPCAddress) {
+		t.Errorf("Expected ErrInvalidServeGRPCAddress, got %v", err)
+	}
+}
+
+func TestValidateDebugAddr(t *testing.T) {
+	for _, addr := range []string{"", ":6060", "127.0.0.1:6060"} {
+		cfg := DefaultConfig()
+		cfg.DebugAddr = addr
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected debug_addr %q to be valid, got %v", addr, err)
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.DebugAddr = "localhost"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidDebugAddress) {
+		t.Errorf("Expected ErrInvalidDebugAddress, got %v", err)
+	}
+}
+
+func TestValidateOTLPEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"", "http://localhost:4318", "https://collector.example.com/v1/traces"} {
+		cfg := DefaultConfig()
+		cfg.OTLPEndpoint = endpoint
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected otlp_endpoint %q to be valid, got %v", endpoint, err)
+		}
+	}
+
+	for _, endpoint := range []string{"localhost:4318", "grpc://localhost:4317", "http://"} {
+		cfg := DefaultConfig()
+		cfg.OTLPEndpoint = endpoint
+		if err := cfg.Validate(); !errors.Is(err, ErrInvalidOTLPEndpoint) {
+			t.Errorf("Expected ErrInvalidOTLPEndpoint for %q, got %v", endpoint, err)
+		}
+	}
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	valid := Webhook{
+		URL:         "https://hooks.example.com/T000/B000",
+		Events:      []string{WebhookCrawlFinished, WebhookURLFailed},
+		ErrorRate:   5,
+		URLPatterns: []string{"^https://example\\.com/checkout"},
+		Headers:     []string{"Authorization: Bearer token"},
+	}
+	cfg := DefaultConfig()
+	cfg.Webhooks = []Webhook{valid}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected webhook to be valid, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Webhook)
+		want   error
+	}{
+		{"relative url", func(w *Webhook) { w.URL = "hooks.example.com" }, ErrInvalidWebhookURL},
+		{"unknown event", func(w *Webhook) { w.Events = []string{"page_crawled"} }, ErrInvalidWebhookEvent},
+		{"error rate above 100", func(w *Webhook) { w.ErrorRate = 150 }, ErrInvalidWebhookErrorRate},
+		{"invalid pattern", func(w *Webhook) { w.URLPatterns = []string{"("} }, nil},
+		{"invalid header", func(w *Webhook) { w.Headers = []string{"Authorization"} }, nil},
+	}
+	for _, tt := range tests {
+		hook := valid
+		tt.modify(&hook)
+		cfg := DefaultConfig()
+		cfg.Webhooks = []Webhook{hook}
+		err := cfg.Validate()
+		if err == nil || (tt.want != nil && !errors.Is(err, tt.want)) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestURLFilterComposition(t *testing.T) {
+	var calls []string
+	filter := func(name string, accept bool) URLFilter {
+		return func(*url.URL) bool {
+			calls = append(calls, name)
+			return accept
+		}
+	}
+	u, _ := url.Parse("https://example.com/docs/")
+
+	tests := []struct {
+		name      string
+		filter    URLFilter
+		wantOK    bool
+		wantCalls string
+	}{
+		{"all accept", AllURLFilters(filter("a", true), filter("b", true)), true, "a,b"},
+		{"all stops at first rejection", AllURLFilters(filter("a", false), filter("b", true)), false, "a"},
+		{"all of none", AllURLFilters(), true, ""},
+		{"any stops at first acceptance", AnyURLFilter(filter("a", true), filter("b", false)), true, "a"},
+		{"any rejects", AnyURLFilter(filter("a", false), filter("b", false)), false, "a,b"},
+		{"any of none", AnyURLFilter(), false, ""},
+		{"not", NotURLFilter(filter("a", true)), false, "a"},
+		{"nested", AllURLFilters(filter("a", true), AnyURLFilter(filter("b", false), filter("c", true))), true, "a,b,c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			if got := tt.filter(u); got != tt.wantOK {
+				t.Errorf("Expected %v, got %v", tt.wantOK, got)
+			}
+			if got := strings.Join(calls, ","); got != tt.wantCalls {
+				t.Errorf("Expected calls %q, got %q", tt.wantCalls, got)
+			}
+		})
+	}
+}
+
+func TestValidateRecordAndReplay(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RecordDir = "fixtures"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected record_dir alone to be valid, got %v", err)
+	}
+	cfg.ReplayDir = "fixtures"
+	if err := cfg.Validate(); !errors.Is(err, ErrRecordAndReplay) {
+		t.Errorf("Expected ErrRecordAndReplay, got %v", err)
+	}
+}
+
+func TestProblems(t *testing.T) {
+	if problems := DefaultConfig().Problems(); len(problems) != 0 {
+		t.Errorf("Expected the default configuration to have no problems, got %v", problems)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Concurrency = 0
+	cfg.QueueOrder = "random"
+	cfg.IncludePatterns = []string{"(", "^/blog/"}
+	cfg.ExcludePatterns = []string{"["}
+	problems := cfg.Problems()
+	if len(problems) != 4 {
+		t.Fatalf("Expected 4 problems, got %v", problems)
+	}
+	for i, want := range []error{ErrInvalidConcurrency, ErrInvalidQueueOrder, ErrInvalidURLPattern, ErrInvalidURLPattern} {
+		if !errors.Is(problems[i], want) {
+			t.Errorf("Problem %d = %v, want %v", i, problems[i], want)
+		}
+	}
+	if !strings.Contains(problems[3].Error(), "exclude pattern '['") {
+		t.Errorf("Expected the exclude pattern to be named, got %v", problems[3])
+	}
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidConcurrency) {
+		t.Errorf("Expected Validate to return the first problem, got %v", err)
+	}
+}
+
+func TestUnsetEnvVars(t *testing.T) {
+	t.Setenv("LT_TEST_USER", "crawler")
+	t.Setenv("LT_TEST_PASSWORD", "")
+	cfg := DefaultConfig()
+	cfg.Auth = &Auth{Type: BasicAuthType, Basic: &BasicAuth{UsernameEnv: "LT_TEST_USER", PasswordEnv: "LT_TEST_PASSWORD"}}
+	cfg.DatabaseEncryption = true
+	cfg.DatabasePassphraseEnv = "LT_TEST_PASSPHRASE"
+
+	unset := cfg.UnsetEnvVars()
+	want := []string{"database_passphrase_env (LT_TEST_PASSPHRASE)", "auth.basic.password_env (LT_TEST_PASSWORD)"}
+	if strings.Join(unset, "\n") != strings.Join(want, "\n") {
+		t.Errorf("UnsetEnvVars() = %v, want %v", unset, want)
+	}
+}
@@ -5,8 +5,82 @@ import "errors"
 var (
 	// ErrInvalidConcurrency is returned when concurrency is not greater than 0
 	ErrInvalidConcurrency = errors.New("concurrency must be greater than 0")
+	// ErrInvalidConcurrencyRange is returned when min_concurrency and max_concurrency do not form a valid range
+	ErrInvalidConcurrencyRange = errors.New("min_concurrency must be between 0 and max_concurrency, which enables autoscaling")
 	// ErrInvalidTimeout is returned when request timeout is not greater than 0
 	ErrInvalidTimeout = errors.New("request_timeout must be greater than 0")
+	// ErrInvalidRequestJitter is returned when request_jitter is outside 0-100
+	ErrInvalidRequestJitter = errors.New("request_jitter must be between 0 and 100 percent")
+	// ErrInvalidTimeoutTotal is returned when timeout_total is negative
+	ErrInvalidTimeoutTotal = errors.New("timeout_total cannot be negative")
+	// ErrInvalidShutdownTimeout is returned when shutdown_timeout is negative
+	ErrInvalidShutdownTimeout = errors.New("shutdown_timeout cannot be negative")
+	// ErrInvalidMaxRetries is returned when max_retries is negative
+	ErrInvalidMaxRetries = errors.New("max_retries cannot be negative")
+	// ErrInvalidRetryBackoff is returned when retry_backoff is negative
+	ErrInvalidRetryBackoff = errors.New("retry_backoff cannot be negative")
+	// ErrInvalidStaleProcessingTimeout is returned when stale_processing_timeout is negative
+	ErrInvalidStaleProcessingTimeout = errors.New("stale_processing_timeout cannot be negative")
+	// ErrInvalidHostLease is returned when host_lease is negative
+	ErrInvalidHostLease = errors.New("host_lease cannot be negative")
+	// ErrInvalidScriptTimeout is returned when a script is set and script_timeout is not greater than 0
+	ErrInvalidScriptTimeout = errors.New("script_timeout must be greater than 0")
+	// ErrRecordAndReplay is returned when both record_dir and replay_dir are set
+	ErrRecordAndReplay = errors.New("record_dir and replay_dir cannot both be set")
+	// ErrInvalidRenderTimeout is returned when rendering is enabled and render_timeout is not greater than 0
+	ErrInvalidRenderTimeout = errors.New("render_timeout must be greater than 0")
+	// ErrInvalidBrowserURL is returned when browser_url is not a ws:// or wss:// URL
+	ErrInvalidBrowserURL = errors.New("browser_url must be a ws:// or wss:// DevTools URL")
+	// ErrInvalidMaxQueueSize is returned when max_queue_size is negative
+	ErrInvalidMaxQueueSize = errors.New("max_queue_size cannot be negative")
+	// ErrInvalidMaxResponseSize is returned when max_response_size is negative
+	ErrInvalidMaxResponseSize = errors.New("max_response_size cannot be negative")
+	// ErrInvalidURLPattern is returned when an include or exclude pattern is not a valid regular expression
+	ErrInvalidURLPattern = errors.New("invalid URL pattern")
+	// ErrInvalidSeenURLCacheSize is returned when seen_url_cache_size is negative
+	ErrInvalidSeenURLCacheSize = errors.New("seen_url_cache_size cannot be negative")
+	// ErrInvalidQueueBatchSize is returned when queue_batch_size is negative
+	ErrInvalidQueueBatchSize = errors.New("queue_batch_size cannot be negative")
+	// ErrInvalidWriteBufferSize is returned when write_buffer_size is negative
+	ErrInvalidWriteBufferSize = errors.New("write_buffer_size cannot be negative")
+	// ErrInvalidWriteWorkers is returned when write_workers is negative
+	ErrInvalidWriteWorkers = errors.New("write_workers cannot be negative")
+	// ErrInvalidPaginationDepth is returned when pagination_depth is negative
+	ErrInvalidPaginationDepth = errors.New("pagination_depth cannot be negative")
+	// ErrInvalidQueueOrder is returned when queue_order is not a known mode
+	ErrInvalidQueueOrder = errors.New("queue_order must be 'host' or 'fifo'")
+	// ErrInvalidTrapLimit is returned when a spider-trap limit is negative
+	ErrInvalidTrapLimit = errors.New("spider-trap limits cannot be negative")
+	// ErrInvalidWeightBudget is returned when a page weight budget is negative
+	ErrInvalidWeightBudget = errors.New("page weight budgets cannot be negative")
+	// ErrInvalidCheckExternal is returned when check_external is not a known mode
+	ErrInvalidCheckExternal = errors.New("check_external must be 'none' or 'head'")
+	// ErrInvalidExternalCheckers is returned when external_checkers is negative
+	ErrInvalidExternalCheckers = errors.New("external_checkers cannot be negative")
+	// ErrInvalidTrailingSlash is returned when trailing_slash is not a known mode
+	ErrInvalidTrailingSlash = errors.New("trailing_slash must be 'keep', 'add' or 'remove'")
+	// ErrInvalidRunHeader is returned when run_header is not a valid HTTP header name
+	ErrInvalidRunHeader = errors.New("run_header must be a valid HTTP header name")
+	// ErrInvalidCrawlerInfoURL is returned when crawler_info_url is not an absolute http(s) URL
+	ErrInvalidCrawlerInfoURL = errors.New("crawler_info_url must be an absolute http or https URL")
 	// ErrEmptyDatabasePath is returned when database path is empty
 	ErrEmptyDatabasePath = errors.New("database_path cannot be empty")
+	// ErrSameResultsDatabasePath is returned when results_database_path names the database_path file
+	ErrSameResultsDatabasePath = errors.New("results_database_path must differ from database_path")
+	// ErrMissingDatabasePassphrase is returned when database encryption is enabled but no passphrase is set
+	ErrMissingDatabasePassphrase = errors.New("database_encryption requires a passphrase in the database_passphrase_env environment variable")
+	// ErrInvalidServeAddress is returned when serve is not a "host:port" listen address
+	ErrInvalidServeAddress = errors.New("serve must be a listen address such as '127.0.0.1:8080' or ':8080'")
+	// ErrInvalidServeGRPCAddress is returned when serve_grpc is not a "host:port" listen address
+	ErrInvalidServeGRPCAddress = errors.New("serve_grpc must be a listen address such as '127.0.0.1:9090' or ':9090'")
+	// ErrInvalidOTLPEndpoint is returned when otlp_endpoint is not an absolute http(s) URL
+	ErrInvalidOTLPEndpoint = errors.New("otlp_endpoint must be an http or https URL such as 'http://localhost:4318'")
+	// ErrInvalidDebugAddress is returned when debug_addr is not a "host:port" listen address
+	ErrInvalidDebugAddress = errors.New("debug_addr must be a listen address such as '127.0.0.1:6060' or ':6060'")
+	// ErrInvalidWebhookURL is returned when a webhook url is not an absolute http(s) URL
+	ErrInvalidWebhookURL = errors.New("webhook url must be an http or https URL")
+	// ErrInvalidWebhookEvent is returned for an unknown webhook event
+	ErrInvalidWebhookEvent = errors.New("webhook events must be crawl_started, crawl_finished, error_rate or url_failed")
+	// ErrInvalidWebhookErrorRate is returned when a webhook error_rate is outside 0-100
+	ErrInvalidWebhookErrorRate = errors.New("webhook error_rate must be between 0 and 100")
 )
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Header sources, in increasing order of precedence. Headers from a source
+// replace headers with the same name (compared case-insensitively) from the
+// sources before it.
+const (
+	HeaderSourceConfig = "config file"  // headers list in the configuration file
+	HeaderSourceEnv    = "environment"  // LT_HEADER_* variables
+	HeaderSourceFlag   = "command line" // -H/--header flags
+)
+
+// headerEnvPrefix is the prefix of environment variables defining headers
+const headerEnvPrefix = "LT_HEADER_"
+
+// HeaderConflict reports a header set with different values by two sources
+type HeaderConflict struct {
+	Name       string // Header name as written by the winning source
+	Source     string // Source whose value is used
+	Overridden string // Source whose value was discarded
+}
+
+// String describes the conflict without revealing header values
+func (hc HeaderConflict) String() string {
+	return fmt.Sprintf("header %q from %s overrides the value from %s", hc.Name, hc.Source, hc.Overridden)
+}
+
+// MergeHeaders merges "Name: Value" headers from source into c.Headers. A
+// header whose name is already set is replaced in place; when the value
+// differs, a conflict is returned so the caller can warn about it. Sources
+// must be merged in increasing order of precedence. Headers already in
+// c.Headers that were not merged through MergeHeaders are attributed to the
+// configuration file.
+func (c *CrawlConfig) MergeHeaders(source string, headers []string) []HeaderConflict {
+	if c.headerSources == nil {
+		c.headerSources = make(map[string]string)
+	}
+
+	var conflicts []HeaderConflict
+	for _, header := range headers {
+		name, value := splitHeader(header)
+		key := strings.ToLower(name)
+
+		replaced := false
+		for i, existing := range c.Headers {
+			existingName, existingValue := splitHeader(existing)
+			if !strings.EqualFold(existingName, name) {
+				continue
+			}
+			if existingValue != value {
+				previous, ok := c.headerSources[key]
+				if !ok {
+					previous = HeaderSourceConfig
+				}
+				conflicts = append(conflicts, HeaderConflict{Name: name, Source: source, Overridden: previous})
+			}
+			c.Headers[i] = header
+			replaced = true
+			break
+		}
+		if !replaced {
+			c.Headers = append(c.Headers, header)
+		}
+		c.headerSources[key] = source
+	}
+	return conflicts
+}
+
+// LoadHeadersFromEnv merges headers defined by LT_HEADER_* environment
+// variables into c.Headers with HeaderSourceEnv precedence. See HeadersFromEnv
+// for the accepted forms.
+func (c *CrawlConfig) LoadHeadersFromEnv() ([]HeaderConflict, error) {
+	headers, err := HeadersFromEnv(os.Environ())
+	if err != nil {
+		return nil, err
+	}
+	return c.MergeHeaders(HeaderSourceEnv, headers), nil
+}
+
+// HeadersFromEnv extracts headers from environment entries ("KEY=value"). Two
+// forms are accepted:
+//
+//   - LT_HEADER_<n>="Name: Value", where <n> is a number: the header is used
+//     verbatim, so names keep their casing and may contain underscores.
+//     Entries are applied in numeric order.
+//   - LT_HEADER_<NAME>=value: the name is derived from the variable, with
+//     underscores turned into hyphens and each word capitalized
+//     (LT_HEADER_X_API_KEY becomes X-Api-Key).
+//
+// Variables with an empty value are treated as unset. A numbered variable
+// that is not in "Name: Value" form is an error.
+func HeadersFromEnv(environ []string) ([]string, error) {
+	type numbered struct {
+		n      int
+		header string
+	}
+	var explicit []numbered
+	var derived []string
+
+	for _, env := range environ {
+		key, value, ok := strings.Cut(env, "=")
+		if !ok || value == "" {
+			continue
+		}
+		suffix, ok := strings.CutPrefix(key, headerEnvPrefix)
+		if !ok || suffix == "" {
+			continue
+		}
+
+		if n, err := strconv.Atoi(suffix); err == nil {
+			if name, _ := splitHeader(value); name == "" || !strings.Contains(value, ":") {
+				return nil, fmt.Errorf("invalid %s: expected 'Name: Value'", key)
+			}
+			explicit = append(explicit, numbered{n: n, header: value})
+			continue
+		}
+
+		derived = append(derived, fmt.Sprintf("%s: %s", headerNameFromEnv(suffix), value))
+	}
+
+	// Derived names come first so an explicit mapping wins a name clash
+	sort.Strings(derived)
+	sort.Slice(explicit, func(i, j int) bool { return explicit[i].n < explicit[j].n })
+	headers := derived
+	for _, e := range explicit {
+		headers = append(headers, e.header)
+	}
+	return headers, nil
+}
+
+// headerNameFromEnv converts an environment variable suffix such as
+// ACCEPT_LANGUAGE to a header name such as Accept-Language
+func headerNameFromEnv(suffix string) string {
+	parts := strings.Split(strings.ToLower(suffix), "_")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// splitHeader splits a "Name: Value" header into its trimmed name and value
+func splitHeader(header string) (name, value string) {
+	name, value, _ = strings.Cut(header, ":")
+	return strings.TrimSpace(name), strings.TrimSpace(value)
+}
@@ -0,0 +1,55 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage/memory"
+)
+
+// Accessibility issues are recorded only with accessibility_checks on
+func TestAccessibilityChecks(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html lang="en"><body><h1>Home</h1><img src="/logo.png"><a href="/"><i class="icon"></i></a></body></html>`))
+	}))
+	defer site.Close()
+
+	for _, enabled := range []bool{false, true} {
+		cfg := baseCfg()
+		cfg.SeedURLs = []string{site.URL + "/"}
+		cfg.AccessibilityChecks = enabled
+		store := memory.New()
+		c, err := crawler.NewCrawler(cfg, store)
+		if err != nil {
+			t.Fatalf("NewCrawler: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		cancel()
+
+		page, ok := store.Page(site.URL + "/")
+		if !ok {
+			t.Fatal("Expected the page to be crawled")
+		}
+		if !enabled {
+			if page.Accessibility != nil {
+				t.Errorf("Expected no issues with checks off, got %+v", page.Accessibility)
+			}
+			continue
+		}
+		want := []crawler.AccessibilityIssue{
+			{Issue: "missing_alt", Element: `<img src="/logo.png">`},
+			{Issue: "empty_link", Element: `<a href="/">`},
+		}
+		if len(page.Accessibility) != len(want) || page.Accessibility[0] != want[0] || page.Accessibility[1] != want[1] {
+			t.Errorf("Expected %+v, got %+v", want, page.Accessibility)
+		}
+	}
+}
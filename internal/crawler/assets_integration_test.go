@@ -0,0 +1,60 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// With crawl_assets, stylesheets and images a page loads are fetched and
+// their status codes recorded; a missing image shows up as a broken link.
+func TestCrawlAssets(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><link rel="stylesheet" href="/site.css"></head>
+			<body><img src="/missing.png" alt="Gone"></body></html>`))
+	})
+	mux.HandleFunc("/site.css", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/css")
+		_, _ = w.Write([]byte("body { color: black; }"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	cfg := baseCfg()
+	cfg.CrawlAssets = true
+	cfg.SeedURLs = []string{server.URL + "/"}
+	store := newStore(t)
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if got, _ := statusOf(t, store, server.URL+"/site.css"); got != "completed" {
+		t.Errorf("/site.css status = %q, want completed", got)
+	}
+	broken, err := store.GetBrokenLinks()
+	if err != nil {
+		t.Fatalf("GetBrokenLinks: %v", err)
+	}
+	if len(broken) != 1 || broken[0].TargetURL != server.URL+"/missing.png" ||
+		broken[0].StatusCode != http.StatusNotFound || broken[0].LinkType != crawler.LinkTypeAsset {
+		t.Errorf("Expected the missing image as the only broken link, got %+v", broken)
+	}
+}
@@ -0,0 +1,93 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// Credentials go to the seed hosts only: an external host admitted by
+// allowed_hosts that answers 401 without an NTLM challenge must not receive
+// them as basic auth, unless it is listed in auth.hosts
+func TestCrawlSendsCredentialsToAuthHostsOnly(t *testing.T) {
+	var mu sync.Mutex
+	var external []string
+	externalSite := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		external = append(external, r.Header.Get("Authorization"))
+		mu.Unlock()
+		w.Header().Set("WWW-Authenticate", `Basic realm="external"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(externalSite.Close)
+
+	var seedAuthorized bool
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != `CORP\crawler` || password != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="intranet"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		seedAuthorized = true
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<a href="` + externalSite.URL + `/page">External</a>`))
+	}))
+	t.Cleanup(site.Close)
+	// Both servers listen on 127.0.0.1; crawl the seed as localhost
+	seedURL := strings.Replace(site.URL, "127.0.0.1", "localhost", 1) + "/"
+
+	for name, authHosts := range map[string][]string{
+		"seed hosts": nil,
+		"auth.hosts": {"localhost", "127.0.0.1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mu.Lock()
+			external, seedAuthorized = nil, false
+			mu.Unlock()
+
+			cfg := baseCfg()
+			cfg.SeedURLs = []string{seedURL}
+			cfg.AllowedHosts = []string{"localhost", "127.0.0.1"}
+			cfg.Auth = &config.Auth{
+				Type:  config.NTLMAuthType,
+				Basic: &config.BasicAuth{Username: `CORP\crawler`, Password: "secret"},
+				Hosts: authHosts,
+			}
+			c, err := crawler.NewCrawler(cfg, newStore(t))
+			if err != nil {
+				t.Fatalf("NewCrawler: %v", err)
+			}
+			t.Cleanup(func() { _ = c.Stop() })
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+				t.Fatalf("Start: %v", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !seedAuthorized {
+				t.Error("Expected the seed host to receive the credentials")
+			}
+			if len(external) == 0 {
+				t.Fatal("Expected the external page to be crawled")
+			}
+			sent := false
+			for _, authorization := range external {
+				sent = sent || authorization != ""
+			}
+			if want := authHosts != nil; sent != want {
+				t.Errorf("External host received credentials = %v, want %v (Authorization headers: %q)", sent, want, external)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package crawler
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Autoscaling thresholds (min_concurrency / max_concurrency)
+const (
+	autoscaleInterval         = 5 * time.Second
+	autoscaleBacklogPerWork   = 10  // Pending URLs per worker before another worker is added
+	autoscaleMaxErrorRate     = 0.2 // Error share of an interval above which workers are removed
+	autoscaleMaxRateLimitWait = 0.5 // Share of worker time spent in per-host delays above which no workers are added
+)
+
+// scaleSample is what the autoscaler observed during one interval
+type scaleSample struct {
+	workers  int           // Workers running
+	pending  int           // URLs waiting in the queue
+	pages    int           // Pages crawled during the interval
+	errors   int           // Pages that failed during the interval
+	waited   time.Duration // Time workers spent waiting on per-host request delays
+	interval time.Duration
+}
+
+// scaleDelta decides how many workers to add (positive) or remove (negative),
+// one at a time so the pool settles instead of oscillating:
+//   - many errors (overloaded or blocking site): remove a worker
+//   - workers mostly waiting on per-host delays: more workers would only wait
+//     too, so none are added; the pool shrinks when the backlog is small
+//   - a backlog of more than autoscaleBacklogPerWork URLs per worker: add one
+//   - fewer pending URLs than workers: remove one
+func scaleDelta(s scaleSample, minWorkers, maxWorkers int) int {
+	total := s.pages + s.errors
+	switch {
+	case total > 0 && float64(s.errors)/float64(total) > autoscaleMaxErrorRate:
+		if s.workers > minWorkers {
+			return -1
+		}
+	case s.pending > s.workers*autoscaleBacklogPerWork:
+		politeBound := s.workers > 0 && s.interval > 0 &&
+			s.waited.Seconds()/(float64(s.workers)*s.interval.Seconds()) > autoscaleMaxRateLimitWait
+		if !politeBound && s.workers < maxWorkers {
+			return 1
+		}
+	case s.pending < s.workers:
+		if s.workers > minWorkers {
+			return -1
+		}
+	}
+	return 0
+}
+
+// autoscaler adjusts the number of workers between min_concurrency and
+// max_concurrency every autoscaleInterval (see scaleDelta)
+func (c *DefaultCrawler) autoscaler() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(autoscaleInterval)
+	defer ticker.Stop()
+
+	minWorkers := max(c.config.MinConcurrency, 1)
+	last := c.GetStats()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			// A paused pool does no work, so its throughput says nothing
+			if c.Paused() {
+				last = c.GetStats()
+				c.rateLimitWait.Store(0)
+				continue
+			}
+			pending, _, _, _, err := c.storage.GetQueueStatus()
+			if err != nil {
+				slog.Error("Autoscaler failed to get queue status", "error", err)
+				continue
+			}
+			stats := c.GetStats()
+			sample := scaleSample{
+				workers:  c.workerTarget(),
+				pending:  pending,
+				pages:    stats.PagesCrawled - last.PagesCrawled,
+				errors:   stats.ErrorCount - last.ErrorCount,
+				waited:   time.Duration(c.rateLimitWait.Swap(0)),
+				interval: autoscaleInterval,
+			}
+			last = stats
+
+			switch delta := scaleDelta(sample, minWorkers, c.config.MaxConcurrency); {
+			case delta > 0:
+				c.addWorker()
+				slog.Info("Autoscaler added a worker", "workers", sample.workers+1, "pending", pending)
+			case delta < 0:
+				c.setWorkerTarget(sample.workers - 1)
+				slog.Info("Autoscaler removed a worker", "workers", sample.workers-1, "pending", pending, "errors", sample.errors)
+			}
+		}
+	}
+}
+
+// workerTarget returns the number of workers the pool should have
+func (c *DefaultCrawler) workerTarget() int {
+	c.workersMutex.Lock()
+	defer c.workersMutex.Unlock()
+	return c.targetWorkers
+}
+
+// setWorkerTarget lowers the pool size; surplus workers exit before
+// claiming their next URL (retireWorker)
+func (c *DefaultCrawler) setWorkerTarget(n int) {
+	c.workersMutex.Lock()
+	defer c.workersMutex.Unlock()
+	c.targetWorkers = n
+}
+
+// addWorker starts one more worker while the crawl is running
+func (c *DefaultCrawler) addWorker() {
+	c.workersMutex.Lock()
+	defer c.workersMutex.Unlock()
+	if c.ctx.Err() != nil || c.activeWorkers == 0 {
+		return
+	}
+	c.targetWorkers++
+	c.activeWorkers++
+	id := c.nextWorkerID
+	c.nextWorkerID++
+	c.wg.Add(1)
+	go c.worker(id)
+}
+
+// retireWorker reports whether a worker should exit because the pool is
+// larger than its target. The retiring worker is removed from activeWorkers
+// here, so several workers checking at once retire only the surplus.
+func (c *DefaultCrawler) retireWorker(id int) bool {
+	c.workersMutex.Lock()
+	defer c.workersMutex.Unlock()
+	if c.targetWorkers <= 0 || c.activeWorkers <= c.targetWorkers {
+		return false
+	}
+	c.activeWorkers--
+	slog.Debug("Worker retired by autoscaler", "worker_id", id)
+	return true
+}
@@ -0,0 +1,49 @@
+package crawler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScaleDelta(t *testing.T) {
+	interval := 5 * time.Second
+	tests := []struct {
+		name   string
+		sample scaleSample
+		want   int
+	}{
+		{"backlog grows the pool", scaleSample{workers: 2, pending: 100, pages: 10, interval: interval}, 1},
+		{"at max", scaleSample{workers: 8, pending: 1000, pages: 10, interval: interval}, 0},
+		{"errors shrink the pool", scaleSample{workers: 4, pending: 1000, pages: 6, errors: 4, interval: interval}, -1},
+		{"errors at min", scaleSample{workers: 1, pending: 1000, pages: 6, errors: 4, interval: interval}, 0},
+		{"waiting on host delays", scaleSample{workers: 2, pending: 100, pages: 10, waited: 8 * time.Second, interval: interval}, 0},
+		{"short queue shrinks the pool", scaleSample{workers: 4, pending: 2, pages: 10, interval: interval}, -1},
+		{"steady", scaleSample{workers: 4, pending: 20, pages: 10, interval: interval}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scaleDelta(tt.sample, 1, 8); got != tt.want {
+				t.Errorf("scaleDelta(%+v) = %d, want %d", tt.sample, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetireWorker(t *testing.T) {
+	c := &DefaultCrawler{activeWorkers: 3, targetWorkers: 2}
+	if !c.retireWorker(0) {
+		t.Error("Expected a worker above the target to retire")
+	}
+	if c.retireWorker(1) {
+		t.Error("Expected no further workers to retire at the target")
+	}
+	if c.activeWorkers != 2 {
+		t.Errorf("activeWorkers = %d, want 2", c.activeWorkers)
+	}
+
+	// Without autoscaling no worker retires
+	c = &DefaultCrawler{activeWorkers: 3}
+	if c.retireWorker(0) {
+		t.Error("Expected no retirement when autoscaling is off")
+	}
+}
@@ -0,0 +1,56 @@
+package crawler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// With queue_batch_size, workers claim several URLs at a time; URLs still
+// claimed when the limit stops the crawl are returned to 'pending'.
+func TestCrawlWithQueueBatches(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/" {
+			for i := range 10 {
+				_, _ = fmt.Fprintf(w, `<a href="/p%d">%d</a>`, i, i)
+			}
+			return
+		}
+		_, _ = w.Write([]byte(`<p>leaf</p>`))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := baseCfg()
+	cfg.QueueBatchSize = 4
+	cfg.Limit = 3
+	cfg.SeedURLs = []string{server.URL + "/"}
+	store := newStore(t)
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	pending, processing, completed, _, err := store.GetQueueStatus()
+	if err != nil {
+		t.Fatalf("GetQueueStatus: %v", err)
+	}
+	if processing != 0 {
+		t.Errorf("Expected no URLs left in processing, got %d", processing)
+	}
+	if completed != 3 || pending != 8 {
+		t.Errorf("Expected 3 completed and 8 pending, got %d and %d", completed, pending)
+	}
+}
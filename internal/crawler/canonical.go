@@ -0,0 +1,39 @@
+package crawler
+
+import (
+	"net/url"
+)
+
+// canonicalLink returns a link from a page to the canonical URL it declares,
+// or nil when the page has no canonical or is its own canonical. Fragments are
+// ignored when comparing the two URLs.
+func canonicalLink(page *PageData) *LinkData {
+	if page == nil || page.CanonicalURL == "" {
+		return nil
+	}
+	pageURL, err := url.Parse(page.URL)
+	if err != nil {
+		return nil
+	}
+	canonicalURL, err := url.Parse(page.CanonicalURL)
+	if err != nil {
+		return nil
+	}
+	pageURL.Fragment, pageURL.RawFragment = "", ""
+	canonicalURL.Fragment, canonicalURL.RawFragment = "", ""
+	if pageURL.String() == canonicalURL.String() {
+		return nil
+	}
+
+	linkType := "internal"
+	if canonicalURL.Host != pageURL.Host {
+		linkType = "external"
+	}
+	return &LinkData{
+		SourceURL:    page.URL,
+		TargetURL:    canonicalURL.String(),
+		LinkType:     linkType,
+		RelAttribute: "canonical",
+		CrawledAt:    page.CrawledAt,
+	}
+}
@@ -0,0 +1,60 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// With follow_canonical, a parameterized page queues its canonical instead of
+// its own links; the canonical page's links are followed as usual.
+func TestCrawlFollowsCanonical(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch {
+		case r.URL.Path == "/shoes" && r.URL.RawQuery != "":
+			_, _ = w.Write([]byte(`<html><head><link rel="canonical" href="/shoes"></head>
+				<body><a href="/shoes?color=blue">Blue</a></body></html>`))
+		case r.URL.Path == "/shoes":
+			_, _ = w.Write([]byte(`<html><head><link rel="canonical" href="/shoes"></head>
+				<body><a href="/about">About</a></body></html>`))
+		case r.URL.Path == "/about":
+			_, _ = w.Write([]byte(`<p>about</p>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := baseCfg()
+	cfg.FollowCanonical = true
+	cfg.SeedURLs = []string{server.URL + "/shoes?color=red"}
+	store := newStore(t)
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	want := map[string]string{
+		"/shoes?color=red":  "completed",
+		"/shoes":            "completed",
+		"/about":            "completed",
+		"/shoes?color=blue": "discovered",
+	}
+	for path, status := range want {
+		if got, _ := statusOf(t, store, server.URL+path); got != status {
+			t.Errorf("%s status = %q, want %q", path, got, status)
+		}
+	}
+}
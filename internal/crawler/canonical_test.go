@@ -0,0 +1,31 @@
+package crawler
+
+import (
+	"testing"
+)
+
+func TestCanonicalLink(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		canonical string
+		expected  *LinkData
+	}{
+		{"no canonical", "https://example.com/a", "", nil},
+		{"self", "https://example.com/a", "https://example.com/a", nil},
+		{"self with fragment", "https://example.com/a#top", "https://example.com/a", nil},
+		{"other page", "https://example.com/a?sort=price", "https://example.com/a",
+			&LinkData{SourceURL: "https://example.com/a?sort=price", TargetURL: "https://example.com/a", LinkType: "internal", RelAttribute: "canonical"}},
+		{"other host", "https://example.com/a", "https://www.example.com/a#main",
+			&LinkData{SourceURL: "https://example.com/a", TargetURL: "https://www.example.com/a", LinkType: "external", RelAttribute: "canonical"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := canonicalLink(&PageData{URL: tt.url, CanonicalURL: tt.canonical})
+			if (got == nil) != (tt.expected == nil) || (got != nil && *got != *tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
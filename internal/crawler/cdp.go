@@ -0,0 +1,162 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/net/websocket"
+)
+
+// cdpMaxMessageSize bounds one Chrome DevTools Protocol message; a rendered
+// DOM comes back in a single message
+const cdpMaxMessageSize = 64 << 20
+
+// errCDPClosed is returned for commands sent over a closed connection
+var errCDPClosed = errors.New("browser connection closed")
+
+// cdpMessage is a command, response or event of the Chrome DevTools Protocol
+type cdpMessage struct {
+	ID        int64           `json:"id,omitempty"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// cdpConn is a connection to a browser's DevTools WebSocket. Commands of
+// several pages run concurrently over it: each page is a session, whose
+// events are delivered to the channel registered with subscribe.
+type cdpConn struct {
+	ws     *websocket.Conn
+	nextID atomic.Int64
+	done   chan struct{} // Closed when the connection is lost
+
+	mu       sync.Mutex
+	pending  map[int64]chan *cdpMessage
+	sessions map[string]chan *cdpMessage
+}
+
+// dialCDP connects to the DevTools WebSocket at wsURL
+func dialCDP(ctx context.Context, wsURL string) (*cdpConn, error) {
+	config, err := websocket.NewConfig(wsURL, "http://localhost")
+	if err != nil {
+		return nil, fmt.Errorf("invalid browser URL: %w", err)
+	}
+	ws, err := config.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to browser: %w", err)
+	}
+	ws.MaxPayloadBytes = cdpMaxMessageSize
+	c := &cdpConn{
+		ws:       ws,
+		done:     make(chan struct{}),
+		pending:  make(map[int64]chan *cdpMessage),
+		sessions: make(map[string]chan *cdpMessage),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+// readLoop hands responses to the waiting commands and events to their
+// session until the connection is lost
+func (c *cdpConn) readLoop() {
+	for {
+		var msg cdpMessage
+		if err := websocket.JSON.Receive(c.ws, &msg); err != nil {
+			close(c.done)
+			return
+		}
+		c.mu.Lock()
+		if msg.ID != 0 {
+			if ch, ok := c.pending[msg.ID]; ok {
+				delete(c.pending, msg.ID)
+				ch <- &msg
+			}
+		} else if ch, ok := c.sessions[msg.SessionID]; ok {
+			select {
+			case ch <- &msg:
+			default: // The session is not keeping up; drop the event
+			}
+		}
+		c.mu.Unlock()
+	}
+}
+
+// call sends method with params to the browser, or to the page of sessionID
+// when set, waits for the answer and decodes it into result (may be nil)
+func (c *cdpConn) call(ctx context.Context, sessionID, method string, params, result any) error {
+	msg := cdpMessage{ID: c.nextID.Add(1), SessionID: sessionID, Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		msg.Params = raw
+	}
+	ch := make(chan *cdpMessage, 1)
+	c.mu.Lock()
+	c.pending[msg.ID] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, msg.ID)
+		c.mu.Unlock()
+	}()
+
+	if err := websocket.JSON.Send(c.ws, msg); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	select {
+	case reply := <-ch:
+		if reply.Error != nil {
+			return fmt.Errorf("%s: %s", method, reply.Error.Message)
+		}
+		if result != nil {
+			return json.Unmarshal(reply.Result, result)
+		}
+		return nil
+	case <-c.done:
+		return fmt.Errorf("%s: %w", method, errCDPClosed)
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", method, ctx.Err())
+	}
+}
+
+// subscribe returns the channel receiving the events of sessionID
+func (c *cdpConn) subscribe(sessionID string) <-chan *cdpMessage {
+	ch := make(chan *cdpMessage, 256)
+	c.mu.Lock()
+	c.sessions[sessionID] = ch
+	c.mu.Unlock()
+	return ch
+}
+
+// unsubscribe stops delivering the events of sessionID
+func (c *cdpConn) unsubscribe(sessionID string) {
+	c.mu.Lock()
+	delete(c.sessions, sessionID)
+	c.mu.Unlock()
+}
+
+// alive reports whether the connection is still open
+func (c *cdpConn) alive() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// close closes the connection
+func (c *cdpConn) close() error {
+	return c.ws.Close()
+}
@@ -0,0 +1,55 @@
+package crawler
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"sync"
+)
+
+// appendCertificate appends the leaf certificate of a TLS connection to
+// certs, unless certs already holds it. The host is the server name sent in
+// the handshake; connections to IP addresses send none, so host is used.
+func appendCertificate(certs []TLSCertificate, state tls.ConnectionState, host string) []TLSCertificate {
+	if len(state.PeerCertificates) == 0 {
+		return certs
+	}
+	if state.ServerName != "" {
+		host = state.ServerName
+	}
+	leaf := state.PeerCertificates[0]
+	sum := sha256.Sum256(leaf.Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+	for _, cert := range certs {
+		if cert.Host == host && cert.Fingerprint == fingerprint {
+			return certs
+		}
+	}
+	return append(certs, TLSCertificate{
+		Host:        host,
+		Subject:     leaf.Subject.String(),
+		Issuer:      leaf.Issuer.String(),
+		DNSNames:    leaf.DNSNames,
+		NotBefore:   leaf.NotBefore.UTC(),
+		NotAfter:    leaf.NotAfter.UTC(),
+		Fingerprint: fingerprint,
+	})
+}
+
+// certificateTracker remembers the certificate last reported for each host,
+// so a host's certificate is stored with the first page fetched from it and
+// again only when it changes
+type certificateTracker struct {
+	seen sync.Map // Host → fingerprint
+}
+
+// unreported returns the certificates of certs not reported yet
+func (t *certificateTracker) unreported(certs []TLSCertificate) []TLSCertificate {
+	var fresh []TLSCertificate
+	for _, cert := range certs {
+		if previous, loaded := t.seen.Swap(cert.Host, cert.Fingerprint); !loaded || previous != cert.Fingerprint {
+			fresh = append(fresh, cert)
+		}
+	}
+	return fresh
+}
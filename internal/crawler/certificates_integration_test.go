@@ -0,0 +1,52 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage/memory"
+)
+
+// A host's certificate is attached to the first page fetched from it only
+func TestCertificatesRecordedOncePerHost(t *testing.T) {
+	site := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`<a href="/a">A</a><a href="/b">B</a>`))
+		}
+	}))
+	defer site.Close()
+
+	cfg := baseCfg()
+	cfg.SeedURLs = []string{site.URL + "/"}
+	cfg.TLSInsecureSkipVerify = true
+	store := memory.New()
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	var certs []crawler.TLSCertificate
+	for _, path := range []string{"/", "/a", "/b"} {
+		page, ok := store.Page(site.URL + path)
+		if !ok {
+			t.Fatalf("Expected %s to be crawled", path)
+		}
+		certs = append(certs, page.Certificates...)
+	}
+	if len(certs) != 1 {
+		t.Fatalf("Expected the certificate to be recorded once, got %+v", certs)
+	}
+	if certs[0].Host != "127.0.0.1" || !certs[0].NotAfter.Equal(site.Certificate().NotAfter) {
+		t.Errorf("Unexpected certificate %+v", certs[0])
+	}
+}
@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"mime"
+	"strings"
+)
+
+// ContentTypeFilter decides from a response's Content-Type header whether its
+// body is worth downloading. Patterns are media types such as "text/html" or
+// wildcards such as "image/*"; parameters like charset are ignored.
+type ContentTypeFilter struct {
+	allowed []string
+	blocked []string
+}
+
+// NewContentTypeFilter creates a filter, or returns nil when both lists are empty
+func NewContentTypeFilter(allowed, blocked []string) *ContentTypeFilter {
+	if len(allowed) == 0 && len(blocked) == 0 {
+		return nil
+	}
+	return &ContentTypeFilter{
+		allowed: normalizeMediaTypes(allowed),
+		blocked: normalizeMediaTypes(blocked),
+	}
+}
+
+// Allows reports whether a response with the given Content-Type should be
+// downloaded. Blocked types are rejected; when allowed types are configured,
+// only those are accepted. A missing Content-Type is always accepted because
+// nothing can be inferred from it.
+func (f *ContentTypeFilter) Allows(contentType string) bool {
+	mediaType := mediaTypeOf(contentType)
+	if mediaType == "" {
+		return true
+	}
+	if matchesAnyMediaType(f.blocked, mediaType) {
+		return false
+	}
+	return len(f.allowed) == 0 || matchesAnyMediaType(f.allowed, mediaType)
+}
+
+// mediaTypeOf returns the lower-cased media type of a Content-Type header without parameters
+func mediaTypeOf(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// matchesAnyMediaType reports whether mediaType matches any pattern
+func matchesAnyMediaType(patterns []string, mediaType string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*/*" || pattern == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeMediaTypes lower-cases and trims configured patterns
+func normalizeMediaTypes(patterns []string) []string {
+	normalized := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(pattern)))
+	}
+	return normalized
+}
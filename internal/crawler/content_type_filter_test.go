@@ -0,0 +1,94 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContentTypeFilter(t *testing.T) {
+	if NewContentTypeFilter(nil, nil) != nil {
+		t.Error("Expected nil filter when no lists are configured")
+	}
+
+	tests := []struct {
+		name        string
+		allowed     []string
+		blocked     []string
+		contentType string
+		expected    bool
+	}{
+		{"blocked wildcard", nil, []string{"image/*"}, "image/png", false},
+		{"blocked exact", nil, []string{"application/zip"}, "application/zip", false},
+		{"not blocked", nil, []string{"image/*"}, "text/html; charset=utf-8", true},
+		{"allowed exact with params", []string{"text/html"}, nil, "text/html; charset=UTF-8", true},
+		{"allowed case-insensitive", []string{"Text/HTML"}, nil, "TEXT/html", true},
+		{"not allowed", []string{"text/html"}, nil, "application/pdf", false},
+		{"blocked wins over allowed", []string{"image/*"}, []string{"image/svg+xml"}, "image/svg+xml", false},
+		{"missing content type", []string{"text/html"}, nil, "", true},
+		{"malformed header", []string{"text/html"}, nil, "text/html;;", true},
+		{"allow all", []string{"*/*"}, nil, "video/mp4", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewContentTypeFilter(tt.allowed, tt.blocked)
+			if got := filter.Allows(tt.contentType); got != tt.expected {
+				t.Errorf("Allows(%q) = %v, expected %v", tt.contentType, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPageProcessorSkipsFilteredContentTypes(t *testing.T) {
+	bodyRead := make(chan bool, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			// Keep streaming until the client hangs up
+			chunk := []byte(strings.Repeat("x", 32*1024))
+			for i := 0; i < 1024; i++ {
+				if _, err := w.Write(chunk); err != nil {
+					bodyRead <- false
+					return
+				}
+			}
+			bodyRead <- true
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><head><title>Page</title></head></html>`))
+		}
+	}))
+	defer server.Close()
+
+	processor := NewPageProcessor(NewHTTPClient("TestCrawler/1.0", 10*time.Second)).(*DefaultPageProcessor)
+	processor.SetContentTypeFilter(NewContentTypeFilter(nil, []string{"image/*"}))
+
+	result, err := processor.Process(context.Background(), server.URL+"/image.png")
+	if err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if result.Skip == nil || result.Skip.Reason != "content_type_filtered" {
+		t.Fatalf("Expected content_type_filtered skip, got %+v", result)
+	}
+	if result.Page != nil {
+		t.Error("Expected no page data for a skipped response")
+	}
+	if <-bodyRead {
+		t.Error("Expected the body download to be aborted")
+	}
+
+	result, err = processor.Process(context.Background(), server.URL+"/page")
+	if err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if result.Skip != nil || result.Page == nil || result.Page.Title != "Page" {
+		t.Errorf("Expected HTML page to be crawled, got %+v", result)
+	}
+}
@@ -5,15 +5,20 @@ package crawler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/url"
-	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/parser"
 )
 
 // DefaultCrawler implements the Crawler interface
@@ -24,15 +29,39 @@ type DefaultCrawler struct {
 	processor    PageProcessor
 	rateLimiter  *RateLimiter
 	robotsParser *RobotsParser
-	allowedHosts []string // Hosts allowed for crawling (from seed URLs)
+	redactor     *Redactor        // Optional; nil when no redaction rules are configured
+	normalizer   *URLNormalizer   // Optional; nil when no normalization rules are configured
+	traps        *TrapDetector    // Optional; nil when every trap limit is disabled
+	allowedHosts []string         // Hosts allowed for crawling (from seed URLs)
+	patterns     *urlPatterns     // Compiled include/exclude patterns
+	seen         *seenURLs        // Optional; nil when seen_url_cache_size is 0
+	writer       *resultWriter    // Optional; nil when write_buffer_size is 0 (workers write synchronously)
+	checker      *externalChecker // Optional; nil unless check_external is head
+	webhooks     *webhookNotifier // Optional; nil when no webhooks are configured
+	hooks        Hooks            // Callbacks of an embedding program (see SetHooks)
+	events       *EventBus        // Typed crawl events for integrations (see Events)
+	script       *Script          // Optional; nil when no script is configured
+	renderer     Renderer         // Optional; nil unless render patterns or the render fallback are configured
+	frontier     frontierLimiter  // Enforces max_queue_size
+	checked      sync.Map         // External URLs claimed for a HEAD check during this run
+	pagination   paginationTracker
 
 	// State
 	stats         CrawlStats
 	statsMutex    sync.RWMutex
 	ctx           context.Context
 	cancel        context.CancelFunc
+	fetchCtx      context.Context    // Requests of pages in flight; outlives ctx while the crawl drains
+	cancelFetch   context.CancelFunc // Cuts off the requests once draining gives up
 	wg            sync.WaitGroup
 	activeWorkers int
+	targetWorkers int          // Pool size chosen by the autoscaler; 0 when autoscaling is off
+	nextWorkerID  int          // ID of the next worker the autoscaler starts
+	rateLimitWait atomic.Int64 // Nanoseconds workers spent in per-host delays since the autoscaler last looked
+	paused        atomic.Bool  // Workers claim no URLs while set (see Pause)
+	emptySent     atomic.Bool  // QueueEmpty was published for the current pool of workers
+	limitSent     atomic.Bool  // LimitReached was published
+	stopped       atomic.Bool  // Stop was called
 	workersMutex  sync.Mutex
 }
 
@@ -60,7 +89,52 @@ func NewCrawler(config *config.CrawlConfig, storage Storage) (*DefaultCrawler, e
 			if header, value := config.GetAPIKeyCredentials(); header != "" && value != "" {
 				httpClient.SetAPIKeyAuth(header, value)
 			}
+		case "ntlm", "negotiate":
+			if username, password := config.GetBasicAuthCredentials(); username != "" && password != "" {
+				httpClient.SetNTLMAuth(username, password)
+			}
+		}
+		httpClient.SetAuthHosts(authHosts(config, config.SeedURLs))
+	}
+
+	// Present a client certificate for mutual TLS if configured
+	if config.TLSClientCert != "" {
+		if err := httpClient.SetClientCertificate(config.TLSClientCert, config.TLSClientKey); err != nil {
+			return nil, err
+		}
+	}
+
+	// Trust a custom CA bundle if configured
+	if config.TLSCAFile != "" {
+		if err := httpClient.SetCAFile(config.TLSCAFile); err != nil {
+			return nil, err
+		}
+	}
+	if config.TLSInsecureSkipVerify {
+		slog.Warn("TLS certificate verification is disabled")
+		httpClient.SetInsecureSkipVerify(true)
+	}
+
+	if config.MaxResponseSize > 0 {
+		httpClient.SetMaxResponseSize(config.MaxResponseSize)
+	}
+
+	// Record responses for offline replay, or replay recorded ones
+	if config.ReplayDir != "" {
+		if err := httpClient.SetReplayDir(config.ReplayDir); err != nil {
+			return nil, err
 		}
+		slog.Info("Replaying recorded responses; no requests are sent", "replay_dir", config.ReplayDir)
+	} else if config.RecordDir != "" {
+		if err := httpClient.SetRecordDir(config.RecordDir); err != nil {
+			return nil, err
+		}
+		slog.Info("Recording responses", "record_dir", config.RecordDir)
+	}
+
+	// Resolve hostnames through the in-process DNS cache if configured
+	if config.UsesCustomDNS() {
+		httpClient.SetDNSCache(NewDNSCache(config.DNSCacheTTL, config.DNSResolver, config.DNSOverrides))
 	}
 
 	// Set custom headers if provided
@@ -93,15 +167,84 @@ func NewCrawler(config *config.CrawlConfig, storage Storage) (*DefaultCrawler, e
 		}
 	}
 
+	// Stamp every request with this run's identifier so server-side logs can
+	// isolate crawler traffic
+	var runID string
+	if config.RunHeader != "" {
+		runID = newRunID()
+		httpClient.AddCustomHeader(config.RunHeader, runID)
+		slog.Info("Stamping requests with run ID", "header", config.RunHeader, "run_id", runID)
+	}
+
 	// Initialize components
-	processor := NewPageProcessorWithConfig(httpClient, config.AllowedSchemes, config.FollowExternalHosts)
+	// Links to other hosts are kept when the crawl scope may admit them or
+	// when they are verified with check_external: head
+	saveExternalLinks := config.FollowExternalHosts || scopeSpansHosts(config) || config.ChecksExternalLinks()
+	processor := NewPageProcessorWithConfig(httpClient, config.AllowedSchemes, saveExternalLinks).(*DefaultPageProcessor)
+	processor.SetContentTypeFilter(NewContentTypeFilter(config.AllowedContentTypes, config.BlockedContentTypes))
+	processor.SetHashAssets(config.HashAssets)
+	processor.SetCrawlAssets(config.CrawlAssets)
+	processor.SetRespectXRobotsTag(config.RespectXRobotsTag)
+	processor.SetRespectNofollow(config.RespectNofollow)
+	processor.SetAccessibilityChecks(config.AccessibilityChecks)
 	rateLimiter := NewRateLimiter(time.Duration(config.RequestDelay * float64(time.Second)))
+	rateLimiter.SetJitter(config.RequestJitter)
 	robotsParser := NewRobotsParser(httpClient, config.IgnoreRobotsTxt)
 
+	redactor, err := NewRedactor(config.Redaction)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizer, err := NewURLNormalizer(config)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns, err := compileURLPatterns(config.IncludePatterns, config.ExcludePatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks, err := newWebhookNotifier(config, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	var renderer Renderer
+	if len(config.RenderPatterns) > 0 || config.RenderFallback {
+		patterns, err := compileRenderPatterns(config.RenderPatterns)
+		if err != nil {
+			return nil, err
+		}
+		browser, err := NewBrowserRenderer(BrowserOptions{
+			Path:      config.BrowserPath,
+			URL:       config.BrowserURL,
+			Timeout:   config.RenderTimeout,
+			UserAgent: config.UserAgent,
+		})
+		if err != nil {
+			return nil, err
+		}
+		renderer = browser
+		processor.SetRenderer(renderer, patterns)
+		processor.SetRenderFallback(config.RenderFallback)
+	}
+
+	var script *Script
+	if config.Script != "" {
+		if script, err = LoadScript(config.Script, config.ScriptTimeout); err != nil {
+			return nil, err
+		}
+		if script.Defines(scriptExtract) {
+			processor.AddExtractor("script", script)
+		}
+	}
+
 	// Extract allowed hosts from seed URLs for same-host filtering
 	allowedHosts := make([]string, 0, len(config.SeedURLs))
 	for _, seedURL := range config.SeedURLs {
-		if parsedURL, err := url.Parse(seedURL); err == nil {
+		if parsedURL, err := url.Parse(normalizeSeedURL(seedURL)); err == nil {
 			host := parsedURL.Scheme + "://" + parsedURL.Host
 			// Avoid duplicates
 			found := false
@@ -124,11 +267,25 @@ func NewCrawler(config *config.CrawlConfig, storage Storage) (*DefaultCrawler, e
 		processor:    processor,
 		rateLimiter:  rateLimiter,
 		robotsParser: robotsParser,
+		redactor:     redactor,
+		normalizer:   normalizer,
+		traps:        NewTrapDetector(config),
 		allowedHosts: allowedHosts,
+		patterns:     patterns,
+		webhooks:     webhooks,
+		events:       &EventBus{},
+		script:       script,
+		renderer:     renderer,
+		seen:         newSeenURLs(config.SeenURLCacheSize),
 		stats: CrawlStats{
 			StartTime: time.Now(),
+			RunID:     runID,
 		},
 	}
+	crawler.events.Subscribe(crawler.errorHook)
+	if webhooks != nil {
+		crawler.events.Subscribe(webhooks.handle)
+	}
 
 	return crawler, nil
 }
@@ -140,6 +297,17 @@ func (c *DefaultCrawler) isAllowedHost(targetURL string) bool {
 		return false
 	}
 
+	// Explicit host lists take precedence over seed-host scoping
+	if len(c.config.BlockedHosts) > 0 || len(c.config.AllowedHosts) > 0 {
+		host := urlHostname(targetURL)
+		if host == "" || matchesAnyHost(c.config.BlockedHosts, host) {
+			return false
+		}
+		if len(c.config.AllowedHosts) > 0 {
+			return matchesAnyHost(c.config.AllowedHosts, host)
+		}
+	}
+
 	// If external hosts are allowed, accept any valid scheme
 	if c.config.FollowExternalHosts {
 		return true
@@ -151,11 +319,24 @@ func (c *DefaultCrawler) isAllowedHost(targetURL string) bool {
 		if targetURL == allowedHost || strings.HasPrefix(targetURL, allowedHost+"/") {
 			return true
 		}
+		if c.config.IncludeSubdomains && isSubdomainOfSeed(targetURL, allowedHost) {
+			return true
+		}
 	}
 
 	return false
 }
 
+// normalizeSeedURL percent-encodes a seed URL the same way discovered links are
+// encoded, so a seed and a link to the same page share one pages row. A URL
+// that cannot be normalized is returned unchanged and fails when fetched.
+func normalizeSeedURL(seedURL string) string {
+	if normalized, err := parser.NormalizeURL(seedURL); err == nil {
+		return normalized
+	}
+	return seedURL
+}
+
 // isAllowedScheme checks if the URL has an allowed scheme
 func (c *DefaultCrawler) isAllowedScheme(targetURL string) bool {
 	// Use configured allowed schemes, fallback to defaults if empty
@@ -180,19 +361,36 @@ func (c *DefaultCrawler) isAllowedScheme(targetURL string) bool {
 // 3. Workers compete for 'queued' items using atomic status updates
 // 4. Continue until queue is empty or limits reached
 func (c *DefaultCrawler) Start(ctx context.Context, seedURLs []string) error {
+	c.workersMutex.Lock()
 	c.ctx, c.cancel = context.WithCancel(ctx)
-	defer c.cancel()
+	cancel := c.cancel
+	c.workersMutex.Unlock()
+	defer cancel()
+	c.fetchCtx, c.cancelFetch = context.WithCancel(context.WithoutCancel(ctx))
+	defer c.cancelFetch()
 
 	// Reset rows left in 'processing' by a previous interrupted run back to
-	// 'pending'. No workers are running yet, so every 'processing' row is stale.
-	// This both re-queues interrupted URLs and prevents a stale 'processing' row
-	// from keeping HasQueuedItems() perpetually true, which would otherwise stop
+	// 'pending'. No workers are running yet, so with the default
+	// stale_processing_timeout of 0 every 'processing' row is stale. This both
+	// re-queues interrupted URLs and prevents a stale 'processing' row from
+	// keeping HasQueuedItems() perpetually true, which would otherwise stop
 	// shouldExitOnEmptyQueue from ever firing and hang every worker (issue #46
-	// review follow-up). Passing 0 treats all current 'processing' rows as stale.
-	if err := c.storage.CleanupStaleProcessing(0); err != nil {
+	// review follow-up). A timeout leaves rows claimed more recently to the
+	// other processes crawling the same database.
+	recovered, err := c.storage.CleanupStaleProcessing(c.config.StaleProcessingTimeout)
+	if err != nil {
 		slog.Error("Failed to reset stale processing rows", "error", err)
+	} else if recovered > 0 {
+		slog.Warn("Requeued pages abandoned in 'processing' by an earlier run", "count", recovered,
+			"stale_processing_timeout", c.config.StaleProcessingTimeout)
 	}
 
+	if c.config.MaxQueueSize > 0 {
+		c.frontier.load(c.storage)
+	}
+
+	c.recordRunID()
+
 	if len(seedURLs) > 0 {
 		slog.Info("Starting crawler", "seed_urls", len(seedURLs))
 
@@ -202,24 +400,51 @@ func (c *DefaultCrawler) Start(ctx context.Context, seedURLs []string) error {
 			if c.config.Limit > 0 && i >= c.config.Limit {
 				break
 			}
-			urls = append(urls, seedURL)
+			urls = append(urls, c.redactURL(c.normalizer.NormalizeURL(normalizeSeedURL(seedURL))))
 		}
 
 		err := c.storage.AddToQueue(urls)
 		if err != nil {
 			return fmt.Errorf("failed to add seed URLs to queue: %w", err)
 		}
+		c.seen.add(urls...)
+		c.recordSeedURLs(urls)
 		slog.Info("Added seed URLs to queue", "count", len(urls))
+		c.events.publish(CrawlStarted{Time: time.Now().UTC(), SeedURLs: urls})
 	} else {
 		slog.Info("Starting crawler - resuming from existing queue")
+		c.events.publish(CrawlStarted{Time: time.Now().UTC()})
+	}
+	if c.config.Auth != nil {
+		// Credentials go to the seed hosts of every run, resumed ones included
+		c.httpClient.SetAuthHosts(authHosts(c.config, c.recordedSeedURLs()))
 	}
+	c.seedFrontier()
 
-	// Step 2: Start workers after queue is populated
-	c.activeWorkers = c.config.Concurrency
-	for i := 0; i < c.config.Concurrency; i++ {
+	// Step 2: Start the result writers and then the workers
+	if c.config.WriteBufferSize > 0 {
+		c.writer = newResultWriter(c, c.config.WriteBufferSize, c.config.WriteWorkers)
+		defer c.writer.close()
+	}
+	if c.config.ChecksExternalLinks() {
+		c.checker = newExternalChecker(ctx, c, c.config.ExternalCheckers)
+	}
+	workers := c.config.Concurrency
+	if c.config.MaxConcurrency > 0 {
+		// Autoscaling starts from concurrency, kept within the allowed range
+		workers = max(min(workers, c.config.MaxConcurrency), c.config.MinConcurrency, 1)
+		c.targetWorkers = workers
+		c.nextWorkerID = workers
+	}
+	c.activeWorkers = workers
+	for i := 0; i < workers; i++ {
 		c.wg.Add(1)
 		go c.worker(i)
 	}
+	if c.config.MaxConcurrency > 0 {
+		c.wg.Add(1)
+		go c.autoscaler()
+	}
 
 	// Start stats reporter
 	c.wg.Add(1)
@@ -234,74 +459,162 @@ func (c *DefaultCrawler) Start(ctx context.Context, seedURLs []string) error {
 
 	select {
 	case <-done:
+	case <-c.ctx.Done():
+	}
+	// The last worker to stop cancels c.ctx as well, so only a cancelled
+	// caller context or Stop means the crawl was interrupted
+	if ctx.Err() != nil || c.stopped.Load() {
+		slog.Info("Crawling cancelled")
+		// Let workers finish their current item so results are written
+		// before the caller summarizes the run
+		c.drain(ctx, done)
+	} else {
+		<-done
+		// Retries look at the stored error pages, so persist every result first
+		c.flushWriter()
 		slog.Info("Crawling completed - checking for retries")
 		// After normal crawling completes, attempt retries
-		if err := c.performRetries(); err != nil {
+		if err := c.performRetries(ctx); err != nil {
 			slog.Error("Error during retry processing", "error", err)
 		}
-	case <-c.ctx.Done():
-		slog.Info("Crawling cancelled")
 	}
 
+	if c.checker != nil {
+		c.checker.close()
+	}
+	c.flushWriter()
+	c.recordStop(ctx)
+	c.events.publish(CrawlFinished{Time: time.Now().UTC(), Stats: c.GetStats()})
+	c.events.close()
+	c.webhooks.wait()
 	return nil
 }
 
-// performRetries handles retry logic for error status pages
-func (c *DefaultCrawler) performRetries() error {
-	const maxRetries = 3
+// flushWriter waits until the result writers have persisted every page
+// handed to them
+func (c *DefaultCrawler) flushWriter() {
+	if c.writer != nil {
+		c.writer.flush()
+	}
+}
 
-	retryablePages, err := c.storage.GetRetryablePages(maxRetries)
-	if err != nil {
-		return fmt.Errorf("failed to get retryable pages: %w", err)
+// drain waits until the workers, which claim no new URLs once the crawl is
+// cancelled, have finished the pages they are fetching. Their requests are
+// cut off after shutdown_timeout, or at once when the run reached its
+// deadline (timeout_total); pages cut off are requeued by their workers.
+func (c *DefaultCrawler) drain(ctx context.Context, done <-chan struct{}) {
+	timeout := c.config.ShutdownTimeout
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		timeout = 0
 	}
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+			slog.Warn("Pages in flight did not finish in time, cancelling their requests", "shutdown_timeout", timeout)
+		}
+	}
+	c.cancelFetch()
+	<-done
+}
 
-	if len(retryablePages) == 0 {
-		slog.Info("No pages available for retry")
+// performRetries fetches the pages that failed transiently again, in rounds:
+// each waits retryBackoff, then requeues the failed pages with retries left
+// and crawls them, until no such page is left or ctx is cancelled
+func (c *DefaultCrawler) performRetries(ctx context.Context) error {
+	if c.config.MaxRetries <= 0 {
 		return nil
 	}
+	// retry_count counts failures; a page is fetched once plus max_retries times
+	failureLimit := c.config.MaxRetries + 1
 
-	slog.Info("Found pages for retry", "count", len(retryablePages))
-
-	// Requeue error pages back to pending status
-	requeued, err := c.storage.RequeueErrorPages(maxRetries)
-	if err != nil {
-		return fmt.Errorf("failed to requeue error pages: %w", err)
-	}
+	for round := 1; ; round++ {
+		if c.limitSent.Load() {
+			return nil
+		}
+		retryablePages, err := c.storage.GetRetryablePages(failureLimit)
+		if err != nil {
+			return fmt.Errorf("failed to get retryable pages: %w", err)
+		}
+		if len(retryablePages) == 0 {
+			slog.Info("No pages available for retry")
+			return nil
+		}
 
-	if requeued > 0 {
-		slog.Info("Requeued error pages for retry", "count", requeued)
+		backoff := retryBackoff(c.config.RetryBackoff, round)
+		slog.Info("Found pages for retry", "count", len(retryablePages), "round", round, "backoff", backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
 
-		// Check if we have items to process after requeueing
-		hasItems, err := c.storage.HasQueuedItems()
+		// Requeue error pages back to pending status
+		requeued, err := c.storage.RequeueErrorPages(failureLimit)
 		if err != nil {
-			return fmt.Errorf("failed to check queued items: %w", err)
+			return fmt.Errorf("failed to requeue error pages: %w", err)
 		}
+		if requeued == 0 {
+			return nil
+		}
+		slog.Info("Requeued error pages for retry", "count", requeued, "round", round)
+		c.seedFrontier()
+
+		// The workers of the previous round cancelled c.ctx when they stopped.
+		// Stop and the control API read the pool while it is replaced.
+		c.workersMutex.Lock()
+		c.ctx, c.cancel = context.WithCancel(ctx)
+		c.wg = sync.WaitGroup{} // Reset wait group
+		c.emptySent.Store(false)
+		c.activeWorkers = c.config.Concurrency
+		c.targetWorkers = 0 // Retries run on a fixed pool
+		for i := 0; i < c.config.Concurrency; i++ {
+			c.wg.Add(1)
+			go c.worker(i)
+		}
+		roundCtx, cancel := c.ctx, c.cancel
+		c.workersMutex.Unlock()
 
-		if hasItems {
-			slog.Info("Starting retry processing")
-			// Start workers again for retry processing
-			c.wg = sync.WaitGroup{} // Reset wait group
-			c.activeWorkers = c.config.Concurrency
-			for i := 0; i < c.config.Concurrency; i++ {
-				c.wg.Add(1)
-				go c.worker(i)
-			}
-
-			// Wait for retry completion
+		done := make(chan struct{})
+		go func() {
 			c.wg.Wait()
-			slog.Info("Retry processing completed")
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-roundCtx.Done():
 		}
+		if ctx.Err() != nil || c.stopped.Load() {
+			slog.Info("Retries cancelled")
+			c.drain(ctx, done)
+			return nil
+		}
+		<-done
+		cancel()
+		// Results must be stored before the next round looks for failures
+		c.flushWriter()
+		slog.Info("Retry processing completed", "round", round)
 	}
-
-	return nil
 }
 
 // Stop stops the crawling process
 func (c *DefaultCrawler) Stop() error {
-	if c.cancel != nil {
-		c.cancel()
+	c.stopped.Store(true)
+	c.workersMutex.Lock()
+	cancel := c.cancel
+	c.workersMutex.Unlock()
+	if cancel != nil {
+		cancel()
 	}
 	c.httpClient.Close()
+	if c.renderer != nil {
+		_ = c.renderer.Close()
+	}
 	return nil
 }
 
@@ -312,6 +625,7 @@ func (c *DefaultCrawler) GetStats() CrawlStats {
 
 	stats := c.stats
 	stats.Duration = time.Since(stats.StartTime)
+	stats.URLsDropped = c.frontier.droppedCount()
 	return stats
 }
 
@@ -322,10 +636,19 @@ func (c *DefaultCrawler) GetStats() CrawlStats {
 // 3. No queued items available (SELECT returns empty result)
 func (c *DefaultCrawler) worker(id int) {
 	defer c.wg.Done()
-	defer c.handleWorkerShutdown(id)
+	retired := false
+	defer func() {
+		if !retired {
+			c.handleWorkerShutdown(id)
+		}
+	}()
 
 	slog.Debug("Worker started", "worker_id", id)
 
+	// Items claimed with queue_batch_size but not yet processed
+	var claimed []URLItem
+	defer func() { c.releaseClaimed(id, claimed) }()
+
 	for {
 		select {
 		case <-c.ctx.Done():
@@ -334,8 +657,15 @@ func (c *DefaultCrawler) worker(id int) {
 			if c.shouldStopWorker(id) {
 				return
 			}
+			if c.retireWorker(id) {
+				retired = true
+				return
+			}
+			if !c.waitWhilePaused(id, &claimed) {
+				return
+			}
 
-			item, err := c.storage.GetNextFromQueue()
+			item, err := c.nextItem(&claimed)
 			if err != nil {
 				slog.Error("Worker failed to get from queue", "worker_id", id, "error", err)
 				c.workerSleep()
@@ -345,6 +675,7 @@ func (c *DefaultCrawler) worker(id int) {
 			if item == nil {
 				if c.shouldExitOnEmptyQueue() {
 					slog.Debug("Worker no more items in queue, exiting", "worker_id", id)
+					c.queueEmptied()
 					return
 				}
 				c.workerSleep()
@@ -356,6 +687,64 @@ func (c *DefaultCrawler) worker(id int) {
 	}
 }
 
+// nextItem returns the next queue item for a worker. With queue_batch_size
+// above 1 the worker claims that many items in one storage call and hands
+// them out from its claimed buffer, so many workers contend for the database
+// less often.
+func (c *DefaultCrawler) nextItem(claimed *[]URLItem) (*URLItem, error) {
+	if c.config.QueueBatchSize <= 1 {
+		item, err := c.storage.GetNextFromQueue()
+		if item != nil && c.config.MaxQueueSize > 0 {
+			c.frontier.claimed(1)
+		}
+		return item, err
+	}
+
+	if len(*claimed) == 0 {
+		items, err := c.storage.GetNextBatchFromQueue(c.config.QueueBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if c.config.MaxQueueSize > 0 {
+			c.frontier.claimed(len(items))
+		}
+		*claimed = items
+	}
+	if len(*claimed) == 0 {
+		return nil, nil
+	}
+	item := (*claimed)[0]
+	*claimed = (*claimed)[1:]
+	return &item, nil
+}
+
+// releaseClaimed returns items a stopping worker claimed but did not finish
+// to 'pending', so the limit or a shutdown does not strand them in
+// 'processing' until the next run's stale-processing cleanup
+func (c *DefaultCrawler) releaseClaimed(id int, claimed []URLItem) {
+	released := 0
+	for _, item := range claimed {
+		if err := c.storage.UpdatePageStatus(item.ID, "pending"); err != nil {
+			slog.Error("Worker failed to release claimed URL", "worker_id", id, "url", item.URL, "error", err)
+			continue
+		}
+		released++
+	}
+	if c.config.MaxQueueSize > 0 {
+		c.frontier.released(released)
+	}
+}
+
+// seedFrontier reads the pending count max_queue_size is enforced against
+func (c *DefaultCrawler) seedFrontier() {
+	if c.config.MaxQueueSize <= 0 {
+		return
+	}
+	if err := c.frontier.seed(c.storage); err != nil {
+		slog.Error("Failed to count pending URLs for max_queue_size", "error", err)
+	}
+}
+
 // handleWorkerShutdown handles worker cleanup when shutting down
 func (c *DefaultCrawler) handleWorkerShutdown(id int) {
 	c.workersMutex.Lock()
@@ -371,10 +760,12 @@ func (c *DefaultCrawler) handleWorkerShutdown(id int) {
 // shouldStopWorker checks if worker should stop due to limit reached
 func (c *DefaultCrawler) shouldStopWorker(id int) bool {
 	c.statsMutex.RLock()
-	defer c.statsMutex.RUnlock()
+	pagesCrawled := c.stats.PagesCrawled
+	c.statsMutex.RUnlock()
 
-	if c.config.Limit > 0 && c.stats.PagesCrawled >= c.config.Limit {
+	if c.config.Limit > 0 && pagesCrawled >= c.config.Limit {
 		slog.Info("Worker reached limit", "worker_id", id)
+		c.limitReached(pagesCrawled)
 		return true
 	}
 	return false
@@ -400,41 +791,84 @@ func (c *DefaultCrawler) shouldExitOnEmptyQueue() bool {
 
 // workerSleep applies the configured delay between requests
 func (c *DefaultCrawler) workerSleep() {
-	time.Sleep(time.Duration(c.config.RequestDelay * float64(time.Second)))
+	delay := time.Duration(c.config.RequestDelay * float64(time.Second))
+	time.Sleep(applyJitter(delay, c.config.RequestJitter/100))
 }
 
 // processURLItem processes a single URL item from the queue
 func (c *DefaultCrawler) processURLItem(id int, item *URLItem) {
+	// Requests run on fetchCtx so that a cancelled crawl can let them finish
+	ctx, span := tracer.Start(c.fetchCtx, "process page", trace.WithAttributes(
+		attribute.String("url.full", item.URL),
+		attribute.Int("linktadoru.worker_id", id),
+	))
+	defer span.End()
+
+	// Skip URLs from infinite URL spaces without fetching them
+	if trap := c.traps.Check(item.URL); trap != "" {
+		slog.Info("Worker skipped URL in spider trap", "worker_id", id, "url", item.URL, "rule", trap)
+		if err := c.storage.SavePageSkipped(item.ID, "trap_detected", trap); err != nil {
+			slog.Error("Worker failed to save trap skip", "worker_id", id, "url", item.URL, "error", err)
+		}
+		return
+	}
+
 	// Check robots.txt
 	if !c.shouldProcessURL(id, item) {
 		return
 	}
 
-	// Rate limiting
-	if err := c.rateLimiter.Wait(c.ctx, item.URL); err != nil {
-		slog.Error("Worker rate limiting error", "worker_id", id, "error", err)
+	// Rate limiting; a cancelled crawl starts no new requests
+	waitStart := time.Now()
+	err := c.rateLimiter.Wait(c.ctx, item.URL)
+	c.rateLimitWait.Add(int64(time.Since(waitStart)))
+	if err != nil {
 		// A non-cancellation error here (e.g. a malformed URL that fails to parse)
 		// would otherwise leave the row in 'processing' forever and hang the
 		// HasQueuedItems()-based worker exit. Mark it terminal. On context
-		// cancellation we leave the row alone — the run is ending and a later
-		// resume's CleanupStaleProcessing resets it back to 'pending'.
-		if c.ctx.Err() == nil {
-			if serr := c.storage.SavePageError(item.ID, "rate_limit_error", err.Error()); serr != nil {
-				slog.Error("Worker failed to mark rate-limit error", "worker_id", id, "url", item.URL, "error", serr)
-			}
-			c.incrementErrorCount()
+		// cancellation the page was never fetched, so it goes back to 'pending'
+		// for the next run.
+		if c.ctx.Err() != nil {
+			c.releaseClaimed(id, []URLItem{*item})
+			return
+		}
+		slog.Error("Worker rate limiting error", "worker_id", id, "error", err)
+		if serr := c.storage.SavePageError(item.ID, "rate_limit_error", err.Error()); serr != nil {
+			slog.Error("Worker failed to mark rate-limit error", "worker_id", id, "url", item.URL, "error", serr)
 		}
+		c.incrementErrorCount()
+		c.crawlFailed(item, "rate_limit_error", err.Error())
 		return
 	}
 
 	// Process the page
-	result, err := c.processor.Process(c.ctx, item.URL)
+	result, err := c.processor.Process(ctx, c.realURL(item.URL))
+	if c.fetchCtx.Err() != nil {
+		// The crawl is shutting down and cut the request short; requeue the
+		// page instead of recording the cancellation as its error
+		c.releaseClaimed(id, []URLItem{*item})
+		return
+	}
 	if err != nil {
 		c.handleProcessingError(id, item, err)
 		return
 	}
+	// A 5xx answer is fetched again while the page has retries left
+	if retry := c.serverErrorRetry(item, result); retry != nil {
+		result = retry
+	}
 
-	c.handleProcessingResult(id, item, result)
+	// Strip ignored query parameters and apply the script's URL rewrites.
+	// Personal data is then scrubbed from everything stored, queued and
+	// published; redacted URLs are the keys pages are queued under.
+	c.normalizer.Apply(result)
+	c.rewriteLinks(result)
+	if c.redactor != nil {
+		result = c.redactor.Redacted(result)
+	}
+	c.pageCrawled(result)
+
+	c.handleProcessingResult(ctx, id, item, result)
 }
 
 // shouldProcessURL checks if URL should be processed (robots.txt check)
@@ -443,7 +877,7 @@ func (c *DefaultCrawler) shouldProcessURL(id int, item *URLItem) bool {
 		return true
 	}
 
-	allowed, err := c.robotsParser.IsAllowed(c.ctx, item.URL, c.config.UserAgent)
+	allowed, err := c.robotsParser.IsAllowed(c.ctx, c.realURL(item.URL), c.config.UserAgent)
 	if err != nil {
 		slog.Warn("Worker robots.txt check failed", "worker_id", id, "url", item.URL, "error", err)
 	}
@@ -461,33 +895,110 @@ func (c *DefaultCrawler) shouldProcessURL(id int, item *URLItem) bool {
 // handleProcessingError handles errors during page processing
 func (c *DefaultCrawler) handleProcessingError(id int, item *URLItem, err error) {
 	slog.Error("Worker failed to process URL", "worker_id", id, "url", item.URL, "error", err)
-	if saveErr := c.storage.SavePageError(item.ID, "processing_error", err.Error()); saveErr != nil {
+	errMsg := err.Error()
+	if c.redactor != nil {
+		errMsg = c.redactor.RedactText(errMsg)
+	}
+	if saveErr := c.storage.SavePageError(item.ID, "processing_error", errMsg); saveErr != nil {
 		slog.Error("Worker failed to save processing error", "worker_id", id, "error", saveErr)
 	}
 	c.incrementErrorCount()
+	c.crawlFailed(item, "processing_error", errMsg)
 	c.workerSleep()
 }
 
 // handleProcessingResult handles successful page processing results
-func (c *DefaultCrawler) handleProcessingResult(id int, item *URLItem, result *PageResult) {
-	// Save links and queue newly discovered URLs BEFORE marking this page
-	// completed. While this runs, item.ID is still 'processing', so
-	// HasQueuedItems() stays true across the whole window — an idle sibling
-	// worker cannot observe an empty queue and exit early before the freshly
-	// discovered links are promoted to 'pending'. Reversing this order would
-	// open a brief pending+processing==0 window on sparse graphs (no data loss,
-	// but lost parallelism).
-	if err := c.storage.SaveLinks(result.Links); err != nil {
+func (c *DefaultCrawler) handleProcessingResult(ctx context.Context, id int, item *URLItem, result *PageResult) {
+	// A deliberately skipped page has no links or content to save
+	if result.Skip != nil {
+		slog.Info("Worker skipped URL", "worker_id", id, "url", item.URL, "reason", result.Skip.Reason)
+		if err := c.storage.SavePageSkipped(item.ID, result.Skip.Reason, result.Skip.Message); err != nil {
+			slog.Error("Worker failed to save skip", "worker_id", id, "url", item.URL, "error", err)
+		}
+		c.workerSleep()
+		return
+	}
+
+	if c.writer != nil {
+		// Queue discovered URLs now and leave the writes to the result
+		// writers. The page is counted when handed off so limit stops the
+		// workers on time; a failed write is logged by the writer.
+		c.queueLinks(id, item, result)
+		c.writer.submit(id, item, result)
+		if result.Page != nil {
+			c.incrementCrawledCount()
+		} else {
+			c.incrementErrorCount()
+		}
+	} else {
+		// Save links and queue newly discovered URLs BEFORE marking this page
+		// completed. While this runs, item.ID is still 'processing', so
+		// HasQueuedItems() stays true across the whole window — an idle sibling
+		// worker cannot observe an empty queue and exit early before the freshly
+		// discovered links are promoted to 'pending'. Reversing this order would
+		// open a brief pending+processing==0 window on sparse graphs (no data loss,
+		// but lost parallelism).
+		c.saveLinks(ctx, id, item, result)
+		c.queueLinks(id, item, result)
+		if c.savePage(ctx, id, item, result) {
+			c.incrementCrawledCount()
+		} else if result.Page == nil {
+			c.incrementErrorCount()
+		}
+	}
+
+	// Log processing result
+	c.logProcessingResult(id, item.URL, result)
+	c.publishResult(item, result)
+
+	// Delay after processing
+	c.workerSleep()
+}
+
+// saveLinks stores the links found on a processed page
+func (c *DefaultCrawler) saveLinks(ctx context.Context, id int, item *URLItem, result *PageResult) {
+	_, span := tracer.Start(ctx, "storage.SaveLinks", trace.WithAttributes(attribute.Int("linktadoru.links", len(result.Links))))
+	err := c.storage.SaveLinks(result.Links)
+	endSpan(span, err)
+	if err != nil {
 		slog.Error("Worker failed to save links", "worker_id", id, "url", item.URL, "error", err)
 	}
-	c.processNewURLs(id, result.Links, item.URL)
+}
 
-	// Move this page out of 'processing' to a terminal state.
+// queueLinks queues the URLs a processed page leads to and checks its
+// out-of-scope links
+func (c *DefaultCrawler) queueLinks(id int, item *URLItem, result *PageResult) {
+	var canonical *LinkData
+	if c.config.FollowCanonical {
+		canonical = canonicalLink(result.Page)
+	}
+	switch {
+	case canonical != nil:
+		// A non-canonical duplicate defers to its canonical page, whose links
+		// are queued when it is crawled
+		slog.Debug("Queueing canonical instead of links", "worker_id", id, "url", item.URL, "canonical", canonical.TargetURL)
+		c.processNewURLs(id, []*LinkData{canonical}, item.URL)
+	case result.NoFollow:
+		slog.Debug("Not queueing links of nofollow page", "worker_id", id, "url", item.URL)
+	default:
+		c.processNewURLs(id, result.Links, item.URL)
+	}
+	c.checkExternalLinks(result.Links)
+}
+
+// savePage moves a processed page out of 'processing' to a terminal state
+// and records its error details. It reports whether a page was saved as
+// completed.
+func (c *DefaultCrawler) savePage(ctx context.Context, id int, item *URLItem, result *PageResult) bool {
+	saved := false
 	if result.Page != nil {
-		if err := c.storage.SavePageResult(item.ID, result.Page); err != nil {
+		_, span := tracer.Start(ctx, "storage.SavePageResult")
+		err := c.storage.SavePageResult(item.ID, result.Page)
+		endSpan(span, err)
+		if err != nil {
 			slog.Error("Worker failed to save page", "worker_id", id, "url", item.URL, "error", err)
 		} else {
-			c.incrementCrawledCount()
+			saved = true
 		}
 	} else {
 		// No page was produced — e.g. a transport/network failure that the
@@ -500,47 +1011,122 @@ func (c *DefaultCrawler) handleProcessingResult(id int, item *URLItem, result *P
 		if result.Error != nil {
 			errType, errMsg = result.Error.ErrorType, result.Error.ErrorMessage
 		}
-		if err := c.storage.SavePageError(item.ID, errType, errMsg); err != nil {
+		_, span := tracer.Start(ctx, "storage.SavePageError")
+		err := c.storage.SavePageError(item.ID, errType, errMsg)
+		endSpan(span, err)
+		if err != nil {
 			slog.Error("Worker failed to mark page error", "worker_id", id, "url", item.URL, "error", err)
 		}
-		c.incrementErrorCount()
 	}
 
-	// Save error details to the crawl_errors table (separate from the pages row).
+	c.saveErrorDetails(id, item, result)
+	return saved
+}
+
+// saveErrorDetails saves a result's error to the crawl_errors table
+// (separate from the pages row)
+func (c *DefaultCrawler) saveErrorDetails(id int, item *URLItem, result *PageResult) {
 	if result.Error != nil {
 		if err := c.storage.SaveError(result.Error); err != nil {
 			slog.Error("Worker failed to save error", "worker_id", id, "url", item.URL, "error", err)
 		}
 	}
-
-	// Log processing result
-	c.logProcessingResult(id, item.URL, result)
-
-	// Delay after processing
-	c.workerSleep()
 }
 
 // processNewURLs collects and queues new URLs from links
 func (c *DefaultCrawler) processNewURLs(id int, links []*LinkData, sourceURL string) {
 	var newURLs []string
 	for _, link := range links {
-		if link.LinkType != "internal" || !c.shouldCrawlURL(link.TargetURL) {
+		if !c.followsLink(link) || c.seen.has(link.TargetURL) {
+			continue
+		}
+		if link.LinkType == LinkTypePagination && !c.pagination.follow(sourceURL, link.TargetURL, c.config.PaginationDepth) {
+			slog.Debug("Pagination depth reached", "worker_id", id, "url", link.TargetURL, "pagination_depth", c.config.PaginationDepth)
 			continue
 		}
 		// Queue the URL when it is brand new, or when it currently exists only as
 		// a 'discovered' link-graph node (created by SaveLinks). AddToQueue inserts
 		// or promotes it to 'pending'. URLs already pending/processing/completed/
 		// skipped/error are left untouched.
-		if status, exists := c.storage.GetURLStatus(link.TargetURL); !exists || status == "discovered" {
+		status, exists := c.storage.GetURLStatus(link.TargetURL)
+		if !exists || status == "discovered" {
 			newURLs = append(newURLs, link.TargetURL)
+		} else {
+			c.seen.add(link.TargetURL)
 		}
 	}
 
 	if len(newURLs) > 0 {
-		if err := c.storage.AddToQueue(newURLs); err != nil {
+		if err := c.frontier.admit(c.storage, newURLs, c.config.MaxQueueSize); err != nil {
 			slog.Error("Worker failed to add URLs to queue", "worker_id", id, "error", err)
+		} else {
+			// Every URL was queued or, under max_queue_size, dropped. Dropped
+			// URLs are cached too, so rediscovering them neither queues them
+			// later nor counts them as dropped again.
+			c.seen.add(newURLs...)
+		}
+	}
+}
+
+// followsLink reports whether a link's target may be queued for crawling.
+// External links are only followed when allowed_hosts or include_subdomains
+// widen the scope beyond the page's own host. Asset links are followed with
+// crawl_assets when their host is in scope. rel="nofollow" links are not
+// followed with respect_nofollow.
+func (c *DefaultCrawler) followsLink(link *LinkData) bool {
+	if link.LinkType == LinkTypeAsset {
+		return c.config.CrawlAssets && c.shouldCrawlURL(link.TargetURL)
+	}
+	if c.config.RespectNofollow && hasNofollowRel(link.RelAttribute) {
+		return false
+	}
+	if link.LinkType != "internal" && link.LinkType != LinkTypePagination && !scopeSpansHosts(c.config) {
+		return false
+	}
+	return c.shouldCrawlURL(link.TargetURL)
+}
+
+// hasNofollowRel reports whether a rel attribute contains the nofollow token
+func hasNofollowRel(rel string) bool {
+	for _, token := range strings.Fields(strings.ToLower(rel)) {
+		if token == "nofollow" {
+			return true
 		}
 	}
+	return false
+}
+
+// checkExternalLinks hands external links that will not be crawled to the
+// external checkers, which verify them with a HEAD request (check_external:
+// head) and store their status codes. Assets on out-of-scope hosts, such as
+// a CDN, are verified the same way. Each URL is checked once per database
+// and blocked hosts are never contacted.
+func (c *DefaultCrawler) checkExternalLinks(links []*LinkData) {
+	if c.checker == nil {
+		return
+	}
+
+	for _, link := range links {
+		if c.ctx.Err() != nil {
+			return
+		}
+		external := link.LinkType == "external" || (link.LinkType == LinkTypeAsset && !c.isAllowedHost(link.TargetURL))
+		if !external || c.followsLink(link) || !c.isAllowedScheme(link.TargetURL) {
+			continue
+		}
+		if matchesAnyHost(c.config.BlockedHosts, urlHostname(link.TargetURL)) {
+			continue
+		}
+		if _, claimed := c.checked.LoadOrStore(link.TargetURL, struct{}{}); claimed {
+			continue
+		}
+		// Link targets are already redacted, the key checks are stored under;
+		// the checker requests the real URL
+		if c.storage.HasExternalCheck(link.TargetURL) {
+			continue
+		}
+		c.checker.submit(c.realURL(link.TargetURL))
+	}
 }
 
 // logProcessingResult logs the result of URL processing
@@ -579,34 +1165,35 @@ func (c *DefaultCrawler) statsReporter() {
 
 // Helper methods
 
-// shouldCrawlURL determines if a URL should be crawled based on include/exclude patterns
+// shouldCrawlURL determines if a URL should be crawled based on host scoping,
+// then include/exclude patterns, then the script
 func (c *DefaultCrawler) shouldCrawlURL(urlStr string) bool {
 	// First check if the host is allowed for crawling
 	if !c.isAllowedHost(urlStr) {
 		return false
 	}
 
-	// If include patterns are specified, URL must match at least one
-	if len(c.config.IncludePatterns) > 0 {
-		matched := false
-		for _, pattern := range c.config.IncludePatterns {
-			if m, _ := regexp.MatchString(pattern, urlStr); m {
-				matched = true
-				break
-			}
-		}
-		if !matched {
-			return false
-		}
+	// URL must match an include pattern (when set) and no exclude pattern
+	if !c.patterns.allows(urlStr) {
+		return false
 	}
 
-	// Check exclude patterns - URL must not match any
-	for _, pattern := range c.config.ExcludePatterns {
-		if matched, _ := regexp.MatchString(pattern, urlStr); matched {
+	// Then the Go filters of an embedding program, in order
+	if len(c.config.URLFilters) > 0 {
+		u, err := url.Parse(urlStr)
+		if err != nil || !config.AllURLFilters(c.config.URLFilters...)(u) {
 			return false
 		}
 	}
 
+	// Finally the script's should_crawl, if any, decides
+	if c.script != nil {
+		crawl, err := c.script.ShouldCrawl(urlStr)
+		if err != nil {
+			slog.Warn("Script failed to decide on URL; not crawling it", "url", urlStr, "error", err)
+		}
+		return crawl
+	}
 	return true
 }
 
@@ -625,3 +1212,21 @@ func (c *DefaultCrawler) incrementErrorCount() {
 	defer c.statsMutex.Unlock()
 	c.stats.ErrorCount++
 }
+
+// redactURL returns the key a URL is queued and stored under: the URL with
+// the redaction.query_params values replaced, or the URL itself without
+// redaction rules
+func (c *DefaultCrawler) redactURL(u string) string {
+	if c.redactor == nil {
+		return u
+	}
+	return c.redactor.RedactURL(u)
+}
+
+// realURL returns the URL to request for a queued or stored URL key
+func (c *DefaultCrawler) realURL(u string) string {
+	if c.redactor == nil {
+		return u
+	}
+	return c.redactor.RealURL(u)
+}
@@ -0,0 +1,157 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DNSCache resolves hostnames in-process, caching answers for a fixed TTL so
+// large crawls do not hammer the system resolver. It can query a specific DNS
+// server and apply hosts-file-style overrides. Concurrent misses for one host
+// share a single query.
+type DNSCache struct {
+	resolver  *net.Resolver
+	ttl       time.Duration
+	overrides map[string]string // Lower-cased host -> IP address
+	entries   map[string]dnsEntry
+	mu        sync.RWMutex
+	inflight  singleflight.Group // Queries in progress, keyed by lower-cased host
+}
+
+// dnsEntry is a cached lookup result
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// NewDNSCache creates a DNS cache. A zero ttl disables caching (lookups still
+// go through the configured resolver and overrides). resolverAddr is the
+// "host:port" of a DNS server to query instead of the system resolver; empty
+// uses the system resolver.
+func NewDNSCache(ttl time.Duration, resolverAddr string, overrides map[string]string) *DNSCache {
+	resolver := net.DefaultResolver
+	if resolverAddr != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, resolverAddr)
+			},
+		}
+	}
+
+	normalized := make(map[string]string, len(overrides))
+	for host, ip := range overrides {
+		normalized[strings.ToLower(host)] = ip
+	}
+
+	return &DNSCache{
+		resolver:  resolver,
+		ttl:       ttl,
+		overrides: normalized,
+		entries:   make(map[string]dnsEntry),
+	}
+}
+
+// LookupHost returns the addresses for host, from the overrides, the cache or
+// the resolver in that order. It reports the lookup to any httptrace.ClientTrace
+// in ctx so HTTPMetrics.DNSLookup reflects the time actually spent resolving.
+func (d *DNSCache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	trace := httptrace.ContextClientTrace(ctx)
+	if trace != nil && trace.DNSStart != nil {
+		trace.DNSStart(httptrace.DNSStartInfo{Host: host})
+	}
+
+	addrs, err := d.lookup(ctx, strings.ToLower(host))
+
+	if trace != nil && trace.DNSDone != nil {
+		info := httptrace.DNSDoneInfo{Err: err}
+		for _, addr := range addrs {
+			if ip := net.ParseIP(addr); ip != nil {
+				info.Addrs = append(info.Addrs, net.IPAddr{IP: ip})
+			}
+		}
+		trace.DNSDone(info)
+	}
+	return addrs, err
+}
+
+// lookup resolves a lower-cased host without tracing
+func (d *DNSCache) lookup(ctx context.Context, host string) ([]string, error) {
+	if ip, ok := d.overrides[host]; ok {
+		return []string{ip}, nil
+	}
+
+	if d.ttl > 0 {
+		d.mu.RLock()
+		entry, ok := d.entries[host]
+		d.mu.RUnlock()
+		if ok && time.Now().Before(entry.expires) {
+			return entry.addrs, nil
+		}
+	}
+
+	// The shared query must not fail for every caller when the first one
+	// gives up, so it ignores cancellation; each caller still stops waiting
+	// when its own ctx is done
+	result := d.inflight.DoChan(host, func() (any, error) {
+		addrs, err := d.resolver.LookupHost(context.WithoutCancel(ctx), host)
+		if err != nil {
+			return nil, err
+		}
+		if d.ttl > 0 {
+			d.mu.Lock()
+			d.entries[host] = dnsEntry{addrs: addrs, expires: time.Now().Add(d.ttl)}
+			d.mu.Unlock()
+		}
+		return addrs, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-result:
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		return r.Val.([]string), nil
+	}
+}
+
+// DialContext returns a dial function for http.Transport that resolves
+// hostnames through the cache and tries each address until one connects
+func (d *DNSCache) DialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		ips, err := d.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var lastErr error
+		for _, ip := range ips {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no addresses found for %s", host)
+		}
+		return nil, lastErr
+	}
+}
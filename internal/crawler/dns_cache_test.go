@@ -0,0 +1,127 @@
+package crawler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestDNSCacheOverrides(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+
+	client := NewHTTPClient("Test-Crawler/1.0", 5*time.Second)
+	defer client.Close()
+	client.SetDNSCache(NewDNSCache(0, "", map[string]string{"Staging.Example.invalid": "127.0.0.1"}))
+
+	resp, err := client.Get(context.Background(), "http://staging.example.invalid:"+serverURL.Port()+"/")
+	if err != nil {
+		t.Fatalf("Expected override to resolve host: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status code 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestDNSCacheTTL(t *testing.T) {
+	cache := NewDNSCache(time.Minute, "", nil)
+
+	addrs, err := cache.LookupHost(context.Background(), "localhost")
+	if err != nil {
+		t.Fatalf("Failed to resolve localhost: %v", err)
+	}
+	if len(addrs) == 0 {
+		t.Fatal("Expected at least one address for localhost")
+	}
+
+	// A cached entry is served without querying the resolver again
+	cache.entries["cached.example.invalid"] = dnsEntry{addrs: []string{"192.0.2.1"}, expires: time.Now().Add(time.Minute)}
+	addrs, err = cache.LookupHost(context.Background(), "cached.example.invalid")
+	if err != nil || len(addrs) != 1 || addrs[0] != "192.0.2.1" {
+		t.Errorf("Expected cached address, got %v (err %v)", addrs, err)
+	}
+
+	// An expired entry is resolved again (and fails for an invalid host)
+	cache.entries["cached.example.invalid"] = dnsEntry{addrs: []string{"192.0.2.1"}, expires: time.Now().Add(-time.Second)}
+	if _, err := cache.LookupHost(context.Background(), "cached.example.invalid"); err == nil {
+		t.Error("Expected expired entry to be looked up again")
+	}
+}
+
+func TestDNSCacheDisabledDoesNotStore(t *testing.T) {
+	cache := NewDNSCache(0, "", nil)
+	if _, err := cache.LookupHost(context.Background(), "localhost"); err != nil {
+		t.Fatalf("Failed to resolve localhost: %v", err)
+	}
+	if len(cache.entries) != 0 {
+		t.Errorf("Expected no cached entries with zero TTL, got %d", len(cache.entries))
+	}
+}
+
+func TestDNSCacheSharesConcurrentMisses(t *testing.T) {
+	// A DNS server answering every A query slowly with 192.0.2.1
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	var queries atomic.Int32
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if query.Unpack(buf[:n]) != nil || len(query.Questions) == 0 {
+				continue
+			}
+			reply := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, Authoritative: true},
+				Questions: query.Questions,
+			}
+			if query.Questions[0].Type == dnsmessage.TypeA {
+				queries.Add(1)
+				time.Sleep(50 * time.Millisecond)
+				reply.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: query.Questions[0].Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+					Body:   &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}},
+				}}
+			}
+			if packed, err := reply.Pack(); err == nil {
+				_, _ = conn.WriteTo(packed, addr)
+			}
+		}
+	}()
+
+	cache := NewDNSCache(time.Minute, conn.LocalAddr().String(), nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			addrs, err := cache.LookupHost(context.Background(), "shared.example.invalid.")
+			if err != nil || len(addrs) != 1 || addrs[0] != "192.0.2.1" {
+				t.Errorf("Expected 192.0.2.1, got %v (err %v)", addrs, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := queries.Load(); n != 1 {
+		t.Errorf("Expected concurrent lookups to share 1 query, got %d", n)
+	}
+}
@@ -0,0 +1,182 @@
+package crawler
+
+import (
+	"sync"
+	"time"
+)
+
+// Event names, as returned by Event.EventName
+const (
+	EventCrawlStarted  = "crawl_started"
+	EventPageCompleted = "page_completed"
+	EventPageErrored   = "page_errored"
+	EventLinkFound     = "link_found"
+	EventQueueEmpty    = "queue_empty"
+	EventLimitReached  = "limit_reached"
+	EventCrawlFinished = "crawl_finished"
+)
+
+// Event is something that happened during a crawl: one of CrawlStarted,
+// PageCompleted, PageErrored, LinkFound, QueueEmpty, LimitReached and
+// CrawlFinished. Subscribers tell them apart with a type switch.
+type Event interface {
+	EventName() string
+}
+
+// CrawlStarted is published when the seeds are queued, before any page is fetched
+type CrawlStarted struct {
+	Time     time.Time
+	SeedURLs []string // Empty when the run resumes an existing queue
+}
+
+// PageCompleted is published for every fetched page, whatever its HTTP
+// status, once its result is saved or handed to the result writers
+type PageCompleted struct {
+	Time time.Time
+	URL  string
+	Page *PageData
+}
+
+// PageErrored is published for every page that could not be fetched or processed
+type PageErrored struct {
+	Time  time.Time
+	URL   string
+	Error *CrawlError
+}
+
+// LinkFound is published for every link of a fetched page the link hook
+// kept, before the page's PageCompleted
+type LinkFound struct {
+	Time time.Time
+	Link *LinkData
+}
+
+// QueueEmpty is published once when the workers find no URL left to crawl;
+// again after retries emptied a requeued queue
+type QueueEmpty struct {
+	Time         time.Time
+	PagesCrawled int
+}
+
+// LimitReached is published once when the crawl reaches its page limit
+type LimitReached struct {
+	Time         time.Time
+	Limit        int
+	PagesCrawled int
+}
+
+// CrawlFinished is published last, with the totals of the run
+type CrawlFinished struct {
+	Time  time.Time
+	Stats CrawlStats
+}
+
+func (CrawlStarted) EventName() string  { return EventCrawlStarted }
+func (PageCompleted) EventName() string { return EventPageCompleted }
+func (PageErrored) EventName() string   { return EventPageErrored }
+func (LinkFound) EventName() string     { return EventLinkFound }
+func (QueueEmpty) EventName() string    { return EventQueueEmpty }
+func (LimitReached) EventName() string  { return EventLimitReached }
+func (CrawlFinished) EventName() string { return EventCrawlFinished }
+
+// EventBus delivers the events of a crawl to its subscribers, in the order
+// each worker publishes them. Integrations such as webhooks subscribe to it
+// instead of being wired into the workers.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers []func(Event)
+	channels    []chan Event
+	closed      bool
+}
+
+// Subscribe calls fn with every event. fn runs on the goroutine that
+// published the event, usually a worker, so it must be safe for concurrent
+// use and return quickly. Subscribe before Start.
+func (b *EventBus) Subscribe(fn func(Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, fn)
+}
+
+// Channel returns a channel receiving every event, with room for buffer
+// events. The publisher waits while the channel is full, so the receiver
+// must keep draining it; it is closed after CrawlFinished. Subscribe before
+// Start.
+func (b *EventBus) Channel(buffer int) <-chan Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan Event, buffer)
+	if b.closed {
+		close(ch)
+		return ch
+	}
+	b.channels = append(b.channels, ch)
+	return ch
+}
+
+// publish delivers event to the subscribers and then the channels
+func (b *EventBus) publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, fn := range b.subscribers {
+		fn(event)
+	}
+	if b.closed {
+		return
+	}
+	for _, ch := range b.channels {
+		ch <- event
+	}
+}
+
+// close closes the channels once the crawl has published its last event
+func (b *EventBus) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for _, ch := range b.channels {
+		close(ch)
+	}
+}
+
+// Events returns the bus the crawler publishes its events on
+func (c *DefaultCrawler) Events() *EventBus {
+	return c.events
+}
+
+// publishResult publishes the events of a processed page: its links and
+// PageCompleted, or PageErrored when it could not be fetched
+func (c *DefaultCrawler) publishResult(item *URLItem, result *PageResult) {
+	now := time.Now().UTC()
+	if result.Page == nil {
+		crawlErr := result.Error
+		if crawlErr == nil {
+			crawlErr = &CrawlError{URL: item.URL, ErrorType: "processing_error", ErrorMessage: "no page result", OccurredAt: now}
+		}
+		c.events.publish(PageErrored{Time: now, URL: item.URL, Error: crawlErr})
+		return
+	}
+	for _, link := range result.Links {
+		c.events.publish(LinkFound{Time: now, Link: link})
+	}
+	c.events.publish(PageCompleted{Time: now, URL: item.URL, Page: result.Page})
+}
+
+// queueEmptied publishes QueueEmpty for the first worker to find the queue empty
+func (c *DefaultCrawler) queueEmptied() {
+	if c.emptySent.Swap(true) {
+		return
+	}
+	c.events.publish(QueueEmpty{Time: time.Now().UTC(), PagesCrawled: c.GetStats().PagesCrawled})
+}
+
+// limitReached publishes LimitReached for the first worker to stop at the limit
+func (c *DefaultCrawler) limitReached(pagesCrawled int) {
+	if c.limitSent.Swap(true) {
+		return
+	}
+	c.events.publish(LimitReached{Time: time.Now().UTC(), Limit: c.config.Limit, PagesCrawled: pagesCrawled})
+}
@@ -0,0 +1,148 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage/memory"
+)
+
+// The crawl publishes typed events to subscribers and channels
+func TestEventBus(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<a href="/a">A</a><a href="/broken">B</a>`))
+		case "/a":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<title>A</title>`))
+		case "/broken":
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+		}
+	}))
+	t.Cleanup(site.Close)
+
+	cfg := baseCfg()
+	cfg.SeedURLs = []string{site.URL + "/"}
+	c, err := crawler.NewCrawler(cfg, memory.New())
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	var links []string
+	c.Events().Subscribe(func(event crawler.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		counts[event.EventName()]++
+		if found, ok := event.(crawler.LinkFound); ok {
+			links = append(links, found.Link.TargetURL)
+		}
+	})
+	var received []crawler.Event
+	ch := c.Events().Channel(16)
+	done := make(chan struct{})
+	go func() {
+		for event := range ch {
+			received = append(received, event)
+		}
+		close(done)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Event channel was not closed after the crawl")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := map[string]int{
+		crawler.EventCrawlStarted:  1,
+		crawler.EventPageCompleted: 2,
+		crawler.EventPageErrored:   1,
+		crawler.EventLinkFound:     2,
+		crawler.EventQueueEmpty:    1,
+		crawler.EventCrawlFinished: 1,
+	}
+	for name, n := range want {
+		if counts[name] != n {
+			t.Errorf("Expected %d %s events, got %d (%v)", n, name, counts[name], counts)
+		}
+	}
+	if counts[crawler.EventLimitReached] != 0 {
+		t.Errorf("Unexpected limit_reached without a limit")
+	}
+	if len(links) != 2 || links[0] != site.URL+"/a" {
+		t.Errorf("Unexpected links found: %v", links)
+	}
+
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if len(received) != total {
+		t.Fatalf("Expected the channel to receive every event, got %d", len(received))
+	}
+	if _, ok := received[0].(crawler.CrawlStarted); !ok {
+		t.Errorf("Expected crawl_started first, got %s", received[0].EventName())
+	}
+	finished, ok := received[len(received)-1].(crawler.CrawlFinished)
+	if !ok || finished.Stats.PagesCrawled != 2 {
+		t.Errorf("Expected crawl_finished last with 2 pages crawled, got %+v", received[len(received)-1])
+	}
+}
+
+// LimitReached is published once when the page limit stops the workers
+func TestEventBusLimitReached(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<a href="/1">1</a><a href="/2">2</a><a href="/3">3</a>`))
+	}))
+	t.Cleanup(site.Close)
+
+	cfg := baseCfg()
+	cfg.SeedURLs = []string{site.URL + "/"}
+	cfg.Limit = 2
+	c, err := crawler.NewCrawler(cfg, memory.New())
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	var mu sync.Mutex
+	var reached []crawler.LimitReached
+	c.Events().Subscribe(func(event crawler.Event) {
+		if e, ok := event.(crawler.LimitReached); ok {
+			mu.Lock()
+			reached = append(reached, e)
+			mu.Unlock()
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reached) != 1 || reached[0].Limit != 2 || reached[0].PagesCrawled < 2 {
+		t.Errorf("Expected one limit_reached at 2 pages, got %+v", reached)
+	}
+}
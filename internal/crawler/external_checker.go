@@ -0,0 +1,111 @@
+package crawler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// externalCheckBuffer is how many external links wait for a checker before
+// workers handing over more block
+const externalCheckBuffer = 256
+
+// externalChecker verifies out-of-scope links (check_external: head) on
+// dedicated goroutines (external_checkers), so a page linking to many
+// external sites does not hold its worker for a HEAD request each. A full
+// buffer blocks submit, which bounds the checks waiting in memory.
+type externalChecker struct {
+	crawler *DefaultCrawler
+	ctx     context.Context // Run context of the crawl; Stop is seen through crawler.stopped
+	checks  chan string     // Target URLs claimed for a check
+	done    sync.WaitGroup  // Running checker goroutines
+}
+
+// newExternalChecker starts workers checker goroutines
+func newExternalChecker(ctx context.Context, c *DefaultCrawler, workers int) *externalChecker {
+	if workers < 1 {
+		workers = 1
+	}
+	e := &externalChecker{
+		crawler: c,
+		ctx:     ctx,
+		checks:  make(chan string, externalCheckBuffer),
+	}
+	for i := 0; i < workers; i++ {
+		e.done.Add(1)
+		go e.run(i)
+	}
+	return e
+}
+
+// submit hands a link target to the checkers, blocking while the buffer is full
+func (e *externalChecker) submit(url string) {
+	select {
+	case e.checks <- url:
+	case <-e.ctx.Done():
+	}
+}
+
+// close waits for the submitted checks and stops the checker goroutines.
+// Checks still waiting when the crawl was interrupted are skipped.
+func (e *externalChecker) close() {
+	close(e.checks)
+	e.done.Wait()
+}
+
+// cancelled reports whether the crawl was interrupted
+func (e *externalChecker) cancelled() bool {
+	return e.ctx.Err() != nil || e.crawler.stopped.Load()
+}
+
+// run checks the URLs handed over until the channel is closed
+func (e *externalChecker) run(id int) {
+	defer e.done.Done()
+	for url := range e.checks {
+		if !e.cancelled() {
+			e.check(id, url)
+		}
+	}
+}
+
+// check verifies one external URL, honouring robots.txt and the per-host
+// request delay, and stores the result
+func (e *externalChecker) check(id int, url string) {
+	c := e.crawler
+	if !c.config.IgnoreRobotsTxt {
+		allowed, err := c.robotsParser.IsAllowed(e.ctx, url, c.config.UserAgent)
+		if err != nil {
+			slog.Warn("External checker robots.txt check failed", "checker_id", id, "url", url, "error", err)
+		}
+		if !allowed {
+			slog.Debug("External URL disallowed by robots.txt", "checker_id", id, "url", url)
+			return
+		}
+	}
+
+	if err := c.rateLimiter.Wait(e.ctx, url); err != nil {
+		slog.Warn("External checker rate limiting error", "checker_id", id, "url", url, "error", err)
+		return
+	}
+
+	// Checks are stored under the redacted URL, the key of the link target
+	check := &ExternalCheck{URL: c.redactURL(url), CheckedAt: time.Now().UTC()}
+	statusCode, method, err := c.httpClient.Check(e.ctx, url)
+	if e.cancelled() {
+		return
+	}
+	check.StatusCode, check.Method = statusCode, method
+	if err != nil {
+		check.ErrorMessage = err.Error()
+		if c.redactor != nil {
+			check.ErrorMessage = c.redactor.RedactText(check.ErrorMessage)
+		}
+	}
+
+	if err := c.storage.SaveExternalCheck(check); err != nil {
+		slog.Error("External checker failed to save check", "checker_id", id, "url", url, "error", err)
+		return
+	}
+	slog.Debug("External checker checked URL", "checker_id", id, "url", url, "method", method, "status", statusCode)
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/masahif/linktadoru/internal/config"
 )
 
 // TestPageProcessorExternalLinks tests that page processor correctly handles external links
@@ -157,3 +160,95 @@ func hasInvalidScheme(url string) bool {
 	}
 	return false
 }
+
+// checkRecordingStorage records saved external checks
+type checkRecordingStorage struct {
+	MockStorage
+	mu     sync.Mutex
+	checks []*ExternalCheck
+}
+
+func (s *checkRecordingStorage) SaveExternalCheck(check *ExternalCheck) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks = append(s.checks, check)
+	return nil
+}
+
+func (s *checkRecordingStorage) HasExternalCheck(url string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, check := range s.checks {
+		if check.URL == url {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheckExternalLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		case "/private":
+			t.Errorf("Expected robots.txt to keep %s from being checked", r.URL.Path)
+		}
+		if r.Method != http.MethodHead {
+			t.Errorf("Expected HEAD request, got %s", r.Method)
+		}
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+		}
+	}))
+	defer server.Close()
+
+	store := &checkRecordingStorage{}
+	httpClient := NewHTTPClient("Test-Crawler/1.0", 5*time.Second)
+	crawler := &DefaultCrawler{
+		config: &config.CrawlConfig{
+			UserAgent:     "Test-Crawler/1.0",
+			CheckExternal: config.CheckExternalHead,
+			BlockedHosts:  []string{"blocked.example.org"},
+		},
+		storage:      store,
+		httpClient:   httpClient,
+		robotsParser: NewRobotsParser(httpClient, false),
+		rateLimiter:  NewRateLimiter(0),
+		allowedHosts: []string{"https://example.com"},
+		ctx:          context.Background(),
+	}
+
+	links := []*LinkData{
+		{TargetURL: "https://example.com/about", LinkType: "internal"},
+		{TargetURL: server.URL + "/ok", LinkType: "external"},
+		{TargetURL: server.URL + "/gone", LinkType: "external"},
+		{TargetURL: server.URL + "/ok", LinkType: "external"},
+		{TargetURL: server.URL + "/private", LinkType: "external"},
+		{TargetURL: "https://blocked.example.org/", LinkType: "external"},
+	}
+	// A single checker verifies the links in order
+	crawler.checker = newExternalChecker(context.Background(), crawler, 1)
+	crawler.checkExternalLinks(links)
+	crawler.checker.close()
+
+	if len(store.checks) != 2 {
+		t.Fatalf("Expected 2 checks, got %d: %+v", len(store.checks), store.checks)
+	}
+	if store.checks[0].StatusCode != http.StatusOK || store.checks[0].Method != http.MethodHead {
+		t.Errorf("Expected 200 via HEAD, got %+v", store.checks[0])
+	}
+	if store.checks[1].StatusCode != http.StatusGone {
+		t.Errorf("Expected 410, got %+v", store.checks[1])
+	}
+
+	// URLs already checked by an earlier run are not requested again
+	crawler.checked = sync.Map{}
+	crawler.checker = newExternalChecker(context.Background(), crawler, 1)
+	crawler.checkExternalLinks(links)
+	crawler.checker.close()
+	if len(store.checks) != 2 {
+		t.Errorf("Expected no new checks, got %d", len(store.checks))
+	}
+}
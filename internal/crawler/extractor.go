@@ -0,0 +1,72 @@
+package crawler
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+
+	"golang.org/x/net/html"
+)
+
+// Extractor pulls custom fields out of an HTML page, e.g. the price and SKU
+// of a product page. It receives the page URL (after redirects), the raw
+// body and the parsed document, and returns the fields to store, encoded as
+// a JSON object in page_extractions. Returning no fields stores an empty
+// object; an error is stored in place of the fields. Extract is called
+// concurrently from the workers, so it must be safe for concurrent use.
+type Extractor interface {
+	Extract(url string, body []byte, doc *html.Node) (map[string]any, error)
+}
+
+// namedExtractor is an extractor with the name its results are stored under
+type namedExtractor struct {
+	name      string
+	extractor Extractor
+}
+
+// AddExtractor runs extractor on every successful HTML page, storing its
+// fields under name. The body of HTML pages is only buffered and parsed
+// into a document tree when an extractor is registered.
+func (p *DefaultPageProcessor) AddExtractor(name string, extractor Extractor) {
+	p.extractors = append(p.extractors, namedExtractor{name: name, extractor: extractor})
+}
+
+// RegisterExtractor adds a content extractor to the crawl (see
+// DefaultPageProcessor.AddExtractor). Call it before Start.
+func (c *DefaultCrawler) RegisterExtractor(name string, extractor Extractor) error {
+	processor, ok := c.processor.(*DefaultPageProcessor)
+	if !ok {
+		return fmt.Errorf("the page processor does not support extractors")
+	}
+	for _, registered := range processor.extractors {
+		if registered.name == name {
+			return fmt.Errorf("extractor %q is already registered", name)
+		}
+	}
+	processor.AddExtractor(name, extractor)
+	return nil
+}
+
+// extract runs the registered extractors on an HTML body
+func (p *DefaultPageProcessor) extract(url string, body []byte) []Extraction {
+	doc, parseErr := html.Parse(bytes.NewReader(body))
+	extractions := make([]Extraction, 0, len(p.extractors))
+	for _, e := range p.extractors {
+		extraction := Extraction{Extractor: e.name}
+		fields, err := map[string]any(nil), parseErr
+		if err == nil {
+			fields, err = e.extractor.Extract(url, body, doc)
+		}
+		switch {
+		case err != nil:
+			slog.Warn("Content extractor failed", "extractor", e.name, "url", url, "error", err)
+			extraction.Error = err.Error()
+		case fields == nil:
+			extraction.Fields = map[string]any{}
+		default:
+			extraction.Fields = fields
+		}
+		extractions = append(extractions, extraction)
+	}
+	return extractions
+}
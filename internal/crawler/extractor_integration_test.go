@@ -0,0 +1,112 @@
+package crawler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage/memory"
+)
+
+// priceExtractor reads the text of the element with id="price"
+type priceExtractor struct{}
+
+func (priceExtractor) Extract(url string, body []byte, doc *html.Node) (map[string]any, error) {
+	var find func(*html.Node) string
+	find = func(n *html.Node) string {
+		if n.Type == html.ElementNode {
+			for _, attr := range n.Attr {
+				if attr.Key == "id" && attr.Val == "price" && n.FirstChild != nil {
+					return n.FirstChild.Data
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if price := find(child); price != "" {
+				return price
+			}
+		}
+		return ""
+	}
+	price := find(doc)
+	if price == "" {
+		return nil, nil
+	}
+	return map[string]any{"price": price, "bytes": len(body), "url": url}, nil
+}
+
+// extractorFunc adapts a function to crawler.Extractor
+type extractorFunc func(url string, body []byte, doc *html.Node) (map[string]any, error)
+
+func (f extractorFunc) Extract(url string, body []byte, doc *html.Node) (map[string]any, error) {
+	return f(url, body, doc)
+}
+
+// Registered extractors run on every HTML page and their fields are saved with it
+func TestContentExtractors(t *testing.T) {
+	product := `<html><body><a href="/plain">Plain</a><span id="price">12.50</span></body></html>`
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(product))
+		case "/plain":
+			_, _ = w.Write([]byte(`<p>No price here</p>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(site.Close)
+
+	cfg := baseCfg()
+	cfg.SeedURLs = []string{site.URL + "/"}
+	store := memory.New()
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+	if err := c.RegisterExtractor("price", priceExtractor{}); err != nil {
+		t.Fatalf("RegisterExtractor: %v", err)
+	}
+	failing := extractorFunc(func(string, []byte, *html.Node) (map[string]any, error) {
+		return nil, errors.New("layout changed")
+	})
+	if err := c.RegisterExtractor("failing", failing); err != nil {
+		t.Fatalf("RegisterExtractor: %v", err)
+	}
+	if err := c.RegisterExtractor("price", priceExtractor{}); err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Errorf("Expected a duplicate name to be rejected, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	page, ok := store.Page(site.URL + "/")
+	if !ok || len(page.Extractions) != 2 {
+		t.Fatalf("Expected two extractions, got %+v", page)
+	}
+	price := page.Extractions[0]
+	if price.Extractor != "price" || price.Error != "" || price.Fields["price"] != "12.50" ||
+		price.Fields["bytes"] != len(product) || price.Fields["url"] != site.URL+"/" {
+		t.Errorf("Unexpected price extraction: %+v", price)
+	}
+	if failed := page.Extractions[1]; failed.Extractor != "failing" || failed.Error != "layout changed" || failed.Fields != nil {
+		t.Errorf("Unexpected failed extraction: %+v", failed)
+	}
+
+	plain, ok := store.Page(site.URL + "/plain")
+	if !ok || len(plain.Extractions) != 2 || len(plain.Extractions[0].Fields) != 0 || plain.Extractions[0].Fields == nil {
+		t.Errorf("Expected an empty price extraction for a page without a price, got %+v", plain)
+	}
+}
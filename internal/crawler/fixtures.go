@@ -0,0 +1,178 @@
+package crawler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrNoFixture is returned in replay mode for a request no response was
+// recorded for
+var ErrNoFixture = errors.New("no recorded response")
+
+// fixture is the metadata of a recorded response, stored as <key>.json next
+// to the body in <key>.body
+type fixture struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	StatusCode  int         `json:"status_code"`
+	Header      http.Header `json:"header"`
+	RecordedAt  time.Time   `json:"recorded_at"`
+	BodySkipped bool        `json:"body_skipped,omitempty"` // Content-Type filtered out, no body recorded
+	Truncated   bool        `json:"truncated,omitempty"`    // Body cut one byte past max_response_size
+}
+
+// fixtureKey names the files of the response to method url
+func fixtureKey(method, url string) string {
+	sum := sha256.Sum256([]byte(method + " " + url))
+	return hex.EncodeToString(sum[:16])
+}
+
+// acceptKey is the request context key of the Content-Type filter of a
+// GetFiltered, so a recording transport does not save bodies the client
+// will not download
+type acceptKey struct{}
+
+// recordingTransport sends requests to next and saves every response it
+// receives in dir (config record_dir), so the crawl can be replayed offline.
+// Bodies the request's Content-Type filter rejects are not recorded, and
+// bodies over maxBodySize are recorded up to one byte past it, enough for a
+// replay to fail with ErrResponseTooLarge as the crawl did.
+type recordingTransport struct {
+	dir         string
+	next        http.RoundTripper
+	maxBodySize int64 // 0 = unlimited
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	f := fixture{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		RecordedAt: time.Now().UTC(),
+	}
+
+	var body []byte
+	accept, _ := req.Context().Value(acceptKey{}).(func(contentType string) bool)
+	if accept != nil && !accept(resp.Header.Get("Content-Type")) {
+		// The client closes the response without reading it
+		f.BodySkipped = true
+	} else {
+		r := resp.Body
+		if t.maxBodySize > 0 {
+			r = io.NopCloser(io.LimitReader(resp.Body, t.maxBodySize+1))
+		}
+		if body, err = io.ReadAll(r); err != nil {
+			_ = resp.Body.Close()
+			return nil, err
+		}
+		if t.maxBodySize > 0 && int64(len(body)) > t.maxBodySize {
+			// The client aborts the rest of the download itself
+			f.Truncated = true
+			resp.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		} else {
+			_ = resp.Body.Close()
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			resp.ContentLength = int64(len(body))
+		}
+	}
+
+	meta, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("failed to encode recorded response: %w", err)
+	}
+	key := filepath.Join(t.dir, fixtureKey(req.Method, req.URL.String()))
+	if err := os.WriteFile(key+".body", body, 0o600); err != nil {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("failed to record response: %w", err)
+	}
+	if err := os.WriteFile(key+".json", meta, 0o600); err != nil {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("failed to record response: %w", err)
+	}
+	return resp, nil
+}
+
+// replayTransport answers requests with the responses recorded in dir
+// (config replay_dir) without any network access
+type replayTransport struct {
+	dir string
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	key := filepath.Join(t.dir, fixtureKey(req.Method, req.URL.String()))
+	meta, err := os.ReadFile(key + ".json") // #nosec G304 -- named by a hash inside the configured directory
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w for %s %s", ErrNoFixture, req.Method, req.URL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded response: %w", err)
+	}
+	var f fixture
+	if err := json.Unmarshal(meta, &f); err != nil {
+		return nil, fmt.Errorf("invalid recorded response %s.json: %w", key, err)
+	}
+	body, err := os.ReadFile(key + ".body") // #nosec G304 -- named by a hash inside the configured directory
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded response: %w", err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.StatusCode, http.StatusText(f.StatusCode)),
+		StatusCode:    f.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// SetRecordDir saves every response the client receives in dir, creating
+// it if needed. A crawl recorded this way can be replayed with SetReplayDir.
+func (h *HTTPClient) SetRecordDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create record directory: %w", err)
+	}
+	h.recordDir = dir
+	h.buildTransport()
+	return nil
+}
+
+// SetReplayDir answers every request with the response recorded in dir
+// instead of sending it; requests nothing was recorded for fail with
+// ErrNoFixture
+func (h *HTTPClient) SetReplayDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open replay directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("replay directory %s is not a directory", dir)
+	}
+	h.replayDir = dir
+	h.buildTransport()
+	return nil
+}
@@ -0,0 +1,172 @@
+package crawler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage/memory"
+)
+
+// A recorded crawl replays with the same results once the site is gone
+func TestRecordAndReplay(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<title>Home</title><a href="/old">Old</a>`))
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/new":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<title>New</title><a href="/missing">Missing</a>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	dir := t.TempDir()
+
+	crawl := func(t *testing.T, record bool) *memory.Storage {
+		t.Helper()
+		cfg := baseCfg()
+		cfg.SeedURLs = []string{site.URL + "/"}
+		if record {
+			cfg.RecordDir = dir
+		} else {
+			cfg.ReplayDir = dir
+		}
+		store := memory.New()
+		c, err := crawler.NewCrawler(cfg, store)
+		if err != nil {
+			t.Fatalf("NewCrawler: %v", err)
+		}
+		t.Cleanup(func() { _ = c.Stop() })
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		return store
+	}
+
+	recorded := crawl(t, true)
+	site.Close()
+	replayed := crawl(t, false)
+
+	for _, path := range []string{"/", "/old", "/missing"} {
+		want, ok := recorded.Page(site.URL + path)
+		if !ok {
+			t.Fatalf("%s was not crawled while recording", path)
+		}
+		got, ok := replayed.Page(site.URL + path)
+		if !ok {
+			t.Fatalf("%s was not crawled while replaying", path)
+		}
+		if got.StatusCode != want.StatusCode || got.Title != want.Title || len(got.Redirects) != len(want.Redirects) {
+			t.Errorf("%s: replayed %d %q %v, recorded %d %q %v", path,
+				got.StatusCode, got.Title, got.Redirects, want.StatusCode, want.Title, want.Redirects)
+		}
+	}
+}
+
+// Replaying a request nothing was recorded for fails without network access
+func TestReplayMissingResponse(t *testing.T) {
+	client := crawler.NewHTTPClient("LinkTadoru-Test/1.0", time.Second)
+	if err := client.SetReplayDir(t.TempDir()); err != nil {
+		t.Fatalf("SetReplayDir: %v", err)
+	}
+	_, err := client.Get(context.Background(), "http://example.invalid/")
+	if err == nil || !strings.Contains(err.Error(), crawler.ErrNoFixture.Error()) {
+		t.Errorf("Expected a no recorded response error, got %v", err)
+	}
+}
+
+// Recording keeps to max_response_size and skips bodies the content-type
+// filter rejects, and a truncated response replays with the same error
+func TestRecordBoundedResponses(t *testing.T) {
+	const limit = 1024
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large":
+			// Streamed without a Content-Length, far over the limit
+			w.Header().Set("Content-Type", "text/html")
+			chunk := []byte(strings.Repeat("x", 4096))
+			for i := 0; i < 256; i++ {
+				if _, err := w.Write(chunk); err != nil {
+					return
+				}
+				w.(http.Flusher).Flush()
+			}
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(make([]byte, 512))
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<title>Small</title>"))
+		}
+	}))
+	defer site.Close()
+	dir := t.TempDir()
+
+	client := crawler.NewHTTPClient("LinkTadoru-Test/1.0", 5*time.Second)
+	client.SetMaxResponseSize(limit)
+	if err := client.SetRecordDir(dir); err != nil {
+		t.Fatalf("SetRecordDir: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := client.Get(ctx, site.URL+"/large"); !errors.Is(err, crawler.ErrResponseTooLarge) {
+		t.Fatalf("Expected ErrResponseTooLarge while recording, got %v", err)
+	}
+	if resp, err := client.Get(ctx, site.URL+"/small"); err != nil || string(resp.Body) != "<title>Small</title>" {
+		t.Fatalf("Unexpected small response: %v", err)
+	}
+	isHTML := func(contentType string) bool { return strings.HasPrefix(contentType, "text/html") }
+	if resp, err := client.GetFiltered(ctx, site.URL+"/image.png", isHTML); err != nil || !resp.BodySkipped {
+		t.Fatalf("Expected the image body to be skipped: %v", err)
+	}
+
+	bodies, _ := filepath.Glob(filepath.Join(dir, "*.body"))
+	if len(bodies) != 3 {
+		t.Fatalf("Expected 3 recorded responses, got %d", len(bodies))
+	}
+	for _, body := range bodies {
+		info, err := os.Stat(body)
+		if err != nil {
+			t.Fatalf("Stat: %v", err)
+		}
+		if info.Size() > limit+1 {
+			t.Errorf("%s holds %d bytes, more than the limit allows", body, info.Size())
+		}
+		meta, _ := os.ReadFile(strings.TrimSuffix(body, ".body") + ".json")
+		switch {
+		case strings.Contains(string(meta), "/large"):
+			if info.Size() != limit+1 || !strings.Contains(string(meta), `"truncated": true`) {
+				t.Errorf("Expected a truncated fixture of %d bytes, got %d:\n%s", limit+1, info.Size(), meta)
+			}
+		case strings.Contains(string(meta), "/image.png"):
+			if info.Size() != 0 || !strings.Contains(string(meta), `"body_skipped": true`) {
+				t.Errorf("Expected a filtered fixture without a body, got %d bytes:\n%s", info.Size(), meta)
+			}
+		}
+	}
+
+	replay := crawler.NewHTTPClient("LinkTadoru-Test/1.0", time.Second)
+	replay.SetMaxResponseSize(limit)
+	if err := replay.SetReplayDir(dir); err != nil {
+		t.Fatalf("SetReplayDir: %v", err)
+	}
+	site.Close()
+	if _, err := replay.Get(ctx, site.URL+"/large"); !errors.Is(err, crawler.ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge while replaying, got %v", err)
+	}
+	if resp, err := replay.Get(ctx, site.URL+"/small"); err != nil || string(resp.Body) != "<title>Small</title>" {
+		t.Errorf("Unexpected replayed small response: %v", err)
+	}
+}
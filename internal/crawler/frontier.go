@@ -0,0 +1,153 @@
+package crawler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+)
+
+// Frontier limit bookkeeping stored in crawl_meta
+const (
+	MetaFrontierDroppedCount  = "frontier_dropped_count"
+	MetaFrontierDroppedSample = "frontier_dropped_sample"
+
+	// FrontierDropReason is recorded with dropped URLs
+	FrontierDropReason = "max_queue_size"
+
+	// frontierSampleSize is the number of dropped URLs kept as a sample
+	frontierSampleSize = 100
+)
+
+// frontierLimiter enforces max_queue_size. Discoveries beyond the limit are
+// dropped instead of queued; they are counted and a uniform sample of them is
+// kept (reservoir sampling) so users can see what was left out.
+//
+// The number of pending URLs is kept in memory rather than counted in storage
+// for every admission: it is read from storage by seed and then follows the
+// URLs admitted, claimed and released. Other processes sharing a queue are
+// only seen at the next seed, so the limit is approximate for them.
+//
+// The zero value is ready to use.
+type frontierLimiter struct {
+	mu      sync.Mutex
+	pending int
+	dropped int
+	sample  []string
+}
+
+// urlSample is the JSON document stored for URL samples in crawl_meta
+// (MetaFrontierDroppedSample, MetaStopRemainingSample)
+type urlSample struct {
+	Reason string   `json:"reason"`
+	URLs   []string `json:"urls"`
+}
+
+// admit queues as many of urls as fit under maxSize pending URLs and drops the
+// rest. The check and the insert happen under one lock so concurrent workers
+// cannot overshoot the limit together.
+func (f *frontierLimiter) admit(storage Storage, urls []string, maxSize int) error {
+	if maxSize <= 0 {
+		return storage.AddToQueue(urls)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	room := min(max(maxSize-f.pending, 0), len(urls))
+	if room > 0 {
+		if err := storage.AddToQueue(urls[:room]); err != nil {
+			return err
+		}
+		f.pending += room
+	}
+
+	if dropped := urls[room:]; len(dropped) > 0 {
+		if f.dropped == 0 {
+			slog.Warn("Queue is full, dropping new URLs", "max_queue_size", maxSize)
+		}
+		for _, u := range dropped {
+			f.record(u)
+		}
+		f.save(storage)
+	}
+	return nil
+}
+
+// seed reads the number of pending URLs from storage, at the start of a crawl
+// and whenever URLs are requeued outside admit
+func (f *frontierLimiter) seed(storage Storage) error {
+	pending, _, _, _, err := storage.GetQueueStatus()
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending = pending
+	return nil
+}
+
+// claimed accounts for n pending URLs claimed by a worker
+func (f *frontierLimiter) claimed(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending = max(f.pending-n, 0)
+}
+
+// released accounts for n claimed URLs returned to pending
+func (f *frontierLimiter) released(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending += n
+}
+
+// record counts a dropped URL and adds it to the reservoir sample
+func (f *frontierLimiter) record(u string) {
+	f.dropped++
+	if len(f.sample) < frontierSampleSize {
+		f.sample = append(f.sample, u)
+		return
+	}
+	if i := rand.IntN(f.dropped); i < frontierSampleSize {
+		f.sample[i] = u
+	}
+}
+
+// save writes the drop count and sample to crawl_meta
+func (f *frontierLimiter) save(storage Storage) {
+	if err := storage.SetMeta(MetaFrontierDroppedCount, strconv.Itoa(f.dropped)); err != nil {
+		slog.Error("Failed to record dropped URL count", "error", err)
+	}
+	data, err := json.Marshal(urlSample{Reason: FrontierDropReason, URLs: f.sample})
+	if err == nil {
+		err = storage.SetMeta(MetaFrontierDroppedSample, string(data))
+	}
+	if err != nil {
+		slog.Error("Failed to record dropped URL sample", "error", err)
+	}
+}
+
+// load restores the drop count and sample recorded by previous runs, so the
+// totals in crawl_meta cover the whole database
+func (f *frontierLimiter) load(storage Storage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if value, err := storage.GetMeta(MetaFrontierDroppedCount); err == nil && value != "" {
+		f.dropped, _ = strconv.Atoi(value)
+	}
+	if value, err := storage.GetMeta(MetaFrontierDroppedSample); err == nil && value != "" {
+		var sample urlSample
+		if json.Unmarshal([]byte(value), &sample) == nil {
+			f.sample = sample.URLs
+		}
+	}
+}
+
+// droppedCount returns the number of URLs dropped so far
+func (f *frontierLimiter) droppedCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.dropped
+}
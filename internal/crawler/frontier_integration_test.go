@@ -0,0 +1,52 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// URLs dropped by max_queue_size are counted once however often they are
+// rediscovered
+func TestCrawlCountsDroppedURLsOnce(t *testing.T) {
+	pages := map[string]string{
+		"/":  `<a href="/a">a</a><a href="/b">b</a><a href="/c">c</a>`,
+		"/a": `<a href="/b">b</a><a href="/c">c</a><a href="/">home</a>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><body>" + pages[r.URL.Path] + "</body></html>"))
+	}))
+	defer server.Close()
+
+	cfg := baseCfg()
+	cfg.SeedURLs = []string{server.URL + "/"}
+	cfg.MaxQueueSize = 1
+	cfg.SeenURLCacheSize = 100
+	store := newStore(t)
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if got, _ := statusOf(t, store, server.URL+"/a"); got != "completed" {
+		t.Errorf("/a status = %q, want completed", got)
+	}
+	if got, _ := statusOf(t, store, server.URL+"/b"); got != "discovered" {
+		t.Errorf("/b status = %q, want discovered (dropped)", got)
+	}
+	if dropped := c.GetStats().URLsDropped; dropped != 2 {
+		t.Errorf("URLsDropped = %d, want 2", dropped)
+	}
+}
@@ -0,0 +1,133 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// frontierStorage treats every queued URL as pending and keeps meta in memory
+type frontierStorage struct {
+	MockStorage
+	queued []string
+	meta   map[string]string
+}
+
+func (s *frontierStorage) AddToQueue(urls []string) error {
+	s.queued = append(s.queued, urls...)
+	return nil
+}
+
+func (s *frontierStorage) GetQueueStatus() (int, int, int, int, error) {
+	return len(s.queued), 0, 0, 0, nil
+}
+
+func (s *frontierStorage) GetMeta(key string) (string, error) {
+	return s.meta[key], nil
+}
+
+func (s *frontierStorage) SetMeta(key, value string) error {
+	s.meta[key] = value
+	return nil
+}
+
+func TestFrontierLimiterUnlimited(t *testing.T) {
+	store := &frontierStorage{meta: map[string]string{}}
+	var f frontierLimiter
+
+	if err := f.admit(store, []string{"a", "b", "c"}, 0); err != nil {
+		t.Fatalf("admit failed: %v", err)
+	}
+	if len(store.queued) != 3 || f.droppedCount() != 0 {
+		t.Errorf("Expected all URLs queued, got %v (dropped %d)", store.queued, f.droppedCount())
+	}
+}
+
+func TestFrontierLimiterDropsBeyondLimit(t *testing.T) {
+	store := &frontierStorage{meta: map[string]string{}}
+	var f frontierLimiter
+
+	if err := f.admit(store, []string{"a", "b", "c"}, 5); err != nil {
+		t.Fatalf("admit failed: %v", err)
+	}
+	if err := f.admit(store, []string{"d", "e", "f", "g"}, 5); err != nil {
+		t.Fatalf("admit failed: %v", err)
+	}
+
+	if len(store.queued) != 5 {
+		t.Errorf("Expected 5 queued URLs, got %v", store.queued)
+	}
+	if f.droppedCount() != 2 {
+		t.Errorf("Expected 2 dropped URLs, got %d", f.droppedCount())
+	}
+	if store.meta[MetaFrontierDroppedCount] != "2" {
+		t.Errorf("Expected dropped count in meta, got %q", store.meta[MetaFrontierDroppedCount])
+	}
+
+	var sample urlSample
+	if err := json.Unmarshal([]byte(store.meta[MetaFrontierDroppedSample]), &sample); err != nil {
+		t.Fatalf("Invalid sample JSON: %v", err)
+	}
+	if sample.Reason != FrontierDropReason || len(sample.URLs) != 2 || sample.URLs[0] != "f" {
+		t.Errorf("Unexpected sample: %+v", sample)
+	}
+}
+
+func TestFrontierLimiterSampleIsBounded(t *testing.T) {
+	store := &frontierStorage{meta: map[string]string{}}
+	var f frontierLimiter
+
+	urls := make([]string, 1000)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://example.com/%d", i)
+	}
+	if err := f.admit(store, urls, 10); err != nil {
+		t.Fatalf("admit failed: %v", err)
+	}
+
+	if f.droppedCount() != 990 {
+		t.Errorf("Expected 990 dropped URLs, got %d", f.droppedCount())
+	}
+	if len(f.sample) != frontierSampleSize {
+		t.Errorf("Expected sample of %d URLs, got %d", frontierSampleSize, len(f.sample))
+	}
+
+	// A later run continues the totals recorded in crawl_meta
+	var resumed frontierLimiter
+	resumed.load(store)
+	if resumed.droppedCount() != 990 || len(resumed.sample) != frontierSampleSize {
+		t.Errorf("Expected restored totals, got %d dropped and %d sampled", resumed.droppedCount(), len(resumed.sample))
+	}
+}
+
+// countingStorage counts the queue status queries of frontierStorage
+type countingStorage struct {
+	frontierStorage
+	statusCalls int
+}
+
+func (s *countingStorage) GetQueueStatus() (int, int, int, int, error) {
+	s.statusCalls++
+	return s.frontierStorage.GetQueueStatus()
+}
+
+func TestFrontierLimiterTracksPending(t *testing.T) {
+	store := &countingStorage{frontierStorage: frontierStorage{queued: []string{"a", "b"}, meta: map[string]string{}}}
+	var f frontierLimiter
+	if err := f.seed(store); err != nil {
+		t.Fatalf("seed failed: %v", err)
+	}
+
+	// Two claimed URLs make room for two more, one released takes one back
+	f.claimed(2)
+	f.released(1)
+	if err := f.admit(store, []string{"c", "d", "e", "f"}, 4); err != nil {
+		t.Fatalf("admit failed: %v", err)
+	}
+	if len(store.queued) != 5 || f.droppedCount() != 1 {
+		t.Errorf("Expected c, d and e queued and f dropped, got %v (dropped %d)", store.queued, f.droppedCount())
+	}
+	if store.statusCalls != 1 {
+		t.Errorf("Expected storage to be counted only when seeding, got %d queries", store.statusCalls)
+	}
+}
@@ -0,0 +1,67 @@
+package crawler
+
+import "time"
+
+// Hooks are callbacks a program embedding the crawler registers to add its
+// own logic to a crawl without changing the crawler. They run on the worker
+// goroutines, so they must be safe for concurrent use and should return
+// quickly: the worker waits for them. Any hook may be nil.
+type Hooks struct {
+	// OnPageCrawled is called with each fetched page, after normalization and
+	// redaction and before anything is saved. It may modify the result, e.g.
+	// to fill in fields or drop links.
+	OnPageCrawled func(result *PageResult)
+
+	// OnLinkDiscovered is called for each link found on a fetched page,
+	// before OnPageCrawled. Returning false drops the link: it is neither
+	// saved nor queued.
+	OnLinkDiscovered func(link *LinkData) bool
+
+	// OnError is called when a page could not be fetched or processed
+	OnError func(crawlErr *CrawlError)
+}
+
+// SetHooks registers the hooks called during the crawl, replacing any set
+// before. Call it before Start.
+func (c *DefaultCrawler) SetHooks(hooks Hooks) {
+	c.hooks = hooks
+}
+
+// UseHTTPMiddleware adds middleware around every request the crawler sends
+// (see HTTPClient.Use). Call it before Start.
+func (c *DefaultCrawler) UseHTTPMiddleware(middleware ...Middleware) {
+	c.httpClient.Use(middleware...)
+}
+
+// pageCrawled runs the link and page hooks on a fetched page
+func (c *DefaultCrawler) pageCrawled(result *PageResult) {
+	if result.Page == nil || result.Skip != nil {
+		return
+	}
+	if c.hooks.OnLinkDiscovered != nil {
+		links := result.Links[:0]
+		for _, link := range result.Links {
+			if c.hooks.OnLinkDiscovered(link) {
+				links = append(links, link)
+			}
+		}
+		result.Links = links
+	}
+	if c.hooks.OnPageCrawled != nil {
+		c.hooks.OnPageCrawled(result)
+	}
+}
+
+// crawlFailed publishes PageErrored for a page that could not be fetched or processed
+func (c *DefaultCrawler) crawlFailed(item *URLItem, errorType, errorMessage string) {
+	now := time.Now().UTC()
+	c.events.publish(PageErrored{Time: now, URL: item.URL,
+		Error: &CrawlError{URL: item.URL, ErrorType: errorType, ErrorMessage: errorMessage, OccurredAt: now}})
+}
+
+// errorHook runs the error hook for the PageErrored events
+func (c *DefaultCrawler) errorHook(event Event) {
+	if errored, ok := event.(PageErrored); ok && c.hooks.OnError != nil {
+		c.hooks.OnError(errored.Error)
+	}
+}
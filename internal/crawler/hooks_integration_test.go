@@ -0,0 +1,122 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage/memory"
+)
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// Middleware sees every request and hooks observe and adjust the results
+func TestHooksAndMiddleware(t *testing.T) {
+	var mu sync.Mutex
+	var requested, unsigned []string
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		if r.Header.Get("X-Signature") != "signed:"+r.URL.Path {
+			unsigned = append(unsigned, r.URL.Path)
+		}
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<title>Home</title><a href="/a">A</a><a href="/private/x">X</a><a href="/broken">B</a>`))
+		case "/a":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<title>Page A</title>`))
+		case "/broken":
+			// Drop the connection without answering
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(site.Close)
+
+	cfg := baseCfg()
+	cfg.SeedURLs = []string{site.URL + "/"}
+	store := memory.New()
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	var order []string
+	c.UseHTTPMiddleware(
+		func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				order = append(order, "outer")
+				mu.Unlock()
+				req.Header.Set("X-Signature", "signed")
+				return next.RoundTrip(req)
+			})
+		},
+		func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				order = append(order, "inner")
+				mu.Unlock()
+				req.Header.Set("X-Signature", req.Header.Get("X-Signature")+":"+req.URL.Path)
+				return next.RoundTrip(req)
+			})
+		},
+	)
+	var failed []*crawler.CrawlError
+	c.SetHooks(crawler.Hooks{
+		OnLinkDiscovered: func(link *crawler.LinkData) bool {
+			return !strings.Contains(link.TargetURL, "/private/")
+		},
+		OnPageCrawled: func(result *crawler.PageResult) {
+			result.Page.Title = strings.ToUpper(result.Page.Title)
+		},
+		OnError: func(crawlErr *crawler.CrawlError) {
+			mu.Lock()
+			failed = append(failed, crawlErr)
+			mu.Unlock()
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(unsigned) > 0 {
+		t.Errorf("Requests not signed by the middleware chain: %v", unsigned)
+	}
+	if len(order) < 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("Expected the first middleware to run first, got %v", order)
+	}
+	for _, path := range requested {
+		if strings.HasPrefix(path, "/private/") {
+			t.Errorf("Dropped link %s was crawled", path)
+		}
+	}
+	if _, ok := store.GetURLStatus(site.URL + "/private/x"); ok {
+		t.Error("Dropped link was stored")
+	}
+	if page, ok := store.Page(site.URL + "/a"); !ok || page.Title != "PAGE A" {
+		t.Errorf("Expected the page hook to change the title, got %+v", page)
+	}
+	if len(failed) != 1 || failed[0].URL != site.URL+"/broken" || failed[0].ErrorMessage == "" {
+		t.Errorf("Expected one error for /broken, got %+v", failed)
+	}
+}
@@ -0,0 +1,85 @@
+package crawler
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+// matchesHostPattern reports whether host matches a host list entry. Entries
+// are compared case-insensitively; "*.example.com" matches any subdomain of
+// example.com (at any depth) but not example.com itself.
+func matchesHostPattern(pattern, host string) bool {
+	pattern = strings.ToLower(pattern)
+	host = strings.ToLower(host)
+
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return host == pattern
+}
+
+// matchesAnyHost reports whether host matches any entry in patterns
+func matchesAnyHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		if matchesHostPattern(pattern, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// authHosts returns the hosts credentials are sent to: auth.hosts, or the
+// hostnames of seedURLs. Hosts admitted by allowed_hosts or
+// include_subdomains only receive credentials when listed in auth.hosts.
+func authHosts(cfg *config.CrawlConfig, seedURLs []string) []string {
+	if cfg.Auth != nil && len(cfg.Auth.Hosts) > 0 {
+		return cfg.Auth.Hosts
+	}
+	hosts := make([]string, 0, len(seedURLs))
+	for _, seedURL := range seedURLs {
+		if host := urlHostname(normalizeSeedURL(seedURL)); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// urlHostname returns the hostname of targetURL without port, or "" if it cannot be parsed
+func urlHostname(targetURL string) string {
+	parsedURL, err := url.Parse(targetURL)
+	if err != nil {
+		return ""
+	}
+	return parsedURL.Hostname()
+}
+
+// isSubdomainOfSeed reports whether targetURL is on a subdomain of a seed
+// host ("scheme://host[:port]"), or on the seed host itself. A leading "www."
+// on the seed is ignored, so a seed of www.example.com also admits
+// example.com and blog.example.com. Scheme and port must match the seed.
+func isSubdomainOfSeed(targetURL, seedHost string) bool {
+	target, err := url.Parse(targetURL)
+	if err != nil {
+		return false
+	}
+	seed, err := url.Parse(seedHost)
+	if err != nil {
+		return false
+	}
+	if target.Scheme != seed.Scheme || target.Port() != seed.Port() {
+		return false
+	}
+
+	host := strings.ToLower(target.Hostname())
+	base := strings.TrimPrefix(strings.ToLower(seed.Hostname()), "www.")
+	return host == base || strings.HasSuffix(host, "."+base)
+}
+
+// scopeSpansHosts reports whether the crawl scope can include hosts other than
+// the page being crawled, so links the parser marks as external may still be
+// followed
+func scopeSpansHosts(cfg *config.CrawlConfig) bool {
+	return len(cfg.AllowedHosts) > 0 || cfg.IncludeSubdomains
+}
@@ -3,16 +3,27 @@ package crawler
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptrace"
+	"os"
+	"slices"
+	"sync"
 	"time"
+
+	"github.com/Azure/go-ntlmssp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // HTTPClient handles HTTP requests with performance metrics
 type HTTPClient struct {
 	client        *http.Client
+	transport     *http.Transport // Underlying transport, kept for TLS configuration
 	userAgent     string
 	authType      string
 	username      string            // Basic auth username
@@ -20,9 +31,23 @@ type HTTPClient struct {
 	bearerToken   string            // Bearer token
 	apiKeyHeader  string            // API key header name
 	apiKeyValue   string            // API key header value
+	authHosts     []string          // Host patterns credentials are sent to (nil = all, see SetAuthHosts)
 	customHeaders map[string]string // Custom headers
+	maxBodySize   int64             // Maximum response body size in bytes (0 = unlimited)
+	middleware    []Middleware      // Wrappers of the transport, outermost first (see Use)
+	recordDir     string            // Directory responses are saved in (see SetRecordDir)
+	replayDir     string            // Directory responses are replayed from (see SetReplayDir)
 }
 
+// Middleware wraps the round tripper that sends the client's requests, e.g.
+// to sign requests, record custom metrics or rewrite responses. It returns a
+// round tripper that normally calls next.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// ErrResponseTooLarge is returned by Get when a response body exceeds the
+// configured maximum size
+var ErrResponseTooLarge = errors.New("response body exceeds maximum size")
+
 // HTTPMetrics contains performance metrics for an HTTP request
 type HTTPMetrics struct {
 	TTFB         time.Duration // Time to First Byte
@@ -36,14 +61,60 @@ type HTTPMetrics struct {
 type HTTPResponse struct {
 	StatusCode      int
 	Headers         http.Header
-	Body            []byte
+	Body            []byte // Nil when the body was streamed (GetStreamed)
+	BodySize        int64  // Body bytes read
 	ContentType     string
 	ContentLength   int64
 	Server          string
 	LastModified    time.Time
 	ContentEncoding string
 	Metrics         HTTPMetrics
-	FinalURL        string // After following redirects
+	FinalURL        string        // After following redirects
+	Redirects       []RedirectHop // Redirects followed to reach FinalURL, in order
+	BodySkipped     bool          // Body was not downloaded because its Content-Type was rejected
+	// Leaf certificates of the HTTPS hosts of the request: those presented
+	// in the handshakes of new connections, redirects included, and the one
+	// of the connection the final response came over
+	Certificates []TLSCertificate
+}
+
+// RedirectHop is one redirect followed while fetching a URL
+type RedirectHop struct {
+	StatusCode int    // 3xx status of the redirect response
+	FromURL    string // URL that answered with the redirect
+	ToURL      string // Resolved Location of the redirect
+}
+
+// maxRedirects is the number of redirects a request follows before failing
+const maxRedirects = 10
+
+// redirectsKey is the request context key of the *[]RedirectHop that
+// checkRedirect records the hops of a Get into
+type redirectsKey struct{}
+
+// checkRedirect records each redirect in the hops of the request context. A
+// redirect repeating a hop already in the chain is a loop and is not
+// followed: the redirect response itself is returned instead. Redirects back
+// to an earlier URL are followed once, since a server may answer differently
+// the second time (e.g. after setting a cookie).
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	hop := RedirectHop{
+		StatusCode: req.Response.StatusCode,
+		FromURL:    via[len(via)-1].URL.String(),
+		ToURL:      req.URL.String(),
+	}
+	if hops, ok := req.Context().Value(redirectsKey{}).(*[]RedirectHop); ok {
+		for _, previous := range *hops {
+			if previous.FromURL == hop.FromURL && previous.ToURL == hop.ToURL {
+				return http.ErrUseLastResponse
+			}
+		}
+		*hops = append(*hops, hop)
+	}
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("too many redirects")
+	}
+	return nil
 }
 
 // NewHTTPClient creates a new HTTP client
@@ -58,19 +129,32 @@ func NewHTTPClient(userAgent string, timeout time.Duration) *HTTPClient {
 	client := &http.Client{
 		Transport: transport,
 		Timeout:   timeout,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= 10 {
-				return fmt.Errorf("too many redirects")
-			}
-			return nil
-		},
 	}
 
-	return &HTTPClient{
+	h := &HTTPClient{
 		client:        client,
+		transport:     transport,
 		userAgent:     userAgent,
 		customHeaders: make(map[string]string),
 	}
+	client.CheckRedirect = h.checkRedirect
+	return h
+}
+
+// checkRedirect follows redirects like the package checkRedirect, and removes
+// the credentials from redirects to hosts that are not sent any (see
+// SetAuthHosts)
+func (h *HTTPClient) checkRedirect(req *http.Request, via []*http.Request) error {
+	if err := checkRedirect(req, via); err != nil {
+		return err
+	}
+	if !h.sendsCredentials(req.URL.Hostname()) {
+		req.Header.Del("Authorization")
+		if h.apiKeyHeader != "" {
+			req.Header.Del(h.apiKeyHeader)
+		}
+	}
+	return nil
 }
 
 // SetBasicAuth configures basic authentication for HTTP requests
@@ -80,6 +164,104 @@ func (h *HTTPClient) SetBasicAuth(username, password string) {
 	h.password = password
 }
 
+// SetNTLMAuth configures NTLM/Negotiate authentication for HTTP requests.
+// The transport is wrapped with a challenge-aware round-tripper that answers
+// NTLM and Negotiate challenges using the given credentials (DOMAIN\user or
+// user@domain). Negotiate is answered with NTLM only: Kerberos/SPNEGO tickets
+// are not supported. Servers that do not issue such a challenge receive the
+// credentials as basic auth, so SetAuthHosts should limit them to the hosts
+// meant to receive them.
+func (h *HTTPClient) SetNTLMAuth(username, password string) {
+	h.authType = "ntlm"
+	h.username = username
+	h.password = password
+	h.buildTransport()
+}
+
+// Use adds middleware around the transport of every request, including
+// robots.txt and external link checks. Middleware added first is outermost:
+// it sees the request first and the response last. Authentication and
+// custom headers are already set on the request it receives.
+func (h *HTTPClient) Use(middleware ...Middleware) {
+	h.middleware = append(h.middleware, middleware...)
+	h.buildTransport()
+}
+
+// buildTransport chains the middleware and the NTLM negotiator, if
+// configured, in front of the underlying transport, which records responses
+// or is replaced by recorded ones
+func (h *HTTPClient) buildTransport() {
+	var rt http.RoundTripper = h.transport
+	switch {
+	case h.replayDir != "":
+		rt = &replayTransport{dir: h.replayDir}
+	case h.recordDir != "":
+		rt = &recordingTransport{dir: h.recordDir, next: rt, maxBodySize: h.maxBodySize}
+	}
+	if h.authType == "ntlm" {
+		rt = ntlmssp.Negotiator{RoundTripper: rt}
+	}
+	for i := len(h.middleware) - 1; i >= 0; i-- {
+		rt = h.middleware[i](rt)
+	}
+	h.client.Transport = rt
+}
+
+// SetClientCertificate loads a PEM-encoded certificate and key pair and
+// presents it during TLS handshakes, for crawling mutual-TLS protected services
+func (h *HTTPClient) SetClientCertificate(certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS client certificate: %w", err)
+	}
+
+	h.tlsConfig().Certificates = []tls.Certificate{cert}
+	return nil
+}
+
+// SetCAFile trusts the PEM-encoded CA certificates in caFile in addition to
+// the system roots, for servers with private or self-signed certificates
+func (h *HTTPClient) SetCAFile(caFile string) error {
+	pemData, err := os.ReadFile(caFile) // #nosec G304 -- path comes from user configuration
+	if err != nil {
+		return fmt.Errorf("failed to read TLS CA file: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pemData) {
+		return fmt.Errorf("no PEM certificates found in TLS CA file %s", caFile)
+	}
+
+	h.tlsConfig().RootCAs = pool
+	return nil
+}
+
+// SetInsecureSkipVerify disables TLS certificate verification. Only intended
+// for staging environments with self-signed certificates.
+func (h *HTTPClient) SetInsecureSkipVerify(skip bool) {
+	h.tlsConfig().InsecureSkipVerify = skip // #nosec G402 -- explicit opt-in
+}
+
+// SetDNSCache routes hostname resolution through the given DNS cache
+func (h *HTTPClient) SetDNSCache(cache *DNSCache) {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	h.transport.DialContext = cache.DialContext(dialer)
+}
+
+// tlsConfig returns the transport's TLS configuration, creating it if needed
+func (h *HTTPClient) tlsConfig() *tls.Config {
+	if h.transport.TLSClientConfig == nil {
+		h.transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return h.transport.TLSClientConfig
+}
+
 // SetBearerAuth configures bearer token authentication for HTTP requests
 func (h *HTTPClient) SetBearerAuth(token string) {
 	h.authType = "bearer"
@@ -93,6 +275,20 @@ func (h *HTTPClient) SetAPIKeyAuth(header, value string) {
 	h.apiKeyValue = value
 }
 
+// SetAuthHosts limits the hosts that basic, NTLM, bearer and API key
+// credentials are sent to. Patterns are hostnames, optionally with a leading
+// "*." for subdomains, as in allowed_hosts. Requests to other hosts, and
+// redirects to them, are sent without credentials. A nil list, the default,
+// sends them to every host; an empty one to none.
+func (h *HTTPClient) SetAuthHosts(patterns []string) {
+	h.authHosts = patterns
+}
+
+// sendsCredentials reports whether requests to host carry the credentials
+func (h *HTTPClient) sendsCredentials(host string) bool {
+	return h.authHosts == nil || matchesAnyHost(h.authHosts, host)
+}
+
 // SetCustomHeaders sets custom HTTP headers
 func (h *HTTPClient) SetCustomHeaders(headers map[string]string) {
 	if h.customHeaders == nil {
@@ -111,80 +307,140 @@ func (h *HTTPClient) AddCustomHeader(name, value string) {
 	h.customHeaders[name] = value
 }
 
+// SetMaxResponseSize limits how many body bytes Get reads. Larger responses
+// are aborted with ErrResponseTooLarge instead of being buffered in memory.
+// Zero disables the limit.
+func (h *HTTPClient) SetMaxResponseSize(maxBytes int64) {
+	h.maxBodySize = maxBytes
+	h.buildTransport()
+}
+
 // Get performs an HTTP GET request with comprehensive performance tracking.
 // It measures DNS lookup time, TCP connection time, TLS handshake time,
 // time to first byte (TTFB), and total download time. The response includes
 // both the content and detailed performance metrics.
 func (h *HTTPClient) Get(ctx context.Context, url string) (*HTTPResponse, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	return h.GetFiltered(ctx, url, nil)
+}
 
-	// Set User-Agent
-	req.Header.Set("User-Agent", h.userAgent)
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
-	// Don't set Accept-Encoding manually - let Go handle compression automatically
+// GetFiltered performs a GET like Get, but once the response headers arrive
+// it calls accept with the Content-Type. If accept returns false the body is
+// not downloaded: the connection is closed and the response is returned with
+// BodySkipped set. A nil accept downloads every body.
+func (h *HTTPClient) GetFiltered(ctx context.Context, url string, accept func(contentType string) bool) (*HTTPResponse, error) {
+	return h.GetStreamed(ctx, url, accept, func(resp *HTTPResponse, body io.Reader) error {
+		var err error
+		resp.Body, err = io.ReadAll(body)
+		return err
+	})
+}
 
-	// Set basic authentication if configured
-	switch h.authType {
-	case "basic":
-		if h.username != "" && h.password != "" {
-			req.SetBasicAuth(h.username, h.password)
-		}
-	case "bearer":
-		if h.bearerToken != "" {
-			req.Header.Set("Authorization", "Bearer "+h.bearerToken)
-		}
-	case "apikey":
-		if h.apiKeyHeader != "" && h.apiKeyValue != "" {
-			req.Header.Set(h.apiKeyHeader, h.apiKeyValue)
-		}
+// GetStreamed performs a GET like GetFiltered, but instead of buffering the
+// body it passes it to read as a stream, along with the response headers.
+// The stream enforces the maximum response size. Whatever read leaves unread
+// is discarded afterwards, so BodySize and DownloadTime cover the whole body.
+// A failed body read fails the request even when read ignored the error.
+func (h *HTTPClient) GetStreamed(ctx context.Context, url string, accept func(contentType string) bool, read func(resp *HTTPResponse, body io.Reader) error) (*HTTPResponse, error) {
+	ctx, span := tracer.Start(ctx, "HTTP GET", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("http.request.method", "GET"), attribute.String("url.full", url)))
+	resp, err := h.getStreamed(ctx, url, accept, read)
+	if resp != nil {
+		span.SetAttributes(
+			attribute.Int("http.response.status_code", resp.StatusCode),
+			attribute.Int64("http.response.body.size", resp.BodySize),
+			attribute.Int("linktadoru.redirects", len(resp.Redirects)),
+		)
 	}
+	endSpan(span, err)
+	return resp, err
+}
 
-	// Set custom headers
-	for name, value := range h.customHeaders {
-		req.Header.Set(name, value)
+// getStreamed performs the request of GetStreamed. The DNS lookup, connect,
+// TLS handshake and wait for the first byte are added as spans under the
+// request span in ctx.
+func (h *HTTPClient) getStreamed(ctx context.Context, url string, accept func(contentType string) bool, read func(resp *HTTPResponse, body io.Reader) error) (*HTTPResponse, error) {
+	req, err := h.newRequest(ctx, "GET", url)
+	if err != nil {
+		return nil, err
 	}
+	h.authorize(req)
 
-	// Setup performance tracking
-	var metrics HTTPMetrics
+	// Setup performance tracking. The dial of a connection may still run
+	// after the request was served by another one, so the trace callbacks
+	// share everything they record under a lock.
+	var traceMu sync.Mutex
+	var traced HTTPMetrics
 	var dnsStart, dnsDone, connectStart, connectDone, tlsStart, tlsDone time.Time
 	var firstByteTime time.Time
+	var connectAddr string
+	var certificates []TLSCertificate
 
 	trace := &httptrace.ClientTrace{
 		DNSStart: func(info httptrace.DNSStartInfo) {
+			traceMu.Lock()
+			defer traceMu.Unlock()
 			dnsStart = time.Now()
 		},
 		DNSDone: func(info httptrace.DNSDoneInfo) {
+			traceMu.Lock()
+			defer traceMu.Unlock()
 			dnsDone = time.Now()
-			metrics.DNSLookup = dnsDone.Sub(dnsStart)
+			traced.DNSLookup = dnsDone.Sub(dnsStart)
 		},
 		ConnectStart: func(network, addr string) {
+			traceMu.Lock()
+			defer traceMu.Unlock()
 			connectStart = time.Now()
+			connectAddr = addr
 		},
 		ConnectDone: func(network, addr string, err error) {
+			traceMu.Lock()
+			defer traceMu.Unlock()
 			connectDone = time.Now()
-			metrics.TCPConnect = connectDone.Sub(connectStart)
+			traced.TCPConnect = connectDone.Sub(connectStart)
 		},
 		TLSHandshakeStart: func() {
+			traceMu.Lock()
+			defer traceMu.Unlock()
 			tlsStart = time.Now()
 		},
 		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			traceMu.Lock()
+			defer traceMu.Unlock()
 			tlsDone = time.Now()
-			metrics.TLSHandshake = tlsDone.Sub(tlsStart)
+			traced.TLSHandshake = tlsDone.Sub(tlsStart)
+			if err == nil {
+				if host, _, err := net.SplitHostPort(connectAddr); err == nil {
+					certificates = appendCertificate(certificates, state, host)
+				}
+			}
 		},
 		GotFirstResponseByte: func() {
+			traceMu.Lock()
+			defer traceMu.Unlock()
 			firstByteTime = time.Now()
 		},
 	}
 
-	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	var redirects []RedirectHop
+	ctx = context.WithValue(httptrace.WithClientTrace(req.Context(), trace), redirectsKey{}, &redirects)
+	if accept != nil {
+		ctx = context.WithValue(ctx, acceptKey{}, accept)
+	}
+	req = req.WithContext(ctx)
 
-	// Perform request
+	// Perform request, then take what the trace recorded so far
 	startTime := time.Now()
 	resp, err := h.client.Do(req)
+	traceMu.Lock()
+	metrics := traced
+	firstByte := firstByteTime
+	responseCertificates := slices.Clone(certificates)
+	addPhaseSpan(ctx, "dns", dnsStart, dnsDone)
+	addPhaseSpan(ctx, "connect", connectStart, connectDone)
+	addPhaseSpan(ctx, "tls", tlsStart, tlsDone)
+	traceMu.Unlock()
+	addPhaseSpan(ctx, "wait for first byte", startTime, firstByte)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -196,19 +452,10 @@ func (h *HTTPClient) Get(ctx context.Context, url string) (*HTTPResponse, error)
 	}()
 
 	// Calculate TTFB if we got the first byte time
-	if !firstByteTime.IsZero() {
-		metrics.TTFB = firstByteTime.Sub(startTime)
-	}
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	if !firstByte.IsZero() {
+		metrics.TTFB = firstByte.Sub(startTime)
 	}
 
-	// Calculate total download time
-	metrics.DownloadTime = time.Since(startTime)
-
 	// Parse Last-Modified header
 	var lastModified time.Time
 	if lm := resp.Header.Get("Last-Modified"); lm != "" {
@@ -217,18 +464,165 @@ func (h *HTTPClient) Get(ctx context.Context, url string) (*HTTPResponse, error)
 		}
 	}
 
-	return &HTTPResponse{
+	response := &HTTPResponse{
 		StatusCode:      resp.StatusCode,
 		Headers:         resp.Header,
-		Body:            body,
 		ContentType:     resp.Header.Get("Content-Type"),
 		ContentLength:   resp.ContentLength,
 		Server:          resp.Header.Get("Server"),
 		LastModified:    lastModified,
 		ContentEncoding: resp.Header.Get("Content-Encoding"),
-		Metrics:         metrics,
 		FinalURL:        resp.Request.URL.String(),
-	}, nil
+		Redirects:       redirects,
+		BodySkipped:     accept != nil && !accept(resp.Header.Get("Content-Type")),
+	}
+
+	// Read response body, refusing to read more than the configured maximum
+	if !response.BodySkipped {
+		body, err := h.newBodyReader(resp)
+		if err != nil {
+			return nil, err
+		}
+		readErr := read(response, body)
+		_, _ = io.Copy(io.Discard, body)
+		switch {
+		case body.err != nil:
+			return nil, body.err
+		case readErr != nil:
+			return nil, readErr
+		}
+		response.BodySize = body.size
+	}
+
+	// Calculate total download time
+	metrics.DownloadTime = time.Since(startTime)
+	response.Metrics = metrics
+	response.Certificates = responseCertificates
+	if resp.TLS != nil {
+		response.Certificates = appendCertificate(response.Certificates, *resp.TLS, resp.Request.URL.Hostname())
+	}
+
+	return response, nil
+}
+
+// newRequest creates a request carrying the client's User-Agent and Accept headers
+func (h *HTTPClient) newRequest(ctx context.Context, method, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set User-Agent
+	req.Header.Set("User-Agent", h.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
+	// Don't set Accept-Encoding manually - let Go handle compression automatically
+
+	return req, nil
+}
+
+// authorize adds the configured custom headers to req, and the configured
+// authentication if req goes to one of the auth hosts
+func (h *HTTPClient) authorize(req *http.Request) {
+	authType := h.authType
+	if !h.sendsCredentials(req.URL.Hostname()) {
+		authType = ""
+	}
+
+	// Set basic authentication if configured
+	switch authType {
+	case "basic", "ntlm":
+		// For ntlm the Negotiator converts these credentials into the handshake
+		if h.username != "" && h.password != "" {
+			req.SetBasicAuth(h.username, h.password)
+		}
+	case "bearer":
+		if h.bearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+h.bearerToken)
+		}
+	case "apikey":
+		if h.apiKeyHeader != "" && h.apiKeyValue != "" {
+			req.Header.Set(h.apiKeyHeader, h.apiKeyValue)
+		}
+	}
+
+	// Set custom headers
+	for name, value := range h.customHeaders {
+		req.Header.Set(name, value)
+	}
+}
+
+// Check verifies that url is reachable without downloading its content. It
+// sends a HEAD request and, when the server does not support HEAD (405 or 501),
+// retries with a GET for the first byte only. It returns the final status code
+// after redirects and the method that produced it.
+//
+// Check is meant for links to third-party hosts, so authentication and custom
+// headers are never sent.
+func (h *HTTPClient) Check(ctx context.Context, url string) (statusCode int, method string, err error) {
+	statusCode, err = h.checkWith(ctx, http.MethodHead, url)
+	if err != nil || (statusCode != http.StatusMethodNotAllowed && statusCode != http.StatusNotImplemented) {
+		return statusCode, http.MethodHead, err
+	}
+	statusCode, err = h.checkWith(ctx, http.MethodGet, url)
+	return statusCode, http.MethodGet, err
+}
+
+// checkWith performs a single Check request and discards the body
+func (h *HTTPClient) checkWith(ctx context.Context, method, url string) (int, error) {
+	req, err := h.newRequest(ctx, method, url)
+	if err != nil {
+		return 0, err
+	}
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// bodyReader reads a response body up to the configured maximum size,
+// counting the bytes read and keeping the first read error
+type bodyReader struct {
+	r       io.Reader
+	maxSize int64 // 0 = unlimited
+	size    int64
+	err     error
+}
+
+// newBodyReader returns a reader for the response body. A declared
+// Content-Length over the limit fails before any body is read.
+func (h *HTTPClient) newBodyReader(resp *http.Response) (*bodyReader, error) {
+	if h.maxBodySize > 0 && resp.ContentLength > h.maxBodySize {
+		return nil, fmt.Errorf("%w: Content-Length %d exceeds %d bytes", ErrResponseTooLarge, resp.ContentLength, h.maxBodySize)
+	}
+	return &bodyReader{r: resp.Body, maxSize: h.maxBodySize}, nil
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	// Read one byte past the limit to detect oversized bodies without a Content-Length
+	if b.maxSize > 0 && int64(len(p)) > b.maxSize+1-b.size {
+		p = p[:b.maxSize+1-b.size]
+	}
+	n, err := b.r.Read(p)
+	b.size += int64(n)
+	switch {
+	case b.maxSize > 0 && b.size > b.maxSize:
+		b.err = fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, b.maxSize)
+		return 0, b.err
+	case err != nil && !errors.Is(err, io.EOF):
+		b.err = fmt.Errorf("failed to read response body: %w", err)
+		return n, b.err
+	}
+	return n, err
 }
 
 // Close closes the HTTP client
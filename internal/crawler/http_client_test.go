@@ -2,10 +2,24 @@ package crawler
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -102,6 +116,54 @@ func TestHTTPClientRedirect(t *testing.T) {
 	}
 }
 
+func TestHTTPClientRedirectChain(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("New page"))
+	})
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/b", http.StatusFound)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/a", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewHTTPClient("Test-Crawler/1.0", 30*time.Second)
+	defer client.Close()
+
+	resp, err := client.Get(context.Background(), server.URL+"/old")
+	if err != nil {
+		t.Fatalf("Failed to get URL: %v", err)
+	}
+	want := []RedirectHop{
+		{StatusCode: http.StatusMovedPermanently, FromURL: server.URL + "/old", ToURL: server.URL + "/moved"},
+		{StatusCode: http.StatusFound, FromURL: server.URL + "/moved", ToURL: server.URL + "/new"},
+	}
+	if !reflect.DeepEqual(resp.Redirects, want) {
+		t.Errorf("Expected redirects %+v, got %+v", want, resp.Redirects)
+	}
+
+	// A loop stops when a hop repeats: the redirect response is returned
+	resp, err = client.Get(context.Background(), server.URL+"/a")
+	if err != nil {
+		t.Fatalf("Failed to get URL: %v", err)
+	}
+	if resp.StatusCode != http.StatusFound || resp.FinalURL != server.URL+"/a" {
+		t.Errorf("Expected the 302 from /a, got %d from %s", resp.StatusCode, resp.FinalURL)
+	}
+	if len(resp.Redirects) != 2 || resp.Redirects[1].ToURL != server.URL+"/a" {
+		t.Errorf("Expected the hops up to the loop, got %+v", resp.Redirects)
+	}
+}
+
 func TestHTTPClientTimeout(t *testing.T) {
 	// Create slow server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -286,6 +348,304 @@ func TestHTTPClientSetBasicAuth(t *testing.T) {
 	}
 }
 
+func TestHTTPClientNTLMAuth(t *testing.T) {
+	// Server issues an NTLM challenge and accepts any NTLM negotiate message.
+	// A full handshake is not simulated; this checks the challenge-aware
+	// round-tripper answers the challenge instead of returning the 401.
+	var sawNegotiate bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "NTLM ") {
+			w.Header().Set("WWW-Authenticate", "NTLM")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		msg, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(auth, "NTLM "))
+		if err != nil || !strings.HasPrefix(string(msg), "NTLMSSP\x00") {
+			t.Errorf("Expected NTLMSSP negotiate message, got %q", auth)
+		}
+		sawNegotiate = true
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("Authenticated!"))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient("Test-Crawler/1.0", 30*time.Second)
+	defer client.Close()
+	client.SetNTLMAuth(`CORP\user`, "secret")
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Failed to get URL: %v", err)
+	}
+
+	if !sawNegotiate {
+		t.Error("Expected server to receive an NTLM negotiate message")
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status code 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPClientAuthHosts(t *testing.T) {
+	// A host answering 401 without an NTLM challenge makes the negotiator
+	// resend the credentials as basic auth, so hosts other than the auth
+	// hosts must never be given them, not even through a redirect
+	var mu sync.Mutex
+	received := map[string]string{}
+	offHost := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received[r.URL.Path] += r.Header.Get("Authorization") + r.Header.Get("X-API-Key")
+		mu.Unlock()
+		w.Header().Set("WWW-Authenticate", `Basic realm="external"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer offHost.Close()
+	authHost := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, offHost.URL+"/redirected", http.StatusFound)
+			return
+		}
+		if _, _, ok := r.BasicAuth(); !ok && r.Header.Get("X-API-Key") == "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="intranet"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("Authenticated!"))
+	}))
+	defer authHost.Close()
+	// Both servers listen on 127.0.0.1; reach the auth host as localhost
+	authURL := strings.Replace(authHost.URL, "127.0.0.1", "localhost", 1)
+
+	for name, configure := range map[string]func(*HTTPClient){
+		"ntlm":   func(c *HTTPClient) { c.SetNTLMAuth(`CORP\user`, "secret") },
+		"apikey": func(c *HTTPClient) { c.SetAPIKeyAuth("X-API-Key", "secret") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			mu.Lock()
+			clear(received)
+			mu.Unlock()
+			client := NewHTTPClient("Test-Crawler/1.0", 30*time.Second)
+			defer client.Close()
+			configure(client)
+			client.SetAuthHosts([]string{"localhost"})
+
+			resp, err := client.Get(context.Background(), authURL+"/")
+			if err != nil {
+				t.Fatalf("Failed to get auth host: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Expected the auth host to accept the credentials, got %d", resp.StatusCode)
+			}
+
+			for _, target := range []string{offHost.URL + "/direct", authURL + "/redirect"} {
+				resp, err := client.Get(context.Background(), target)
+				if err != nil {
+					t.Fatalf("Failed to get %s: %v", target, err)
+				}
+				if resp.StatusCode != http.StatusUnauthorized {
+					t.Errorf("Expected 401 from the off-host server for %s, got %d", target, resp.StatusCode)
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, path := range []string{"/direct", "/redirected"} {
+				if credentials, ok := received[path]; !ok || credentials != "" {
+					t.Errorf("Expected a request to %s without credentials, got %q (requested: %v)", path, credentials, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPClientClientCertificate(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			t.Error("Expected client certificate to be presented")
+		} else if cn := r.TLS.PeerCertificates[0].Subject.CommonName; cn != "crawler-client" {
+			t.Errorf("Expected client certificate CN 'crawler-client', got '%s'", cn)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	certFile, keyFile := writeTestKeyPair(t, t.TempDir(), "crawler-client")
+
+	client := NewHTTPClient("Test-Crawler/1.0", 30*time.Second)
+	defer client.Close()
+	if err := client.SetClientCertificate(certFile, keyFile); err != nil {
+		t.Fatalf("Failed to set client certificate: %v", err)
+	}
+	// Trust the test server's self-signed certificate
+	client.tlsConfig().RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Failed to get URL: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status code 200, got %d", resp.StatusCode)
+	}
+
+	if err := client.SetClientCertificate(certFile, "/nonexistent.key"); err == nil {
+		t.Error("Expected error for missing key file")
+	}
+}
+
+func TestHTTPClientCustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Without the CA the self-signed certificate is rejected
+	client := NewHTTPClient("Test-Crawler/1.0", 30*time.Second)
+	defer client.Close()
+	if _, err := client.Get(context.Background(), server.URL); err == nil {
+		t.Fatal("Expected certificate verification error")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	caClient := NewHTTPClient("Test-Crawler/1.0", 30*time.Second)
+	defer caClient.Close()
+	if err := caClient.SetCAFile(caFile); err != nil {
+		t.Fatalf("Failed to set CA file: %v", err)
+	}
+	resp, err := caClient.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected request to succeed with custom CA: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status code 200, got %d", resp.StatusCode)
+	}
+
+	// A file without certificates is rejected
+	emptyFile := filepath.Join(t.TempDir(), "empty.pem")
+	_ = os.WriteFile(emptyFile, []byte("not a certificate"), 0600)
+	if err := caClient.SetCAFile(emptyFile); err == nil {
+		t.Error("Expected error for CA file without certificates")
+	}
+}
+
+func TestHTTPClientInsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient("Test-Crawler/1.0", 30*time.Second)
+	defer client.Close()
+	client.SetInsecureSkipVerify(true)
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected request to succeed without verification: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status code 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPClientMaxResponseSize(t *testing.T) {
+	body := strings.Repeat("x", 1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// Flushing before writing forces chunked encoding without a Content-Length
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient("Test-Crawler/1.0", 30*time.Second)
+	defer client.Close()
+
+	client.SetMaxResponseSize(1024)
+	resp, err := client.Get(context.Background(), server.URL+"/exact")
+	if err != nil {
+		t.Fatalf("Expected body at the limit to succeed: %v", err)
+	}
+	if len(resp.Body) != 1024 {
+		t.Errorf("Expected 1024 body bytes, got %d", len(resp.Body))
+	}
+
+	client.SetMaxResponseSize(512)
+	for _, path := range []string{"/content-length", "/chunked"} {
+		if _, err := client.Get(context.Background(), server.URL+path); !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("%s: expected ErrResponseTooLarge, got %v", path, err)
+		}
+	}
+}
+
+func TestPageProcessorResponseTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(strings.Repeat("<p>big</p>", 1000)))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient("Test-Crawler/1.0", 30*time.Second)
+	defer client.Close()
+	client.SetMaxResponseSize(100)
+
+	result, err := NewPageProcessor(client).Process(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if result.Error == nil || result.Error.ErrorType != "response_too_large" {
+		t.Errorf("Expected response_too_large error, got %+v", result.Error)
+	}
+}
+
+func TestHTTPClientGetStreamed(t *testing.T) {
+	body := "<html><head><title>Streamed</title></head><body>" + strings.Repeat("<p>text</p>", 1000) + "</body></html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient("Test-Crawler/1.0", 30*time.Second)
+	defer client.Close()
+
+	// The client discards what read leaves unread, still counting it
+	resp, err := client.GetStreamed(context.Background(), server.URL, nil, func(resp *HTTPResponse, r io.Reader) error {
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected headers before the body, got status %d", resp.StatusCode)
+		}
+		_, err := io.ReadFull(r, make([]byte, 10))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("GetStreamed failed: %v", err)
+	}
+	if resp.Body != nil || resp.BodySize != int64(len(body)) {
+		t.Errorf("Expected an unbuffered body of %d bytes, got %d buffered and BodySize %d", len(body), len(resp.Body), resp.BodySize)
+	}
+
+	result, err := NewPageProcessor(client).Process(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if result.Page == nil || result.Page.Title != "Streamed" || result.Page.ResponseSize != int64(len(body)) {
+		t.Errorf("Expected parsed page of %d bytes, got %+v", len(body), result.Page)
+	}
+
+	client.SetMaxResponseSize(100)
+	if _, err := client.GetStreamed(context.Background(), server.URL, nil, func(*HTTPResponse, io.Reader) error { return nil }); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge for an unread oversized body, got %v", err)
+	}
+}
+
 func TestHTTPClientBearerAuth(t *testing.T) {
 	// Create test server that requires bearer auth
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -476,3 +836,131 @@ func TestHTTPClientAddCustomHeader(t *testing.T) {
 		t.Errorf("Expected 'another-value' for X-Another-Header, got '%s'", client.customHeaders["X-Another-Header"])
 	}
 }
+
+// writeTestKeyPair generates a self-signed certificate and writes the PEM
+// certificate and key into dir, returning their paths
+func writeTestKeyPair(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return certFile, keyFile
+}
+
+func TestHTTPClientCheck(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Expected no credentials on a check, got Authorization %q", auth)
+		}
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if r.Header.Get("Range") != "bytes=0-0" {
+				t.Errorf("Expected ranged GET, got Range %q", r.Header.Get("Range"))
+			}
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte("x"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient("Test-Crawler/1.0", 30*time.Second)
+	defer client.Close()
+	client.SetBearerAuth("secret")
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantMethod string
+		wantCalls  []string
+	}{
+		{"/ok", http.StatusOK, http.MethodHead, []string{"HEAD"}},
+		{"/missing", http.StatusNotFound, http.MethodHead, []string{"HEAD"}},
+		{"/no-head", http.StatusPartialContent, http.MethodGet, []string{"HEAD", "GET"}},
+	}
+
+	for _, tt := range tests {
+		methods = nil
+		status, method, err := client.Check(context.Background(), server.URL+tt.path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.path, err)
+		}
+		if status != tt.wantStatus || method != tt.wantMethod {
+			t.Errorf("%s: got %d via %s, expected %d via %s", tt.path, status, method, tt.wantStatus, tt.wantMethod)
+		}
+		if strings.Join(methods, ",") != strings.Join(tt.wantCalls, ",") {
+			t.Errorf("%s: server saw %v, expected %v", tt.path, methods, tt.wantCalls)
+		}
+	}
+}
+
+func TestHTTPClientCertificates(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient("Test-Crawler/1.0", 30*time.Second)
+	defer client.Close()
+	client.SetInsecureSkipVerify(true)
+
+	// The second request reuses the connection, so only resp.TLS reports the certificate
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if len(resp.Certificates) != 1 {
+			t.Fatalf("Request %d: expected 1 certificate, got %+v", i, resp.Certificates)
+		}
+		cert := resp.Certificates[0]
+		if cert.Host != "127.0.0.1" || cert.NotAfter.IsZero() || len(cert.Fingerprint) != 64 {
+			t.Errorf("Unexpected certificate %+v", cert)
+		}
+		if cert.NotAfter != server.Certificate().NotAfter.UTC() || cert.Issuer != server.Certificate().Issuer.String() {
+			t.Errorf("Certificate does not match the server's: %+v", cert)
+		}
+	}
+
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+	if resp, err := client.Get(context.Background(), plain.URL); err != nil || len(resp.Certificates) != 0 {
+		t.Errorf("Expected no certificate over plain HTTP, got %+v (%v)", resp, err)
+	}
+}
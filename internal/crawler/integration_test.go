@@ -508,3 +508,53 @@ func (h *HostFilteringTestStorage) GetRetryablePages(maxRetries int) ([]URLItem,
 func (h *HostFilteringTestStorage) RequeueErrorPages(maxRetries int) (int, error) {
 	return 0, nil
 }
+
+// endlessQueueStorage always has another URL to crawl
+type endlessQueueStorage struct {
+	MockStorage
+	url  string
+	mu   sync.Mutex
+	next int
+}
+
+func (s *endlessQueueStorage) GetNextFromQueue() (*URLItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next++
+	return &URLItem{ID: s.next, URL: s.url}, nil
+}
+
+// TestStartStopsAtContextDeadline verifies a crawl that would never finish on
+// its own ends promptly once its context deadline passes (--timeout-total)
+func TestStartStopsAtContextDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	config := &config.CrawlConfig{
+		Concurrency:     2,
+		RequestDelay:    0.01,
+		RequestTimeout:  30 * time.Second,
+		UserAgent:       "LinkTadoru-Test/1.0",
+		IgnoreRobotsTxt: true,
+	}
+	crawler, err := NewCrawler(config, &endlessQueueStorage{url: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := crawler.Start(ctx, nil); err != nil {
+		t.Errorf("Start() returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Start() returned after %v, expected shortly after the deadline", elapsed)
+	}
+}
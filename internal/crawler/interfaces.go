@@ -9,6 +9,9 @@ import (
 type Crawler interface {
 	Start(ctx context.Context, seedURLs []string) error
 	Stop() error
+	Pause()  // Stop claiming new URLs; in-flight pages are finished
+	Resume() // Claim URLs again after Pause
+	Paused() bool
 	GetStats() CrawlStats
 }
 
@@ -22,10 +25,12 @@ type Storage interface {
 	// Queue management (using pages table)
 	AddToQueue(urls []string) error
 	GetNextFromQueue() (*URLItem, error)
+	GetNextBatchFromQueue(n int) ([]URLItem, error) // Claims up to n pending items at once
 	UpdatePageStatus(id int, status string) error
 
 	// Page results (updates existing queued entry)
 	SavePageResult(id int, page *PageData) error
+	SavePageResults(pages []CompletedPage) error // Saves several pages in one transaction
 	SavePageError(id int, errorType, errorMessage string) error
 	SavePageSkipped(id int, reason, message string) error
 
@@ -34,10 +39,15 @@ type Storage interface {
 	SaveLinks(links []*LinkData) error // Batch link saving
 	SaveError(err *CrawlError) error
 
+	// External link checks (check_external: head)
+	SaveExternalCheck(check *ExternalCheck) error
+	HasExternalCheck(url string) bool
+
 	// Queue status
 	GetQueueStatus() (pending int, processing int, completed int, errors int, err error)
 	GetProcessingItems() ([]URLItem, error)
-	CleanupStaleProcessing(timeout time.Duration) error
+	GetQueuedURLs(limit int) ([]string, error) // Oldest pending/processing URLs
+	CleanupStaleProcessing(timeout time.Duration) (int, error)
 	HasQueuedItems() (bool, error) // Check if queue has any work items (pending or processing)
 
 	// Retry management
@@ -60,8 +70,15 @@ type CrawlStats struct {
 	PagesCrawled int
 	PagesQueued  int
 	ErrorCount   int
+	URLsDropped  int // URLs not queued because max_queue_size was reached (all runs on the database)
 	StartTime    time.Time
 	Duration     time.Duration
+	RunID        string // UUID stamped on requests via run_header (empty when disabled)
+
+	// Set when Start returns
+	StopReason      string   // Why the run ended (StopReason* constants)
+	RemainingQueued int      // URLs still pending or processing
+	RemainingSample []string // Oldest remaining URLs (at most 100)
 }
 
 // PageResult represents the result of processing a single page
@@ -69,4 +86,8 @@ type PageResult struct {
 	Page  *PageData
 	Links []*LinkData
 	Error *CrawlError
+	Skip  *CrawlSkip // Set when the page was deliberately not downloaded
+
+	// NoFollow is set when the page's links are saved but must not be queued
+	NoFollow bool
 }
@@ -22,6 +22,14 @@ func (m *MockStorage) SaveError(crawlError *CrawlError) error {
 	return nil
 }
 
+func (m *MockStorage) SaveExternalCheck(check *ExternalCheck) error {
+	return nil
+}
+
+func (m *MockStorage) HasExternalCheck(url string) bool {
+	return false
+}
+
 func (m *MockStorage) Close() error {
 	return nil
 }
@@ -34,6 +42,10 @@ func (m *MockStorage) GetNextFromQueue() (*URLItem, error) {
 	return nil, nil
 }
 
+func (m *MockStorage) GetNextBatchFromQueue(n int) ([]URLItem, error) {
+	return nil, nil
+}
+
 func (m *MockStorage) UpdatePageStatus(id int, status string) error {
 	return nil
 }
@@ -42,6 +54,10 @@ func (m *MockStorage) SavePageResult(id int, page *PageData) error {
 	return nil
 }
 
+func (m *MockStorage) SavePageResults(pages []CompletedPage) error {
+	return nil
+}
+
 func (m *MockStorage) SavePageError(id int, errorType, errorMessage string) error {
 	return nil
 }
@@ -54,8 +70,12 @@ func (m *MockStorage) GetProcessingItems() ([]URLItem, error) {
 	return nil, nil
 }
 
-func (m *MockStorage) CleanupStaleProcessing(timeout time.Duration) error {
-	return nil
+func (m *MockStorage) GetQueuedURLs(limit int) ([]string, error) {
+	return nil, nil
+}
+
+func (m *MockStorage) CleanupStaleProcessing(timeout time.Duration) (int, error) {
+	return 0, nil
 }
 
 func (m *MockStorage) GetMeta(key string) (string, error) {
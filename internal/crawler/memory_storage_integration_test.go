@@ -0,0 +1,57 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage/memory"
+)
+
+// A crawl into the in-memory backend behaves like one into SQLite: pages are
+// crawled once, excluded links stay link-graph nodes, and results are kept.
+func TestCrawlIntoMemoryStorage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(`<title>Home</title><a href="/a">A</a><a href="/b">B</a><a href="/admin/x">Admin</a>`))
+		default:
+			_, _ = w.Write([]byte(`<title>Page</title><a href="/">home</a>`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := baseCfg()
+	cfg.Concurrency = 2
+	cfg.ExcludePatterns = []string{"/admin/"}
+	cfg.SeedURLs = []string{server.URL + "/"}
+	store := memory.New()
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if stats := c.GetStats(); stats.PagesCrawled != 3 {
+		t.Errorf("PagesCrawled = %d, want 3", stats.PagesCrawled)
+	}
+	if status, _ := store.GetURLStatus(server.URL + "/admin/x"); status != "discovered" {
+		t.Errorf("/admin/x status = %q, want discovered", status)
+	}
+	if page, ok := store.Page(server.URL + "/"); !ok || page.Title != "Home" {
+		t.Errorf("Expected the home page to be saved, got %+v", page)
+	}
+	if links := store.Links(); len(links) != 5 {
+		t.Errorf("Expected 5 saved links, got %d", len(links))
+	}
+}
@@ -4,8 +4,16 @@ import "time"
 
 // URLItem represents an item in the crawl queue
 type URLItem struct {
-	ID  int    // Queue item ID for tracking
-	URL string // URL to be processed
+	ID         int    // Queue item ID for tracking
+	URL        string // URL to be processed
+	RetryCount int    // Times fetching the page failed before this claim
+}
+
+// CompletedPage is a crawled page and the queue item it completes, for
+// saving several pages at once (SavePageResults)
+type CompletedPage struct {
+	ID   int       // Queue item ID
+	Page *PageData // Crawl results
 }
 
 // PageData represents crawled page information
@@ -15,22 +23,127 @@ type PageData struct {
 	Title        string            // HTML <title> tag content
 	MetaDesc     string            // HTML <meta name="description"> content
 	MetaRobots   string            // HTML <meta name="robots"> content
-	CanonicalURL string            // HTML <link rel="canonical"> href attribute
+	XRobotsTag   string            // X-Robots-Tag directives that apply to this crawler, comma-separated
+	CanonicalURL string            // Canonical URL from <link rel="canonical">, else from the Link header
 	ContentHash  string            // Hash of page content for duplicate detection
 	TTFB         time.Duration     // Time to First Byte
 	DownloadTime time.Duration     // Total download time
 	ResponseSize int64             // Response body size in bytes
 	HTTPHeaders  map[string]string // All HTTP response headers
+	Redirects    []RedirectHop     // Redirects followed from URL, in order
 	CrawledAt    time.Time         // Timestamp when crawled (UTC)
+	Alternates   []AlternateLink   // rel="alternate" representations from HTML and Link headers
+	Rels         []PageRel         // canonical, next and prev relations from HTML and Link headers
+	Text         *PageText         // Main readable text statistics (nil unless parsed as HTML)
+	Headings     []Heading         // <h1>-<h6> elements in document order
+	Structured   []StructuredData  // JSON-LD blocks and top-level microdata items
+	Images       []Image           // <img> elements in document order
+	Extractions  []Extraction      // Results of the registered content extractors, in registration order
+	Rendered     bool              // Parsed from the DOM a headless browser rendered (render_patterns, render_fallback)
+	Certificates []TLSCertificate  // Certificates of HTTPS hosts first contacted while fetching the page
+	// Accessibility problems found in the HTML (nil unless accessibility_checks is on)
+	Accessibility []AccessibilityIssue
+}
+
+// AccessibilityIssue is an accessibility problem of one element of a page
+type AccessibilityIssue struct {
+	Issue   string // missing_alt, missing_label, empty_link, empty_button, missing_lang or heading_jump
+	Element string // Short description of the element, e.g. <input type="email" name="q">
+}
+
+// TLSCertificate is the leaf certificate an HTTPS host presented during a
+// TLS handshake
+type TLSCertificate struct {
+	Host        string    // Host name the certificate was requested for, without port
+	Subject     string    // Subject distinguished name
+	Issuer      string    // Issuer distinguished name
+	DNSNames    []string  // Subject alternative DNS names
+	NotBefore   time.Time // Start of validity (UTC)
+	NotAfter    time.Time // Expiry (UTC)
+	Fingerprint string    // SHA-256 of the DER certificate, hex
+}
+
+// Extraction is the result of one content extractor on a page
+type Extraction struct {
+	Extractor string         // Name the extractor was registered under
+	Fields    map[string]any // Extracted fields; nil when the extractor failed
+	Error     string         // Message of the extractor's error; "" on success
+}
+
+// Image is an <img> element of a page
+type Image struct {
+	URL     string // Absolute src
+	Alt     string // alt text, whitespace-trimmed
+	HasAlt  bool   // False when the alt attribute is absent
+	Width   int    // Declared width in pixels (0 = not declared)
+	Height  int    // Declared height in pixels (0 = not declared)
+	Loading string // loading attribute ("lazy", "eager" or "")
+}
+
+// Heading is an <h1>-<h6> element of a page
+type Heading struct {
+	Level int    // 1-6
+	Text  string // Text content, whitespace-trimmed
+}
+
+// StructuredData is a JSON-LD block or a top-level microdata item of a page
+type StructuredData struct {
+	Format  string   // 'json-ld' or 'microdata'
+	Types   []string // Declared types, schema.org prefix removed (e.g. "Product")
+	Payload string   // Raw JSON-LD text; empty for microdata
+	Valid   bool     // False when a JSON-LD payload is not valid JSON
 }
 
+// PageText summarizes the main readable text of an HTML page for thin-content
+// detection; navigation, headers, footers and scripts are excluded
+type PageText struct {
+	WordCount int     // Words in the main text
+	TextRatio float64 // Main text size divided by HTML size (0-1)
+	SimHash   uint64  // SimHash of the main text's word shingles; 0 without words
+	Language  string  // Detected language of the main text (ISO 639-1); "" when undetermined
+	HTMLLang  string  // lang attribute of <html> as declared
+}
+
+// AlternateLink represents an alternate representation of a page (feed,
+// print version, mobile version or translation)
+type AlternateLink struct {
+	URL      string // Absolute href of the alternate
+	Kind     string // 'feed', 'print', 'mobile', 'language' or 'other'
+	Type     string // MIME type attribute (e.g. application/rss+xml)
+	Media    string // Media query attribute
+	Hreflang string // Language of a translation
+	Title    string // Title attribute
+	Source   string // RelSourceHTML or RelSourceHeader
+}
+
+// Sources of relations declared by a page
+const (
+	RelSourceHTML   = "html"   // <link> element in the document
+	RelSourceHeader = "header" // HTTP Link response header
+)
+
+// PageRel is a canonical, next or prev relation declared by a page
+type PageRel struct {
+	Rel    string // 'canonical', 'next' or 'prev'
+	URL    string // Absolute target URL
+	Source string // RelSourceHTML or RelSourceHeader
+}
+
+// LinkTypeAsset marks a static resource (stylesheet, script, image or icon)
+// loaded by a page rather than linked from it (crawl_assets)
+const LinkTypeAsset = "asset"
+
+// LinkTypePagination marks an internal link to another page of a paginated
+// listing: rel="next"/"prev" links and URLs matching a common pagination pattern
+const LinkTypePagination = "pagination"
+
 // LinkData represents link relationships
 type LinkData struct {
 	SourceURL    string    // URL of the page containing the link
 	TargetURL    string    // URL that the link points to
 	AnchorText   string    // Text content of the <a> tag
-	LinkType     string    // 'internal' (same domain) or 'external' (different domain)
-	RelAttribute string    // Value of rel attribute ('nofollow', 'sponsored', etc.)
+	LinkType     string    // 'internal' (same domain), 'external' (different domain), LinkTypeAsset or LinkTypePagination
+	RelAttribute string    // Value of rel attribute ('nofollow', 'sponsored', etc.); the asset kind for asset links
 	CrawledAt    time.Time // Timestamp when link was discovered
 }
 
@@ -42,6 +155,22 @@ type CrawlError struct {
 	OccurredAt   time.Time // Error occurrence timestamp (UTC)
 }
 
+// ExternalCheck is the result of verifying an out-of-scope link without
+// crawling it (check_external: head)
+type ExternalCheck struct {
+	URL          string    // Checked URL
+	Method       string    // HTTP method that produced the status (HEAD, or GET when HEAD is unsupported)
+	StatusCode   int       // Final HTTP status code after redirects (0 on network failure)
+	ErrorMessage string    // Network error, if the request failed
+	CheckedAt    time.Time // Check timestamp (UTC)
+}
+
+// CrawlSkip describes why a page was skipped rather than crawled
+type CrawlSkip struct {
+	Reason  string // Skip reason (robots_txt_disallow, content_type_filtered, etc.)
+	Message string // Human-readable detail
+}
+
 // CrawlState represents the current crawling state for resume functionality
 type CrawlState struct {
 	QueueURLs    []string  // Queue of pending URLs
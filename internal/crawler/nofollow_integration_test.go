@@ -0,0 +1,60 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// With respect_nofollow, rel="nofollow" links and the links of a meta robots
+// nofollow page are saved as graph nodes but never crawled.
+func TestCrawlRespectsNofollow(t *testing.T) {
+	pages := map[string]string{
+		"/":         `<a href="/followed">F</a><a href="/login" rel="nofollow noopener">L</a>`,
+		"/followed": `<html><head><meta name="robots" content="noindex, NOFOLLOW"></head><body><a href="/deep">D</a></body></html>`,
+		"/login":    `<p>login</p>`,
+		"/deep":     `<p>deep</p>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := baseCfg()
+	cfg.RespectNofollow = true
+	cfg.SeedURLs = []string{server.URL + "/"}
+	store := newStore(t)
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	want := map[string]string{
+		"/":         "completed",
+		"/followed": "completed",
+		"/login":    "discovered",
+		"/deep":     "discovered",
+	}
+	for path, status := range want {
+		if got, _ := statusOf(t, store, server.URL+path); got != status {
+			t.Errorf("%s status = %q, want %q", path, got, status)
+		}
+	}
+}
@@ -1,8 +1,14 @@
 package crawler
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
+	"regexp"
 	"strings"
 	"time"
 
@@ -14,6 +20,17 @@ type DefaultPageProcessor struct {
 	httpClient        *HTTPClient
 	allowedSchemes    []string
 	saveExternalLinks bool
+	contentTypes      *ContentTypeFilter // Optional; nil downloads every response
+	hashAssets        bool               // Hash non-HTML response bodies
+	crawlAssets       bool               // Emit asset links for stylesheets, scripts, images and icons
+	respectXRobotsTag bool               // Do not queue links of X-Robots-Tag nofollow pages
+	respectNofollow   bool               // Do not queue links of meta robots nofollow pages
+	extractors        []namedExtractor   // Content extractors run on HTML pages (see AddExtractor)
+	renderer          Renderer           // Optional; renders the pages matching renderPatterns
+	renderPatterns    []*regexp.Regexp
+	renderFallback    bool               // Render 200 pages without links that look like single-page applications
+	certificates      certificateTracker // TLS certificates already attached to a page
+	accessibility     bool               // Record the accessibility issues of HTML pages
 }
 
 // NewPageProcessor creates a new page processor with default schemes
@@ -35,26 +52,123 @@ func NewPageProcessorWithConfig(httpClient *HTTPClient, allowedSchemes []string,
 	}
 }
 
+// SetRenderer renders the pages whose URL matches one of patterns with
+// renderer and parses the rendered HTML instead of the response
+func (p *DefaultPageProcessor) SetRenderer(renderer Renderer, patterns []*regexp.Regexp) {
+	p.renderer = renderer
+	p.renderPatterns = patterns
+}
+
+// shouldRender reports whether the page at url is rendered before parsing
+func (p *DefaultPageProcessor) shouldRender(url string) bool {
+	if p.renderer == nil {
+		return false
+	}
+	for _, re := range p.renderPatterns {
+		if re.MatchString(url) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRenderFallback renders pages that answered 200 with HTML without any
+// link but with the markers of a single-page application (see looksLikeSPA),
+// and parses the rendered HTML instead. It needs a renderer (SetRenderer).
+func (p *DefaultPageProcessor) SetRenderFallback(enabled bool) {
+	p.renderFallback = enabled
+}
+
+// render returns the HTML of the page at url as rendered by the browser, or
+// nil when rendering failed
+func (p *DefaultPageProcessor) render(ctx context.Context, url string) []byte {
+	_, span := tracer.Start(ctx, "render page")
+	rendered, err := p.renderer.Render(ctx, url)
+	endSpan(span, err)
+	if err != nil {
+		slog.Warn("Failed to render page; parsing the raw response", "url", url, "error", err)
+		return nil
+	}
+	return rendered
+}
+
+// SetContentTypeFilter skips downloading responses whose Content-Type the filter rejects
+func (p *DefaultPageProcessor) SetContentTypeFilter(filter *ContentTypeFilter) {
+	p.contentTypes = filter
+}
+
+// SetHashAssets records a SHA-256 hash of successful non-HTML responses
+// (images, PDFs, downloads) in ContentHash. The body itself is never stored.
+func (p *DefaultPageProcessor) SetHashAssets(enabled bool) {
+	p.hashAssets = enabled
+}
+
+// SetCrawlAssets adds the stylesheets, scripts, images and icons a page loads
+// to its links, with link type "asset" and the asset kind in RelAttribute, so
+// they are fetched and their status codes recorded like pages
+func (p *DefaultPageProcessor) SetCrawlAssets(enabled bool) {
+	p.crawlAssets = enabled
+}
+
+// SetRespectXRobotsTag marks the links of pages whose X-Robots-Tag header
+// applies "nofollow" (or "none") to the crawler as not to be queued. The
+// directives are recorded in XRobotsTag either way.
+func (p *DefaultPageProcessor) SetRespectXRobotsTag(enabled bool) {
+	p.respectXRobotsTag = enabled
+}
+
+// SetRespectNofollow marks the links of pages whose <meta name="robots">
+// includes "nofollow" (or "none") as not to be queued
+func (p *DefaultPageProcessor) SetRespectNofollow(enabled bool) {
+	p.respectNofollow = enabled
+}
+
+// SetAccessibilityChecks records the accessibility issues found in the static
+// HTML of pages (see parser.AccessibilityIssue) in PageData.Accessibility
+func (p *DefaultPageProcessor) SetAccessibilityChecks(enabled bool) {
+	p.accessibility = enabled
+}
+
+// userAgentProduct returns the lower-cased product token of a User-Agent
+// ("linktadoru" for "LinkTadoru/1.0 (+https://...)"), which X-Robots-Tag
+// directives may be scoped to
+func userAgentProduct(userAgent string) string {
+	product, _, _ := strings.Cut(strings.TrimSpace(userAgent), "/")
+	if fields := strings.Fields(product); len(fields) > 0 {
+		product = fields[0]
+	}
+	return strings.ToLower(product)
+}
+
 // Process processes a single page
 func (p *DefaultPageProcessor) Process(ctx context.Context, url string) (*PageResult, error) {
 	// Fetch the page
-	resp, err := p.httpClient.Get(ctx, url)
+	var accept func(string) bool
+	if p.contentTypes != nil {
+		accept = p.contentTypes.Allows
+	}
+	body := pageBody{render: p.shouldRender(url)}
+	resp, err := p.httpClient.GetStreamed(ctx, url, accept, func(resp *HTTPResponse, r io.Reader) error {
+		return p.readBody(ctx, resp, r, &body)
+	})
 	if err != nil {
 		return &PageResult{
 			Error: &CrawlError{
 				URL:          url,
-				ErrorType:    "network_error",
+				ErrorType:    transportErrorType(err),
 				ErrorMessage: err.Error(),
 				OccurredAt:   time.Now().UTC(),
 			},
 		}, nil
 	}
 
-	// Check if content is HTML
-	isHTML := false
-	if ct := resp.ContentType; ct != "" {
-		isHTML = strings.HasPrefix(ct, "text/html") ||
-			strings.HasPrefix(ct, "application/xhtml+xml")
+	if resp.BodySkipped {
+		return &PageResult{
+			Skip: &CrawlSkip{
+				Reason:  "content_type_filtered",
+				Message: fmt.Sprintf("Content-Type %q is not crawled (status %d)", resp.ContentType, resp.StatusCode),
+			},
+		}, nil
 	}
 
 	// Convert HTTP headers to map[string]string
@@ -72,8 +186,10 @@ func (p *DefaultPageProcessor) Process(ctx context.Context, url string) (*PageRe
 		StatusCode:   resp.StatusCode,
 		TTFB:         resp.Metrics.TTFB,
 		DownloadTime: resp.Metrics.DownloadTime,
-		ResponseSize: int64(len(resp.Body)),
+		ResponseSize: resp.BodySize,
 		HTTPHeaders:  headerMap,
+		Redirects:    resp.Redirects,
+		Certificates: p.certificates.unreported(resp.Certificates),
 		CrawledAt:    time.Now().UTC(),
 	}
 
@@ -82,20 +198,26 @@ func (p *DefaultPageProcessor) Process(ctx context.Context, url string) (*PageRe
 		Links: []*LinkData{},
 	}
 
-	// Only parse HTML content
-	if !isHTML || resp.StatusCode >= 400 {
-		slog.Debug("Skipping HTML parsing", "url", url, "is_html", isHTML, "status_code", resp.StatusCode)
-		return result, nil
+	directives := parser.ParseXRobotsTag(resp.Headers.Values("X-Robots-Tag"), userAgentProduct(p.httpClient.userAgent))
+	pageData.XRobotsTag = strings.Join(directives, ", ")
+	if p.respectXRobotsTag && parser.HasRobotsDirective(directives, "nofollow") {
+		result.NoFollow = true
 	}
 
-	// Parse HTML with configured allowed schemes
-	htmlParser, err := parser.NewHTMLParserWithSchemes(resp.FinalURL, p.allowedSchemes)
-	if err != nil {
-		return result, nil
+	pageData.ContentHash = body.assetHash
+
+	// Link headers can declare relations for any content type (e.g. the
+	// canonical URL of a PDF), so they are read before the HTML check
+	if resp.StatusCode < 400 {
+		p.applyLinkHeaders(pageData, resp)
 	}
 
-	parseResult, err := htmlParser.Parse(resp.Body)
-	if err != nil {
+	// Only HTML content is parsed. Other responses, such as paginated API
+	// results, are followed through their Link headers.
+	parseResult := body.parsed
+	if parseResult == nil {
+		slog.Debug("Skipping HTML parsing", "url", url, "content_type", resp.ContentType, "status_code", resp.StatusCode)
+		p.markPagination(result, resp.FinalURL)
 		return result, nil
 	}
 
@@ -103,8 +225,49 @@ func (p *DefaultPageProcessor) Process(ctx context.Context, url string) (*PageRe
 	pageData.Title = parseResult.Title
 	pageData.MetaDesc = parseResult.MetaDesc
 	pageData.MetaRobots = parseResult.MetaRobots
-	pageData.CanonicalURL = parseResult.CanonicalURL
+	if p.respectNofollow && parser.HasRobotsDirective(parser.ParseMetaRobots(parseResult.MetaRobots), "nofollow") {
+		result.NoFollow = true
+	}
+	if parseResult.CanonicalURL != "" {
+		// The document's canonical takes precedence over a Link header
+		pageData.CanonicalURL = parseResult.CanonicalURL
+		pageData.Rels = append(pageData.Rels, PageRel{Rel: parser.RelCanonical, URL: parseResult.CanonicalURL, Source: RelSourceHTML})
+	}
+	for _, rel := range parseResult.Rels {
+		pageData.Rels = append(pageData.Rels, PageRel{Rel: rel.Rel, URL: rel.URL, Source: RelSourceHTML})
+	}
 	pageData.ContentHash = parseResult.ContentHash
+	pageData.Rendered = body.rendered
+	pageData.Text = &PageText{
+		WordCount: parseResult.Text.WordCount,
+		TextRatio: parseResult.Text.TextRatio,
+		SimHash:   parseResult.Text.SimHash,
+		Language:  parseResult.Text.Language,
+		HTMLLang:  parseResult.Lang,
+	}
+	for _, heading := range parseResult.Headings {
+		pageData.Headings = append(pageData.Headings, Heading{Level: heading.Level, Text: heading.Text})
+	}
+	for _, alt := range parseResult.Alternates {
+		pageData.Alternates = append(pageData.Alternates, alternateLink(alt, RelSourceHTML))
+	}
+	for _, img := range parseResult.Images {
+		pageData.Images = append(pageData.Images, Image(img))
+	}
+	pageData.Extractions = body.extractions
+	if p.accessibility {
+		for _, issue := range parseResult.Accessibility {
+			pageData.Accessibility = append(pageData.Accessibility, AccessibilityIssue(issue))
+		}
+	}
+	for _, data := range parseResult.StructuredData {
+		pageData.Structured = append(pageData.Structured, StructuredData{
+			Format:  data.Format,
+			Types:   data.Types,
+			Payload: data.Payload,
+			Valid:   data.Valid,
+		})
+	}
 
 	// Convert parsed links to LinkData
 	slog.Debug("Found links", "url", url, "links_count", len(parseResult.Links))
@@ -133,5 +296,148 @@ func (p *DefaultPageProcessor) Process(ctx context.Context, url string) (*PageRe
 		slog.Debug("Added link", "source", resp.FinalURL, "target", link.URL, "type", linkType)
 	}
 
+	p.markPagination(result, resp.FinalURL)
+
+	if p.crawlAssets {
+		p.addAssetLinks(result, parseResult.Assets, resp.FinalURL)
+	}
+
 	return result, nil
 }
+
+// pageBody is what Process takes from a response body as it streams in
+type pageBody struct {
+	render      bool                // Parse the rendered DOM instead of the response
+	rendered    bool                // The parsed HTML is the rendered DOM
+	parsed      *parser.ParseResult // Successful HTML responses
+	extractions []Extraction        // Successful HTML responses, with extractors registered
+	assetHash   string              // Other successful responses, with hash_assets
+}
+
+// readBody consumes a streamed response body. HTML is parsed as it arrives,
+// so a page is never buffered whole; other bodies are hashed when
+// hash_assets is on and otherwise left for the client to discard.
+func (p *DefaultPageProcessor) readBody(ctx context.Context, resp *HTTPResponse, r io.Reader, body *pageBody) error {
+	if resp.StatusCode >= 400 {
+		return nil
+	}
+
+	if !isHTMLContentType(resp.ContentType) {
+		if !p.hashAssets {
+			return nil
+		}
+		hash := sha256.New()
+		n, err := io.Copy(hash, r)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			body.assetHash = fmt.Sprintf("%x", hash.Sum(nil))
+		}
+		return nil
+	}
+
+	// Parse HTML with configured allowed schemes
+	htmlParser, err := parser.NewHTMLParserWithSchemes(resp.FinalURL, p.allowedSchemes)
+	if err != nil {
+		return nil
+	}
+	if body.render {
+		// The response is read first; it is parsed when rendering fails
+		raw, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(raw)
+		if rendered := p.render(ctx, resp.FinalURL); rendered != nil {
+			r = bytes.NewReader(rendered)
+			body.rendered = true
+		}
+	}
+	// Extractors and the render fallback need the whole body, so it is kept
+	// while being parsed
+	fallback := p.renderFallback && p.renderer != nil && !body.render && resp.StatusCode == http.StatusOK
+	var raw bytes.Buffer
+	if len(p.extractors) > 0 || fallback {
+		r = io.TeeReader(r, &raw)
+	}
+	// The parse span includes the time spent waiting for the body to arrive
+	_, span := tracer.Start(ctx, "parse html")
+	body.parsed, err = htmlParser.ParseReader(r)
+	endSpan(span, err)
+	if err == nil && fallback && len(body.parsed.Links) == 0 && looksLikeSPA(raw.Bytes()) {
+		slog.Debug("Page without links looks like a single-page application, rendering it", "url", resp.FinalURL)
+		if rendered := p.render(ctx, resp.FinalURL); rendered != nil {
+			if parsed, perr := htmlParser.ParseReader(bytes.NewReader(rendered)); perr == nil {
+				body.parsed = parsed
+				body.rendered = true
+				raw.Reset()
+				raw.Write(rendered)
+			}
+		}
+	}
+	if err == nil && len(p.extractors) > 0 {
+		body.extractions = p.extract(resp.FinalURL, raw.Bytes())
+	}
+	return err
+}
+
+// isHTMLContentType reports whether a Content-Type is HTML or XHTML
+func isHTMLContentType(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/html") ||
+		strings.HasPrefix(contentType, "application/xhtml+xml")
+}
+
+// addAssetLinks records the static resources a page loads as asset links.
+// Assets on other hosts follow the same rule as external links.
+func (p *DefaultPageProcessor) addAssetLinks(result *PageResult, assets []parser.Asset, sourceURL string) {
+	for _, asset := range assets {
+		if asset.IsExternal && !p.saveExternalLinks {
+			continue
+		}
+		result.Links = append(result.Links, &LinkData{
+			SourceURL:    sourceURL,
+			TargetURL:    asset.URL,
+			LinkType:     LinkTypeAsset,
+			RelAttribute: asset.Kind,
+			CrawledAt:    time.Now().UTC(),
+		})
+	}
+}
+
+// applyLinkHeaders records the canonical, next, prev and alternate relations
+// declared by the response's Link headers
+func (p *DefaultPageProcessor) applyLinkHeaders(pageData *PageData, resp *HTTPResponse) {
+	values := resp.Headers.Values("Link")
+	if len(values) == 0 {
+		return
+	}
+	linkParser, err := parser.NewHTMLParserWithSchemes(resp.FinalURL, p.allowedSchemes)
+	if err != nil {
+		return
+	}
+
+	links := linkParser.ParseLinkHeaders(values)
+	for _, rel := range links.Rels {
+		if rel.Rel == parser.RelCanonical && pageData.CanonicalURL == "" {
+			pageData.CanonicalURL = rel.URL
+		}
+		pageData.Rels = append(pageData.Rels, PageRel{Rel: rel.Rel, URL: rel.URL, Source: RelSourceHeader})
+	}
+	for _, alt := range links.Alternates {
+		pageData.Alternates = append(pageData.Alternates, alternateLink(alt, RelSourceHeader))
+	}
+}
+
+// alternateLink converts a parsed alternate representation
+func alternateLink(alt parser.Alternate, source string) AlternateLink {
+	return AlternateLink{
+		URL:      alt.URL,
+		Kind:     alt.Kind,
+		Type:     alt.Type,
+		Media:    alt.Media,
+		Hreflang: alt.Hreflang,
+		Title:    alt.Title,
+		Source:   source,
+	}
+}
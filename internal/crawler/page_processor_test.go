@@ -2,6 +2,8 @@ package crawler
 
 import (
 	"context"
+	"crypto/sha256"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -167,3 +169,255 @@ func validateHTMLPageLinks(t *testing.T, result *PageResult) {
 		t.Errorf("Expected second link to be external")
 	}
 }
+
+func TestPageProcessorNonASCIILinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(`<html><body>
+				<a href="/日本語/ページ?q=東京 駅">Japanese</a>
+				<a href="/emoji/🎉">Emoji</a>
+			</body></html>`))
+		case "/日本語/ページ", "/emoji/🎉":
+			_, _ = w.Write([]byte(`<html><head><title>` + r.URL.Path + `</title></head></html>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	processor := NewPageProcessor(NewHTTPClient("TestCrawler/1.0", 10*time.Second))
+	ctx := context.Background()
+
+	result, err := processor.Process(ctx, server.URL+"/")
+	if err != nil {
+		t.Fatalf("Failed to process page: %v", err)
+	}
+	if len(result.Links) != 2 {
+		t.Fatalf("Expected 2 links, got %d", len(result.Links))
+	}
+
+	expectedTitles := []string{"/日本語/ページ", "/emoji/🎉"}
+	for i, link := range result.Links {
+		linked, err := processor.Process(ctx, link.TargetURL)
+		if err != nil {
+			t.Fatalf("Failed to process %s: %v", link.TargetURL, err)
+		}
+		if linked.Error != nil {
+			t.Fatalf("Fetching %s failed: %s", link.TargetURL, linked.Error.ErrorMessage)
+		}
+		if linked.Page.StatusCode != http.StatusOK || linked.Page.Title != expectedTitles[i] {
+			t.Errorf("Fetching %s: status %d, title %q", link.TargetURL, linked.Page.StatusCode, linked.Page.Title)
+		}
+	}
+}
+
+func TestPageProcessorHashAssets(t *testing.T) {
+	pdf := []byte("%PDF-1.4 test document")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/doc.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write(pdf)
+		case "/missing.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("not found"))
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><body>page</body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	processor := NewPageProcessor(NewHTTPClient("TestCrawler/1.0", 10*time.Second)).(*DefaultPageProcessor)
+	ctx := context.Background()
+
+	// Disabled by default: assets carry no hash
+	result, err := processor.Process(ctx, server.URL+"/doc.pdf")
+	if err != nil {
+		t.Fatalf("Failed to process asset: %v", err)
+	}
+	if result.Page.ContentHash != "" {
+		t.Errorf("Expected no hash with hash_assets disabled, got %s", result.Page.ContentHash)
+	}
+
+	processor.SetHashAssets(true)
+	result, err = processor.Process(ctx, server.URL+"/doc.pdf")
+	if err != nil {
+		t.Fatalf("Failed to process asset: %v", err)
+	}
+	want := fmt.Sprintf("%x", sha256.Sum256(pdf))
+	if result.Page.ContentHash != want {
+		t.Errorf("Expected hash %s, got %s", want, result.Page.ContentHash)
+	}
+	if result.Page.ResponseSize != int64(len(pdf)) {
+		t.Errorf("Expected size %d, got %d", len(pdf), result.Page.ResponseSize)
+	}
+
+	// Error responses are not hashed
+	result, err = processor.Process(ctx, server.URL+"/missing.png")
+	if err != nil {
+		t.Fatalf("Failed to process missing asset: %v", err)
+	}
+	if result.Page.ContentHash != "" {
+		t.Errorf("Expected no hash for a 404, got %s", result.Page.ContentHash)
+	}
+}
+
+func TestPageProcessorLinkHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/guide.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Header().Set("Link", `</guide>; rel="canonical"`)
+			_, _ = w.Write([]byte("%PDF-1.4"))
+		case "/api/items":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Link", `</api/items?cursor=abc>; rel="next"`)
+			_, _ = w.Write([]byte(`{"items":[]}`))
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Add("Link", `</from-header>; rel="canonical", </page/2>; rel="next"`)
+			w.Header().Add("Link", `</page.json>; rel="alternate"; type="application/json"`)
+			_, _ = w.Write([]byte(`<html><head><link rel="canonical" href="/from-html"></head></html>`))
+		}
+	}))
+	defer server.Close()
+
+	processor := NewPageProcessor(NewHTTPClient("TestCrawler/1.0", 10*time.Second))
+	ctx := context.Background()
+
+	// Non-HTML responses take their canonical from the header
+	result, err := processor.Process(ctx, server.URL+"/guide.pdf")
+	if err != nil {
+		t.Fatalf("Failed to process PDF: %v", err)
+	}
+	if result.Page.CanonicalURL != server.URL+"/guide" {
+		t.Errorf("Expected header canonical for PDF, got %q", result.Page.CanonicalURL)
+	}
+
+	// HTML wins for the canonical URL, but both sources are recorded
+	result, err = processor.Process(ctx, server.URL+"/page")
+	if err != nil {
+		t.Fatalf("Failed to process page: %v", err)
+	}
+	if result.Page.CanonicalURL != server.URL+"/from-html" {
+		t.Errorf("Expected HTML canonical to take precedence, got %q", result.Page.CanonicalURL)
+	}
+	expected := map[PageRel]bool{
+		{Rel: "canonical", URL: server.URL + "/from-header", Source: RelSourceHeader}: true,
+		{Rel: "next", URL: server.URL + "/page/2", Source: RelSourceHeader}:           true,
+		{Rel: "canonical", URL: server.URL + "/from-html", Source: RelSourceHTML}:     true,
+	}
+	if len(result.Page.Rels) != len(expected) {
+		t.Fatalf("Expected %d relations, got %+v", len(expected), result.Page.Rels)
+	}
+	for _, rel := range result.Page.Rels {
+		if !expected[rel] {
+			t.Errorf("Unexpected relation %+v", rel)
+		}
+	}
+	if len(result.Page.Alternates) != 1 || result.Page.Alternates[0].Source != RelSourceHeader {
+		t.Errorf("Expected one header alternate, got %+v", result.Page.Alternates)
+	}
+	// Header next/prev relations are discovered like <link> elements
+	if len(result.Links) != 1 || result.Links[0].TargetURL != server.URL+"/page/2" || result.Links[0].LinkType != LinkTypePagination {
+		t.Errorf("Expected a pagination link from the Link header, got %+v", result.Links)
+	}
+
+	// Non-HTML responses, such as paginated APIs, too
+	result, err = processor.Process(ctx, server.URL+"/api/items")
+	if err != nil {
+		t.Fatalf("Failed to process API response: %v", err)
+	}
+	if len(result.Links) != 1 || result.Links[0].TargetURL != server.URL+"/api/items?cursor=abc" || result.Links[0].RelAttribute != "next" {
+		t.Errorf("Expected a next link from the Link header, got %+v", result.Links)
+	}
+}
+
+func TestPageProcessorCrawlAssets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head>
+			<link rel="stylesheet" href="/site.css">
+			<script src="https://cdn.example.net/app.js"></script>
+		</head><body><a href="/about">About</a><img src="/logo.png" alt="Logo"></body></html>`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	processor := NewPageProcessorWithConfig(NewHTTPClient("TestCrawler/1.0", 10*time.Second), nil, false).(*DefaultPageProcessor)
+
+	// Disabled by default: only anchors become links
+	result, err := processor.Process(ctx, server.URL+"/")
+	if err != nil {
+		t.Fatalf("Failed to process page: %v", err)
+	}
+	if len(result.Links) != 1 {
+		t.Fatalf("Expected only the anchor link, got %d links", len(result.Links))
+	}
+
+	// Enabled: in-host assets are added; the CDN script is dropped like an external link
+	processor.SetCrawlAssets(true)
+	result, err = processor.Process(ctx, server.URL+"/")
+	if err != nil {
+		t.Fatalf("Failed to process page: %v", err)
+	}
+	expected := map[string]string{
+		server.URL + "/site.css": "stylesheet",
+		server.URL + "/logo.png": "image",
+	}
+	assets := 0
+	for _, link := range result.Links {
+		if link.LinkType != LinkTypeAsset {
+			continue
+		}
+		assets++
+		if kind, ok := expected[link.TargetURL]; !ok || kind != link.RelAttribute {
+			t.Errorf("Unexpected asset link %s (%s)", link.TargetURL, link.RelAttribute)
+		}
+	}
+	if assets != len(expected) {
+		t.Errorf("Expected %d asset links, got %d", len(expected), assets)
+	}
+}
+
+func TestPageProcessorXRobotsTag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Add("X-Robots-Tag", "noarchive")
+		w.Header().Add("X-Robots-Tag", "googlebot: noindex")
+		w.Header().Add("X-Robots-Tag", "testcrawler: nofollow")
+		_, _ = w.Write([]byte(`<html><body><a href="/about">About</a></body></html>`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	processor := NewPageProcessorWithConfig(NewHTTPClient("TestCrawler/1.0", 10*time.Second), nil, false).(*DefaultPageProcessor)
+
+	// Directives are recorded, but links are queued unless respected
+	result, err := processor.Process(ctx, server.URL+"/")
+	if err != nil {
+		t.Fatalf("Failed to process page: %v", err)
+	}
+	if result.Page.XRobotsTag != "noarchive, nofollow" {
+		t.Errorf("Expected directives %q, got %q", "noarchive, nofollow", result.Page.XRobotsTag)
+	}
+	if result.NoFollow {
+		t.Error("Expected NoFollow to be off by default")
+	}
+
+	processor.SetRespectXRobotsTag(true)
+	result, err = processor.Process(ctx, server.URL+"/")
+	if err != nil {
+		t.Fatalf("Failed to process page: %v", err)
+	}
+	if !result.NoFollow {
+		t.Error("Expected NoFollow for a nofollow page")
+	}
+	if len(result.Links) != 1 {
+		t.Errorf("Expected links of a nofollow page to be kept, got %d", len(result.Links))
+	}
+}
@@ -0,0 +1,126 @@
+package crawler
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/masahif/linktadoru/internal/parser"
+)
+
+// paginationPathPattern matches paths ending in a page number: /page/2, /page-2/
+var paginationPathPattern = regexp.MustCompile(`(?i)/page[/-]?\d+/?$`)
+
+// paginationParams are query parameters that commonly hold a page number.
+// "p" is left out: WordPress uses it for post IDs.
+var paginationParams = []string{"page", "paged", "pg", "pagenum"}
+
+// isPaginationRel reports whether a rel attribute contains next, prev or previous
+func isPaginationRel(rel string) bool {
+	for _, token := range strings.Fields(strings.ToLower(rel)) {
+		if token == parser.RelNext || token == parser.RelPrev || token == "previous" {
+			return true
+		}
+	}
+	return false
+}
+
+// isPaginationURL reports whether rawURL looks like a page of a paginated
+// listing: a path ending in /page/N or a numeric page query parameter
+func isPaginationURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if paginationPathPattern.MatchString(u.Path) {
+		return true
+	}
+	query := u.Query()
+	for _, name := range paginationParams {
+		if value := query.Get(name); value != "" && strings.Trim(value, "0123456789") == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// markPagination gives the internal links of a page that lead to another
+// page of a paginated listing the link type LinkTypePagination, and adds
+// links for the next and prev relations no <a> element covers, whether
+// declared by <link> elements or Link headers
+func (p *DefaultPageProcessor) markPagination(result *PageResult, sourceURL string) {
+	linked := make(map[string]*LinkData, len(result.Links))
+	for _, link := range result.Links {
+		if link.LinkType == "internal" && (isPaginationRel(link.RelAttribute) || isPaginationURL(link.TargetURL)) {
+			link.LinkType = LinkTypePagination
+		}
+		linked[link.TargetURL] = link
+	}
+
+	for _, rel := range result.Page.Rels {
+		if rel.Rel != parser.RelNext && rel.Rel != parser.RelPrev {
+			continue
+		}
+		if link, ok := linked[rel.URL]; ok {
+			if link.LinkType == "internal" {
+				link.LinkType = LinkTypePagination
+			}
+			continue
+		}
+		link := &LinkData{
+			SourceURL:    sourceURL,
+			TargetURL:    rel.URL,
+			LinkType:     LinkTypePagination,
+			RelAttribute: rel.Rel,
+			CrawledAt:    result.Page.CrawledAt,
+		}
+		if urlHost(rel.URL) != urlHost(sourceURL) {
+			if !p.saveExternalLinks {
+				continue
+			}
+			link.LinkType = "external"
+		}
+		linked[rel.URL] = link
+		result.Links = append(result.Links, link)
+	}
+}
+
+// urlHost returns the host and port of a URL, or "" when it does not parse
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// paginationTracker limits how many pagination links are followed in a row
+// (pagination_depth). It remembers the run of pagination links that led to
+// each queued pagination URL; pages reached any other way start a new run.
+type paginationTracker struct {
+	mu    sync.Mutex
+	depth map[string]int
+}
+
+// follow reports whether a pagination link from source to target is within
+// maxDepth links in a row (0 = unlimited) and records the target's depth
+func (t *paginationTracker) follow(source, target string, maxDepth int) bool {
+	if maxDepth <= 0 {
+		return true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.depth == nil {
+		t.depth = make(map[string]int)
+	}
+	depth := t.depth[source] + 1
+	if depth > maxDepth {
+		return false
+	}
+	if previous, ok := t.depth[target]; !ok || depth < previous {
+		t.depth[target] = depth
+	}
+	return true
+}
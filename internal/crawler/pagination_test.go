@@ -0,0 +1,79 @@
+package crawler
+
+import "testing"
+
+func TestIsPaginationURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://example.com/blog/page/2/":      true,
+		"https://example.com/blog/page-3":       true,
+		"https://example.com/search?q=x&page=4": true,
+		"https://example.com/?paged=2":          true,
+		"https://example.com/?p=123":            false,
+		"https://example.com/?page=last":        false,
+		"https://example.com/pages/about":       false,
+		"https://example.com/blog/":             false,
+	}
+	for rawURL, want := range tests {
+		if got := isPaginationURL(rawURL); got != want {
+			t.Errorf("isPaginationURL(%q) = %v, want %v", rawURL, got, want)
+		}
+	}
+}
+
+func TestMarkPagination(t *testing.T) {
+	p := &DefaultPageProcessor{saveExternalLinks: false}
+	result := &PageResult{
+		Page: &PageData{Rels: []PageRel{
+			{Rel: "next", URL: "https://example.com/list?start=20", Source: RelSourceHTML},
+			{Rel: "prev", URL: "https://example.com/list", Source: RelSourceHTML},
+			{Rel: "next", URL: "https://example.com/from-header", Source: RelSourceHeader},
+			{Rel: "next", URL: "https://other.example.org/2", Source: RelSourceHTML},
+		}},
+		Links: []*LinkData{
+			{TargetURL: "https://example.com/about", LinkType: "internal"},
+			{TargetURL: "https://example.com/list", LinkType: "internal"},
+			{TargetURL: "https://example.com/list/page/3", LinkType: "internal"},
+			{TargetURL: "https://example.com/older", LinkType: "internal", RelAttribute: "prev"},
+			{TargetURL: "https://other.example.org/?page=2", LinkType: "external"},
+		},
+	}
+	p.markPagination(result, "https://example.com/list?start=10")
+
+	want := map[string]string{
+		"https://example.com/about":         "internal",
+		"https://example.com/list":          LinkTypePagination,
+		"https://example.com/list/page/3":   LinkTypePagination,
+		"https://example.com/older":         LinkTypePagination,
+		"https://other.example.org/?page=2": "external",
+		"https://example.com/list?start=20": LinkTypePagination,
+		"https://example.com/from-header":   LinkTypePagination,
+	}
+	if len(result.Links) != len(want) {
+		t.Fatalf("Expected %d links, got %d", len(want), len(result.Links))
+	}
+	for _, link := range result.Links {
+		if link.LinkType != want[link.TargetURL] {
+			t.Errorf("Link to %s has type %q, want %q", link.TargetURL, link.LinkType, want[link.TargetURL])
+		}
+	}
+	if added := result.Links[len(result.Links)-2]; added.SourceURL != "https://example.com/list?start=10" || added.RelAttribute != "next" {
+		t.Errorf("Unexpected link added for rel=next: %+v", added)
+	}
+}
+
+func TestPaginationTrackerDepth(t *testing.T) {
+	var tracker paginationTracker
+	if !tracker.follow("/blog", "/blog/page/2", 2) || !tracker.follow("/blog/page/2", "/blog/page/3", 2) {
+		t.Fatal("Expected the first two pagination links to be followed")
+	}
+	if tracker.follow("/blog/page/3", "/blog/page/4", 2) {
+		t.Error("Expected the third pagination link in a row to be refused")
+	}
+	// A page reached another way starts a new run
+	if !tracker.follow("/archive", "/blog/page/4", 2) {
+		t.Error("Expected a pagination link from a non-paginated page to be followed")
+	}
+	if !tracker.follow("/a", "/b", 0) {
+		t.Error("Expected no limit with pagination_depth 0")
+	}
+}
@@ -0,0 +1,53 @@
+package crawler
+
+import (
+	"log/slog"
+	"time"
+)
+
+// pausePollInterval is how often a paused worker checks whether the crawl
+// was resumed or cancelled
+const pausePollInterval = 200 * time.Millisecond
+
+// Pause stops workers from claiming new URLs. Pages being fetched when it is
+// called are finished and saved; the crawl waits until Resume, Stop or the
+// end of the run context.
+func (c *DefaultCrawler) Pause() {
+	if !c.paused.Swap(true) {
+		slog.Info("Crawl paused")
+	}
+}
+
+// Resume lets paused workers claim URLs again
+func (c *DefaultCrawler) Resume() {
+	if c.paused.Swap(false) {
+		slog.Info("Crawl resumed")
+	}
+}
+
+// Paused reports whether the crawl is paused
+func (c *DefaultCrawler) Paused() bool {
+	return c.paused.Load()
+}
+
+// waitWhilePaused blocks a worker while the crawl is paused. Items the
+// worker claimed in advance are released first, so a long pause does not
+// hold them in 'processing'. It returns false when the run was cancelled.
+func (c *DefaultCrawler) waitWhilePaused(id int, claimed *[]URLItem) bool {
+	if !c.paused.Load() {
+		return true
+	}
+	c.releaseClaimed(id, *claimed)
+	*claimed = nil
+
+	ticker := time.NewTicker(pausePollInterval)
+	defer ticker.Stop()
+	for c.paused.Load() {
+		select {
+		case <-c.ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage/memory"
+)
+
+// A paused crawl fetches nothing until it is resumed, then finishes the queue
+func TestPauseResume(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`<a href="/a">A</a><a href="/b">B</a>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<title>Page</title>`))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := baseCfg()
+	cfg.IgnoreRobotsTxt = true
+	cfg.SeedURLs = []string{server.URL + "/"}
+	c, err := crawler.NewCrawler(cfg, memory.New())
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	c.Pause()
+	if !c.Paused() {
+		t.Fatal("Expected the crawler to be paused")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- c.Start(ctx, cfg.SeedURLs) }()
+
+	time.Sleep(500 * time.Millisecond)
+	if n := requests.Load(); n != 0 {
+		t.Fatalf("Paused crawl made %d requests", n)
+	}
+
+	c.Resume()
+	if err := <-done; err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if stats := c.GetStats(); stats.PagesCrawled != 3 {
+		t.Errorf("PagesCrawled = %d, want 3", stats.PagesCrawled)
+	}
+}
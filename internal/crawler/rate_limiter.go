@@ -2,6 +2,7 @@ package crawler
 
 import (
 	"context"
+	"math/rand/v2"
 	"net/url"
 	"sync"
 	"time"
@@ -11,19 +12,34 @@ import (
 
 // RateLimiter manages rate limiting per domain
 type RateLimiter struct {
-	limiters map[string]*rate.Limiter
+	limiters map[string]*domainLimiter
 	mu       sync.RWMutex
 	delay    time.Duration
+	jitter   float64 // Fraction of the delay added or removed at random (0 = none)
+}
+
+// domainLimiter is the limiter for one domain and its base delay
+type domainLimiter struct {
+	*rate.Limiter
+	delay time.Duration
 }
 
 // NewRateLimiter creates a new rate limiter
 func NewRateLimiter(defaultDelay time.Duration) *RateLimiter {
 	return &RateLimiter{
-		limiters: make(map[string]*rate.Limiter),
+		limiters: make(map[string]*domainLimiter),
 		delay:    defaultDelay,
 	}
 }
 
+// SetJitter makes every per-domain delay vary at random by up to ±percent, so
+// request timing does not form a perfectly regular pattern
+func (r *RateLimiter) SetJitter(percent float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jitter = percent / 100
+}
+
 // Wait waits for permission to proceed with a request to the given URL
 func (r *RateLimiter) Wait(ctx context.Context, urlStr string) error {
 	parsedURL, err := url.Parse(urlStr)
@@ -34,6 +50,15 @@ func (r *RateLimiter) Wait(ctx context.Context, urlStr string) error {
 	domain := parsedURL.Host
 	limiter := r.getLimiter(domain)
 
+	r.mu.RLock()
+	jitter := r.jitter
+	r.mu.RUnlock()
+	if jitter > 0 {
+		// Re-draw the interval before each request; the limiter spaces the
+		// next token by whatever interval is current when it is reserved
+		limiter.SetLimit(rate.Every(applyJitter(limiter.delay, jitter)))
+	}
+
 	return limiter.Wait(ctx)
 }
 
@@ -46,12 +71,11 @@ func (r *RateLimiter) SetDomainDelay(domain string, delay time.Duration) {
 		delay = r.delay
 	}
 
-	limit := rate.Every(delay)
-	r.limiters[domain] = rate.NewLimiter(limit, 1)
+	r.limiters[domain] = newDomainLimiter(delay)
 }
 
 // getLimiter gets or creates a rate limiter for a domain
-func (r *RateLimiter) getLimiter(domain string) *rate.Limiter {
+func (r *RateLimiter) getLimiter(domain string) *domainLimiter {
 	r.mu.RLock()
 	limiter, exists := r.limiters[domain]
 	r.mu.RUnlock()
@@ -69,9 +93,22 @@ func (r *RateLimiter) getLimiter(domain string) *rate.Limiter {
 	}
 
 	// Create new limiter with default delay
-	limit := rate.Every(r.delay)
-	limiter = rate.NewLimiter(limit, 1)
+	limiter = newDomainLimiter(r.delay)
 	r.limiters[domain] = limiter
 
 	return limiter
 }
+
+// newDomainLimiter creates a limiter allowing one request per delay
+func newDomainLimiter(delay time.Duration) *domainLimiter {
+	return &domainLimiter{Limiter: rate.NewLimiter(rate.Every(delay), 1), delay: delay}
+}
+
+// applyJitter returns d scaled by a random factor in [1-fraction, 1+fraction]
+func applyJitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || d <= 0 {
+		return d
+	}
+	factor := 1 + fraction*(2*rand.Float64()-1) // #nosec G404 -- timing jitter, not security sensitive
+	return time.Duration(float64(d) * factor)
+}
@@ -104,3 +104,42 @@ func TestRateLimiterInvalidURL(t *testing.T) {
 		t.Errorf("Expected error for invalid URL, got nil")
 	}
 }
+
+func TestApplyJitter(t *testing.T) {
+	base := 100 * time.Millisecond
+	if got := applyJitter(base, 0); got != base {
+		t.Errorf("Expected no jitter to keep %v, got %v", base, got)
+	}
+
+	varied := false
+	for i := 0; i < 100; i++ {
+		got := applyJitter(base, 0.2)
+		if got < 80*time.Millisecond || got > 120*time.Millisecond {
+			t.Fatalf("Jittered delay %v outside ±20%% of %v", got, base)
+		}
+		if got != base {
+			varied = true
+		}
+	}
+	if !varied {
+		t.Error("Expected jitter to vary the delay")
+	}
+}
+
+func TestRateLimiterJitter(t *testing.T) {
+	limiter := NewRateLimiter(100 * time.Millisecond)
+	limiter.SetJitter(50)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := limiter.Wait(ctx, "https://example.com/page"); err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+	}
+
+	elapsed := time.Since(start)
+	if elapsed < 45*time.Millisecond || elapsed > 200*time.Millisecond {
+		t.Errorf("Expected a jittered wait between 50ms and 150ms, got %v", elapsed)
+	}
+}
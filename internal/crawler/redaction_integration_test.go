@@ -0,0 +1,167 @@
+package crawler_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+	"golang.org/x/net/html"
+)
+
+// Redacted URLs are the stored keys of pages and links, no stored column
+// (response headers and extractions included) keeps the secret, and the real
+// URLs are still fetched
+func TestCrawlStoresNoRedactedData(t *testing.T) {
+	const secret = "s3cr3t-4242"
+
+	var mu sync.Mutex
+	var requested []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.RequestURI())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Set-Cookie", "session="+secret+"; Path=/; HttpOnly")
+			w.Header().Set("Content-Location", "/?sid="+secret)
+			w.Header().Set("Link", fmt.Sprintf(`</next?sid=%s>; rel="next"`, secret))
+			w.Header().Set("X-Contact", "mail-"+secret+"@example.com")
+			_, _ = fmt.Fprintf(w, `<html><head><title>Home of mail-%[1]s@example.com</title></head><body>
+<a href="/next?sid=%[1]s">next for mail-%[1]s@example.com</a></body></html>`, secret)
+		case "/next":
+			_, _ = fmt.Fprintf(w, `<html><body><a href="/?sid=%s">home</a></body></html>`, secret)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	dbPath := filepath.Join(t.TempDir(), "redaction.db")
+	store, err := storage.NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	cfg := baseCfg()
+	cfg.SeedURLs = []string{server.URL + "/?sid=" + secret}
+	cfg.Redaction = &config.Redaction{Patterns: []string{`[\w.+-]+@[\w-]+\.[\w.]+`}, QueryParams: []string{"sid"}}
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	extractor := extractorFunc(func(string, []byte, *html.Node) (map[string]any, error) {
+		return map[string]any{
+			"contact": "mail-" + secret + "@example.com",
+			"next":    server.URL + "/next?sid=" + secret,
+			"links":   []any{map[string]any{"href": server.URL + "/?sid=" + secret}},
+		}, nil
+	})
+	if err := c.RegisterExtractor("contact", extractor); err != nil {
+		t.Fatalf("RegisterExtractor: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	_ = c.Stop()
+
+	redacted := "?sid=%5BREDACTED%5D"
+	for _, path := range []string{"/", "/next"} {
+		if got, _ := store.GetURLStatus(server.URL + path + redacted); got != "completed" {
+			t.Errorf("%s status = %q, want completed under its redacted URL", path, got)
+		}
+	}
+	_ = store.Close()
+
+	mu.Lock()
+	if want := "/next?sid=" + secret; !strings.Contains(strings.Join(requested, " "), want) {
+		t.Errorf("Expected a request for %s, got %v", want, requested)
+	}
+	mu.Unlock()
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	var headers string
+	err = db.QueryRow("SELECT response_http_headers FROM pages WHERE url = ?", server.URL+"/"+redacted).Scan(&headers)
+	if err != nil {
+		t.Fatalf("Failed to read stored headers: %v", err)
+	}
+	for _, want := range []string{
+		`"set-cookie":"[REDACTED]"`,
+		`"content-location":"/?sid=%5BREDACTED%5D"`,
+		`"link":"\u003c/next?sid=%5BREDACTED%5D\u003e; rel=\"next\""`,
+		`"x-contact":"[REDACTED]"`,
+	} {
+		if !strings.Contains(headers, want) {
+			t.Errorf("Stored headers lack %s: %s", want, headers)
+		}
+	}
+
+	var extracted string
+	if err := db.QueryRow("SELECT data FROM page_extractions WHERE extractor = 'contact' LIMIT 1").Scan(&extracted); err != nil {
+		t.Fatalf("Failed to read stored extraction: %v", err)
+	}
+	for _, want := range []string{`"contact":"[REDACTED]"`, "/next?sid=%5BREDACTED%5D", "/?sid=%5BREDACTED%5D"} {
+		if !strings.Contains(extracted, want) {
+			t.Errorf("Stored extraction lacks %s: %s", want, extracted)
+		}
+	}
+
+	tables, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table'")
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	var names []string
+	for tables.Next() {
+		var name string
+		_ = tables.Scan(&name)
+		names = append(names, name)
+	}
+	_ = tables.Close()
+
+	for _, table := range names {
+		rows, err := db.Query(`SELECT * FROM "` + table + `"`)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", table, err)
+		}
+		columns, _ := rows.Columns()
+		for rows.Next() {
+			values := make([]any, len(columns))
+			pointers := make([]any, len(columns))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+			if err := rows.Scan(pointers...); err != nil {
+				t.Fatalf("Failed to scan %s: %v", table, err)
+			}
+			for i, value := range values {
+				var text string
+				switch v := value.(type) {
+				case string:
+					text = v
+				case []byte:
+					text = string(v)
+				}
+				if strings.Contains(text, secret) {
+					t.Errorf("%s.%s stores the secret: %q", table, columns[i], text)
+				}
+			}
+		}
+		_ = rows.Close()
+	}
+}
@@ -0,0 +1,312 @@
+package crawler
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+// DefaultRedactionReplacement is substituted for redacted data when no
+// replacement is configured
+const DefaultRedactionReplacement = "[REDACTED]"
+
+// secretHeaders are response headers whose whole value is replaced: they
+// carry session cookies and credentials rather than page metadata
+var secretHeaders = map[string]bool{
+	"set-cookie":          true,
+	"set-cookie2":         true,
+	"cookie":              true,
+	"authorization":       true,
+	"proxy-authorization": true,
+	"www-authenticate":    true,
+	"proxy-authenticate":  true,
+}
+
+// urlHeaders are response headers whose value is a URL
+var urlHeaders = map[string]bool{
+	"location":         true,
+	"content-location": true,
+}
+
+// linkHeaderURL matches the <URL> references of a Link header
+var linkHeaderURL = regexp.MustCompile(`<[^>]*>`)
+
+// Redactor scrubs personal data (emails, tokens, session IDs) from crawl
+// results before they are written to storage, so a crawl database can be
+// shared without leaking it.
+//
+// Redacted URLs are the keys pages are queued and stored under. The real URL
+// each one was redacted from is kept in memory only, so the crawler can still
+// fetch it.
+type Redactor struct {
+	patterns    []*regexp.Regexp
+	queryParams map[string]bool
+	replacement string
+	realURLs    sync.Map // Redacted URL -> first URL redacted to it
+}
+
+// NewRedactor compiles the configured redaction rules. It returns nil when no
+// rules are configured.
+func NewRedactor(cfg *config.Redaction) (*Redactor, error) {
+	if cfg == nil || (len(cfg.Patterns) == 0 && len(cfg.QueryParams) == 0) {
+		return nil, nil
+	}
+
+	r := &Redactor{
+		queryParams: make(map[string]bool, len(cfg.QueryParams)),
+		replacement: cfg.Replacement,
+	}
+	if r.replacement == "" {
+		r.replacement = DefaultRedactionReplacement
+	}
+
+	for _, pattern := range cfg.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern '%s': %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	for _, param := range cfg.QueryParams {
+		r.queryParams[strings.ToLower(strings.TrimSpace(param))] = true
+	}
+
+	return r, nil
+}
+
+// RedactText replaces every match of the configured patterns in s
+func (r *Redactor) RedactText(s string) string {
+	if s == "" {
+		return s
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, r.replacement)
+	}
+	return s
+}
+
+// RedactURL replaces the values of configured query parameters in rawURL.
+// Parameter names are matched case-insensitively. URLs that fail to parse or
+// carry no matching parameter are returned unchanged.
+func (r *Redactor) RedactURL(rawURL string) string {
+	if len(r.queryParams) == 0 || !strings.Contains(rawURL, "?") {
+		return rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	// Rewrite the raw query in place to keep the original parameter order
+	parts := strings.Split(u.RawQuery, "&")
+	changed := false
+	for i, part := range parts {
+		name, _, _ := strings.Cut(part, "=")
+		if decoded, err := url.QueryUnescape(name); err == nil {
+			name = decoded
+		}
+		if r.queryParams[strings.ToLower(name)] {
+			parts[i] = url.QueryEscape(name) + "=" + url.QueryEscape(r.replacement)
+			changed = true
+		}
+	}
+	if !changed {
+		return rawURL
+	}
+
+	u.RawQuery = strings.Join(parts, "&")
+	redacted := u.String()
+	r.realURLs.LoadOrStore(redacted, rawURL)
+	return redacted
+}
+
+// RealURL returns the URL to fetch for a redacted URL: the URL it was
+// redacted from during this run. URLs redacting the same parameters to the
+// same key share the first one seen. A redacted URL queued by an earlier run
+// has no real URL left, so the redacted parameters are dropped from it
+// instead of sending the replacement. Other URLs are returned unchanged.
+func (r *Redactor) RealURL(redactedURL string) string {
+	if real, ok := r.realURLs.Load(redactedURL); ok {
+		return real.(string)
+	}
+	if len(r.queryParams) == 0 || !strings.Contains(redactedURL, "?") {
+		return redactedURL
+	}
+
+	u, err := url.Parse(redactedURL)
+	if err != nil {
+		return redactedURL
+	}
+
+	parts := strings.Split(u.RawQuery, "&")
+	kept := parts[:0]
+	for _, part := range parts {
+		name, value, _ := strings.Cut(part, "=")
+		if decoded, err := url.QueryUnescape(name); err == nil {
+			name = decoded
+		}
+		if decoded, err := url.QueryUnescape(value); err == nil {
+			value = decoded
+		}
+		if r.queryParams[strings.ToLower(name)] && value == r.replacement {
+			continue
+		}
+		kept = append(kept, part)
+	}
+	if len(kept) == len(parts) {
+		return redactedURL
+	}
+
+	u.RawQuery = strings.Join(kept, "&")
+	return u.String()
+}
+
+// Redacted returns a copy of a processed page result with the page URL,
+// response headers, redirects, title, meta description, canonical URL,
+// alternate links, headings, images, JSON-LD payloads, extractions, link
+// sources, targets and anchor text, and error redacted. The result itself is
+// left untouched.
+func (r *Redactor) Redacted(result *PageResult) *PageResult {
+	if result == nil {
+		return nil
+	}
+
+	redacted := *result
+	if result.Page != nil {
+		page := *result.Page
+		page.Redirects = slices.Clone(page.Redirects)
+		page.Alternates = slices.Clone(page.Alternates)
+		page.Rels = slices.Clone(page.Rels)
+		page.Headings = slices.Clone(page.Headings)
+		page.Images = slices.Clone(page.Images)
+		page.Structured = slices.Clone(page.Structured)
+		page.Extractions = slices.Clone(page.Extractions)
+		if page.HTTPHeaders != nil {
+			page.HTTPHeaders = maps.Clone(page.HTTPHeaders)
+		}
+		redacted.Page = &page
+	}
+	if result.Links != nil {
+		redacted.Links = make([]*LinkData, len(result.Links))
+		for i, link := range result.Links {
+			copied := *link
+			redacted.Links[i] = &copied
+		}
+	}
+	if result.Error != nil {
+		crawlErr := *result.Error
+		redacted.Error = &crawlErr
+	}
+	r.apply(&redacted)
+	return &redacted
+}
+
+// apply redacts the fields of a result listed by Redacted in place
+func (r *Redactor) apply(result *PageResult) {
+	if page := result.Page; page != nil {
+		page.URL = r.RedactURL(page.URL)
+		for name, value := range page.HTTPHeaders {
+			page.HTTPHeaders[name] = r.redactHeader(name, value)
+		}
+		for i := range page.Redirects {
+			page.Redirects[i].FromURL = r.RedactURL(page.Redirects[i].FromURL)
+			page.Redirects[i].ToURL = r.RedactURL(page.Redirects[i].ToURL)
+		}
+		page.Title = r.RedactText(page.Title)
+		page.MetaDesc = r.RedactText(page.MetaDesc)
+		page.CanonicalURL = r.RedactURL(page.CanonicalURL)
+		for i := range page.Alternates {
+			page.Alternates[i].URL = r.RedactURL(page.Alternates[i].URL)
+			page.Alternates[i].Title = r.RedactText(page.Alternates[i].Title)
+		}
+		for i := range page.Rels {
+			page.Rels[i].URL = r.RedactURL(page.Rels[i].URL)
+		}
+		for i := range page.Headings {
+			page.Headings[i].Text = r.RedactText(page.Headings[i].Text)
+		}
+		for i := range page.Images {
+			page.Images[i].URL = r.RedactURL(page.Images[i].URL)
+			page.Images[i].Alt = r.RedactText(page.Images[i].Alt)
+		}
+		for i := range page.Structured {
+			page.Structured[i].Payload = r.RedactText(page.Structured[i].Payload)
+		}
+		for i := range page.Extractions {
+			if fields := page.Extractions[i].Fields; fields != nil {
+				page.Extractions[i].Fields = r.redactValue(fields).(map[string]any)
+			}
+			page.Extractions[i].Error = r.RedactText(page.Extractions[i].Error)
+		}
+	}
+
+	for _, link := range result.Links {
+		link.SourceURL = r.RedactURL(link.SourceURL)
+		link.TargetURL = r.RedactURL(link.TargetURL)
+		link.AnchorText = r.RedactText(link.AnchorText)
+	}
+
+	if result.Error != nil {
+		result.Error.URL = r.RedactURL(result.Error.URL)
+		result.Error.ErrorMessage = r.RedactText(result.Error.ErrorMessage)
+	}
+}
+
+// redactHeader redacts one response header. Cookie and credential headers
+// are replaced entirely, URL-valued headers have their query parameters
+// redacted, and every header is scrubbed of the configured patterns.
+func (r *Redactor) redactHeader(name, value string) string {
+	switch {
+	case secretHeaders[name]:
+		return r.replacement
+	case name == "link":
+		value = linkHeaderURL.ReplaceAllStringFunc(value, func(ref string) string {
+			return "<" + r.RedactURL(ref[1:len(ref)-1]) + ">"
+		})
+	case name == "refresh":
+		// "5; url=https://..."
+		if i := strings.Index(strings.ToLower(value), "url="); i >= 0 {
+			value = value[:i+4] + r.RedactURL(value[i+4:])
+		}
+	case urlHeaders[name]:
+		value = r.RedactURL(value)
+	}
+	return r.RedactText(value)
+}
+
+// redactValue returns a copy of an extracted value with every string
+// redacted as text and, when it is a URL, of its query parameters
+func (r *Redactor) redactValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.RedactText(r.RedactURL(v))
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for key, value := range v {
+			redacted[key] = r.redactValue(value)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, value := range v {
+			redacted[i] = r.redactValue(value)
+		}
+		return redacted
+	case []string:
+		redacted := make([]string, len(v))
+		for i, value := range v {
+			redacted[i] = r.RedactText(r.RedactURL(value))
+		}
+		return redacted
+	default:
+		return v
+	}
+}
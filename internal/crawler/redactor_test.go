@@ -0,0 +1,143 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+func TestNewRedactorNoRules(t *testing.T) {
+	r, err := NewRedactor(nil)
+	if err != nil || r != nil {
+		t.Errorf("Expected nil redactor for nil config, got %v (err %v)", r, err)
+	}
+
+	r, err = NewRedactor(&config.Redaction{})
+	if err != nil || r != nil {
+		t.Errorf("Expected nil redactor for empty config, got %v (err %v)", r, err)
+	}
+
+	if _, err := NewRedactor(&config.Redaction{Patterns: []string{"("}}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestRedactText(t *testing.T) {
+	r, err := NewRedactor(&config.Redaction{
+		Patterns: []string{`[\w.+-]+@[\w-]+\.[\w.]+`, `tok_[A-Za-z0-9]+`},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create redactor: %v", err)
+	}
+
+	got := r.RedactText("Contact alice@example.com with tok_abc123")
+	want := "Contact [REDACTED] with [REDACTED]"
+	if got != want {
+		t.Errorf("RedactText() = %q, want %q", got, want)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	r, err := NewRedactor(&config.Redaction{
+		QueryParams: []string{"SessionID", "token"},
+		Replacement: "x",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create redactor: %v", err)
+	}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"https://example.com/page", "https://example.com/page"},
+		{"https://example.com/page?a=1&b=2", "https://example.com/page?a=1&b=2"},
+		{"https://example.com/page?sessionid=abc&a=1", "https://example.com/page?sessionid=x&a=1"},
+		{"https://example.com/page?a=1&TOKEN=secret#top", "https://example.com/page?a=1&TOKEN=x#top"},
+	}
+
+	for _, tt := range tests {
+		if got := r.RedactURL(tt.in); got != tt.want {
+			t.Errorf("RedactURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactorRedacted(t *testing.T) {
+	r, err := NewRedactor(&config.Redaction{
+		Patterns:    []string{`[\w.+-]+@[\w-]+\.[\w.]+`},
+		QueryParams: []string{"sid"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create redactor: %v", err)
+	}
+
+	result := &PageResult{
+		Page: &PageData{
+			URL:          "https://example.com/?sid=1",
+			Title:        "Profile of bob@example.com",
+			CanonicalURL: "https://example.com/profile?sid=42",
+			Headings:     []Heading{{Level: 1, Text: "Contact bob@example.com"}},
+			Structured:   []StructuredData{{Format: "json-ld", Payload: `{"email": "bob@example.com"}`}},
+			Images:       []Image{{URL: "https://example.com/a.png?sid=42", Alt: "Photo of bob@example.com", HasAlt: true}},
+		},
+		Links: []*LinkData{{
+			SourceURL:  "https://example.com/?sid=1",
+			TargetURL:  "https://example.com/next?sid=42",
+			AnchorText: "mail bob@example.com",
+		}},
+	}
+	original := result
+	result = r.Redacted(original)
+	if original.Page.Title != "Profile of bob@example.com" || original.Links[0].TargetURL != "https://example.com/next?sid=42" ||
+		original.Page.Headings[0].Text != "Contact bob@example.com" || original.Page.Images[0].URL != "https://example.com/a.png?sid=42" {
+		t.Errorf("The original result should be left untouched, got %+v", original.Page)
+	}
+
+	if result.Page.Title != "Profile of [REDACTED]" {
+		t.Errorf("Unexpected title: %q", result.Page.Title)
+	}
+	if result.Page.Headings[0].Text != "Contact [REDACTED]" {
+		t.Errorf("Unexpected heading: %q", result.Page.Headings[0].Text)
+	}
+	if img := result.Page.Images[0]; img.Alt != "Photo of [REDACTED]" || img.URL != "https://example.com/a.png?sid=%5BREDACTED%5D" {
+		t.Errorf("Unexpected image: %+v", img)
+	}
+	if result.Page.Structured[0].Payload != `{"email": "[REDACTED]"}` {
+		t.Errorf("Unexpected structured data payload: %q", result.Page.Structured[0].Payload)
+	}
+	if result.Page.CanonicalURL != "https://example.com/profile?sid=%5BREDACTED%5D" {
+		t.Errorf("Unexpected canonical URL: %q", result.Page.CanonicalURL)
+	}
+	if result.Page.URL != "https://example.com/?sid=%5BREDACTED%5D" {
+		t.Errorf("Unexpected page URL: %q", result.Page.URL)
+	}
+	if result.Links[0].SourceURL != "https://example.com/?sid=%5BREDACTED%5D" {
+		t.Errorf("Unexpected link source URL: %q", result.Links[0].SourceURL)
+	}
+	if result.Links[0].TargetURL != "https://example.com/next?sid=%5BREDACTED%5D" {
+		t.Errorf("Unexpected target URL: %q", result.Links[0].TargetURL)
+	}
+	if result.Links[0].AnchorText != "mail [REDACTED]" {
+		t.Errorf("Unexpected anchor text: %q", result.Links[0].AnchorText)
+	}
+}
+
+func TestRedactorRealURL(t *testing.T) {
+	r, err := NewRedactor(&config.Redaction{QueryParams: []string{"sid"}, Replacement: "x"})
+	if err != nil {
+		t.Fatalf("Failed to create redactor: %v", err)
+	}
+
+	redacted := r.RedactURL("https://example.com/a?sid=1&b=2")
+	if got := r.RealURL(redacted); got != "https://example.com/a?sid=1&b=2" {
+		t.Errorf("RealURL(%q) = %q, want the URL it was redacted from", redacted, got)
+	}
+	// Queued by an earlier run: the redacted parameter is dropped
+	if got := r.RealURL("https://example.com/c?b=2&sid=x"); got != "https://example.com/c?b=2" {
+		t.Errorf("Unexpected real URL of an unknown redacted URL: %q", got)
+	}
+	if got := r.RealURL("https://example.com/d?b=2"); got != "https://example.com/d?b=2" {
+		t.Errorf("Unexpected real URL of an unredacted URL: %q", got)
+	}
+}
@@ -0,0 +1,371 @@
+package crawler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// browserLaunchTimeout bounds how long a launched browser takes to open its
+// DevTools endpoint
+const browserLaunchTimeout = 20 * time.Second
+
+// browserNames are the executables looked up in PATH when browser_path is not set
+var browserNames = []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable", "chrome"}
+
+// devToolsListening is the line a browser started with
+// --remote-debugging-port prints to stderr once its endpoint is open
+var devToolsListening = regexp.MustCompile(`DevTools listening on (ws://\S+)`)
+
+// Markers of pages that build their content with JavaScript, looked for by
+// looksLikeSPA
+var (
+	// An empty element frameworks mount the application into
+	spaMountPoint = regexp.MustCompile(`(?i)<(?:div|main|section)[^>]*\bid=["']?(?:root|app|__next|__nuxt|svelte)(?:["'][^>]*|\s[^>]*)?>\s*</(?:div|main|section)>`)
+	// Attributes and globals framework builds leave in the HTML
+	spaFrameworkMarker = regexp.MustCompile(`(?i)<app-root|\bng-version=|\bng-app\b|\bdata-reactroot\b|\bdata-server-rendered\b|\b__NEXT_DATA__\b|\bwindow\.__NUXT__`)
+	// Script bundles named by the usual bundler conventions
+	spaBundleScript = regexp.MustCompile(`(?i)<script[^>]+\bsrc=["']?[^"'\s>]*\b(?:bundle|chunk|main|app|vendor|runtime)\b[^"'\s>]*\.m?js`)
+	// Inline scripts, to weigh them against the rest of the document
+	inlineScript = regexp.MustCompile(`(?is)<script\b[^>]*>(.*?)</script>`)
+)
+
+// looksLikeSPA reports whether the raw HTML of a page without links appears
+// to be a single-page application shell: an empty mount point, a framework
+// marker, a bundled script, or a document made mostly of inline script.
+func looksLikeSPA(body []byte) bool {
+	if spaMountPoint.Match(body) || spaFrameworkMarker.Match(body) || spaBundleScript.Match(body) {
+		return true
+	}
+	scripts := 0
+	for _, m := range inlineScript.FindAllSubmatchIndex(body, -1) {
+		scripts += m[3] - m[2]
+	}
+	return len(body) > 0 && scripts*2 >= len(body)
+}
+
+// Renderer loads a page the way a browser does, running its JavaScript, and
+// returns the HTML of the resulting DOM. Pages matching render_patterns are
+// parsed from the rendered HTML instead of the raw response, so links added
+// by scripts (single-page applications) are found.
+type Renderer interface {
+	Render(ctx context.Context, url string) ([]byte, error)
+	Close() error
+}
+
+// compileRenderPatterns compiles the render_patterns of the configuration
+func compileRenderPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid render pattern '%s': %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// SetRenderer replaces the renderer of the pages matching render_patterns
+// and of the render fallback, e.g. with one driving another browser, closing
+// the one it replaces. Call it before Start.
+func (c *DefaultCrawler) SetRenderer(renderer Renderer) error {
+	processor, ok := c.processor.(*DefaultPageProcessor)
+	if !ok {
+		return fmt.Errorf("the page processor does not support rendering")
+	}
+	patterns, err := compileRenderPatterns(c.config.RenderPatterns)
+	if err != nil {
+		return err
+	}
+	if c.renderer != nil {
+		_ = c.renderer.Close()
+	}
+	c.renderer = renderer
+	processor.SetRenderer(renderer, patterns)
+	processor.SetRenderFallback(c.config.RenderFallback)
+	return nil
+}
+
+// BrowserOptions configures a BrowserRenderer
+type BrowserOptions struct {
+	Path      string        // Chrome or Chromium executable; empty looks one up in PATH
+	URL       string        // DevTools WebSocket URL of a running browser to use instead of launching one
+	Timeout   time.Duration // How long a page may take to render
+	UserAgent string        // User-Agent the browser sends
+}
+
+// BrowserRenderer renders pages in a headless Chrome or Chromium over the
+// DevTools protocol. The browser is started with the first page and shared
+// by all workers: each page renders in its own tab, which is closed
+// afterwards. A browser that crashed is restarted with the next page.
+type BrowserRenderer struct {
+	opts BrowserOptions
+
+	mu      sync.Mutex
+	conn    *cdpConn
+	cmd     *exec.Cmd // Nil when connected to a running browser
+	dataDir string    // Profile directory of the launched browser
+}
+
+// NewBrowserRenderer returns a renderer for opts. Without opts.URL it checks
+// that a browser executable can be found; none is started yet.
+func NewBrowserRenderer(opts BrowserOptions) (*BrowserRenderer, error) {
+	if opts.URL == "" {
+		path, err := findBrowser(opts.Path)
+		if err != nil {
+			return nil, err
+		}
+		opts.Path = path
+	}
+	return &BrowserRenderer{opts: opts}, nil
+}
+
+// findBrowser returns the browser executable to launch
+func findBrowser(path string) (string, error) {
+	if path != "" {
+		return exec.LookPath(path)
+	}
+	for _, name := range browserNames {
+		if found, err := exec.LookPath(name); err == nil {
+			return found, nil
+		}
+	}
+	return "", fmt.Errorf("no Chrome or Chromium found in PATH (tried %s); set browser_path or browser_url",
+		strings.Join(browserNames, ", "))
+}
+
+// connection returns the connection to the browser, starting or reconnecting
+// to it first when needed
+func (r *BrowserRenderer) connection(ctx context.Context) (*cdpConn, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conn != nil && r.conn.alive() {
+		return r.conn, nil
+	}
+	r.shutdown()
+
+	wsURL := r.opts.URL
+	if wsURL == "" {
+		var err error
+		if wsURL, err = r.launch(); err != nil {
+			return nil, err
+		}
+	}
+	conn, err := dialCDP(ctx, wsURL)
+	if err != nil {
+		r.shutdown()
+		return nil, err
+	}
+	r.conn = conn
+	return conn, nil
+}
+
+// launch starts a headless browser with a fresh profile and returns its
+// DevTools endpoint
+func (r *BrowserRenderer) launch() (string, error) {
+	dataDir, err := os.MkdirTemp("", "linktadoru-browser-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create browser profile: %w", err)
+	}
+	args := []string{
+		"--headless=new",
+		"--disable-gpu",
+		"--no-first-run",
+		"--no-default-browser-check",
+		"--disable-extensions",
+		"--mute-audio",
+		"--remote-debugging-port=0",
+		"--user-data-dir=" + dataDir,
+	}
+	if os.Geteuid() == 0 {
+		// Chrome refuses to run as root with its sandbox, e.g. in containers
+		args = append(args, "--no-sandbox")
+	}
+	cmd := exec.Command(r.opts.Path, append(args, "about:blank")...) // #nosec G204 -- path comes from user configuration
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		_ = os.RemoveAll(dataDir)
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		_ = os.RemoveAll(dataDir)
+		return "", fmt.Errorf("failed to start browser: %w", err)
+	}
+	r.cmd, r.dataDir = cmd, dataDir
+
+	found := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			if m := devToolsListening.FindStringSubmatch(scanner.Text()); m != nil {
+				found <- m[1]
+				break
+			}
+		}
+		close(found)
+		// Keep draining so the browser never blocks on a full pipe
+		for scanner.Scan() {
+		}
+	}()
+	select {
+	case wsURL, ok := <-found:
+		if !ok {
+			r.shutdown()
+			return "", errors.New("browser exited without opening its DevTools endpoint")
+		}
+		slog.Info("Started headless browser", "path", r.opts.Path)
+		return wsURL, nil
+	case <-time.After(browserLaunchTimeout):
+		r.shutdown()
+		return "", fmt.Errorf("browser did not open its DevTools endpoint within %s", browserLaunchTimeout)
+	}
+}
+
+// Render loads url in a new tab, waits until the network is idle or the
+// timeout passes, and returns the HTML of the DOM. The browser sends the
+// crawler's User-Agent but not its authentication or custom headers, which
+// would otherwise reach every host the page loads resources from.
+func (r *BrowserRenderer) Render(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+	defer cancel()
+	conn, err := r.connection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var target struct {
+		TargetID string `json:"targetId"`
+	}
+	if err := conn.call(ctx, "", "Target.createTarget", map[string]any{"url": "about:blank"}, &target); err != nil {
+		return nil, err
+	}
+	defer func() {
+		// The tab is closed even when the render timed out
+		closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = conn.call(closeCtx, "", "Target.closeTarget", map[string]any{"targetId": target.TargetID}, nil)
+	}()
+	var attached struct {
+		SessionID string `json:"sessionId"`
+	}
+	if err := conn.call(ctx, "", "Target.attachToTarget", map[string]any{"targetId": target.TargetID, "flatten": true}, &attached); err != nil {
+		return nil, err
+	}
+	session := attached.SessionID
+	events := conn.subscribe(session)
+	defer conn.unsubscribe(session)
+
+	if err := conn.call(ctx, session, "Page.enable", nil, nil); err != nil {
+		return nil, err
+	}
+	if err := conn.call(ctx, session, "Page.setLifecycleEventsEnabled", map[string]any{"enabled": true}, nil); err != nil {
+		return nil, err
+	}
+	if r.opts.UserAgent != "" {
+		if err := conn.call(ctx, session, "Emulation.setUserAgentOverride", map[string]any{"userAgent": r.opts.UserAgent}, nil); err != nil {
+			return nil, err
+		}
+	}
+	var navigated struct {
+		LoaderID  string `json:"loaderId"`
+		ErrorText string `json:"errorText"`
+	}
+	if err := conn.call(ctx, session, "Page.navigate", map[string]any{"url": url}, &navigated); err != nil {
+		return nil, err
+	}
+	if navigated.ErrorText != "" {
+		return nil, fmt.Errorf("browser failed to load %s: %s", url, navigated.ErrorText)
+	}
+	if err := waitForNetworkIdle(ctx, events, navigated.LoaderID); err != nil {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		// Pages that keep connections open (polling, analytics) never go
+		// idle; their DOM is taken as it is when the timeout passes
+		slog.Debug("Page did not reach network idle before the render timeout", "url", url)
+	}
+
+	// Read the DOM even when the render timeout passed
+	evalCtx, cancelEval := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelEval()
+	var evaluated struct {
+		Result struct {
+			Value string `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text string `json:"text"`
+		} `json:"exceptionDetails"`
+	}
+	err = conn.call(evalCtx, session, "Runtime.evaluate", map[string]any{
+		"expression":    "document.documentElement.outerHTML",
+		"returnByValue": true,
+	}, &evaluated)
+	if err != nil {
+		return nil, err
+	}
+	if evaluated.ExceptionDetails != nil {
+		return nil, fmt.Errorf("failed to read rendered DOM of %s: %s", url, evaluated.ExceptionDetails.Text)
+	}
+	return []byte(evaluated.Result.Value), nil
+}
+
+// waitForNetworkIdle waits for the networkIdle lifecycle event of the
+// navigation loaderID
+func waitForNetworkIdle(ctx context.Context, events <-chan *cdpMessage, loaderID string) error {
+	for {
+		select {
+		case event := <-events:
+			if event.Method != "Page.lifecycleEvent" {
+				continue
+			}
+			var lifecycle struct {
+				LoaderID string `json:"loaderId"`
+				Name     string `json:"name"`
+			}
+			if err := json.Unmarshal(event.Params, &lifecycle); err != nil {
+				return err
+			}
+			if lifecycle.Name == "networkIdle" && (loaderID == "" || lifecycle.LoaderID == loaderID) {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Close stops the browser the renderer launched, or disconnects from the
+// running browser it used
+func (r *BrowserRenderer) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shutdown()
+	return nil
+}
+
+// shutdown closes the connection and stops a launched browser
+func (r *BrowserRenderer) shutdown() {
+	if r.conn != nil {
+		_ = r.conn.close()
+		r.conn = nil
+	}
+	if r.cmd != nil {
+		_ = r.cmd.Process.Kill()
+		_ = r.cmd.Wait()
+		r.cmd = nil
+	}
+	if r.dataDir != "" {
+		_ = os.RemoveAll(r.dataDir)
+		r.dataDir = ""
+	}
+}
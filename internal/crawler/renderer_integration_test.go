@@ -0,0 +1,146 @@
+package crawler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage/memory"
+)
+
+// fakeRenderer renders pages by inserting the links their scripts would add
+type fakeRenderer struct {
+	mu       sync.Mutex
+	rendered []string
+	fail     bool
+}
+
+func (r *fakeRenderer) Render(_ context.Context, url string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rendered = append(r.rendered, url)
+	if r.fail {
+		return nil, errors.New("browser crashed")
+	}
+	return []byte(`<html><head><title>Rendered</title></head><body><a href="/app/settings">Settings</a></body></html>`), nil
+}
+
+func (r *fakeRenderer) Close() error { return nil }
+
+// spaSite serves a single-page application shell whose links only exist once rendered
+func spaSite(t *testing.T) *httptest.Server {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(`<title>Home</title><a href="/app/">App</a>`))
+		case "/app/":
+			_, _ = w.Write([]byte(`<title>Loading</title><div id="root"></div><script src="/bundle.js"></script>`))
+		default:
+			_, _ = w.Write([]byte(`<title>` + r.URL.Path + `</title>`))
+		}
+	}))
+	t.Cleanup(site.Close)
+	return site
+}
+
+func crawlWithRenderer(t *testing.T, site *httptest.Server, renderer crawler.Renderer, configure func(*config.CrawlConfig)) *memory.Storage {
+	t.Helper()
+	cfg := baseCfg()
+	cfg.SeedURLs = []string{site.URL + "/"}
+	configure(cfg)
+	cfg.RenderTimeout = time.Second
+	cfg.BrowserURL = "ws://127.0.0.1:1/devtools/browser/unused"
+	store := memory.New()
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+	if err := c.SetRenderer(renderer); err != nil {
+		t.Fatalf("SetRenderer: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	return store
+}
+
+// renderPatterns renders the /app/ pages of spaSite
+func renderPatterns(cfg *config.CrawlConfig) {
+	cfg.RenderPatterns = []string{"/app/"}
+}
+
+// Pages matching render_patterns are parsed from the rendered DOM
+func TestRenderPatterns(t *testing.T) {
+	site := spaSite(t)
+	renderer := &fakeRenderer{}
+	store := crawlWithRenderer(t, site, renderer, renderPatterns)
+
+	renderer.mu.Lock()
+	defer renderer.mu.Unlock()
+	if len(renderer.rendered) == 0 {
+		t.Error("Expected /app/ to be rendered")
+	}
+	for _, url := range renderer.rendered {
+		if !strings.Contains(url, "/app/") {
+			t.Errorf("Page not matching render_patterns was rendered: %s", url)
+		}
+	}
+	if page, ok := store.Page(site.URL + "/app/"); !ok || page.Title != "Rendered" || !page.Rendered {
+		t.Errorf("Expected /app/ to be parsed from the rendered DOM, got %+v", page)
+	}
+	if _, ok := store.Page(site.URL + "/app/settings"); !ok {
+		t.Error("Expected the link added by the script to be crawled")
+	}
+	if page, ok := store.Page(site.URL + "/"); !ok || page.Title != "Home" {
+		t.Errorf("Expected / to be parsed from the response, got %+v", page)
+	}
+}
+
+// A failed render falls back to the raw response
+func TestRenderFailureFallsBack(t *testing.T) {
+	site := spaSite(t)
+	store := crawlWithRenderer(t, site, &fakeRenderer{fail: true}, renderPatterns)
+
+	if page, ok := store.Page(site.URL + "/app/"); !ok || page.Title != "Loading" || page.Rendered {
+		t.Errorf("Expected /app/ to be parsed from the response, got %+v", page)
+	}
+	if _, ok := store.GetURLStatus(site.URL + "/app/settings"); ok {
+		t.Error("Link only the rendered DOM has was found")
+	}
+}
+
+// With render_fallback, pages without links that look like single-page
+// applications are rendered; other pages are not
+func TestRenderFallback(t *testing.T) {
+	site := spaSite(t)
+	renderer := &fakeRenderer{}
+	store := crawlWithRenderer(t, site, renderer, func(cfg *config.CrawlConfig) {
+		cfg.RenderFallback = true
+	})
+
+	renderer.mu.Lock()
+	defer renderer.mu.Unlock()
+	if len(renderer.rendered) != 1 || renderer.rendered[0] != site.URL+"/app/" {
+		t.Errorf("Expected only /app/ to be rendered, got %v", renderer.rendered)
+	}
+	if page, ok := store.Page(site.URL + "/app/"); !ok || page.Title != "Rendered" || !page.Rendered {
+		t.Errorf("Expected /app/ to be parsed from the rendered DOM, got %+v", page)
+	}
+	if _, ok := store.Page(site.URL + "/app/settings"); !ok {
+		t.Error("Expected the link added by the script to be crawled")
+	}
+	if page, ok := store.Page(site.URL + "/"); !ok || page.Rendered {
+		t.Errorf("Expected / to be parsed from the response, got %+v", page)
+	}
+}
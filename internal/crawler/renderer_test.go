@@ -0,0 +1,159 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// fakeDevTools answers the DevTools commands a render sends, rendering every
+// page as html. It records the methods it received.
+type fakeDevTools struct {
+	html      string
+	idle      bool // Send networkIdle after Page.navigate
+	mu        sync.Mutex
+	methods   []string
+	userAgent string
+}
+
+func (f *fakeDevTools) serve(ws *websocket.Conn) {
+	for {
+		var msg cdpMessage
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.methods = append(f.methods, msg.Method)
+		f.mu.Unlock()
+
+		var result any = map[string]any{}
+		switch msg.Method {
+		case "Target.createTarget":
+			result = map[string]any{"targetId": "T1"}
+		case "Target.attachToTarget":
+			result = map[string]any{"sessionId": "S1"}
+		case "Emulation.setUserAgentOverride":
+			var params struct {
+				UserAgent string `json:"userAgent"`
+			}
+			_ = json.Unmarshal(msg.Params, &params)
+			f.mu.Lock()
+			f.userAgent = params.UserAgent
+			f.mu.Unlock()
+		case "Page.navigate":
+			result = map[string]any{"frameId": "F1", "loaderId": "L2"}
+		case "Runtime.evaluate":
+			result = map[string]any{"result": map[string]any{"type": "string", "value": f.html}}
+		}
+		raw, _ := json.Marshal(result)
+		_ = websocket.JSON.Send(ws, cdpMessage{ID: msg.ID, SessionID: msg.SessionID, Result: raw})
+
+		if msg.Method == "Page.navigate" && f.idle {
+			for _, event := range []map[string]any{
+				{"frameId": "F1", "loaderId": "L1", "name": "networkIdle"}, // The earlier about:blank load
+				{"frameId": "F1", "loaderId": "L2", "name": "load"},
+				{"frameId": "F1", "loaderId": "L2", "name": "networkIdle"},
+			} {
+				params, _ := json.Marshal(event)
+				_ = websocket.JSON.Send(ws, cdpMessage{SessionID: "S1", Method: "Page.lifecycleEvent", Params: params})
+			}
+		}
+	}
+}
+
+func (f *fakeDevTools) start(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(websocket.Handler(f.serve))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestBrowserRendererRender(t *testing.T) {
+	devtools := &fakeDevTools{html: `<html><body><a href="/app/a">A</a></body></html>`, idle: true}
+	renderer, err := NewBrowserRenderer(BrowserOptions{URL: devtools.start(t), Timeout: 5 * time.Second, UserAgent: "LinkTadoru-Test/1.0"})
+	if err != nil {
+		t.Fatalf("NewBrowserRenderer: %v", err)
+	}
+	defer func() { _ = renderer.Close() }()
+
+	html, err := renderer.Render(context.Background(), "https://example.com/app")
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if string(html) != devtools.html {
+		t.Errorf("Expected the rendered DOM, got %q", html)
+	}
+
+	devtools.mu.Lock()
+	defer devtools.mu.Unlock()
+	got := strings.Join(devtools.methods, " ")
+	want := "Target.createTarget Target.attachToTarget Page.enable Page.setLifecycleEventsEnabled " +
+		"Emulation.setUserAgentOverride Page.navigate Runtime.evaluate Target.closeTarget"
+	if got != want {
+		t.Errorf("Unexpected commands:\n got %s\nwant %s", got, want)
+	}
+	if devtools.userAgent != "LinkTadoru-Test/1.0" {
+		t.Errorf("Expected the crawler's User-Agent, got %q", devtools.userAgent)
+	}
+}
+
+// A page that never reaches network idle is read when the timeout passes
+func TestBrowserRendererTimeout(t *testing.T) {
+	devtools := &fakeDevTools{html: `<html><body>polling</body></html>`}
+	renderer, err := NewBrowserRenderer(BrowserOptions{URL: devtools.start(t), Timeout: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewBrowserRenderer: %v", err)
+	}
+	defer func() { _ = renderer.Close() }()
+
+	html, err := renderer.Render(context.Background(), "https://example.com/live")
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if string(html) != devtools.html {
+		t.Errorf("Expected the DOM at the timeout, got %q", html)
+	}
+}
+
+func TestFindBrowser(t *testing.T) {
+	if _, err := findBrowser("/nonexistent/chrome"); err == nil {
+		t.Error("Expected an error for a missing browser_path")
+	}
+	t.Setenv("PATH", t.TempDir())
+	if _, err := NewBrowserRenderer(BrowserOptions{}); err == nil || !strings.Contains(err.Error(), "browser_url") {
+		t.Errorf("Expected an error naming browser_path and browser_url, got %v", err)
+	}
+	if _, err := NewBrowserRenderer(BrowserOptions{URL: "ws://localhost:9222/devtools/browser/x"}); err != nil {
+		t.Errorf("Expected a browser URL not to need an executable, got %v", err)
+	}
+}
+
+func TestLooksLikeSPA(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want bool
+	}{
+		{"empty root", `<title>App</title><div id="root"></div>`, true},
+		{"empty next mount point", `<body><div id="__next" class="x">  </div></body>`, true},
+		{"angular", `<body><app-root></app-root></body>`, true},
+		{"nuxt state", `<script>window.__NUXT__={}</script><p>Loading the application, please wait.</p>`, true},
+		{"bundle", `<title>App</title><p>Loading</p><script src="/static/js/main.4f3a9c.js"></script>`, true},
+		{"inline script", `<p>Hi</p><script>document.body.innerHTML = "<a href='/a'>generated by a long script</a>"</script>`, true},
+		{"plain page", `<title>About</title><h1>About</h1><p>We write software and have no links here.</p>`, false},
+		{"filled root", `<div id="root"><p>Server-rendered content</p></div>`, false},
+		{"similar id", `<div id="app-shell"></div><p>Static page without links</p>`, false},
+		{"analytics script", `<title>About</title><p>We write software and have no links here at all, just this rather long paragraph.</p><script src="/analytics.js"></script>`, false},
+	}
+	for _, tt := range tests {
+		if got := looksLikeSPA([]byte(tt.html)); got != tt.want {
+			t.Errorf("%s: looksLikeSPA = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
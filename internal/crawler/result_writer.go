@@ -0,0 +1,142 @@
+package crawler
+
+import (
+	"log/slog"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// pageWrite is a processed page handed from a worker to the result writers
+type pageWrite struct {
+	workerID int
+	item     URLItem
+	result   *PageResult
+}
+
+// resultWriter persists processed pages on dedicated goroutines
+// (write_buffer_size), so workers go back to fetching instead of waiting on
+// several SQLite writes per page. Workers still queue discovered URLs
+// themselves; a page's row stays 'processing' until its writer has saved it,
+// which keeps HasQueuedItems true and idle workers from exiting early.
+//
+// A full buffer blocks submit, so a slow database slows the workers down
+// rather than letting results pile up in memory.
+type resultWriter struct {
+	crawler *DefaultCrawler
+	writes  chan pageWrite
+	batch   int            // Most writes a writer takes off the channel at once
+	pending sync.WaitGroup // Submitted writes not yet persisted
+	done    sync.WaitGroup // Running writer goroutines
+}
+
+// newResultWriter starts workers writer goroutines fed by a channel holding
+// up to bufferSize pages
+func newResultWriter(c *DefaultCrawler, bufferSize, workers int) *resultWriter {
+	if workers < 1 {
+		workers = 1
+	}
+	w := &resultWriter{
+		crawler: c,
+		writes:  make(chan pageWrite, bufferSize),
+		batch:   bufferSize,
+	}
+	for i := 0; i < workers; i++ {
+		w.done.Add(1)
+		go w.run()
+	}
+	return w
+}
+
+// submit hands a processed page to the writers, blocking while the buffer is full
+func (w *resultWriter) submit(id int, item *URLItem, result *PageResult) {
+	w.pending.Add(1)
+	w.writes <- pageWrite{workerID: id, item: *item, result: result}
+}
+
+// flush waits until every submitted page has been persisted
+func (w *resultWriter) flush() {
+	w.pending.Wait()
+}
+
+// close persists the remaining pages and stops the writer goroutines
+func (w *resultWriter) close() {
+	close(w.writes)
+	w.done.Wait()
+}
+
+// run takes pages off the channel in batches of whatever is buffered and
+// persists them
+func (w *resultWriter) run() {
+	defer w.done.Done()
+
+	batch := make([]pageWrite, 0, w.batch)
+	for write := range w.writes {
+		batch = append(batch[:0], write)
+	drain:
+		for len(batch) < w.batch {
+			select {
+			case next, ok := <-w.writes:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, next)
+			default:
+				break drain
+			}
+		}
+
+		w.persist(batch)
+		for range batch {
+			w.pending.Done()
+		}
+		slog.Debug("Result writer persisted pages", "count", len(batch))
+	}
+}
+
+// persist saves a batch of pages: the links of every page in one SaveLinks
+// call and the completed pages in one SavePageResults transaction. When the
+// transaction fails the pages are saved one by one, so a single bad page
+// cannot leave the whole batch in 'processing'.
+func (w *resultWriter) persist(batch []pageWrite) {
+	c := w.crawler
+	ctx, span := tracer.Start(c.ctx, "persist batch", trace.WithAttributes(attribute.Int("linktadoru.pages", len(batch))))
+	defer span.End()
+
+	var links []*LinkData
+	var completed []CompletedPage
+	for _, pw := range batch {
+		links = append(links, pw.result.Links...)
+		if pw.result.Page != nil {
+			completed = append(completed, CompletedPage{ID: pw.item.ID, Page: pw.result.Page})
+		}
+	}
+	if len(links) > 0 {
+		_, span := tracer.Start(ctx, "storage.SaveLinks", trace.WithAttributes(attribute.Int("linktadoru.links", len(links))))
+		err := c.storage.SaveLinks(links)
+		endSpan(span, err)
+		if err != nil {
+			slog.Error("Result writer failed to save links", "pages", len(batch), "error", err)
+		}
+	}
+
+	batchSaved := false
+	if len(completed) > 0 {
+		_, span := tracer.Start(ctx, "storage.SavePageResults", trace.WithAttributes(attribute.Int("linktadoru.pages", len(completed))))
+		err := c.storage.SavePageResults(completed)
+		endSpan(span, err)
+		if err != nil {
+			slog.Warn("Result writer failed to save page batch, saving pages individually", "pages", len(completed), "error", err)
+		}
+		batchSaved = err == nil
+	}
+
+	for _, pw := range batch {
+		if pw.result.Page != nil && batchSaved {
+			c.saveErrorDetails(pw.workerID, &pw.item, pw.result)
+		} else {
+			c.savePage(ctx, pw.workerID, &pw.item, pw.result)
+		}
+	}
+}
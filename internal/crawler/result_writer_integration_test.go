@@ -0,0 +1,63 @@
+package crawler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// With write_buffer_size, pages are persisted by background writers; every
+// page and link is stored by the time Start returns.
+func TestCrawlWithResultWriters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/":
+			for i := range 6 {
+				_, _ = fmt.Fprintf(w, `<a href="/p%d">%d</a>`, i, i)
+			}
+		case "/p5":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			_, _ = w.Write([]byte(`<a href="/">home</a>`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := baseCfg()
+	cfg.Concurrency = 3
+	cfg.WriteBufferSize = 4
+	cfg.WriteWorkers = 2
+	cfg.SeedURLs = []string{server.URL + "/"}
+	store := newStore(t)
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	pending, processing, completed, _, err := store.GetQueueStatus()
+	if err != nil {
+		t.Fatalf("GetQueueStatus: %v", err)
+	}
+	if pending != 0 || processing != 0 || completed != 7 {
+		t.Errorf("Expected 7 completed pages and an empty queue, got pending=%d processing=%d completed=%d", pending, processing, completed)
+	}
+	if got, _ := statusOf(t, store, server.URL+"/p3"); got != "completed" {
+		t.Errorf("/p3 status = %q, want completed", got)
+	}
+	if stats := c.GetStats(); stats.PagesCrawled != 7 {
+		t.Errorf("PagesCrawled = %d, want 7", stats.PagesCrawled)
+	}
+}
@@ -0,0 +1,78 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// Error types of transient failures, which are retried up to max_retries
+// times. The storage backends requeue exactly these types.
+const (
+	ErrorTypeTimeout           = "network_timeout"
+	ErrorTypeConnectionRefused = "connection_refused"
+	ErrorTypeConnectionReset   = "connection_reset"
+	ErrorTypeDNSFailure        = "dns_resolution_failed"
+	ErrorTypeServerError       = "server_error_5xx"
+)
+
+// maxRetryBackoff caps the wait before a retry round
+const maxRetryBackoff = 5 * time.Minute
+
+// transportErrorType classifies a failed request. Failures a later attempt
+// may get past get one of the transient ErrorType constants; the rest are
+// network_error, or response_too_large for an oversized body.
+func transportErrorType(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrResponseTooLarge):
+		return "response_too_large"
+	case errors.As(err, &dnsErr):
+		// A host that does not exist will not exist on the next attempt either
+		if dnsErr.IsNotFound {
+			return "network_error"
+		}
+		return ErrorTypeDNSFailure
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTypeTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorTypeConnectionRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNABORTED), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorTypeConnectionReset
+	}
+	return "network_error"
+}
+
+// serverErrorRetry returns a server_error_5xx failure in place of a page
+// answered with a 5xx status while the page has retries left, so it is
+// fetched again; otherwise it returns nil and the answer is saved as is
+func (c *DefaultCrawler) serverErrorRetry(item *URLItem, result *PageResult) *PageResult {
+	if result.Page == nil || result.Page.StatusCode < 500 || item.RetryCount >= c.config.MaxRetries {
+		return nil
+	}
+	return &PageResult{
+		Error: &CrawlError{
+			URL:          item.URL,
+			ErrorType:    ErrorTypeServerError,
+			ErrorMessage: fmt.Sprintf("HTTP %d %s", result.Page.StatusCode, http.StatusText(result.Page.StatusCode)),
+			OccurredAt:   time.Now().UTC(),
+		},
+	}
+}
+
+// retryBackoff returns the wait before retry round n (1 for the first):
+// retry_backoff doubled for each round after the first, at most maxRetryBackoff
+func retryBackoff(base time.Duration, round int) time.Duration {
+	backoff := base
+	for i := 1; i < round && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRetryBackoff)
+}
@@ -0,0 +1,119 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// Pages failing transiently are fetched again after the queue drains, until
+// they succeed or use up max_retries; a page still answering 5xx is saved.
+func TestCrawlRetriesTransientFailures(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		n := requests[r.URL.Path]
+		mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<a href="/flaky">F</a><a href="/down">D</a><a href="/reset">R</a><a href="/missing">M</a>`))
+		case r.URL.Path == "/flaky" && n <= 2, r.URL.Path == "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/reset" && n == 1:
+			// Drop the connection without answering
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				_ = conn.Close()
+			}
+		case r.URL.Path == "/missing":
+			http.NotFound(w, r)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<p>ok</p>`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := baseCfg()
+	cfg.MaxRetries = 2
+	cfg.RetryBackoff = 10 * time.Millisecond
+	cfg.SeedURLs = []string{server.URL + "/"}
+	store := newStore(t)
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for path, want := range map[string]int{"/flaky": 3, "/down": 3, "/reset": 2, "/missing": 1} {
+		if requests[path] != want {
+			t.Errorf("%s fetched %d times, want %d", path, requests[path], want)
+		}
+		if status, _ := statusOf(t, store, server.URL+path); status != "completed" {
+			t.Errorf("%s status = %q, want completed", path, status)
+		}
+	}
+}
+
+// Stop ends a crawl while it is retrying, without waiting for the rounds left
+func TestCrawlStopDuringRetries(t *testing.T) {
+	var c *crawler.DefaultCrawler
+	var mu sync.Mutex
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<a href="/down">D</a>`))
+			return
+		}
+		mu.Lock()
+		fetches++
+		retrying := fetches == 2
+		mu.Unlock()
+		if retrying {
+			_ = c.Stop()
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := baseCfg()
+	cfg.MaxRetries = 5
+	cfg.RetryBackoff = time.Millisecond
+	cfg.SeedURLs = []string{server.URL + "/"}
+	var err error
+	c, err = crawler.NewCrawler(cfg, newStore(t))
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fetches != 2 {
+		t.Errorf("/down fetched %d times, want 2", fetches)
+	}
+}
@@ -0,0 +1,47 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestTransportErrorType(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"timeout", fmt.Errorf("get: %w", context.DeadlineExceeded), ErrorTypeTimeout},
+		{"net timeout", &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, ErrorTypeTimeout},
+		{"refused", &net.OpError{Op: "dial", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}, ErrorTypeConnectionRefused},
+		{"reset", &net.OpError{Op: "read", Err: &os.SyscallError{Syscall: "read", Err: syscall.ECONNRESET}}, ErrorTypeConnectionReset},
+		{"closed", fmt.Errorf("get: %w", io.EOF), ErrorTypeConnectionReset},
+		{"dns failure", &net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true}, ErrorTypeDNSFailure},
+		{"unknown host", &net.DNSError{Err: "no such host", Name: "nowhere.invalid", IsNotFound: true}, "network_error"},
+		{"too large", fmt.Errorf("read: %w", ErrResponseTooLarge), "response_too_large"},
+		{"other", errors.New("unsupported protocol scheme"), "network_error"},
+	}
+	for _, tt := range tests {
+		if got := transportErrorType(tt.err); got != tt.want {
+			t.Errorf("%s: transportErrorType = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	for round, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 20: maxRetryBackoff} {
+		if got := retryBackoff(time.Second, round); got != want {
+			t.Errorf("retryBackoff(1s, %d) = %v, want %v", round, got, want)
+		}
+	}
+	if got := retryBackoff(0, 3); got != 0 {
+		t.Errorf("retryBackoff(0, 3) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,31 @@
+package crawler
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+)
+
+// MetaRunID is the crawl_meta key holding the UUID of the latest run stamped
+// with run_header
+const MetaRunID = "run_id"
+
+// newRunID returns a random (version 4) UUID identifying a crawl run
+func newRunID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// recordRunID stores the run's UUID in crawl_meta so requests seen in server
+// logs can be matched to this database
+func (c *DefaultCrawler) recordRunID() {
+	if c.stats.RunID == "" {
+		return
+	}
+	if err := c.storage.SetMeta(MetaRunID, c.stats.RunID); err != nil {
+		slog.Warn("Failed to record run ID", "error", err)
+	}
+}
@@ -0,0 +1,69 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+func TestNewRunID(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	first, second := newRunID(), newRunID()
+	if !pattern.MatchString(first) {
+		t.Errorf("Run ID %q is not a version 4 UUID", first)
+	}
+	if first == second {
+		t.Error("Expected run IDs to differ")
+	}
+}
+
+func TestRunHeader(t *testing.T) {
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get("X-LinkTadoru-Run")
+	}))
+	defer server.Close()
+
+	store := &frontierStorage{meta: map[string]string{}}
+	cfg := &config.CrawlConfig{
+		Concurrency:    1,
+		RequestTimeout: 5 * time.Second,
+		UserAgent:      "LinkTadoru-Test/1.0",
+		RunHeader:      "X-LinkTadoru-Run",
+	}
+	crawler, err := NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+	runID := crawler.GetStats().RunID
+	if runID == "" {
+		t.Fatal("Expected a run ID when run_header is set")
+	}
+
+	if _, err := crawler.httpClient.Get(context.Background(), server.URL); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if got := <-received; got != runID {
+		t.Errorf("Expected header %q, got %q", runID, got)
+	}
+
+	crawler.recordRunID()
+	if store.meta[MetaRunID] != runID {
+		t.Errorf("Expected run ID in crawl_meta, got %q", store.meta[MetaRunID])
+	}
+}
+
+func TestRunHeaderDisabled(t *testing.T) {
+	c, err := NewCrawler(&config.CrawlConfig{Concurrency: 1, RequestTimeout: time.Second}, &MockStorage{})
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+	if runID := c.GetStats().RunID; runID != "" {
+		t.Errorf("Expected no run ID without run_header, got %q", runID)
+	}
+}
@@ -0,0 +1,304 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+	"golang.org/x/net/html"
+)
+
+// Functions a crawl script may define
+const (
+	scriptShouldCrawl = "should_crawl" // should_crawl(url) -> boolean
+	scriptRewriteURL  = "rewrite_url"  // rewrite_url(url) -> string or nil
+	scriptExtract     = "extract"      // extract(url, html) -> table or nil
+)
+
+// Limits of the script sandbox besides the call timeout. They bound the
+// obvious ways to exhaust memory, but not every one: a loop concatenating
+// ever longer strings still allocates until the timeout cuts it off.
+const (
+	// scriptCallStackSize is the deepest a script may recurse
+	scriptCallStackSize = 200
+	// scriptRegistryMaxSize is the most values an interpreter's stack may hold
+	scriptRegistryMaxSize = 1 << 20
+	// scriptMaxRepSize is the longest string string.rep may build, in bytes
+	scriptMaxRepSize = 16 << 20
+	// scriptMaxDepth is the deepest extract may nest the tables it returns
+	scriptMaxDepth = 64
+)
+
+// scriptLibs are the only Lua libraries a script can use: no file, OS or
+// module access
+var scriptLibs = []struct {
+	name string
+	open lua.LGFunction
+}{
+	{lua.BaseLibName, lua.OpenBase},
+	{lua.TabLibName, lua.OpenTable},
+	{lua.StringLibName, lua.OpenString},
+	{lua.MathLibName, lua.OpenMath},
+}
+
+// Script runs the rules of a user-provided Lua script (config script): which
+// URLs to crawl, how to rewrite discovered URLs and which fields to extract
+// from pages. Each call runs in a sandboxed interpreter without file, OS or
+// module access and is cut off after the configured timeout. The sandbox
+// limits recursion, stack size and string.rep, but it does not cap the total
+// memory a script allocates. Interpreters
+// are pooled, so a Script is safe for concurrent use; global state a script
+// keeps between calls is per interpreter and should not be relied on.
+type Script struct {
+	path      string
+	proto     *lua.FunctionProto
+	timeout   time.Duration
+	states    sync.Pool
+	functions map[string]bool // Functions the script defines
+}
+
+// LoadScript compiles the Lua script at path and checks that it defines at
+// least one of should_crawl, rewrite_url and extract
+func LoadScript(path string, timeout time.Duration) (*Script, error) {
+	file, err := os.Open(path) // #nosec G304 -- path comes from user configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to open script: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	chunk, err := parse.Parse(file, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse script %s: %w", path, err)
+	}
+	proto, err := lua.Compile(chunk, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile script %s: %w", path, err)
+	}
+
+	s := &Script{path: path, proto: proto, timeout: timeout, functions: make(map[string]bool)}
+	L, err := s.newState()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{scriptShouldCrawl, scriptRewriteURL, scriptExtract} {
+		s.functions[name] = L.GetGlobal(name).Type() == lua.LTFunction
+	}
+	s.states.Put(L)
+	if !s.functions[scriptShouldCrawl] && !s.functions[scriptRewriteURL] && !s.functions[scriptExtract] {
+		return nil, fmt.Errorf("script %s defines none of %s, %s and %s", path, scriptShouldCrawl, scriptRewriteURL, scriptExtract)
+	}
+	return s, nil
+}
+
+// newState returns a sandboxed interpreter that has run the script's top level
+func (s *Script) newState() (*lua.LState, error) {
+	L := lua.NewState(lua.Options{
+		SkipOpenLibs:    true,
+		CallStackSize:   scriptCallStackSize,
+		RegistryMaxSize: scriptRegistryMaxSize,
+	})
+	for _, lib := range scriptLibs {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	if strlib, ok := L.GetGlobal(lua.StringLibName).(*lua.LTable); ok {
+		L.SetField(strlib, "rep", L.NewFunction(scriptStringRep))
+	}
+	for _, name := range []string{"dofile", "loadfile", "load", "loadstring"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	L.SetContext(ctx)
+	defer L.RemoveContext()
+	L.Push(L.NewFunctionFromProto(s.proto))
+	if err := L.PCall(0, 0, nil); err != nil {
+		L.Close()
+		return nil, fmt.Errorf("failed to run script %s: %w", s.path, err)
+	}
+	return L, nil
+}
+
+// Defines reports whether the script defines the function name
+func (s *Script) Defines(name string) bool {
+	return s.functions[name]
+}
+
+// call runs a function of the script with args and returns its result. An
+// interpreter whose call failed, e.g. timed out, is discarded.
+func (s *Script) call(name string, args ...lua.LValue) (lua.LValue, error) {
+	L, ok := s.states.Get().(*lua.LState)
+	if !ok {
+		var err error
+		if L, err = s.newState(); err != nil {
+			return lua.LNil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	L.SetContext(ctx)
+	err := L.CallByParam(lua.P{Fn: L.GetGlobal(name), NRet: 1, Protect: true}, args...)
+	L.RemoveContext()
+	if err != nil {
+		L.Close()
+		return lua.LNil, fmt.Errorf("script %s: %s: %w", s.path, name, err)
+	}
+	result := L.Get(-1)
+	L.Pop(1)
+	s.states.Put(L)
+	return result, nil
+}
+
+// ShouldCrawl returns the answer of should_crawl(url); true when the script
+// does not define it
+func (s *Script) ShouldCrawl(url string) (bool, error) {
+	if !s.functions[scriptShouldCrawl] {
+		return true, nil
+	}
+	result, err := s.call(scriptShouldCrawl, lua.LString(url))
+	if err != nil {
+		return false, err
+	}
+	return lua.LVAsBool(result), nil
+}
+
+// RewriteURL returns the URL rewrite_url(url) returns; url itself when the
+// script does not define it or returns nil or an empty string
+func (s *Script) RewriteURL(url string) (string, error) {
+	if !s.functions[scriptRewriteURL] {
+		return url, nil
+	}
+	result, err := s.call(scriptRewriteURL, lua.LString(url))
+	if err != nil {
+		return url, err
+	}
+	switch rewritten := result.(type) {
+	case *lua.LNilType:
+		return url, nil
+	case lua.LString:
+		if rewritten == "" {
+			return url, nil
+		}
+		return string(rewritten), nil
+	default:
+		return url, fmt.Errorf("script %s: %s returned a %s, not a string", s.path, scriptRewriteURL, result.Type())
+	}
+}
+
+// Extract implements Extractor with extract(url, html), which returns a
+// table of fields or nil
+func (s *Script) Extract(url string, body []byte, _ *html.Node) (map[string]any, error) {
+	result, err := s.call(scriptExtract, lua.LString(url), lua.LString(body))
+	if err != nil {
+		return nil, err
+	}
+	switch fields := result.(type) {
+	case *lua.LNilType:
+		return nil, nil
+	case *lua.LTable:
+		value, err := luaToGo(fields, map[*lua.LTable]bool{}, 0)
+		if err != nil {
+			return nil, fmt.Errorf("script %s: %s: %w", s.path, scriptExtract, err)
+		}
+		if value, ok := value.(map[string]any); ok {
+			return value, nil
+		}
+		return nil, fmt.Errorf("script %s: %s returned an array, not a table of fields", s.path, scriptExtract)
+	default:
+		return nil, fmt.Errorf("script %s: %s returned a %s, not a table", s.path, scriptExtract, result.Type())
+	}
+}
+
+// rewriteLinks replaces the targets of a page's links with the URLs the
+// script's rewrite_url returns; a failed rewrite keeps the URL
+func (c *DefaultCrawler) rewriteLinks(result *PageResult) {
+	if c.script == nil || !c.script.Defines(scriptRewriteURL) {
+		return
+	}
+	for _, link := range result.Links {
+		rewritten, err := c.script.RewriteURL(link.TargetURL)
+		if err != nil {
+			slog.Warn("Script failed to rewrite URL", "url", link.TargetURL, "error", err)
+		}
+		link.TargetURL = rewritten
+	}
+}
+
+// scriptStringRep is string.rep refusing to build strings over
+// scriptMaxRepSize
+func scriptStringRep(L *lua.LState) int {
+	str := L.CheckString(1)
+	n := L.CheckInt(2)
+	if n <= 0 || str == "" {
+		L.Push(lua.LString(""))
+		return 1
+	}
+	if len(str) > scriptMaxRepSize/n {
+		L.RaiseError("string.rep result exceeds %d bytes", scriptMaxRepSize)
+		return 0
+	}
+	L.Push(lua.LString(strings.Repeat(str, n)))
+	return 1
+}
+
+// luaToGo converts a Lua value to its JSON-compatible Go equivalent: tables
+// with only the keys 1..n become slices, other tables maps with string keys.
+// open holds the tables being converted, so a table containing itself is an
+// error rather than an endless recursion; depth is the nesting so far.
+func luaToGo(value lua.LValue, open map[*lua.LTable]bool, depth int) (any, error) {
+	switch v := value.(type) {
+	case lua.LBool:
+		return bool(v), nil
+	case lua.LNumber:
+		return float64(v), nil
+	case lua.LString:
+		return string(v), nil
+	case *lua.LTable:
+		if open[v] {
+			return nil, errors.New("table contains itself")
+		}
+		if depth >= scriptMaxDepth {
+			return nil, fmt.Errorf("tables nested deeper than %d levels", scriptMaxDepth)
+		}
+		open[v] = true
+		defer delete(open, v)
+
+		n := v.MaxN()
+		count := 0
+		v.ForEach(func(lua.LValue, lua.LValue) { count++ })
+		if n > 0 && n == count {
+			items := make([]any, 0, n)
+			for i := 1; i <= n; i++ {
+				item, err := luaToGo(v.RawGetInt(i), open, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			return items, nil
+		}
+		fields := make(map[string]any, count)
+		var err error
+		v.ForEach(func(key, item lua.LValue) {
+			if err != nil {
+				return
+			}
+			fields[key.String()], err = luaToGo(item, open, depth+1)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return fields, nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,76 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage/memory"
+)
+
+// A script chooses the URLs to crawl, rewrites links and extracts fields
+func TestCrawlScript(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(`<a href="/shop/42?ref=home">Product</a><a href="/shop/cart">Cart</a>`))
+		case "/shop/42":
+			_, _ = w.Write([]byte(`<h1 class="name">Teapot</h1>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(site.Close)
+
+	path := filepath.Join(t.TempDir(), "rules.lua")
+	script := `
+		function should_crawl(url)
+			return not url:find("/shop/") or url:match("/shop/%d+$") ~= nil
+		end
+		function rewrite_url(url)
+			return (url:gsub("%?ref=%w+$", ""))
+		end
+		function extract(url, html)
+			return {name = html:match('<h1 class="name">(.-)</h1>')}
+		end
+	`
+	if err := os.WriteFile(path, []byte(script), 0600); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	cfg := baseCfg()
+	cfg.SeedURLs = []string{site.URL + "/"}
+	cfg.Script = path
+	cfg.ScriptTimeout = time.Second
+	store := memory.New()
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	product, ok := store.Page(site.URL + "/shop/42")
+	if !ok {
+		t.Fatal("Expected the rewritten product URL to be crawled")
+	}
+	if len(product.Extractions) != 1 || product.Extractions[0].Extractor != "script" || product.Extractions[0].Fields["name"] != "Teapot" {
+		t.Errorf("Unexpected extractions: %+v", product.Extractions)
+	}
+	if status, _ := store.GetURLStatus(site.URL + "/shop/cart"); status == "completed" {
+		t.Error("Expected the cart page not to be crawled")
+	}
+	if _, ok := store.GetURLStatus(site.URL + "/shop/42?ref=home"); ok {
+		t.Error("Expected the original link target to be rewritten")
+	}
+}
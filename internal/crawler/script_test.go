@@ -0,0 +1,177 @@
+package crawler
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeScript writes a Lua script to a temporary file and loads it
+func writeScript(t *testing.T, source string) (*Script, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.lua")
+	if err := os.WriteFile(path, []byte(source), 0600); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	return LoadScript(path, 200*time.Millisecond)
+}
+
+func TestScript(t *testing.T) {
+	script, err := writeScript(t, `
+		function should_crawl(url)
+			return url:match("/shop/%d+$") ~= nil or not url:find("/shop/")
+		end
+
+		function rewrite_url(url)
+			if url:find("^http://") then
+				return "https://" .. url:sub(8)
+			end
+		end
+
+		function extract(url, html)
+			local price = html:match('id="price">([%d.]+)<')
+			if not price then
+				return nil
+			end
+			return {price = tonumber(price), tags = {"shop", "product"}, stock = {count = 3, low = false}}
+		end
+	`)
+	if err != nil {
+		t.Fatalf("LoadScript failed: %v", err)
+	}
+
+	for url, want := range map[string]bool{
+		"https://example.com/shop/123":     true,
+		"https://example.com/shop/sale":    false,
+		"https://example.com/about":        true,
+		"https://example.com/shop/123/pic": false,
+	} {
+		if got, err := script.ShouldCrawl(url); err != nil || got != want {
+			t.Errorf("ShouldCrawl(%s) = %v, %v; want %v", url, got, err, want)
+		}
+	}
+
+	if got, _ := script.RewriteURL("http://example.com/a"); got != "https://example.com/a" {
+		t.Errorf("Unexpected rewrite: %s", got)
+	}
+	if got, _ := script.RewriteURL("https://example.com/b"); got != "https://example.com/b" {
+		t.Errorf("Expected nil to keep the URL, got %s", got)
+	}
+
+	fields, err := script.Extract("https://example.com/shop/1", []byte(`<span id="price">9.99</span>`), nil)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	want := map[string]any{"price": 9.99, "tags": []any{"shop", "product"}, "stock": map[string]any{"count": 3.0, "low": false}}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("Unexpected fields: %#v", fields)
+	}
+	if fields, err := script.Extract("https://example.com/", []byte(`<p>none</p>`), nil); err != nil || fields != nil {
+		t.Errorf("Expected no fields, got %v, %v", fields, err)
+	}
+}
+
+func TestScriptSafety(t *testing.T) {
+	// Runaway calls are cut off, and the next call gets a fresh interpreter
+	script, err := writeScript(t, `
+		calls = 0
+		function should_crawl(url)
+			calls = calls + 1
+			if url:find("loop") then
+				while true do end
+			end
+			return true
+		end
+	`)
+	if err != nil {
+		t.Fatalf("LoadScript failed: %v", err)
+	}
+	start := time.Now()
+	if crawl, err := script.ShouldCrawl("https://example.com/loop"); err == nil || crawl {
+		t.Errorf("Expected the endless loop to fail, got %v", crawl)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Timeout took %v", elapsed)
+	}
+	if crawl, err := script.ShouldCrawl("https://example.com/"); err != nil || !crawl {
+		t.Errorf("Expected the script to recover, got %v, %v", crawl, err)
+	}
+
+	// No file, OS or module access
+	for _, source := range []string{
+		`os.exit(1)`,
+		`io.open("/etc/passwd")`,
+		`dofile("/etc/passwd")`,
+		`require("os")`,
+	} {
+		if _, err := writeScript(t, source+"\nfunction should_crawl(url) return true end"); err == nil {
+			t.Errorf("Expected %q to fail", source)
+		}
+	}
+
+	if _, err := writeScript(t, `x = 1`); err == nil || !strings.Contains(err.Error(), "defines none of") {
+		t.Errorf("Expected a script without rules to be rejected, got %v", err)
+	}
+	if _, err := writeScript(t, `function should_crawl(url`); err == nil {
+		t.Error("Expected a syntax error")
+	}
+}
+
+func TestScriptLimits(t *testing.T) {
+	script, err := writeScript(t, `
+		function extract(url, html)
+			if url:find("cycle") then
+				local t = {}
+				t.self = t
+				return t
+			elseif url:find("deep") then
+				local t = {}
+				for i = 1, 1000 do t = {child = t} end
+				return t
+			elseif url:find("shared") then
+				local shared = {1, 2}
+				return {a = shared, b = shared}
+			end
+			return {ok = true}
+		end
+
+		function should_crawl(url)
+			if url:find("rep") then
+				return #string.rep("x", 1e10) > 0
+			elseif url:find("method") then
+				return #("x"):rep(1e10) > 0
+			end
+			local function recurse(n) return recurse(n + 1) + 1 end
+			return recurse(1) > 0
+		end
+	`)
+	if err != nil {
+		t.Fatalf("LoadScript failed: %v", err)
+	}
+
+	// A table containing itself is an error, not an endless conversion
+	if _, err := script.Extract("https://example.com/cycle", nil, nil); err == nil || !strings.Contains(err.Error(), "contains itself") {
+		t.Errorf("Expected a cycle error, got %v", err)
+	}
+	if _, err := script.Extract("https://example.com/deep", nil, nil); err == nil || !strings.Contains(err.Error(), "nested deeper") {
+		t.Errorf("Expected a nesting error, got %v", err)
+	}
+	// A table referenced twice without a cycle converts both times
+	fields, err := script.Extract("https://example.com/shared", nil, nil)
+	if want := map[string]any{"a": []any{1.0, 2.0}, "b": []any{1.0, 2.0}}; err != nil || !reflect.DeepEqual(fields, want) {
+		t.Errorf("Unexpected shared fields: %#v, %v", fields, err)
+	}
+
+	// Huge strings and unbounded recursion fail the call instead of the crawl
+	for _, url := range []string{"https://example.com/rep", "https://example.com/method", "https://example.com/recurse"} {
+		if crawl, err := script.ShouldCrawl(url); err == nil || crawl {
+			t.Errorf("ShouldCrawl(%s) = %v, %v; want an error", url, crawl, err)
+		}
+	}
+	if fields, err := script.Extract("https://example.com/", nil, nil); err != nil || fields["ok"] != true {
+		t.Errorf("Expected the script to recover, got %v, %v", fields, err)
+	}
+}
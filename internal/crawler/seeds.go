@@ -0,0 +1,43 @@
+package crawler
+
+import (
+	"encoding/json"
+	"log/slog"
+)
+
+// MetaSeedURLs is the crawl_meta key holding the seed URLs of every run on
+// the database as a JSON array, in the order they were first given
+const MetaSeedURLs = "seed_urls"
+
+// recordSeedURLs adds the run's normalized seed URLs to those recorded by
+// earlier runs, so analyses such as click depth can start from them
+func (c *DefaultCrawler) recordSeedURLs(urls []string) {
+	seeds := c.recordedSeedURLs()
+	known := make(map[string]bool, len(seeds))
+	for _, seed := range seeds {
+		known[seed] = true
+	}
+	for _, u := range urls {
+		if !known[u] {
+			known[u] = true
+			seeds = append(seeds, u)
+		}
+	}
+
+	data, err := json.Marshal(seeds)
+	if err == nil {
+		err = c.storage.SetMeta(MetaSeedURLs, string(data))
+	}
+	if err != nil {
+		slog.Warn("Failed to record seed URLs", "error", err)
+	}
+}
+
+// recordedSeedURLs returns the seed URLs recorded by earlier runs
+func (c *DefaultCrawler) recordedSeedURLs() []string {
+	var seeds []string
+	if value, err := c.storage.GetMeta(MetaSeedURLs); err == nil && value != "" {
+		_ = json.Unmarshal([]byte(value), &seeds)
+	}
+	return seeds
+}
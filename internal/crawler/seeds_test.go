@@ -0,0 +1,29 @@
+package crawler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+func TestRecordSeedURLs(t *testing.T) {
+	store := &frontierStorage{meta: map[string]string{}}
+	cfg := &config.CrawlConfig{
+		Concurrency:    1,
+		RequestTimeout: 5 * time.Second,
+		UserAgent:      "LinkTadoru-Test/1.0",
+	}
+	crawler, err := NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	// Seeds of later runs are added to those already recorded
+	crawler.recordSeedURLs([]string{"https://example.com/", "https://example.com/blog"})
+	crawler.recordSeedURLs([]string{"https://example.com/blog", "https://example.org/"})
+	want := `["https://example.com/","https://example.com/blog","https://example.org/"]`
+	if got := store.meta[MetaSeedURLs]; got != want {
+		t.Errorf("Expected recorded seeds %s, got %s", want, got)
+	}
+}
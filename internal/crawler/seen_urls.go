@@ -0,0 +1,66 @@
+package crawler
+
+import (
+	"sync"
+)
+
+// seenURLs is a bounded, concurrency-safe set of URLs processNewURLs need not
+// look at again: those queued, being crawled or finished, and those dropped by
+// max_queue_size. It is consulted before asking storage, so the links every
+// page repeats (navigation, footer) cost no database query after the first
+// time. A queued URL never returns to 'discovered' during a crawl, so those
+// entries do not go stale. A dropped URL keeps its row as it was, possibly
+// 'discovered' or missing, but stays in the set on purpose: rediscovering it
+// neither queues it later nor counts it as dropped again.
+//
+// Once max entries are stored the set stops growing and further lookups fall
+// through to storage. A nil set caches nothing.
+type seenURLs struct {
+	mu   sync.RWMutex
+	urls map[string]struct{}
+	max  int
+}
+
+// newSeenURLs returns a set holding up to max URLs, or nil when max is 0
+func newSeenURLs(max int) *seenURLs {
+	if max <= 0 {
+		return nil
+	}
+	return &seenURLs{urls: make(map[string]struct{}), max: max}
+}
+
+// has reports whether u is known to be queued, crawled or dropped
+func (s *seenURLs) has(u string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.urls[u]
+	return ok
+}
+
+// add records URLs as queued, crawled or dropped while there is room
+func (s *seenURLs) add(urls ...string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range urls {
+		if len(s.urls) >= s.max {
+			return
+		}
+		s.urls[u] = struct{}{}
+	}
+}
+
+// size returns the number of cached URLs
+func (s *seenURLs) size() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.urls)
+}
@@ -0,0 +1,78 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+func TestSeenURLs(t *testing.T) {
+	disabled := newSeenURLs(0)
+	disabled.add("https://example.com/")
+	if disabled.has("https://example.com/") || disabled.size() != 0 {
+		t.Error("Expected a disabled cache to remember nothing")
+	}
+
+	seen := newSeenURLs(2)
+	seen.add("https://example.com/a", "https://example.com/b", "https://example.com/c")
+	if !seen.has("https://example.com/a") || !seen.has("https://example.com/b") {
+		t.Error("Expected the first URLs to be cached")
+	}
+	if seen.has("https://example.com/c") || seen.size() != 2 {
+		t.Errorf("Expected the cache to stop at its limit, size %d", seen.size())
+	}
+}
+
+// lookupCountingStorage reports every URL as completed and counts lookups
+type lookupCountingStorage struct {
+	queueRecordingStorage
+	lookups int
+}
+
+func (s *lookupCountingStorage) GetURLStatus(url string) (string, bool) {
+	s.lookups++
+	return "completed", true
+}
+
+func TestProcessNewURLsUsesSeenCache(t *testing.T) {
+	store := &lookupCountingStorage{}
+	crawler := &DefaultCrawler{
+		config:       &config.CrawlConfig{},
+		storage:      store,
+		allowedHosts: []string{"https://example.com"},
+		seen:         newSeenURLs(100),
+	}
+
+	links := []*LinkData{
+		{TargetURL: "https://example.com/", LinkType: "internal"},
+		{TargetURL: "https://example.com/about", LinkType: "internal"},
+	}
+	for range 3 {
+		crawler.processNewURLs(1, links, "https://example.com/page")
+	}
+
+	if store.lookups != 2 {
+		t.Errorf("Expected one storage lookup per URL, got %d", store.lookups)
+	}
+	if len(store.queued) != 0 {
+		t.Errorf("Expected completed URLs not to be queued, got %v", store.queued)
+	}
+}
+
+func TestProcessNewURLsCachesQueuedURLs(t *testing.T) {
+	store := &queueRecordingStorage{}
+	crawler := &DefaultCrawler{
+		config:       &config.CrawlConfig{},
+		storage:      store,
+		allowedHosts: []string{"https://example.com"},
+		seen:         newSeenURLs(100),
+	}
+
+	links := []*LinkData{{TargetURL: "https://example.com/new", LinkType: "internal"}}
+	crawler.processNewURLs(1, links, "https://example.com/")
+	crawler.processNewURLs(1, links, "https://example.com/other")
+
+	if len(store.queued) != 1 {
+		t.Errorf("Expected the new URL to be queued once, got %v", store.queued)
+	}
+}
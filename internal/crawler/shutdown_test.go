@@ -0,0 +1,88 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage/memory"
+)
+
+// startAndInterrupt crawls the root of a site answering with handler and
+// cancels the crawl as soon as the request arrives. It returns the storage
+// and how long Start took to return after the cancellation.
+func startAndInterrupt(t *testing.T, shutdownTimeout time.Duration, handler func(w http.ResponseWriter, r *http.Request)) (*memory.Storage, string, time.Duration) {
+	t.Helper()
+	arrived := make(chan struct{}, 1)
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case arrived <- struct{}{}:
+		default:
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(site.Close)
+
+	cfg := baseCfg()
+	cfg.ShutdownTimeout = shutdownTimeout
+	store := memory.New()
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var cancelledAt time.Time
+	go func() {
+		<-arrived
+		cancelledAt = time.Now()
+		cancel()
+	}()
+	root := site.URL + "/"
+	if err := c.Start(ctx, []string{root}); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if reason := c.GetStats().StopReason; reason != crawler.StopReasonInterrupted {
+		t.Errorf("Stop reason = %q, want %q", reason, crawler.StopReasonInterrupted)
+	}
+	return store, root, time.Since(cancelledAt)
+}
+
+// An interrupted crawl lets the page being fetched finish and saves it
+func TestShutdownDrainsPagesInFlight(t *testing.T) {
+	store, root, _ := startAndInterrupt(t, 5*time.Second, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<title>Slow</title><a href="/next">Next</a>`))
+	})
+
+	if status, _ := store.GetURLStatus(root); status != "completed" {
+		t.Errorf("Page in flight has status %q, want completed", status)
+	}
+	if page, ok := store.Page(root); !ok || page.Title != "Slow" {
+		t.Errorf("Page in flight was not saved: %+v", page)
+	}
+}
+
+// A page still in flight after shutdown_timeout is cut off and requeued
+// rather than left in 'processing' or recorded as an error
+func TestShutdownRequeuesPagesCutOff(t *testing.T) {
+	store, root, elapsed := startAndInterrupt(t, 100*time.Millisecond, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	if elapsed > 3*time.Second {
+		t.Errorf("Start returned %v after the interrupt, expected about shutdown_timeout", elapsed)
+	}
+	if status, _ := store.GetURLStatus(root); status != "pending" {
+		t.Errorf("Page cut off has status %q, want pending", status)
+	}
+	if _, processing, _, errors, _ := store.GetQueueStatus(); processing != 0 || errors != 0 {
+		t.Errorf("Expected no processing or error rows, got %d processing and %d errors", processing, errors)
+	}
+}
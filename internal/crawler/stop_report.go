@@ -0,0 +1,89 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+)
+
+// Why a crawl run ended
+const (
+	StopReasonCompleted   = "completed"     // The queue was drained
+	StopReasonLimit       = "limit"         // The page limit was reached
+	StopReasonTimeout     = "timeout_total" // The run deadline passed
+	StopReasonInterrupted = "interrupted"   // The run was cancelled, e.g. by a signal
+)
+
+// Stop bookkeeping stored in crawl_meta, overwritten by every run
+const (
+	MetaStopReason          = "stop_reason"
+	MetaStopRemainingQueued = "stop_remaining_queued"
+	MetaStopRemainingSample = "stop_remaining_sample"
+
+	// stopSampleSize is the number of remaining URLs kept as a sample
+	stopSampleSize = 100
+)
+
+// stopReason classifies why Start returned. ctx is the caller's context: the
+// crawler's own context is always cancelled once the workers exit.
+func (c *DefaultCrawler) stopReason(ctx context.Context) string {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return StopReasonTimeout
+	case ctx.Err() != nil:
+		return StopReasonInterrupted
+	}
+
+	c.statsMutex.RLock()
+	defer c.statsMutex.RUnlock()
+	if c.config.Limit > 0 && c.stats.PagesCrawled >= c.config.Limit {
+		return StopReasonLimit
+	}
+	return StopReasonCompleted
+}
+
+// recordStop stores the stop reason and what was left in the queue in the
+// stats and in crawl_meta, so users can see what a bounded run left out
+func (c *DefaultCrawler) recordStop(ctx context.Context) {
+	reason := c.stopReason(ctx)
+
+	pending, processing, _, _, err := c.storage.GetQueueStatus()
+	if err != nil {
+		slog.Error("Failed to count remaining queue", "error", err)
+	}
+	remaining := pending + processing
+
+	var sample []string
+	if remaining > 0 {
+		if sample, err = c.storage.GetQueuedURLs(stopSampleSize); err != nil {
+			slog.Error("Failed to sample remaining queue", "error", err)
+		}
+	}
+	if sample == nil {
+		sample = []string{}
+	}
+
+	c.statsMutex.Lock()
+	c.stats.StopReason = reason
+	c.stats.RemainingQueued = remaining
+	c.stats.RemainingSample = sample
+	c.statsMutex.Unlock()
+
+	slog.Info("Crawl stopped", "reason", reason, "remaining_queued", remaining)
+
+	if err := c.storage.SetMeta(MetaStopReason, reason); err != nil {
+		slog.Error("Failed to record stop reason", "error", err)
+	}
+	if err := c.storage.SetMeta(MetaStopRemainingQueued, strconv.Itoa(remaining)); err != nil {
+		slog.Error("Failed to record remaining queue count", "error", err)
+	}
+	data, err := json.Marshal(urlSample{Reason: reason, URLs: sample})
+	if err == nil {
+		err = c.storage.SetMeta(MetaStopRemainingSample, string(data))
+	}
+	if err != nil {
+		slog.Error("Failed to record remaining queue sample", "error", err)
+	}
+}
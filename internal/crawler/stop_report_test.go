@@ -0,0 +1,88 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+// queueSampleStorage reports a fixed queue and keeps meta in memory
+type queueSampleStorage struct {
+	frontierStorage
+}
+
+func (s *queueSampleStorage) GetQueuedURLs(limit int) ([]string, error) {
+	if limit < len(s.queued) {
+		return s.queued[:limit], nil
+	}
+	return s.queued, nil
+}
+
+func TestStopReason(t *testing.T) {
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		limit   int
+		crawled int
+		want    string
+	}{
+		{"queue drained", context.Background(), 0, 5, StopReasonCompleted},
+		{"below limit", context.Background(), 10, 5, StopReasonCompleted},
+		{"limit reached", context.Background(), 5, 5, StopReasonLimit},
+		{"deadline", expired, 5, 5, StopReasonTimeout},
+		{"cancelled", cancelled, 0, 1, StopReasonInterrupted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &DefaultCrawler{
+				config: &config.CrawlConfig{Limit: tt.limit},
+				stats:  CrawlStats{PagesCrawled: tt.crawled},
+			}
+			if got := c.stopReason(tt.ctx); got != tt.want {
+				t.Errorf("stopReason() = %q, expected %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordStop(t *testing.T) {
+	store := &queueSampleStorage{frontierStorage{
+		queued: []string{"https://example.com/a", "https://example.com/b"},
+		meta:   map[string]string{},
+	}}
+	c := &DefaultCrawler{
+		config:  &config.CrawlConfig{Limit: 1},
+		storage: store,
+		stats:   CrawlStats{PagesCrawled: 1},
+	}
+
+	c.recordStop(context.Background())
+
+	stats := c.GetStats()
+	if stats.StopReason != StopReasonLimit || stats.RemainingQueued != 2 || len(stats.RemainingSample) != 2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if store.meta[MetaStopReason] != StopReasonLimit {
+		t.Errorf("Expected stop reason in meta, got %q", store.meta[MetaStopReason])
+	}
+	if store.meta[MetaStopRemainingQueued] != "2" {
+		t.Errorf("Expected remaining count in meta, got %q", store.meta[MetaStopRemainingQueued])
+	}
+
+	var sample urlSample
+	if err := json.Unmarshal([]byte(store.meta[MetaStopRemainingSample]), &sample); err != nil {
+		t.Fatalf("Invalid sample JSON: %v", err)
+	}
+	if sample.Reason != StopReasonLimit || len(sample.URLs) != 2 || sample.URLs[0] != "https://example.com/a" {
+		t.Errorf("Unexpected sample: %+v", sample)
+	}
+}
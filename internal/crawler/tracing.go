@@ -0,0 +1,34 @@
+package crawler
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the crawler's spans. It follows the global tracer provider,
+// a no-op unless tracing.Setup installed an exporter.
+var tracer = otel.Tracer("github.com/masahif/linktadoru/internal/crawler")
+
+// endSpan records err, if any, on span and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// addPhaseSpan adds a finished span from start to end under the span in ctx,
+// for request phases that are only timed while they run. Phases that did not
+// happen, such as the DNS lookup of a reused connection, are left out.
+func addPhaseSpan(ctx context.Context, name string, start, end time.Time) {
+	if start.IsZero() || end.IsZero() {
+		return
+	}
+	_, span := tracer.Start(ctx, name, trace.WithTimestamp(start))
+	span.End(trace.WithTimestamp(end))
+}
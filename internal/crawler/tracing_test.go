@@ -0,0 +1,67 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestPageProcessorSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Traced</title></head><body><a href="/next">Next</a></body></html>`))
+	}))
+	defer server.Close()
+
+	processor := NewPageProcessor(NewHTTPClient("Test-Crawler/1.0", 30*time.Second))
+	if _, err := processor.Process(context.Background(), server.URL+"/"); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	request, ok := spans["HTTP GET"]
+	if !ok {
+		t.Fatalf("No HTTP GET span in %v", spans)
+	}
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range request.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs["url.full"].AsString() != server.URL+"/" || attrs["http.response.status_code"].AsInt64() != 200 {
+		t.Errorf("Unexpected HTTP GET attributes: %v", request.Attributes())
+	}
+
+	// The connection phases are children of the request span
+	for _, name := range []string{"connect", "wait for first byte"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("No %q span", name)
+			continue
+		}
+		if span.Parent().SpanID() != request.SpanContext().SpanID() {
+			t.Errorf("Span %q is not a child of HTTP GET", name)
+		}
+	}
+	if _, ok := spans["dns"]; ok {
+		t.Errorf("Unexpected dns span for an IP address")
+	}
+	if _, ok := spans["parse html"]; !ok {
+		t.Errorf("No parse html span")
+	}
+}
@@ -0,0 +1,99 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+// TrapDetector recognizes URLs from infinite URL spaces ("spider traps"):
+// session IDs appended to ever-longer paths, relative links that repeat path
+// segments (/a/b/a/b/...), and calendars or sort combinations that generate
+// endless query strings. A zero limit disables its rule.
+type TrapDetector struct {
+	maxURLLength        int
+	maxPathSegments     int
+	maxRepeatedSegments int
+	maxQueryParams      int
+	maxQueryVariants    int
+
+	mu            sync.Mutex
+	queryVariants map[string]int // scheme://host/path -> URLs with a query checked this run
+}
+
+// NewTrapDetector returns a detector for the configured limits, or nil when
+// every limit is disabled
+func NewTrapDetector(cfg *config.CrawlConfig) *TrapDetector {
+	if cfg.MaxURLLength == 0 && cfg.MaxPathSegments == 0 && cfg.MaxRepeatedSegments == 0 &&
+		cfg.MaxQueryParams == 0 && cfg.MaxQueryVariants == 0 {
+		return nil
+	}
+	return &TrapDetector{
+		maxURLLength:        cfg.MaxURLLength,
+		maxPathSegments:     cfg.MaxPathSegments,
+		maxRepeatedSegments: cfg.MaxRepeatedSegments,
+		maxQueryParams:      cfg.MaxQueryParams,
+		maxQueryVariants:    cfg.MaxQueryVariants,
+		queryVariants:       make(map[string]int),
+	}
+}
+
+// Check returns a description of the trap rule rawURL hits, or "" when the URL
+// may be crawled. Query variants are counted per path across calls, so the
+// first max_query_variants URLs of a path pass and later ones are trapped.
+func (d *TrapDetector) Check(rawURL string) string {
+	if d == nil {
+		return ""
+	}
+
+	if d.maxURLLength > 0 && len(rawURL) > d.maxURLLength {
+		return fmt.Sprintf("URL length %d exceeds max_url_length %d", len(rawURL), d.maxURLLength)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(u.EscapedPath(), "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if d.maxPathSegments > 0 && len(segments) > d.maxPathSegments {
+		return fmt.Sprintf("%d path segments exceed max_path_segments %d", len(segments), d.maxPathSegments)
+	}
+	if d.maxRepeatedSegments > 0 {
+		counts := make(map[string]int, len(segments))
+		for _, segment := range segments {
+			counts[segment]++
+			if counts[segment] > d.maxRepeatedSegments {
+				return fmt.Sprintf("path segment %q repeats more than max_repeated_segments %d times", segment, d.maxRepeatedSegments)
+			}
+		}
+	}
+
+	if u.RawQuery == "" {
+		return ""
+	}
+	if d.maxQueryParams > 0 {
+		if params := strings.Count(u.RawQuery, "&") + 1; params > d.maxQueryParams {
+			return fmt.Sprintf("%d query parameters exceed max_query_params %d", params, d.maxQueryParams)
+		}
+	}
+	if d.maxQueryVariants > 0 {
+		key := u.Scheme + "://" + u.Host + u.EscapedPath()
+		d.mu.Lock()
+		d.queryVariants[key]++
+		variants := d.queryVariants[key]
+		d.mu.Unlock()
+		if variants > d.maxQueryVariants {
+			return fmt.Sprintf("more than max_query_variants %d query strings for %s", d.maxQueryVariants, key)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package crawler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+func TestNewTrapDetectorDisabled(t *testing.T) {
+	d := NewTrapDetector(&config.CrawlConfig{})
+	if d != nil {
+		t.Fatalf("Expected nil detector without limits, got %+v", d)
+	}
+	if trap := d.Check("https://example.com/" + strings.Repeat("a/", 100)); trap != "" {
+		t.Errorf("Expected a nil detector to pass every URL, got %q", trap)
+	}
+}
+
+func TestTrapDetectorRules(t *testing.T) {
+	d := NewTrapDetector(&config.CrawlConfig{
+		MaxURLLength:        60,
+		MaxPathSegments:     5,
+		MaxRepeatedSegments: 2,
+		MaxQueryParams:      3,
+	})
+
+	tests := []struct {
+		url  string
+		rule string // Substring of the expected trap description; "" = not trapped
+	}{
+		{"https://example.com/a/b/c", ""},
+		{"https://example.com/" + strings.Repeat("x", 60), "max_url_length"},
+		{"https://example.com/a/b/c/d/e/f", "max_path_segments"},
+		{"https://example.com/a/b/a/b/", ""},
+		{"https://example.com/a/b/a/b/a", "max_repeated_segments"},
+		{"https://example.com/list?a=1&b=2&c=3", ""},
+		{"https://example.com/list?a=1&b=2&c=3&d=4", "max_query_params"},
+	}
+	for _, tt := range tests {
+		trap := d.Check(tt.url)
+		if tt.rule == "" && trap != "" {
+			t.Errorf("%s: expected no trap, got %q", tt.url, trap)
+		}
+		if tt.rule != "" && !strings.Contains(trap, tt.rule) {
+			t.Errorf("%s: expected a %s trap, got %q", tt.url, tt.rule, trap)
+		}
+	}
+}
+
+func TestTrapDetectorQueryVariants(t *testing.T) {
+	d := NewTrapDetector(&config.CrawlConfig{MaxQueryVariants: 2})
+
+	for _, month := range []string{"2025-01", "2025-02"} {
+		if trap := d.Check("https://example.com/calendar?month=" + month); trap != "" {
+			t.Errorf("Expected month %s to pass, got %q", month, trap)
+		}
+	}
+	if trap := d.Check("https://example.com/calendar?month=2025-03"); !strings.Contains(trap, "max_query_variants") {
+		t.Errorf("Expected the third variant to be trapped, got %q", trap)
+	}
+
+	// Other paths and URLs without a query are counted separately
+	if trap := d.Check("https://example.com/events?month=2025-03"); trap != "" {
+		t.Errorf("Expected another path to pass, got %q", trap)
+	}
+	if trap := d.Check("https://example.com/calendar"); trap != "" {
+		t.Errorf("Expected the bare path to pass, got %q", trap)
+	}
+}
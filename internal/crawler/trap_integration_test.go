@@ -0,0 +1,47 @@
+package crawler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// A calendar that always links to the next month is cut off by
+// max_query_variants; the trapped month is recorded as a trap_detected skip.
+func TestCrawlStopsAtSpiderTrap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		month, _ := strconv.Atoi(r.URL.Query().Get("month"))
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprintf(w, `<a href="/calendar?month=%d">Next</a>`, month+1)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := baseCfg()
+	cfg.MaxQueryVariants = 3
+	cfg.SeedURLs = []string{server.URL + "/calendar?month=1"}
+	store := newStore(t)
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	for month, want := range map[int]string{1: "completed", 3: "completed", 4: "skipped", 5: ""} {
+		got, _ := statusOf(t, store, fmt.Sprintf("%s/calendar?month=%d", server.URL, month))
+		if got != want {
+			t.Errorf("month %d status = %q, want %q", month, got, want)
+		}
+	}
+}
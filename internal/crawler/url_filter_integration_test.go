@@ -0,0 +1,75 @@
+package crawler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage/memory"
+)
+
+// Go URL filters run in order after the patterns and any of them can reject a URL
+func TestURLFilters(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.RequestURI())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`<a href="/docs/a">A</a><a href="/docs/b?lang=de">B</a>` +
+				`<a href="/blog/x">X</a><a href="/admin/">Admin</a>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<title>` + r.URL.Path + `</title>`))
+	}))
+	t.Cleanup(site.Close)
+
+	var checked []string
+	cfg := baseCfg()
+	cfg.SeedURLs = []string{site.URL + "/"}
+	cfg.ExcludePatterns = []string{"/admin/"}
+	cfg.URLFilters = []config.URLFilter{
+		func(u *url.URL) bool {
+			mu.Lock()
+			checked = append(checked, u.Path)
+			mu.Unlock()
+			return strings.HasPrefix(u.Path, "/docs/")
+		},
+		func(u *url.URL) bool { return u.Query().Get("lang") != "de" },
+	}
+	store := memory.New()
+	c, err := crawler.NewCrawler(cfg, store)
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := strings.Join(requested, " "); got != "/ /docs/a" {
+		t.Errorf("Expected only / and /docs/a to be fetched, got %q", got)
+	}
+	for _, path := range checked {
+		if path == "/admin/" {
+			t.Error("URL excluded by a pattern was passed to the filters")
+		}
+	}
+	if _, ok := store.Page(site.URL + "/docs/a"); !ok {
+		t.Error("Expected /docs/a to be crawled")
+	}
+}
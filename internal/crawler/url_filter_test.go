@@ -2,6 +2,7 @@ package crawler
 
 import (
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/masahif/linktadoru/internal/config"
@@ -131,6 +132,7 @@ func TestShouldCrawlURL(t *testing.T) {
 					FollowExternalHosts: true, // Allow all hosts for legacy tests
 				},
 				allowedHosts: []string{}, // Empty list but external hosts allowed
+				patterns:     mustCompileURLPatterns(t, tt.includePatterns, tt.excludePatterns),
 			}
 
 			result := crawler.shouldCrawlURL(tt.url)
@@ -380,6 +382,7 @@ func TestShouldCrawlURLWithHostFiltering(t *testing.T) {
 			crawler := &DefaultCrawler{
 				config:       config,
 				allowedHosts: allowedHosts,
+				patterns:     mustCompileURLPatterns(t, tt.includePatterns, tt.excludePatterns),
 			}
 
 			result := crawler.shouldCrawlURL(tt.targetURL)
@@ -609,3 +612,250 @@ func TestConfigAllowedSchemes(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchesHostPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		host     string
+		expected bool
+	}{
+		{"example.com", "example.com", true},
+		{"example.com", "EXAMPLE.com", true},
+		{"example.com", "www.example.com", false},
+		{"*.example.com", "www.example.com", true},
+		{"*.example.com", "a.b.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", "badexample.com", false},
+		{"*.Example.COM", "docs.example.com", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchesHostPattern(tt.pattern, tt.host); got != tt.expected {
+			t.Errorf("matchesHostPattern(%q, %q) = %v, expected %v", tt.pattern, tt.host, got, tt.expected)
+		}
+	}
+}
+
+func TestShouldCrawlURLWithHostLists(t *testing.T) {
+	tests := []struct {
+		name            string
+		seedURLs        []string
+		followExternal  bool
+		allowedHosts    []string
+		blockedHosts    []string
+		includePatterns []string
+		targetURL       string
+		expected        bool
+	}{
+		{
+			name:         "Allowed host outside seed hosts",
+			seedURLs:     []string{"https://example.com"},
+			allowedHosts: []string{"example.com", "*.example.com"},
+			targetURL:    "https://docs.example.com/page",
+			expected:     true,
+		},
+		{
+			name:         "Seed host not in allowed hosts",
+			seedURLs:     []string{"https://example.com"},
+			allowedHosts: []string{"*.example.com"},
+			targetURL:    "https://example.com/page",
+			expected:     false,
+		},
+		{
+			name:           "Allowed hosts restrict follow_external_hosts",
+			seedURLs:       []string{"https://example.com"},
+			followExternal: true,
+			allowedHosts:   []string{"example.com"},
+			targetURL:      "https://other.com/page",
+			expected:       false,
+		},
+		{
+			name:         "Allowed host with port",
+			seedURLs:     []string{"https://example.com"},
+			allowedHosts: []string{"example.com"},
+			targetURL:    "https://example.com:8443/page",
+			expected:     true,
+		},
+		{
+			name:         "Blocked host wins over allowed host",
+			seedURLs:     []string{"https://example.com"},
+			allowedHosts: []string{"*.example.com"},
+			blockedHosts: []string{"ads.example.com"},
+			targetURL:    "https://ads.example.com/banner",
+			expected:     false,
+		},
+		{
+			name:           "Blocked wildcard with external hosts",
+			seedURLs:       []string{"https://example.com"},
+			followExternal: true,
+			blockedHosts:   []string{"*.tracker.net"},
+			targetURL:      "https://cdn.tracker.net/pixel",
+			expected:       false,
+		},
+		{
+			name:         "Blocked hosts keep seed-host scoping",
+			seedURLs:     []string{"https://example.com"},
+			blockedHosts: []string{"ads.example.com"},
+			targetURL:    "https://other.com/page",
+			expected:     false,
+		},
+		{
+			name:            "Blocked host evaluated before include patterns",
+			seedURLs:        []string{"https://example.com"},
+			blockedHosts:    []string{"example.com"},
+			includePatterns: []string{".*"},
+			targetURL:       "https://example.com/page",
+			expected:        false,
+		},
+		{
+			name:            "Include patterns still apply to allowed hosts",
+			seedURLs:        []string{"https://example.com"},
+			allowedHosts:    []string{"*.example.com"},
+			includePatterns: []string{"/blog/"},
+			targetURL:       "https://www.example.com/shop/item",
+			expected:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &config.CrawlConfig{
+				SeedURLs:            tt.seedURLs,
+				FollowExternalHosts: tt.followExternal,
+				AllowedHosts:        tt.allowedHosts,
+				BlockedHosts:        tt.blockedHosts,
+				IncludePatterns:     tt.includePatterns,
+			}
+
+			crawler := &DefaultCrawler{
+				config:       config,
+				allowedHosts: []string{"https://example.com"},
+				patterns:     mustCompileURLPatterns(t, tt.includePatterns, nil),
+			}
+
+			result := crawler.shouldCrawlURL(tt.targetURL)
+			if result != tt.expected {
+				t.Errorf("shouldCrawlURL(%s) = %v, expected %v", tt.targetURL, result, tt.expected)
+			}
+		})
+	}
+}
+
+// queueRecordingStorage records URLs added to the queue
+type queueRecordingStorage struct {
+	MockStorage
+	queued []string
+}
+
+func (s *queueRecordingStorage) AddToQueue(urls []string) error {
+	s.queued = append(s.queued, urls...)
+	return nil
+}
+
+func TestProcessNewURLsFollowsAllowedHosts(t *testing.T) {
+	store := &queueRecordingStorage{}
+	crawler := &DefaultCrawler{
+		config: &config.CrawlConfig{
+			AllowedHosts: []string{"example.com", "*.example.com"},
+		},
+		storage:      store,
+		allowedHosts: []string{"https://example.com"},
+	}
+
+	links := []*LinkData{
+		{TargetURL: "https://example.com/about", LinkType: "internal"},
+		{TargetURL: "https://docs.example.com/guide", LinkType: "external"},
+		{TargetURL: "https://other.com/page", LinkType: "external"},
+	}
+	crawler.processNewURLs(1, links, "https://example.com/")
+
+	expected := []string{"https://example.com/about", "https://docs.example.com/guide"}
+	if len(store.queued) != len(expected) {
+		t.Fatalf("queued %v, expected %v", store.queued, expected)
+	}
+	for i, u := range expected {
+		if store.queued[i] != u {
+			t.Errorf("queued[%d] = %s, expected %s", i, store.queued[i], u)
+		}
+	}
+}
+
+func TestIsAllowedHostWithSubdomains(t *testing.T) {
+	tests := []struct {
+		name      string
+		seedHosts []string
+		targetURL string
+		expected  bool
+	}{
+		{"seed host", []string{"https://example.com"}, "https://example.com/page", true},
+		{"www subdomain", []string{"https://example.com"}, "https://www.example.com/page", true},
+		{"nested subdomain", []string{"https://example.com"}, "https://a.b.example.com/", true},
+		{"uppercase subdomain", []string{"https://example.com"}, "https://BLOG.example.com/", true},
+		{"www seed admits apex", []string{"https://www.example.com"}, "https://example.com/", true},
+		{"www seed admits sibling", []string{"https://www.example.com"}, "https://blog.example.com/", true},
+		{"lookalike domain", []string{"https://example.com"}, "https://badexample.com/", false},
+		{"suffix domain", []string{"https://example.com"}, "https://example.com.evil.net/", false},
+		{"different scheme", []string{"https://example.com"}, "http://www.example.com/", false},
+		{"different port", []string{"https://example.com"}, "https://www.example.com:8443/", false},
+		{"parent domain", []string{"https://blog.example.com"}, "https://example.com/", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crawler := &DefaultCrawler{
+				config:       &config.CrawlConfig{IncludeSubdomains: true},
+				allowedHosts: tt.seedHosts,
+			}
+			if got := crawler.isAllowedHost(tt.targetURL); got != tt.expected {
+				t.Errorf("isAllowedHost(%s) = %v, expected %v", tt.targetURL, got, tt.expected)
+			}
+		})
+	}
+
+	// Without the option, www. variants stay out of scope
+	crawler := &DefaultCrawler{
+		config:       &config.CrawlConfig{},
+		allowedHosts: []string{"https://example.com"},
+	}
+	if crawler.isAllowedHost("https://www.example.com/") {
+		t.Error("Expected www subdomain to be rejected without include_subdomains")
+	}
+}
+
+func TestProcessNewURLsFollowsSubdomains(t *testing.T) {
+	store := &queueRecordingStorage{}
+	crawler := &DefaultCrawler{
+		config:       &config.CrawlConfig{IncludeSubdomains: true},
+		storage:      store,
+		allowedHosts: []string{"https://example.com"},
+	}
+
+	links := []*LinkData{
+		{TargetURL: "https://www.example.com/", LinkType: "external"},
+		{TargetURL: "https://other.com/", LinkType: "external"},
+	}
+	crawler.processNewURLs(1, links, "https://example.com/")
+
+	if len(store.queued) != 1 || store.queued[0] != "https://www.example.com/" {
+		t.Errorf("queued %v, expected only the www subdomain", store.queued)
+	}
+}
+
+// mustCompileURLPatterns compiles include/exclude patterns as NewCrawler does
+func mustCompileURLPatterns(t *testing.T, include, exclude []string) *urlPatterns {
+	t.Helper()
+	patterns, err := compileURLPatterns(include, exclude)
+	if err != nil {
+		t.Fatalf("Failed to compile patterns: %v", err)
+	}
+	return patterns
+}
+
+func TestNewCrawlerRejectsInvalidPattern(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SeedURLs = []string{"https://example.com/"}
+	cfg.IncludePatterns = []string{"[unclosed"}
+	if _, err := NewCrawler(cfg, nil); err == nil || !strings.Contains(err.Error(), "invalid include pattern") {
+		t.Errorf("Expected an invalid include pattern error, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+// URLNormalizer rewrites discovered URLs so that variants of the same page,
+// such as links carrying tracking parameters or linking both /docs and
+// /docs/, share one pages row and are fetched once. The rewritten URL is the
+// form that is queued and stored.
+//
+// A query parameter is removed when its name matches strip_query_params, or
+// when keep_query_params is set and the name matches none of its patterns.
+// keep_query_params wins over strip_query_params. Names are matched
+// case-insensitively against glob patterns ("utm_*").
+//
+// Paths ending in a directory_index file name lose it (/docs/index.html
+// becomes /docs/), and trailing_slash then adds or removes the final slash.
+type URLNormalizer struct {
+	strip          []string
+	keep           []string
+	directoryIndex map[string]bool
+	trailingSlash  string
+}
+
+// NewURLNormalizer validates the configured normalization rules. It returns
+// nil when no rules are configured.
+func NewURLNormalizer(cfg *config.CrawlConfig) (*URLNormalizer, error) {
+	trailingSlash := cfg.TrailingSlash
+	if trailingSlash == config.TrailingSlashKeep {
+		trailingSlash = ""
+	}
+	if len(cfg.StripQueryParams) == 0 && len(cfg.KeepQueryParams) == 0 &&
+		len(cfg.DirectoryIndex) == 0 && trailingSlash == "" {
+		return nil, nil
+	}
+
+	n := &URLNormalizer{trailingSlash: trailingSlash}
+	var err error
+	if n.strip, err = queryParamPatterns("strip_query_params", cfg.StripQueryParams); err != nil {
+		return nil, err
+	}
+	if n.keep, err = queryParamPatterns("keep_query_params", cfg.KeepQueryParams); err != nil {
+		return nil, err
+	}
+	if len(cfg.DirectoryIndex) > 0 {
+		n.directoryIndex = make(map[string]bool, len(cfg.DirectoryIndex))
+		for _, name := range cfg.DirectoryIndex {
+			if name = strings.TrimSpace(name); name != "" {
+				n.directoryIndex[name] = true
+			}
+		}
+	}
+	return n, nil
+}
+
+// queryParamPatterns lower-cases and validates glob patterns for parameter names
+func queryParamPatterns(option string, patterns []string) ([]string, error) {
+	normalized := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid %s pattern '%s': %w", option, pattern, err)
+		}
+		normalized = append(normalized, pattern)
+	}
+	return normalized, nil
+}
+
+// matchesAnyParam reports whether a lower-cased parameter name matches a pattern
+func matchesAnyParam(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// keepsParam reports whether a query parameter survives normalization
+func (n *URLNormalizer) keepsParam(name string) bool {
+	name = strings.ToLower(name)
+	if matchesAnyParam(n.keep, name) {
+		return true
+	}
+	return len(n.keep) == 0 && !matchesAnyParam(n.strip, name)
+}
+
+// NormalizeURL applies the configured rules to rawURL. URLs that fail to
+// parse, and URLs the rules do not change, are returned unchanged.
+func (n *URLNormalizer) NormalizeURL(rawURL string) string {
+	if n == nil {
+		return rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Opaque != "" {
+		return rawURL
+	}
+
+	queryChanged := n.normalizeQuery(u)
+	pathChanged := u.Host != "" && n.normalizePath(u)
+	if !queryChanged && !pathChanged {
+		return rawURL
+	}
+	return u.String()
+}
+
+// normalizeQuery removes the query parameters the rules drop, keeping the
+// order and encoding of the others. A URL left without parameters loses its "?".
+func (n *URLNormalizer) normalizeQuery(u *url.URL) bool {
+	if u.RawQuery == "" || (len(n.strip) == 0 && len(n.keep) == 0) {
+		return false
+	}
+
+	parts := strings.Split(u.RawQuery, "&")
+	kept := make([]string, 0, len(parts))
+	for _, part := range parts {
+		name, _, _ := strings.Cut(part, "=")
+		if decoded, err := url.QueryUnescape(name); err == nil {
+			name = decoded
+		}
+		if part == "" || !n.keepsParam(name) {
+			continue
+		}
+		kept = append(kept, part)
+	}
+	if len(kept) == len(parts) {
+		return false
+	}
+
+	u.RawQuery = strings.Join(kept, "&")
+	u.ForceQuery = false
+	return true
+}
+
+// normalizePath removes a directory index file name and applies the
+// trailing-slash rule, preserving the path's percent-encoding
+func (n *URLNormalizer) normalizePath(u *url.URL) bool {
+	original := u.EscapedPath()
+	escaped := original
+
+	slash := strings.LastIndex(escaped, "/")
+	dir, file := escaped[:slash+1], escaped[slash+1:]
+	if n.directoryIndex[file] {
+		escaped, file = dir, ""
+	}
+
+	switch n.trailingSlash {
+	case config.TrailingSlashAdd:
+		// Paths naming a file (/report.pdf) are left alone
+		if !strings.HasSuffix(escaped, "/") && !strings.Contains(file, ".") {
+			escaped += "/"
+		}
+	case config.TrailingSlashRemove:
+		// The root path keeps its slash
+		if len(escaped) > 1 && strings.HasSuffix(escaped, "/") {
+			escaped = strings.TrimSuffix(escaped, "/")
+		}
+	}
+
+	if escaped == original {
+		return false
+	}
+	unescaped, err := url.PathUnescape(escaped)
+	if err != nil {
+		return false
+	}
+	u.Path, u.RawPath = unescaped, escaped
+	return true
+}
+
+// Apply normalizes the link targets and canonical URL of a processed page
+// result in place, before they are queued or compared with the page URL
+func (n *URLNormalizer) Apply(result *PageResult) {
+	if n == nil || result == nil {
+		return
+	}
+
+	if page := result.Page; page != nil {
+		page.CanonicalURL = n.NormalizeURL(page.CanonicalURL)
+		for i := range page.Rels {
+			page.Rels[i].URL = n.NormalizeURL(page.Rels[i].URL)
+		}
+	}
+	for _, link := range result.Links {
+		link.TargetURL = n.NormalizeURL(link.TargetURL)
+	}
+}
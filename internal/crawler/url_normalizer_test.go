@@ -0,0 +1,122 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+func TestNewURLNormalizerNoRules(t *testing.T) {
+	n, err := NewURLNormalizer(&config.CrawlConfig{})
+	if err != nil || n != nil {
+		t.Errorf("Expected nil normalizer without rules, got %v (err %v)", n, err)
+	}
+	if got := n.NormalizeURL("https://example.com/?utm_source=x"); got != "https://example.com/?utm_source=x" {
+		t.Errorf("Expected a nil normalizer to leave URLs unchanged, got %q", got)
+	}
+
+	if _, err := NewURLNormalizer(&config.CrawlConfig{StripQueryParams: []string{"utm_["}}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestURLNormalizerQueryParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		strip    []string
+		keep     []string
+		input    string
+		expected string
+	}{
+		{"strip glob", []string{"utm_*", "FBCLID"}, nil,
+			"https://example.com/a?utm_source=news&id=7&UTM_Medium=mail&fbclid=abc", "https://example.com/a?id=7"},
+		{"all stripped", []string{"utm_*"}, nil,
+			"https://example.com/a?utm_source=news#top", "https://example.com/a#top"},
+		{"encoding kept", []string{"sessionid"}, nil,
+			"https://example.com/a?q=caf%C3%A9+au+lait&sessionid=1", "https://example.com/a?q=caf%C3%A9+au+lait"},
+		{"encoded name", []string{"utm_*"}, nil,
+			"https://example.com/a?utm%5Fsource=x&b=2", "https://example.com/a?b=2"},
+		{"no query", []string{"utm_*"}, nil, "https://example.com/a", "https://example.com/a"},
+		{"allowlist", nil, []string{"page"},
+			"https://example.com/list?sort=asc&page=2&view=grid", "https://example.com/list?page=2"},
+		{"allowlist wins", []string{"*"}, []string{"id"},
+			"https://example.com/a?id=1&ref=x", "https://example.com/a?id=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewURLNormalizer(&config.CrawlConfig{StripQueryParams: tt.strip, KeepQueryParams: tt.keep})
+			if err != nil {
+				t.Fatalf("Failed to create normalizer: %v", err)
+			}
+			if got := n.NormalizeURL(tt.input); got != tt.expected {
+				t.Errorf("NormalizeURL(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestURLNormalizerApply(t *testing.T) {
+	n, err := NewURLNormalizer(&config.CrawlConfig{StripQueryParams: []string{"utm_*"}})
+	if err != nil {
+		t.Fatalf("Failed to create normalizer: %v", err)
+	}
+
+	result := &PageResult{
+		Page: &PageData{
+			URL:          "https://example.com/a",
+			CanonicalURL: "https://example.com/a?utm_source=feed",
+			Rels:         []PageRel{{Rel: "canonical", URL: "https://example.com/a?utm_source=feed"}},
+		},
+		Links: []*LinkData{{SourceURL: "https://example.com/a?utm_source=x", TargetURL: "https://example.com/b?utm_campaign=y"}},
+	}
+	n.Apply(result)
+
+	if result.Page.CanonicalURL != "https://example.com/a" || result.Page.Rels[0].URL != "https://example.com/a" {
+		t.Errorf("Expected canonical URLs to be normalized, got %q and %q", result.Page.CanonicalURL, result.Page.Rels[0].URL)
+	}
+	if result.Links[0].TargetURL != "https://example.com/b" {
+		t.Errorf("Expected link target to be normalized, got %q", result.Links[0].TargetURL)
+	}
+	if result.Links[0].SourceURL != "https://example.com/a?utm_source=x" {
+		t.Errorf("Expected link source to be left untouched, got %q", result.Links[0].SourceURL)
+	}
+}
+
+func TestURLNormalizerPaths(t *testing.T) {
+	index := []string{"index.html", "index.php"}
+	tests := []struct {
+		name          string
+		trailingSlash string
+		index         []string
+		input         string
+		expected      string
+	}{
+		{"index dropped", config.TrailingSlashKeep, index, "https://example.com/docs/index.html?x=1", "https://example.com/docs/?x=1"},
+		{"index case-sensitive", config.TrailingSlashKeep, index, "https://example.com/docs/INDEX.HTML", "https://example.com/docs/INDEX.HTML"},
+		{"add", config.TrailingSlashAdd, nil, "https://example.com/docs", "https://example.com/docs/"},
+		{"add empty path", config.TrailingSlashAdd, nil, "https://example.com", "https://example.com/"},
+		{"add skips files", config.TrailingSlashAdd, nil, "https://example.com/report.pdf", "https://example.com/report.pdf"},
+		{"add after index", config.TrailingSlashAdd, index, "https://example.com/docs/index.php", "https://example.com/docs/"},
+		{"remove", config.TrailingSlashRemove, nil, "https://example.com/docs/#intro", "https://example.com/docs#intro"},
+		{"remove keeps root", config.TrailingSlashRemove, nil, "https://example.com/", "https://example.com/"},
+		{"remove after index", config.TrailingSlashRemove, index, "https://example.com/docs/index.html", "https://example.com/docs"},
+		{"encoding kept", config.TrailingSlashRemove, nil, "https://example.com/a%2Fb/caf%C3%A9/", "https://example.com/a%2Fb/caf%C3%A9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewURLNormalizer(&config.CrawlConfig{TrailingSlash: tt.trailingSlash, DirectoryIndex: tt.index})
+			if err != nil {
+				t.Fatalf("Failed to create normalizer: %v", err)
+			}
+			if got := n.NormalizeURL(tt.input); got != tt.expected {
+				t.Errorf("NormalizeURL(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	if n, _ := NewURLNormalizer(&config.CrawlConfig{TrailingSlash: config.TrailingSlashKeep}); n != nil {
+		t.Error("Expected nil normalizer for trailing_slash: keep alone")
+	}
+}
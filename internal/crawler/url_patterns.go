@@ -0,0 +1,62 @@
+package crawler
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// urlPatterns holds the compiled include_patterns and exclude_patterns, so
+// each pattern is compiled once per crawl rather than once per URL
+type urlPatterns struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// compileURLPatterns compiles the include and exclude patterns, failing on
+// the first invalid one
+func compileURLPatterns(include, exclude []string) (*urlPatterns, error) {
+	p := &urlPatterns{}
+	for _, pattern := range include {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern '%s': %w", pattern, err)
+		}
+		p.include = append(p.include, re)
+	}
+	for _, pattern := range exclude {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern '%s': %w", pattern, err)
+		}
+		p.exclude = append(p.exclude, re)
+	}
+	return p, nil
+}
+
+// allows reports whether a URL matches at least one include pattern (when
+// any are set) and no exclude pattern. A nil set allows every URL.
+func (p *urlPatterns) allows(urlStr string) bool {
+	if p == nil {
+		return true
+	}
+
+	if len(p.include) > 0 {
+		matched := false
+		for _, re := range p.include {
+			if re.MatchString(urlStr) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	for _, re := range p.exclude {
+		if re.MatchString(urlStr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,330 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+const (
+	// webhookTimeout bounds each webhook delivery
+	webhookTimeout = 10 * time.Second
+	// webhookMinPages is the number of pages fetched before the error rate
+	// is judged, so a failing first page does not count as a 100% error rate
+	webhookMinPages = 20
+	// webhookWorkers is the most deliveries sent at once
+	webhookWorkers = 2
+	// webhookQueueSize is the most url_failed deliveries waiting to be sent;
+	// further ones are dropped until the queue drains
+	webhookQueueSize = 100
+)
+
+// WebhookPayload is the JSON body POSTed to webhooks
+type WebhookPayload struct {
+	Event string    `json:"event"` // config.Webhook* event
+	Time  time.Time `json:"time"`
+	RunID string    `json:"run_id,omitempty"`
+	Text  string    `json:"text"` // One-line summary, shown by Slack incoming webhooks
+
+	// crawl_started
+	SeedURLs []string `json:"seed_urls,omitempty"`
+
+	// crawl_finished
+	PagesCrawled    int     `json:"pages_crawled,omitempty"`
+	Errors          int     `json:"errors,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+	StopReason      string  `json:"stop_reason,omitempty"`
+	RemainingQueued int     `json:"remaining_queued,omitempty"`
+
+	// error_rate
+	ErrorRate    float64 `json:"error_rate,omitempty"` // Percent of fetched pages that failed
+	Threshold    float64 `json:"threshold,omitempty"`
+	PagesFetched int64   `json:"pages_fetched,omitempty"`
+	PagesFailed  int64   `json:"pages_failed,omitempty"`
+
+	// url_failed
+	URL          string `json:"url,omitempty"`
+	StatusCode   int    `json:"status_code,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// webhook is a configured webhook with its patterns compiled
+type webhook struct {
+	url       string
+	host      string          // Logged instead of url, which may embed a secret token
+	events    map[string]bool // nil sends every event
+	errorRate float64
+	patterns  []*regexp.Regexp
+	headers   map[string]string
+	rateSent  atomic.Bool // error_rate is sent once per run
+}
+
+// webhookDelivery is a payload waiting to be POSTed to a webhook
+type webhookDelivery struct {
+	hook  *webhook
+	event string
+	body  []byte
+}
+
+// webhookNotifier POSTs crawl events to the configured webhooks. Deliveries
+// are queued and sent in the background by up to webhookWorkers goroutines,
+// which exit once the queue is empty; wait blocks until they are done.
+type webhookNotifier struct {
+	hooks     []*webhook
+	client    *http.Client
+	userAgent string
+	runID     string
+	wg        sync.WaitGroup // Queued and running deliveries
+
+	mu      sync.Mutex
+	queue   []webhookDelivery
+	running int          // Delivery goroutines
+	dropped atomic.Int64 // url_failed deliveries dropped by a full queue
+
+	// Pages fetched and failed during this run, for error_rate
+	fetched atomic.Int64
+	failed  atomic.Int64
+}
+
+// newWebhookNotifier compiles the webhooks of cfg. It returns nil when none
+// are configured.
+func newWebhookNotifier(cfg *config.CrawlConfig, runID string) (*webhookNotifier, error) {
+	if len(cfg.Webhooks) == 0 {
+		return nil, nil
+	}
+	n := &webhookNotifier{
+		client:    &http.Client{Timeout: webhookTimeout},
+		userAgent: cfg.UserAgent,
+		runID:     runID,
+	}
+	for _, hook := range cfg.Webhooks {
+		w := &webhook{url: hook.URL, errorRate: hook.ErrorRate, headers: map[string]string{}}
+		if u, err := neturl.Parse(hook.URL); err == nil {
+			w.host = u.Host
+		}
+		if len(hook.Events) > 0 {
+			w.events = map[string]bool{}
+			for _, event := range hook.Events {
+				w.events[event] = true
+			}
+		}
+		for _, pattern := range hook.URLPatterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid webhook url pattern '%s': %w", pattern, err)
+			}
+			w.patterns = append(w.patterns, re)
+		}
+		for _, header := range hook.Headers {
+			name, value, _ := strings.Cut(header, ":")
+			w.headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+		n.hooks = append(n.hooks, w)
+	}
+	return n, nil
+}
+
+// wants reports whether the webhook is sent event
+func (w *webhook) wants(event string) bool {
+	return w.events == nil || w.events[event]
+}
+
+// handle sends the webhook events matching a crawl event
+func (n *webhookNotifier) handle(event Event) {
+	switch e := event.(type) {
+	case CrawlStarted:
+		n.crawlStarted(e.SeedURLs)
+	case PageCompleted:
+		n.pageFetched(e.URL, e.Page.StatusCode, "")
+	case PageErrored:
+		n.pageFetched(e.URL, 0, e.Error.ErrorMessage)
+	case CrawlFinished:
+		n.crawlFinished(e.Stats)
+	}
+}
+
+// crawlStarted sends crawl_started
+func (n *webhookNotifier) crawlStarted(seedURLs []string) {
+	if n == nil {
+		return
+	}
+	text := "Crawl started, resuming the queue"
+	if len(seedURLs) > 0 {
+		text = fmt.Sprintf("Crawl started from %s", strings.Join(seedURLs, ", "))
+	}
+	n.send(func(*webhook) bool { return true }, WebhookPayload{
+		Event:    config.WebhookCrawlStarted,
+		Text:     text,
+		SeedURLs: seedURLs,
+	})
+}
+
+// crawlFinished sends crawl_finished with the totals of stats
+func (n *webhookNotifier) crawlFinished(stats CrawlStats) {
+	if n == nil {
+		return
+	}
+	n.send(func(*webhook) bool { return true }, WebhookPayload{
+		Event: config.WebhookCrawlFinished,
+		Text: fmt.Sprintf("Crawl finished (%s): %d pages crawled, %d errors, %d URLs left in the queue",
+			stats.StopReason, stats.PagesCrawled, stats.ErrorCount, stats.RemainingQueued),
+		PagesCrawled:    stats.PagesCrawled,
+		Errors:          stats.ErrorCount,
+		DurationSeconds: stats.Duration.Seconds(),
+		StopReason:      stats.StopReason,
+		RemainingQueued: stats.RemainingQueued,
+	})
+}
+
+// pageFetched records a fetched page, sending url_failed when it failed -
+// a fetch error or an HTTP status of 400 or above - and error_rate when the
+// failures first exceed a webhook's threshold
+func (n *webhookNotifier) pageFetched(url string, statusCode int, errMsg string) {
+	if n == nil {
+		return
+	}
+	fetched := n.fetched.Add(1)
+	if errMsg == "" && statusCode < 400 {
+		return
+	}
+	failed := n.failed.Add(1)
+
+	text := fmt.Sprintf("Page failed: %s (HTTP %d)", url, statusCode)
+	if errMsg != "" {
+		text = fmt.Sprintf("Page failed: %s (%s)", url, errMsg)
+	}
+	n.send(func(w *webhook) bool {
+		for _, re := range w.patterns {
+			if re.MatchString(url) {
+				return true
+			}
+		}
+		return false
+	}, WebhookPayload{
+		Event:        config.WebhookURLFailed,
+		Text:         text,
+		URL:          url,
+		StatusCode:   statusCode,
+		ErrorMessage: errMsg,
+	})
+
+	if fetched < webhookMinPages {
+		return
+	}
+	rate := 100 * float64(failed) / float64(fetched)
+	for _, w := range n.hooks {
+		if w.errorRate <= 0 || rate <= w.errorRate || !w.wants(config.WebhookErrorRate) || w.rateSent.Swap(true) {
+			continue
+		}
+		n.deliver(w, WebhookPayload{
+			Event:        config.WebhookErrorRate,
+			Text:         fmt.Sprintf("Error rate %.1f%% is above %g%%: %d of %d pages failed", rate, w.errorRate, failed, fetched),
+			ErrorRate:    rate,
+			Threshold:    w.errorRate,
+			PagesFetched: fetched,
+			PagesFailed:  failed,
+		})
+	}
+}
+
+// send delivers payload to every webhook that wants its event and passes
+// match
+func (n *webhookNotifier) send(match func(*webhook) bool, payload WebhookPayload) {
+	for _, w := range n.hooks {
+		if w.wants(payload.Event) && match(w) {
+			n.deliver(w, payload)
+		}
+	}
+}
+
+// deliver queues payload for w. url_failed deliveries are dropped while
+// webhookQueueSize of them wait already, so a burst of failing pages cannot
+// pile up requests; the other events are a handful per run and always sent.
+func (n *webhookNotifier) deliver(w *webhook, payload WebhookPayload) {
+	payload.Time = time.Now().UTC()
+	payload.RunID = n.runID
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("Failed to encode webhook payload", "event", payload.Event, "error", err)
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if payload.Event == config.WebhookURLFailed && len(n.queue) >= webhookQueueSize {
+		n.dropped.Add(1)
+		return
+	}
+	n.queue = append(n.queue, webhookDelivery{hook: w, event: payload.Event, body: body})
+	n.wg.Add(1)
+	if n.running < webhookWorkers {
+		n.running++
+		go n.run()
+	}
+}
+
+// run sends queued deliveries until the queue is empty
+func (n *webhookNotifier) run() {
+	for {
+		n.mu.Lock()
+		if len(n.queue) == 0 {
+			n.running--
+			n.mu.Unlock()
+			return
+		}
+		d := n.queue[0]
+		n.queue = n.queue[1:]
+		n.mu.Unlock()
+
+		n.post(d)
+		n.wg.Done()
+	}
+}
+
+// post sends one delivery. A failed delivery is logged and not retried.
+func (n *webhookNotifier) post(d webhookDelivery) {
+	// Deliveries outlive the crawl's context, so crawl_finished is sent
+	// after an interrupted run too
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, d.hook.url, bytes.NewReader(d.body))
+	if err != nil {
+		slog.Error("Failed to create webhook request", "webhook", d.hook.host, "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", n.userAgent)
+	for name, value := range d.hook.headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		slog.Warn("Webhook delivery failed", "webhook", d.hook.host, "event", d.event, "error", err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		slog.Warn("Webhook rejected event", "webhook", d.hook.host, "event", d.event, "status", resp.StatusCode)
+	}
+}
+
+// wait blocks until every queued delivery has finished or timed out, and
+// logs how many url_failed events a full queue dropped
+func (n *webhookNotifier) wait() {
+	if n == nil {
+		return
+	}
+	n.wg.Wait()
+	if dropped := n.dropped.Swap(0); dropped > 0 {
+		slog.Warn("Webhook queue was full, url_failed events dropped", "dropped", dropped)
+	}
+}
@@ -0,0 +1,90 @@
+package crawler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage/memory"
+)
+
+// Webhooks receive the crawl lifecycle and the failures of matching URLs
+func TestWebhooks(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<a href="/missing">Missing</a><a href="/gone">Gone</a>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(site.Close)
+
+	var mu sync.Mutex
+	var received []crawler.WebhookPayload
+	var auth []string
+	hooks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload crawler.WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Failed to decode webhook: %v", err)
+		}
+		mu.Lock()
+		received = append(received, payload)
+		auth = append(auth, r.Header.Get("Authorization"))
+		mu.Unlock()
+	}))
+	t.Cleanup(hooks.Close)
+
+	cfg := baseCfg()
+	cfg.SeedURLs = []string{site.URL + "/"}
+	cfg.Webhooks = []config.Webhook{{
+		URL:         hooks.URL,
+		URLPatterns: []string{"/missing$"},
+		Headers:     []string{"Authorization: Bearer secret"},
+	}}
+	c, err := crawler.NewCrawler(cfg, memory.New())
+	if err != nil {
+		t.Fatalf("NewCrawler: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Start(ctx, cfg.SeedURLs); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// Start waits for the deliveries
+	mu.Lock()
+	defer mu.Unlock()
+	events := map[string]crawler.WebhookPayload{}
+	for _, payload := range received {
+		if _, dup := events[payload.Event]; dup {
+			t.Errorf("Event %q sent twice", payload.Event)
+		}
+		events[payload.Event] = payload
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected crawl_started, url_failed and crawl_finished, got %+v", received)
+	}
+	if started := events[config.WebhookCrawlStarted]; len(started.SeedURLs) != 1 {
+		t.Errorf("Unexpected crawl_started: %+v", started)
+	}
+	if failed := events[config.WebhookURLFailed]; failed.URL != site.URL+"/missing" || failed.StatusCode != 404 {
+		t.Errorf("Unexpected url_failed: %+v", failed)
+	}
+	if finished := events[config.WebhookCrawlFinished]; finished.StopReason != crawler.StopReasonCompleted || finished.PagesCrawled != 3 || finished.Text == "" {
+		t.Errorf("Unexpected crawl_finished: %+v", finished)
+	}
+	for _, header := range auth {
+		if header != "Bearer secret" {
+			t.Errorf("Authorization = %q", header)
+		}
+	}
+}
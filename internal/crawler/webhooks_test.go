@@ -0,0 +1,122 @@
+package crawler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/masahif/linktadoru/internal/config"
+)
+
+func TestWebhookErrorRate(t *testing.T) {
+	var mu sync.Mutex
+	var received []WebhookPayload
+	hooks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		received = append(received, payload)
+		mu.Unlock()
+	}))
+	defer hooks.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Webhooks = []config.Webhook{
+		{URL: hooks.URL, Events: []string{config.WebhookErrorRate}, ErrorRate: 10},
+		{URL: hooks.URL, Events: []string{config.WebhookErrorRate}, ErrorRate: 50},
+	}
+	n, err := newWebhookNotifier(cfg, "run")
+	if err != nil {
+		t.Fatalf("newWebhookNotifier failed: %v", err)
+	}
+
+	// Failures before webhookMinPages are not judged yet
+	for i := 0; i < 3; i++ {
+		n.pageFetched("https://example.com/broken", 500, "")
+	}
+	for i := 0; i < webhookMinPages; i++ {
+		n.pageFetched("https://example.com/", 200, "")
+	}
+	n.pageFetched("https://example.com/down", 0, "connection refused")
+	n.pageFetched("https://example.com/down", 0, "connection refused")
+	n.wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 {
+		t.Fatalf("Expected one error_rate event, got %+v", received)
+	}
+	got := received[0]
+	if got.Event != config.WebhookErrorRate || got.Threshold != 10 || got.PagesFetched != 24 || got.PagesFailed != 4 || got.RunID != "run" {
+		t.Errorf("Unexpected error_rate event: %+v", got)
+	}
+}
+
+func TestWebhookDeliveriesBounded(t *testing.T) {
+	release := make(chan struct{})
+	var inFlight, maxInFlight, received atomic.Int32
+	var finished atomic.Bool
+	hooks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		<-release
+		var payload WebhookPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		finished.CompareAndSwap(false, payload.Event == config.WebhookCrawlFinished)
+		received.Add(1)
+	}))
+	defer hooks.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Webhooks = []config.Webhook{{URL: hooks.URL, URLPatterns: []string{"example"}}}
+	n, err := newWebhookNotifier(cfg, "run")
+	if err != nil {
+		t.Fatalf("newWebhookNotifier failed: %v", err)
+	}
+
+	// The hook stalls, so the queue fills and further url_failed events are dropped
+	const failures = 3 * webhookQueueSize
+	for i := 0; i < failures; i++ {
+		n.pageFetched("https://example.com/down", 500, "")
+	}
+	n.crawlFinished(CrawlStats{StopReason: "completed"})
+	if dropped := n.dropped.Load(); dropped == 0 {
+		t.Error("Expected url_failed events to be dropped while the queue is full")
+	}
+	close(release)
+	n.wait()
+
+	if got := maxInFlight.Load(); got > webhookWorkers {
+		t.Errorf("Expected at most %d deliveries at once, got %d", webhookWorkers, got)
+	}
+	// Everything queued is delivered, and crawl_finished is never dropped
+	if got := received.Load(); got > webhookQueueSize+webhookWorkers+1 || got < webhookQueueSize {
+		t.Errorf("Unexpected number of deliveries: %d", got)
+	}
+	if !finished.Load() {
+		t.Error("Expected crawl_finished to be delivered despite the full queue")
+	}
+	if n.dropped.Load() != 0 {
+		t.Error("wait should report and reset the dropped count")
+	}
+}
+
+func TestWebhookNotifierDisabled(t *testing.T) {
+	n, err := newWebhookNotifier(config.DefaultConfig(), "")
+	if err != nil || n != nil {
+		t.Fatalf("newWebhookNotifier = %v, %v; want nil", n, err)
+	}
+	// A nil notifier ignores events
+	n.crawlStarted(nil)
+	n.pageFetched("https://example.com/", 500, "")
+	n.wait()
+}
@@ -15,6 +15,7 @@ type Config struct {
 	MaxSize    int64 // MB
 	MaxBackups int
 	Console    bool
+	Output     io.Writer // Console destination (default os.Stdout)
 }
 
 // DefaultConfig returns the default logging configuration
@@ -50,7 +51,11 @@ func NewLogger(config Config) (*slog.Logger, error) {
 
 	// Console output
 	if config.Console {
-		writers = append(writers, os.Stdout)
+		if config.Output != nil {
+			writers = append(writers, config.Output)
+		} else {
+			writers = append(writers, os.Stdout)
+		}
 	}
 
 	// File output with rotation
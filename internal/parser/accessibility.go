@@ -0,0 +1,188 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Accessibility issues found in static HTML
+const (
+	AccessibilityMissingAlt   = "missing_alt"   // <img> without an alt attribute
+	AccessibilityMissingLabel = "missing_label" // Form control without a label or ARIA name
+	AccessibilityEmptyLink    = "empty_link"    // Link without text or another accessible name
+	AccessibilityEmptyButton  = "empty_button"  // Button without text or another accessible name
+	AccessibilityMissingLang  = "missing_lang"  // <html> without a lang attribute
+	AccessibilityHeadingJump  = "heading_jump"  // Heading more than one level below the previous one
+)
+
+// AccessibilityIssue is an accessibility problem of one element, in
+// document order except for form controls, whose labels are only known once
+// the whole document was read
+type AccessibilityIssue struct {
+	Issue   string // One of the Accessibility constants
+	Element string // Short description of the element, e.g. <input type="email" name="q">
+}
+
+// maxDescribedValue bounds the attribute values quoted in an issue
+const maxDescribedValue = 100
+
+// accessibleName tracks whether an open link or button has a name other
+// than its text: an aria-label, aria-labelledby or title attribute, or an
+// image with alt text inside it
+type accessibleName struct {
+	issue   string // Reported when the element closes without a name
+	element string
+	named   bool
+}
+
+// unlabeledControl is a form control whose only possible label is a
+// <label for> naming its id
+type unlabeledControl struct {
+	id      string
+	element string
+}
+
+// accessibilityScan collects the accessibility issues of a document
+type accessibilityScan struct {
+	issues    []AccessibilityIssue
+	labelFor  map[string]bool // ids named by <label for>
+	controls  []unlabeledControl
+	htmlSeen  bool
+	hasLang   bool
+	lastLevel int // Level of the previous heading; 0 before the first
+}
+
+// startTag checks an element as it opens; s is the scanner holding the
+// stack of open elements, e the entry about to be pushed
+func (a *accessibilityScan) startTag(s *documentScanner, name string, attrs []html.Attribute, e *openElement) {
+	switch {
+	case name == "html" && !a.htmlSeen:
+		a.htmlSeen = true
+		lang := attrText(attrs, "lang")
+		if lang == "" {
+			lang = attrText(attrs, "xml:lang")
+		}
+		a.hasLang = lang != ""
+		s.result.Lang = lang
+	case name == "img":
+		if _, ok := attr(attrs, "alt"); !ok {
+			a.report(AccessibilityMissingAlt, describe(name, attrs, "src"))
+		} else if attrValue(attrs, "alt") != "" {
+			s.nameOpenElements()
+		}
+	case name == "a":
+		if _, ok := attr(attrs, "href"); ok {
+			e.accessible = &accessibleName{issue: AccessibilityEmptyLink, element: describe(name, attrs, "href"), named: hasARIAName(attrs)}
+		}
+	case name == "button":
+		e.accessible = &accessibleName{issue: AccessibilityEmptyButton, element: describe(name, attrs, "id", "name", "type"), named: hasARIAName(attrs)}
+	case name == "label":
+		if id := attrText(attrs, "for"); id != "" {
+			if a.labelFor == nil {
+				a.labelFor = make(map[string]bool)
+			}
+			a.labelFor[id] = true
+		}
+	case name == "input" || name == "select" || name == "textarea":
+		a.control(s, name, attrs)
+	case isHeading(name):
+		level := int(name[1] - '0')
+		if a.lastLevel > 0 && level > a.lastLevel+1 {
+			a.report(AccessibilityHeadingJump, fmt.Sprintf("<%s> after <h%d>", name, a.lastLevel))
+		}
+		a.lastLevel = level
+	}
+}
+
+// control checks that a form control has a label
+func (a *accessibilityScan) control(s *documentScanner, name string, attrs []html.Attribute) {
+	element := describe(name, attrs, "type", "id", "name")
+	if name == "input" {
+		switch attrValue(attrs, "type") {
+		case "hidden", "submit", "reset":
+			return // Not shown, or labeled by the browser
+		case "button":
+			if attrValue(attrs, "value") == "" && !hasARIAName(attrs) {
+				a.report(AccessibilityEmptyButton, element)
+			}
+			return
+		case "image":
+			if attrValue(attrs, "alt") == "" && !hasARIAName(attrs) {
+				a.report(AccessibilityEmptyButton, element)
+			}
+			return
+		}
+	}
+	if hasARIAName(attrs) || s.lastOpen(func(n string) bool { return n == "label" }) >= 0 {
+		return
+	}
+	a.controls = append(a.controls, unlabeledControl{id: attrText(attrs, "id"), element: element})
+}
+
+// endElement reports a link or button closing without a name
+func (a *accessibilityScan) endElement(e openElement) {
+	if e.accessible != nil && !e.accessible.named && (e.text == nil || e.text.String() == "") {
+		a.report(e.accessible.issue, e.accessible.element)
+	}
+}
+
+// finish reports the document-level issues and the form controls no
+// <label for> names, and returns all issues
+func (a *accessibilityScan) finish() []AccessibilityIssue {
+	for _, c := range a.controls {
+		if c.id == "" || !a.labelFor[c.id] {
+			a.report(AccessibilityMissingLabel, c.element)
+		}
+	}
+	if !a.hasLang {
+		a.report(AccessibilityMissingLang, "<html>")
+	}
+	return a.issues
+}
+
+func (a *accessibilityScan) report(issue, element string) {
+	a.issues = append(a.issues, AccessibilityIssue{Issue: issue, Element: element})
+}
+
+// nameOpenElements marks the open links and buttons as named, for an image
+// with alt text inside them
+func (s *documentScanner) nameOpenElements() {
+	for i := range s.stack {
+		if s.stack[i].accessible != nil {
+			s.stack[i].accessible.named = true
+		}
+	}
+}
+
+// hasARIAName reports whether an element is named by aria-label,
+// aria-labelledby or title
+func hasARIAName(attrs []html.Attribute) bool {
+	return attrValue(attrs, "aria-label") != "" || attrValue(attrs, "aria-labelledby") != "" || attrValue(attrs, "title") != ""
+}
+
+// attrText returns the whitespace-trimmed value of attribute key, case kept
+func attrText(attrs []html.Attribute, key string) string {
+	value, _ := attr(attrs, key)
+	return strings.TrimSpace(value)
+}
+
+// describe renders an element's start tag with the given attributes, those
+// present only, for reporting
+func describe(name string, attrs []html.Attribute, keys ...string) string {
+	var b strings.Builder
+	b.WriteString("<" + name)
+	for _, key := range keys {
+		value, ok := attr(attrs, key)
+		if !ok {
+			continue
+		}
+		if len(value) > maxDescribedValue {
+			value = value[:maxDescribedValue] + "..."
+		}
+		fmt.Fprintf(&b, " %s=%q", key, value)
+	}
+	b.WriteString(">")
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAccessibilityIssues(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []AccessibilityIssue
+	}{
+		{
+			name: "accessible page",
+			html: `<html lang="en"><body>
+				<h1>Title</h1><h2>Section</h2><h3>Sub</h3><h2>Next</h2>
+				<img src="/logo.png" alt="Logo"><img src="/spacer.gif" alt="">
+				<a href="/a">Text</a><a href="/b"><img src="/i.png" alt="Icon"></a><a href="/c" aria-label="Close"></a>
+				<a name="anchor"></a>
+				<button>Save</button><button title="Menu"><svg></svg></button>
+				<form><label>Name <input name="n"></label>
+				<label for="email">Email</label><input id="email" type="email">
+				<input type="search" aria-label="Search"><input type="hidden" name="t">
+				<input type="submit"><input type="button" value="Go">
+				<select title="Sort"></select></form>
+			</body></html>`,
+			want: nil,
+		},
+		{
+			name: "issues",
+			html: `<html><body>
+				<h1>Title</h1><h3>Jumped</h3>
+				<img src="/photo.jpg">
+				<a href="/empty"></a><a href="/icon"><i class="fa fa-x"></i></a>
+				<button type="button"> </button><input type="image" src="/go.png">
+				<textarea name="comment"></textarea>
+				<label for="other">Other</label><input type="text" id="q" placeholder="Search">
+			</body></html>`,
+			want: []AccessibilityIssue{
+				{Issue: AccessibilityHeadingJump, Element: "<h3> after <h1>"},
+				{Issue: AccessibilityMissingAlt, Element: `<img src="/photo.jpg">`},
+				{Issue: AccessibilityEmptyLink, Element: `<a href="/empty">`},
+				{Issue: AccessibilityEmptyLink, Element: `<a href="/icon">`},
+				{Issue: AccessibilityEmptyButton, Element: `<button type="button">`},
+				{Issue: AccessibilityEmptyButton, Element: `<input type="image">`},
+				{Issue: AccessibilityMissingLabel, Element: `<textarea name="comment">`},
+				{Issue: AccessibilityMissingLabel, Element: `<input type="text" id="q">`},
+				{Issue: AccessibilityMissingLang, Element: "<html>"},
+			},
+		},
+		{
+			name: "empty lang",
+			html: `<html lang=" "><body><p>Text</p></body></html>`,
+			want: []AccessibilityIssue{{Issue: AccessibilityMissingLang, Element: "<html>"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewHTMLParser("https://example.com/")
+			if err != nil {
+				t.Fatalf("Failed to create parser: %v", err)
+			}
+			result, err := parser.Parse([]byte(tt.html))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if !reflect.DeepEqual(result.Accessibility, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, result.Accessibility)
+			}
+		})
+	}
+}
+
+func TestLang(t *testing.T) {
+	parser, err := NewHTMLParser("https://example.com/")
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	result, err := parser.Parse([]byte(`<html lang=" en-US "><body></body></html>`))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	if result.Lang != "en-US" {
+		t.Errorf("Expected lang en-US, got %q", result.Lang)
+	}
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Static resource kinds recorded for asset references
+const (
+	AssetKindStylesheet = "stylesheet" // <link rel="stylesheet">
+	AssetKindScript     = "script"     // <script src>
+	AssetKindImage      = "image"      // <img src>
+	AssetKindIcon       = "icon"       // <link rel="icon">, "shortcut icon" or "apple-touch-icon"
+)
+
+// Asset is a static resource referenced by a page, in document order
+type Asset struct {
+	URL        string // Absolute URL
+	Kind       string // One of the AssetKind constants
+	IsExternal bool   // Hosted on a different host than the page
+}
+
+// assetKindForRel returns the asset kind of a <link> element's lower-cased
+// rel attribute, or "" when the element does not load a resource
+func assetKindForRel(rel string) string {
+	switch {
+	case hasRelToken(rel, "stylesheet"):
+		return AssetKindStylesheet
+	case hasRelToken(rel, "icon"), hasRelToken(rel, "apple-touch-icon"):
+		return AssetKindIcon
+	default:
+		return ""
+	}
+}
+
+// parseScript records the external script referenced by a <script src> element
+func (p *HTMLParser) parseScript(attrs []html.Attribute, result *ParseResult) {
+	for _, attr := range attrs {
+		if attr.Key == "src" {
+			p.addAsset(attr.Val, AssetKindScript, result)
+			return
+		}
+	}
+}
+
+// addAsset resolves href against the page and records it as an asset. Inline
+// data: URIs and other schemes outside the allowed set are skipped.
+func (p *HTMLParser) addAsset(href, kind string, result *ParseResult) {
+	href = strings.TrimSpace(href)
+	if href == "" || !p.isAllowedScheme(href) {
+		return
+	}
+	absURL, err := p.resolveURL(href)
+	if err != nil || !p.isAllowedScheme(absURL) {
+		return
+	}
+	parsedURL, err := url.Parse(absURL)
+	if err != nil {
+		return
+	}
+	result.Assets = append(result.Assets, Asset{
+		URL:        absURL,
+		Kind:       kind,
+		IsExternal: parsedURL.Host != p.baseURL.Host,
+	})
+}
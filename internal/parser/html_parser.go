@@ -3,9 +3,14 @@
 package parser
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"errors"
 	"fmt"
+	"hash"
+	"io"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/html"
@@ -14,17 +19,27 @@ import (
 // HTMLParser extracts metadata and links from HTML
 type HTMLParser struct {
 	baseURL        *url.URL
+	documentBase   *url.URL // From the document's <base href>; nil resolves against baseURL
 	allowedSchemes []string
 }
 
 // ParseResult contains the parsed HTML data
 type ParseResult struct {
-	Title        string
-	MetaDesc     string
-	MetaRobots   string
-	CanonicalURL string
-	ContentHash  string
-	Links        []Link
+	Title          string
+	MetaDesc       string
+	MetaRobots     string
+	CanonicalURL   string
+	ContentHash    string
+	Text           TextStats
+	Headings       []Heading
+	Rels           []Rel // rel="next"/"prev" <link> elements
+	Links          []Link
+	Alternates     []Alternate
+	StructuredData []StructuredData // JSON-LD blocks and top-level microdata items
+	Images         []Image
+	Assets         []Asset // Stylesheets, scripts, images and icons the page loads
+	Lang           string  // lang attribute of <html>, whitespace-trimmed
+	Accessibility  []AccessibilityIssue
 }
 
 // Link represents a parsed link
@@ -35,6 +50,49 @@ type Link struct {
 	IsExternal   bool
 }
 
+// Image represents an <img> element, in document order
+type Image struct {
+	URL     string // Absolute src
+	Alt     string
+	HasAlt  bool   // False when the alt attribute is absent (Alt is then "")
+	Width   int    // Declared width in pixels; 0 when absent or not a number
+	Height  int    // Declared height in pixels; 0 when absent or not a number
+	Loading string // loading attribute, lower-cased ("lazy", "eager" or "")
+}
+
+// Heading represents an <h1>-<h6> element, in document order
+type Heading struct {
+	Level int // 1-6
+	Text  string
+}
+
+// Alternate kinds recorded for <link rel="alternate"> elements
+const (
+	AlternateKindFeed     = "feed"     // RSS, Atom or JSON Feed
+	AlternateKindPrint    = "print"    // media="print" version
+	AlternateKindMobile   = "mobile"   // handheld / small-screen version
+	AlternateKindLanguage = "language" // hreflang translation
+	AlternateKindOther    = "other"
+)
+
+// Alternate represents an alternate representation declared by a link element
+type Alternate struct {
+	URL      string
+	Kind     string // One of the AlternateKind constants
+	Type     string
+	Media    string
+	Hreflang string
+	Title    string
+}
+
+// feedTypes are the MIME types that identify syndication feeds
+var feedTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/rdf+xml":   true,
+	"application/feed+json": true,
+}
+
 // NewHTMLParser creates a new HTML parser with default allowed schemes
 func NewHTMLParser(baseURL string) (*HTMLParser, error) {
 	return NewHTMLParserWithSchemes(baseURL, []string{"https://", "http://"})
@@ -42,10 +100,13 @@ func NewHTMLParser(baseURL string) (*HTMLParser, error) {
 
 // NewHTMLParserWithSchemes creates a new HTML parser with custom allowed schemes
 func NewHTMLParserWithSchemes(baseURL string, allowedSchemes []string) (*HTMLParser, error) {
-	parsedURL, err := url.Parse(baseURL)
+	parsedURL, err := url.Parse(escapeStrayPercents(baseURL))
 	if err != nil {
 		return nil, fmt.Errorf("invalid base URL: %w", err)
 	}
+	if err := encodeURL(parsedURL); err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
 
 	if len(allowedSchemes) == 0 {
 		allowedSchemes = []string{"https://", "http://"}
@@ -59,59 +120,62 @@ func NewHTMLParserWithSchemes(baseURL string, allowedSchemes []string) (*HTMLPar
 
 // Parse parses HTML content and extracts metadata and links.
 // It extracts title, meta description, meta robots, canonical URL,
-// and all links from the HTML document. The content hash is computed
+// headings, structured data, images, static assets, all links, and statistics about the main readable text. The content hash is computed
 // for duplicate detection purposes.
 func (p *HTMLParser) Parse(htmlContent []byte) (*ParseResult, error) {
-	doc, err := html.Parse(strings.NewReader(string(htmlContent)))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse HTML: %w", err)
-	}
-
-	result := &ParseResult{
-		Links: []Link{},
-	}
-
-	// Extract metadata and links
-	p.traverse(doc, result)
-
-	// Generate content hash
-	hash := sha256.Sum256(htmlContent)
-	result.ContentHash = fmt.Sprintf("%x", hash)
-
-	return result, nil
+	return p.ParseReader(bytes.NewReader(htmlContent))
 }
 
-// traverse recursively walks the HTML tree
-func (p *HTMLParser) traverse(n *html.Node, result *ParseResult) {
-	if n.Type == html.ElementNode {
-		switch n.Data {
-		case "title":
-			if n.FirstChild != nil && n.FirstChild.Type == html.TextNode {
-				result.Title = strings.TrimSpace(n.FirstChild.Data)
+// ParseReader extracts the same data as Parse from a stream. The document is
+// read token by token and never held in memory as a whole, so memory use is
+// bounded by the largest single token rather than the page size. The only
+// errors are read errors from r.
+func (p *HTMLParser) ParseReader(r io.Reader) (*ParseResult, error) {
+	digest := &digestReader{r: r, hash: sha256.New()}
+	s := newDocumentScanner(p)
+
+	z := html.NewTokenizer(digest)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("failed to read HTML: %w", err)
 			}
-
-		case "meta":
-			p.parseMeta(n, result)
-
-		case "link":
-			p.parseLink(n, result)
-
-		case "a":
-			p.parseAnchor(n, result)
+			result := s.finish(digest.size)
+			result.ContentHash = fmt.Sprintf("%x", digest.hash.Sum(nil))
+			return result, nil
+		case html.StartTagToken:
+			s.startTag(z.Token(), false)
+		case html.SelfClosingTagToken:
+			s.startTag(z.Token(), true)
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			s.endTag(string(name))
+		case html.TextToken:
+			s.text(z.Text())
 		}
 	}
+}
 
-	// Traverse children
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		p.traverse(c, result)
-	}
+// digestReader hashes and counts the bytes read through it
+type digestReader struct {
+	r    io.Reader
+	hash hash.Hash
+	size int
+}
+
+func (d *digestReader) Read(b []byte) (int, error) {
+	n, err := d.r.Read(b)
+	d.hash.Write(b[:n])
+	d.size += n
+	return n, err
 }
 
 // parseMeta extracts metadata from meta tags
-func (p *HTMLParser) parseMeta(n *html.Node, result *ParseResult) {
+func (p *HTMLParser) parseMeta(attrs []html.Attribute, result *ParseResult) {
 	var name, content string
 
-	for _, attr := range n.Attr {
+	for _, attr := range attrs {
 		switch attr.Key {
 		case "name":
 			name = strings.ToLower(attr.Val)
@@ -128,16 +192,26 @@ func (p *HTMLParser) parseMeta(n *html.Node, result *ParseResult) {
 	}
 }
 
-// parseLink extracts canonical URL from link tags
-func (p *HTMLParser) parseLink(n *html.Node, result *ParseResult) {
+// parseLink extracts canonical URL, pagination relations and alternate
+// representations from link tags
+func (p *HTMLParser) parseLink(attrs []html.Attribute, result *ParseResult) {
 	var rel, href string
+	var alt Alternate
 
-	for _, attr := range n.Attr {
+	for _, attr := range attrs {
 		switch attr.Key {
 		case "rel":
 			rel = strings.ToLower(attr.Val)
 		case "href":
 			href = attr.Val
+		case "type":
+			alt.Type = strings.ToLower(strings.TrimSpace(attr.Val))
+		case "media":
+			alt.Media = strings.TrimSpace(attr.Val)
+		case "hreflang":
+			alt.Hreflang = strings.TrimSpace(attr.Val)
+		case "title":
+			alt.Title = strings.TrimSpace(attr.Val)
 		}
 	}
 
@@ -147,16 +221,74 @@ func (p *HTMLParser) parseLink(n *html.Node, result *ParseResult) {
 			result.CanonicalURL = absURL
 		}
 	}
+
+	// Pagination relations
+	if href != "" {
+		for _, token := range []string{RelNext, RelPrev, "previous"} {
+			if !hasRelToken(rel, token) {
+				continue
+			}
+			if absURL, err := p.resolveURL(href); err == nil {
+				if token == "previous" {
+					token = RelPrev
+				}
+				result.Rels = append(result.Rels, Rel{Rel: token, URL: absURL})
+			}
+		}
+	}
+
+	// rel is a space-separated token list, e.g. "alternate feed"
+	if href != "" && hasRelToken(rel, "alternate") {
+		absURL, err := p.resolveURL(href)
+		if err != nil {
+			return
+		}
+		alt.URL = absURL
+		alt.Kind = classifyAlternate(alt)
+		result.Alternates = append(result.Alternates, alt)
+	}
+
+	if kind := assetKindForRel(rel); kind != "" {
+		p.addAsset(href, kind, result)
+	}
+}
+
+// hasRelToken reports whether a lower-cased rel attribute contains token
+func hasRelToken(rel, token string) bool {
+	for _, field := range strings.Fields(rel) {
+		if field == token {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyAlternate determines the kind of an alternate representation from
+// its type, media and hreflang attributes
+func classifyAlternate(alt Alternate) string {
+	media := strings.ToLower(alt.Media)
+	switch {
+	case feedTypes[alt.Type]:
+		return AlternateKindFeed
+	case strings.Contains(media, "print"):
+		return AlternateKindPrint
+	case strings.Contains(media, "handheld") || strings.Contains(media, "max-width"):
+		return AlternateKindMobile
+	case alt.Hreflang != "":
+		return AlternateKindLanguage
+	default:
+		return AlternateKindOther
+	}
 }
 
 // parseAnchor extracts links from anchor tags
-func (p *HTMLParser) parseAnchor(n *html.Node, result *ParseResult) {
+func (p *HTMLParser) parseAnchor(attrs []html.Attribute, anchorText string, result *ParseResult) {
 	var href, rel string
 
-	for _, attr := range n.Attr {
+	for _, attr := range attrs {
 		switch attr.Key {
 		case "href":
-			href = attr.Val
+			href = strings.TrimSpace(attr.Val)
 		case "rel":
 			rel = attr.Val
 		}
@@ -171,9 +303,6 @@ func (p *HTMLParser) parseAnchor(n *html.Node, result *ParseResult) {
 		return
 	}
 
-	// Extract anchor text
-	anchorText := p.extractText(n)
-
 	// Resolve relative URL
 	absURL, err := p.resolveURL(href)
 	if err != nil {
@@ -203,33 +332,92 @@ func (p *HTMLParser) parseAnchor(n *html.Node, result *ParseResult) {
 	result.Links = append(result.Links, link)
 }
 
-// resolveURL converts relative URLs to absolute URLs
-func (p *HTMLParser) resolveURL(href string) (string, error) {
-	u, err := url.Parse(href)
-	if err != nil {
-		return "", err
+// parseImage extracts an image from an img tag. Images without a src or with
+// a src outside the allowed schemes (such as inline data: URIs) are skipped.
+func (p *HTMLParser) parseImage(attrs []html.Attribute, result *ParseResult) {
+	var src string
+	var img Image
+
+	for _, attr := range attrs {
+		switch attr.Key {
+		case "src":
+			src = strings.TrimSpace(attr.Val)
+		case "alt":
+			img.Alt = strings.TrimSpace(attr.Val)
+			img.HasAlt = true
+		case "width":
+			img.Width = parseDimension(attr.Val)
+		case "height":
+			img.Height = parseDimension(attr.Val)
+		case "loading":
+			img.Loading = strings.ToLower(strings.TrimSpace(attr.Val))
+		}
 	}
 
-	// Resolve relative to base URL
-	resolved := p.baseURL.ResolveReference(u)
-	return resolved.String(), nil
+	if src == "" || !p.isAllowedScheme(src) {
+		return
+	}
+	absURL, err := p.resolveURL(src)
+	if err != nil || !p.isAllowedScheme(absURL) {
+		return
+	}
+	img.URL = absURL
+	result.Images = append(result.Images, img)
+	p.addAsset(absURL, AssetKindImage, result)
 }
 
-// extractText recursively extracts text content from a node
-func (p *HTMLParser) extractText(n *html.Node) string {
-	if n.Type == html.TextNode {
-		return strings.TrimSpace(n.Data)
+// parseDimension reads a width or height attribute in pixels. Browsers accept
+// a trailing "px" and ignore fractions; anything else yields 0.
+func parseDimension(value string) int {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "px")
+	if whole, _, found := strings.Cut(value, "."); found {
+		value = whole
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0
 	}
+	return n
+}
 
-	var parts []string
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		text := p.extractText(c)
-		if text != "" {
-			parts = append(parts, text)
-		}
+// resolveDocumentBase returns the URL of the document's first <base href>,
+// resolved against the page URL as browsers do. Later <base> elements are
+// ignored, and so is a first one whose href does not parse or has a scheme
+// outside the allowed set; relative URLs then resolve against the page URL.
+func (p *HTMLParser) resolveDocumentBase(href string) *url.URL {
+	if href == "" {
+		return nil
+	}
+	u, err := url.Parse(escapeStrayPercents(href))
+	if err != nil {
+		return nil
+	}
+	resolved := p.baseURL.ResolveReference(u)
+	if !p.isAllowedScheme(resolved.String()) {
+		return nil
 	}
+	return resolved
+}
 
-	return strings.Join(parts, " ")
+// resolveURL converts relative URLs to absolute, consistently percent-encoded
+// URLs (see NormalizeURL). The document's <base href>, when present, takes the
+// place of the page URL.
+func (p *HTMLParser) resolveURL(href string) (string, error) {
+	u, err := url.Parse(escapeStrayPercents(strings.TrimSpace(href)))
+	if err != nil {
+		return "", err
+	}
+
+	// Resolve relative to base URL
+	base := p.baseURL
+	if p.documentBase != nil {
+		base = p.documentBase
+	}
+	resolved := base.ResolveReference(u)
+	if err := encodeURL(resolved); err != nil {
+		return "", err
+	}
+	return resolved.String(), nil
 }
 
 // isAllowedScheme checks if the URL has an allowed scheme
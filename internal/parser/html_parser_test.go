@@ -1,7 +1,12 @@
 package parser
 
 import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestHTMLParser(t *testing.T) {
@@ -264,3 +269,269 @@ func TestIsAllowedScheme(t *testing.T) {
 		})
 	}
 }
+
+func TestHTMLParserAlternates(t *testing.T) {
+	htmlContent := `
+<html>
+<head>
+	<link rel="alternate" type="application/rss+xml" title="Blog RSS" href="/feed.xml">
+	<link rel="alternate" type="application/atom+xml" href="https://example.com/atom.xml">
+	<link rel="alternate" media="print" href="/page?print=1">
+	<link rel="alternate" media="only screen and (max-width: 640px)" href="https://m.example.com/page">
+	<link rel="alternate" hreflang="ja" href="https://example.com/ja/page">
+	<link rel="ALTERNATE feed" type="application/feed+json" href="/feed.json">
+	<link rel="stylesheet" href="/style.css">
+</head>
+<body></body>
+</html>
+`
+
+	parser, err := NewHTMLParser("https://example.com/blog/page")
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	result, err := parser.Parse([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	expected := []Alternate{
+		{URL: "https://example.com/feed.xml", Kind: AlternateKindFeed, Type: "application/rss+xml", Title: "Blog RSS"},
+		{URL: "https://example.com/atom.xml", Kind: AlternateKindFeed, Type: "application/atom+xml"},
+		{URL: "https://example.com/page?print=1", Kind: AlternateKindPrint, Media: "print"},
+		{URL: "https://m.example.com/page", Kind: AlternateKindMobile, Media: "only screen and (max-width: 640px)"},
+		{URL: "https://example.com/ja/page", Kind: AlternateKindLanguage, Hreflang: "ja"},
+		{URL: "https://example.com/feed.json", Kind: AlternateKindFeed, Type: "application/feed+json"},
+	}
+
+	if len(result.Alternates) != len(expected) {
+		t.Fatalf("Expected %d alternates, got %d: %+v", len(expected), len(result.Alternates), result.Alternates)
+	}
+	for i, want := range expected {
+		if result.Alternates[i] != want {
+			t.Errorf("Alternate %d = %+v, expected %+v", i, result.Alternates[i], want)
+		}
+	}
+}
+
+func TestPaginationRels(t *testing.T) {
+	htmlContent := `<html><head>
+		<link rel="prev" href="/list?page=1">
+		<link rel="next" href="/list?page=3">
+		<link rel="stylesheet" href="/style.css">
+	</head></html>`
+
+	parser, err := NewHTMLParser("https://example.com/list?page=2")
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	result, err := parser.Parse([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	expected := []Rel{
+		{Rel: RelPrev, URL: "https://example.com/list?page=1"},
+		{Rel: RelNext, URL: "https://example.com/list?page=3"},
+	}
+	if len(result.Rels) != len(expected) || result.Rels[0] != expected[0] || result.Rels[1] != expected[1] {
+		t.Errorf("Expected %+v, got %+v", expected, result.Rels)
+	}
+}
+
+func TestHeadings(t *testing.T) {
+	htmlContent := `<html><body>
+		<h1>Main <em>title</em></h1>
+		<h2>Section</h2>
+		<h3>  Detail  </h3>
+		<H2>Second section</H2>
+		<h6></h6>
+	</body></html>`
+
+	parser, err := NewHTMLParser("https://example.com/")
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	result, err := parser.Parse([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	expected := []Heading{
+		{Level: 1, Text: "Main title"},
+		{Level: 2, Text: "Section"},
+		{Level: 3, Text: "Detail"},
+		{Level: 2, Text: "Second section"},
+		{Level: 6, Text: ""},
+	}
+	if len(result.Headings) != len(expected) {
+		t.Fatalf("Expected %d headings, got %+v", len(expected), result.Headings)
+	}
+	for i, heading := range result.Headings {
+		if heading != expected[i] {
+			t.Errorf("Heading %d: expected %+v, got %+v", i, expected[i], heading)
+		}
+	}
+}
+
+func TestImages(t *testing.T) {
+	htmlContent := `<html><body>
+		<img src="/logo.png" alt="Example logo" width="120" height="40px">
+		<img src="spacer.gif" alt="" loading="LAZY">
+		<img src="https://cdn.example.net/hero.jpg" width="1920.5" height="auto">
+		<img src="data:image/png;base64,iVBORw0KGgo=" alt="inline">
+		<img alt="no source">
+	</body></html>`
+
+	parser, err := NewHTMLParser("https://example.com/products/")
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	result, err := parser.Parse([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	expected := []Image{
+		{URL: "https://example.com/logo.png", Alt: "Example logo", HasAlt: true, Width: 120, Height: 40},
+		{URL: "https://example.com/products/spacer.gif", HasAlt: true, Loading: "lazy"},
+		{URL: "https://cdn.example.net/hero.jpg", Width: 1920},
+	}
+	if len(result.Images) != len(expected) {
+		t.Fatalf("Expected %d images, got %+v", len(expected), result.Images)
+	}
+	for i, img := range result.Images {
+		if img != expected[i] {
+			t.Errorf("Image %d: expected %+v, got %+v", i, expected[i], img)
+		}
+	}
+}
+
+func TestAssets(t *testing.T) {
+	htmlContent := `<html><head>
+		<link rel="stylesheet" href="/css/site.css">
+		<link rel="Shortcut Icon" href="/favicon.ico">
+		<link rel="preconnect" href="https://fonts.example.net">
+		<script src="https://cdn.example.net/app.js"></script>
+		<script>var inline = true;</script>
+		<script type="application/ld+json">{"@type": "WebSite"}</script>
+	</head><body>
+		<img src="logo.png" alt="Logo">
+		<img src="data:image/png;base64,iVBORw0KGgo=">
+	</body></html>`
+
+	parser, err := NewHTMLParser("https://example.com/docs/")
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	result, err := parser.Parse([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	expected := []Asset{
+		{URL: "https://example.com/css/site.css", Kind: AssetKindStylesheet},
+		{URL: "https://example.com/favicon.ico", Kind: AssetKindIcon},
+		{URL: "https://cdn.example.net/app.js", Kind: AssetKindScript, IsExternal: true},
+		{URL: "https://example.com/docs/logo.png", Kind: AssetKindImage},
+	}
+	if len(result.Assets) != len(expected) {
+		t.Fatalf("Expected %d assets, got %+v", len(expected), result.Assets)
+	}
+	for i, asset := range result.Assets {
+		if asset != expected[i] {
+			t.Errorf("Asset %d: expected %+v, got %+v", i, expected[i], asset)
+		}
+	}
+}
+
+func TestBaseHref(t *testing.T) {
+	tests := []struct {
+		name     string
+		head     string
+		expected string // Resolution of href="page.html"
+	}{
+		{"absolute base", `<base href="https://example.com/docs/v2/">`, "https://example.com/docs/v2/page.html"},
+		{"relative base", `<base href="../shared/">`, "https://example.com/shared/page.html"},
+		{"root-relative base", `<base href="/static/">`, "https://example.com/static/page.html"},
+		{"base on another host", `<base href="https://cdn.example.net/site/">`, "https://cdn.example.net/site/page.html"},
+		{"first base wins", `<base href="/first/"><base href="/second/">`, "https://example.com/first/page.html"},
+		{"target-only base skipped", `<base target="_blank"><base href="/second/">`, "https://example.com/second/page.html"},
+		{"empty href", `<base href="">`, "https://example.com/section/page.html"},
+		{"unparseable href", `<base href="http://[::1">`, "https://example.com/section/page.html"},
+		{"disallowed scheme", `<base href="javascript:alert(1)//">`, "https://example.com/section/page.html"},
+		{"invalid first base is not replaced", `<base href="ftp://files.example.com/"><base href="/second/">`, "https://example.com/section/page.html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewHTMLParser("https://example.com/section/index.html")
+			if err != nil {
+				t.Fatalf("Failed to create parser: %v", err)
+			}
+			htmlContent := `<html><head>` + tt.head + `<link rel="canonical" href="page.html"></head>
+				<body><a href="page.html">Page</a><img src="page.html"></body></html>`
+			result, err := parser.Parse([]byte(htmlContent))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			if len(result.Links) != 1 || result.Links[0].URL != tt.expected {
+				t.Errorf("Expected link %s, got %+v", tt.expected, result.Links)
+			}
+			if result.CanonicalURL != tt.expected {
+				t.Errorf("Expected canonical %s, got %s", tt.expected, result.CanonicalURL)
+			}
+			if len(result.Images) != 1 || result.Images[0].URL != tt.expected {
+				t.Errorf("Expected image %s, got %+v", tt.expected, result.Images)
+			}
+		})
+	}
+
+	// Links are external by host of the page, not of the base
+	parser, _ := NewHTMLParser("https://example.com/")
+	result, err := parser.Parse([]byte(`<base href="https://cdn.example.net/"><a href="/x">X</a>`))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	if len(result.Links) != 1 || !result.Links[0].IsExternal {
+		t.Errorf("Expected a link resolved to the base host to be external, got %+v", result.Links)
+	}
+}
+
+func TestParseReader(t *testing.T) {
+	htmlContent := `<html><head><title>Streamed</title><base href="/docs/"></head>
+		<body><nav><a href="menu">Menu</a></nav><main><h1>Heading <em>text</em></h1>
+		<p>Main content words</p><a href="page">Page <b>link</b></a></main></body></html>`
+
+	p, err := NewHTMLParser("https://example.com/")
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	want, err := p.Parse([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	// A reader returning one byte at a time splits every token across reads
+	got, err := p.ParseReader(iotest.OneByteReader(strings.NewReader(htmlContent)))
+	if err != nil {
+		t.Fatalf("Failed to parse stream: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected streamed result %+v, got %+v", want, got)
+	}
+	if got.Title != "Streamed" || len(got.Links) != 2 || got.Links[1].URL != "https://example.com/docs/page" || got.Links[1].AnchorText != "Page link" {
+		t.Errorf("Unexpected result: %+v", got)
+	}
+	if len(got.Headings) != 1 || got.Headings[0].Text != "Heading text" {
+		t.Errorf("Expected heading 'Heading text', got %+v", got.Headings)
+	}
+
+	readErr := errors.New("connection reset")
+	_, err = p.ParseReader(io.MultiReader(strings.NewReader(htmlContent[:40]), iotest.ErrReader(readErr)))
+	if !errors.Is(err, readErr) {
+		t.Errorf("Expected read error, got %v", err)
+	}
+}
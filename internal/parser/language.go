@@ -0,0 +1,208 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Thresholds below which languageDetector reports no language
+const (
+	minLanguageLetters  = 20 // Letters of the dominant script
+	minLanguageStopword = 3  // Stop words of the winning Latin-script language
+)
+
+// latinStopWords are frequent short words of Latin-script languages. A text's
+// language is the one whose stop words it uses most; words shared by several
+// languages count for each of them.
+var latinStopWords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "it", "for", "with", "was", "on", "are", "this", "you", "be", "as", "have", "not", "by"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "mit", "den", "ein", "eine", "zu", "von", "sie", "auf", "für", "dem", "auch", "sich", "wird", "ich"},
+	"fr": {"le", "la", "les", "et", "des", "est", "une", "un", "du", "dans", "que", "pour", "qui", "sur", "pas", "au", "avec", "ce", "il", "sont"},
+	"es": {"el", "la", "los", "las", "y", "de", "que", "en", "es", "por", "con", "para", "una", "un", "del", "se", "no", "su", "al", "como"},
+	"it": {"il", "di", "che", "e", "la", "per", "un", "una", "non", "sono", "del", "della", "con", "è", "gli", "le", "si", "da", "al", "anche"},
+	"pt": {"o", "a", "os", "as", "de", "que", "e", "do", "da", "em", "um", "uma", "para", "com", "não", "é", "dos", "por", "se", "mais"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "op", "te", "in", "niet", "zijn", "voor", "met", "die", "ook", "aan", "er", "maar", "wordt"},
+	"sv": {"och", "att", "det", "som", "en", "är", "på", "för", "med", "av", "inte", "den", "till", "har", "om", "ett", "jag", "var", "de", "kan"},
+	"pl": {"i", "w", "nie", "na", "się", "z", "do", "to", "że", "jest", "jak", "o", "co", "ale", "po", "od", "tak", "za", "przez", "są"},
+	"tr": {"ve", "bir", "bu", "da", "de", "için", "ile", "çok", "daha", "olarak", "gibi", "en", "ne", "var", "olan", "her", "ama", "kadar", "sonra", "değil"},
+	"id": {"yang", "dan", "di", "ini", "itu", "dengan", "untuk", "dari", "tidak", "akan", "dalam", "pada", "ada", "juga", "ke", "saya", "bisa", "atau", "oleh", "karena"},
+}
+
+// stopWordLanguages maps each stop word to the languages using it
+var stopWordLanguages = func() map[string][]string {
+	index := map[string][]string{}
+	for lang, words := range latinStopWords {
+		for _, word := range words {
+			index[word] = append(index[word], lang)
+		}
+	}
+	return index
+}()
+
+// Scripts told apart by languageDetector; each maps to one language except
+// Latin (told apart by stop words), Han and kana (Chinese, or Japanese when
+// kana appear), Cyrillic (Russian unless Ukrainian letters appear) and Arabic
+// (Arabic unless Persian letters appear)
+const (
+	scriptLatin = iota
+	scriptHan
+	scriptKana
+	scriptHangul
+	scriptCyrillic
+	scriptGreek
+	scriptArabic
+	scriptHebrew
+	scriptThai
+	scriptDevanagari
+	scriptCount
+)
+
+// scriptLanguages are the languages of the single-language scripts
+var scriptLanguages = map[int]string{
+	scriptHangul:     "ko",
+	scriptGreek:      "el",
+	scriptArabic:     "ar",
+	scriptHebrew:     "he",
+	scriptThai:       "th",
+	scriptDevanagari: "hi",
+}
+
+// languageDetector guesses the language of a text from its scripts and, for
+// Latin-script text, its stop words, as the words stream past
+type languageDetector struct {
+	letters   [scriptCount]int
+	stopWords map[string]int
+	ukrainian int // Letters only Ukrainian uses among Cyrillic languages
+	persian   int // Letters Persian adds to the Arabic script
+}
+
+// addWord counts the letters and stop words of one word
+func (d *languageDetector) addWord(word string) {
+	latin := false
+	for _, r := range word {
+		script := runeScript(r)
+		if script < 0 {
+			continue
+		}
+		d.letters[script]++
+		switch {
+		case script == scriptLatin:
+			latin = true
+		case strings.ContainsRune("іїєґ", r):
+			d.ukrainian++
+		case strings.ContainsRune("پچژگ", r):
+			d.persian++
+		}
+	}
+	if !latin {
+		return
+	}
+	for _, lang := range stopWordLanguages[word] {
+		if d.stopWords == nil {
+			d.stopWords = map[string]int{}
+		}
+		d.stopWords[lang]++
+	}
+}
+
+// language returns the ISO 639-1 code of the text's language, or "" when the
+// text is too short or no language stands out
+func (d *languageDetector) language() string {
+	// Japanese mixes Han with kana, so the two count together here
+	letters := d.letters
+	letters[scriptHan] += letters[scriptKana]
+	letters[scriptKana] = 0
+	dominant := scriptLatin
+	for script, count := range letters {
+		if count > letters[dominant] {
+			dominant = script
+		}
+	}
+	if letters[dominant] < minLanguageLetters {
+		return ""
+	}
+
+	switch dominant {
+	case scriptLatin:
+		return d.latinLanguage()
+	case scriptHan:
+		// Chinese text has no kana; any real share of it makes the text Japanese
+		if d.letters[scriptKana]*10 >= letters[scriptHan] {
+			return "ja"
+		}
+		return "zh"
+	case scriptCyrillic:
+		if d.ukrainian*50 >= letters[scriptCyrillic] {
+			return "uk"
+		}
+		return "ru"
+	case scriptArabic:
+		if d.persian*50 >= letters[scriptArabic] {
+			return "fa"
+		}
+		return "ar"
+	default:
+		return scriptLanguages[dominant]
+	}
+}
+
+// latinLanguage returns the language whose stop words were seen most, when
+// it leads the runner-up by a quarter
+func (d *languageDetector) latinLanguage() string {
+	best, bestCount := "", 0
+	for lang, count := range d.stopWords {
+		if count > bestCount || (count == bestCount && lang < best) {
+			best, bestCount = lang, count
+		}
+	}
+	second := 0
+	for lang, count := range d.stopWords {
+		if lang != best && count > second {
+			second = count
+		}
+	}
+	if bestCount < minLanguageStopword || bestCount*4 < second*5 {
+		return ""
+	}
+	return best
+}
+
+// runeScript returns the script of a letter, or -1 for other runes
+func runeScript(r rune) int {
+	switch {
+	case r < 0x80:
+		if unicode.IsLetter(r) {
+			return scriptLatin
+		}
+		return -1
+	case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r), r == 'ー':
+		return scriptKana
+	case unicode.Is(unicode.Han, r):
+		return scriptHan
+	case unicode.Is(unicode.Hangul, r):
+		return scriptHangul
+	case unicode.Is(unicode.Cyrillic, r):
+		return scriptCyrillic
+	case unicode.Is(unicode.Greek, r):
+		return scriptGreek
+	case unicode.Is(unicode.Arabic, r):
+		return scriptArabic
+	case unicode.Is(unicode.Hebrew, r):
+		return scriptHebrew
+	case unicode.Is(unicode.Thai, r):
+		return scriptThai
+	case unicode.Is(unicode.Devanagari, r):
+		return scriptDevanagari
+	case unicode.Is(unicode.Latin, r):
+		return scriptLatin
+	}
+	return -1
+}
+
+// PrimaryLanguage returns the lower-cased primary subtag of a language tag,
+// e.g. "en" for "en-US", for comparing a declared language with a detected one
+func PrimaryLanguage(tag string) string {
+	primary, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	primary, _, _ = strings.Cut(primary, "_")
+	return strings.ToLower(primary)
+}
@@ -0,0 +1,46 @@
+package parser
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"english", "The crawler follows every link on the page and records the status of each one it finds.", "en"},
+		{"german", "Der Crawler folgt jedem Link auf der Seite und speichert den Status, der für die Analyse wichtig ist.", "de"},
+		{"french", "Le robot suit tous les liens de la page et enregistre le statut de chaque lien dans la base.", "fr"},
+		{"spanish", "El rastreador sigue todos los enlaces de la página y guarda el estado de cada uno para su análisis.", "es"},
+		{"japanese", "このクローラーはページ上のすべてのリンクをたどり、各リンクの状態を記録します。", "ja"},
+		{"chinese", "这个爬虫会跟踪页面上的所有链接，并记录每个链接的状态以便分析网站的问题。", "zh"},
+		{"korean", "이 크롤러는 페이지의 모든 링크를 따라가서 각 링크의 상태를 기록합니다.", "ko"},
+		{"russian", "Этот краулер переходит по всем ссылкам на странице и записывает их состояние.", "ru"},
+		{"ukrainian", "Цей краулер переходить за всіма посиланнями на сторінці та зберігає їхній стан.", "uk"},
+		{"too short", "Hello world", ""},
+		{"no stop words", "Lorem ipsum dolor sit amet consectetur adipiscing elit sed eiusmod tempor", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewHTMLParser("https://example.com/")
+			if err != nil {
+				t.Fatalf("Failed to create parser: %v", err)
+			}
+			result, err := parser.Parse([]byte("<html><body><nav>Home About Contact</nav><p>" + tt.text + "</p></body></html>"))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if result.Text.Language != tt.want {
+				t.Errorf("Language = %q, want %q", result.Text.Language, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrimaryLanguage(t *testing.T) {
+	for tag, want := range map[string]string{"en-US": "en", " DE ": "de", "pt_BR": "pt", "zh-Hant-TW": "zh", "": ""} {
+		if got := PrimaryLanguage(tag); got != want {
+			t.Errorf("PrimaryLanguage(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"strings"
+)
+
+// Relations recorded from <link> elements and HTTP Link headers
+const (
+	RelCanonical = "canonical"
+	RelNext      = "next"
+	RelPrev      = "prev"
+)
+
+// Rel is a canonical, next or prev relation declared by a page
+type Rel struct {
+	Rel string // One of the Rel constants
+	URL string // Absolute target URL
+}
+
+// HeaderLinks contains the relations declared by HTTP Link response headers
+type HeaderLinks struct {
+	Rels       []Rel
+	Alternates []Alternate
+}
+
+// linkValue is one entry of a Link header: a target and its parameters
+type linkValue struct {
+	target string
+	params map[string]string // Lower-cased parameter names
+}
+
+// ParseLinkHeaders extracts canonical, next, prev and alternate relations from
+// HTTP Link header values (RFC 8288), resolving targets against the base URL.
+// Entries with disallowed schemes or unparseable targets are ignored.
+func (p *HTMLParser) ParseLinkHeaders(values []string) HeaderLinks {
+	var links HeaderLinks
+	for _, value := range values {
+		for _, lv := range splitLinkHeader(value) {
+			if lv.target == "" {
+				continue
+			}
+			absURL, err := p.resolveURL(lv.target)
+			if err != nil || !p.isAllowedScheme(absURL) {
+				continue
+			}
+
+			for _, rel := range strings.Fields(strings.ToLower(lv.params["rel"])) {
+				switch rel {
+				case RelCanonical, RelNext, RelPrev, "previous":
+					if rel == "previous" {
+						rel = RelPrev
+					}
+					links.Rels = append(links.Rels, Rel{Rel: rel, URL: absURL})
+				case "alternate":
+					alt := Alternate{
+						URL:      absURL,
+						Type:     strings.ToLower(lv.params["type"]),
+						Media:    lv.params["media"],
+						Hreflang: lv.params["hreflang"],
+						Title:    lv.params["title"],
+					}
+					alt.Kind = classifyAlternate(alt)
+					links.Alternates = append(links.Alternates, alt)
+				}
+			}
+		}
+	}
+	return links
+}
+
+// splitLinkHeader splits a Link header value into its entries, e.g.
+// `<https://example.com/a>; rel="canonical", </page/2>; rel=next`.
+// Commas and semicolons inside <...> or quoted strings do not separate entries.
+func splitLinkHeader(value string) []linkValue {
+	var entries []linkValue
+	rest := value
+	for {
+		start := strings.IndexByte(rest, '<')
+		if start < 0 {
+			return entries
+		}
+		end := strings.IndexByte(rest[start:], '>')
+		if end < 0 {
+			return entries
+		}
+		entry := linkValue{
+			target: strings.TrimSpace(rest[start+1 : start+end]),
+			params: map[string]string{},
+		}
+		rest = rest[start+end+1:]
+
+		// Parameters run until an unquoted comma
+		params, next := splitUnquoted(rest, ',')
+		for _, param := range splitParams(params) {
+			name, val, _ := strings.Cut(param, "=")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if _, seen := entry.params[name]; !seen { // The first occurrence wins
+				entry.params[name] = strings.Trim(strings.TrimSpace(val), `"`)
+			}
+		}
+		entries = append(entries, entry)
+		rest = next
+	}
+}
+
+// splitUnquoted returns the text before the first sep outside a quoted string
+// and the text after it
+func splitUnquoted(s string, sep byte) (before, after string) {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case '\\':
+			if quoted {
+				i++
+			}
+		case sep:
+			if !quoted {
+				return s[:i], s[i+1:]
+			}
+		}
+	}
+	return s, ""
+}
+
+// splitParams splits `; rel="a"; type=b` into its parameters
+func splitParams(s string) []string {
+	var params []string
+	for {
+		before, after := splitUnquoted(s, ';')
+		if before = strings.TrimSpace(before); before != "" {
+			params = append(params, before)
+		}
+		if after == "" {
+			return params
+		}
+		s = after
+	}
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParseLinkHeaders(t *testing.T) {
+	parser, err := NewHTMLParser("https://example.com/docs/guide.pdf")
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	links := parser.ParseLinkHeaders([]string{
+		`<https://example.com/docs/guide>; rel="canonical"`,
+		`</docs/guide.pdf?page=2>; rel=next, <guide.pdf?page=0>; rel="previous"`,
+		`<https://example.com/ja/guide.pdf>; rel="alternate"; hreflang="ja"; title="Guide; Japanese, PDF"`,
+		`<https://example.com/feed>; rel="alternate"; type="application/atom+xml"`,
+		`<https://example.com/style.css>; rel=preload; as=style`,
+		`<mailto:docs@example.com>; rel="canonical"`,
+	})
+
+	expectedRels := []Rel{
+		{Rel: RelCanonical, URL: "https://example.com/docs/guide"},
+		{Rel: RelNext, URL: "https://example.com/docs/guide.pdf?page=2"},
+		{Rel: RelPrev, URL: "https://example.com/docs/guide.pdf?page=0"},
+	}
+	if len(links.Rels) != len(expectedRels) {
+		t.Fatalf("Expected %d relations, got %+v", len(expectedRels), links.Rels)
+	}
+	for i, rel := range links.Rels {
+		if rel != expectedRels[i] {
+			t.Errorf("Relation %d: expected %+v, got %+v", i, expectedRels[i], rel)
+		}
+	}
+
+	if len(links.Alternates) != 2 {
+		t.Fatalf("Expected 2 alternates, got %+v", links.Alternates)
+	}
+	translation := links.Alternates[0]
+	if translation.Kind != AlternateKindLanguage || translation.Hreflang != "ja" || translation.Title != "Guide; Japanese, PDF" {
+		t.Errorf("Unexpected translation: %+v", translation)
+	}
+	if links.Alternates[1].Kind != AlternateKindFeed {
+		t.Errorf("Expected feed alternate, got %+v", links.Alternates[1])
+	}
+}
+
+func TestParseLinkHeadersMalformed(t *testing.T) {
+	parser, err := NewHTMLParser("https://example.com/")
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	for _, value := range []string{"", "rel=canonical", "<https://example.com/a", `<>; rel="next"`} {
+		links := parser.ParseLinkHeaders([]string{value})
+		if len(links.Rels) != 0 || len(links.Alternates) != 0 {
+			t.Errorf("Unexpected result for %q: %+v", value, links)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"strings"
+)
+
+// valuedRobotsDirectives are X-Robots-Tag directives written as "name: value";
+// any other "name:" prefix names the user agent the following directives apply to
+var valuedRobotsDirectives = map[string]bool{
+	"unavailable_after": true,
+	"max-snippet":       true,
+	"max-image-preview": true,
+	"max-video-preview": true,
+}
+
+// ParseXRobotsTag returns the lower-cased directives of X-Robots-Tag header
+// values that apply to the given user agent product token: unscoped directives
+// and those scoped to the token (e.g. "linktadoru: noindex"). A user agent
+// prefix applies to the rest of its header value. Duplicates are removed.
+func ParseXRobotsTag(values []string, product string) []string {
+	product = strings.ToLower(strings.TrimSpace(product))
+	seen := make(map[string]bool)
+	var directives []string
+	for _, value := range values {
+		scope := ""
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if name, rest, ok := strings.Cut(part, ":"); ok {
+				name = strings.ToLower(strings.TrimSpace(name))
+				if !valuedRobotsDirectives[name] {
+					scope = name
+					part = strings.TrimSpace(rest)
+				}
+			}
+			if part == "" || (scope != "" && scope != product) {
+				continue
+			}
+			directive := strings.ToLower(part)
+			if !seen[directive] {
+				seen[directive] = true
+				directives = append(directives, directive)
+			}
+		}
+	}
+	return directives
+}
+
+// ParseMetaRobots returns the lower-cased directives of a <meta name="robots">
+// content attribute
+func ParseMetaRobots(content string) []string {
+	var directives []string
+	for _, part := range strings.Split(content, ",") {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			directives = append(directives, part)
+		}
+	}
+	return directives
+}
+
+// HasRobotsDirective reports whether directives contain name, counting "none"
+// as both "noindex" and "nofollow"
+func HasRobotsDirective(directives []string, name string) bool {
+	for _, directive := range directives {
+		if directive == name || (directive == "none" && (name == "noindex" || name == "nofollow")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseXRobotsTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected []string
+	}{
+		{"none", nil, nil},
+		{"unscoped", []string{"NoIndex, nofollow"}, []string{"noindex", "nofollow"}},
+		{"other agent", []string{"googlebot: noindex, nofollow"}, nil},
+		{"own agent", []string{"LinkTadoru: nofollow, noarchive"}, []string{"nofollow", "noarchive"}},
+		{"scope ends with the header value", []string{"googlebot: noindex", "nosnippet"}, []string{"nosnippet"}},
+		{"scope switches", []string{"googlebot: noindex, linktadoru: nofollow"}, []string{"nofollow"}},
+		{"valued directive", []string{"unavailable_after: 25 Jun 2010 15:00:00 PST, max-snippet: 20"},
+			[]string{"unavailable_after: 25 jun 2010 15:00:00 pst", "max-snippet: 20"}},
+		{"duplicates", []string{"noindex", "noindex, none"}, []string{"noindex", "none"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseXRobotsTag(tt.values, "linktadoru")
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseMetaRobots(t *testing.T) {
+	got := ParseMetaRobots(" NoIndex,, NOFOLLOW ")
+	if !reflect.DeepEqual(got, []string{"noindex", "nofollow"}) {
+		t.Errorf("Expected noindex and nofollow, got %q", got)
+	}
+}
+
+func TestHasRobotsDirective(t *testing.T) {
+	if !HasRobotsDirective([]string{"none"}, "nofollow") || !HasRobotsDirective([]string{"none"}, "noindex") {
+		t.Error("Expected none to imply noindex and nofollow")
+	}
+	if HasRobotsDirective([]string{"noindex"}, "nofollow") {
+		t.Error("Expected noindex not to imply nofollow")
+	}
+}
@@ -0,0 +1,293 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// voidElements never have content or an end tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "keygen": true, "link": true,
+	"meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// headElements may appear before <body> without starting it
+var headElements = map[string]bool{
+	"html": true, "head": true, "title": true, "base": true, "link": true,
+	"meta": true, "style": true, "script": true, "noscript": true,
+	"template": true, "basefont": true, "bgsound": true, "noframes": true,
+}
+
+// openElement is an entry in the stack of open elements
+type openElement struct {
+	name        string
+	boilerplate int             // Stack index of the innermost open boilerplate element, this one included; -1 for none
+	text        *elementText    // Text of an <a>, <button> or heading element; nil for others
+	heading     int             // Index into ParseResult.Headings; -1 for other elements
+	jsonLD      bool            // <script type="application/ld+json">
+	accessible  *accessibleName // Link or button checked for an accessible name; nil for others
+}
+
+// elementText collects the text of an open <a>, <button> or heading element: trimmed
+// text tokens joined by single spaces
+type elementText struct {
+	parts []string
+}
+
+func (t *elementText) add(text string) {
+	if text = strings.TrimSpace(text); text != "" {
+		t.parts = append(t.parts, text)
+	}
+}
+
+func (t *elementText) String() string {
+	return strings.Join(t.parts, " ")
+}
+
+// documentScanner extracts a ParseResult from the token stream of one
+// document. A stack of open elements tells which anchor, heading and
+// boilerplate elements a text token is inside of, approximating the tree
+// html.Parse would build: end tags pop to the matching element, a new <a>
+// closes one left open, and a heading closes a heading it directly follows.
+// Self-closing tags are treated as void, as in SVG and MathML.
+//
+// URLs are resolved after the whole document has been read, because the
+// first <base href> applies to every relative URL, including those before it.
+type documentScanner struct {
+	p          *HTMLParser
+	result     *ParseResult
+	stack      []openElement
+	collectors []*elementText // Text of the open <a>, <button> and heading elements
+	deferred   []func()       // URL-resolving steps, in document order
+	baseHref   string
+	hasBase    bool
+	a11y       accessibilityScan
+
+	main, article, body textRegion
+}
+
+func newDocumentScanner(p *HTMLParser) *documentScanner {
+	return &documentScanner{
+		p:      p,
+		result: &ParseResult{Links: []Link{}},
+	}
+}
+
+// startTag handles a start tag, recording what the element contributes and
+// pushing it onto the stack unless it is void
+func (s *documentScanner) startTag(tok html.Token, selfClosing bool) {
+	name, attrs := tok.Data, tok.Attr
+
+	if !s.body.started && !headElements[name] {
+		s.startBody()
+	}
+	switch {
+	case name == "a":
+		if i := s.lastOpen(func(n string) bool { return n == "a" }); i >= 0 {
+			s.popTo(i)
+		}
+	case isHeading(name):
+		if n := len(s.stack); n > 0 && isHeading(s.stack[n-1].name) {
+			s.popTo(n - 1)
+		}
+	}
+
+	e := openElement{name: name, boilerplate: s.innermostBoilerplate(), heading: -1}
+	switch {
+	case name == "meta":
+		s.p.parseMeta(attrs, s.result)
+	case name == "base":
+		if href, ok := attr(attrs, "href"); ok && !s.hasBase {
+			s.baseHref, s.hasBase = strings.TrimSpace(href), true
+		}
+	case name == "link":
+		s.later(func() { s.p.parseLink(attrs, s.result) })
+	case name == "a":
+		e.text = &elementText{}
+		text := e.text
+		s.later(func() { s.p.parseAnchor(attrs, text.String(), s.result) })
+	case name == "img":
+		s.later(func() { s.p.parseImage(attrs, s.result) })
+	case isHeading(name):
+		e.text = &elementText{}
+		e.heading = len(s.result.Headings)
+		s.result.Headings = append(s.result.Headings, Heading{Level: int(name[1] - '0')})
+	case name == "button":
+		e.text = &elementText{}
+	case name == "script":
+		if isJSONLDScript(attrs) {
+			e.jsonLD = true
+		} else {
+			s.later(func() { s.p.parseScript(attrs, s.result) })
+		}
+	}
+	s.a11y.startTag(s, name, attrs, &e)
+	s.p.parseMicrodata(attrs, s.result)
+
+	depth := len(s.stack)
+	push := !voidElements[name] && !selfClosing
+	if isMainElement(name, attrs) {
+		s.main.start(depth, push)
+	}
+	if name == "article" {
+		s.article.start(depth, push)
+	}
+	if !push {
+		return
+	}
+	if isBoilerplate(name, attrs) {
+		e.boilerplate = depth
+	}
+	if e.text != nil {
+		s.collectors = append(s.collectors, e.text)
+	}
+	s.stack = append(s.stack, e)
+}
+
+// endTag pops the stack down to the matching open element; stray end tags
+// are ignored. Any heading end tag closes the innermost open heading.
+func (s *documentScanner) endTag(name string) {
+	match := func(n string) bool { return n == name }
+	if isHeading(name) {
+		match = isHeading
+	}
+	if i := s.lastOpen(match); i >= 0 {
+		s.popTo(i)
+	}
+}
+
+// text handles a text token: the page title, a JSON-LD payload, anchor and
+// heading text, and the main-content statistics
+func (s *documentScanner) text(data []byte) {
+	var top *openElement
+	if n := len(s.stack); n > 0 {
+		top = &s.stack[n-1]
+	}
+	switch {
+	case top != nil && top.name == "title":
+		s.result.Title = strings.TrimSpace(string(data))
+	case top != nil && top.jsonLD:
+		s.p.parseJSONLD(string(data), s.result)
+	}
+
+	// Text outside the head starts an implied <body>
+	if !s.body.started && len(bytes.TrimSpace(data)) > 0 &&
+		(top == nil || top.name == "html" || top.name == "head") {
+		s.startBody()
+	}
+
+	if len(s.collectors) > 0 {
+		text := string(data)
+		for _, c := range s.collectors {
+			c.add(text)
+		}
+	}
+
+	boilerplate := s.innermostBoilerplate()
+	var fields []string
+	for _, r := range []*textRegion{&s.main, &s.article, &s.body} {
+		if !r.accepts(boilerplate) {
+			continue
+		}
+		if fields == nil {
+			fields = strings.Fields(string(data))
+		}
+		r.add(fields)
+	}
+}
+
+// finish closes the elements left open, resolves the deferred URLs and
+// returns the result. htmlSize is the size of the document in bytes.
+func (s *documentScanner) finish(htmlSize int) *ParseResult {
+	s.popTo(0)
+	s.result.Accessibility = s.a11y.finish()
+
+	// Relative URLs anywhere in the document resolve against <base href>
+	s.p.documentBase = nil
+	if s.hasBase {
+		s.p.documentBase = s.p.resolveDocumentBase(s.baseHref)
+	}
+	for _, step := range s.deferred {
+		step()
+	}
+
+	root := &s.body
+	switch {
+	case s.main.started:
+		root = &s.main
+	case s.article.started:
+		root = &s.article
+	}
+	s.result.Text = root.stats(htmlSize)
+	return s.result
+}
+
+// startBody begins the body region, closing a <head> left open. The region
+// covers the rest of the document.
+func (s *documentScanner) startBody() {
+	if i := s.lastOpen(func(n string) bool { return n == "head" }); i >= 0 {
+		s.popTo(i)
+	}
+	s.body.start(len(s.stack), true)
+}
+
+// later defers a step that resolves URLs until the document base is known
+func (s *documentScanner) later(step func()) {
+	s.deferred = append(s.deferred, step)
+}
+
+// lastOpen returns the stack index of the innermost open element whose name
+// matches, or -1
+func (s *documentScanner) lastOpen(match func(string) bool) int {
+	for i := len(s.stack) - 1; i >= 0; i-- {
+		if match(s.stack[i].name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// popTo pops the elements at stack index i and above, completing their text
+func (s *documentScanner) popTo(i int) {
+	for len(s.stack) > i {
+		top := len(s.stack) - 1
+		e := s.stack[top]
+		if e.text != nil {
+			s.collectors = s.collectors[:len(s.collectors)-1]
+			if e.heading >= 0 {
+				s.result.Headings[e.heading].Text = e.text.String()
+			}
+		}
+		s.a11y.endElement(e)
+		s.main.end(top)
+		s.article.end(top)
+		s.stack = s.stack[:top]
+	}
+}
+
+// innermostBoilerplate returns the stack index of the innermost open
+// boilerplate element, or -1
+func (s *documentScanner) innermostBoilerplate() int {
+	if n := len(s.stack); n > 0 {
+		return s.stack[n-1].boilerplate
+	}
+	return -1
+}
+
+// isHeading reports whether name is one of h1-h6
+func isHeading(name string) bool {
+	return len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6'
+}
+
+// attr returns the raw value of attribute key and whether it is present
+func attr(attrs []html.Attribute, key string) (string, bool) {
+	for _, a := range attrs {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// shingleSize is the number of consecutive words hashed together. Pages
+// sharing most of their 3-word sequences get SimHashes only a few bits apart,
+// so a changed timestamp or session token moves a hash by little.
+const shingleSize = 3
+
+// simHasher computes the 64-bit SimHash of a text from its word shingles, as
+// split by eachWord, as the text streams past
+type simHasher struct {
+	window  [shingleSize]string // The last words, oldest first
+	words   int
+	weights [64]int
+}
+
+// eachWord calls fn with the words of one whitespace-separated field:
+// lower-cased runs of letters and digits, and each Han, Hiragana or Katakana
+// character on its own
+func eachWord(field string, fn func(word string)) {
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			fn(word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range field {
+		switch {
+		case isCJK(r):
+			flush()
+			fn(string(r))
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			word.WriteRune(unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+}
+
+func (h *simHasher) addWord(word string) {
+	copy(h.window[:], h.window[1:])
+	h.window[shingleSize-1] = word
+	h.words++
+	if h.words >= shingleSize {
+		addWeights(&h.weights, shingleHash(h.window[:]))
+	}
+}
+
+// sum returns the SimHash of the words added so far: bit i is set when most
+// shingle hashes have it set. A text shorter than a shingle is hashed as one
+// shingle; a text without words hashes to 0.
+func (h *simHasher) sum() uint64 {
+	if h.words == 0 {
+		return 0
+	}
+	weights := h.weights
+	if h.words < shingleSize {
+		addWeights(&weights, shingleHash(h.window[shingleSize-h.words:]))
+	}
+	var hash uint64
+	for i, weight := range weights {
+		if weight > 0 {
+			hash |= 1 << i
+		}
+	}
+	return hash
+}
+
+// addWeights counts the set bits of hash up and the clear ones down
+func addWeights(weights *[64]int, hash uint64) {
+	for i := range weights {
+		if hash&(1<<i) != 0 {
+			weights[i]++
+		} else {
+			weights[i]--
+		}
+	}
+}
+
+// shingleHash returns the 64-bit FNV-1a hash of words separated by NUL bytes
+func shingleHash(words []string) uint64 {
+	const (
+		offset = 14695981039346656037
+		prime  = 1099511628211
+	)
+	hash := uint64(offset)
+	for i, word := range words {
+		if i > 0 {
+			hash *= prime // XOR with the NUL separator changes nothing
+		}
+		for j := 0; j < len(word); j++ {
+			hash = (hash ^ uint64(word[j])) * prime
+		}
+	}
+	return hash
+}
+
+// isCJK reports whether r belongs to a script written without spaces
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) || r == 'ー'
+}
+
+// SimHashDistance returns the number of bits two SimHashes differ in; near
+// duplicates are a few bits apart
+func SimHashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
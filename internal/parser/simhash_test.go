@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimHash(t *testing.T) {
+	article := strings.Repeat("The quick brown fox jumps over the lazy dog near the quiet river bank. ", 3) +
+		"Foxes are small omnivorous mammals found on every continent except Antarctica, " +
+		"and they adapt well to cities, farmland and forests alike."
+	simHash := func(body string) uint64 {
+		t.Helper()
+		parser, err := NewHTMLParser("https://example.com/")
+		if err != nil {
+			t.Fatalf("Failed to create parser: %v", err)
+		}
+		result, err := parser.Parse([]byte("<html><body>" + body + "</body></html>"))
+		if err != nil {
+			t.Fatalf("Failed to parse HTML: %v", err)
+		}
+		return result.Text.SimHash
+	}
+
+	base := simHash("<main><p>" + article + "</p><p>Updated 2024-01-02 10:15</p></main>")
+	if base == 0 {
+		t.Fatal("Expected a SimHash for a page with text")
+	}
+
+	// Markup, case, punctuation and boilerplate do not change the hash
+	if got := simHash("<nav>Menu</nav><main><p>" + strings.ToUpper(article) + "</p><div>Updated: 2024/01/02 10:15!</div></main>"); got != base {
+		t.Errorf("Expected the same SimHash after markup changes, got distance %d", SimHashDistance(base, got))
+	}
+
+	// A different timestamp moves the hash a few bits
+	near := simHash("<main><p>" + article + "</p><p>Updated 2025-06-30 23:59</p></main>")
+	if d := SimHashDistance(base, near); d == 0 || d > 10 {
+		t.Errorf("Expected a near-duplicate distance, got %d", d)
+	}
+
+	other := simHash("<main><p>Opening hours: Monday to Friday from nine to five. Closed on public holidays. " +
+		"Call our office or send an email to book an appointment with one of our advisers.</p></main>")
+	if d := SimHashDistance(base, other); d < 16 {
+		t.Errorf("Expected unrelated text to be far apart, got distance %d", d)
+	}
+
+	if got := simHash(""); got != 0 {
+		t.Errorf("Expected 0 for a page without words, got %x", got)
+	}
+	if simHash("<p>Hello</p>") == 0 || simHash("<p>日本語</p>") == 0 {
+		t.Error("Expected a SimHash for texts shorter than a shingle")
+	}
+}
+
+func TestSimHashDistance(t *testing.T) {
+	if d := SimHashDistance(0b1011, 0b0001); d != 2 {
+		t.Errorf("SimHashDistance = %d, want 2", d)
+	}
+	if d := SimHashDistance(0, ^uint64(0)); d != 64 {
+		t.Errorf("SimHashDistance = %d, want 64", d)
+	}
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Sitemap is the content of an XML sitemap, a sitemap index or a plain-text
+// URL list
+type Sitemap struct {
+	URLs     []string // Page URLs (<urlset><url><loc>, or the lines of a text list)
+	Sitemaps []string // Child sitemap URLs of a sitemap index (<sitemapindex><sitemap><loc>)
+}
+
+// sitemapXML matches both <urlset> and <sitemapindex> documents, in any namespace
+type sitemapXML struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// ParseSitemap reads a sitemap in any of the formats of the sitemaps.org
+// protocol: an XML urlset, an XML sitemap index or a text file with one URL
+// per line. Gzip-compressed input is decompressed. Text lines that are empty
+// or start with '#' are skipped.
+func ParseSitemap(r io.Reader) (*Sitemap, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+		defer func() { _ = gz.Close() }()
+		br = bufio.NewReader(gz)
+	}
+
+	data, err := io.ReadAll(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sitemap: %w", err)
+	}
+
+	sitemap := &Sitemap{}
+	if trimmed := bytes.TrimSpace(data); !bytes.HasPrefix(trimmed, []byte("<")) {
+		for _, line := range strings.Split(string(trimmed), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				sitemap.URLs = append(sitemap.URLs, line)
+			}
+		}
+		return sitemap, nil
+	}
+
+	var doc sitemapXML
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse sitemap XML: %w", err)
+	}
+	for _, u := range doc.URLs {
+		if loc := strings.TrimSpace(u.Loc); loc != "" {
+			sitemap.URLs = append(sitemap.URLs, loc)
+		}
+	}
+	for _, s := range doc.Sitemaps {
+		if loc := strings.TrimSpace(s.Loc); loc != "" {
+			sitemap.Sitemaps = append(sitemap.Sitemaps, loc)
+		}
+	}
+	return sitemap, nil
+}
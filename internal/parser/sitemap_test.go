@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSitemap(t *testing.T) {
+	urlset := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/</loc><lastmod>2024-06-01</lastmod></url>
+  <url><loc>
+    https://example.com/a?x=1&amp;y=2
+  </loc></url>
+</urlset>`
+	index := `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/sitemap-1.xml.gz</loc></sitemap>
+</sitemapindex>`
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	_, _ = w.Write([]byte(urlset))
+	_ = w.Close()
+
+	tests := []struct {
+		name  string
+		input string
+		want  *Sitemap
+	}{
+		{"urlset", urlset, &Sitemap{URLs: []string{"https://example.com/", "https://example.com/a?x=1&y=2"}}},
+		{"gzip", gz.String(), &Sitemap{URLs: []string{"https://example.com/", "https://example.com/a?x=1&y=2"}}},
+		{"index", index, &Sitemap{Sitemaps: []string{"https://example.com/sitemap-1.xml.gz"}}},
+		{"text", "# pages\nhttps://example.com/\n\n  https://example.com/b  \n", &Sitemap{URLs: []string{"https://example.com/", "https://example.com/b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSitemap(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ParseSitemap failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := ParseSitemap(strings.NewReader("<urlset><url>")); err == nil {
+		t.Error("Expected error for malformed XML")
+	}
+}
@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Structured data formats
+const (
+	StructuredDataJSONLD    = "json-ld"   // <script type="application/ld+json">
+	StructuredDataMicrodata = "microdata" // top-level itemscope element
+)
+
+// StructuredData is a JSON-LD block or a top-level microdata item
+type StructuredData struct {
+	Format  string   // One of the StructuredData format constants
+	Types   []string // Declared types, schema.org prefix removed (e.g. "Product")
+	Payload string   // Raw JSON-LD text; empty for microdata
+	Valid   bool     // False when a JSON-LD payload is not valid JSON
+}
+
+// schemaOrgPrefixes are stripped from declared types so "https://schema.org/Product",
+// "http://schema.org/Product" and "schema:Product" all read "Product"
+var schemaOrgPrefixes = []string{"https://schema.org/", "http://schema.org/", "schema:"}
+
+// parseJSONLD records a <script type="application/ld+json"> block. Types are
+// taken from the top-level object(s) and the members of an @graph; types of
+// nested values such as an offer inside a product are not listed.
+func (p *HTMLParser) parseJSONLD(payload string, result *ParseResult) {
+	text := strings.TrimSpace(payload)
+	if text == "" {
+		return
+	}
+
+	data := StructuredData{Format: StructuredDataJSONLD, Payload: text}
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err == nil {
+		data.Valid = true
+		data.Types = jsonLDTypes(value, nil)
+	}
+	result.StructuredData = append(result.StructuredData, data)
+}
+
+// jsonLDTypes appends the @type values of a top-level JSON-LD value to types
+func jsonLDTypes(value any, types []string) []string {
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			types = jsonLDTypes(item, types)
+		}
+	case map[string]any:
+		switch t := v["@type"].(type) {
+		case string:
+			types = appendSchemaType(types, t)
+		case []any:
+			for _, item := range t {
+				if s, ok := item.(string); ok {
+					types = appendSchemaType(types, s)
+				}
+			}
+		}
+		if graph, ok := v["@graph"]; ok {
+			types = jsonLDTypes(graph, types)
+		}
+	}
+	return types
+}
+
+// parseMicrodata records an element starting a top-level microdata item.
+// Items that are the value of another item's property (itemprop) are part of
+// their parent and not listed separately.
+func (p *HTMLParser) parseMicrodata(attrs []html.Attribute, result *ParseResult) {
+	var scoped, property bool
+	var itemType string
+	for _, attr := range attrs {
+		switch attr.Key {
+		case "itemscope":
+			scoped = true
+		case "itemprop":
+			property = true
+		case "itemtype":
+			itemType = attr.Val
+		}
+	}
+	if !scoped || property {
+		return
+	}
+
+	data := StructuredData{Format: StructuredDataMicrodata, Valid: true}
+	for _, t := range strings.Fields(itemType) {
+		data.Types = appendSchemaType(data.Types, t)
+	}
+	result.StructuredData = append(result.StructuredData, data)
+}
+
+// appendSchemaType appends a normalized type to types unless already present
+func appendSchemaType(types []string, t string) []string {
+	t = strings.TrimSpace(t)
+	for _, prefix := range schemaOrgPrefixes {
+		if len(t) > len(prefix) && strings.EqualFold(t[:len(prefix)], prefix) {
+			t = t[len(prefix):]
+			break
+		}
+	}
+	if t == "" {
+		return types
+	}
+	for _, existing := range types {
+		if existing == t {
+			return types
+		}
+	}
+	return append(types, t)
+}
+
+// isJSONLDScript reports whether a <script> element holds JSON-LD
+func isJSONLDScript(attrs []html.Attribute) bool {
+	for _, attr := range attrs {
+		if attr.Key == "type" {
+			mediaType, _, _ := strings.Cut(attr.Val, ";")
+			return strings.EqualFold(strings.TrimSpace(mediaType), "application/ld+json")
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStructuredData(t *testing.T) {
+	parser, err := NewHTMLParser("https://example.com/product")
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	html := `<html><head>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@type": "Product", "name": "Kettle",
+ "offers": {"@type": "Offer", "price": "25.00"}}
+</script>
+<script type="application/ld+json; charset=utf-8">
+{"@context": "https://schema.org", "@graph": [
+  {"@type": ["WebPage", "ItemPage"]},
+  {"@type": "schema:BreadcrumbList"}
+]}
+</script>
+<script type="application/ld+json">{"@type": "Broken",</script>
+<script type="application/ld+json">   </script>
+<script>var notStructured = {"@type": "Thing"};</script>
+</head><body>
+<div itemscope itemtype="https://schema.org/Organization">
+  <span itemprop="name">Example</span>
+  <div itemprop="address" itemscope itemtype="https://schema.org/PostalAddress"></div>
+</div>
+<div itemscope></div>
+</body></html>`
+
+	result, err := parser.Parse([]byte(html))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	expected := []struct {
+		format string
+		types  []string
+		valid  bool
+	}{
+		{StructuredDataJSONLD, []string{"Product"}, true},
+		{StructuredDataJSONLD, []string{"WebPage", "ItemPage", "BreadcrumbList"}, true},
+		{StructuredDataJSONLD, nil, false},
+		{StructuredDataMicrodata, []string{"Organization"}, true},
+		{StructuredDataMicrodata, nil, true},
+	}
+	if len(result.StructuredData) != len(expected) {
+		t.Fatalf("Expected %d structured data items, got %+v", len(expected), result.StructuredData)
+	}
+	for i, want := range expected {
+		got := result.StructuredData[i]
+		if got.Format != want.format || !reflect.DeepEqual(got.Types, want.types) || got.Valid != want.valid {
+			t.Errorf("Item %d: expected %s %v valid=%t, got %+v", i, want.format, want.types, want.valid, got)
+		}
+	}
+
+	if result.StructuredData[0].Payload == "" || result.StructuredData[3].Payload != "" {
+		t.Errorf("Expected a payload for JSON-LD only, got %+v", result.StructuredData)
+	}
+}
@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// TextStats describes the readable text of a page, used for thin-content detection
+type TextStats struct {
+	WordCount  int     // Words in the main text; each CJK character counts as one word
+	TextLength int     // Length of the main text in bytes
+	TextRatio  float64 // TextLength divided by the size of the HTML document (0-1)
+	SimHash    uint64  // SimHash of the main text's word shingles, for near-duplicate detection; 0 without words
+	Language   string  // ISO 639-1 code of the main text's detected language; "" when undetermined
+}
+
+// boilerplateTags are elements whose text is never part of the main content
+var boilerplateTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+	"template": true,
+	"svg":      true,
+	"iframe":   true,
+	"nav":      true,
+	"header":   true,
+	"footer":   true,
+	"aside":    true,
+	"form":     true,
+	"button":   true,
+	"select":   true,
+}
+
+// boilerplateRoles are ARIA landmark roles that mark navigation and page chrome
+var boilerplateRoles = map[string]bool{
+	"navigation":    true,
+	"banner":        true,
+	"contentinfo":   true,
+	"complementary": true,
+	"search":        true,
+}
+
+// textRegion accumulates the text of a candidate main-content element while
+// the document streams past. The main content is the first <main> (or
+// role="main") element, else the first <article>, else <body>; navigation,
+// headers, footers, scripts and other boilerplate inside it are ignored.
+type textRegion struct {
+	started bool
+	open    bool
+	depth   int // Index of the element in the stack of open elements
+	fields  int // Whitespace-separated fields of text
+	bytes   int // Bytes in those fields
+	words   int
+	hash    simHasher
+	lang    languageDetector
+}
+
+// start begins the region at the first matching element; later ones are ignored
+func (r *textRegion) start(depth int, open bool) {
+	if r.started {
+		return
+	}
+	r.started, r.open, r.depth = true, open, depth
+}
+
+// end closes the region when its element is popped off the stack
+func (r *textRegion) end(depth int) {
+	if r.open && r.depth == depth {
+		r.open = false
+	}
+}
+
+// accepts reports whether text belongs to the region, given the stack index
+// of the innermost open boilerplate element (-1 for none)
+func (r *textRegion) accepts(boilerplate int) bool {
+	return r.open && boilerplate < r.depth
+}
+
+// add counts the fields of one text token
+func (r *textRegion) add(fields []string) {
+	for _, field := range fields {
+		r.fields++
+		r.bytes += len(field)
+		r.words += countWords(field)
+		eachWord(field, r.addWord)
+	}
+}
+
+// addWord feeds one word to the SimHash and the language detector
+func (r *textRegion) addWord(word string) {
+	r.hash.addWord(word)
+	r.lang.addWord(word)
+}
+
+// stats returns the word count and text-to-HTML ratio of the region, its text
+// taken as the fields joined by single spaces
+func (r *textRegion) stats(htmlSize int) TextStats {
+	stats := TextStats{WordCount: r.words, TextLength: r.bytes, SimHash: r.hash.sum(), Language: r.lang.language()}
+	if r.fields > 1 {
+		stats.TextLength += r.fields - 1
+	}
+	if htmlSize > 0 {
+		stats.TextRatio = float64(stats.TextLength) / float64(htmlSize)
+	}
+	return stats
+}
+
+// isMainElement reports whether an element is <main> or has role="main"
+func isMainElement(name string, attrs []html.Attribute) bool {
+	return name == "main" || attrValue(attrs, "role") == "main"
+}
+
+// isBoilerplate reports whether an element's text is never main content
+func isBoilerplate(name string, attrs []html.Attribute) bool {
+	return boilerplateTags[name] || boilerplateRoles[attrValue(attrs, "role")]
+}
+
+// attrValue returns the lower-cased value of attribute key, or ""
+func attrValue(attrs []html.Attribute, key string) string {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return strings.ToLower(strings.TrimSpace(attr.Val))
+		}
+	}
+	return ""
+}
+
+// countWords counts whitespace-separated words. Scripts written without spaces
+// (Han, Hiragana, Katakana) count each character as a word, so counts stay
+// comparable for Japanese and Chinese pages.
+func countWords(text string) int {
+	count := 0
+	for _, field := range strings.Fields(text) {
+		hasWord := false
+		for _, r := range field {
+			switch {
+			case isCJK(r):
+				count++
+			case unicode.IsLetter(r) || unicode.IsNumber(r):
+				hasWord = true
+			}
+		}
+		if hasWord {
+			count++
+		}
+	}
+	return count
+}
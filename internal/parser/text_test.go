@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestTextStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		html  string
+		words int
+	}{
+		{
+			name: "main element excludes chrome",
+			html: `<html><body>
+				<header>Site Name</header>
+				<nav><a href="/">Home</a> <a href="/about">About</a></nav>
+				<main><h1>Title here</h1><p>Three more words.</p><script>var x = 1;</script></main>
+				<footer>Copyright notice</footer>
+			</body></html>`,
+			words: 5,
+		},
+		{
+			name: "article used when there is no main",
+			html: `<html><body><div role="navigation">Menu items</div>
+				<article><p>Only these four words</p></article><aside>Related links</aside></body></html>`,
+			words: 4,
+		},
+		{
+			name:  "role main",
+			html:  `<html><body><div>Outside</div><div role="main">Inside the main</div></body></html>`,
+			words: 3,
+		},
+		{
+			name:  "body fallback skips boilerplate",
+			html:  `<html><body><p>Hello, world! Don't panic.</p><style>p{}</style><!-- comment --></body></html>`,
+			words: 4,
+		},
+		{
+			name:  "CJK characters count individually",
+			html:  `<html><body><p>日本語のページ test</p></body></html>`,
+			words: 8,
+		},
+		{
+			name:  "empty body",
+			html:  `<html><head><title>Nothing</title></head><body></body></html>`,
+			words: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewHTMLParser("https://example.com/")
+			if err != nil {
+				t.Fatalf("Failed to create parser: %v", err)
+			}
+			result, err := parser.Parse([]byte(tt.html))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if result.Text.WordCount != tt.words {
+				t.Errorf("Expected %d words, got %d", tt.words, result.Text.WordCount)
+			}
+			if result.Text.TextRatio < 0 || result.Text.TextRatio > 1 {
+				t.Errorf("Text ratio %v outside 0-1", result.Text.TextRatio)
+			}
+		})
+	}
+}
+
+func TestTextRatio(t *testing.T) {
+	html := `<html><body><p>abcd</p></body></html>`
+	parser, err := NewHTMLParser("https://example.com/")
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	result, err := parser.Parse([]byte(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	want := 4 / float64(len(html))
+	if result.Text.TextLength != 4 || result.Text.TextRatio != want {
+		t.Errorf("Expected length 4 and ratio %v, got %d and %v", want, result.Text.TextLength, result.Text.TextRatio)
+	}
+}
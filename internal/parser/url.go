@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// NormalizeURL returns rawURL with a consistent percent-encoding, so the same
+// resource is always fetched and stored under the same string:
+//   - surrounding whitespace is trimmed, as browsers do for href values
+//   - a '%' that does not start a valid escape is encoded as %25
+//   - spaces, control and non-ASCII characters in the path, query and
+//     fragment are percent-encoded as UTF-8 (existing escapes are kept)
+//   - internationalized host names are converted to their punycode form
+func NormalizeURL(rawURL string) (string, error) {
+	u, err := url.Parse(escapeStrayPercents(strings.TrimSpace(rawURL)))
+	if err != nil {
+		return "", err
+	}
+	if err := encodeURL(u); err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// encodeURL normalizes the host and query of a parsed URL in place. The path
+// and fragment are encoded by url.URL.String.
+func encodeURL(u *url.URL) error {
+	if host := u.Hostname(); !isASCII(host) {
+		asciiHost, err := idna.Lookup.ToASCII(host)
+		if err != nil {
+			return fmt.Errorf("invalid host %q: %w", host, err)
+		}
+		if port := u.Port(); port != "" {
+			asciiHost += ":" + port
+		}
+		u.Host = asciiHost
+	}
+	u.RawQuery = encodeQuery(u.RawQuery)
+	return nil
+}
+
+// escapeStrayPercents encodes '%' characters that are not followed by two hex digits
+func escapeStrayPercents(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && (i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2])) {
+			b.WriteString("%25")
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// encodeQuery percent-encodes bytes that are not allowed verbatim in a query
+// string, leaving delimiters such as '&' and '=' and existing escapes intact
+func encodeQuery(query string) string {
+	var b strings.Builder
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"<>\^`+"`{|}", c) >= 0 {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// isASCII reports whether s contains only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// isHex reports whether c is a hexadecimal digit
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+// RegistrableDomain returns the registrable domain of host (the public suffix
+// plus one label, e.g. "example.co.uk" for "shop.example.co.uk"), using the
+// public suffix list. IP addresses, single-label hosts and public suffixes
+// themselves are returned unchanged, lower-cased and without a port.
+func RegistrableDomain(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(strings.Trim(host, "[]"), "."))
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
@@ -0,0 +1,108 @@
+package parser
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"ascii unchanged", "https://example.com/path?a=1&b=2", "https://example.com/path?a=1&b=2"},
+		{"japanese path", "https://example.com/日本語/ページ", "https://example.com/%E6%97%A5%E6%9C%AC%E8%AA%9E/%E3%83%9A%E3%83%BC%E3%82%B8"},
+		{"japanese query", "https://example.com/search?q=東京 駅", "https://example.com/search?q=%E6%9D%B1%E4%BA%AC%20%E9%A7%85"},
+		{"emoji path", "https://example.com/😀/party", "https://example.com/%F0%9F%98%80/party"},
+		{"space in path", "https://example.com/a b/c", "https://example.com/a%20b/c"},
+		{"already encoded", "https://example.com/%E6%97%A5?q=%E6%97%A5", "https://example.com/%E6%97%A5?q=%E6%97%A5"},
+		{"mixed encoded and raw", "https://example.com/%E6%97%A5/日", "https://example.com/%E6%97%A5/%E6%97%A5"},
+		{"encoded slash kept", "https://example.com/a%2Fb/c", "https://example.com/a%2Fb/c"},
+		{"stray percent", "https://example.com/100%/off", "https://example.com/100%25/off"},
+		{"idn host", "https://例え.jp/パス", "https://xn--r8jz45g.jp/%E3%83%91%E3%82%B9"},
+		{"idn host with port", "https://例え.jp:8443/", "https://xn--r8jz45g.jp:8443/"},
+		{"surrounding whitespace", "  https://example.com/page\n", "https://example.com/page"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeURL(tt.input)
+			if err != nil {
+				t.Fatalf("NormalizeURL(%q) error: %v", tt.input, err)
+			}
+			if got != tt.expected {
+				t.Errorf("NormalizeURL(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+
+			// Normalizing is idempotent
+			again, err := NormalizeURL(got)
+			if err != nil || again != got {
+				t.Errorf("NormalizeURL(%q) = %q (err %v), expected idempotent result", got, again, err)
+			}
+		})
+	}
+}
+
+func TestHTMLParserNonASCIILinks(t *testing.T) {
+	htmlContent := `
+<html><body>
+	<a href="/ブログ/記事 1">Japanese</a>
+	<a href="https://example.com/%E3%83%96%E3%83%AD%E3%82%B0/%E8%A8%98%E4%BA%8B%201">Encoded</a>
+	<a href="/emoji/🎉?tag=パーティー">Emoji</a>
+	<a href=" /50%-off ">Stray percent</a>
+	<a href="https://例え.jp/">IDN</a>
+</body></html>
+`
+
+	parser, err := NewHTMLParser("https://example.com/ホーム/")
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	result, err := parser.Parse([]byte(htmlContent))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	expected := []struct {
+		url        string
+		isExternal bool
+	}{
+		{"https://example.com/%E3%83%96%E3%83%AD%E3%82%B0/%E8%A8%98%E4%BA%8B%201", false},
+		{"https://example.com/%E3%83%96%E3%83%AD%E3%82%B0/%E8%A8%98%E4%BA%8B%201", false},
+		{"https://example.com/emoji/%F0%9F%8E%89?tag=%E3%83%91%E3%83%BC%E3%83%86%E3%82%A3%E3%83%BC", false},
+		{"https://example.com/50%25-off", false},
+		{"https://xn--r8jz45g.jp/", true},
+	}
+
+	if len(result.Links) != len(expected) {
+		t.Fatalf("Expected %d links, got %d: %+v", len(expected), len(result.Links), result.Links)
+	}
+	for i, want := range expected {
+		if result.Links[i].URL != want.url {
+			t.Errorf("Link %d URL = %q, expected %q", i, result.Links[i].URL, want.url)
+		}
+		if result.Links[i].IsExternal != want.isExternal {
+			t.Errorf("Link %d IsExternal = %v, expected %v", i, result.Links[i].IsExternal, want.isExternal)
+		}
+	}
+}
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := map[string]string{
+		"www.example.com":    "example.com",
+		"Shop.Example.COM.":  "example.com",
+		"example.com":        "example.com",
+		"a.b.example.co.uk":  "example.co.uk",
+		"blog.example.co.jp": "example.co.jp",
+		"user.github.io":     "user.github.io",
+		"example.com:8080":   "example.com",
+		"127.0.0.1":          "127.0.0.1",
+		"[::1]:443":          "::1",
+		"localhost":          "localhost",
+		"co.uk":              "co.uk",
+	}
+	for host, want := range tests {
+		if got := RegistrableDomain(host); got != want {
+			t.Errorf("RegistrableDomain(%q) = %q, expected %q", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tokenParam is the query parameter carrying the token where a client cannot
+// set headers, such as the dashboard's EventSource
+const tokenParam = "access_token"
+
+// errUnauthorized is returned for requests without the server's token
+var errUnauthorized = errors.New("missing or invalid bearer token")
+
+// validToken reports whether the Authorization header value carries the
+// server's token, comparing in constant time
+func (s *Server) validToken(authorization string) bool {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+// authenticate wraps next so API requests must carry the server's token.
+// The dashboard page itself holds no crawl data and is served to anyone, so
+// a browser can load it and send the token with its API requests.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.Token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		if authorization == "" && r.URL.Query().Has(tokenParam) {
+			authorization = "Bearer " + r.URL.Query().Get(tokenParam)
+		}
+		if r.URL.Path != "/" && !s.validToken(authorization) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="linktadoru"`)
+			writeError(w, http.StatusUnauthorized, errUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GRPCServerOptions returns the options a gRPC server serving the control
+// API needs: interceptors checking the server's token in the
+// "authorization" metadata, when a token is set
+func (s *Server) GRPCServerOptions() []grpc.ServerOption {
+	if s.Token == "" {
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := s.authenticateGRPC(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := s.authenticateGRPC(stream.Context()); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+}
+
+// authenticateGRPC checks the token of a gRPC call
+func (s *Server) authenticateGRPC(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, authorization := range md.Get("authorization") {
+		if s.validToken(authorization) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, errUnauthorized.Error())
+}
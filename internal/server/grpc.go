@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/masahif/linktadoru/api/controlpb"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// protoStates maps the State* constants to their protobuf values
+var protoStates = map[string]controlpb.State{
+	StateIdle:    controlpb.State_STATE_IDLE,
+	StateRunning: controlpb.State_STATE_RUNNING,
+	StatePaused:  controlpb.State_STATE_PAUSED,
+}
+
+// protoTables maps protobuf tables to export table names
+var protoTables = map[controlpb.Table]string{
+	controlpb.Table_TABLE_PAGES:  storage.ExportPages,
+	controlpb.Table_TABLE_LINKS:  storage.ExportLinks,
+	controlpb.Table_TABLE_ERRORS: storage.ExportErrors,
+}
+
+// grpcService implements the gRPC control API on top of a Server, so both
+// APIs steer the same crawls
+type grpcService struct {
+	controlpb.UnimplementedCrawlControlServer
+	s *Server
+}
+
+// RegisterGRPC registers the gRPC control API of the server on r
+func (s *Server) RegisterGRPC(r grpc.ServiceRegistrar) {
+	controlpb.RegisterCrawlControlServer(r, &grpcService{s: s})
+}
+
+// protoStatus collects the status as a protobuf message
+func (g *grpcService) protoStatus() (*controlpb.Status, error) {
+	st, err := g.s.status()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	msg := &controlpb.Status{
+		State:        protoStates[st.State],
+		PagesCrawled: int64(st.PagesCrawled),
+		Errors:       int64(st.Errors),
+		UrlsDropped:  int64(st.URLsDropped),
+		Duration:     durationpb.New(time.Duration(st.DurationSeconds * float64(time.Second))),
+		RunId:        st.RunID,
+		StopReason:   st.StopReason,
+		Queue:        protoQueueCounts(st.Queue),
+	}
+	if st.StartedAt != nil {
+		msg.StartedAt = timestamppb.New(*st.StartedAt)
+	}
+	return msg, nil
+}
+
+// protoQueueCounts converts queue counts to a protobuf message
+func protoQueueCounts(q QueueCounts) *controlpb.QueueCounts {
+	return &controlpb.QueueCounts{
+		Pending:    int64(q.Pending),
+		Processing: int64(q.Processing),
+		Completed:  int64(q.Completed),
+		Errors:     int64(q.Errors),
+	}
+}
+
+func (g *grpcService) GetStatus(ctx context.Context, req *controlpb.GetStatusRequest) (*controlpb.Status, error) {
+	return g.protoStatus()
+}
+
+// WatchStatus sends the status every req.Interval, or the server's
+// EventInterval, until the client cancels
+func (g *grpcService) WatchStatus(req *controlpb.WatchStatusRequest, stream grpc.ServerStreamingServer[controlpb.Status]) error {
+	interval := g.s.EventInterval
+	if req.GetInterval() != nil {
+		if err := req.GetInterval().CheckValid(); err != nil || req.GetInterval().AsDuration() < 0 {
+			return status.Errorf(codes.InvalidArgument, "invalid interval %v", req.GetInterval())
+		}
+		if d := req.GetInterval().AsDuration(); d > 0 {
+			interval = d
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		msg, err := g.protoStatus()
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *grpcService) StartCrawl(ctx context.Context, req *controlpb.StartCrawlRequest) (*controlpb.Status, error) {
+	if err := checkSeedURLs(req.GetUrls()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return g.action(func() error { return g.s.control.Start(req.GetUrls()) })
+}
+
+func (g *grpcService) PauseCrawl(ctx context.Context, req *controlpb.PauseCrawlRequest) (*controlpb.Status, error) {
+	return g.action(g.s.control.Pause)
+}
+
+func (g *grpcService) ResumeCrawl(ctx context.Context, req *controlpb.ResumeCrawlRequest) (*controlpb.Status, error) {
+	return g.action(g.s.control.Resume)
+}
+
+func (g *grpcService) StopCrawl(ctx context.Context, req *controlpb.StopCrawlRequest) (*controlpb.Status, error) {
+	return g.action(g.s.control.Stop)
+}
+
+// action runs a control action and returns the new status. The crawl not
+// being in a state that allows the action is FAILED_PRECONDITION.
+func (g *grpcService) action(action func() error) (*controlpb.Status, error) {
+	if err := action(); err != nil {
+		if errors.Is(err, ErrCrawlRunning) || errors.Is(err, ErrNoCrawl) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return g.protoStatus()
+}
+
+func (g *grpcService) GetQueue(ctx context.Context, req *controlpb.GetQueueRequest) (*controlpb.GetQueueResponse, error) {
+	limit := int(req.GetLimit())
+	if limit < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limit %d: must be a non-negative integer", limit)
+	}
+	if limit == 0 {
+		limit = defaultQueueSample
+	}
+
+	var counts QueueCounts
+	var err error
+	counts.Pending, counts.Processing, counts.Completed, counts.Errors, err = g.s.store.GetQueueStatus()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get queue status: %v", err)
+	}
+	next, err := g.s.store.GetQueuedURLs(limit)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get queued URLs: %v", err)
+	}
+	return &controlpb.GetQueueResponse{Counts: protoQueueCounts(counts), Next: next}, nil
+}
+
+// ListRows streams the rows of an export table, selected as by
+// `linktadoru export`
+func (g *grpcService) ListRows(req *controlpb.ListRowsRequest, stream grpc.ServerStreamingServer[controlpb.Row]) error {
+	table, ok := protoTables[req.GetTable()]
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "invalid table %v", req.GetTable())
+	}
+	if req.GetLimit() < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid limit %d: must be a non-negative integer", req.GetLimit())
+	}
+	filter := storage.ExportFilter{
+		Columns:  req.GetColumns(),
+		Statuses: req.GetStatuses(),
+		Limit:    int(req.GetLimit()),
+	}
+	if err := storage.CheckExportFilter(table, filter); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	columns := filter.Columns
+	if len(columns) == 0 {
+		var err error
+		if columns, err = storage.ExportColumns(table); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	err := g.s.store.ExportRows(table, filter, func(values []any) error {
+		fields := make(map[string]*structpb.Value, len(values))
+		for i, value := range values {
+			v, err := structpb.NewValue(value)
+			if err != nil {
+				return err
+			}
+			fields[columns[i]] = v
+		}
+		return stream.Send(&controlpb.Row{Fields: &structpb.Struct{Fields: fields}})
+	})
+	if err != nil {
+		// A failed send already carries a status, e.g. the client went away
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (g *grpcService) RequeueErrors(ctx context.Context, req *controlpb.RequeueErrorsRequest) (*controlpb.RequeueErrorsResponse, error) {
+	n, err := g.s.store.RequeueErrors(storage.RequeueFilter{URLs: req.GetUrls()})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &controlpb.RequeueErrorsResponse{Requeued: int64(n)}, nil
+}
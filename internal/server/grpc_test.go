@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/masahif/linktadoru/api/controlpb"
+)
+
+func newTestGRPCClient(t *testing.T) (controlpb.CrawlControlClient, *fakeController) {
+	t.Helper()
+	srv, control := newTestAPI(t)
+	return newGRPCClient(t, srv), control
+}
+
+// newGRPCClient serves srv over an in-memory connection and returns a client
+func newGRPCClient(t *testing.T, srv *Server) controlpb.CrawlControlClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	g := grpc.NewServer(srv.GRPCServerOptions()...)
+	srv.RegisterGRPC(g)
+	go func() { _ = g.Serve(listener) }()
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return controlpb.NewCrawlControlClient(conn)
+}
+
+func TestGRPCStatusAndControl(t *testing.T) {
+	client, control := newTestGRPCClient(t)
+	ctx := context.Background()
+
+	st, err := client.GetStatus(ctx, &controlpb.GetStatusRequest{})
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if st.GetState() != controlpb.State_STATE_IDLE || st.GetPagesCrawled() != 2 || st.GetErrors() != 1 ||
+		st.GetQueue().GetPending() != 1 || st.GetQueue().GetCompleted() != 2 || st.GetStartedAt() == nil {
+		t.Errorf("Unexpected status: %v", st)
+	}
+
+	if _, err := client.PauseCrawl(ctx, &controlpb.PauseCrawlRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("PauseCrawl while idle = %v, want FailedPrecondition", err)
+	}
+	if _, err := client.StartCrawl(ctx, &controlpb.StartCrawlRequest{Urls: []string{"example.com"}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("StartCrawl with a relative URL = %v, want InvalidArgument", err)
+	}
+
+	st, err = client.StartCrawl(ctx, &controlpb.StartCrawlRequest{Urls: []string{"https://example.org/"}})
+	if err != nil {
+		t.Fatalf("StartCrawl failed: %v", err)
+	}
+	if st.GetState() != controlpb.State_STATE_RUNNING || len(control.started) != 1 || control.started[0][0] != "https://example.org/" {
+		t.Errorf("Unexpected start: status %v, calls %v", st, control.started)
+	}
+	if _, err := client.StartCrawl(ctx, &controlpb.StartCrawlRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("StartCrawl while running = %v, want FailedPrecondition", err)
+	}
+
+	if st, err = client.PauseCrawl(ctx, &controlpb.PauseCrawlRequest{}); err != nil || st.GetState() != controlpb.State_STATE_PAUSED {
+		t.Errorf("PauseCrawl = %v, %v", st, err)
+	}
+	if st, err = client.StopCrawl(ctx, &controlpb.StopCrawlRequest{}); err != nil || st.GetState() != controlpb.State_STATE_IDLE {
+		t.Errorf("StopCrawl = %v, %v", st, err)
+	}
+}
+
+func TestGRPCWatchStatus(t *testing.T) {
+	client, _ := newTestGRPCClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.WatchStatus(ctx, &controlpb.WatchStatusRequest{})
+	if err != nil {
+		t.Fatalf("WatchStatus failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		st, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv %d failed: %v", i, err)
+		}
+		if st.GetPagesCrawled() != 2 {
+			t.Errorf("Unexpected status: %v", st)
+		}
+	}
+}
+
+func TestGRPCQueueAndRows(t *testing.T) {
+	client, _ := newTestGRPCClient(t)
+	ctx := context.Background()
+
+	queue, err := client.GetQueue(ctx, &controlpb.GetQueueRequest{})
+	if err != nil {
+		t.Fatalf("GetQueue failed: %v", err)
+	}
+	if queue.GetCounts().GetPending() != 1 || len(queue.GetNext()) != 1 || queue.GetNext()[0] != "https://example.com/next" {
+		t.Errorf("Unexpected queue: %v", queue)
+	}
+
+	stream, err := client.ListRows(ctx, &controlpb.ListRowsRequest{
+		Table:    controlpb.Table_TABLE_PAGES,
+		Columns:  []string{"url", "status_code"},
+		Statuses: []string{"404"},
+	})
+	if err != nil {
+		t.Fatalf("ListRows failed: %v", err)
+	}
+	var rows []map[string]any
+	for {
+		row, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		rows = append(rows, row.GetFields().AsMap())
+	}
+	if len(rows) != 1 || rows[0]["url"] != "https://example.com/missing" || rows[0]["status_code"] != float64(404) {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+
+	stream, err = client.ListRows(ctx, &controlpb.ListRowsRequest{Table: controlpb.Table_TABLE_PAGES, Columns: []string{"nope"}})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListRows with an unknown column = %v, want InvalidArgument", err)
+	}
+}
+
+func TestGRPCTokenAuthentication(t *testing.T) {
+	srv, _ := newTestAPI(t)
+	srv.Token = "s3cret"
+	client := newGRPCClient(t, srv)
+
+	ctx := context.Background()
+	if _, err := client.GetStatus(ctx, &controlpb.GetStatusRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("GetStatus without a token = %v, want Unauthenticated", err)
+	}
+	wrong := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer wrong")
+	if _, err := client.StartCrawl(wrong, &controlpb.StartCrawlRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("StartCrawl with a wrong token = %v, want Unauthenticated", err)
+	}
+	stream, err := client.WatchStatus(ctx, &controlpb.WatchStatusRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("WatchStatus without a token = %v, want Unauthenticated", err)
+	}
+
+	authorized := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer s3cret")
+	if _, err := client.GetStatus(authorized, &controlpb.GetStatusRequest{}); err != nil {
+		t.Errorf("GetStatus with the token failed: %v", err)
+	}
+}
@@ -0,0 +1,419 @@
+// Package server provides the HTTP control API of `linktadoru --serve` and
+// the gRPC control API of `--serve-grpc`.
+//
+// Dashboards and orchestration systems use it to start, stop and pause
+// crawls, inspect the queue, read pages, links and errors, and follow
+// progress as a stream of server-sent events, without opening the SQLite
+// file themselves. Crawls are run by a Controller; results are read from
+// the crawl database. The root path serves a built-in dashboard that uses
+// the same API. The gRPC API, defined in api/controlpb, offers the same
+// operations to programs that embed the crawler as a managed service. Both
+// APIs can require a bearer token (Server.Token).
+package server
+
+import (
+	_ "embed"
+
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// Crawl states reported by the API
+const (
+	StateIdle    = "idle"    // No crawl is running
+	StateRunning = "running" // A crawl is fetching pages
+	StatePaused  = "paused"  // A crawl is running but claims no new URLs
+)
+
+var (
+	// ErrCrawlRunning is returned by Controller.Start while a crawl is running
+	ErrCrawlRunning = errors.New("a crawl is already running")
+	// ErrNoCrawl is returned by Controller methods that need a running crawl
+	ErrNoCrawl = errors.New("no crawl is running")
+)
+
+// Controller runs the crawls the API starts, one at a time
+type Controller interface {
+	Start(urls []string) error // Starts a crawl from urls, or resumes the queue when empty
+	Stop() error
+	Pause() error
+	Resume() error
+	State() string             // One of the State* constants
+	Stats() crawler.CrawlStats // Of the running crawl, or the last one when idle
+}
+
+const (
+	// defaultEventInterval is the time between progress events
+	defaultEventInterval = 2 * time.Second
+	// defaultQueueSample is the number of queued URLs /api/queue lists
+	defaultQueueSample = 100
+	// defaultRecentErrors is the number of errors /api/errors/recent lists
+	defaultRecentErrors = 20
+)
+
+// dashboardHTML is the dashboard page, with its styles and script inline so
+// the binary needs no asset files
+//
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// Server serves the control API
+type Server struct {
+	control Controller
+	store   *storage.SQLiteStorage
+
+	// EventInterval is the time between progress events on /api/events
+	EventInterval time.Duration
+	// Token is the bearer token API requests must send (empty = no authentication)
+	Token string
+}
+
+// New returns a server steering crawls through control and reading results from store
+func New(control Controller, store *storage.SQLiteStorage) *Server {
+	return &Server{control: control, store: store, EventInterval: defaultEventInterval}
+}
+
+// QueueCounts is the number of queue entries in each state
+type QueueCounts struct {
+	Pending    int `json:"pending"`
+	Processing int `json:"processing"`
+	Completed  int `json:"completed"`
+	Errors     int `json:"errors"`
+}
+
+// Status is the state and progress of the current or last crawl
+type Status struct {
+	State           string      `json:"state"`
+	PagesCrawled    int         `json:"pages_crawled"`
+	Errors          int         `json:"errors"`
+	URLsDropped     int         `json:"urls_dropped"`
+	StartedAt       *time.Time  `json:"started_at,omitempty"`
+	DurationSeconds float64     `json:"duration_seconds"`
+	RunID           string      `json:"run_id,omitempty"`
+	StopReason      string      `json:"stop_reason,omitempty"` // Set once a crawl has ended
+	Queue           QueueCounts `json:"queue"`
+}
+
+// Handler returns the HTTP handler of the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleDashboard)
+	mux.HandleFunc("GET /api/status", s.handleStatus)
+	mux.HandleFunc("GET /api/events", s.handleEvents)
+	mux.HandleFunc("GET /api/queue", s.handleQueue)
+	mux.HandleFunc("GET /api/pages", s.handleRows(storage.ExportPages))
+	mux.HandleFunc("GET /api/links", s.handleRows(storage.ExportLinks))
+	mux.HandleFunc("GET /api/errors", s.handleRows(storage.ExportErrors))
+	mux.HandleFunc("GET /api/errors/recent", s.handleRecentErrors)
+	mux.HandleFunc("GET /api/hosts", s.handleHosts)
+	mux.HandleFunc("POST /api/requeue", s.handleRequeue)
+	mux.HandleFunc("POST /api/crawl/start", s.handleStart)
+	mux.HandleFunc("POST /api/crawl/stop", s.handleAction(s.control.Stop))
+	mux.HandleFunc("POST /api/crawl/pause", s.handleAction(s.control.Pause))
+	mux.HandleFunc("POST /api/crawl/resume", s.handleAction(s.control.Resume))
+	return s.authenticate(mux)
+}
+
+// status collects the crawl state and queue counts
+func (s *Server) status() (Status, error) {
+	stats := s.control.Stats()
+	status := Status{
+		State:           s.control.State(),
+		PagesCrawled:    stats.PagesCrawled,
+		Errors:          stats.ErrorCount,
+		URLsDropped:     stats.URLsDropped,
+		DurationSeconds: stats.Duration.Seconds(),
+		RunID:           stats.RunID,
+		StopReason:      stats.StopReason,
+	}
+	if !stats.StartTime.IsZero() {
+		started := stats.StartTime.UTC()
+		status.StartedAt = &started
+	}
+
+	var err error
+	queue := &status.Queue
+	queue.Pending, queue.Processing, queue.Completed, queue.Errors, err = s.store.GetQueueStatus()
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to get queue status: %w", err)
+	}
+	return status, nil
+}
+
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(dashboardHTML)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.status()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleEvents streams the status as server-sent "status" events every
+// EventInterval until the client disconnects
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	ticker := time.NewTicker(s.EventInterval)
+	defer ticker.Stop()
+	for {
+		status, err := s.status()
+		if err != nil {
+			slog.Error("Failed to get crawl status for event stream", "error", err)
+			return
+		}
+		data, err := json.Marshal(status)
+		if err != nil {
+			return
+		}
+		if _, err := fmt.Fprintf(w, "event: status\ndata: %s\n\n", data); err != nil {
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// handleQueue returns the queue counts and the oldest queued URLs; ?limit=
+// sets how many (default 100)
+func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
+	limit, err := intParam(r, "limit", defaultQueueSample)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var queue struct {
+		QueueCounts
+		Next []string `json:"next"`
+	}
+	queue.Pending, queue.Processing, queue.Completed, queue.Errors, err = s.store.GetQueueStatus()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to get queue status: %w", err))
+		return
+	}
+	if queue.Next, err = s.store.GetQueuedURLs(limit); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to get queued URLs: %w", err))
+		return
+	}
+	if queue.Next == nil {
+		queue.Next = []string{}
+	}
+	writeJSON(w, http.StatusOK, queue)
+}
+
+// handleRows streams the rows of an export table as JSON Lines. ?columns=,
+// ?status= and ?limit= select rows and columns as in `linktadoru export`.
+func (s *Server) handleRows(table string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, err := intParam(r, "limit", 0)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		filter := storage.ExportFilter{
+			Columns:  listParam(r, "columns"),
+			Statuses: listParam(r, "status"),
+			Limit:    limit,
+		}
+		if err := storage.CheckExportFilter(table, filter); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		if err := s.store.WriteJSONL(w, table, filter); err != nil {
+			// The status line may already be sent; end the stream short
+			slog.Error("Failed to write API rows", "table", table, "error", err)
+		}
+	}
+}
+
+// handleRecentErrors returns the latest fetch errors, newest first; ?limit=
+// sets how many (default 20)
+func (s *Server) handleRecentErrors(w http.ResponseWriter, r *http.Request) {
+	limit, err := intParam(r, "limit", defaultRecentErrors)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	recent, err := s.store.GetRecentErrors(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if recent == nil {
+		recent = []storage.RecentError{}
+	}
+	writeJSON(w, http.StatusOK, recent)
+}
+
+// handleHosts returns the crawl progress of every host
+func (s *Server) handleHosts(w http.ResponseWriter, r *http.Request) {
+	hosts, err := s.store.GetHostStats(false)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if hosts == nil {
+		hosts = []storage.HostStats{}
+	}
+	writeJSON(w, http.StatusOK, hosts)
+}
+
+// requeueRequest is the body of POST /api/requeue
+type requeueRequest struct {
+	URLs []string `json:"urls"` // Failed pages to requeue; empty = all of them
+}
+
+// handleRequeue puts failed pages back in the queue. A running crawl picks
+// them up; otherwise the next start does.
+func (s *Server) handleRequeue(w http.ResponseWriter, r *http.Request) {
+	var req requeueRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+	}
+	n, err := s.store.RequeueErrors(storage.RequeueFilter{URLs: req.URLs})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"requeued": n})
+}
+
+// startRequest is the body of POST /api/crawl/start
+type startRequest struct {
+	URLs []string `json:"urls"` // Seed URLs; empty resumes the queue
+}
+
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	var req startRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+	}
+	if err := checkSeedURLs(req.URLs); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := s.control.Start(req.URLs); err != nil {
+		writeControlError(w, err)
+		return
+	}
+	s.respondStatus(w, http.StatusAccepted)
+}
+
+// checkSeedURLs returns an error for the first seed URL that is not an
+// absolute http or https URL
+func checkSeedURLs(urls []string) error {
+	for _, seed := range urls {
+		if u, err := url.Parse(seed); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid seed URL %q: must be an absolute http or https URL", seed)
+		}
+	}
+	return nil
+}
+
+// handleAction runs a control action and responds with the new status
+func (s *Server) handleAction(action func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := action(); err != nil {
+			writeControlError(w, err)
+			return
+		}
+		s.respondStatus(w, http.StatusOK)
+	}
+}
+
+// respondStatus responds with the status and the given HTTP status code
+func (s *Server) respondStatus(w http.ResponseWriter, code int) {
+	status, err := s.status()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, code, status)
+}
+
+// writeControlError responds to a failed Controller call: 409 Conflict when
+// the crawl is not in a state that allows the action
+func writeControlError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrCrawlRunning) || errors.Is(err, ErrNoCrawl) {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+// writeJSON responds with v as JSON
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Debug("Failed to write API response", "error", err)
+	}
+}
+
+// writeError responds with {"error": message}
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+// intParam returns the non-negative integer query parameter name, or def when it is absent
+func intParam(r *http.Request, name string, def int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a non-negative integer", name, value)
+	}
+	return n, nil
+}
+
+// listParam returns the values of a query parameter given as a comma-separated
+// list, repeated, or both
+func listParam(r *http.Request, name string) []string {
+	var values []string
+	for _, value := range r.URL.Query()[name] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,305 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// fakeController records the calls the server makes
+type fakeController struct {
+	mu      sync.Mutex
+	state   string
+	started [][]string
+}
+
+func (f *fakeController) Start(urls []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.state != StateIdle {
+		return ErrCrawlRunning
+	}
+	f.state = StateRunning
+	f.started = append(f.started, urls)
+	return nil
+}
+
+func (f *fakeController) Stop() error { return f.set(StateIdle) }
+
+func (f *fakeController) Pause() error { return f.set(StatePaused) }
+
+func (f *fakeController) Resume() error { return f.set(StateRunning) }
+
+func (f *fakeController) set(state string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.state == StateIdle {
+		return ErrNoCrawl
+	}
+	f.state = state
+	return nil
+}
+
+func (f *fakeController) State() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state
+}
+
+func (f *fakeController) Stats() crawler.CrawlStats {
+	return crawler.CrawlStats{PagesCrawled: 2, ErrorCount: 1, StartTime: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *fakeController) {
+	t.Helper()
+	srv, control := newTestAPI(t)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts, control
+}
+
+// newTestAPI returns a server on a database with two crawled pages and one
+// queued, and an idle controller
+func newTestAPI(t *testing.T) (*Server, *fakeController) {
+	t.Helper()
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "serve.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	if err := store.AddToQueue([]string{"https://example.com/", "https://example.com/missing", "https://example.com/next"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for _, page := range []*crawler.PageData{
+		{URL: "https://example.com/", StatusCode: 200, Title: "Home", HTTPHeaders: map[string]string{}},
+		{URL: "https://example.com/missing", StatusCode: 404, HTTPHeaders: map[string]string{}},
+	} {
+		item, _ := store.GetNextFromQueue()
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save page: %v", err)
+		}
+	}
+
+	control := &fakeController{state: StateIdle}
+	srv := New(control, store)
+	srv.EventInterval = 10 * time.Millisecond
+	return srv, control
+}
+
+// request sends a request and decodes a JSON response into v
+func request(t *testing.T, method, url, body string, v any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("Failed to decode %s %s: %v", method, url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestStatusAndControl(t *testing.T) {
+	ts, control := newTestServer(t)
+
+	var status Status
+	if code := request(t, http.MethodGet, ts.URL+"/api/status", "", &status); code != http.StatusOK {
+		t.Fatalf("GET /api/status = %d", code)
+	}
+	if status.State != StateIdle || status.PagesCrawled != 2 || status.Errors != 1 ||
+		status.Queue.Pending != 1 || status.Queue.Completed != 2 || status.StartedAt == nil {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	// Actions need a running crawl
+	var failure map[string]string
+	if code := request(t, http.MethodPost, ts.URL+"/api/crawl/pause", "", &failure); code != http.StatusConflict || failure["error"] != ErrNoCrawl.Error() {
+		t.Errorf("Pause while idle = %d %v, want 409", code, failure)
+	}
+
+	if code := request(t, http.MethodPost, ts.URL+"/api/crawl/start", `{"urls": ["https://example.com/"]}`, &status); code != http.StatusAccepted || status.State != StateRunning {
+		t.Errorf("Start = %d %+v, want 202 running", code, status)
+	}
+	if len(control.started) != 1 || control.started[0][0] != "https://example.com/" {
+		t.Errorf("Unexpected started crawls: %v", control.started)
+	}
+	if code := request(t, http.MethodPost, ts.URL+"/api/crawl/start", "", nil); code != http.StatusConflict {
+		t.Errorf("Second start = %d, want 409", code)
+	}
+	if code := request(t, http.MethodPost, ts.URL+"/api/crawl/pause", "", &status); code != http.StatusOK || status.State != StatePaused {
+		t.Errorf("Pause = %d %+v, want 200 paused", code, status)
+	}
+	if code := request(t, http.MethodPost, ts.URL+"/api/crawl/resume", "", &status); code != http.StatusOK || status.State != StateRunning {
+		t.Errorf("Resume = %d %+v, want 200 running", code, status)
+	}
+	if code := request(t, http.MethodPost, ts.URL+"/api/crawl/stop", "", &status); code != http.StatusOK || status.State != StateIdle {
+		t.Errorf("Stop = %d %+v, want 200 idle", code, status)
+	}
+
+	// Seeds must be absolute http(s) URLs
+	if code := request(t, http.MethodPost, ts.URL+"/api/crawl/start", `{"urls": ["example.com"]}`, nil); code != http.StatusBadRequest {
+		t.Errorf("Start with a relative URL = %d, want 400", code)
+	}
+	if code := request(t, http.MethodGet, ts.URL+"/api/crawl/stop", "", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /api/crawl/stop = %d, want 405", code)
+	}
+}
+
+func TestQueueAndRows(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	var queue struct {
+		Pending int      `json:"pending"`
+		Next    []string `json:"next"`
+	}
+	if code := request(t, http.MethodGet, ts.URL+"/api/queue", "", &queue); code != http.StatusOK {
+		t.Fatalf("GET /api/queue = %d", code)
+	}
+	if queue.Pending != 1 || len(queue.Next) != 1 || queue.Next[0] != "https://example.com/next" {
+		t.Errorf("Unexpected queue: %+v", queue)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/pages?status=404&columns=url,status_code")
+	if err != nil {
+		t.Fatalf("GET /api/pages failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if string(body) != `{"url":"https://example.com/missing","status_code":404}`+"\n" {
+		t.Errorf("Unexpected pages: %s", body)
+	}
+
+	if code := request(t, http.MethodGet, ts.URL+"/api/pages?columns=body", "", nil); code != http.StatusBadRequest {
+		t.Errorf("Unknown column = %d, want 400", code)
+	}
+	if code := request(t, http.MethodGet, ts.URL+"/api/links?limit=-1", "", nil); code != http.StatusBadRequest {
+		t.Errorf("Negative limit = %d, want 400", code)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/api/events")
+	if err != nil {
+		t.Fatalf("GET /api/events failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	// Events repeat every EventInterval
+	scanner := bufio.NewScanner(resp.Body)
+	events := 0
+	for events < 2 && scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var status Status
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &status); err != nil || status.State != StateIdle {
+			t.Errorf("Unexpected event %q: %v", line, err)
+		}
+		events++
+	}
+	if events != 2 {
+		t.Errorf("Expected 2 events, got %d", events)
+	}
+}
+
+func TestDashboardAndFailures(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `new EventSource("/api/events"`) {
+		t.Errorf("GET / = %d, expected the dashboard", resp.StatusCode)
+	}
+	if code := request(t, http.MethodGet, ts.URL+"/missing", "", nil); code != http.StatusNotFound {
+		t.Errorf("GET /missing = %d, want 404", code)
+	}
+
+	var hosts []storage.HostStats
+	if code := request(t, http.MethodGet, ts.URL+"/api/hosts", "", &hosts); code != http.StatusOK {
+		t.Fatalf("GET /api/hosts = %d", code)
+	}
+	if len(hosts) != 1 || hosts[0].Host != "example.com" || hosts[0].Pages != 3 || hosts[0].Pending != 1 {
+		t.Errorf("Unexpected hosts: %+v", hosts)
+	}
+
+	var recent []storage.RecentError
+	if code := request(t, http.MethodGet, ts.URL+"/api/errors/recent", "", &recent); code != http.StatusOK || len(recent) != 0 {
+		t.Errorf("GET /api/errors/recent = %d %+v, want no errors", code, recent)
+	}
+
+	var requeued map[string]int
+	if code := request(t, http.MethodPost, ts.URL+"/api/requeue", `{"urls": ["https://example.com/missing"]}`, &requeued); code != http.StatusOK || requeued["requeued"] != 0 {
+		t.Errorf("Requeue of a completed page = %d %v, want none requeued", code, requeued)
+	}
+}
+
+func TestTokenAuthentication(t *testing.T) {
+	srv, _ := newTestAPI(t)
+	srv.Token = "s3cret"
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	get := func(path, authorization string) int {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	for _, authorization := range []string{"", "Bearer wrong", "s3cret", "Basic s3cret"} {
+		if code := get("/api/status", authorization); code != http.StatusUnauthorized {
+			t.Errorf("GET /api/status with %q = %d, want 401", authorization, code)
+		}
+	}
+	if code := request(t, http.MethodPost, ts.URL+"/api/crawl/start", `{}`, nil); code != http.StatusUnauthorized {
+		t.Errorf("POST /api/crawl/start without a token = %d, want 401", code)
+	}
+	if code := get("/api/status", "Bearer s3cret"); code != http.StatusOK {
+		t.Errorf("GET /api/status with the token = %d, want 200", code)
+	}
+	if code := get("/api/status?access_token=s3cret", ""); code != http.StatusOK {
+		t.Errorf("GET /api/status with the token parameter = %d, want 200", code)
+	}
+	// The dashboard page holds no crawl data
+	if code := get("/", ""); code != http.StatusOK {
+		t.Errorf("GET / without a token = %d, want 200", code)
+	}
+}
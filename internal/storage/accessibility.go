@@ -0,0 +1,116 @@
+// Package storage — accessibility issues.
+//
+// With accessibility_checks on, the problems found in the static HTML of each
+// page are stored in the page_accessibility table: a first-pass sweep for
+// issues that need no browser to detect, summarized by `analyze accessibility`.
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// AccessibilitySummary counts the pages and elements with one kind of issue
+type AccessibilitySummary struct {
+	Issue       string `json:"issue"`
+	Pages       int    `json:"pages"`
+	Occurrences int    `json:"occurrences"`
+}
+
+// PageAccessibilityIssue is one accessibility issue of a crawled page
+type PageAccessibilityIssue struct {
+	URL     string `json:"url"`
+	Issue   string `json:"issue"`
+	Element string `json:"element"`
+}
+
+// savePageAccessibility replaces the accessibility issues stored for a page
+func (s *SQLiteStorage) savePageAccessibility(tx *sql.Tx, pageID int, issues []crawler.AccessibilityIssue) error {
+	if _, err := tx.Exec("DELETE FROM page_accessibility WHERE page_id = ?", pageID); err != nil {
+		return fmt.Errorf("failed to clear page accessibility issues: %w", err)
+	}
+
+	if len(issues) > 0 {
+		stmt, err := tx.Prepare("INSERT INTO page_accessibility (page_id, position, issue, element) VALUES (?, ?, ?, ?)")
+		if err != nil {
+			return fmt.Errorf("failed to prepare accessibility issue insert: %w", err)
+		}
+		defer func() { _ = stmt.Close() }()
+
+		for i, issue := range issues {
+			if _, err := stmt.Exec(pageID, i, issue.Issue, issue.Element); err != nil {
+				return fmt.Errorf("failed to save accessibility issue: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetAccessibilitySummary returns the number of pages and elements with each
+// kind of accessibility issue, the issues affecting the most pages first
+func (s *SQLiteStorage) GetAccessibilitySummary() ([]AccessibilitySummary, error) {
+	rows, err := s.read.Query(`
+		SELECT issue, COUNT(DISTINCT page_id), COUNT(*)
+		FROM page_accessibility
+		GROUP BY issue
+		ORDER BY COUNT(DISTINCT page_id) DESC, issue
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query accessibility issues: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var summary []AccessibilitySummary
+	for rows.Next() {
+		var row AccessibilitySummary
+		if err := rows.Scan(&row.Issue, &row.Pages, &row.Occurrences); err != nil {
+			return nil, fmt.Errorf("failed to scan accessibility summary: %w", err)
+		}
+		summary = append(summary, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read accessibility issues: %w", err)
+	}
+	return summary, nil
+}
+
+// GetAccessibilityIssues returns the accessibility issues of crawled pages,
+// ordered by URL and position. Only the given kinds of issue are returned
+// when issues is not empty.
+func (s *SQLiteStorage) GetAccessibilityIssues(issues []string) ([]PageAccessibilityIssue, error) {
+	query := `
+		SELECT p.url, pa.issue, COALESCE(pa.element, '')
+		FROM page_accessibility pa
+		JOIN pages p ON p.id = pa.page_id`
+	args := make([]any, 0, len(issues))
+	if len(issues) > 0 {
+		query += " WHERE pa.issue IN (?" + strings.Repeat(", ?", len(issues)-1) + ")"
+		for _, issue := range issues {
+			args = append(args, issue)
+		}
+	}
+	query += " ORDER BY p.url, pa.position"
+
+	rows, err := s.read.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query accessibility issues: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var found []PageAccessibilityIssue
+	for rows.Next() {
+		var issue PageAccessibilityIssue
+		if err := rows.Scan(&issue.URL, &issue.Issue, &issue.Element); err != nil {
+			return nil, fmt.Errorf("failed to scan accessibility issue: %w", err)
+		}
+		found = append(found, issue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read accessibility issues: %w", err)
+	}
+	return found, nil
+}
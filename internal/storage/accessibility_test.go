@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestAccessibilityIssues(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "accessibility.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	pages := map[string][]crawler.AccessibilityIssue{
+		"https://example.com/a": {
+			{Issue: "missing_alt", Element: `<img src="/a.png">`},
+			{Issue: "missing_alt", Element: `<img src="/b.png">`},
+			{Issue: "missing_lang", Element: "<html>"},
+		},
+		"https://example.com/b": {
+			{Issue: "missing_alt", Element: `<img src="/c.png">`},
+		},
+		"https://example.com/c": nil,
+	}
+	if err := store.AddToQueue([]string{"https://example.com/a", "https://example.com/b", "https://example.com/c"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	ids := map[string]int{}
+	for range pages {
+		item, _ := store.GetNextFromQueue()
+		ids[item.URL] = item.ID
+		page := &crawler.PageData{URL: item.URL, StatusCode: 200, HTTPHeaders: map[string]string{}, CrawledAt: time.Now(),
+			Accessibility: pages[item.URL]}
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	summary, err := store.GetAccessibilitySummary()
+	if err != nil {
+		t.Fatalf("GetAccessibilitySummary failed: %v", err)
+	}
+	wantSummary := []AccessibilitySummary{
+		{Issue: "missing_alt", Pages: 2, Occurrences: 3},
+		{Issue: "missing_lang", Pages: 1, Occurrences: 1},
+	}
+	if !reflect.DeepEqual(summary, wantSummary) {
+		t.Errorf("Expected summary %+v, got %+v", wantSummary, summary)
+	}
+
+	issues, err := store.GetAccessibilityIssues([]string{"missing_lang"})
+	if err != nil {
+		t.Fatalf("GetAccessibilityIssues failed: %v", err)
+	}
+	want := []PageAccessibilityIssue{{URL: "https://example.com/a", Issue: "missing_lang", Element: "<html>"}}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("Expected %+v, got %+v", want, issues)
+	}
+	if issues, err = store.GetAccessibilityIssues(nil); err != nil || len(issues) != 4 || issues[1].Element != `<img src="/b.png">` {
+		t.Errorf("Expected every issue in page order, got %+v (%v)", issues, err)
+	}
+
+	// Recrawling a page replaces its issues
+	page := &crawler.PageData{URL: "https://example.com/a", StatusCode: 200, HTTPHeaders: map[string]string{}, CrawledAt: time.Now()}
+	if err := store.SavePageResult(ids["https://example.com/a"], page); err != nil {
+		t.Fatalf("Failed to recrawl: %v", err)
+	}
+	if issues, err = store.GetAccessibilityIssues(nil); err != nil || len(issues) != 1 {
+		t.Errorf("Expected the recrawled page's issues to be cleared, got %+v (%v)", issues, err)
+	}
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// FeedPage is a page exposing a syndication feed, as listed by the feed_pages view
+type FeedPage struct {
+	PageURL   string `json:"page_url"`
+	FeedURL   string `json:"feed_url"`
+	FeedType  string `json:"feed_type"`
+	FeedTitle string `json:"feed_title"`
+}
+
+// savePageAlternates replaces the alternate representations stored for a page
+func (s *SQLiteStorage) savePageAlternates(tx *sql.Tx, pageID int, alternates []crawler.AlternateLink) error {
+	if _, err := tx.Exec("DELETE FROM page_alternates WHERE page_id = ?", pageID); err != nil {
+		return fmt.Errorf("failed to clear page alternates: %w", err)
+	}
+
+	if len(alternates) > 0 {
+		stmt, err := tx.Prepare(`
+			INSERT OR IGNORE INTO page_alternates (page_id, href, kind, type, media, hreflang, title, source)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare alternate insert: %w", err)
+		}
+		defer func() { _ = stmt.Close() }()
+
+		for _, alt := range alternates {
+			title, err := s.encryptField(alt.Title)
+			if err != nil {
+				return err
+			}
+			source := alt.Source
+			if source == "" {
+				source = crawler.RelSourceHTML
+			}
+			if _, err := stmt.Exec(pageID, alt.URL, alt.Kind, alt.Type, alt.Media, alt.Hreflang, title, source); err != nil {
+				return fmt.Errorf("failed to save page alternate: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetFeedPages returns every crawled page that exposes a feed, ordered by page URL
+func (s *SQLiteStorage) GetFeedPages() ([]FeedPage, error) {
+	rows, err := s.db.Query(`
+		SELECT page_url, feed_url, COALESCE(feed_type, ''), COALESCE(feed_title, '')
+		FROM feed_pages
+		ORDER BY page_url, feed_url
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feed pages: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var feeds []FeedPage
+	for rows.Next() {
+		var feed FeedPage
+		if err := rows.Scan(&feed.PageURL, &feed.FeedURL, &feed.FeedType, &feed.FeedTitle); err != nil {
+			return nil, fmt.Errorf("failed to scan feed page: %w", err)
+		}
+		if feed.FeedTitle, err = s.DecryptField(feed.FeedTitle); err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, feed)
+	}
+	return feeds, rows.Err()
+}
+
+// savePageRels replaces the canonical, next and prev relations stored for a page
+func (s *SQLiteStorage) savePageRels(tx *sql.Tx, pageID int, rels []crawler.PageRel) error {
+	if _, err := tx.Exec("DELETE FROM page_rels WHERE page_id = ?", pageID); err != nil {
+		return fmt.Errorf("failed to clear page relations: %w", err)
+	}
+
+	if len(rels) > 0 {
+		stmt, err := tx.Prepare("INSERT OR IGNORE INTO page_rels (page_id, rel, href, source) VALUES (?, ?, ?, ?)")
+		if err != nil {
+			return fmt.Errorf("failed to prepare relation insert: %w", err)
+		}
+		defer func() { _ = stmt.Close() }()
+
+		for _, rel := range rels {
+			if _, err := stmt.Exec(pageID, rel.Rel, rel.URL, rel.Source); err != nil {
+				return fmt.Errorf("failed to save page relation: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestPageAlternates(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "alternates.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.AddToQueue([]string{"https://example.com/blog", "https://example.com/about"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+
+	blog, _ := store.GetNextFromQueue()
+	page := &crawler.PageData{
+		URL:         blog.URL,
+		StatusCode:  200,
+		HTTPHeaders: map[string]string{},
+		CrawledAt:   time.Now(),
+		Alternates: []crawler.AlternateLink{
+			{URL: "https://example.com/feed.xml", Kind: "feed", Type: "application/rss+xml", Title: "Blog"},
+			{URL: "https://example.com/blog?print=1", Kind: "print", Media: "print"},
+		},
+	}
+	if err := store.SavePageResult(blog.ID, page); err != nil {
+		t.Fatalf("Failed to save page result: %v", err)
+	}
+
+	about, _ := store.GetNextFromQueue()
+	if err := store.SavePageResult(about.ID, &crawler.PageData{URL: about.URL, StatusCode: 200, HTTPHeaders: map[string]string{}, CrawledAt: time.Now()}); err != nil {
+		t.Fatalf("Failed to save page result: %v", err)
+	}
+
+	feeds, err := store.GetFeedPages()
+	if err != nil {
+		t.Fatalf("Failed to get feed pages: %v", err)
+	}
+	expected := FeedPage{PageURL: "https://example.com/blog", FeedURL: "https://example.com/feed.xml", FeedType: "application/rss+xml", FeedTitle: "Blog"}
+	if len(feeds) != 1 || feeds[0] != expected {
+		t.Fatalf("Expected [%+v], got %+v", expected, feeds)
+	}
+
+	// Re-saving a page replaces its alternates
+	page.Alternates = nil
+	if err := store.SavePageResult(blog.ID, page); err != nil {
+		t.Fatalf("Failed to re-save page result: %v", err)
+	}
+	var count int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM page_alternates").Scan(&count); err != nil {
+		t.Fatalf("Failed to count alternates: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected alternates to be replaced, found %d rows", count)
+	}
+}
+
+func TestPageRels(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "rels.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.AddToQueue([]string{"https://example.com/list?page=2"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	item, _ := store.GetNextFromQueue()
+	page := &crawler.PageData{
+		URL:          item.URL,
+		StatusCode:   200,
+		HTTPHeaders:  map[string]string{},
+		CrawledAt:    time.Now(),
+		CanonicalURL: "https://example.com/list",
+		Rels: []crawler.PageRel{
+			{Rel: "canonical", URL: "https://example.com/list", Source: crawler.RelSourceHTML},
+			{Rel: "canonical", URL: "https://example.com/list-all", Source: crawler.RelSourceHeader},
+			{Rel: "next", URL: "https://example.com/list?page=3", Source: crawler.RelSourceHeader},
+		},
+		Alternates: []crawler.AlternateLink{
+			{URL: "https://example.com/list.json", Kind: "other", Type: "application/json", Source: crawler.RelSourceHeader},
+		},
+	}
+	if err := store.SavePageResult(item.ID, page); err != nil {
+		t.Fatalf("Failed to save page result: %v", err)
+	}
+
+	var count int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM page_rels WHERE page_id = ?", item.ID).Scan(&count); err != nil {
+		t.Fatalf("Failed to count relations: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 relations, got %d", count)
+	}
+
+	var href string
+	err = store.db.QueryRow("SELECT href FROM page_rels WHERE rel = 'canonical' AND source = 'header'").Scan(&href)
+	if err != nil || href != "https://example.com/list-all" {
+		t.Errorf("Expected header canonical, got %q (%v)", href, err)
+	}
+
+	var source string
+	if err := store.db.QueryRow("SELECT source FROM page_alternates").Scan(&source); err != nil || source != "header" {
+		t.Errorf("Expected alternate source 'header', got %q (%v)", source, err)
+	}
+}
+
+func TestMigratePageAlternatesAddSource(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "legacy.db")
+	store, err := NewSQLiteStorage(dbFile)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// Recreate page_alternates as it was before the source column existed
+	_, err = store.db.Exec(`
+		DROP VIEW feed_pages;
+		DROP TABLE page_alternates;
+		CREATE TABLE page_alternates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			page_id INTEGER NOT NULL,
+			href TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			type TEXT,
+			media TEXT,
+			hreflang TEXT,
+			title TEXT,
+			UNIQUE(page_id, href)
+		);
+		INSERT INTO page_alternates (page_id, href, kind) VALUES (1, 'https://example.com/feed.xml', 'feed');
+	`)
+	if err != nil {
+		t.Fatalf("Failed to build legacy table: %v", err)
+	}
+	if err := store.SetMeta(metaSchemaVersion, "7"); err != nil {
+		t.Fatalf("Failed to record legacy schema version: %v", err)
+	}
+
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	var source string
+	if err := store.db.QueryRow("SELECT source FROM page_alternates WHERE page_id = 1").Scan(&source); err != nil {
+		t.Fatalf("Failed to read migrated row: %v", err)
+	}
+	if source != "html" {
+		t.Errorf("Expected existing rows to default to 'html', got %q", source)
+	}
+
+	// Running the migration again is a no-op
+	if err := store.Migrate(); err != nil {
+		t.Errorf("Second Migrate failed: %v", err)
+	}
+}
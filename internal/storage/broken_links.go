@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+)
+
+// BrokenLink is a link whose target failed: a crawled page answering with an
+// HTTP error, a page that could not be fetched, or an external URL whose check
+// failed
+type BrokenLink struct {
+	SourceURL    string `json:"source_url"`
+	TargetURL    string `json:"target_url"`
+	AnchorText   string `json:"anchor_text"`
+	LinkType     string `json:"link_type"`
+	StatusCode   int    `json:"status_code"` // 0 when no HTTP response was received
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// BrokenTarget is a broken link target with every link pointing at it
+type BrokenTarget struct {
+	TargetURL    string             `json:"target_url"`
+	StatusCode   int                `json:"status_code"` // 0 when no HTTP response was received
+	ErrorMessage string             `json:"error_message,omitempty"`
+	Sources      []BrokenLinkSource `json:"sources"`
+}
+
+// BrokenLinkSource is a link pointing at a broken target
+type BrokenLinkSource struct {
+	SourceURL  string `json:"source_url"`
+	AnchorText string `json:"anchor_text"`
+	LinkType   string `json:"link_type"`
+}
+
+// brokenLinkCondition selects the links of link_relations whose target p2 is
+// broken; external targets (checked in ec) are judged by their check
+const brokenLinkCondition = `((ec.page_id IS NOT NULL AND (ec.status_code IS NULL OR ec.status_code = 0 OR ec.status_code >= 400))
+		   OR (ec.page_id IS NULL AND (p2.status = 'error' OR (p2.status = 'completed' AND p2.status_code >= 400))))`
+
+// GetBrokenLinks returns every link pointing at a URL that returned status 400
+// or above or could not be fetched, ordered by source and target URL. External
+// targets are judged by their check_external result; targets that were never
+// crawled or checked are not reported.
+func (s *SQLiteStorage) GetBrokenLinks() ([]BrokenLink, error) {
+	rows, err := s.db.Query(`
+		SELECT
+			p1.url,
+			p2.url,
+			COALESCE(lr.anchor_text, ''),
+			COALESCE(lr.link_type, ''),
+			COALESCE(CASE WHEN ec.page_id IS NOT NULL THEN ec.status_code ELSE p2.status_code END, 0),
+			COALESCE(CASE WHEN ec.page_id IS NOT NULL THEN ec.error_message ELSE p2.last_error_message END, '')
+		FROM link_relations lr
+		JOIN pages p1 ON lr.source_page_id = p1.id
+		JOIN pages p2 ON lr.target_page_id = p2.id
+		LEFT JOIN external_checks ec ON ec.page_id = p2.id
+		WHERE ` + brokenLinkCondition + `
+		ORDER BY p1.url, p2.url
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query broken links: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var links []BrokenLink
+	for rows.Next() {
+		var link BrokenLink
+		if err := rows.Scan(&link.SourceURL, &link.TargetURL, &link.AnchorText, &link.LinkType, &link.StatusCode, &link.ErrorMessage); err != nil {
+			return nil, fmt.Errorf("failed to scan broken link: %w", err)
+		}
+		if link.AnchorText, err = s.DecryptField(link.AnchorText); err != nil {
+			return nil, err
+		}
+		if link.ErrorMessage, err = s.DecryptField(link.ErrorMessage); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// GetBrokenTargets returns the targets of GetBrokenLinks with the pages and
+// anchor texts linking to each, most linked first, then by URL. Only links to
+// the crawled site (internal and asset links) are included unless
+// includeExternal is set.
+func (s *SQLiteStorage) GetBrokenTargets(includeExternal bool) ([]BrokenTarget, error) {
+	links, err := s.GetBrokenLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []BrokenTarget
+	index := make(map[string]int)
+	for _, link := range links {
+		if link.LinkType == "external" && !includeExternal {
+			continue
+		}
+		i, ok := index[link.TargetURL]
+		if !ok {
+			i = len(targets)
+			index[link.TargetURL] = i
+			targets = append(targets, BrokenTarget{TargetURL: link.TargetURL, StatusCode: link.StatusCode, ErrorMessage: link.ErrorMessage})
+		}
+		targets[i].Sources = append(targets[i].Sources, BrokenLinkSource{SourceURL: link.SourceURL, AnchorText: link.AnchorText, LinkType: link.LinkType})
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		if len(targets[i].Sources) != len(targets[j].Sources) {
+			return len(targets[i].Sources) > len(targets[j].Sources)
+		}
+		return targets[i].TargetURL < targets[j].TargetURL
+	})
+	return targets, nil
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestGetBrokenLinks(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "broken.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	home := "https://example.com/"
+	links := []*crawler.LinkData{
+		{SourceURL: home, TargetURL: "https://example.com/ok", AnchorText: "OK", LinkType: "internal"},
+		{SourceURL: home, TargetURL: "https://example.com/missing", AnchorText: "Missing", LinkType: "internal"},
+		{SourceURL: home, TargetURL: "https://example.com/down", AnchorText: "Down", LinkType: "internal"},
+		{SourceURL: home, TargetURL: "https://other.example.org/", AnchorText: "Unchecked", LinkType: "external"},
+		{SourceURL: home, TargetURL: "https://dead.example.org/", AnchorText: "Dead", LinkType: "external"},
+	}
+	if err := store.SaveLinks(links); err != nil {
+		t.Fatalf("Failed to save links: %v", err)
+	}
+
+	if err := store.AddToQueue([]string{"https://example.com/ok", "https://example.com/missing", "https://example.com/down"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		item, _ := store.GetNextFromQueue()
+		switch item.URL {
+		case "https://example.com/down":
+			err = store.SavePageError(item.ID, "connection_failed", "connection refused")
+		default:
+			status := 200
+			if item.URL == "https://example.com/missing" {
+				status = 404
+			}
+			page := &crawler.PageData{URL: item.URL, StatusCode: status, HTTPHeaders: map[string]string{}, CrawledAt: time.Now()}
+			err = store.SavePageResult(item.ID, page)
+		}
+		if err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	check := &crawler.ExternalCheck{URL: "https://dead.example.org/", Method: "HEAD", ErrorMessage: "no such host", CheckedAt: time.Now()}
+	if err := store.SaveExternalCheck(check); err != nil {
+		t.Fatalf("Failed to save external check: %v", err)
+	}
+
+	broken, err := store.GetBrokenLinks()
+	if err != nil {
+		t.Fatalf("GetBrokenLinks failed: %v", err)
+	}
+
+	expected := []BrokenLink{
+		{SourceURL: home, TargetURL: "https://dead.example.org/", AnchorText: "Dead", LinkType: "external", ErrorMessage: "no such host"},
+		{SourceURL: home, TargetURL: "https://example.com/down", AnchorText: "Down", LinkType: "internal", ErrorMessage: "connection refused"},
+		{SourceURL: home, TargetURL: "https://example.com/missing", AnchorText: "Missing", LinkType: "internal", StatusCode: 404},
+	}
+	if len(broken) != len(expected) {
+		t.Fatalf("Expected %d broken links, got %+v", len(expected), broken)
+	}
+	for i, want := range expected {
+		if broken[i] != want {
+			t.Errorf("broken[%d] = %+v, expected %+v", i, broken[i], want)
+		}
+	}
+}
+
+func TestGetBrokenTargets(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "broken.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	links := []*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/gone", AnchorText: "Gone", LinkType: "internal"},
+		{SourceURL: "https://example.com/a", TargetURL: "https://example.com/gone", AnchorText: "Old page", LinkType: "internal"},
+		{SourceURL: "https://example.com/a", TargetURL: "https://example.com/missing", AnchorText: "Missing", LinkType: "internal"},
+		{SourceURL: "https://example.com/a", TargetURL: "https://dead.example.org/", AnchorText: "Dead", LinkType: "external"},
+	}
+	if err := store.SaveLinks(links); err != nil {
+		t.Fatalf("Failed to save links: %v", err)
+	}
+	if err := store.AddToQueue([]string{"https://example.com/gone", "https://example.com/missing"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		item, _ := store.GetNextFromQueue()
+		page := &crawler.PageData{URL: item.URL, StatusCode: 404, HTTPHeaders: map[string]string{}, CrawledAt: time.Now()}
+		if item.URL == "https://example.com/gone" {
+			page.StatusCode = 410
+		}
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+	check := &crawler.ExternalCheck{URL: "https://dead.example.org/", Method: "HEAD", StatusCode: 404, CheckedAt: time.Now()}
+	if err := store.SaveExternalCheck(check); err != nil {
+		t.Fatalf("Failed to save external check: %v", err)
+	}
+
+	targets, err := store.GetBrokenTargets(false)
+	if err != nil {
+		t.Fatalf("GetBrokenTargets failed: %v", err)
+	}
+	want := []BrokenTarget{
+		{TargetURL: "https://example.com/gone", StatusCode: 410, Sources: []BrokenLinkSource{
+			{SourceURL: "https://example.com/", AnchorText: "Gone", LinkType: "internal"},
+			{SourceURL: "https://example.com/a", AnchorText: "Old page", LinkType: "internal"},
+		}},
+		{TargetURL: "https://example.com/missing", StatusCode: 404, Sources: []BrokenLinkSource{
+			{SourceURL: "https://example.com/a", AnchorText: "Missing", LinkType: "internal"},
+		}},
+	}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("Expected %+v, got %+v", want, targets)
+	}
+
+	if targets, err = store.GetBrokenTargets(true); err != nil || len(targets) != 3 {
+		t.Errorf("Expected 3 targets with external links, got %+v (%v)", targets, err)
+	}
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestCanonicalPagesView(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "canonical.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	canonicals := map[string]string{
+		"https://example.com/shoes":           "https://example.com/shoes",
+		"https://example.com/shoes?color=red": "https://example.com/shoes",
+		"https://example.com/boots?page=2":    "https://example.com/boots",
+	}
+	urls := make([]string, 0, len(canonicals))
+	for url := range canonicals {
+		urls = append(urls, url)
+	}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range canonicals {
+		item, err := store.GetNextFromQueue()
+		if err != nil || item == nil {
+			t.Fatalf("Failed to dequeue: %v", err)
+		}
+		page := &crawler.PageData{
+			URL:          item.URL,
+			StatusCode:   200,
+			CanonicalURL: canonicals[item.URL],
+			HTTPHeaders:  map[string]string{},
+			CrawledAt:    time.Now(),
+		}
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	rows, err := store.db.Query("SELECT url, canonical_status_code FROM canonical_pages ORDER BY url")
+	if err != nil {
+		t.Fatalf("Failed to query canonical_pages: %v", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var got []string
+	var codes []*int
+	for rows.Next() {
+		var url string
+		var code *int
+		if err := rows.Scan(&url, &code); err != nil {
+			t.Fatalf("Failed to scan: %v", err)
+		}
+		got = append(got, url)
+		codes = append(codes, code)
+	}
+	if len(got) != 2 || got[0] != "https://example.com/boots?page=2" || got[1] != "https://example.com/shoes?color=red" {
+		t.Fatalf("Expected the two non-canonical pages, got %v", got)
+	}
+	if codes[0] != nil {
+		t.Errorf("Expected no status for the uncrawled canonical, got %d", *codes[0])
+	}
+	if codes[1] == nil || *codes[1] != 200 {
+		t.Errorf("Expected status 200 for the crawled canonical, got %v", codes[1])
+	}
+}
@@ -0,0 +1,85 @@
+// Package storage — canonical conflicts.
+//
+// A canonical URL should be the final, indexable version of a page. Search
+// engines distrust canonicals that redirect, fail or point at a page that is
+// itself canonicalized elsewhere, so `linktadoru analyze canonicals` ties the
+// canonical declarations to the crawled status and redirects of their targets.
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// Issues reported by GetCanonicalIssues
+const (
+	CanonicalIssueRedirects = "redirects" // The canonical URL redirects; Target is where it ends
+	CanonicalIssueBroken    = "broken"    // The canonical URL answered 4xx/5xx or could not be fetched
+	CanonicalIssueChain     = "chain"     // The canonical page declares another canonical; Target is that URL
+)
+
+// CanonicalIssue is a page whose canonical URL is not a final, working page
+type CanonicalIssue struct {
+	Issue        string `json:"issue"`
+	URL          string `json:"url"`
+	CanonicalURL string `json:"canonical_url"`
+	StatusCode   int    `json:"status_code"`      // Final status of the canonical URL (0 = not fetched)
+	Target       string `json:"target,omitempty"` // Redirect destination or next canonical
+}
+
+// GetCanonicalIssues returns the crawled pages whose canonical URL redirects,
+// is broken or is canonicalized to yet another URL, ordered by issue, then
+// URL. A page appears once per issue. Canonical URLs the crawl did not fetch
+// are not judged.
+func (s *SQLiteStorage) GetCanonicalIssues() ([]CanonicalIssue, error) {
+	rows, err := s.read.Query(`
+		SELECT p.url, p.canonical_url, c.status, COALESCE(c.status_code, 0), COALESCE(c.canonical_url, ''),
+		       (SELECT r.to_url FROM redirects r WHERE r.page_id = c.id ORDER BY r.hop DESC LIMIT 1)
+		FROM pages p
+		JOIN pages c ON c.url = p.canonical_url
+		WHERE p.status = 'completed'
+		  AND p.canonical_url IS NOT NULL AND p.canonical_url != ''
+		  AND p.canonical_url != p.url
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query canonical URLs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var issues []CanonicalIssue
+	for rows.Next() {
+		var page CanonicalIssue
+		var status, nextCanonical string
+		var redirectTo sql.NullString
+		if err := rows.Scan(&page.URL, &page.CanonicalURL, &status, &page.StatusCode, &nextCanonical, &redirectTo); err != nil {
+			return nil, fmt.Errorf("failed to scan canonical URL: %w", err)
+		}
+		if redirectTo.Valid {
+			issue := page
+			issue.Issue, issue.Target = CanonicalIssueRedirects, redirectTo.String
+			issues = append(issues, issue)
+		}
+		if status == "error" || (status == "completed" && page.StatusCode >= 400) {
+			issue := page
+			issue.Issue = CanonicalIssueBroken
+			issues = append(issues, issue)
+		}
+		if nextCanonical != "" && nextCanonical != page.CanonicalURL {
+			issue := page
+			issue.Issue, issue.Target = CanonicalIssueChain, nextCanonical
+			issues = append(issues, issue)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read canonical URLs: %w", err)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Issue != issues[j].Issue {
+			return issues[i].Issue < issues[j].Issue
+		}
+		return issues[i].URL < issues[j].URL
+	})
+	return issues, nil
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestGetCanonicalIssues(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "canonicals.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	const base = "https://example.com"
+	pages := map[string]*crawler.PageData{
+		"/a":      {StatusCode: 200, CanonicalURL: base + "/moved"},
+		"/moved":  {StatusCode: 200, Redirects: []crawler.RedirectHop{{StatusCode: 301, FromURL: base + "/moved", ToURL: base + "/final"}}},
+		"/b":      {StatusCode: 200, CanonicalURL: base + "/gone"},
+		"/gone":   {StatusCode: 404},
+		"/c":      {StatusCode: 200, CanonicalURL: base + "/d"},
+		"/d":      {StatusCode: 200, CanonicalURL: base + "/e"},
+		"/good":   {StatusCode: 200, CanonicalURL: base + "/e"},
+		"/e":      {StatusCode: 200, CanonicalURL: base + "/e"},
+		"/self":   {StatusCode: 200, CanonicalURL: base + "/self"},
+		"/absent": {StatusCode: 200, CanonicalURL: base + "/never-crawled"},
+	}
+	var urls []string
+	for path := range pages {
+		urls = append(urls, base+path)
+	}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range pages {
+		item, _ := store.GetNextFromQueue()
+		page := pages[item.URL[len(base):]]
+		page.URL, page.HTTPHeaders, page.CrawledAt = item.URL, map[string]string{}, time.Now()
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	issues, err := store.GetCanonicalIssues()
+	if err != nil {
+		t.Fatalf("GetCanonicalIssues failed: %v", err)
+	}
+	want := []CanonicalIssue{
+		{Issue: CanonicalIssueBroken, URL: base + "/b", CanonicalURL: base + "/gone", StatusCode: 404},
+		{Issue: CanonicalIssueChain, URL: base + "/c", CanonicalURL: base + "/d", StatusCode: 200, Target: base + "/e"},
+		{Issue: CanonicalIssueRedirects, URL: base + "/a", CanonicalURL: base + "/moved", StatusCode: 200, Target: base + "/final"},
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("Expected %+v, got %+v", want, issues)
+	}
+}
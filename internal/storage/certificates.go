@@ -0,0 +1,86 @@
+// Package storage — TLS certificates.
+//
+// The leaf certificate of every HTTPS host contacted while crawling is kept
+// in the tls_certificates table, one row per host, so certificates about to
+// expire can be found before visitors see a browser warning.
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// Certificate is the stored certificate of an HTTPS host
+type Certificate struct {
+	Host        string    `json:"host"`
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	DNSNames    []string  `json:"dns_names"`
+	NotBefore   time.Time `json:"not_before"`
+	NotAfter    time.Time `json:"not_after"`
+	DaysLeft    int       `json:"days_left"` // Whole days until NotAfter; negative once expired
+	Fingerprint string    `json:"fingerprint"`
+	SeenAt      time.Time `json:"seen_at"` // When the certificate was last recorded
+}
+
+// saveCertificates records the certificates presented while fetching a page,
+// replacing those stored for the same hosts
+func (s *SQLiteStorage) saveCertificates(tx *sql.Tx, certs []crawler.TLSCertificate, seenAt time.Time) error {
+	for _, cert := range certs {
+		_, err := tx.Exec(`
+			INSERT OR REPLACE INTO tls_certificates (
+				host, subject, issuer, sans, not_before, not_after, fingerprint, seen_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, cert.Host, cert.Subject, cert.Issuer, strings.Join(cert.DNSNames, " "),
+			cert.NotBefore, cert.NotAfter, cert.Fingerprint, seenAt)
+		if err != nil {
+			return fmt.Errorf("failed to save TLS certificate: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetExpiringCertificates returns the stored certificates that expire within
+// days of now, expired ones included, soonest first. A negative days returns
+// every certificate.
+func (s *SQLiteStorage) GetExpiringCertificates(days int, now time.Time) ([]Certificate, error) {
+	rows, err := s.read.Query(`
+		SELECT host, COALESCE(subject, ''), COALESCE(issuer, ''), COALESCE(sans, ''),
+		       not_before, not_after, fingerprint, seen_at
+		FROM tls_certificates
+		ORDER BY not_after, host
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query TLS certificates: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var certs []Certificate
+	for rows.Next() {
+		var cert Certificate
+		var sans string
+		var notBefore sql.NullTime
+		if err := rows.Scan(&cert.Host, &cert.Subject, &cert.Issuer, &sans,
+			&notBefore, &cert.NotAfter, &cert.Fingerprint, &cert.SeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan TLS certificate: %w", err)
+		}
+		cert.NotBefore = notBefore.Time
+		cert.DNSNames = strings.Fields(sans)
+		if cert.DNSNames == nil {
+			cert.DNSNames = []string{}
+		}
+		cert.DaysLeft = int(math.Floor(cert.NotAfter.Sub(now).Hours() / 24))
+		if days < 0 || cert.NotAfter.Before(now.AddDate(0, 0, days)) {
+			certs = append(certs, cert)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read TLS certificates: %w", err)
+	}
+	return certs, nil
+}
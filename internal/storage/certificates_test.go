@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestGetExpiringCertificates(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "certificates.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cert := func(host string, days int) crawler.TLSCertificate {
+		return crawler.TLSCertificate{
+			Host:        host,
+			Subject:     "CN=" + host,
+			Issuer:      "CN=Test CA",
+			DNSNames:    []string{host, "www." + host},
+			NotBefore:   now.AddDate(0, -3, 0),
+			NotAfter:    now.AddDate(0, 0, days),
+			Fingerprint: host + "-v1",
+		}
+	}
+	pages := map[string][]crawler.TLSCertificate{
+		"https://soon.example/":    {cert("soon.example", 10)},
+		"https://later.example/":   {cert("later.example", 90)},
+		"https://expired.example/": {cert("expired.example", -2), cert("cdn.example", 20)},
+	}
+	if err := store.AddToQueue([]string{"https://soon.example/", "https://later.example/", "https://expired.example/"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range pages {
+		item, _ := store.GetNextFromQueue()
+		page := &crawler.PageData{URL: item.URL, StatusCode: 200, HTTPHeaders: map[string]string{}, CrawledAt: now,
+			Certificates: pages[item.URL]}
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	certs, err := store.GetExpiringCertificates(30, now)
+	if err != nil {
+		t.Fatalf("GetExpiringCertificates failed: %v", err)
+	}
+	var hosts []string
+	var daysLeft []int
+	for _, c := range certs {
+		hosts = append(hosts, c.Host)
+		daysLeft = append(daysLeft, c.DaysLeft)
+	}
+	if len(hosts) != 3 || hosts[0] != "expired.example" || hosts[1] != "soon.example" || hosts[2] != "cdn.example" {
+		t.Fatalf("Expected expiring certificates soonest first, got %v", hosts)
+	}
+	if daysLeft[0] != -2 || daysLeft[1] != 10 || daysLeft[2] != 20 {
+		t.Errorf("Unexpected days left %v", daysLeft)
+	}
+	if got := certs[1]; got.Subject != "CN=soon.example" || got.Issuer != "CN=Test CA" ||
+		len(got.DNSNames) != 2 || got.DNSNames[1] != "www.soon.example" || !got.NotBefore.Equal(now.AddDate(0, -3, 0)) {
+		t.Errorf("Unexpected certificate %+v", got)
+	}
+
+	if certs, err = store.GetExpiringCertificates(-1, now); err != nil || len(certs) != 4 {
+		t.Errorf("Expected every certificate, got %d (%v)", len(certs), err)
+	}
+
+	// A renewed certificate replaces the host's row
+	renewed := cert("soon.example", 365)
+	renewed.Fingerprint = "soon.example-v2"
+	if err := store.AddToQueue([]string{"https://soon.example/renewed"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	item, _ := store.GetNextFromQueue()
+	if err := store.SavePageResult(item.ID, &crawler.PageData{URL: item.URL, StatusCode: 200, HTTPHeaders: map[string]string{},
+		CrawledAt: now, Certificates: []crawler.TLSCertificate{renewed}}); err != nil {
+		t.Fatalf("Failed to save renewed certificate: %v", err)
+	}
+	if certs, err = store.GetExpiringCertificates(30, now); err != nil || len(certs) != 2 {
+		t.Errorf("Expected the renewed certificate to no longer expire soon, got %+v (%v)", certs, err)
+	}
+}
@@ -0,0 +1,124 @@
+// Package storage — click depth.
+//
+// Click depth is the fewest links a visitor follows from a seed URL to reach
+// a page. Pages buried deep in a site are crawled less often by search
+// engines and are harder for visitors to find, so `linktadoru analyze depth`
+// stores every page's depth in page_metrics and reports the deep ones.
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// DeepPage is a page deeper than the click depth limit, or unreachable
+type DeepPage struct {
+	URL        string `json:"url"`
+	Depth      int    `json:"depth"`       // -1 when no seed URL links to the page
+	StatusCode int    `json:"status_code"` // 0 when not fetched
+}
+
+// CrawlRoots returns the URLs click depth is counted from: the seed URLs
+// recorded by the crawls on the database or, for databases written before
+// seeds were recorded, the first URL queued
+func (s *SQLiteStorage) CrawlRoots() ([]string, error) {
+	value, err := s.GetMeta(crawler.MetaSeedURLs)
+	if err != nil {
+		return nil, err
+	}
+	if value != "" {
+		var seeds []string
+		if err := json.Unmarshal([]byte(value), &seeds); err != nil {
+			return nil, fmt.Errorf("invalid %s in crawl_meta: %w", crawler.MetaSeedURLs, err)
+		}
+		if len(seeds) > 0 {
+			return seeds, nil
+		}
+	}
+
+	var first string
+	err = s.db.QueryRow("SELECT url FROM pages WHERE status != 'discovered' ORDER BY id LIMIT 1").Scan(&first)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query first queued URL: %w", err)
+	}
+	return []string{first}, nil
+}
+
+// ComputeClickDepths computes the click depth of every page from roots
+// (CrawlRoots when empty) over the non-asset links, stores it and returns the
+// number of pages given a depth
+func (s *SQLiteStorage) ComputeClickDepths(roots []string) (int, error) {
+	if len(roots) == 0 {
+		var err error
+		if roots, err = s.CrawlRoots(); err != nil {
+			return 0, err
+		}
+	}
+	graph, err := s.LoadLinkGraph(false)
+	if err != nil {
+		return 0, err
+	}
+	graph.SetDepths(roots)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("UPDATE page_metrics SET click_depth = NULL"); err != nil {
+		return 0, fmt.Errorf("failed to clear click depths: %w", err)
+	}
+	stmt, err := tx.Prepare(`
+		INSERT INTO page_metrics (page_id, click_depth) VALUES (?, ?)
+		ON CONFLICT(page_id) DO UPDATE SET click_depth = excluded.click_depth
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare click depth statement: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+	for _, node := range graph.Nodes {
+		if _, err := stmt.Exec(node.ID, node.Depth); err != nil {
+			return 0, fmt.Errorf("failed to save click depth: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit click depths: %w", err)
+	}
+	return len(graph.Nodes), nil
+}
+
+// GetDeepPages returns the pages selected for crawling whose stored click
+// depth exceeds maxDepth, deepest first, followed by the unreachable ones
+func (s *SQLiteStorage) GetDeepPages(maxDepth int) ([]DeepPage, error) {
+	rows, err := s.db.Query(`
+		SELECT p.url, m.click_depth, COALESCE(p.status_code, 0)
+		FROM page_metrics m
+		JOIN pages p ON p.id = m.page_id
+		WHERE p.status != 'discovered' AND (m.click_depth > ? OR m.click_depth = -1)
+		ORDER BY m.click_depth = -1, m.click_depth DESC, p.url
+	`, maxDepth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query click depths: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var pages []DeepPage
+	for rows.Next() {
+		var page DeepPage
+		if err := rows.Scan(&page.URL, &page.Depth, &page.StatusCode); err != nil {
+			return nil, fmt.Errorf("failed to scan click depth: %w", err)
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read click depths: %w", err)
+	}
+	return pages, nil
+}
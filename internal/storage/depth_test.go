@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestComputeClickDepths(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "depth.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.AddToQueue([]string{"https://example.com/", "https://example.com/orphan"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	// Without recorded seeds, depth is counted from the first URL queued
+	if roots, err := store.CrawlRoots(); err != nil || len(roots) != 1 || roots[0] != "https://example.com/" {
+		t.Errorf("Expected the first queued URL as root, got %v (%v)", roots, err)
+	}
+
+	var chain []*crawler.LinkData
+	for _, pair := range [][2]string{{"/", "/a"}, {"/a", "/b"}, {"/b", "/c"}, {"/c", "/d"}} {
+		chain = append(chain, &crawler.LinkData{
+			SourceURL: "https://example.com" + pair[0], TargetURL: "https://example.com" + pair[1], LinkType: "internal",
+		})
+	}
+	if err := store.SaveLinks(chain); err != nil {
+		t.Fatalf("Failed to save links: %v", err)
+	}
+	if err := store.AddToQueue([]string{"https://example.com/c", "https://example.com/d"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+
+	if n, err := store.ComputeClickDepths(nil); err != nil || n != 6 {
+		t.Fatalf("Expected depths for 6 pages, got %d (%v)", n, err)
+	}
+	pages, err := store.GetDeepPages(2)
+	if err != nil {
+		t.Fatalf("GetDeepPages failed: %v", err)
+	}
+	// /a and /b are within the limit; /c and /d are deep; the orphan is unreachable
+	want := []DeepPage{
+		{URL: "https://example.com/d", Depth: 4},
+		{URL: "https://example.com/c", Depth: 3},
+		{URL: "https://example.com/orphan", Depth: -1},
+	}
+	if len(pages) != len(want) {
+		t.Fatalf("Expected %+v, got %+v", want, pages)
+	}
+	for i := range want {
+		if pages[i] != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], pages[i])
+		}
+	}
+
+	// Recorded seeds take precedence
+	if err := store.SetMeta(crawler.MetaSeedURLs, `["https://example.com/orphan","https://example.com/b"]`); err != nil {
+		t.Fatalf("Failed to record seeds: %v", err)
+	}
+	if _, err := store.ComputeClickDepths(nil); err != nil {
+		t.Fatalf("ComputeClickDepths failed: %v", err)
+	}
+	var depth int
+	if err := store.db.QueryRow(`
+		SELECT m.click_depth FROM page_metrics m JOIN pages p ON p.id = m.page_id WHERE p.url = ?
+	`, "https://example.com/d").Scan(&depth); err != nil || depth != 2 {
+		t.Errorf("Expected depth 2 from the recorded seeds, got %d (%v)", depth, err)
+	}
+}
+
+func TestMigratePageMetricsAddClickDepth(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	_, err = store.db.Exec(`
+		DROP TABLE page_metrics;
+		CREATE TABLE page_metrics (page_id INTEGER PRIMARY KEY, pagerank REAL);
+		INSERT INTO page_metrics (page_id, pagerank) VALUES (1, 0.5);
+	`)
+	if err != nil {
+		t.Fatalf("Failed to build legacy table: %v", err)
+	}
+	if err := store.SetMeta(metaSchemaVersion, "17"); err != nil {
+		t.Fatalf("Failed to record legacy schema version: %v", err)
+	}
+
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if _, hasColumn, err := columnState(store.db, "page_metrics", "click_depth"); err != nil || !hasColumn {
+		t.Errorf("Expected page_metrics.click_depth after migration (%v)", err)
+	}
+}
@@ -0,0 +1,131 @@
+// Package storage — differences between two crawls.
+//
+// DiffCrawls compares the pages two crawls reached (completed, errored or
+// skipped) by URL, so a crawl before and after a release shows what appeared,
+// what disappeared and which pages changed their answer, title, meta
+// description or content. Pages only queued or discovered in either crawl
+// are not compared.
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Kinds of PageChange, in report order
+const (
+	DiffAdded           = "added"
+	DiffRemoved         = "removed"
+	DiffStatus          = "status"
+	DiffTitle           = "title"
+	DiffMetaDescription = "meta_description"
+	DiffContent         = "content"
+)
+
+// DiffKinds are the kinds of change DiffCrawls reports, in report order
+var DiffKinds = []string{DiffAdded, DiffRemoved, DiffStatus, DiffTitle, DiffMetaDescription, DiffContent}
+
+// PageSnapshot is the state of a crawled page that DiffCrawls compares
+type PageSnapshot struct {
+	Status      string // HTTP status code of a completed page, else the page status ("error", "skipped")
+	Title       string
+	MetaDesc    string
+	ContentHash string
+	completed   bool
+}
+
+// PageChange is one difference between two crawls
+type PageChange struct {
+	Change string `json:"change"` // One of DiffKinds
+	URL    string `json:"url"`
+	Old    string `json:"old"` // Value in the old crawl; "" for added pages
+	New    string `json:"new"` // Value in the new crawl; "" for removed pages
+}
+
+// GetPageSnapshots returns the compared state of every completed, errored or
+// skipped page by URL
+func (s *SQLiteStorage) GetPageSnapshots() (map[string]PageSnapshot, error) {
+	rows, err := s.read.Query(`
+		SELECT url, status, status_code, title, meta_description, content_hash
+		FROM pages
+		WHERE status IN ('completed', 'error', 'skipped')
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pages: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	snapshots := make(map[string]PageSnapshot)
+	for rows.Next() {
+		var url, status string
+		var statusCode sql.NullInt64
+		var title, metaDesc, contentHash sql.NullString
+		if err := rows.Scan(&url, &status, &statusCode, &title, &metaDesc, &contentHash); err != nil {
+			return nil, fmt.Errorf("failed to scan page: %w", err)
+		}
+		snap := PageSnapshot{Status: status, ContentHash: contentHash.String}
+		if status == "completed" && statusCode.Valid {
+			snap.Status = strconv.FormatInt(statusCode.Int64, 10)
+			snap.completed = true
+		}
+		if snap.Title, err = s.DecryptField(title.String); err != nil {
+			return nil, err
+		}
+		if snap.MetaDesc, err = s.DecryptField(metaDesc.String); err != nil {
+			return nil, err
+		}
+		snapshots[url] = snap
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pages: %w", err)
+	}
+	return snapshots, nil
+}
+
+// DiffCrawls returns the differences from the crawl before to the crawl
+// after, ordered by kind (DiffKinds) and URL. Titles, meta descriptions and
+// content are only compared for pages completed in both crawls.
+func DiffCrawls(before, after map[string]PageSnapshot) []PageChange {
+	var changes []PageChange
+	for url, n := range after {
+		o, ok := before[url]
+		if !ok {
+			changes = append(changes, PageChange{Change: DiffAdded, URL: url, New: n.Status})
+			continue
+		}
+		if o.Status != n.Status {
+			changes = append(changes, PageChange{Change: DiffStatus, URL: url, Old: o.Status, New: n.Status})
+		}
+		if !o.completed || !n.completed {
+			continue
+		}
+		if o.Title != n.Title {
+			changes = append(changes, PageChange{Change: DiffTitle, URL: url, Old: o.Title, New: n.Title})
+		}
+		if o.MetaDesc != n.MetaDesc {
+			changes = append(changes, PageChange{Change: DiffMetaDescription, URL: url, Old: o.MetaDesc, New: n.MetaDesc})
+		}
+		if o.ContentHash != n.ContentHash {
+			changes = append(changes, PageChange{Change: DiffContent, URL: url, Old: o.ContentHash, New: n.ContentHash})
+		}
+	}
+	for url, o := range before {
+		if _, ok := after[url]; !ok {
+			changes = append(changes, PageChange{Change: DiffRemoved, URL: url, Old: o.Status})
+		}
+	}
+
+	order := make(map[string]int, len(DiffKinds))
+	for i, kind := range DiffKinds {
+		order[kind] = i
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Change != changes[j].Change {
+			return order[changes[i].Change] < order[changes[j].Change]
+		}
+		return changes[i].URL < changes[j].URL
+	})
+	return changes
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// crawlSnapshots saves pages, by URL, into a new database and returns its snapshots
+func crawlSnapshots(t *testing.T, pages map[string]*crawler.PageData, failed ...string) map[string]PageSnapshot {
+	t.Helper()
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "crawl.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	var urls []string
+	for url := range pages {
+		urls = append(urls, url)
+	}
+	_ = store.AddToQueue(append(urls, failed...))
+	_ = store.AddToQueue([]string{"https://example.com/queued"})
+	for range len(urls) + len(failed) {
+		item, _ := store.GetNextFromQueue()
+		if page, ok := pages[item.URL]; ok {
+			page.URL, page.HTTPHeaders, page.CrawledAt = item.URL, map[string]string{}, time.Now()
+			if err := store.SavePageResult(item.ID, page); err != nil {
+				t.Fatalf("Failed to save page: %v", err)
+			}
+		} else if err := store.SavePageError(item.ID, "network_timeout", "timeout"); err != nil {
+			t.Fatalf("Failed to save error: %v", err)
+		}
+	}
+
+	snapshots, err := store.GetPageSnapshots()
+	if err != nil {
+		t.Fatalf("GetPageSnapshots failed: %v", err)
+	}
+	return snapshots
+}
+
+func TestDiffCrawls(t *testing.T) {
+	before := crawlSnapshots(t, map[string]*crawler.PageData{
+		"https://example.com/":      {StatusCode: 200, Title: "Home", MetaDesc: "Welcome", ContentHash: "a"},
+		"https://example.com/old":   {StatusCode: 200, Title: "Old"},
+		"https://example.com/moved": {StatusCode: 200, Title: "Moved"},
+		"https://example.com/down":  {StatusCode: 200, Title: "Down"},
+	})
+	after := crawlSnapshots(t, map[string]*crawler.PageData{
+		"https://example.com/":      {StatusCode: 200, Title: "Start", MetaDesc: "Hello", ContentHash: "b"},
+		"https://example.com/moved": {StatusCode: 404},
+		"https://example.com/new":   {StatusCode: 200, Title: "New"},
+	}, "https://example.com/down")
+
+	if _, ok := after["https://example.com/queued"]; ok {
+		t.Error("Expected queued pages not to be compared")
+	}
+
+	got := DiffCrawls(before, after)
+	want := []PageChange{
+		{Change: DiffAdded, URL: "https://example.com/new", New: "200"},
+		{Change: DiffRemoved, URL: "https://example.com/old", Old: "200"},
+		{Change: DiffStatus, URL: "https://example.com/down", Old: "200", New: "error"},
+		{Change: DiffStatus, URL: "https://example.com/moved", Old: "200", New: "404"},
+		{Change: DiffTitle, URL: "https://example.com/", Old: "Home", New: "Start"},
+		{Change: DiffTitle, URL: "https://example.com/moved", Old: "Moved", New: ""},
+		{Change: DiffMetaDescription, URL: "https://example.com/", Old: "Welcome", New: "Hello"},
+		{Change: DiffContent, URL: "https://example.com/", Old: "a", New: "b"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected changes:\n got %+v\nwant %+v", got, want)
+	}
+
+	if changes := DiffCrawls(before, before); len(changes) != 0 {
+		t.Errorf("Expected no changes between identical crawls, got %+v", changes)
+	}
+}
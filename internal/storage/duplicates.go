@@ -0,0 +1,101 @@
+// Package storage — duplicate pages.
+//
+// Pages sharing a title or meta description compete with each other in
+// search results, and pages sharing a content hash are byte-identical copies
+// served under several URLs. `linktadoru analyze duplicates` groups the
+// completed pages by each of these values.
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Values pages are grouped by in GetDuplicates, as named in the FIELD column
+// of `linktadoru analyze duplicates`
+const (
+	DuplicateByTitle       = "title"
+	DuplicateByDescription = "meta_description"
+	DuplicateByContentHash = "content_hash"
+)
+
+// DuplicateFields lists the values pages are grouped by, in report order
+var DuplicateFields = []string{DuplicateByTitle, DuplicateByDescription, DuplicateByContentHash}
+
+// DuplicateGroup is a set of pages sharing the value of one field
+type DuplicateGroup struct {
+	Field string   `json:"field"` // One of the DuplicateFields
+	Value string   `json:"value"`
+	Pages int      `json:"pages"`
+	URLs  []string `json:"urls"` // Example URLs, in URL order
+}
+
+// GetDuplicates groups the successfully crawled pages sharing a non-empty
+// title, meta description or content hash, for each of fields. Groups are
+// ordered by field, then largest first, then by value; each lists up to
+// examples URLs (0 = all). Titles and descriptions are compared after
+// decryption, since encrypted values never match.
+func (s *SQLiteStorage) GetDuplicates(fields []string, examples int) ([]DuplicateGroup, error) {
+	rows, err := s.read.Query(`
+		SELECT url, COALESCE(title, ''), COALESCE(meta_description, ''), COALESCE(content_hash, '')
+		FROM pages
+		WHERE status = 'completed' AND status_code < 300
+		ORDER BY url
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pages: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	byValue := map[string]map[string][]string{}
+	for _, field := range DuplicateFields {
+		byValue[field] = map[string][]string{}
+	}
+	for rows.Next() {
+		var url, title, description, hash string
+		if err := rows.Scan(&url, &title, &description, &hash); err != nil {
+			return nil, fmt.Errorf("failed to scan page: %w", err)
+		}
+		if title, err = s.DecryptField(title); err != nil {
+			return nil, err
+		}
+		if description, err = s.DecryptField(description); err != nil {
+			return nil, err
+		}
+		for field, value := range map[string]string{DuplicateByTitle: title, DuplicateByDescription: description, DuplicateByContentHash: hash} {
+			if value != "" {
+				byValue[field][value] = append(byValue[field][value], url)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pages: %w", err)
+	}
+
+	var groups []DuplicateGroup
+	for _, field := range DuplicateFields {
+		if !slices.Contains(fields, field) {
+			continue
+		}
+		start := len(groups)
+		for value, urls := range byValue[field] {
+			if len(urls) < 2 {
+				continue
+			}
+			group := DuplicateGroup{Field: field, Value: value, Pages: len(urls), URLs: urls}
+			if examples > 0 && len(urls) > examples {
+				group.URLs = urls[:examples]
+			}
+			groups = append(groups, group)
+		}
+		fieldGroups := groups[start:]
+		sort.Slice(fieldGroups, func(i, j int) bool {
+			if fieldGroups[i].Pages != fieldGroups[j].Pages {
+				return fieldGroups[i].Pages > fieldGroups[j].Pages
+			}
+			return fieldGroups[i].Value < fieldGroups[j].Value
+		})
+	}
+	return groups, nil
+}
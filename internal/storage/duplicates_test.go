@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestGetDuplicates(t *testing.T) {
+	// Encrypted titles and descriptions must still group
+	store, err := NewSQLiteStorageWithPassphrase(filepath.Join(t.TempDir(), "duplicates.db"), "correct horse")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	pages := map[string]*crawler.PageData{
+		"https://example.com/a":     {StatusCode: 200, Title: "Shop", MetaDesc: "Buy things", ContentHash: "h1"},
+		"https://example.com/a?s=1": {StatusCode: 200, Title: "Shop", MetaDesc: "Buy things", ContentHash: "h1"},
+		"https://example.com/b":     {StatusCode: 200, Title: "Shop", MetaDesc: "Other", ContentHash: "h2"},
+		"https://example.com/c":     {StatusCode: 200, Title: "About", ContentHash: "h3"},
+		"https://example.com/d":     {StatusCode: 200, Title: "About", ContentHash: "h4"},
+		"https://example.com/old":   {StatusCode: 301, Title: "Shop", ContentHash: "h1"},
+	}
+	var urls []string
+	for u := range pages {
+		urls = append(urls, u)
+	}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range pages {
+		item, _ := store.GetNextFromQueue()
+		page := pages[item.URL]
+		page.URL, page.HTTPHeaders, page.CrawledAt = item.URL, map[string]string{}, time.Now()
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	groups, err := store.GetDuplicates(DuplicateFields, 2)
+	if err != nil {
+		t.Fatalf("GetDuplicates failed: %v", err)
+	}
+	want := []DuplicateGroup{
+		{Field: DuplicateByTitle, Value: "Shop", Pages: 3, URLs: []string{"https://example.com/a", "https://example.com/a?s=1"}},
+		{Field: DuplicateByTitle, Value: "About", Pages: 2, URLs: []string{"https://example.com/c", "https://example.com/d"}},
+		{Field: DuplicateByDescription, Value: "Buy things", Pages: 2, URLs: []string{"https://example.com/a", "https://example.com/a?s=1"}},
+		{Field: DuplicateByContentHash, Value: "h1", Pages: 2, URLs: []string{"https://example.com/a", "https://example.com/a?s=1"}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("GetDuplicates() = %+v, want %+v", groups, want)
+	}
+
+	groups, err = store.GetDuplicates([]string{DuplicateByContentHash}, 0)
+	if err != nil {
+		t.Fatalf("GetDuplicates failed: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Field != DuplicateByContentHash {
+		t.Errorf("Expected only content hash groups, got %+v", groups)
+	}
+}
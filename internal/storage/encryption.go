@@ -0,0 +1,154 @@
+// Package storage — application-level encryption of sensitive columns.
+//
+// When a passphrase is supplied, free-text columns that may carry content from
+// authenticated or internal sites (page titles, meta descriptions, anchor text
+// and error messages) are encrypted with AES-256-GCM before they are written.
+// URLs are left in clear text because they are the queue and link-graph keys,
+// and response headers are left in clear text because generated columns are
+// derived from them.
+//
+// The key is derived from the passphrase with scrypt. The salt and an encrypted
+// check value are kept in crawl_meta, so a database can only be reopened with
+// the passphrase it was created with.
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// encryptedPrefix marks a column value written by encryptField
+	encryptedPrefix = "enc:v1:"
+
+	metaEncryptionSalt  = "encryption_salt"
+	metaEncryptionCheck = "encryption_check"
+	encryptionCheckText = "linktadoru"
+)
+
+var (
+	// ErrPassphraseRequired is returned when an encrypted database is opened without a passphrase
+	ErrPassphraseRequired = errors.New("database is encrypted: a passphrase is required")
+	// ErrWrongPassphrase is returned when the passphrase does not match the one the database was created with
+	ErrWrongPassphrase = errors.New("database passphrase is incorrect")
+)
+
+// initEncryption sets up column encryption for the opened database. With an
+// empty passphrase it only verifies the database is not encrypted. Otherwise
+// it derives the key, creating and storing a salt on first use, and verifies
+// the passphrase against the stored check value.
+func (s *SQLiteStorage) initEncryption(passphrase string) error {
+	encodedSalt, err := s.GetMeta(metaEncryptionSalt)
+	if err != nil {
+		return err
+	}
+
+	if passphrase == "" {
+		if encodedSalt != "" {
+			return ErrPassphraseRequired
+		}
+		return nil
+	}
+	if encodedSalt == "" && s.readOnly {
+		return nil // Nothing was encrypted, and a read-only database is not set up for it
+	}
+
+	var salt []byte
+	if encodedSalt == "" {
+		salt = make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("failed to generate encryption salt: %w", err)
+		}
+	} else {
+		salt, err = base64.StdEncoding.DecodeString(encodedSalt)
+		if err != nil {
+			return fmt.Errorf("failed to decode encryption salt: %w", err)
+		}
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return fmt.Errorf("failed to derive encryption key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("failed to create cipher: %w", err)
+	}
+	s.aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return fmt.Errorf("failed to create GCM cipher: %w", err)
+	}
+
+	if encodedSalt == "" {
+		check, err := s.encryptField(encryptionCheckText)
+		if err != nil {
+			return err
+		}
+		if err := s.SetMeta(metaEncryptionSalt, base64.StdEncoding.EncodeToString(salt)); err != nil {
+			return err
+		}
+		return s.SetMeta(metaEncryptionCheck, check)
+	}
+
+	check, err := s.GetMeta(metaEncryptionCheck)
+	if err != nil {
+		return err
+	}
+	if plain, err := s.DecryptField(check); err != nil || plain != encryptionCheckText {
+		return ErrWrongPassphrase
+	}
+	return nil
+}
+
+// IsEncrypted reports whether sensitive columns are encrypted
+func (s *SQLiteStorage) IsEncrypted() bool {
+	return s.aead != nil
+}
+
+// encryptField encrypts a column value. Empty values and values on an
+// unencrypted database are returned unchanged.
+func (s *SQLiteStorage) encryptField(plain string) (string, error) {
+	if s.aead == nil || plain == "" {
+		return plain, nil
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptField reverses encryptField for callers that read encrypted columns
+// directly. Values without the encrypted prefix (written before encryption was
+// enabled) are returned unchanged.
+func (s *SQLiteStorage) DecryptField(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	if s.aead == nil {
+		return "", ErrPassphraseRequired
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode encrypted value: %w", err)
+	}
+	nonceSize := s.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", fmt.Errorf("encrypted value too short")
+	}
+	plain, err := s.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestEncryptedStorage(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "encrypted.db")
+
+	store, err := NewSQLiteStorageWithPassphrase(dbPath, "correct horse")
+	if err != nil {
+		t.Fatalf("Failed to create encrypted storage: %v", err)
+	}
+	if !store.IsEncrypted() {
+		t.Fatal("Expected storage to be encrypted")
+	}
+
+	if err := store.AddToQueue([]string{"https://example.com/"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	item, err := store.GetNextFromQueue()
+	if err != nil || item == nil {
+		t.Fatalf("Failed to get queue item: %v", err)
+	}
+	if err := store.SavePageResult(item.ID, &crawler.PageData{
+		URL:         item.URL,
+		StatusCode:  200,
+		Title:       "Internal Payroll",
+		HTTPHeaders: map[string]string{"content-type": "text/html"},
+		CrawledAt:   time.Now(),
+	}); err != nil {
+		t.Fatalf("Failed to save page: %v", err)
+	}
+	if err := store.SaveLinks([]*crawler.LinkData{{
+		SourceURL:  item.URL,
+		TargetURL:  "https://example.com/salaries",
+		AnchorText: "Salaries",
+		LinkType:   "internal",
+		CrawledAt:  time.Now(),
+	}}); err != nil {
+		t.Fatalf("Failed to save links: %v", err)
+	}
+
+	var rawTitle, rawAnchor string
+	if err := store.db.QueryRow("SELECT title FROM pages WHERE id = ?", item.ID).Scan(&rawTitle); err != nil {
+		t.Fatalf("Failed to read title: %v", err)
+	}
+	if err := store.db.QueryRow("SELECT anchor_text FROM link_relations").Scan(&rawAnchor); err != nil {
+		t.Fatalf("Failed to read anchor text: %v", err)
+	}
+	for _, raw := range []string{rawTitle, rawAnchor} {
+		if !strings.HasPrefix(raw, encryptedPrefix) {
+			t.Errorf("Expected encrypted column value, got %q", raw)
+		}
+	}
+
+	if title, err := store.DecryptField(rawTitle); err != nil || title != "Internal Payroll" {
+		t.Errorf("DecryptField() = %q, %v; want %q", title, err, "Internal Payroll")
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Failed to close storage: %v", err)
+	}
+
+	if _, err := NewSQLiteStorage(dbPath); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("Expected ErrPassphraseRequired, got %v", err)
+	}
+	if _, err := NewSQLiteStorageWithPassphrase(dbPath, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+
+	reopened, err := NewSQLiteStorageWithPassphrase(dbPath, "correct horse")
+	if err != nil {
+		t.Fatalf("Failed to reopen encrypted storage: %v", err)
+	}
+	defer func() { _ = reopened.Close() }()
+	if title, err := reopened.DecryptField(rawTitle); err != nil || title != "Internal Payroll" {
+		t.Errorf("DecryptField() after reopen = %q, %v", title, err)
+	}
+}
+
+func TestUnencryptedStorageLeavesFieldsPlain(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "plain.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if store.IsEncrypted() {
+		t.Error("Expected storage not to be encrypted")
+	}
+	if got, err := store.encryptField("plain"); err != nil || got != "plain" {
+		t.Errorf("encryptField() = %q, %v; want unchanged", got, err)
+	}
+}
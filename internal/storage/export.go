@@ -0,0 +1,339 @@
+// Package storage — row export.
+//
+// `linktadoru export` writes the pages, links and errors of a crawl to files.
+// ExportRows streams the rows one at a time from the read pool, so exporting a
+// large crawl never holds the result set in memory.
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tables accepted by ExportRows
+const (
+	ExportPages  = "pages"
+	ExportLinks  = "links"
+	ExportErrors = "errors"
+)
+
+var (
+	// ErrUnknownExportTable is returned for a table ExportRows cannot export
+	ErrUnknownExportTable = errors.New("unknown export table")
+	// ErrUnknownExportColumn is returned for a column the export table does not have
+	ErrUnknownExportColumn = errors.New("unknown export column")
+	// ErrStatusFilterUnsupported is returned when a status filter is given for the errors table
+	ErrStatusFilterUnsupported = errors.New("status filtering is not supported for this table")
+)
+
+// exportColumn is a column of an export table
+type exportColumn struct {
+	name      string
+	expr      string // SQL expression selecting the column
+	encrypted bool   // Written with encryptField
+	extra     bool   // Exported only when selected explicitly
+}
+
+// exportTable describes how an export table is queried. A status filter
+// applies to the page named by status (and status code), if any.
+type exportTable struct {
+	from       string
+	status     string
+	statusCode string
+	orderBy    string
+	columns    []exportColumn
+}
+
+var exportTables = map[string]exportTable{
+	ExportPages: {
+		from:       "pages p",
+		status:     "p.status",
+		statusCode: "p.status_code",
+		orderBy:    "p.id",
+		columns: []exportColumn{
+			{name: "id", expr: "p.id"},
+			{name: "url", expr: "p.url"},
+			{name: "status", expr: "p.status"},
+			{name: "status_code", expr: "p.status_code"},
+			{name: "title", expr: "p.title", encrypted: true},
+			{name: "meta_description", expr: "p.meta_description", encrypted: true},
+			{name: "meta_robots", expr: "p.meta_robots"},
+			{name: "x_robots_tag", expr: "p.x_robots_tag"},
+			{name: "canonical_url", expr: "p.canonical_url"},
+			{name: "content_type", expr: "p.content_type"},
+			{name: "content_length", expr: "p.content_length"},
+			{name: "content_hash", expr: "p.content_hash"},
+			{name: "ttfb_ms", expr: "p.ttfb_ms"},
+			{name: "download_time_ms", expr: "p.download_time_ms"},
+			{name: "response_size_bytes", expr: "p.response_size_bytes"},
+			{name: "rendered", expr: "p.rendered"},
+			{name: "last_modified", expr: "p.last_modified"},
+			{name: "server", expr: "p.server"},
+			{name: "host", expr: "p.host"},
+			{name: "added_at", expr: "p.added_at"},
+			{name: "crawled_at", expr: "p.crawled_at"},
+			{name: "retry_count", expr: "p.retry_count"},
+			{name: "last_error_type", expr: "p.last_error_type"},
+			{name: "last_error_message", expr: "p.last_error_message", encrypted: true},
+			{name: "content_encoding", expr: "p.content_encoding", extra: true},
+			{name: "x_cache", expr: "p.x_cache", extra: true},
+			{name: "response_http_headers", expr: "p.response_http_headers", extra: true},
+		},
+	},
+	ExportLinks: {
+		from: `link_relations lr
+			JOIN pages s ON lr.source_page_id = s.id
+			JOIN pages t ON lr.target_page_id = t.id`,
+		status:     "t.status",
+		statusCode: "t.status_code",
+		orderBy:    "lr.id",
+		columns: []exportColumn{
+			{name: "id", expr: "lr.id"},
+			{name: "source_url", expr: "s.url"},
+			{name: "target_url", expr: "t.url"},
+			{name: "anchor_text", expr: "lr.anchor_text", encrypted: true},
+			{name: "link_type", expr: "lr.link_type"},
+			{name: "rel_attribute", expr: "lr.rel_attribute"},
+			{name: "target_status", expr: "t.status"},
+			{name: "target_status_code", expr: "t.status_code"},
+			{name: "crawled_at", expr: "lr.crawled_at"},
+		},
+	},
+	ExportErrors: {
+		from:    "crawl_errors e",
+		orderBy: "e.id",
+		columns: []exportColumn{
+			{name: "id", expr: "e.id"},
+			{name: "url", expr: "e.url"},
+			{name: "error_type", expr: "e.error_type"},
+			{name: "error_message", expr: "e.error_message", encrypted: true},
+			{name: "occurred_at", expr: "e.occurred_at"},
+		},
+	},
+}
+
+// ExportTables returns the tables ExportRows accepts
+func ExportTables() []string {
+	return []string{ExportPages, ExportLinks, ExportErrors}
+}
+
+// ExportColumns returns the columns exported from table when none are
+// selected. Pages also have the columns content_encoding, x_cache and
+// response_http_headers, exported only on request.
+func ExportColumns(table string) ([]string, error) {
+	t, ok := exportTables[table]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (must be one of %s)", ErrUnknownExportTable, table, strings.Join(ExportTables(), ", "))
+	}
+	var names []string
+	for _, c := range t.columns {
+		if !c.extra {
+			names = append(names, c.name)
+		}
+	}
+	return names, nil
+}
+
+// ExportFilter selects what ExportRows exports
+type ExportFilter struct {
+	Columns []string // Columns to export, in order; empty = ExportColumns(table)
+	// Statuses keeps only rows whose page has one of these crawl statuses
+	// ("completed", "error", ...) or HTTP status codes ("404"); for links it
+	// is the target page. Empty = all rows.
+	Statuses []string
+	Limit    int // Most rows exported; 0 = all
+}
+
+// ExportRows calls fn with the values of each row of table, in id order.
+// Values are nil, int64, float64 or string; timestamps are RFC 3339 strings
+// in UTC and encrypted columns are decrypted. Returning an error from fn
+// stops the export and returns that error.
+func (s *SQLiteStorage) ExportRows(table string, filter ExportFilter, fn func(values []any) error) error {
+	t, ok := exportTables[table]
+	if !ok {
+		return fmt.Errorf("%w: %q (must be one of %s)", ErrUnknownExportTable, table, strings.Join(ExportTables(), ", "))
+	}
+
+	columns, err := t.selected(table, filter)
+	if err != nil {
+		return err
+	}
+	exprs := make([]string, 0, len(columns))
+	for _, c := range columns {
+		exprs = append(exprs, c.expr)
+	}
+
+	query := "SELECT " + strings.Join(exprs, ", ") + " FROM " + t.from
+	var args []any
+	if len(filter.Statuses) > 0 {
+		var statuses, codes []any
+		for _, status := range filter.Statuses {
+			if code, err := strconv.Atoi(status); err == nil {
+				codes = append(codes, code)
+			} else {
+				statuses = append(statuses, status)
+			}
+		}
+		var conds []string
+		if len(statuses) > 0 {
+			conds = append(conds, t.status+" IN ("+placeholders(len(statuses))+")")
+		}
+		if len(codes) > 0 {
+			conds = append(conds, t.statusCode+" IN ("+placeholders(len(codes))+")")
+		}
+		args = append(statuses, codes...)
+		query += " WHERE " + strings.Join(conds, " OR ")
+	}
+	query += " ORDER BY " + t.orderBy
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.read.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", table, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("failed to scan %s row: %w", table, err)
+		}
+		for i, c := range columns {
+			if values[i], err = s.exportValue(values[i], c.encrypted); err != nil {
+				return err
+			}
+		}
+		if err := fn(values); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	return nil
+}
+
+// WriteJSONL writes the rows of table as JSON Lines: one JSON object per row,
+// with the columns as keys in column order. NULL values are written as null.
+func (s *SQLiteStorage) WriteJSONL(w io.Writer, table string, filter ExportFilter) error {
+	if len(filter.Columns) == 0 {
+		columns, err := ExportColumns(table)
+		if err != nil {
+			return err
+		}
+		filter.Columns = columns
+	}
+
+	bw := bufio.NewWriter(w)
+	keys := make([][]byte, len(filter.Columns))
+	for i, column := range filter.Columns {
+		key, err := json.Marshal(column)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+
+	var line []byte
+	err := s.ExportRows(table, filter, func(values []any) error {
+		line = append(line[:0], '{')
+		for i, value := range values {
+			if i > 0 {
+				line = append(line, ',')
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s: %w", filter.Columns[i], err)
+			}
+			line = append(line, keys[i]...)
+			line = append(line, ':')
+			line = append(line, encoded...)
+		}
+		line = append(line, '}', '\n')
+		_, err := bw.Write(line)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// CheckExportFilter returns the error ExportRows would return for table and
+// filter before reading any row, so commands can reject bad flags up front
+func CheckExportFilter(table string, filter ExportFilter) error {
+	t, ok := exportTables[table]
+	if !ok {
+		return fmt.Errorf("%w: %q (must be one of %s)", ErrUnknownExportTable, table, strings.Join(ExportTables(), ", "))
+	}
+	_, err := t.selected(table, filter)
+	return err
+}
+
+// selected returns the columns filter selects, checking the filter applies to the table
+func (t exportTable) selected(table string, filter ExportFilter) ([]exportColumn, error) {
+	if len(filter.Statuses) > 0 && t.status == "" {
+		return nil, fmt.Errorf("%w: %s", ErrStatusFilterUnsupported, table)
+	}
+	var columns []exportColumn
+	for _, c := range t.columns {
+		if len(filter.Columns) == 0 && !c.extra {
+			columns = append(columns, c)
+		}
+	}
+	for _, name := range filter.Columns {
+		c, ok := t.column(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q in %s", ErrUnknownExportColumn, name, table)
+		}
+		columns = append(columns, c)
+	}
+	return columns, nil
+}
+
+// placeholders returns n comma-separated query parameter placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// column returns the export column named name
+func (t exportTable) column(name string) (exportColumn, bool) {
+	for _, c := range t.columns {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return exportColumn{}, false
+}
+
+// exportValue normalises a scanned value for ExportRows
+func (s *SQLiteStorage) exportValue(value any, encrypted bool) (any, error) {
+	switch v := value.(type) {
+	case []byte:
+		value = string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339), nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	}
+	if text, ok := value.(string); ok && encrypted {
+		return s.DecryptField(text)
+	}
+	return value, nil
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestExportRows(t *testing.T) {
+	store, err := NewSQLiteStorageWithPassphrase(filepath.Join(t.TempDir(), "export.db"), "secret")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	crawledAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.AddToQueue([]string{"https://example.com/", "https://example.com/missing"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for _, page := range []*crawler.PageData{
+		{URL: "https://example.com/", StatusCode: 200, Title: "Home", HTTPHeaders: map[string]string{}, CrawledAt: crawledAt},
+		{URL: "https://example.com/missing", StatusCode: 404, HTTPHeaders: map[string]string{}, CrawledAt: crawledAt},
+	} {
+		item, _ := store.GetNextFromQueue()
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save page: %v", err)
+		}
+	}
+	if err := store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/missing", AnchorText: "Gone", LinkType: "internal"},
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/about", AnchorText: "About", LinkType: "internal"},
+	}); err != nil {
+		t.Fatalf("Failed to save links: %v", err)
+	}
+
+	var rows [][]any
+	collect := func(values []any) error {
+		rows = append(rows, append([]any(nil), values...))
+		return nil
+	}
+
+	// Encrypted columns are decrypted and timestamps normalised
+	err = store.ExportRows(ExportPages, ExportFilter{Columns: []string{"url", "status_code", "title", "crawled_at"}, Statuses: []string{"200"}}, collect)
+	if err != nil {
+		t.Fatalf("ExportRows failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0][0] != "https://example.com/" || rows[0][1] != int64(200) ||
+		rows[0][2] != "Home" || rows[0][3] != "2024-06-01T12:00:00Z" {
+		t.Errorf("Unexpected page rows: %v", rows)
+	}
+
+	// A link status filter applies to the link target
+	rows = nil
+	err = store.ExportRows(ExportLinks, ExportFilter{Columns: []string{"target_url", "anchor_text"}, Statuses: []string{"404", "discovered"}}, collect)
+	if err != nil {
+		t.Fatalf("ExportRows failed: %v", err)
+	}
+	if len(rows) != 2 || rows[0][1] != "Gone" || rows[1][0] != "https://example.com/about" {
+		t.Errorf("Unexpected link rows: %v", rows)
+	}
+
+	// Limit keeps the first rows in id order
+	rows = nil
+	err = store.ExportRows(ExportLinks, ExportFilter{Columns: []string{"target_url"}, Limit: 1}, collect)
+	if err != nil {
+		t.Fatalf("ExportRows failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0][0] != "https://example.com/missing" {
+		t.Errorf("Unexpected limited rows: %v", rows)
+	}
+
+	stop := errors.New("stop")
+	if err := store.ExportRows(ExportPages, ExportFilter{}, func([]any) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("Expected the callback error, got %v", err)
+	}
+
+	for _, tc := range []struct {
+		table  string
+		filter ExportFilter
+		want   error
+	}{
+		{"sessions", ExportFilter{}, ErrUnknownExportTable},
+		{ExportPages, ExportFilter{Columns: []string{"body"}}, ErrUnknownExportColumn},
+		{ExportErrors, ExportFilter{Statuses: []string{"error"}}, ErrStatusFilterUnsupported},
+	} {
+		if err := CheckExportFilter(tc.table, tc.filter); !errors.Is(err, tc.want) {
+			t.Errorf("CheckExportFilter(%s, %+v) = %v, want %v", tc.table, tc.filter, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// SaveExternalCheck records the result of verifying an out-of-scope link. The
+// checked URL keeps its 'discovered' status in pages; the result lives in
+// external_checks so it never counts as a crawled page.
+func (s *SQLiteStorage) SaveExternalCheck(check *crawler.ExternalCheck) error {
+	pageID, err := s.getOrCreatePageID(check.URL)
+	if err != nil {
+		return fmt.Errorf("failed to get page ID for %s: %w", check.URL, err)
+	}
+
+	errorMessage, err := s.encryptField(check.ErrorMessage)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT OR REPLACE INTO external_checks (
+			page_id, method, status_code, error_message, checked_at
+		) VALUES (?, ?, ?, ?, ?)
+	`, pageID, check.Method, check.StatusCode, errorMessage, check.CheckedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save external check: %w", err)
+	}
+	return nil
+}
+
+// HasExternalCheck reports whether url has already been verified
+func (s *SQLiteStorage) HasExternalCheck(url string) bool {
+	var exists bool
+	err := s.read.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM external_checks ec
+			JOIN pages p ON ec.page_id = p.id
+			WHERE p.url = ?
+		)
+	`, url).Scan(&exists)
+	return err == nil && exists
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestExternalChecks(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "external.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	link := &crawler.LinkData{
+		SourceURL:  "https://example.com/",
+		TargetURL:  "https://other.example.org/missing",
+		AnchorText: "Partner",
+		LinkType:   "external",
+		CrawledAt:  time.Now(),
+	}
+	if err := store.SaveLinks([]*crawler.LinkData{link}); err != nil {
+		t.Fatalf("Failed to save link: %v", err)
+	}
+
+	if store.HasExternalCheck(link.TargetURL) {
+		t.Fatal("Expected no check before saving one")
+	}
+
+	check := &crawler.ExternalCheck{URL: link.TargetURL, Method: "HEAD", StatusCode: 404, CheckedAt: time.Now()}
+	if err := store.SaveExternalCheck(check); err != nil {
+		t.Fatalf("Failed to save external check: %v", err)
+	}
+	if !store.HasExternalCheck(link.TargetURL) {
+		t.Error("Expected check to be recorded")
+	}
+
+	// The checked URL is not crawled, so it must stay a 'discovered' node
+	if status, _ := store.GetURLStatus(link.TargetURL); status != "discovered" {
+		t.Errorf("Expected status 'discovered', got %q", status)
+	}
+
+	var source, anchor string
+	var statusCode int
+	err = store.db.QueryRow(
+		"SELECT source_url, anchor_text, status_code FROM external_link_status WHERE target_url = ?", link.TargetURL,
+	).Scan(&source, &anchor, &statusCode)
+	if err != nil {
+		t.Fatalf("Failed to query external_link_status: %v", err)
+	}
+	if source != link.SourceURL || anchor != "Partner" || statusCode != 404 {
+		t.Errorf("Unexpected row: %s %q %d", source, anchor, statusCode)
+	}
+}
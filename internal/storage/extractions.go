@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// savePageExtractions replaces the content extractor results stored for a page
+func (s *SQLiteStorage) savePageExtractions(tx *sql.Tx, pageID int, extractions []crawler.Extraction) error {
+	if _, err := tx.Exec("DELETE FROM page_extractions WHERE page_id = ?", pageID); err != nil {
+		return fmt.Errorf("failed to clear page extractions: %w", err)
+	}
+
+	for _, extraction := range extractions {
+		var data, errMsg any
+		if extraction.Error != "" {
+			errMsg = extraction.Error
+		} else {
+			payload, err := json.Marshal(extraction.Fields)
+			if err != nil {
+				errMsg = fmt.Sprintf("failed to encode extracted fields: %v", err)
+			} else if data, err = s.encryptField(string(payload)); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(
+			"INSERT OR REPLACE INTO page_extractions (page_id, extractor, data, error) VALUES (?, ?, ?, ?)",
+			pageID, extraction.Extractor, data, errMsg,
+		); err != nil {
+			return fmt.Errorf("failed to save page extraction: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetPageExtractions returns the content extractor results of a page, ordered
+// by extractor name
+func (s *SQLiteStorage) GetPageExtractions(url string) ([]crawler.Extraction, error) {
+	rows, err := s.db.Query(`
+		SELECT pe.extractor, COALESCE(pe.data, ''), COALESCE(pe.error, '')
+		FROM page_extractions pe
+		JOIN pages p ON pe.page_id = p.id
+		WHERE p.url = ?
+		ORDER BY pe.extractor
+	`, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page extractions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var extractions []crawler.Extraction
+	for rows.Next() {
+		var extraction crawler.Extraction
+		var data string
+		if err := rows.Scan(&extraction.Extractor, &data, &extraction.Error); err != nil {
+			return nil, fmt.Errorf("failed to scan page extraction: %w", err)
+		}
+		if data, err = s.DecryptField(data); err != nil {
+			return nil, err
+		}
+		if data != "" {
+			if err := json.Unmarshal([]byte(data), &extraction.Fields); err != nil {
+				return nil, fmt.Errorf("failed to decode extracted fields: %w", err)
+			}
+		}
+		extractions = append(extractions, extraction)
+	}
+	return extractions, rows.Err()
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestPageExtractions(t *testing.T) {
+	for _, passphrase := range []string{"", "secret"} {
+		store, err := NewSQLiteStorageWithResults(filepath.Join(t.TempDir(), "crawl.db"), "", passphrase)
+		if err != nil {
+			t.Fatalf("Failed to create storage: %v", err)
+		}
+		url := "https://example.com/product"
+		_ = store.AddToQueue([]string{url})
+		item, _ := store.GetNextFromQueue()
+		page := &crawler.PageData{URL: url, StatusCode: 200, HTTPHeaders: map[string]string{}, CrawledAt: time.Now(),
+			Extractions: []crawler.Extraction{
+				{Extractor: "product", Fields: map[string]any{"price": 12.5, "sku": "A-1", "tags": []any{"new"}}},
+				{Extractor: "broken", Error: "layout changed"},
+			}}
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save page: %v", err)
+		}
+
+		got, err := store.GetPageExtractions(url)
+		if err != nil {
+			t.Fatalf("GetPageExtractions failed: %v", err)
+		}
+		want := []crawler.Extraction{
+			{Extractor: "broken", Error: "layout changed"},
+			{Extractor: "product", Fields: map[string]any{"price": 12.5, "sku": "A-1", "tags": []any{"new"}}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Unexpected extractions (passphrase %q): %+v", passphrase, got)
+		}
+
+		// Recrawling the page replaces its extractions
+		page.Extractions = nil
+		_ = store.SavePageResult(item.ID, page)
+		if got, _ := store.GetPageExtractions(url); len(got) != 0 {
+			t.Errorf("Expected the extractions to be replaced, got %+v", got)
+		}
+		_ = store.Close()
+	}
+}
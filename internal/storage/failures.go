@@ -0,0 +1,187 @@
+// Package storage — failure review.
+//
+// The control API's dashboard lists the latest fetch errors and lets users
+// put failed pages back in the queue once the cause is fixed, without
+// editing the database by hand; `linktadoru requeue` does the same from the
+// command line, selecting pages by error type, failure count or URL pattern.
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RecentError is a crawl_errors row, newest first in GetRecentErrors
+type RecentError struct {
+	URL          string `json:"url"`
+	ErrorType    string `json:"error_type"`
+	ErrorMessage string `json:"error_message"`
+	OccurredAt   string `json:"occurred_at"` // RFC 3339, UTC
+	PageStatus   string `json:"page_status"` // Crawl status of the page now ("" when not queued)
+}
+
+// GetRecentErrors returns the limit most recent fetch errors
+func (s *SQLiteStorage) GetRecentErrors(limit int) ([]RecentError, error) {
+	rows, err := s.read.Query(`
+		SELECT e.url, e.error_type, COALESCE(e.error_message, ''), e.occurred_at, COALESCE(p.status, '')
+		FROM crawl_errors e
+		LEFT JOIN pages p ON p.url = e.url
+		ORDER BY e.id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query errors: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var recent []RecentError
+	for rows.Next() {
+		var e RecentError
+		var occurredAt any
+		if err := rows.Scan(&e.URL, &e.ErrorType, &e.ErrorMessage, &occurredAt, &e.PageStatus); err != nil {
+			return nil, fmt.Errorf("failed to scan error: %w", err)
+		}
+		if e.ErrorMessage, err = s.DecryptField(e.ErrorMessage); err != nil {
+			return nil, err
+		}
+		if value, err := s.exportValue(occurredAt, false); err == nil && value != nil {
+			e.OccurredAt = fmt.Sprint(value)
+		}
+		recent = append(recent, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read errors: %w", err)
+	}
+	return recent, nil
+}
+
+// RequeueFilter selects the failed pages RequeueErrors puts back in the
+// queue; a page must pass every filter that is set
+type RequeueFilter struct {
+	URLs       []string       // Only these pages; empty = every page with status 'error'
+	ErrorTypes []string       // Only pages whose last error has one of these types
+	MaxRetries int            // Only pages that failed fewer times than this; 0 = no limit
+	Match      *regexp.Regexp // Only pages whose URL matches
+}
+
+// ErrorPage is a failed page selected by a RequeueFilter
+type ErrorPage struct {
+	URL       string `json:"url"`
+	ErrorType string `json:"error_type"`
+	Failures  int    `json:"failures"` // Times the fetch failed (pages.retry_count)
+}
+
+// requeueBatchSize bounds the page IDs of one UPDATE when the filter is
+// applied outside SQL
+const requeueBatchSize = 500
+
+// where returns the SQL conditions of the filter, except Match
+func (f RequeueFilter) where() (string, []any) {
+	where := "status = 'error'"
+	var args []any
+	if len(f.URLs) > 0 {
+		where += " AND url IN (" + placeholders(len(f.URLs)) + ")"
+		for _, u := range f.URLs {
+			args = append(args, strings.TrimSpace(u))
+		}
+	}
+	if len(f.ErrorTypes) > 0 {
+		where += " AND last_error_type IN (" + placeholders(len(f.ErrorTypes)) + ")"
+		for _, t := range f.ErrorTypes {
+			args = append(args, strings.TrimSpace(t))
+		}
+	}
+	if f.MaxRetries > 0 {
+		where += " AND retry_count < ?"
+		args = append(args, f.MaxRetries)
+	}
+	return where, args
+}
+
+// GetErrorPages returns the failed pages selected by filter, ordered by URL
+func (s *SQLiteStorage) GetErrorPages(filter RequeueFilter) ([]ErrorPage, error) {
+	pages, _, err := s.errorPages(filter)
+	return pages, err
+}
+
+// errorPages returns the failed pages selected by filter with their IDs
+func (s *SQLiteStorage) errorPages(filter RequeueFilter) ([]ErrorPage, []int, error) {
+	where, args := filter.where()
+	rows, err := s.read.Query(`SELECT id, url, COALESCE(last_error_type, ''), COALESCE(retry_count, 0)
+		FROM pages WHERE `+where+` ORDER BY url`, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query error pages: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var pages []ErrorPage
+	var ids []int
+	for rows.Next() {
+		var id int
+		var page ErrorPage
+		if err := rows.Scan(&id, &page.URL, &page.ErrorType, &page.Failures); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan error page: %w", err)
+		}
+		if filter.Match != nil && !filter.Match.MatchString(page.URL) {
+			continue
+		}
+		pages = append(pages, page)
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read error pages: %w", err)
+	}
+	return pages, ids, nil
+}
+
+// RequeueErrors sets failed pages back to 'pending' so the running crawl, or
+// the next one, fetches them again, and returns how many were requeued
+func (s *SQLiteStorage) RequeueErrors(filter RequeueFilter) (int, error) {
+	const requeue = "UPDATE pages SET status = 'pending', processing_started_at = NULL WHERE "
+	if filter.Match == nil {
+		where, args := filter.where()
+		result, err := s.db.Exec(requeue+where, args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to requeue error pages: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		return int(n), nil
+	}
+
+	// SQLite has no regular expressions; the matching pages are requeued by ID
+	_, ids, err := s.errorPages(filter)
+	if err != nil {
+		return 0, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	requeued := 0
+	for start := 0; start < len(ids); start += requeueBatchSize {
+		batch := ids[start:min(start+requeueBatchSize, len(ids))]
+		args := make([]any, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+		// A page claimed or requeued since it was selected is left alone
+		result, err := tx.Exec(requeue+"status = 'error' AND id IN ("+placeholders(len(batch))+")", args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to requeue error pages: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		requeued += int(n)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit requeue: %w", err)
+	}
+	return requeued, nil
+}
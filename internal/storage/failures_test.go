@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestRecentErrorsAndRequeue(t *testing.T) {
+	store, err := NewSQLiteStorageWithPassphrase(filepath.Join(t.TempDir(), "failures.db"), "secret")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	urls := []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	occurredAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, u := range urls[:2] {
+		item, _ := store.GetNextFromQueue()
+		if err := store.SavePageError(item.ID, "network_timeout", "timed out"); err != nil {
+			t.Fatalf("Failed to save page error: %v", err)
+		}
+		if err := store.SaveError(&crawler.CrawlError{URL: u, ErrorType: "network_timeout", ErrorMessage: "timed out", OccurredAt: occurredAt}); err != nil {
+			t.Fatalf("Failed to save error: %v", err)
+		}
+	}
+
+	// Newest first, with the message decrypted
+	recent, err := store.GetRecentErrors(1)
+	if err != nil {
+		t.Fatalf("GetRecentErrors failed: %v", err)
+	}
+	if len(recent) != 1 || recent[0].URL != urls[1] || recent[0].ErrorMessage != "timed out" ||
+		recent[0].OccurredAt != "2024-06-01T12:00:00Z" || recent[0].PageStatus != "error" {
+		t.Errorf("Unexpected recent errors: %+v", recent)
+	}
+
+	// Only the selected page is requeued, then every remaining one
+	n, err := store.RequeueErrors(RequeueFilter{URLs: []string{urls[0], urls[2]}})
+	if err != nil || n != 1 {
+		t.Errorf("RequeueErrors(a, c) = %d, %v; want 1", n, err)
+	}
+	if status, _ := store.GetURLStatus(urls[0]); status != "pending" {
+		t.Errorf("Requeued page status = %q, want pending", status)
+	}
+	if n, err := store.RequeueErrors(RequeueFilter{}); err != nil || n != 1 {
+		t.Errorf("RequeueErrors() = %d, %v; want 1", n, err)
+	}
+}
+
+func TestRequeueErrorFilters(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "requeue.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// Each page fails once per listed error type, the last one sticking
+	failures := map[string][]string{
+		"https://example.com/blog/a": {"network_error"},
+		"https://example.com/blog/b": {"network_error", "network_error", "network_error"},
+		"https://example.com/blog/c": {"timeout"},
+		"https://example.com/shop/a": {"network_error"},
+	}
+	for round := 0; round < 3; round++ {
+		var urls []string
+		for u, types := range failures {
+			if round < len(types) {
+				urls = append(urls, u)
+			}
+		}
+		if err := store.AddToQueue(urls); err != nil {
+			t.Fatalf("Failed to add to queue: %v", err)
+		}
+		if round > 0 {
+			if _, err := store.RequeueErrors(RequeueFilter{URLs: urls}); err != nil {
+				t.Fatalf("Failed to requeue: %v", err)
+			}
+		}
+		for range urls {
+			item, err := store.GetNextFromQueue()
+			if err != nil || item == nil {
+				t.Fatalf("Failed to dequeue: %v", err)
+			}
+			if err := store.SavePageError(item.ID, failures[item.URL][round], "failed"); err != nil {
+				t.Fatalf("Failed to save page error: %v", err)
+			}
+		}
+	}
+
+	filter := RequeueFilter{
+		ErrorTypes: []string{"network_error"},
+		MaxRetries: 3,
+		Match:      regexp.MustCompile(`/blog/`),
+	}
+	pages, err := store.GetErrorPages(filter)
+	if err != nil {
+		t.Fatalf("GetErrorPages failed: %v", err)
+	}
+	want := []ErrorPage{{URL: "https://example.com/blog/a", ErrorType: "network_error", Failures: 1}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("GetErrorPages = %+v, want %+v", pages, want)
+	}
+	if n, err := store.RequeueErrors(filter); err != nil || n != 1 {
+		t.Errorf("RequeueErrors(filter) = %d, %v; want 1", n, err)
+	}
+	if status, _ := store.GetURLStatus("https://example.com/blog/a"); status != "pending" {
+		t.Errorf("Requeued page status = %q, want pending", status)
+	}
+
+	// Without the pattern every page below the failure limit is requeued in SQL
+	if n, err := store.RequeueErrors(RequeueFilter{MaxRetries: 3}); err != nil || n != 2 {
+		t.Errorf("RequeueErrors(max 3) = %d, %v; want 2", n, err)
+	}
+	if status, _ := store.GetURLStatus("https://example.com/blog/b"); status != "error" {
+		t.Errorf("Page over the failure limit status = %q, want error", status)
+	}
+}
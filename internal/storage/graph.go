@@ -0,0 +1,132 @@
+// Package storage — link graph.
+//
+// LoadLinkGraph reads the link_relations graph with the page attributes
+// graph tools need, for `linktadoru export graph`. Unlike row exports the
+// graph is held in memory: click depth is a breadth-first search over it.
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// GraphNode is a page of the link graph
+type GraphNode struct {
+	ID         int64
+	URL        string
+	Status     string // Crawl status of the page
+	StatusCode int    // HTTP status code (0 = not fetched)
+	Title      string
+	Depth      int // Clicks from the nearest root (-1 = unreachable); set by SetDepths
+}
+
+// GraphEdge is a link between two pages of the link graph
+type GraphEdge struct {
+	Source   int64 // GraphNode.ID of the linking page
+	Target   int64 // GraphNode.ID of the linked page
+	LinkType string
+	Rel      string
+}
+
+// LinkGraph is the link graph of a crawl
+type LinkGraph struct {
+	Nodes []GraphNode // In page id order
+	Edges []GraphEdge // In link id order
+}
+
+// LoadLinkGraph reads the link graph. Asset links (stylesheets, scripts,
+// images) are left out unless includeAssets is set. Nodes are the pages
+// selected for crawling plus the pages at either end of a loaded link, so
+// assets and other link-graph-only URLs only appear when linked.
+func (s *SQLiteStorage) LoadLinkGraph(includeAssets bool) (*LinkGraph, error) {
+	graph := &LinkGraph{}
+	linked := make(map[int64]bool)
+
+	rows, err := s.read.Query(`
+		SELECT source_page_id, target_page_id, COALESCE(link_type, ''), COALESCE(rel_attribute, '')
+		FROM link_relations
+		WHERE ? OR link_type IS NOT ?
+		ORDER BY id
+	`, includeAssets, crawler.LinkTypeAsset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query links: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var edge GraphEdge
+		if err := rows.Scan(&edge.Source, &edge.Target, &edge.LinkType, &edge.Rel); err != nil {
+			return nil, fmt.Errorf("failed to scan link: %w", err)
+		}
+		linked[edge.Source], linked[edge.Target] = true, true
+		graph.Edges = append(graph.Edges, edge)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read links: %w", err)
+	}
+	_ = rows.Close()
+
+	pages, err := s.read.Query(`
+		SELECT id, url, status, COALESCE(status_code, 0), title
+		FROM pages
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pages: %w", err)
+	}
+	defer func() { _ = pages.Close() }()
+	for pages.Next() {
+		node := GraphNode{Depth: -1}
+		var title sql.NullString
+		if err := pages.Scan(&node.ID, &node.URL, &node.Status, &node.StatusCode, &title); err != nil {
+			return nil, fmt.Errorf("failed to scan page: %w", err)
+		}
+		if node.Status == "discovered" && !linked[node.ID] {
+			continue
+		}
+		if node.Title, err = s.DecryptField(title.String); err != nil {
+			return nil, err
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	if err := pages.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pages: %w", err)
+	}
+	return graph, nil
+}
+
+// SetDepths sets the click depth of every node: the fewest links to follow
+// from one of the root URLs, -1 when no root links to it. Root URLs that are
+// not in the graph are ignored.
+func (g *LinkGraph) SetDepths(roots []string) {
+	index := make(map[int64]int, len(g.Nodes))
+	byURL := make(map[string]int, len(g.Nodes))
+	for i := range g.Nodes {
+		g.Nodes[i].Depth = -1
+		index[g.Nodes[i].ID] = i
+		byURL[g.Nodes[i].URL] = i
+	}
+	out := make(map[int64][]int64)
+	for _, edge := range g.Edges {
+		out[edge.Source] = append(out[edge.Source], edge.Target)
+	}
+
+	var queue []int
+	for _, root := range roots {
+		if i, ok := byURL[root]; ok && g.Nodes[i].Depth < 0 {
+			g.Nodes[i].Depth = 0
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		node := g.Nodes[queue[0]]
+		queue = queue[1:]
+		for _, target := range out[node.ID] {
+			if i, ok := index[target]; ok && g.Nodes[i].Depth < 0 {
+				g.Nodes[i].Depth = node.Depth + 1
+				queue = append(queue, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestLoadLinkGraph(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "graph.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.AddToQueue([]string{"https://example.com/", "https://example.com/orphan"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	item, _ := store.GetNextFromQueue()
+	err = store.SavePageResult(item.ID, &crawler.PageData{
+		URL: item.URL, StatusCode: 200, Title: "Home", HTTPHeaders: map[string]string{}, CrawledAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to save page: %v", err)
+	}
+	err = store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/a", LinkType: "internal"},
+		{SourceURL: "https://example.com/a", TargetURL: "https://example.com/b", LinkType: "internal", RelAttribute: "nofollow"},
+		{SourceURL: "https://example.com/b", TargetURL: "https://example.com/", LinkType: "internal"},
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/logo.png", LinkType: crawler.LinkTypeAsset},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save links: %v", err)
+	}
+
+	graph, err := store.LoadLinkGraph(false)
+	if err != nil {
+		t.Fatalf("LoadLinkGraph failed: %v", err)
+	}
+	if len(graph.Nodes) != 4 || len(graph.Edges) != 3 {
+		t.Fatalf("Expected 4 nodes and 3 edges without assets, got %+v", graph)
+	}
+	if graph.Nodes[0].Title != "Home" || graph.Nodes[0].StatusCode != 200 || graph.Edges[1].Rel != "nofollow" {
+		t.Errorf("Expected page and link attributes, got %+v", graph)
+	}
+
+	graph.SetDepths([]string{"https://example.com/", "https://example.com/unknown"})
+	depths := map[string]int{}
+	for _, node := range graph.Nodes {
+		depths[node.URL] = node.Depth
+	}
+	want := map[string]int{
+		"https://example.com/":       0,
+		"https://example.com/orphan": -1,
+		"https://example.com/a":      1,
+		"https://example.com/b":      2,
+	}
+	for url, depth := range want {
+		if depths[url] != depth {
+			t.Errorf("Depth of %s = %d, want %d", url, depths[url], depth)
+		}
+	}
+
+	graph, err = store.LoadLinkGraph(true)
+	if err != nil || len(graph.Nodes) != 5 || len(graph.Edges) != 4 {
+		t.Errorf("Expected asset links and their targets, got %+v (%v)", graph, err)
+	}
+}
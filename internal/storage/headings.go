@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// savePageHeadings replaces the headings stored for a page
+func (s *SQLiteStorage) savePageHeadings(tx *sql.Tx, pageID int, headings []crawler.Heading) error {
+	if _, err := tx.Exec("DELETE FROM page_headings WHERE page_id = ?", pageID); err != nil {
+		return fmt.Errorf("failed to clear page headings: %w", err)
+	}
+
+	if len(headings) > 0 {
+		stmt, err := tx.Prepare("INSERT INTO page_headings (page_id, position, level, text) VALUES (?, ?, ?, ?)")
+		if err != nil {
+			return fmt.Errorf("failed to prepare heading insert: %w", err)
+		}
+		defer func() { _ = stmt.Close() }()
+
+		for i, heading := range headings {
+			text, err := s.encryptField(heading.Text)
+			if err != nil {
+				return err
+			}
+			if _, err := stmt.Exec(pageID, i, heading.Level, text); err != nil {
+				return fmt.Errorf("failed to save page heading: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetPageHeadings returns the headings of a page in document order
+func (s *SQLiteStorage) GetPageHeadings(url string) ([]crawler.Heading, error) {
+	rows, err := s.db.Query(`
+		SELECT ph.level, COALESCE(ph.text, '')
+		FROM page_headings ph
+		JOIN pages p ON ph.page_id = p.id
+		WHERE p.url = ?
+		ORDER BY ph.position
+	`, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page headings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var headings []crawler.Heading
+	for rows.Next() {
+		var heading crawler.Heading
+		if err := rows.Scan(&heading.Level, &heading.Text); err != nil {
+			return nil, fmt.Errorf("failed to scan page heading: %w", err)
+		}
+		if heading.Text, err = s.DecryptField(heading.Text); err != nil {
+			return nil, err
+		}
+		headings = append(headings, heading)
+	}
+	return headings, rows.Err()
+}
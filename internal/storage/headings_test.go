@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestPageHeadings(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "headings.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	pages := map[string][]crawler.Heading{
+		"https://example.com/ok":       {{Level: 1, Text: "Title"}, {Level: 2, Text: "Section"}},
+		"https://example.com/none":     {{Level: 2, Text: "Only a section"}},
+		"https://example.com/multiple": {{Level: 1, Text: "First"}, {Level: 1, Text: "Second"}},
+	}
+	var urls []string
+	for url := range pages {
+		urls = append(urls, url)
+	}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range urls {
+		item, err := store.GetNextFromQueue()
+		if err != nil || item == nil {
+			t.Fatalf("Failed to dequeue: %v", err)
+		}
+		page := &crawler.PageData{
+			URL:         item.URL,
+			StatusCode:  200,
+			HTTPHeaders: map[string]string{},
+			CrawledAt:   time.Now(),
+			Text:        &crawler.PageText{WordCount: 10},
+			Headings:    pages[item.URL],
+		}
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	headings, err := store.GetPageHeadings("https://example.com/ok")
+	if err != nil {
+		t.Fatalf("Failed to get headings: %v", err)
+	}
+	if len(headings) != 2 || headings[0] != (crawler.Heading{Level: 1, Text: "Title"}) || headings[1].Level != 2 {
+		t.Errorf("Unexpected headings: %+v", headings)
+	}
+
+	rows, err := store.db.Query("SELECT url, h1_count, issue FROM heading_issues ORDER BY url")
+	if err != nil {
+		t.Fatalf("Failed to query heading_issues: %v", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	issues := map[string]string{}
+	for rows.Next() {
+		var url, issue string
+		var count int
+		if err := rows.Scan(&url, &count, &issue); err != nil {
+			t.Fatalf("Failed to scan: %v", err)
+		}
+		issues[url] = issue
+	}
+	if len(issues) != 2 || issues["https://example.com/none"] != "missing_h1" || issues["https://example.com/multiple"] != "multiple_h1" {
+		t.Errorf("Unexpected heading issues: %v", issues)
+	}
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/masahif/linktadoru/internal/parser"
+)
+
+// HostStats summarizes the pages of one host, or of every host sharing a
+// registrable domain when grouped
+type HostStats struct {
+	Host      string  `json:"host"`        // Host name, or registrable domain when grouped
+	Hosts     int     `json:"hosts"`       // Number of distinct hosts in the group
+	Pages     int     `json:"pages"`       // Queued or crawled pages (discovered-only nodes excluded)
+	Completed int     `json:"completed"`   // Pages crawled successfully
+	Errors    int     `json:"errors"`      // Pages that failed
+	Skipped   int     `json:"skipped"`     // Pages skipped (robots.txt, content type, ...)
+	Pending   int     `json:"pending"`     // Pages still queued or in progress
+	AvgTTFBMs float64 `json:"avg_ttfb_ms"` // Mean TTFB of completed pages
+	Bytes     int64   `json:"bytes"`       // Total response size of completed pages
+}
+
+// GetHostStats returns page counts per host, ordered by page count. With
+// byDomain set, hosts are grouped by registrable domain using the public
+// suffix list, so www.example.com and shop.example.com count together.
+func (s *SQLiteStorage) GetHostStats(byDomain bool) ([]HostStats, error) {
+	rows, err := s.db.Query(`
+		SELECT url, status, COALESCE(ttfb_ms, 0), COALESCE(response_size_bytes, 0)
+		FROM pages
+		WHERE status != 'discovered'
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pages: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	groups := map[string]*HostStats{}
+	hosts := map[string]map[string]bool{}
+	ttfbTotals := map[string]int64{}
+	for rows.Next() {
+		var rawURL, status string
+		var ttfb, size int64
+		if err := rows.Scan(&rawURL, &status, &ttfb, &size); err != nil {
+			return nil, fmt.Errorf("failed to scan page: %w", err)
+		}
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		host := u.Hostname()
+		key := host
+		if byDomain {
+			key = parser.RegistrableDomain(host)
+		}
+
+		stats, ok := groups[key]
+		if !ok {
+			stats = &HostStats{Host: key}
+			groups[key] = stats
+			hosts[key] = map[string]bool{}
+		}
+		hosts[key][host] = true
+		stats.Pages++
+		switch status {
+		case "completed":
+			stats.Completed++
+			stats.Bytes += size
+			ttfbTotals[key] += ttfb
+		case "error":
+			stats.Errors++
+		case "skipped":
+			stats.Skipped++
+		default:
+			stats.Pending++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pages: %w", err)
+	}
+
+	result := make([]HostStats, 0, len(groups))
+	for key, stats := range groups {
+		stats.Hosts = len(hosts[key])
+		if stats.Completed > 0 {
+			stats.AvgTTFBMs = float64(ttfbTotals[key]) / float64(stats.Completed)
+		}
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Pages != result[j].Pages {
+			return result[i].Pages > result[j].Pages
+		}
+		return result[i].Host < result[j].Host
+	})
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestGetHostStats(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "hosts.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	urls := []string{
+		"https://www.example.com/",
+		"https://www.example.com/about",
+		"https://shop.example.com/",
+		"https://blog.example.co.uk/",
+	}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	// Crawl three pages; one of them fails
+	for i := 0; i < 3; i++ {
+		item, err := store.GetNextFromQueue()
+		if err != nil || item == nil {
+			t.Fatalf("Failed to dequeue: %v", err)
+		}
+		if item.URL == "https://shop.example.com/" {
+			err = store.SavePageError(item.ID, "network_error", "connection refused")
+		} else {
+			err = store.SavePageResult(item.ID, &crawler.PageData{
+				URL:          item.URL,
+				StatusCode:   200,
+				TTFB:         100 * time.Millisecond,
+				ResponseSize: 1000,
+				HTTPHeaders:  map[string]string{},
+				CrawledAt:    time.Now(),
+			})
+		}
+		if err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+	// Link-graph-only nodes are not counted
+	if err := store.SaveLinks([]*crawler.LinkData{{SourceURL: urls[0], TargetURL: "https://cdn.example.net/x", LinkType: "external"}}); err != nil {
+		t.Fatalf("Failed to save link: %v", err)
+	}
+
+	byDomain, err := store.GetHostStats(true)
+	if err != nil {
+		t.Fatalf("Failed to get domain stats: %v", err)
+	}
+	if len(byDomain) != 2 {
+		t.Fatalf("Expected 2 domains, got %+v", byDomain)
+	}
+	example := byDomain[0]
+	if example.Host != "example.com" || example.Hosts != 2 || example.Pages != 3 || example.Completed+example.Errors+example.Pending != 3 {
+		t.Errorf("Unexpected example.com stats: %+v", example)
+	}
+	if byDomain[1].Host != "example.co.uk" || byDomain[1].Pages != 1 {
+		t.Errorf("Unexpected example.co.uk stats: %+v", byDomain[1])
+	}
+
+	byHost, err := store.GetHostStats(false)
+	if err != nil {
+		t.Fatalf("Failed to get host stats: %v", err)
+	}
+	if len(byHost) != 3 || byHost[0].Host != "www.example.com" || byHost[0].Pages != 2 || byHost[0].Hosts != 1 {
+		t.Errorf("Unexpected host stats: %+v", byHost)
+	}
+}
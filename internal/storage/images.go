@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// Image audit issues reported by GetImageIssues
+const (
+	ImageIssueMissingAlt = "missing_alt" // no alt attribute
+	ImageIssueEmptyAlt   = "empty_alt"   // alt="" (fine for decorative images)
+	ImageIssueOversized  = "oversized"   // larger than the byte or dimension limit
+)
+
+// ImageIssue is an accessibility or size problem of an image on a page. An
+// image with several problems appears once per issue.
+type ImageIssue struct {
+	PageURL  string `json:"page_url"`
+	ImageURL string `json:"image_url"`
+	Issue    string `json:"issue"`  // One of the ImageIssue constants
+	Width    int    `json:"width"`  // Declared width (0 = not declared)
+	Height   int    `json:"height"` // Declared height (0 = not declared)
+	Bytes    int64  `json:"bytes"`  // Response size when the image was crawled (0 = unknown)
+}
+
+// savePageImages replaces the images stored for a page
+func (s *SQLiteStorage) savePageImages(tx *sql.Tx, pageID int, images []crawler.Image) error {
+	if _, err := tx.Exec("DELETE FROM images WHERE page_id = ?", pageID); err != nil {
+		return fmt.Errorf("failed to clear page images: %w", err)
+	}
+
+	if len(images) > 0 {
+		stmt, err := tx.Prepare(`
+			INSERT INTO images (page_id, position, src, alt, width, height, loading)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare image insert: %w", err)
+		}
+		defer func() { _ = stmt.Close() }()
+
+		for i, img := range images {
+			var alt any
+			if img.HasAlt {
+				encrypted, err := s.encryptField(img.Alt)
+				if err != nil {
+					return err
+				}
+				alt = encrypted
+			}
+			if _, err := stmt.Exec(pageID, i, img.URL, alt,
+				nullIfZero(img.Width), nullIfZero(img.Height), img.Loading); err != nil {
+				return fmt.Errorf("failed to save page image: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// nullIfZero stores an undeclared (zero) dimension as NULL
+func nullIfZero(n int) any {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
+
+// GetPageImages returns the images of a page in document order
+func (s *SQLiteStorage) GetPageImages(url string) ([]crawler.Image, error) {
+	rows, err := s.db.Query(`
+		SELECT i.src, i.alt, COALESCE(i.width, 0), COALESCE(i.height, 0), COALESCE(i.loading, '')
+		FROM images i
+		JOIN pages p ON i.page_id = p.id
+		WHERE p.url = ?
+		ORDER BY i.position
+	`, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page images: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var images []crawler.Image
+	for rows.Next() {
+		var img crawler.Image
+		var alt sql.NullString
+		if err := rows.Scan(&img.URL, &alt, &img.Width, &img.Height, &img.Loading); err != nil {
+			return nil, fmt.Errorf("failed to scan page image: %w", err)
+		}
+		if alt.Valid {
+			img.HasAlt = true
+			if img.Alt, err = s.DecryptField(alt.String); err != nil {
+				return nil, err
+			}
+		}
+		images = append(images, img)
+	}
+	return images, rows.Err()
+}
+
+// GetImageIssues returns images without alt text or with empty alt text, and
+// images over the size limits: maxBytes applies to images the crawl fetched
+// (their response size is known), maxDimension to the declared width or
+// height. A zero limit disables that check.
+func (s *SQLiteStorage) GetImageIssues(maxBytes int64, maxDimension int) ([]ImageIssue, error) {
+	rows, err := s.db.Query(`
+		SELECT p.url, i.src, i.alt IS NULL, (i.alt IS NOT NULL AND i.alt = ''),
+		       COALESCE(i.width, 0), COALESCE(i.height, 0), COALESCE(ip.response_size_bytes, 0)
+		FROM images i
+		JOIN pages p ON i.page_id = p.id
+		LEFT JOIN pages ip ON ip.url = i.src AND ip.status = 'completed'
+		ORDER BY p.url, i.position
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query images: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var issues []ImageIssue
+	for rows.Next() {
+		var img ImageIssue
+		var missing, empty bool
+		if err := rows.Scan(&img.PageURL, &img.ImageURL, &missing, &empty, &img.Width, &img.Height, &img.Bytes); err != nil {
+			return nil, fmt.Errorf("failed to scan image: %w", err)
+		}
+
+		switch {
+		case missing:
+			img.Issue = ImageIssueMissingAlt
+			issues = append(issues, img)
+		case empty:
+			img.Issue = ImageIssueEmptyAlt
+			issues = append(issues, img)
+		}
+		if (maxBytes > 0 && img.Bytes > maxBytes) ||
+			(maxDimension > 0 && (img.Width > maxDimension || img.Height > maxDimension)) {
+			img.Issue = ImageIssueOversized
+			issues = append(issues, img)
+		}
+	}
+	return issues, rows.Err()
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestPageImages(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "images.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	images := []crawler.Image{
+		{URL: "https://example.com/logo.png", Alt: "Logo", HasAlt: true, Width: 120, Height: 40},
+		{URL: "https://example.com/spacer.gif", HasAlt: true, Loading: "lazy"},
+		{URL: "https://example.com/hero.jpg", Width: 4000, Height: 3000},
+		{URL: "https://example.com/photo.jpg", Alt: "Photo", HasAlt: true},
+	}
+	if err := store.AddToQueue([]string{"https://example.com/", "https://example.com/photo.jpg"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range 2 {
+		item, err := store.GetNextFromQueue()
+		if err != nil || item == nil {
+			t.Fatalf("Failed to dequeue: %v", err)
+		}
+		page := &crawler.PageData{
+			URL:          item.URL,
+			StatusCode:   200,
+			HTTPHeaders:  map[string]string{},
+			CrawledAt:    time.Now(),
+			ResponseSize: 500_000,
+		}
+		if item.URL == "https://example.com/" {
+			page.ResponseSize = 1000
+			page.Images = images
+		}
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	stored, err := store.GetPageImages("https://example.com/")
+	if err != nil {
+		t.Fatalf("Failed to get images: %v", err)
+	}
+	if !reflect.DeepEqual(stored, images) {
+		t.Errorf("Unexpected images:\n got %+v\nwant %+v", stored, images)
+	}
+
+	issues, err := store.GetImageIssues(200_000, 2000)
+	if err != nil {
+		t.Fatalf("Failed to get image issues: %v", err)
+	}
+	expected := []struct{ url, issue string }{
+		{"https://example.com/spacer.gif", ImageIssueEmptyAlt},
+		{"https://example.com/hero.jpg", ImageIssueMissingAlt},
+		{"https://example.com/hero.jpg", ImageIssueOversized},
+		{"https://example.com/photo.jpg", ImageIssueOversized},
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %+v", len(expected), issues)
+	}
+	for i, want := range expected {
+		if issues[i].ImageURL != want.url || issues[i].Issue != want.issue {
+			t.Errorf("Issue %d: expected %s %s, got %+v", i, want.url, want.issue, issues[i])
+		}
+	}
+	if issues[3].Bytes != 500_000 {
+		t.Errorf("Expected the crawled image size, got %d", issues[3].Bytes)
+	}
+
+	// Disabled limits report alt text problems only
+	issues, err = store.GetImageIssues(0, 0)
+	if err != nil {
+		t.Fatalf("Failed to get image issues: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Errorf("Expected 2 alt text issues, got %+v", issues)
+	}
+}
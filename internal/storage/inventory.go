@@ -0,0 +1,137 @@
+// Package storage — crawl inventory.
+//
+// `linktadoru stats` answers the first questions asked of a crawl database —
+// how many pages answered with which status code, what was downloaded, from
+// which hosts, and why fetches failed — without starting a crawl.
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Dimensions of an Inventory, as named in the DIMENSION column of `linktadoru stats`
+const (
+	InventoryStatusCode  = "status_code"
+	InventoryContentType = "content_type"
+	InventoryHost        = "host"
+	InventoryErrorType   = "error_type"
+)
+
+// InventoryDimensions lists the dimensions of an Inventory in report order
+var InventoryDimensions = []string{InventoryStatusCode, InventoryContentType, InventoryHost, InventoryErrorType}
+
+// InventoryCount is the number of pages sharing one value of a dimension
+type InventoryCount struct {
+	Value string `json:"value"`
+	Pages int    `json:"pages"`
+}
+
+// Inventory breaks the pages of a crawl down by status code, content type,
+// host and error type, each list ordered by page count
+type Inventory struct {
+	StatusCodes  []InventoryCount `json:"status_codes"`  // Pages answered with each HTTP status code
+	ContentTypes []InventoryCount `json:"content_types"` // Pages answered with each media type, parameters removed
+	Hosts        []InventoryCount `json:"hosts"`         // Queued or crawled pages per host (discovered-only URLs excluded)
+	ErrorTypes   []InventoryCount `json:"error_types"`   // Pages whose fetch failed, by error type
+}
+
+// Counts returns the counts of one of the InventoryDimensions
+func (inv *Inventory) Counts(dimension string) []InventoryCount {
+	switch dimension {
+	case InventoryStatusCode:
+		return inv.StatusCodes
+	case InventoryContentType:
+		return inv.ContentTypes
+	case InventoryHost:
+		return inv.Hosts
+	case InventoryErrorType:
+		return inv.ErrorTypes
+	}
+	return nil
+}
+
+// GetInventory counts the pages of the crawl per status code, content type,
+// host and error type
+func (s *SQLiteStorage) GetInventory() (*Inventory, error) {
+	inv := &Inventory{}
+	for _, query := range []struct {
+		name   string
+		sql    string
+		counts *[]InventoryCount
+	}{
+		{"status codes", `SELECT CAST(status_code AS TEXT), COUNT(*) FROM pages
+			WHERE status_code IS NOT NULL GROUP BY status_code`, &inv.StatusCodes},
+		{"content types", `SELECT content_type, COUNT(*) FROM pages
+			WHERE status_code IS NOT NULL AND COALESCE(content_type, '') != '' GROUP BY content_type`, &inv.ContentTypes},
+		{"hosts", `SELECT host, COUNT(*) FROM pages
+			WHERE status != 'discovered' AND COALESCE(host, '') != '' GROUP BY host`, &inv.Hosts},
+		{"error types", `SELECT COALESCE(NULLIF(last_error_type, ''), 'unknown'), COUNT(*) FROM pages
+			WHERE status = 'error' GROUP BY 1`, &inv.ErrorTypes},
+	} {
+		counts, err := s.inventoryCounts(query.sql)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", query.name, err)
+		}
+		*query.counts = counts
+	}
+
+	// Content-Type headers differ in parameters and case; count media types
+	inv.ContentTypes = mergeInventoryCounts(inv.ContentTypes, func(value string) string {
+		mediaType, _, _ := strings.Cut(value, ";")
+		return strings.ToLower(strings.TrimSpace(mediaType))
+	})
+	return inv, nil
+}
+
+// inventoryCounts runs a query returning a value and a page count per row,
+// and orders the counts largest first
+func (s *SQLiteStorage) inventoryCounts(query string) ([]InventoryCount, error) {
+	rows, err := s.read.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := []InventoryCount{}
+	for rows.Next() {
+		var count InventoryCount
+		if err := rows.Scan(&count.Value, &count.Pages); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sortInventoryCounts(counts)
+	return counts, nil
+}
+
+// mergeInventoryCounts adds up the counts whose values normalize alike
+func mergeInventoryCounts(counts []InventoryCount, normalize func(string) string) []InventoryCount {
+	index := map[string]int{}
+	merged := []InventoryCount{}
+	for _, count := range counts {
+		value := normalize(count.Value)
+		if i, ok := index[value]; ok {
+			merged[i].Pages += count.Pages
+			continue
+		}
+		index[value] = len(merged)
+		merged = append(merged, InventoryCount{Value: value, Pages: count.Pages})
+	}
+	sortInventoryCounts(merged)
+	return merged
+}
+
+// sortInventoryCounts orders counts by page count, then value
+func sortInventoryCounts(counts []InventoryCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Pages != counts[j].Pages {
+			return counts[i].Pages > counts[j].Pages
+		}
+		return counts[i].Value < counts[j].Value
+	})
+}
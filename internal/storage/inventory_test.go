@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestGetInventory(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "inventory.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	pages := map[string]*crawler.PageData{
+		"https://example.com/":          {StatusCode: 200, HTTPHeaders: map[string]string{"content-type": "text/html; charset=utf-8"}},
+		"https://example.com/a":         {StatusCode: 200, HTTPHeaders: map[string]string{"content-type": "TEXT/HTML"}},
+		"https://example.com/style.css": {StatusCode: 200, HTTPHeaders: map[string]string{"content-type": "text/css"}},
+		"https://example.com/missing":   {StatusCode: 404, HTTPHeaders: map[string]string{}},
+		"https://cdn.example.com/x.js":  {StatusCode: 301, HTTPHeaders: map[string]string{}},
+	}
+	failures := map[string]string{
+		"https://example.com/down":  "network_error",
+		"https://example.com/slow":  "timeout",
+		"https://example.com/slow2": "timeout",
+	}
+	var urls []string
+	for url := range pages {
+		urls = append(urls, url)
+	}
+	for url := range failures {
+		urls = append(urls, url)
+	}
+	if err := store.AddToQueue(append(urls, "https://example.com/next")); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range urls {
+		item, err := store.GetNextFromQueue()
+		if err != nil || item == nil {
+			t.Fatalf("Failed to dequeue: %v", err)
+		}
+		if page, ok := pages[item.URL]; ok {
+			page.URL = item.URL
+			err = store.SavePageResult(item.ID, page)
+		} else {
+			err = store.SavePageError(item.ID, failures[item.URL], "failed")
+		}
+		if err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+	if err := store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://other.example.net/", LinkType: "external"},
+	}); err != nil {
+		t.Fatalf("Failed to save links: %v", err)
+	}
+
+	inventory, err := store.GetInventory()
+	if err != nil {
+		t.Fatalf("GetInventory failed: %v", err)
+	}
+	want := &Inventory{
+		StatusCodes:  []InventoryCount{{"200", 3}, {"301", 1}, {"404", 1}},
+		ContentTypes: []InventoryCount{{"text/html", 2}, {"text/css", 1}},
+		// The pending page counts; the discovered-only external URL does not
+		Hosts:      []InventoryCount{{"example.com", 8}, {"cdn.example.com", 1}},
+		ErrorTypes: []InventoryCount{{"timeout", 2}, {"network_error", 1}},
+	}
+	if !reflect.DeepEqual(inventory, want) {
+		t.Errorf("GetInventory = %+v, want %+v", inventory, want)
+	}
+	if got := inventory.Counts(InventoryErrorType); !reflect.DeepEqual(got, want.ErrorTypes) {
+		t.Errorf("Counts(%s) = %+v", InventoryErrorType, got)
+	}
+}
@@ -206,9 +206,13 @@ func TestCleanupStaleProcessingResetsNullAndOldTimestamps(t *testing.T) {
 		t.Fatalf("seed old-ts: %v", err)
 	}
 
-	if err := store.CleanupStaleProcessing(0); err != nil {
+	recovered, err := store.CleanupStaleProcessing(0)
+	if err != nil {
 		t.Fatalf("CleanupStaleProcessing: %v", err)
 	}
+	if recovered != 2 {
+		t.Errorf("Recovered %d rows, want 2", recovered)
+	}
 
 	for _, u := range []string{"https://example.com/null-ts", "https://example.com/old-ts"} {
 		if got := mustStatus(t, store, u); got != "pending" {
@@ -261,6 +265,9 @@ func TestMigratePagesAddDiscovered(t *testing.T) {
 		`); err != nil {
 			t.Fatalf("seed rows: %v", err)
 		}
+		if err := store.SetMeta(metaSchemaVersion, "1"); err != nil {
+			t.Fatalf("legacy schema version: %v", err)
+		}
 		if _, err := store.db.Exec("INSERT INTO pages (url, status) VALUES ('https://example.com/x', 'discovered')"); err == nil {
 			t.Fatal("legacy schema unexpectedly accepted 'discovered'")
 		}
@@ -268,8 +275,8 @@ func TestMigratePagesAddDiscovered(t *testing.T) {
 	}
 
 	// Run the migration (and schema recreate) on the legacy DB.
-	if err := legacy.InitSchema(); err != nil {
-		t.Fatalf("InitSchema (migration) failed: %v", err)
+	if err := legacy.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
 	}
 
 	// Existing data preserved.
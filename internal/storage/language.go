@@ -0,0 +1,68 @@
+// Package storage — page languages.
+//
+// page_content stores, for every HTML page, the language detected from its
+// main text next to the one its <html lang> attribute declares. On
+// multilingual sites the two drift apart when templates hard-code a language
+// or translations are published untranslated; `linktadoru analyze language`
+// lists the pages where they disagree or the declaration is missing.
+package storage
+
+import (
+	"fmt"
+
+	"github.com/masahif/linktadoru/internal/parser"
+)
+
+// Issues reported by GetPageLanguages
+const (
+	LanguageIssueMissing  = "missing_lang" // No <html lang> attribute
+	LanguageIssueMismatch = "mismatch"     // The declared language is not the detected one
+)
+
+// PageLanguage is the declared and detected language of a crawled page
+type PageLanguage struct {
+	URL      string `json:"url"`
+	HTMLLang string `json:"html_lang"` // Declared <html lang>; empty when absent
+	Language string `json:"language"`  // Detected ISO 639-1 code; empty when undetermined
+	Issue    string `json:"issue"`     // One of the LanguageIssue constants; empty when none
+}
+
+// GetPageLanguages returns the languages of the completed HTML pages ordered
+// by URL. A page whose declared primary language (en for en-US) differs from
+// the detected one is a mismatch; pages whose language could not be detected
+// are never mismatches. Unless all is set, only pages with an issue are
+// returned.
+func (s *SQLiteStorage) GetPageLanguages(all bool) ([]PageLanguage, error) {
+	rows, err := s.read.Query(`
+		SELECT p.url, COALESCE(pc.html_lang, ''), COALESCE(pc.language, '')
+		FROM page_content pc
+		JOIN pages p ON pc.page_id = p.id
+		WHERE p.status = 'completed'
+		ORDER BY p.url
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page languages: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var pages []PageLanguage
+	for rows.Next() {
+		var page PageLanguage
+		if err := rows.Scan(&page.URL, &page.HTMLLang, &page.Language); err != nil {
+			return nil, fmt.Errorf("failed to scan page language: %w", err)
+		}
+		switch {
+		case page.HTMLLang == "":
+			page.Issue = LanguageIssueMissing
+		case page.Language != "" && parser.PrimaryLanguage(page.HTMLLang) != page.Language:
+			page.Issue = LanguageIssueMismatch
+		}
+		if all || page.Issue != "" {
+			pages = append(pages, page)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read page languages: %w", err)
+	}
+	return pages, nil
+}
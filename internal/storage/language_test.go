@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestGetPageLanguages(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "language.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	texts := map[string]*crawler.PageText{
+		"https://example.com/en/":      {Language: "en", HTMLLang: "en-US"},
+		"https://example.com/de/":      {Language: "de", HTMLLang: "en"},
+		"https://example.com/ja/":      {Language: "ja"},
+		"https://example.com/gallery/": {HTMLLang: "fr"},
+	}
+	var urls []string
+	for u := range texts {
+		urls = append(urls, u)
+	}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range texts {
+		item, _ := store.GetNextFromQueue()
+		page := &crawler.PageData{URL: item.URL, StatusCode: 200, HTTPHeaders: map[string]string{}, CrawledAt: time.Now(),
+			Text: texts[item.URL]}
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	pages, err := store.GetPageLanguages(false)
+	if err != nil {
+		t.Fatalf("GetPageLanguages failed: %v", err)
+	}
+	want := []PageLanguage{
+		{URL: "https://example.com/de/", HTMLLang: "en", Language: "de", Issue: LanguageIssueMismatch},
+		{URL: "https://example.com/ja/", Language: "ja", Issue: LanguageIssueMissing},
+	}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("Expected %+v, got %+v", want, pages)
+	}
+
+	if pages, err = store.GetPageLanguages(true); err != nil || len(pages) != 4 ||
+		pages[1] != (PageLanguage{URL: "https://example.com/en/", HTMLLang: "en-US", Language: "en"}) {
+		t.Errorf("Expected every page, got %+v (%v)", pages, err)
+	}
+}
@@ -0,0 +1,94 @@
+// Package storage — database maintenance.
+//
+// Long-running crawls grow the database without bound: crawl_errors keeps
+// every failed attempt, and deleted rows leave free pages SQLite does not
+// return to the file system. `linktadoru db maintain` prunes old error rows,
+// rebuilds the files with VACUUM, refreshes the query planner statistics with
+// ANALYZE and reports how much space each table takes.
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TableSize is the disk space one table takes, its indexes included
+type TableSize struct {
+	Database string `json:"database"` // "main", or "results" for the attached results database
+	Table    string `json:"table"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// PruneErrors deletes crawl_errors rows that occurred before cutoff and
+// returns how many were deleted. Pages keep their last error in
+// pages.last_error_type/last_error_message, so only the history is lost.
+func (s *SQLiteStorage) PruneErrors(cutoff time.Time) (int64, error) {
+	result, err := s.db.Exec("DELETE FROM crawl_errors WHERE occurred_at < ?", cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune crawl errors: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Vacuum rebuilds the database files, returning the free pages left by
+// deleted rows to the file system. It needs free disk space about the size
+// of the largest file and blocks writers while it runs.
+func (s *SQLiteStorage) Vacuum() error {
+	for _, schema := range s.schemas() {
+		if _, err := s.db.Exec("VACUUM " + schema); err != nil {
+			return fmt.Errorf("failed to vacuum %s database: %w", schema, err)
+		}
+	}
+	return nil
+}
+
+// Analyze refreshes the statistics the query planner uses to choose indexes
+func (s *SQLiteStorage) Analyze() error {
+	if _, err := s.db.Exec("ANALYZE"); err != nil {
+		return fmt.Errorf("failed to analyze database: %w", err)
+	}
+	return nil
+}
+
+// TableSizes returns the disk space of every table, largest first. Index
+// pages count towards the table they index.
+func (s *SQLiteStorage) TableSizes() ([]TableSize, error) {
+	var sizes []TableSize
+	for _, schema := range s.schemas() {
+		rows, err := s.db.Query(`
+			SELECT m.tbl_name, SUM(d.pgsize)
+			FROM dbstat(?) d
+			JOIN `+schema+`.sqlite_master m ON m.name = d.name
+			GROUP BY m.tbl_name
+		`, schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s table sizes: %w", schema, err)
+		}
+		for rows.Next() {
+			size := TableSize{Database: schema}
+			if err := rows.Scan(&size.Table, &size.Bytes); err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("failed to scan table size: %w", err)
+			}
+			sizes = append(sizes, size)
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s table sizes: %w", schema, err)
+		}
+	}
+
+	sort.SliceStable(sizes, func(i, j int) bool { return sizes[i].Bytes > sizes[j].Bytes })
+	return sizes, nil
+}
+
+// schemas returns the names of the database files in use: main, and the
+// attached results database when there is one
+func (s *SQLiteStorage) schemas() []string {
+	if s.resultsPath != "" {
+		return []string{"main", resultsSchema}
+	}
+	return []string{"main"}
+}
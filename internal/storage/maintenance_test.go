@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestPruneErrors(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "prune.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Now()
+	for _, age := range []time.Duration{48 * time.Hour, 36 * time.Hour, time.Hour} {
+		if err := store.SaveError(&crawler.CrawlError{
+			URL: "https://example.com/", ErrorType: "network_error", ErrorMessage: "refused", OccurredAt: now.Add(-age),
+		}); err != nil {
+			t.Fatalf("Failed to save error: %v", err)
+		}
+	}
+
+	pruned, err := store.PruneErrors(now.Add(-24 * time.Hour))
+	if err != nil || pruned != 2 {
+		t.Errorf("Expected 2 pruned rows, got %d (err %v)", pruned, err)
+	}
+	var remaining int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM crawl_errors").Scan(&remaining); err != nil || remaining != 1 {
+		t.Errorf("Expected 1 remaining error row, got %d (err %v)", remaining, err)
+	}
+}
+
+func TestVacuumAnalyzeAndTableSizes(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewSQLiteStorageWithResults(filepath.Join(dir, "queue.db"), filepath.Join(dir, "results.db"), "")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	if err := store.AddToQueue([]string{"https://example.com/"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+
+	if err := store.Vacuum(); err != nil {
+		t.Fatalf("Vacuum failed: %v", err)
+	}
+	if err := store.Analyze(); err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	sizes, err := store.TableSizes()
+	if err != nil {
+		t.Fatalf("TableSizes failed: %v", err)
+	}
+	found := map[string]bool{}
+	for i, size := range sizes {
+		found[size.Database+"."+size.Table] = true
+		if size.Bytes <= 0 || (i > 0 && size.Bytes > sizes[i-1].Bytes) {
+			t.Errorf("Expected positive sizes, largest first: %+v", sizes)
+			break
+		}
+	}
+	if !found["main.pages"] || !found["results.link_relations"] {
+		t.Errorf("Expected sizes for both database files, got %+v", sizes)
+	}
+}
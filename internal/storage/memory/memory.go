@@ -0,0 +1,593 @@
+// Package memory provides a crawl storage backend that keeps the queue and
+// the results in process memory. Nothing is written to disk and everything is
+// gone when the process exits, which suits ephemeral crawls, benchmarks and
+// library use. Importing the package registers it as the "memory" storage
+// driver.
+package memory
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// DriverName is the storage_driver value that selects this backend
+const DriverName = "memory"
+
+// Page statuses, as in the SQLite pages table
+const (
+	statusDiscovered = "discovered"
+	statusPending    = "pending"
+	statusProcessing = "processing"
+	statusCompleted  = "completed"
+	statusSkipped    = "skipped"
+	statusError      = "error"
+)
+
+// retryableErrorTypes are the error types GetRetryablePages and
+// RequeueErrorPages retry
+var retryableErrorTypes = map[string]bool{
+	crawler.ErrorTypeTimeout:           true,
+	crawler.ErrorTypeConnectionRefused: true,
+	crawler.ErrorTypeConnectionReset:   true,
+	crawler.ErrorTypeDNSFailure:        true,
+	crawler.ErrorTypeServerError:       true,
+}
+
+func init() {
+	storage.Register(DriverName, func(cfg *config.CrawlConfig) (crawler.Storage, error) {
+		s := New()
+		s.SetHostRotation(cfg.QueueOrder != config.QueueOrderFIFO)
+		return s, nil
+	})
+}
+
+// Storage implements crawler.Storage in memory with the semantics of the
+// SQLite backend: one page record per URL serves as queue entry and result,
+// pending pages are claimed oldest first (or rotating across hosts), and
+// links to unknown URLs create 'discovered' pages that are only crawled once
+// AddToQueue promotes them. It is safe for concurrent use.
+type Storage struct {
+	mu           sync.Mutex
+	pages        []*page               // By ID - 1
+	byURL        map[string]*page      // By URL
+	hosts        map[string]*hostQueue // By host[:port]
+	pendingHosts map[string]*hostQueue // Hosts with pending pages
+	counts       map[string]int        // Pages per status
+	links        []crawler.LinkData
+	linkKeys     map[[2]int]bool // Source and target page IDs of saved links
+	errors       []crawler.CrawlError
+	checks       map[int]crawler.ExternalCheck // By page ID
+	meta         map[string]string
+	rotateHosts  bool
+	order        uint64 // Last queue position handed out
+	claims       uint64 // Claims made so far, ordering the host turns
+}
+
+// page is the record of one URL
+type page struct {
+	id                  int
+	url                 string
+	host                string
+	status              string
+	order               uint64 // Queue position, lowest claimed first (added_at in SQLite)
+	processingStartedAt time.Time
+	retryCount          int
+	errorType           string
+	errorMessage        string
+	data                *crawler.PageData // Set once the page is completed
+}
+
+// hostQueue holds the pending pages of one host
+type hostQueue struct {
+	pending    entryHeap
+	count      int    // Pending pages; pending may also hold stale entries
+	processing int    // Pages of the host being processed
+	lastClaim  uint64 // Claim number of the host's last turn; 0 = never claimed
+}
+
+// queueEntry is a page's place in its host's queue. It is stale once the page
+// leaves 'pending' or is queued again under a new position.
+type queueEntry struct {
+	page  *page
+	order uint64
+}
+
+func (e queueEntry) stale() bool {
+	return e.page.status != statusPending || e.page.order != e.order
+}
+
+// entryHeap orders queue entries by position
+type entryHeap []queueEntry
+
+func (h entryHeap) Len() int           { return len(h) }
+func (h entryHeap) Less(i, j int) bool { return h[i].order < h[j].order }
+func (h entryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *entryHeap) Push(x any)        { *h = append(*h, x.(queueEntry)) }
+func (h *entryHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// peek drops stale entries and returns the oldest pending page, or nil
+func (q *hostQueue) peek() *page {
+	for q.pending.Len() > 0 {
+		if e := q.pending[0]; !e.stale() {
+			return e.page
+		}
+		heap.Pop(&q.pending)
+	}
+	return nil
+}
+
+// New creates an empty in-memory storage
+func New() *Storage {
+	return &Storage{
+		byURL:        make(map[string]*page),
+		hosts:        make(map[string]*hostQueue),
+		pendingHosts: make(map[string]*hostQueue),
+		counts:       make(map[string]int),
+		linkKeys:     make(map[[2]int]bool),
+		checks:       make(map[int]crawler.ExternalCheck),
+		meta:         make(map[string]string),
+	}
+}
+
+// SetHostRotation switches the queue between plain FIFO order (the default)
+// and rotating claims across hosts (queue_order: host), as for SQLite
+func (s *Storage) SetHostRotation(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rotateHosts = enabled
+}
+
+// AddToQueue queues URLs for crawling. New URLs and 'discovered' link-graph
+// nodes become 'pending' at the tail of the queue; URLs in any other status
+// are left alone.
+func (s *Storage) AddToQueue(urls []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, url := range urls {
+		p, ok := s.byURL[url]
+		if !ok {
+			p = s.newPage(url)
+		} else if p.status != statusDiscovered {
+			continue
+		}
+		s.order++
+		p.order = s.order
+		s.setStatus(p, statusPending)
+	}
+	return nil
+}
+
+// GetNextFromQueue claims the next pending URL, or returns nil when none is pending
+func (s *Storage) GetNextFromQueue() (*crawler.URLItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.claim(), nil
+}
+
+// GetNextBatchFromQueue claims up to n pending URLs. In FIFO order the batch
+// is returned ordered by ID, like the SQLite backend.
+func (s *Storage) GetNextBatchFromQueue(n int) ([]crawler.URLItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []crawler.URLItem
+	for len(items) < n {
+		item := s.claim()
+		if item == nil {
+			break
+		}
+		items = append(items, *item)
+	}
+	if !s.rotateHosts {
+		sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	}
+	return items, nil
+}
+
+// claim marks the next pending page as processing. In FIFO order that is the
+// oldest pending page; with host rotation it is the oldest page of the host
+// with the fewest pages in flight, then the longest since its last turn,
+// then the longest waiting.
+func (s *Storage) claim() *crawler.URLItem {
+	var bestHost *hostQueue
+	var best *page
+	for _, h := range s.pendingHosts {
+		top := h.peek()
+		if top == nil {
+			continue
+		}
+		if best == nil || s.claimsBefore(h, top, bestHost, best) {
+			bestHost, best = h, top
+		}
+	}
+	if best == nil {
+		return nil
+	}
+
+	heap.Pop(&bestHost.pending)
+	s.claims++
+	bestHost.lastClaim = s.claims
+	best.processingStartedAt = time.Now()
+	s.setStatus(best, statusProcessing)
+	return &crawler.URLItem{ID: best.id, URL: best.url, RetryCount: best.retryCount}
+}
+
+// claimsBefore reports whether page a of host ha is claimed before page b of host hb
+func (s *Storage) claimsBefore(ha *hostQueue, a *page, hb *hostQueue, b *page) bool {
+	if s.rotateHosts {
+		if ha.processing != hb.processing {
+			return ha.processing < hb.processing
+		}
+		if ha.lastClaim != hb.lastClaim {
+			return ha.lastClaim < hb.lastClaim
+		}
+	}
+	return a.order < b.order
+}
+
+// UpdatePageStatus sets the status of a page
+func (s *Storage) UpdatePageStatus(id int, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p := s.pageByID(id); p != nil {
+		s.setStatus(p, status)
+	}
+	return nil
+}
+
+// SavePageResult saves the crawl results for a page
+func (s *Storage) SavePageResult(id int, page *crawler.PageData) error {
+	return s.SavePageResults([]crawler.CompletedPage{{ID: id, Page: page}})
+}
+
+// SavePageResults saves the crawl results for several pages and marks them completed
+func (s *Storage) SavePageResults(pages []crawler.CompletedPage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, cp := range pages {
+		if p := s.pageByID(cp.ID); p != nil {
+			p.data = cp.Page
+			s.setStatus(p, statusCompleted)
+		}
+	}
+	return nil
+}
+
+// SavePageError marks a page as errored, counting the attempt
+func (s *Storage) SavePageError(id int, errorType, errorMessage string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p := s.pageByID(id); p != nil {
+		p.errorType, p.errorMessage = errorType, errorMessage
+		p.retryCount++
+		s.setStatus(p, statusError)
+	}
+	return nil
+}
+
+// SavePageSkipped marks a page as skipped (e.g., robots.txt disallow)
+func (s *Storage) SavePageSkipped(id int, reason, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p := s.pageByID(id); p != nil {
+		p.errorType, p.errorMessage = reason, message
+		s.setStatus(p, statusSkipped)
+	}
+	return nil
+}
+
+// SaveLink saves a link, creating 'discovered' pages for unknown URLs. A
+// second link between the same pages is ignored.
+func (s *Storage) SaveLink(link *crawler.LinkData) error {
+	return s.SaveLinks([]*crawler.LinkData{link})
+}
+
+// SaveLinks saves several links (see SaveLink)
+func (s *Storage) SaveLinks(links []*crawler.LinkData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, link := range links {
+		key := [2]int{s.pageFor(link.SourceURL).id, s.pageFor(link.TargetURL).id}
+		if s.linkKeys[key] {
+			continue
+		}
+		s.linkKeys[key] = true
+		s.links = append(s.links, *link)
+	}
+	return nil
+}
+
+// SaveError records crawl error details
+func (s *Storage) SaveError(crawlErr *crawler.CrawlError) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors = append(s.errors, *crawlErr)
+	return nil
+}
+
+// SaveExternalCheck records the result of verifying an out-of-scope link. The
+// URL keeps its status; a later check replaces an earlier one.
+func (s *Storage) SaveExternalCheck(check *crawler.ExternalCheck) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks[s.pageFor(check.URL).id] = *check
+	return nil
+}
+
+// HasExternalCheck reports whether url has already been verified
+func (s *Storage) HasExternalCheck(url string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.byURL[url]
+	if !ok {
+		return false
+	}
+	_, checked := s.checks[p.id]
+	return checked
+}
+
+// GetQueueStatus returns counts by status
+func (s *Storage) GetQueueStatus() (pending int, processing int, completed int, errors int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counts[statusPending], s.counts[statusProcessing], s.counts[statusCompleted], s.counts[statusError], nil
+}
+
+// GetProcessingItems returns the pages being processed, most recently claimed first
+func (s *Storage) GetProcessingItems() ([]crawler.URLItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var processing []*page
+	for _, p := range s.pages {
+		if p.status == statusProcessing {
+			processing = append(processing, p)
+		}
+	}
+	sort.SliceStable(processing, func(i, j int) bool {
+		return processing[i].processingStartedAt.After(processing[j].processingStartedAt)
+	})
+
+	items := make([]crawler.URLItem, 0, len(processing))
+	for _, p := range processing {
+		items = append(items, crawler.URLItem{ID: p.id, URL: p.url})
+	}
+	return items, nil
+}
+
+// GetQueuedURLs returns up to limit URLs still waiting to be crawled
+// ('pending' or 'processing'), oldest first
+func (s *Storage) GetQueuedURLs(limit int) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var queued []*page
+	for _, p := range s.pages {
+		if p.status == statusPending || p.status == statusProcessing {
+			queued = append(queued, p)
+		}
+	}
+	sort.SliceStable(queued, func(i, j int) bool { return queued[i].order < queued[j].order })
+
+	var urls []string
+	for _, p := range queued {
+		if len(urls) >= limit {
+			break
+		}
+		urls = append(urls, p.url)
+	}
+	return urls, nil
+}
+
+// CleanupStaleProcessing returns pages processing for longer than timeout to
+// 'pending' and returns how many
+func (s *Storage) CleanupStaleProcessing(timeout time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-timeout)
+	recovered := 0
+	for _, p := range s.pages {
+		if p.status == statusProcessing && p.processingStartedAt.Before(cutoff) {
+			p.processingStartedAt = time.Time{}
+			s.setStatus(p, statusPending)
+			recovered++
+		}
+	}
+	return recovered, nil
+}
+
+// HasQueuedItems reports whether any page is pending or processing
+func (s *Storage) HasQueuedItems() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counts[statusPending]+s.counts[statusProcessing] > 0, nil
+}
+
+// GetRetryablePages returns errored pages with a transient error type and
+// fewer than maxRetries attempts, least retried first
+func (s *Storage) GetRetryablePages(maxRetries int) ([]crawler.URLItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	retryable := s.retryable(maxRetries)
+	sort.SliceStable(retryable, func(i, j int) bool {
+		if retryable[i].retryCount != retryable[j].retryCount {
+			return retryable[i].retryCount < retryable[j].retryCount
+		}
+		return retryable[i].order < retryable[j].order
+	})
+
+	var items []crawler.URLItem
+	for _, p := range retryable {
+		items = append(items, crawler.URLItem{ID: p.id, URL: p.url, RetryCount: p.retryCount})
+	}
+	return items, nil
+}
+
+// RequeueErrorPages moves the pages GetRetryablePages returns back to 'pending'
+func (s *Storage) RequeueErrorPages(maxRetries int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	retryable := s.retryable(maxRetries)
+	for _, p := range retryable {
+		p.processingStartedAt = time.Time{}
+		s.setStatus(p, statusPending)
+	}
+	return len(retryable), nil
+}
+
+// retryable returns the errored pages eligible for a retry, by ID
+func (s *Storage) retryable(maxRetries int) []*page {
+	var pages []*page
+	for _, p := range s.pages {
+		if p.status == statusError && p.retryCount < maxRetries && retryableErrorTypes[p.errorType] {
+			pages = append(pages, p)
+		}
+	}
+	return pages
+}
+
+// GetMeta retrieves a metadata value, "" when unset
+func (s *Storage) GetMeta(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.meta[key], nil
+}
+
+// SetMeta stores a metadata value
+func (s *Storage) SetMeta(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.meta[key] = value
+	return nil
+}
+
+// GetURLStatus returns the status of a URL and whether it is known
+func (s *Storage) GetURLStatus(url string) (status string, exists bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.byURL[url]; ok {
+		return p.status, true
+	}
+	return "", false
+}
+
+// Close releases nothing; the data stays readable until the Storage is dropped
+func (s *Storage) Close() error {
+	return nil
+}
+
+// Page returns the saved crawl results of a completed page
+func (s *Storage) Page(url string) (*crawler.PageData, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.byURL[url]; ok && p.data != nil {
+		return p.data, true
+	}
+	return nil, false
+}
+
+// Links returns the saved links in the order they were saved
+func (s *Storage) Links() []crawler.LinkData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]crawler.LinkData(nil), s.links...)
+}
+
+// Errors returns the recorded crawl errors in the order they occurred
+func (s *Storage) Errors() []crawler.CrawlError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]crawler.CrawlError(nil), s.errors...)
+}
+
+// pageFor returns the page of url, creating a 'discovered' link-graph node
+// for an unknown URL
+func (s *Storage) pageFor(url string) *page {
+	if p, ok := s.byURL[url]; ok {
+		return p
+	}
+	p := s.newPage(url)
+	s.order++
+	p.order = s.order
+	s.setStatus(p, statusDiscovered)
+	return p
+}
+
+// newPage adds a page record without a status
+func (s *Storage) newPage(url string) *page {
+	p := &page{id: len(s.pages) + 1, url: url, host: hostOf(url)}
+	s.pages = append(s.pages, p)
+	s.byURL[url] = p
+	return p
+}
+
+// pageByID returns the page with the given ID, or nil
+func (s *Storage) pageByID(id int) *page {
+	if id < 1 || id > len(s.pages) {
+		return nil
+	}
+	return s.pages[id-1]
+}
+
+// setStatus moves a page to status, keeping the status counts and host
+// queues in step. A page entering 'pending' joins its host's queue at its
+// current position.
+func (s *Storage) setStatus(p *page, status string) {
+	old := p.status
+	if old == status {
+		return
+	}
+	h := s.hosts[p.host]
+	if h == nil {
+		h = &hostQueue{}
+		s.hosts[p.host] = h
+	}
+
+	if old != "" {
+		s.counts[old]--
+	}
+	switch old {
+	case statusPending:
+		h.count--
+		if h.count == 0 {
+			delete(s.pendingHosts, p.host)
+		}
+	case statusProcessing:
+		h.processing--
+	}
+
+	p.status = status
+	s.counts[status]++
+	switch status {
+	case statusPending:
+		h.count++
+		s.pendingHosts[p.host] = h
+		heap.Push(&h.pending, queueEntry{page: p, order: p.order})
+	case statusProcessing:
+		h.processing++
+	}
+}
+
+// hostOf returns the host[:port] of a URL, the way the SQLite host column derives it
+func hostOf(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	}
+	host, _, _ := strings.Cut(url, "/")
+	return host
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+func TestQueueLifecycle(t *testing.T) {
+	s := New()
+
+	// Link targets are link-graph nodes until queued
+	if err := s.SaveLinks([]*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/a"},
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/a"},
+	}); err != nil {
+		t.Fatalf("SaveLinks failed: %v", err)
+	}
+	if len(s.Links()) != 1 {
+		t.Errorf("Expected duplicate links to be ignored, got %d", len(s.Links()))
+	}
+	if status, _ := s.GetURLStatus("https://example.com/a"); status != statusDiscovered {
+		t.Errorf("Expected discovered link target, got %q", status)
+	}
+	if item, _ := s.GetNextFromQueue(); item != nil {
+		t.Errorf("Expected discovered pages not to be claimed, got %+v", item)
+	}
+
+	if err := s.AddToQueue([]string{"https://example.com/b", "https://example.com/a", "https://example.com/b"}); err != nil {
+		t.Fatalf("AddToQueue failed: %v", err)
+	}
+	pending, _, _, _, _ := s.GetQueueStatus()
+	if pending != 2 {
+		t.Errorf("Expected 2 pending pages, got %d", pending)
+	}
+
+	first, _ := s.GetNextFromQueue()
+	second, _ := s.GetNextFromQueue()
+	if first == nil || second == nil || first.URL != "https://example.com/b" || second.URL != "https://example.com/a" {
+		t.Fatalf("Expected pages in queue order, got %+v and %+v", first, second)
+	}
+
+	if err := s.SavePageResult(first.ID, &crawler.PageData{URL: first.URL, StatusCode: 200}); err != nil {
+		t.Fatalf("SavePageResult failed: %v", err)
+	}
+	if page, ok := s.Page(first.URL); !ok || page.StatusCode != 200 {
+		t.Errorf("Expected saved page, got %+v", page)
+	}
+
+	// A transient error is retried until maxRetries attempts were made
+	if err := s.SavePageError(second.ID, "network_timeout", "timeout"); err != nil {
+		t.Fatalf("SavePageError failed: %v", err)
+	}
+	if n, _ := s.RequeueErrorPages(2); n != 1 {
+		t.Errorf("Expected 1 requeued page, got %d", n)
+	}
+	retry, _ := s.GetNextFromQueue()
+	if retry == nil || retry.ID != second.ID {
+		t.Fatalf("Expected the requeued page, got %+v", retry)
+	}
+	_ = s.SavePageError(retry.ID, "network_timeout", "timeout")
+	if n, _ := s.RequeueErrorPages(2); n != 0 {
+		t.Errorf("Expected no requeue after the last retry, got %d", n)
+	}
+
+	pending, processing, completed, errors, _ := s.GetQueueStatus()
+	if pending != 0 || processing != 0 || completed != 1 || errors != 1 {
+		t.Errorf("Unexpected queue status %d/%d/%d/%d", pending, processing, completed, errors)
+	}
+	if has, _ := s.HasQueuedItems(); has {
+		t.Error("Expected no queued items")
+	}
+}
+
+func TestCleanupStaleProcessing(t *testing.T) {
+	s := New()
+	_ = s.AddToQueue([]string{"https://example.com/"})
+	item, _ := s.GetNextFromQueue()
+
+	if n, _ := s.CleanupStaleProcessing(time.Hour); n != 0 {
+		t.Errorf("Expected nothing recovered, got %d", n)
+	}
+	if status, _ := s.GetURLStatus(item.URL); status != statusProcessing {
+		t.Errorf("Expected a fresh claim to stay processing, got %q", status)
+	}
+
+	if n, _ := s.CleanupStaleProcessing(0); n != 1 {
+		t.Errorf("Expected 1 page recovered, got %d", n)
+	}
+	again, _ := s.GetNextFromQueue()
+	if again == nil || again.ID != item.ID {
+		t.Errorf("Expected the stale page to be claimable again, got %+v", again)
+	}
+}
+
+func TestHostRotation(t *testing.T) {
+	s := New()
+	s.SetHostRotation(true)
+	_ = s.AddToQueue([]string{
+		"https://a.example/1", "https://a.example/2", "https://a.example/3",
+		"https://b.example/1", "https://c.example:8080/1",
+	})
+
+	items, _ := s.GetNextBatchFromQueue(4)
+	var hosts []string
+	for _, item := range items {
+		hosts = append(hosts, hostOf(item.URL))
+	}
+	want := []string{"a.example", "b.example", "c.example:8080", "a.example"}
+	if len(hosts) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, hosts)
+	}
+	for i := range want {
+		if hosts[i] != want[i] {
+			t.Errorf("Expected claims to rotate across hosts %v, got %v", want, hosts)
+			break
+		}
+	}
+}
+
+func TestMemoryDriverRegistered(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.StorageDriver = DriverName
+	store, err := storage.Open(cfg)
+	if err != nil {
+		t.Fatalf("Failed to open memory driver: %v", err)
+	}
+	if s, ok := store.(*Storage); !ok || !s.rotateHosts {
+		t.Errorf("Expected a memory Storage rotating hosts, got %T", store)
+	}
+}
@@ -1,18 +1,128 @@
 // Package storage — schema migrations.
 //
-// This file rebuilds an existing `pages` table whose CHECK constraint predates
+// Migrations are ordered steps, each tagged with the schema version that
+// introduced it. Migrate applies the steps newer than the schema_version
+// recorded in crawl_meta, in order, in one transaction with the schema
+// creation and the new schema_version, so an interrupted upgrade leaves the
+// database as it was. Opening a database never migrates it: only
+// `linktadoru db migrate` and the crawl command do (MigrateSQLiteStorage).
+// Each step still detects whether its change is needed, which keeps databases
+// created before versions were recorded (schema_version 0) safe to upgrade.
+// Changes that only add tables, indexes or views need no step: schemaSQL
+// creates them.
+//
+// migratePagesAddDiscovered rebuilds an existing `pages` table whose CHECK constraint predates
 // the 'discovered' status added for issue #46. SQLite cannot ALTER a CHECK
 // constraint in place, so the table is rebuilt with the standard rename/copy
 // procedure. The migration is a no-op on a fresh database (the table does not
 // exist yet) and on a database already carrying the 'discovered' status.
+// Columns added to other tables later are migrated with ALTER TABLE.
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
+// migration is one schema upgrade step
+type migration struct {
+	version int                 // Schema version that introduced the change
+	name    string              // Short description, reported by `linktadoru db migrate`
+	apply   func(*sql.Tx) error // Applies the change; a no-op when already applied
+}
+
+// migrations are the schema upgrade steps, in ascending version order. Add a
+// step here, with a new SchemaVersion, whenever an existing table changes.
+var migrations = []migration{
+	{2, "add 'discovered' page status", migratePagesAddDiscovered},
+	{8, "add page_alternates.source", migratePageAlternatesAddSource},
+	{14, "add pages.x_robots_tag", migratePagesAddXRobotsTag},
+	{16, "add pages.host", migratePagesAddHost},
+	{18, "add page_metrics.click_depth", migratePageMetricsAddClickDepth},
+	{20, "add pages.claimed_by", migratePagesAddClaimedBy},
+	{23, "add pages.rendered", migratePagesAddRendered},
+	{26, "add page_content.simhash", migratePageContentAddSimHash},
+	{27, "add page_content.language and html_lang", migratePageContentAddLanguage},
+	{28, "count host_frontier pages", migrateHostFrontierAddCounts},
+}
+
+// Migrate upgrades an existing database written with an older schema to
+// SchemaVersion. The pending steps, the schema creation and the new
+// schema_version are applied in one transaction, with foreign keys off so
+// steps can rebuild referenced tables. A fresh or current database is left
+// untouched.
+func (s *SQLiteStorage) Migrate() error {
+	exists, err := s.mainTableExists("pages")
+	if err != nil || !exists {
+		return err
+	}
+	stored, err := s.storedSchemaVersion()
+	if err != nil || stored >= SchemaVersion {
+		return err
+	}
+
+	// PRAGMA foreign_keys is a no-op inside a transaction, so it is toggled
+	// around the transaction on one pinned connection
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get migration connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return fmt.Errorf("failed to disable foreign keys: %w", err)
+	}
+	if s.resultsPath == "" {
+		defer func() { _, _ = conn.ExecContext(ctx, "PRAGMA foreign_keys = ON") }()
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var applied []string
+	for _, m := range migrations {
+		if m.version <= stored {
+			continue
+		}
+		if err := m.apply(tx); err != nil {
+			return fmt.Errorf("failed to migrate to schema version %d (%s): %w", m.version, m.name, err)
+		}
+		applied = append(applied, m.name)
+	}
+
+	// Create the tables added since and recreate the indexes and views that a
+	// table rebuild dropped
+	schema := schemaSQL
+	if s.resultsPath != "" {
+		schema = queueSchemaSQL
+	}
+	if _, err := tx.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+	if _, err := tx.Exec("INSERT OR REPLACE INTO crawl_meta (key, value) VALUES (?, ?)",
+		metaSchemaVersion, strconv.Itoa(SchemaVersion)); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration: %w", err)
+	}
+
+	s.migrated = append(s.migrated, applied...)
+	return nil
+}
+
+// AppliedMigrations returns the names of the migration steps Migrate applied,
+// oldest first; empty when the schema was already current
+func (s *SQLiteStorage) AppliedMigrations() []string {
+	return s.migrated
+}
+
 // pagesBaseColumns are the non-generated columns of the pages table, in a stable
 // order. Generated columns (content_type, content_length, last_modified, server,
 // content_encoding, x_cache) are derived and must NOT be copied explicitly.
@@ -26,19 +136,19 @@ const pagesBaseColumns = "id, url, status, added_at, processing_started_at, " +
 // 'discovered' on databases created before issue #46. It detects the need for
 // migration from the stored table DDL, then rebuilds the table preserving all
 // rows and ids. Indexes and views dropped by the rebuild are recreated by the
-// subsequent schemaSQL run in InitSchema.
+// schemaSQL run that follows the steps in Migrate.
 //
 // Scope: this migration is guaranteed only for databases created with the
 // current released pages schema (status CHECK ... 'skipped', 'error'). It is
 // intentionally conservative — it copies the fixed set of base columns
 // (pagesBaseColumns) and locates the CHECK list by its exact text. Against an
 // older/foreign schema where that text is absent, it ABORTS with an error
-// rather than risk a lossy rebuild; the caller surfaces the error and the
-// database is left untouched. Broader cross-version migration (dynamic column
+// rather than risk a lossy rebuild; Migrate rolls back and surfaces the error,
+// leaving the database untouched. Broader cross-version migration (dynamic column
 // intersection, status normalisation) is out of scope here.
-func (s *SQLiteStorage) migratePagesAddDiscovered() error {
+func migratePagesAddDiscovered(tx *sql.Tx) error {
 	var ddl string
-	err := s.db.QueryRow(
+	err := tx.QueryRow(
 		"SELECT sql FROM sqlite_master WHERE type='table' AND name='pages'",
 	).Scan(&ddl)
 	if err == sql.ErrNoRows {
@@ -65,24 +175,22 @@ func (s *SQLiteStorage) migratePagesAddDiscovered() error {
 	}
 	newDDL = widened
 
-	// Foreign keys must be off while the referenced table is rebuilt; this PRAGMA
-	// is a no-op inside a transaction, so toggle it around the transaction.
-	if _, err := s.db.Exec("PRAGMA foreign_keys = OFF"); err != nil {
-		return fmt.Errorf("failed to disable foreign keys: %w", err)
-	}
-	defer func() { _, _ = s.db.Exec("PRAGMA foreign_keys = ON") }()
-
-	tx, err := s.db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin migration transaction: %w", err)
-	}
-	defer func() { _ = tx.Rollback() }()
-
+	// Foreign keys are off while the referenced table is rebuilt (see Migrate)
 	stmts := []string{
 		// Drop views that reference pages; schemaSQL recreates them afterwards.
 		"DROP VIEW IF EXISTS links",
 		"DROP VIEW IF EXISTS completed_pages",
 		"DROP VIEW IF EXISTS queue_status",
+		"DROP VIEW IF EXISTS feed_pages",
+		"DROP VIEW IF EXISTS external_link_status",
+		"DROP VIEW IF EXISTS assets",
+		"DROP VIEW IF EXISTS heading_issues",
+		"DROP VIEW IF EXISTS host_summary",
+		"DROP VIEW IF EXISTS status_summary",
+		"DROP VIEW IF EXISTS daily_crawl_counts",
+		"DROP VIEW IF EXISTS page_assets",
+		"DROP VIEW IF EXISTS page_indexability",
+		"DROP VIEW IF EXISTS canonical_pages",
 		newDDL,
 		fmt.Sprintf("INSERT INTO pages_new (%s) SELECT %s FROM pages",
 			pagesBaseColumns, pagesBaseColumns),
@@ -94,9 +202,224 @@ func (s *SQLiteStorage) migratePagesAddDiscovered() error {
 			return fmt.Errorf("migration step failed (%s): %w", stmt, err)
 		}
 	}
+	return nil
+}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit pages migration: %w", err)
+// queryer is what columnState reads table definitions with: a migration
+// transaction or a connection pool
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// columnState reports whether table exists and whether it has the named column
+func columnState(q queryer, table, column string) (tableExists, hasColumn bool, err error) {
+	rows, err := q.Query("SELECT name FROM pragma_table_xinfo(?)", table)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to read %s columns: %w", table, err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return false, false, fmt.Errorf("failed to scan %s column: %w", table, err)
+		}
+		tableExists = true
+		if name == column {
+			hasColumn = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, false, fmt.Errorf("failed to read %s columns: %w", table, err)
+	}
+	return tableExists, hasColumn, nil
+}
+
+// migratePageAlternatesAddSource adds the source column to a page_alternates
+// table created before Link headers were parsed (schema version 8). Existing
+// rows all came from HTML, which is the column default.
+func migratePageAlternatesAddSource(tx *sql.Tx) error {
+	exists, hasSource, err := columnState(tx, "page_alternates", "source")
+	if err != nil {
+		return err
+	}
+	if !exists || hasSource {
+		return nil // fresh database or already migrated
+	}
+
+	if _, err := tx.Exec("ALTER TABLE page_alternates ADD COLUMN source TEXT NOT NULL DEFAULT 'html'"); err != nil {
+		return fmt.Errorf("failed to add page_alternates.source: %w", err)
+	}
+	return nil
+}
+
+// migratePagesAddXRobotsTag adds the x_robots_tag column to a pages table
+// created before X-Robots-Tag headers were recorded (schema version 14).
+// Existing rows keep NULL: their headers were not inspected.
+func migratePagesAddXRobotsTag(tx *sql.Tx) error {
+	exists, hasColumn, err := columnState(tx, "pages", "x_robots_tag")
+	if err != nil {
+		return err
+	}
+	if !exists || hasColumn {
+		return nil // fresh database or already migrated
+	}
+
+	if _, err := tx.Exec("ALTER TABLE pages ADD COLUMN x_robots_tag TEXT"); err != nil {
+		return fmt.Errorf("failed to add pages.x_robots_tag: %w", err)
+	}
+	return nil
+}
+
+// migratePagesAddClaimedBy adds the column recording which crawl process
+// claimed a page to a pages table created before schema version 20. Pages
+// already processing keep a NULL owner and are treated as abandoned.
+func migratePagesAddClaimedBy(tx *sql.Tx) error {
+	exists, hasColumn, err := columnState(tx, "pages", "claimed_by")
+	if err != nil {
+		return err
+	}
+	if !exists || hasColumn {
+		return nil // fresh database or already migrated
+	}
+
+	if _, err := tx.Exec("ALTER TABLE pages ADD COLUMN claimed_by TEXT"); err != nil {
+		return fmt.Errorf("failed to add pages.claimed_by: %w", err)
+	}
+	return nil
+}
+
+// migratePagesAddHost adds the generated host column used by the host
+// frontier to a pages table created before schema version 16. SQLite can add
+// VIRTUAL (but not STORED) generated columns in place.
+func migratePagesAddHost(tx *sql.Tx) error {
+	exists, hasColumn, err := columnState(tx, "pages", "host")
+	if err != nil {
+		return err
+	}
+	if !exists || hasColumn {
+		return nil // fresh database or already migrated
+	}
+
+	if _, err := tx.Exec("ALTER TABLE pages ADD COLUMN host TEXT GENERATED ALWAYS AS (" + pagesHostExpr + ") VIRTUAL"); err != nil {
+		return fmt.Errorf("failed to add pages.host: %w", err)
+	}
+	return nil
+}
+
+// migratePageMetricsAddClickDepth adds the click_depth column to a
+// page_metrics table created before click depths were stored (schema version
+// 18). Existing rows keep NULL until `analyze depth` runs.
+func migratePageMetricsAddClickDepth(tx *sql.Tx) error {
+	exists, hasColumn, err := columnState(tx, "page_metrics", "click_depth")
+	if err != nil {
+		return err
+	}
+	if !exists || hasColumn {
+		return nil // fresh database or already migrated
+	}
+
+	if _, err := tx.Exec("ALTER TABLE page_metrics ADD COLUMN click_depth INTEGER"); err != nil {
+		return fmt.Errorf("failed to add page_metrics.click_depth: %w", err)
+	}
+	return nil
+}
+
+// migratePagesAddRendered adds the column flagging pages parsed from a
+// rendered DOM to a pages table created before schema version 23. Existing
+// rows were parsed from their raw response.
+func migratePagesAddRendered(tx *sql.Tx) error {
+	exists, hasColumn, err := columnState(tx, "pages", "rendered")
+	if err != nil {
+		return err
+	}
+	if !exists || hasColumn {
+		return nil // fresh database or already migrated
+	}
+
+	if _, err := tx.Exec("ALTER TABLE pages ADD COLUMN rendered INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return fmt.Errorf("failed to add pages.rendered: %w", err)
+	}
+	return nil
+}
+
+// migratePageContentAddSimHash adds the simhash column to a page_content
+// table created before near-duplicates were detected (schema version 26).
+// Existing rows keep NULL until their page is crawled again.
+func migratePageContentAddSimHash(tx *sql.Tx) error {
+	exists, hasColumn, err := columnState(tx, "page_content", "simhash")
+	if err != nil {
+		return err
+	}
+	if !exists || hasColumn {
+		return nil // fresh database or already migrated
+	}
+
+	if _, err := tx.Exec("ALTER TABLE page_content ADD COLUMN simhash INTEGER"); err != nil {
+		return fmt.Errorf("failed to add page_content.simhash: %w", err)
+	}
+	return nil
+}
+
+// migratePageContentAddLanguage adds the detected and declared language
+// columns to a page_content table created before languages were stored
+// (schema version 27). Existing rows keep NULL until their page is crawled
+// again.
+func migratePageContentAddLanguage(tx *sql.Tx) error {
+	exists, hasColumn, err := columnState(tx, "page_content", "language")
+	if err != nil {
+		return err
+	}
+	if !exists || hasColumn {
+		return nil // fresh database or already migrated
+	}
+
+	for _, column := range []string{"language", "html_lang"} {
+		if _, err := tx.Exec("ALTER TABLE page_content ADD COLUMN " + column + " TEXT"); err != nil {
+			return fmt.Errorf("failed to add page_content.%s: %w", column, err)
+		}
+	}
+	return nil
+}
+
+// migrateHostFrontierAddCounts adds the pending and in-flight page counts to
+// a host_frontier table created before schema version 28, creating the table
+// for databases older than version 16, and fills them from pages. The
+// triggers that keep them up to date are created with the schema afterwards.
+func migrateHostFrontierAddCounts(tx *sql.Tx) error {
+	pagesExist, _, err := columnState(tx, "pages", "host")
+	if err != nil || !pagesExist {
+		return err // fresh database
+	}
+	exists, hasColumn, err := columnState(tx, "host_frontier", "pending")
+	if err != nil {
+		return err
+	}
+	if exists && hasColumn {
+		return nil // already migrated
+	}
+
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS host_frontier (
+			host TEXT PRIMARY KEY,
+			last_claimed_at INTEGER NOT NULL,
+			pending INTEGER NOT NULL DEFAULT 0,
+			inflight INTEGER NOT NULL DEFAULT 0
+		)`,
+		`INSERT INTO host_frontier (host, last_claimed_at, pending, inflight)
+		SELECT host, 0, SUM(status = 'pending'), SUM(status = 'processing')
+		FROM pages WHERE status IN ('pending', 'processing') GROUP BY host
+		ON CONFLICT(host) DO UPDATE SET pending = excluded.pending, inflight = excluded.inflight`,
+	}
+	if exists {
+		stmts = append([]string{
+			"ALTER TABLE host_frontier ADD COLUMN pending INTEGER NOT NULL DEFAULT 0",
+			"ALTER TABLE host_frontier ADD COLUMN inflight INTEGER NOT NULL DEFAULT 0",
+		}, stmts...)
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to count host_frontier pages: %w", err)
+		}
 	}
 	return nil
 }
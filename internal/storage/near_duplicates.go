@@ -0,0 +1,138 @@
+// Package storage — near-duplicate pages.
+//
+// content_hash only matches pages whose bytes are identical, so two copies of
+// a page differing by a timestamp or a session token go unnoticed. Every HTML
+// page also stores a SimHash of its main text in page_content; pages whose
+// hashes differ in a few bits are near duplicates, which
+// `linktadoru analyze near-duplicates` groups into clusters.
+package storage
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/masahif/linktadoru/internal/parser"
+)
+
+// NearDuplicate is a page in a cluster of pages with near-identical text
+type NearDuplicate struct {
+	Cluster   int    `json:"cluster"` // 1 for the largest cluster
+	URL       string `json:"url"`
+	WordCount int    `json:"word_count"`
+	Distance  int    `json:"distance"` // Bits the page's SimHash differs from that of the cluster's first page
+}
+
+// simHashPage is a page with the SimHash of its text
+type simHashPage struct {
+	url   string
+	words int
+	hash  uint64
+}
+
+// GetNearDuplicates clusters the completed pages with at least minWords words
+// whose SimHashes differ in at most maxDistance bits (0-63), directly or
+// through other pages of the cluster. Clusters are returned largest first,
+// their pages ordered by URL; pages without a near duplicate are left out.
+func (s *SQLiteStorage) GetNearDuplicates(maxDistance, minWords int) ([]NearDuplicate, error) {
+	if maxDistance < 0 || maxDistance > 63 {
+		return nil, fmt.Errorf("maximum SimHash distance must be between 0 and 63, got %d", maxDistance)
+	}
+	rows, err := s.read.Query(`
+		SELECT p.url, pc.word_count, pc.simhash
+		FROM page_content pc
+		JOIN pages p ON pc.page_id = p.id
+		WHERE p.status = 'completed' AND pc.simhash IS NOT NULL AND pc.word_count >= ?
+		ORDER BY p.url
+	`, minWords)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page SimHashes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var pages []simHashPage
+	for rows.Next() {
+		var page simHashPage
+		var hash int64
+		if err := rows.Scan(&page.url, &page.words, &hash); err != nil {
+			return nil, fmt.Errorf("failed to scan page SimHash: %w", err)
+		}
+		page.hash = uint64(hash)
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read page SimHashes: %w", err)
+	}
+
+	return clusterSimHashes(pages, maxDistance), nil
+}
+
+// clusterSimHashes groups pages, ordered by URL, whose hashes are within
+// maxDistance bits. Hashes that close agree exactly on at least one of
+// maxDistance+1 bands of bits, so only pages sharing a band are compared.
+func clusterSimHashes(pages []simHashPage, maxDistance int) []NearDuplicate {
+	parent := make([]int, len(pages))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	bands := maxDistance + 1
+	for band := 0; band < bands; band++ {
+		low, high := band*64/bands, (band+1)*64/bands
+		mask := ^uint64(0) >> (64 - (high - low))
+		buckets := map[uint64][]int{}
+		for i, page := range pages {
+			key := (page.hash >> low) & mask
+			buckets[key] = append(buckets[key], i)
+		}
+		for _, bucket := range buckets {
+			for a := 0; a < len(bucket); a++ {
+				for b := a + 1; b < len(bucket); b++ {
+					i, j := bucket[a], bucket[b]
+					if find(i) != find(j) && parser.SimHashDistance(pages[i].hash, pages[j].hash) <= maxDistance {
+						parent[find(j)] = find(i)
+					}
+				}
+			}
+		}
+	}
+
+	// Members are collected in URL order, so each cluster starts with its first URL
+	members := map[int][]int{}
+	for i := range pages {
+		root := find(i)
+		members[root] = append(members[root], i)
+	}
+	var clusters [][]int
+	for _, cluster := range members {
+		if len(cluster) > 1 {
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.Slice(clusters, func(a, b int) bool {
+		if len(clusters[a]) != len(clusters[b]) {
+			return len(clusters[a]) > len(clusters[b])
+		}
+		return clusters[a][0] < clusters[b][0]
+	})
+
+	var duplicates []NearDuplicate
+	for n, cluster := range clusters {
+		first := pages[cluster[0]]
+		for _, i := range cluster {
+			duplicates = append(duplicates, NearDuplicate{
+				Cluster:   n + 1,
+				URL:       pages[i].url,
+				WordCount: pages[i].words,
+				Distance:  parser.SimHashDistance(first.hash, pages[i].hash),
+			})
+		}
+	}
+	return duplicates
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestGetNearDuplicates(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "near-duplicates.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	const base = 0x0123456789abcdef
+	hashes := map[string]uint64{
+		"https://example.com/a":       base,
+		"https://example.com/a?sid=1": base ^ 0b101,          // 2 bits from a
+		"https://example.com/a?sid=2": base ^ 0b101 ^ 1<<40,  // 1 bit from a?sid=1, 3 from a
+		"https://example.com/b":       ^uint64(base),         // Unrelated
+		"https://example.com/b2":      ^uint64(base) ^ 1<<63, // 1 bit from b
+		"https://example.com/short":   base,                  // Too few words
+		"https://example.com/empty":   0,                     // No text
+	}
+	var urls []string
+	for u := range hashes {
+		urls = append(urls, u)
+	}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range hashes {
+		item, _ := store.GetNextFromQueue()
+		words := 100
+		if item.URL == "https://example.com/short" {
+			words = 5
+		}
+		page := &crawler.PageData{URL: item.URL, StatusCode: 200, HTTPHeaders: map[string]string{}, CrawledAt: time.Now(),
+			Text: &crawler.PageText{WordCount: words, SimHash: hashes[item.URL]}}
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	duplicates, err := store.GetNearDuplicates(2, 10)
+	if err != nil {
+		t.Fatalf("GetNearDuplicates failed: %v", err)
+	}
+	want := []NearDuplicate{
+		{Cluster: 1, URL: "https://example.com/a", WordCount: 100, Distance: 0},
+		{Cluster: 1, URL: "https://example.com/a?sid=1", WordCount: 100, Distance: 2},
+		{Cluster: 1, URL: "https://example.com/a?sid=2", WordCount: 100, Distance: 3},
+		{Cluster: 2, URL: "https://example.com/b", WordCount: 100, Distance: 0},
+		{Cluster: 2, URL: "https://example.com/b2", WordCount: 100, Distance: 1},
+	}
+	if !reflect.DeepEqual(duplicates, want) {
+		t.Errorf("Expected %+v, got %+v", want, duplicates)
+	}
+
+	// Exact matches only; the short page joins with a lower word minimum
+	if duplicates, err = store.GetNearDuplicates(0, 0); err != nil || len(duplicates) != 2 ||
+		duplicates[0].URL != "https://example.com/a" || duplicates[1].URL != "https://example.com/short" {
+		t.Errorf("Expected the exact duplicates a and short, got %+v (%v)", duplicates, err)
+	}
+
+	if _, err := store.GetNearDuplicates(64, 0); err == nil {
+		t.Error("Expected an error for a distance above 63")
+	}
+}
@@ -0,0 +1,94 @@
+// Package storage — sitemap coverage.
+//
+// A sitemap lists the URLs a site wants indexed; the link graph shows which
+// of them visitors can actually reach. `linktadoru analyze orphans` compares
+// the two: listed URLs no other page links to are orphans, and crawled pages
+// missing from the sitemap may be left out of search engine discovery.
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Issues reported by GetSitemapIssues
+const (
+	SitemapIssueOrphan       = "orphan"         // Listed, but no other page links to it
+	SitemapIssueNotInSitemap = "not_in_sitemap" // Crawled HTML page missing from the sitemap
+)
+
+// SitemapIssue is a URL whose sitemap listing and internal links disagree
+type SitemapIssue struct {
+	Issue      string `json:"issue"`
+	URL        string `json:"url"`
+	Status     string `json:"status"`      // Crawl status; empty when the crawl never found the URL
+	StatusCode int    `json:"status_code"` // 0 when not fetched
+	Inlinks    int    `json:"inlinks"`     // Internal links from other pages
+}
+
+// GetSitemapIssues compares sitemapURLs with the crawl. Listed URLs with no
+// internal link from another page, including URLs the crawl never found,
+// are orphans; completed 2xx HTML pages that are not listed are
+// not_in_sitemap. URLs must be normalized the way the crawler stores them.
+// Issues are ordered by issue, then URL.
+func (s *SQLiteStorage) GetSitemapIssues(sitemapURLs []string) ([]SitemapIssue, error) {
+	rows, err := s.read.Query(`
+		SELECT p.url, p.status, COALESCE(p.status_code, 0), COALESCE(p.content_type, ''),
+		       (SELECT COUNT(*) FROM link_relations lr
+		        WHERE lr.target_page_id = p.id AND lr.source_page_id != p.id
+		          AND lr.link_type IN ('internal', 'pagination'))
+		FROM pages p
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pages: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	listed := make(map[string]bool, len(sitemapURLs))
+	for _, u := range sitemapURLs {
+		listed[u] = true
+	}
+	found := make(map[string]bool)
+	var issues []SitemapIssue
+	for rows.Next() {
+		var page SitemapIssue
+		var contentType string
+		if err := rows.Scan(&page.URL, &page.Status, &page.StatusCode, &contentType, &page.Inlinks); err != nil {
+			return nil, fmt.Errorf("failed to scan page: %w", err)
+		}
+		found[page.URL] = true
+		switch {
+		case listed[page.URL] && page.Inlinks == 0:
+			page.Issue = SitemapIssueOrphan
+		case !listed[page.URL] && page.Status == "completed" && page.StatusCode >= 200 && page.StatusCode < 300 &&
+			isHTMLContentType(contentType):
+			page.Issue = SitemapIssueNotInSitemap
+		default:
+			continue
+		}
+		issues = append(issues, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pages: %w", err)
+	}
+
+	for u := range listed {
+		if !found[u] {
+			issues = append(issues, SitemapIssue{Issue: SitemapIssueOrphan, URL: u})
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Issue != issues[j].Issue {
+			return issues[i].Issue < issues[j].Issue
+		}
+		return issues[i].URL < issues[j].URL
+	})
+	return issues, nil
+}
+
+// isHTMLContentType reports whether a Content-Type header names an HTML document
+func isHTMLContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.HasPrefix(contentType, "text/html") || strings.HasPrefix(contentType, "application/xhtml+xml")
+}
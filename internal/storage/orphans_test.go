@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestGetSitemapIssues(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "orphans.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	urls := []string{"https://example.com/", "https://example.com/listed", "https://example.com/unlisted", "https://example.com/feed.xml"}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for _, page := range []*crawler.PageData{
+		{URL: urls[0], StatusCode: 200, HTTPHeaders: map[string]string{"content-type": "text/html; charset=utf-8"}, CrawledAt: time.Now()},
+		{URL: urls[1], StatusCode: 200, HTTPHeaders: map[string]string{"content-type": "text/html"}, CrawledAt: time.Now()},
+		{URL: urls[2], StatusCode: 200, HTTPHeaders: map[string]string{"content-type": "text/html"}, CrawledAt: time.Now()},
+		{URL: urls[3], StatusCode: 200, HTTPHeaders: map[string]string{"content-type": "application/rss+xml"}, CrawledAt: time.Now()},
+	} {
+		item, _ := store.GetNextFromQueue()
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save page: %v", err)
+		}
+	}
+	// The home page links to /unlisted and to itself; /listed only links to itself
+	var links []*crawler.LinkData
+	for _, pair := range [][2]string{{"/", "/unlisted"}, {"/", "/"}, {"/listed", "/listed"}} {
+		links = append(links, &crawler.LinkData{
+			SourceURL: "https://example.com" + pair[0], TargetURL: "https://example.com" + pair[1], LinkType: "internal",
+		})
+	}
+	if err := store.SaveLinks(links); err != nil {
+		t.Fatalf("Failed to save links: %v", err)
+	}
+
+	issues, err := store.GetSitemapIssues([]string{"https://example.com/", "https://example.com/listed", "https://example.com/never-linked"})
+	if err != nil {
+		t.Fatalf("GetSitemapIssues failed: %v", err)
+	}
+	want := []SitemapIssue{
+		{Issue: SitemapIssueNotInSitemap, URL: "https://example.com/unlisted", Status: "completed", StatusCode: 200, Inlinks: 1},
+		{Issue: SitemapIssueOrphan, URL: "https://example.com/", Status: "completed", StatusCode: 200},
+		{Issue: SitemapIssueOrphan, URL: "https://example.com/listed", Status: "completed", StatusCode: 200},
+		{Issue: SitemapIssueOrphan, URL: "https://example.com/never-linked"},
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("Expected %+v, got %+v", want, issues)
+	}
+}
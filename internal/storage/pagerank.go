@@ -0,0 +1,178 @@
+// Package storage — PageRank.
+//
+// `linktadoru analyze pagerank` estimates how internal link equity flows
+// through a site. PageRank is computed over the internal links of the link
+// graph, leaving out links marked rel="nofollow", and the scores are stored
+// in page_metrics so later queries can join them with other page data.
+package storage
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// PageRankEntry is the PageRank of a page
+type PageRankEntry struct {
+	URL      string  `json:"url"`
+	PageRank float64 `json:"pagerank"` // Scores of all pages sum to 1
+	Inlinks  int     `json:"inlinks"`  // Internal followed links pointing at the page
+}
+
+// passesEquity reports whether a link passes PageRank: an internal or
+// pagination link without rel="nofollow"
+func passesEquity(edge GraphEdge) bool {
+	if edge.LinkType != "internal" && edge.LinkType != crawler.LinkTypePagination {
+		return false
+	}
+	for _, rel := range strings.Fields(strings.ToLower(edge.Rel)) {
+		if rel == "nofollow" {
+			return false
+		}
+	}
+	return true
+}
+
+// PageRank returns the PageRank of every node by power iteration over the
+// links that pass equity (see passesEquity), keyed by node ID. damping is the
+// probability of following a link (0.85 is customary); the rank of pages
+// without outgoing links is spread evenly over all pages. Iteration stops
+// after maxIterations or once the scores change by less than 1e-9 in total.
+func (g *LinkGraph) PageRank(damping float64, maxIterations int) map[int64]float64 {
+	n := len(g.Nodes)
+	if n == 0 {
+		return map[int64]float64{}
+	}
+	index := make(map[int64]int, n)
+	for i, node := range g.Nodes {
+		index[node.ID] = i
+	}
+	// Duplicate links count once: link_relations keeps one row per page pair
+	out := make([][]int, n)
+	for _, edge := range g.Edges {
+		source, okSource := index[edge.Source]
+		target, okTarget := index[edge.Target]
+		if okSource && okTarget && source != target && passesEquity(edge) {
+			out[source] = append(out[source], target)
+		}
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+	next := make([]float64, n)
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		dangling := 0.0
+		for i, targets := range out {
+			if len(targets) == 0 {
+				dangling += rank[i]
+			}
+		}
+		base := (1-damping)/float64(n) + damping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+		for i, targets := range out {
+			share := damping * rank[i] / float64(len(targets))
+			for _, target := range targets {
+				next[target] += share
+			}
+		}
+
+		delta := 0.0
+		for i := range rank {
+			delta += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if delta < 1e-9 {
+			break
+		}
+	}
+
+	ranks := make(map[int64]float64, n)
+	for i, node := range g.Nodes {
+		ranks[node.ID] = rank[i]
+	}
+	return ranks
+}
+
+// SavePageRanks replaces the stored PageRank scores, keyed by page ID
+func (s *SQLiteStorage) SavePageRanks(ranks map[int64]float64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("UPDATE page_metrics SET pagerank = NULL"); err != nil {
+		return fmt.Errorf("failed to clear PageRank: %w", err)
+	}
+	stmt, err := tx.Prepare(`
+		INSERT INTO page_metrics (page_id, pagerank) VALUES (?, ?)
+		ON CONFLICT(page_id) DO UPDATE SET pagerank = excluded.pagerank
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare PageRank statement: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+	for pageID, rank := range ranks {
+		if _, err := stmt.Exec(pageID, rank); err != nil {
+			return fmt.Errorf("failed to save PageRank: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ComputePageRank computes PageRank over the stored link graph, saves it and
+// returns the number of pages scored
+func (s *SQLiteStorage) ComputePageRank(damping float64, maxIterations int) (int, error) {
+	graph, err := s.LoadLinkGraph(false)
+	if err != nil {
+		return 0, err
+	}
+	ranks := graph.PageRank(damping, maxIterations)
+	if err := s.SavePageRanks(ranks); err != nil {
+		return 0, err
+	}
+	return len(ranks), nil
+}
+
+// GetPageRanks returns the stored PageRank scores, highest first. limit
+// caps the number of pages returned (0 = all).
+func (s *SQLiteStorage) GetPageRanks(limit int) ([]PageRankEntry, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.Query(`
+		SELECT p.url, m.pagerank,
+		       (SELECT COUNT(*) FROM link_relations lr
+		        WHERE lr.target_page_id = p.id AND lr.source_page_id != p.id
+		          AND lr.link_type IN ('internal', 'pagination')
+		          AND ' ' || lower(COALESCE(lr.rel_attribute, '')) || ' ' NOT LIKE '% nofollow %')
+		FROM page_metrics m
+		JOIN pages p ON p.id = m.page_id
+		WHERE m.pagerank IS NOT NULL
+		ORDER BY m.pagerank DESC, p.url
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query PageRank: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []PageRankEntry
+	for rows.Next() {
+		var entry PageRankEntry
+		if err := rows.Scan(&entry.URL, &entry.PageRank, &entry.Inlinks); err != nil {
+			return nil, fmt.Errorf("failed to scan PageRank: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read PageRank: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestPageRank(t *testing.T) {
+	graph := &LinkGraph{
+		Nodes: []GraphNode{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}},
+		Edges: []GraphEdge{
+			{Source: 1, Target: 2, LinkType: "internal"},
+			{Source: 2, Target: 1, LinkType: "internal"},
+			{Source: 3, Target: 1, LinkType: "internal"},
+			{Source: 3, Target: 4, LinkType: "internal", Rel: "NoFollow"},
+			{Source: 4, Target: 1, LinkType: "external"},
+		},
+	}
+	ranks := graph.PageRank(0.85, 100)
+
+	sum := 0.0
+	for _, rank := range ranks {
+		sum += rank
+	}
+	if math.Abs(sum-1) > 1e-6 {
+		t.Errorf("Expected scores to sum to 1, got %g", sum)
+	}
+	if !(ranks[1] > ranks[2] && ranks[2] > ranks[3]) {
+		t.Errorf("Expected the most linked page to rank highest, got %v", ranks)
+	}
+	// Neither the nofollow nor the external link passes equity
+	if math.Abs(ranks[3]-ranks[4]) > 1e-9 {
+		t.Errorf("Expected unlinked pages to rank equally, got %v", ranks)
+	}
+
+	if ranks := (&LinkGraph{}).PageRank(0.85, 100); len(ranks) != 0 {
+		t.Errorf("Expected no scores for an empty graph, got %v", ranks)
+	}
+}
+
+func TestComputePageRank(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "pagerank.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.AddToQueue([]string{"https://example.com/"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	err = store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/a", LinkType: "internal"},
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/b", LinkType: "internal"},
+		{SourceURL: "https://example.com/a", TargetURL: "https://example.com/b", LinkType: "internal"},
+		{SourceURL: "https://example.com/b", TargetURL: "https://example.com/", LinkType: "internal", RelAttribute: "nofollow"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save links: %v", err)
+	}
+
+	scored, err := store.ComputePageRank(0.85, 100)
+	if err != nil || scored != 3 {
+		t.Fatalf("Expected 3 scored pages, got %d (%v)", scored, err)
+	}
+	// Recomputing replaces the stored scores
+	if _, err := store.ComputePageRank(0.85, 100); err != nil {
+		t.Fatalf("Recomputing PageRank failed: %v", err)
+	}
+
+	entries, err := store.GetPageRanks(2)
+	if err != nil {
+		t.Fatalf("GetPageRanks failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].URL != "https://example.com/b" || entries[0].Inlinks != 2 {
+		t.Errorf("Expected /b to rank first with 2 inlinks, got %+v", entries)
+	}
+	if entries[0].PageRank <= entries[1].PageRank {
+		t.Errorf("Expected pages ordered by score, got %+v", entries)
+	}
+}
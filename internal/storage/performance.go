@@ -0,0 +1,137 @@
+// Package storage — response time percentiles.
+//
+// Every completed page records its time to first byte and download time.
+// Averages hide the slow tail visitors notice, so `linktadoru analyze
+// performance` reports percentiles of both, for the whole crawl and per host
+// or site section.
+package storage
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// PerformanceOverall is the Group of the row covering every page
+const PerformanceOverall = "(all)"
+
+// PerformanceStats holds the response time percentiles of a group of pages,
+// in milliseconds
+type PerformanceStats struct {
+	Group       string `json:"group"` // PerformanceOverall, a host name or a host and first path segment
+	Pages       int    `json:"pages"`
+	TTFBP50     int    `json:"ttfb_p50_ms"`
+	TTFBP90     int    `json:"ttfb_p90_ms"`
+	TTFBP99     int    `json:"ttfb_p99_ms"`
+	DownloadP50 int    `json:"download_p50_ms"`
+	DownloadP90 int    `json:"download_p90_ms"`
+	DownloadP99 int    `json:"download_p99_ms"`
+}
+
+// pageTimings collects the timings of a group of pages
+type pageTimings struct {
+	ttfb, download []int
+}
+
+// GetPerformanceStats returns the TTFB and download time percentiles of the
+// completed pages, the whole crawl first and then one row per host, most
+// pages first. With bySection set, rows are per host and first path segment
+// instead, so example.com/blog/ and example.com/shop/ are told apart.
+func (s *SQLiteStorage) GetPerformanceStats(bySection bool) ([]PerformanceStats, error) {
+	rows, err := s.read.Query(`
+		SELECT url, ttfb_ms, COALESCE(download_time_ms, 0)
+		FROM pages
+		WHERE status = 'completed' AND ttfb_ms IS NOT NULL
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page timings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	overall := &pageTimings{}
+	groups := map[string]*pageTimings{}
+	for rows.Next() {
+		var rawURL string
+		var ttfb, download int
+		if err := rows.Scan(&rawURL, &ttfb, &download); err != nil {
+			return nil, fmt.Errorf("failed to scan page timings: %w", err)
+		}
+		overall.add(ttfb, download)
+
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		key := u.Hostname()
+		if bySection {
+			key += pathSection(u.Path)
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &pageTimings{}
+			groups[key] = group
+		}
+		group.add(ttfb, download)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read page timings: %w", err)
+	}
+
+	result := make([]PerformanceStats, 0, len(groups))
+	for key, group := range groups {
+		result = append(result, group.percentiles(key))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Pages != result[j].Pages {
+			return result[i].Pages > result[j].Pages
+		}
+		return result[i].Group < result[j].Group
+	})
+	return append([]PerformanceStats{overall.percentiles(PerformanceOverall)}, result...), nil
+}
+
+func (t *pageTimings) add(ttfb, download int) {
+	t.ttfb = append(t.ttfb, ttfb)
+	t.download = append(t.download, download)
+}
+
+// percentiles computes the percentiles of the collected timings
+func (t *pageTimings) percentiles(group string) PerformanceStats {
+	sort.Ints(t.ttfb)
+	sort.Ints(t.download)
+	return PerformanceStats{
+		Group:       group,
+		Pages:       len(t.ttfb),
+		TTFBP50:     percentile(t.ttfb, 50),
+		TTFBP90:     percentile(t.ttfb, 90),
+		TTFBP99:     percentile(t.ttfb, 99),
+		DownloadP50: percentile(t.download, 50),
+		DownloadP90: percentile(t.download, 90),
+		DownloadP99: percentile(t.download, 99),
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted values, 0 when
+// there are none
+func percentile(sorted []int, p float64) int {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// pathSection returns the first segment of a URL path with its slashes,
+// e.g. /blog/ for /blog/post, or / for pages at the root
+func pathSection(path string) string {
+	segment, _, found := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if !found {
+		return "/"
+	}
+	return "/" + segment + "/"
+}
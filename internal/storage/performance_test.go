@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestGetPerformanceStats(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "performance.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// Ten blog posts answering in 10-100 ms and two shop pages in 500 ms
+	timings := map[string]int{}
+	for i := 1; i <= 10; i++ {
+		timings[fmt.Sprintf("https://example.com/blog/%d", i)] = i * 10
+	}
+	timings["https://example.com/shop/a"] = 500
+	timings["https://example.com/shop/b"] = 500
+	var urls []string
+	for u := range timings {
+		urls = append(urls, u)
+	}
+	if err := store.AddToQueue(append(urls, "https://example.com/pending")); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range timings {
+		item, _ := store.GetNextFromQueue()
+		ms := time.Duration(timings[item.URL]) * time.Millisecond
+		page := &crawler.PageData{URL: item.URL, StatusCode: 200, TTFB: ms, DownloadTime: 2 * ms,
+			HTTPHeaders: map[string]string{}, CrawledAt: time.Now()}
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	stats, err := store.GetPerformanceStats(false)
+	if err != nil {
+		t.Fatalf("GetPerformanceStats failed: %v", err)
+	}
+	want := []PerformanceStats{
+		{Group: PerformanceOverall, Pages: 12, TTFBP50: 60, TTFBP90: 500, TTFBP99: 500, DownloadP50: 120, DownloadP90: 1000, DownloadP99: 1000},
+		{Group: "example.com", Pages: 12, TTFBP50: 60, TTFBP90: 500, TTFBP99: 500, DownloadP50: 120, DownloadP90: 1000, DownloadP99: 1000},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("Expected %+v, got %+v", want, stats)
+	}
+
+	stats, err = store.GetPerformanceStats(true)
+	if err != nil {
+		t.Fatalf("GetPerformanceStats failed: %v", err)
+	}
+	if len(stats) != 3 || stats[1].Group != "example.com/blog/" || stats[1].Pages != 10 || stats[1].TTFBP50 != 50 ||
+		stats[1].TTFBP90 != 90 || stats[1].TTFBP99 != 100 || stats[2].Group != "example.com/shop/" || stats[2].TTFBP50 != 500 {
+		t.Errorf("Expected per-section percentiles, got %+v", stats)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	for _, tt := range []struct {
+		p    float64
+		want int
+	}{{50, 5}, {90, 9}, {99, 10}, {0, 1}} {
+		if got := percentile(values, tt.p); got != tt.want {
+			t.Errorf("percentile(%v) = %d, want %d", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile of no values = %d, want 0", got)
+	}
+}
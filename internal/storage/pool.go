@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// Connection pools. SQLite lets one connection write at a time, so the write
+// pool stays small: a second connection can prepare its statement while the
+// first commits, and both wait on busy_timeout rather than failing. Status
+// queries (GetURLStatus, HasQueuedItems, ...) run on a separate query-only
+// pool; in WAL mode readers neither block nor wait for the writer, so workers
+// checking links no longer queue behind page writes.
+const (
+	maxWriteConns = 2
+	maxReadConns  = 4
+)
+
+// connectionPragmas are per-connection settings applied to every pooled
+// connection when it is opened (InitSchema only reaches one connection)
+var connectionPragmas = []string{
+	"busy_timeout(30000)", // 30 second timeout for locks
+	"synchronous(NORMAL)",
+	"cache_size(-64000)", // 64MB cache
+	"temp_store(MEMORY)",
+}
+
+// openPool opens a connection pool on dbPath (and resultsPath, see
+// results.go). Write pools begin transactions with BEGIN IMMEDIATE, so a
+// transaction that reads before it writes takes the write lock up front
+// instead of failing with SQLITE_BUSY when another connection wrote in
+// between. Read pools are query-only.
+func openPool(dbPath, resultsPath string, readOnly bool) (*sql.DB, error) {
+	params := url.Values{}
+	for _, pragma := range connectionPragmas {
+		params.Add("_pragma", pragma)
+	}
+	if readOnly {
+		if resultsPath == "" {
+			params.Add("_pragma", "query_only(1)")
+		}
+	} else {
+		params.Set("_txlock", "immediate")
+	}
+	if resultsPath == "" {
+		// Foreign keys cannot reference pages across database files, so the
+		// split layout leaves them off (see openSplitDB)
+		params.Add("_pragma", "foreign_keys(1)")
+	}
+	dsn, err := databaseURI(dbPath, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if resultsPath != "" {
+		return openSplitDB(dsn, resultsPath, readOnly)
+	}
+	return sql.Open("sqlite", dsn)
+}
+
+// databaseURI returns the name the driver opens dbPath by, with params as its
+// query. Files are opened by an absolute file: URI with the path escaped, so
+// that "?" or "#" in a file name is not read as the start of the parameters.
+func databaseURI(dbPath string, params url.Values) (string, error) {
+	if isMemoryDatabase(dbPath) {
+		return dbPath + "?" + params.Encode(), nil
+	}
+	// A relative path would be read as the URI authority
+	abs, err := filepath.Abs(dbPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve database path: %w", err)
+	}
+	path := filepath.ToSlash(abs)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // Windows drive letters, e.g. /C:/crawl.db
+	}
+	return (&url.URL{Scheme: "file", Path: path, RawQuery: params.Encode()}).String(), nil
+}
+
+// isMemoryDatabase reports whether dbPath names an in-memory database
+func isMemoryDatabase(dbPath string) bool {
+	return dbPath == "" || dbPath == ":memory:"
+}
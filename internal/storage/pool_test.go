@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestReadPoolIsQueryOnly(t *testing.T) {
+	dir := t.TempDir()
+	layouts := map[string]func() (*SQLiteStorage, error){
+		"single": func() (*SQLiteStorage, error) {
+			return NewSQLiteStorage(filepath.Join(dir, "single.db"))
+		},
+		"split": func() (*SQLiteStorage, error) {
+			return NewSQLiteStorageWithResults(filepath.Join(dir, "queue.db"), filepath.Join(dir, "results.db"), "")
+		},
+	}
+	for name, open := range layouts {
+		t.Run(name, func(t *testing.T) {
+			store, err := open()
+			if err != nil {
+				t.Fatalf("Failed to create storage: %v", err)
+			}
+			defer func() { _ = store.Close() }()
+
+			if store.read == store.db {
+				t.Fatal("Expected a separate read pool for a file database")
+			}
+			if _, err := store.read.Exec("DELETE FROM pages"); err == nil {
+				t.Error("Expected the read pool to reject writes")
+			}
+
+			// Writes are visible to status queries as soon as they commit
+			if err := store.AddToQueue([]string{"https://example.com/"}); err != nil {
+				t.Fatalf("Failed to add to queue: %v", err)
+			}
+			if status, exists := store.GetURLStatus("https://example.com/"); !exists || status != "pending" {
+				t.Errorf("GetURLStatus = %q, %v; want pending", status, exists)
+			}
+			if _, err := store.read.Exec("SELECT * FROM links"); err != nil {
+				t.Errorf("Expected result views on read connections: %v", err)
+			}
+		})
+	}
+}
+
+func TestMemoryDatabaseSharesOnePool(t *testing.T) {
+	store, err := NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if store.read != store.db {
+		t.Error("Expected an in-memory database to read and write on one connection")
+	}
+	if err := store.AddToQueue([]string{"https://example.com/"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	if _, exists := store.GetURLStatus("https://example.com/"); !exists {
+		t.Error("Expected the queued URL to be visible")
+	}
+}
+
+func TestDatabasePathWithQueryCharacters(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "crawl?mode=ro#1.db")
+	resultsPath := filepath.Join(dir, "results?cache=shared.db")
+
+	for name, open := range map[string]func() (*SQLiteStorage, error){
+		"single": func() (*SQLiteStorage, error) { return NewSQLiteStorage(dbPath) },
+		"split": func() (*SQLiteStorage, error) {
+			return NewSQLiteStorageWithResults(filepath.Join(dir, "queue?.db"), resultsPath, "")
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			store, err := open()
+			if err != nil {
+				t.Fatalf("Failed to create storage: %v", err)
+			}
+			if err := store.AddToQueue([]string{"https://example.com/"}); err != nil {
+				t.Fatalf("Failed to add to queue: %v", err)
+			}
+			_ = store.Close()
+
+			// Reopening finds the queued URL in the same file
+			store, err = open()
+			if err != nil {
+				t.Fatalf("Failed to reopen storage: %v", err)
+			}
+			defer func() { _ = store.Close() }()
+			if status, exists := store.GetURLStatus("https://example.com/"); !exists || status != "pending" {
+				t.Errorf("GetURLStatus = %q, %v; want pending", status, exists)
+			}
+		})
+	}
+
+	for _, name := range []string{"crawl?mode=ro#1.db", "queue?.db", "results?cache=shared.db"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected database file %q: %v", name, err)
+		}
+	}
+	for _, name := range []string{"crawl", "queue", "results"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("Expected no database file %q cut at the '?'", name)
+		}
+	}
+}
+
+// BenchmarkQueueContention has workers claim pages, look up link statuses
+// and save results concurrently, as crawl workers do. The "single" variant
+// restores the former one-connection pool for comparison:
+//
+//	go test ./internal/storage -run '^$' -bench QueueContention
+func BenchmarkQueueContention(b *testing.B) {
+	for _, workers := range []int{1, 8, 16} {
+		for _, pools := range []string{"single", "pooled"} {
+			b.Run(fmt.Sprintf("%s/workers=%d", pools, workers), func(b *testing.B) {
+				store, err := NewSQLiteStorage(filepath.Join(b.TempDir(), "bench.db"))
+				if err != nil {
+					b.Fatalf("Failed to create storage: %v", err)
+				}
+				defer func() { _ = store.Close() }()
+				if pools == "single" {
+					store.db.SetMaxOpenConns(1)
+					store.read = store.db
+				}
+
+				urls := make([]string, b.N)
+				for i := range urls {
+					urls[i] = fmt.Sprintf("https://example.com/page/%d", i)
+				}
+				if err := store.AddToQueue(urls); err != nil {
+					b.Fatalf("Failed to add to queue: %v", err)
+				}
+
+				b.ResetTimer()
+				var wg sync.WaitGroup
+				for w := 0; w < workers; w++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						for {
+							item, err := store.GetNextFromQueue()
+							if err != nil || item == nil {
+								return
+							}
+							// A page's links are checked before it is saved
+							for i := 0; i < 20; i++ {
+								store.GetURLStatus(fmt.Sprintf("https://example.com/page/%d", (item.ID+i)%len(urls)))
+							}
+							_ = store.SavePageResult(item.ID, &crawler.PageData{
+								URL:         item.URL,
+								StatusCode:  200,
+								HTTPHeaders: map[string]string{},
+								CrawledAt:   time.Now(),
+							})
+						}
+					}()
+				}
+				wg.Wait()
+			})
+		}
+	}
+}
+
+// BenchmarkHostFrontierClaim claims pages with host rotation from a large
+// pending queue spread over many hosts, the default queue_order of a crawl.
+// A claim should cost the same however many pages and hosts are pending:
+//
+//	go test ./internal/storage -run '^$' -bench HostFrontierClaim
+func BenchmarkHostFrontierClaim(b *testing.B) {
+	for _, hosts := range []int{10, 1000} {
+		b.Run(fmt.Sprintf("hosts=%d", hosts), func(b *testing.B) {
+			store, err := NewSQLiteStorage(filepath.Join(b.TempDir(), "bench.db"))
+			if err != nil {
+				b.Fatalf("Failed to create storage: %v", err)
+			}
+			defer func() { _ = store.Close() }()
+			store.SetHostRotation(true)
+
+			urls := make([]string, 50000+b.N)
+			for i := range urls {
+				urls[i] = fmt.Sprintf("https://host%d.example.com/page/%d", i%hosts, i)
+			}
+			if err := store.AddToQueue(urls); err != nil {
+				b.Fatalf("Failed to add to queue: %v", err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				item, err := store.GetNextFromQueue()
+				if err != nil || item == nil {
+					b.Fatalf("Failed to claim: %v", err)
+				}
+				_ = store.SavePageResult(item.ID, &crawler.PageData{
+					URL:         item.URL,
+					StatusCode:  200,
+					HTTPHeaders: map[string]string{},
+					CrawledAt:   time.Now(),
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Package storage — several crawl processes on one database.
+//
+// SQLite serializes writers, and every queue claim is a single UPDATE (or an
+// IMMEDIATE transaction for the host frontier) that only takes rows still
+// 'pending', so two processes never claim the same page. What SQLite cannot
+// tell is whether a 'processing' row belongs to a crashed run or to another
+// process that is still fetching it. Each claiming process therefore records
+// itself in crawl_processes, stamps its claims with its ID (pages.claimed_by)
+// and refreshes a heartbeat while it runs. CleanupStaleProcessing leaves the
+// pages of processes with a recent heartbeat alone, and the live processes
+// requeue the pages of a process whose heartbeat stopped, e.g. one killed
+// while a second process was starting.
+package storage
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// processHeartbeatInterval is how often a claiming process refreshes its heartbeat
+	processHeartbeatInterval = 10 * time.Second
+	// processLiveness is how old a heartbeat may be before its process counts as dead
+	processLiveness = 6 * processHeartbeatInterval
+)
+
+// crawlProcess is the registration of this storage as a claiming process.
+// It registers on the first claim, so read-only subcommands never appear.
+type crawlProcess struct {
+	id         string
+	register   sync.Once
+	unregister sync.Once
+	err        error         // Registration error, returned by every claim
+	stop       chan struct{} // Closed to stop the heartbeat; nil until registered
+	done       chan struct{} // Closed when the heartbeat has stopped
+}
+
+// CrawlProcess is a process claiming pages from a database
+type CrawlProcess struct {
+	ID          string // hostname/pid/start time in Unix nanoseconds
+	StartedAt   time.Time
+	HeartbeatAt time.Time
+}
+
+// newCrawlProcess returns an unregistered process with a new ID
+func newCrawlProcess() *crawlProcess {
+	host, _ := os.Hostname()
+	return &crawlProcess{id: fmt.Sprintf("%s/%d/%d", host, os.Getpid(), time.Now().UnixNano())}
+}
+
+// registerProcess records the process in crawl_processes and starts its
+// heartbeat, once
+func (s *SQLiteStorage) registerProcess() error {
+	p := s.process
+	p.register.Do(func() {
+		now := time.Now()
+		if _, err := s.db.Exec(`
+			INSERT INTO crawl_processes (id, started_at, heartbeat_at) VALUES (?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET heartbeat_at = excluded.heartbeat_at
+		`, p.id, now, now); err != nil {
+			p.err = fmt.Errorf("failed to register crawl process: %w", err)
+			return
+		}
+		p.stop = make(chan struct{})
+		p.done = make(chan struct{})
+		go s.heartbeat()
+	})
+	return p.err
+}
+
+// heartbeat refreshes the process's heartbeat until unregisterProcess
+func (s *SQLiteStorage) heartbeat() {
+	p := s.process
+	defer close(p.done)
+	ticker := time.NewTicker(processHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+		if _, err := s.db.Exec(`UPDATE crawl_processes SET heartbeat_at = ? WHERE id = ?`, time.Now(), p.id); err != nil {
+			slog.Warn("Failed to refresh crawl process heartbeat", "error", err)
+		}
+		if n, err := s.reapDeadProcesses(); err != nil {
+			slog.Warn("Failed to requeue pages of dead crawl processes", "error", err)
+		} else if n > 0 {
+			slog.Warn("Requeued pages claimed by a crawl process that stopped responding", "count", n)
+		}
+	}
+}
+
+// reapDeadProcesses removes processes whose heartbeat is older than
+// processLiveness and returns their 'processing' pages to 'pending'
+func (s *SQLiteStorage) reapDeadProcesses() (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM crawl_processes WHERE heartbeat_at < ?`, time.Now().Add(-processLiveness)); err != nil {
+		return 0, fmt.Errorf("failed to remove dead crawl processes: %w", err)
+	}
+	result, err := tx.Exec(`
+		UPDATE pages
+		SET status = 'pending', processing_started_at = NULL, claimed_by = NULL
+		WHERE status = 'processing' AND claimed_by IS NOT NULL
+		AND claimed_by NOT IN (SELECT id FROM crawl_processes)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue pages of dead crawl processes: %w", err)
+	}
+	reaped, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count requeued pages: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit requeued pages: %w", err)
+	}
+	return int(reaped), nil
+}
+
+// unregisterProcess stops the heartbeat and removes the process, so its
+// remaining 'processing' pages count as abandoned right away
+func (s *SQLiteStorage) unregisterProcess() {
+	p := s.process
+	p.unregister.Do(func() {
+		if p.stop == nil {
+			return
+		}
+		close(p.stop)
+		<-p.done
+		if _, err := s.db.Exec(`DELETE FROM crawl_processes WHERE id = ?`, p.id); err != nil {
+			slog.Warn("Failed to unregister crawl process", "error", err)
+		}
+	})
+}
+
+// LiveCrawlProcesses returns the other processes claiming from the database
+// with a recent heartbeat, oldest first
+func (s *SQLiteStorage) LiveCrawlProcesses() ([]CrawlProcess, error) {
+	rows, err := s.read.Query(`
+		SELECT id, started_at, heartbeat_at FROM crawl_processes
+		WHERE heartbeat_at >= ? AND id != ?
+		ORDER BY started_at, id
+	`, time.Now().Add(-processLiveness), s.process.id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list crawl processes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var processes []CrawlProcess
+	for rows.Next() {
+		var p CrawlProcess
+		if err := rows.Scan(&p.ID, &p.StartedAt, &p.HeartbeatAt); err != nil {
+			return nil, fmt.Errorf("failed to scan crawl process: %w", err)
+		}
+		processes = append(processes, p)
+	}
+	return processes, rows.Err()
+}
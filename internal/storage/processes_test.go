@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// claimHelperEnv makes the test binary run TestClaimHelperProcess as a
+// separate crawl process claiming from the database it names
+const claimHelperEnv = "LT_TEST_CLAIM_HELPER_DB"
+
+// TestClaimHelperProcess is not a test: run by TestConcurrentProcessesClaimOnce
+// in child processes, it claims batches until the queue is empty and prints
+// the claimed page IDs
+func TestClaimHelperProcess(t *testing.T) {
+	dbPath := os.Getenv(claimHelperEnv)
+	if dbPath == "" {
+		t.Skip("helper process")
+	}
+	store, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open: %v\n", err)
+		os.Exit(2)
+	}
+	store.SetHostRotation(os.Getenv("LT_TEST_CLAIM_HELPER_ROTATE") == "1")
+	for {
+		items, err := store.GetNextBatchFromQueue(3)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "claim: %v\n", err)
+			os.Exit(2)
+		}
+		if len(items) == 0 {
+			break
+		}
+		for _, item := range items {
+			fmt.Println(item.ID)
+		}
+	}
+	_ = store.Close()
+	os.Exit(0)
+}
+
+func TestConcurrentProcessesClaimOnce(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts child processes")
+	}
+	for _, rotate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("rotate=%v", rotate), func(t *testing.T) {
+			dbPath := filepath.Join(t.TempDir(), "shared.db")
+			store, err := NewSQLiteStorage(dbPath)
+			if err != nil {
+				t.Fatalf("Failed to create storage: %v", err)
+			}
+			const pages = 300
+			var urls []string
+			for i := 0; i < pages; i++ {
+				urls = append(urls, fmt.Sprintf("https://host%d.example/page%d", i%7, i))
+			}
+			if err := store.AddToQueue(urls); err != nil {
+				t.Fatalf("AddToQueue failed: %v", err)
+			}
+			_ = store.Close()
+
+			env := append(os.Environ(), claimHelperEnv+"="+dbPath)
+			if rotate {
+				env = append(env, "LT_TEST_CLAIM_HELPER_ROTATE=1")
+			}
+			const processes = 4
+			var mu sync.Mutex
+			claims := make(map[int]int)
+			var wg sync.WaitGroup
+			for i := 0; i < processes; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					cmd := exec.Command(os.Args[0], "-test.run=^TestClaimHelperProcess$")
+					cmd.Env = env
+					out, err := cmd.Output()
+					if err != nil {
+						t.Errorf("Helper process failed: %v", err)
+						return
+					}
+					scanner := bufio.NewScanner(strings.NewReader(string(out)))
+					mu.Lock()
+					defer mu.Unlock()
+					for scanner.Scan() {
+						if id, err := strconv.Atoi(scanner.Text()); err == nil {
+							claims[id]++
+						}
+					}
+				}()
+			}
+			wg.Wait()
+
+			if len(claims) != pages {
+				t.Errorf("Expected all %d pages claimed, got %d", pages, len(claims))
+			}
+			for id, n := range claims {
+				if n != 1 {
+					t.Errorf("Page %d claimed %d times", id, n)
+				}
+			}
+		})
+	}
+}
+
+func TestCleanupLeavesPagesOfLiveProcesses(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shared.db")
+	first, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = first.Close() }()
+	if err := first.AddToQueue([]string{"https://example.com/a", "https://example.com/b"}); err != nil {
+		t.Fatalf("AddToQueue failed: %v", err)
+	}
+	item, err := first.GetNextFromQueue()
+	if err != nil || item == nil {
+		t.Fatalf("GetNextFromQueue failed: %v", err)
+	}
+
+	// A second process starting up sees the first one and its claim
+	second, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	defer func() { _ = second.Close() }()
+	live, err := second.LiveCrawlProcesses()
+	if err != nil || len(live) != 1 || live[0].ID != first.process.id {
+		t.Fatalf("Expected the first process to be live, got %+v (%v)", live, err)
+	}
+	if n, _ := second.CleanupStaleProcessing(0); n != 0 {
+		t.Errorf("Expected the live process's page to stay processing, %d requeued", n)
+	}
+	if status, _ := second.GetURLStatus(item.URL); status != "processing" {
+		t.Errorf("Expected processing, got %q", status)
+	}
+
+	// Once the first process is gone its claims are abandoned
+	_ = first.Close()
+	if live, _ := second.LiveCrawlProcesses(); len(live) != 0 {
+		t.Errorf("Expected no live process after Close, got %+v", live)
+	}
+	if n, _ := second.CleanupStaleProcessing(0); n != 1 {
+		t.Errorf("Expected the closed process's page to be requeued, got %d", n)
+	}
+}
+
+func TestReapDeadProcesses(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "shared.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	if err := store.AddToQueue([]string{"https://example.com/a", "https://example.com/b"}); err != nil {
+		t.Fatalf("AddToQueue failed: %v", err)
+	}
+	mine, _ := store.GetNextFromQueue()
+
+	// A process that claimed a page and stopped refreshing its heartbeat
+	stale := time.Now().Add(-2 * processLiveness)
+	if _, err := store.db.Exec(`INSERT INTO crawl_processes (id, started_at, heartbeat_at) VALUES ('dead', ?, ?)`, stale, stale); err != nil {
+		t.Fatalf("Failed to insert process: %v", err)
+	}
+	if _, err := store.db.Exec(`UPDATE pages SET status = 'processing', processing_started_at = ?, claimed_by = 'dead' WHERE id != ?`, time.Now(), mine.ID); err != nil {
+		t.Fatalf("Failed to claim page: %v", err)
+	}
+
+	n, err := store.reapDeadProcesses()
+	if err != nil || n != 1 {
+		t.Fatalf("Expected the dead process's page to be requeued, got %d (%v)", n, err)
+	}
+	if status, _ := store.GetURLStatus(mine.URL); status != "processing" {
+		t.Errorf("Expected the live process's page to stay processing, got %q", status)
+	}
+	if live, _ := store.LiveCrawlProcesses(); len(live) != 0 {
+		t.Errorf("Expected the dead process to be removed, got %+v", live)
+	}
+}
@@ -0,0 +1,24 @@
+// Package storage — progress of a running crawl.
+//
+// The database uses locking_mode NORMAL, so `linktadoru status` can read the
+// queue of a crawl while it runs and tell how far along it is.
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// CountCrawledSince returns the number of pages completed at or after since,
+// from which the throughput of a running crawl is derived
+func (s *SQLiteStorage) CountCrawledSince(since time.Time) (int, error) {
+	var count int
+	err := s.read.QueryRow(`
+		SELECT COUNT(*) FROM pages
+		WHERE status = 'completed' AND crawled_at >= ?
+	`, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count crawled pages: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestCountCrawledSince(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "progress.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Now()
+	crawledAt := map[string]time.Time{
+		"https://example.com/":    now.Add(-10 * time.Minute),
+		"https://example.com/a":   now.Add(-30 * time.Second),
+		"https://example.com/b":   now,
+		"https://example.com/err": now,
+	}
+	if err := store.AddToQueue([]string{"https://example.com/", "https://example.com/a", "https://example.com/b", "https://example.com/err"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range crawledAt {
+		item, err := store.GetNextFromQueue()
+		if err != nil || item == nil {
+			t.Fatalf("Failed to dequeue: %v", err)
+		}
+		if item.URL == "https://example.com/err" {
+			err = store.SavePageError(item.ID, "timeout", "timed out")
+		} else {
+			err = store.SavePageResult(item.ID, &crawler.PageData{
+				URL: item.URL, StatusCode: 200, HTTPHeaders: map[string]string{}, CrawledAt: crawledAt[item.URL],
+			})
+		}
+		if err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	count, err := store.CountCrawledSince(now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("CountCrawledSince failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("CountCrawledSince = %d, want 2", count)
+	}
+}
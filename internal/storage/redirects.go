@@ -0,0 +1,124 @@
+// Package storage — redirect chains.
+//
+// Every redirect followed while fetching a page is stored in the redirects
+// table, so chains can be audited after the crawl: each extra hop costs the
+// visitor a round trip and search engines stop following long chains, and a
+// redirect loop never reaches content at all.
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// Redirect chain issues reported by GetRedirectChains
+const (
+	RedirectIssueLoop    = "loop"     // The chain redirects back to a URL already in it
+	RedirectIssueTooLong = "too_long" // The chain has more hops than the limit
+)
+
+// RedirectChain is the chain of redirects followed from a crawled URL
+type RedirectChain struct {
+	URL        string         `json:"url"`
+	FinalURL   string         `json:"final_url"`   // Target of the last redirect
+	StatusCode int            `json:"status_code"` // Status of the page's final response
+	Issues     []string       `json:"issues"`      // RedirectIssue constants
+	Hops       []RedirectStep `json:"hops"`
+}
+
+// RedirectStep is one hop of a redirect chain
+type RedirectStep struct {
+	StatusCode int    `json:"status_code"`
+	FromURL    string `json:"from_url"`
+	ToURL      string `json:"to_url"`
+}
+
+// savePageRedirects replaces the redirects stored for a page
+func (s *SQLiteStorage) savePageRedirects(tx *sql.Tx, pageID int, hops []crawler.RedirectHop) error {
+	if _, err := tx.Exec("DELETE FROM redirects WHERE page_id = ?", pageID); err != nil {
+		return fmt.Errorf("failed to clear page redirects: %w", err)
+	}
+
+	if len(hops) > 0 {
+		stmt, err := tx.Prepare("INSERT INTO redirects (page_id, hop, status_code, from_url, to_url) VALUES (?, ?, ?, ?, ?)")
+		if err != nil {
+			return fmt.Errorf("failed to prepare redirect insert: %w", err)
+		}
+		defer func() { _ = stmt.Close() }()
+
+		for i, hop := range hops {
+			if _, err := stmt.Exec(pageID, i, hop.StatusCode, hop.FromURL, hop.ToURL); err != nil {
+				return fmt.Errorf("failed to save page redirect: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetRedirectChains returns the redirect chains of crawled pages that loop
+// or have more than maxHops hops, ordered by URL. With all set, every chain
+// is returned, flagged or not.
+func (s *SQLiteStorage) GetRedirectChains(maxHops int, all bool) ([]RedirectChain, error) {
+	rows, err := s.read.Query(`
+		SELECT r.page_id, p.url, COALESCE(p.status_code, 0), r.status_code, r.from_url, r.to_url
+		FROM redirects r
+		JOIN pages p ON p.id = r.page_id
+		ORDER BY p.url, r.page_id, r.hop
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query redirects: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var chains []RedirectChain
+	lastPage := int64(-1)
+	for rows.Next() {
+		var pageID int64
+		var chain RedirectChain
+		var step RedirectStep
+		if err := rows.Scan(&pageID, &chain.URL, &chain.StatusCode, &step.StatusCode, &step.FromURL, &step.ToURL); err != nil {
+			return nil, fmt.Errorf("failed to scan redirect: %w", err)
+		}
+		if pageID != lastPage {
+			chains = append(chains, chain)
+			lastPage = pageID
+		}
+		current := &chains[len(chains)-1]
+		current.Hops = append(current.Hops, step)
+		current.FinalURL = step.ToURL
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read redirects: %w", err)
+	}
+
+	var flagged []RedirectChain
+	for _, chain := range chains {
+		if chain.loops() {
+			chain.Issues = append(chain.Issues, RedirectIssueLoop)
+		}
+		if len(chain.Hops) > maxHops {
+			chain.Issues = append(chain.Issues, RedirectIssueTooLong)
+		}
+		if chain.Issues == nil {
+			chain.Issues = []string{}
+		}
+		if all || len(chain.Issues) > 0 {
+			flagged = append(flagged, chain)
+		}
+	}
+	return flagged, nil
+}
+
+// loops reports whether the last redirect of the chain points back at a URL
+// the chain already visited
+func (c RedirectChain) loops() bool {
+	for _, step := range c.Hops {
+		if step.FromURL == c.FinalURL {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestGetRedirectChains(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "redirects.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	pages := map[string]*crawler.PageData{
+		"https://example.com/single": {StatusCode: 200, Redirects: []crawler.RedirectHop{
+			{StatusCode: 301, FromURL: "https://example.com/single", ToURL: "https://example.com/target"},
+		}},
+		"https://example.com/chain": {StatusCode: 200, Redirects: []crawler.RedirectHop{
+			{StatusCode: 301, FromURL: "https://example.com/chain", ToURL: "https://example.com/step"},
+			{StatusCode: 302, FromURL: "https://example.com/step", ToURL: "https://example.com/target"},
+		}},
+		"https://example.com/loop": {StatusCode: 302, Redirects: []crawler.RedirectHop{
+			{StatusCode: 302, FromURL: "https://example.com/loop", ToURL: "https://example.com/loop/"},
+			{StatusCode: 302, FromURL: "https://example.com/loop/", ToURL: "https://example.com/loop"},
+		}},
+		"https://example.com/target": {StatusCode: 200},
+	}
+	if err := store.AddToQueue([]string{"https://example.com/single", "https://example.com/chain", "https://example.com/loop", "https://example.com/target"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range pages {
+		item, _ := store.GetNextFromQueue()
+		page := pages[item.URL]
+		page.URL, page.HTTPHeaders, page.CrawledAt = item.URL, map[string]string{}, time.Now()
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	chains, err := store.GetRedirectChains(1, false)
+	if err != nil {
+		t.Fatalf("GetRedirectChains failed: %v", err)
+	}
+	want := []RedirectChain{
+		{URL: "https://example.com/chain", FinalURL: "https://example.com/target", StatusCode: 200, Issues: []string{RedirectIssueTooLong}, Hops: []RedirectStep{
+			{StatusCode: 301, FromURL: "https://example.com/chain", ToURL: "https://example.com/step"},
+			{StatusCode: 302, FromURL: "https://example.com/step", ToURL: "https://example.com/target"},
+		}},
+		{URL: "https://example.com/loop", FinalURL: "https://example.com/loop", StatusCode: 302, Issues: []string{RedirectIssueLoop, RedirectIssueTooLong}, Hops: []RedirectStep{
+			{StatusCode: 302, FromURL: "https://example.com/loop", ToURL: "https://example.com/loop/"},
+			{StatusCode: 302, FromURL: "https://example.com/loop/", ToURL: "https://example.com/loop"},
+		}},
+	}
+	if !reflect.DeepEqual(chains, want) {
+		t.Errorf("Expected %+v, got %+v", want, chains)
+	}
+
+	if chains, err = store.GetRedirectChains(2, true); err != nil || len(chains) != 3 {
+		t.Fatalf("Expected every chain with all set, got %+v (%v)", chains, err)
+	}
+	if chains[2].URL != "https://example.com/single" || len(chains[2].Issues) != 0 {
+		t.Errorf("Expected an unflagged single redirect, got %+v", chains[2])
+	}
+
+	// Recrawling a page replaces its redirects
+	if _, err := store.db.Exec("UPDATE pages SET status = 'pending' WHERE url = 'https://example.com/chain'"); err != nil {
+		t.Fatalf("Failed to requeue page: %v", err)
+	}
+	var id int
+	_ = store.db.QueryRow("SELECT id FROM pages WHERE url = 'https://example.com/chain'").Scan(&id)
+	if err := store.SavePageResult(id, &crawler.PageData{URL: "https://example.com/chain", StatusCode: 200, HTTPHeaders: map[string]string{}, CrawledAt: time.Now()}); err != nil {
+		t.Fatalf("Failed to save page: %v", err)
+	}
+	var hops int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM redirects WHERE page_id = ?", id).Scan(&hops); err != nil || hops != 0 {
+		t.Errorf("Expected redirects to be cleared, got %d (%v)", hops, err)
+	}
+}
@@ -0,0 +1,523 @@
+// Package redis provides a crawl storage backend that keeps the queue and
+// the results in a Redis server, so several crawler processes on any number
+// of machines can work through one shared frontier. Claims are atomic, and a
+// worker claiming a URL leases its host for host_lease, so each host is
+// fetched by one worker at a time and its politeness settings hold across
+// workers. Importing the package registers it as the "redis" storage driver.
+//
+// The scripts build the keys of hosts and pages from the prefix themselves
+// instead of receiving them in KEYS, so the backend needs a single Redis
+// server (or a primary with replicas), not a Redis Cluster. Choosing the next
+// host reads the front of a sorted set, so a claim does not slow down as the
+// number of hosts grows.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// DriverName is the storage_driver value that selects this backend
+const DriverName = "redis"
+
+// Page statuses, as in the SQLite pages table
+const (
+	statusDiscovered = "discovered"
+	statusPending    = "pending"
+	statusProcessing = "processing"
+	statusCompleted  = "completed"
+	statusSkipped    = "skipped"
+	statusError      = "error"
+)
+
+// retryableErrorTypes are the error types GetRetryablePages and
+// RequeueErrorPages retry
+var retryableErrorTypes = []string{
+	crawler.ErrorTypeTimeout,
+	crawler.ErrorTypeConnectionRefused,
+	crawler.ErrorTypeConnectionReset,
+	crawler.ErrorTypeDNSFailure,
+	crawler.ErrorTypeServerError,
+}
+
+func init() {
+	storage.Register(DriverName, func(cfg *config.CrawlConfig) (crawler.Storage, error) {
+		opts, err := goredis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis_url %q: %w", cfg.RedisURL, err)
+		}
+		s, err := New(goredis.NewClient(opts), cfg.RedisKeyPrefix)
+		if err != nil {
+			return nil, err
+		}
+		s.SetHostRotation(cfg.QueueOrder != config.QueueOrderFIFO)
+		s.SetHostLease(cfg.HostLease)
+		return s, nil
+	})
+}
+
+// Storage implements crawler.Storage on a Redis server with the semantics of
+// the SQLite backend: one page record per URL serves as queue entry and
+// result, pending pages are claimed oldest first (or rotating across hosts),
+// and links to unknown URLs create 'discovered' pages that are only crawled
+// once AddToQueue promotes them. Any number of Storages, in this process or
+// others, may share a prefix; each is one worker for the host leases.
+type Storage struct {
+	client      *goredis.Client
+	ctx         context.Context
+	prefix      string // Key prefix, ending in ':'
+	owner       string // Worker ID stored in host leases
+	rotateHosts bool
+	hostLease   time.Duration
+}
+
+// New creates a Storage on client with keys below prefix and checks that
+// the server is reachable. Closing the Storage closes the client.
+func New(client *goredis.Client, prefix string) (*Storage, error) {
+	s := &Storage{
+		client: client,
+		ctx:    context.Background(),
+		prefix: strings.TrimSuffix(prefix, ":") + ":",
+		owner:  newOwner(),
+	}
+	if err := client.Ping(s.ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	return s, nil
+}
+
+// newOwner returns a worker ID naming the host and process, made unique
+// with the current time
+func newOwner() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s/%d/%d", host, os.Getpid(), time.Now().UnixNano())
+}
+
+// SetHostRotation switches the queue between plain FIFO order (the default)
+// and rotating claims across hosts (queue_order: host), as for SQLite
+func (s *Storage) SetHostRotation(enabled bool) {
+	s.rotateHosts = enabled
+}
+
+// SetHostLease sets how long claiming a URL keeps its host to this worker;
+// hosts leased to other workers are not claimed from. Zero disables leases.
+func (s *Storage) SetHostLease(d time.Duration) {
+	s.hostLease = d
+}
+
+// run runs a script with the key prefix and the current time before args
+func (s *Storage) run(script *goredis.Script, args ...any) *goredis.Cmd {
+	return script.Run(s.ctx, s.client, []string{s.prefix}, append([]any{time.Now().UnixMilli()}, args...)...)
+}
+
+// exec runs a script whose result is not needed
+func (s *Storage) exec(script *goredis.Script, args ...any) error {
+	if err := s.run(script, args...).Err(); err != nil && !errors.Is(err, goredis.Nil) {
+		return err
+	}
+	return nil
+}
+
+// AddToQueue queues URLs for crawling. New URLs and 'discovered' link-graph
+// nodes become 'pending' at the tail of the queue; URLs in any other status
+// are left alone.
+func (s *Storage) AddToQueue(urls []string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	args := make([]any, 0, 2*len(urls))
+	for _, url := range urls {
+		args = append(args, url, hostOf(url))
+	}
+	if err := s.exec(addScript, args...); err != nil {
+		return fmt.Errorf("failed to queue URLs: %w", err)
+	}
+	return nil
+}
+
+// GetNextFromQueue claims the next pending URL, or returns nil when none is pending
+func (s *Storage) GetNextFromQueue() (*crawler.URLItem, error) {
+	items, err := s.GetNextBatchFromQueue(1)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// GetNextBatchFromQueue claims up to n pending URLs of hosts not leased to
+// other workers. In FIFO order the batch is returned ordered by ID, like the
+// SQLite backend.
+func (s *Storage) GetNextBatchFromQueue(n int) ([]crawler.URLItem, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	rotate := "0"
+	if s.rotateHosts {
+		rotate = "1"
+	}
+	values, err := s.run(claimScript, n, s.hostLease.Milliseconds(), s.owner, rotate).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim URLs: %w", err)
+	}
+
+	items := make([]crawler.URLItem, 0, len(values)/3)
+	for i := 0; i+2 < len(values); i += 3 {
+		id, err := strconv.Atoi(values[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid page ID %q: %w", values[i], err)
+		}
+		retries, _ := strconv.Atoi(values[i+2])
+		items = append(items, crawler.URLItem{ID: id, URL: values[i+1], RetryCount: retries})
+	}
+	if !s.rotateHosts {
+		sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	}
+	return items, nil
+}
+
+// UpdatePageStatus sets the status of a page
+func (s *Storage) UpdatePageStatus(id int, status string) error {
+	return s.exec(setStatusScript, id, status)
+}
+
+// SavePageResult saves the crawl results for a page
+func (s *Storage) SavePageResult(id int, page *crawler.PageData) error {
+	return s.SavePageResults([]crawler.CompletedPage{{ID: id, Page: page}})
+}
+
+// SavePageResults saves the crawl results for several pages and marks them completed
+func (s *Storage) SavePageResults(pages []crawler.CompletedPage) error {
+	if len(pages) == 0 {
+		return nil
+	}
+	args := make([]any, 0, 2*len(pages))
+	for _, cp := range pages {
+		data, err := json.Marshal(cp.Page)
+		if err != nil {
+			return fmt.Errorf("failed to encode page %d: %w", cp.ID, err)
+		}
+		args = append(args, cp.ID, data)
+	}
+	if err := s.exec(completeScript, args...); err != nil {
+		return fmt.Errorf("failed to save page results: %w", err)
+	}
+	return nil
+}
+
+// SavePageError marks a page as errored, counting the attempt
+func (s *Storage) SavePageError(id int, errorType, errorMessage string) error {
+	return s.exec(failScript, id, statusError, errorType, errorMessage, "1")
+}
+
+// SavePageSkipped marks a page as skipped (e.g., robots.txt disallow)
+func (s *Storage) SavePageSkipped(id int, reason, message string) error {
+	return s.exec(failScript, id, statusSkipped, reason, message, "0")
+}
+
+// SaveLink saves a link, creating 'discovered' pages for unknown URLs. A
+// second link between the same pages is ignored.
+func (s *Storage) SaveLink(link *crawler.LinkData) error {
+	return s.SaveLinks([]*crawler.LinkData{link})
+}
+
+// SaveLinks saves several links (see SaveLink)
+func (s *Storage) SaveLinks(links []*crawler.LinkData) error {
+	if len(links) == 0 {
+		return nil
+	}
+	args := make([]any, 0, 5*len(links))
+	for _, link := range links {
+		data, err := json.Marshal(link)
+		if err != nil {
+			return fmt.Errorf("failed to encode link: %w", err)
+		}
+		args = append(args, link.SourceURL, hostOf(link.SourceURL), link.TargetURL, hostOf(link.TargetURL), data)
+	}
+	if err := s.exec(linksScript, args...); err != nil {
+		return fmt.Errorf("failed to save links: %w", err)
+	}
+	return nil
+}
+
+// SaveError records crawl error details
+func (s *Storage) SaveError(crawlErr *crawler.CrawlError) error {
+	data, err := json.Marshal(crawlErr)
+	if err != nil {
+		return fmt.Errorf("failed to encode crawl error: %w", err)
+	}
+	return s.client.RPush(s.ctx, s.prefix+"errors", data).Err()
+}
+
+// SaveExternalCheck records the result of verifying an out-of-scope link. The
+// URL keeps its status; a later check replaces an earlier one.
+func (s *Storage) SaveExternalCheck(check *crawler.ExternalCheck) error {
+	data, err := json.Marshal(check)
+	if err != nil {
+		return fmt.Errorf("failed to encode external check: %w", err)
+	}
+	return s.exec(checkScript, check.URL, hostOf(check.URL), data)
+}
+
+// HasExternalCheck reports whether url has already been verified
+func (s *Storage) HasExternalCheck(url string) bool {
+	id, err := s.client.HGet(s.ctx, s.prefix+"ids", url).Result()
+	if err != nil {
+		return false
+	}
+	checked, err := s.client.HExists(s.ctx, s.prefix+"checks", id).Result()
+	return err == nil && checked
+}
+
+// GetQueueStatus returns counts by status
+func (s *Storage) GetQueueStatus() (pending int, processing int, completed int, errors int, err error) {
+	values, err := s.client.HMGet(s.ctx, s.prefix+"counts", statusPending, statusProcessing, statusCompleted, statusError).Result()
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("failed to get queue status: %w", err)
+	}
+	counts := make([]int, len(values))
+	for i, v := range values {
+		if v, ok := v.(string); ok {
+			counts[i], _ = strconv.Atoi(v)
+		}
+	}
+	return counts[0], counts[1], counts[2], counts[3], nil
+}
+
+// GetProcessingItems returns the pages being processed, most recently claimed first
+func (s *Storage) GetProcessingItems() ([]crawler.URLItem, error) {
+	ids, err := s.client.ZRevRange(s.ctx, s.prefix+"processing", 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get processing items: %w", err)
+	}
+	return s.items(ids)
+}
+
+// GetQueuedURLs returns up to limit URLs still waiting to be crawled
+// ('pending' or 'processing'), oldest first
+func (s *Storage) GetQueuedURLs(limit int) ([]string, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	ids, err := s.client.ZRange(s.ctx, s.prefix+"queued", 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queued URLs: %w", err)
+	}
+	items, err := s.items(ids)
+	if err != nil {
+		return nil, err
+	}
+	var urls []string
+	for _, item := range items {
+		urls = append(urls, item.URL)
+	}
+	return urls, nil
+}
+
+// CleanupStaleProcessing returns pages processing for longer than timeout to
+// 'pending' and returns how many. Workers share the queue, so a timeout
+// longer than any page takes keeps one worker starting from requeueing the
+// pages of the others.
+func (s *Storage) CleanupStaleProcessing(timeout time.Duration) (int, error) {
+	cutoff := time.Now().Add(-timeout).UnixMilli()
+	n, err := s.run(staleScript, cutoff).Int()
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset stale processing pages: %w", err)
+	}
+	return n, nil
+}
+
+// HasQueuedItems reports whether any page is pending or processing
+func (s *Storage) HasQueuedItems() (bool, error) {
+	n, err := s.client.ZCard(s.ctx, s.prefix+"queued").Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check queued items: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetRetryablePages returns errored pages with a transient error type and
+// fewer than maxRetries attempts, least retried first
+func (s *Storage) GetRetryablePages(maxRetries int) ([]crawler.URLItem, error) {
+	ids, err := s.client.ZRange(s.ctx, s.prefix+"errored", 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get error pages: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	pipe := s.client.Pipeline()
+	cmds := make([]*goredis.SliceCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.HMGet(s.ctx, s.prefix+"page:"+id, "url", "retry", "error_type")
+	}
+	if _, err := pipe.Exec(s.ctx); err != nil {
+		return nil, fmt.Errorf("failed to get error pages: %w", err)
+	}
+
+	type retryable struct {
+		item    crawler.URLItem
+		retries int
+	}
+	var pages []retryable
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		url, _ := fields[0].(string)
+		retry, _ := fields[1].(string)
+		errorType, _ := fields[2].(string)
+		retries, _ := strconv.Atoi(retry)
+		if retries >= maxRetries || !isRetryable(errorType) {
+			continue
+		}
+		id, _ := strconv.Atoi(ids[i])
+		pages = append(pages, retryable{crawler.URLItem{ID: id, URL: url, RetryCount: retries}, retries})
+	}
+	// ids are in queue order, which the stable sort keeps among equal counts
+	sort.SliceStable(pages, func(i, j int) bool { return pages[i].retries < pages[j].retries })
+
+	var items []crawler.URLItem
+	for _, p := range pages {
+		items = append(items, p.item)
+	}
+	return items, nil
+}
+
+// RequeueErrorPages moves the pages GetRetryablePages returns back to 'pending'
+func (s *Storage) RequeueErrorPages(maxRetries int) (int, error) {
+	args := []any{maxRetries}
+	for _, t := range retryableErrorTypes {
+		args = append(args, t)
+	}
+	n, err := s.run(requeueScript, args...).Int()
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue error pages: %w", err)
+	}
+	return n, nil
+}
+
+// isRetryable reports whether pages failing with errorType are retried
+func isRetryable(errorType string) bool {
+	for _, t := range retryableErrorTypes {
+		if t == errorType {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMeta retrieves a metadata value, "" when unset
+func (s *Storage) GetMeta(key string) (string, error) {
+	value, err := s.client.HGet(s.ctx, s.prefix+"meta", key).Result()
+	if errors.Is(err, goredis.Nil) {
+		return "", nil
+	}
+	return value, err
+}
+
+// SetMeta stores a metadata value
+func (s *Storage) SetMeta(key, value string) error {
+	return s.client.HSet(s.ctx, s.prefix+"meta", key, value).Err()
+}
+
+// GetURLStatus returns the status of a URL and whether it is known
+func (s *Storage) GetURLStatus(url string) (status string, exists bool) {
+	id, err := s.client.HGet(s.ctx, s.prefix+"ids", url).Result()
+	if err != nil {
+		return "", false
+	}
+	status, err = s.client.HGet(s.ctx, s.prefix+"page:"+id, "status").Result()
+	if err != nil {
+		return "", false
+	}
+	return status, true
+}
+
+// Close closes the connection to the server; the data stays in Redis
+func (s *Storage) Close() error {
+	return s.client.Close()
+}
+
+// Page returns the saved crawl results of a completed page
+func (s *Storage) Page(url string) (*crawler.PageData, bool) {
+	id, err := s.client.HGet(s.ctx, s.prefix+"ids", url).Result()
+	if err != nil {
+		return nil, false
+	}
+	data, err := s.client.HGet(s.ctx, s.prefix+"page:"+id, "data").Bytes()
+	if err != nil {
+		return nil, false
+	}
+	var page crawler.PageData
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, false
+	}
+	return &page, true
+}
+
+// Links returns the saved links in the order they were saved
+func (s *Storage) Links() ([]crawler.LinkData, error) {
+	return decodeList[crawler.LinkData](s, "links")
+}
+
+// Errors returns the recorded crawl errors in the order they occurred
+func (s *Storage) Errors() ([]crawler.CrawlError, error) {
+	return decodeList[crawler.CrawlError](s, "errors")
+}
+
+// decodeList reads the JSON values of a list
+func decodeList[T any](s *Storage, key string) ([]T, error) {
+	values, err := s.client.LRange(s.ctx, s.prefix+key, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	list := make([]T, len(values))
+	for i, v := range values {
+		if err := json.Unmarshal([]byte(v), &list[i]); err != nil {
+			return nil, fmt.Errorf("invalid entry in %s: %w", key, err)
+		}
+	}
+	return list, nil
+}
+
+// items looks up the URLs of page IDs
+func (s *Storage) items(ids []string) ([]crawler.URLItem, error) {
+	pipe := s.client.Pipeline()
+	cmds := make([]*goredis.StringCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.HGet(s.ctx, s.prefix+"page:"+id, "url")
+	}
+	if len(ids) > 0 {
+		if _, err := pipe.Exec(s.ctx); err != nil && !errors.Is(err, goredis.Nil) {
+			return nil, fmt.Errorf("failed to look up pages: %w", err)
+		}
+	}
+	items := make([]crawler.URLItem, 0, len(ids))
+	for i, cmd := range cmds {
+		id, _ := strconv.Atoi(ids[i])
+		items = append(items, crawler.URLItem{ID: id, URL: cmd.Val()})
+	}
+	return items, nil
+}
+
+// hostOf returns the host[:port] of a URL, the way the SQLite host column derives it
+func hostOf(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	}
+	host, _, _ := strings.Cut(url, "/")
+	return host
+}
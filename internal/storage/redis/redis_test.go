@@ -0,0 +1,260 @@
+package redis
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+	"github.com/masahif/linktadoru/internal/storage"
+)
+
+// newTestStorage returns a worker on the server of m
+func newTestStorage(t *testing.T, m *miniredis.Miniredis) *Storage {
+	t.Helper()
+	s, err := New(goredis.NewClient(&goredis.Options{Addr: m.Addr()}), "test")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestQueueLifecycle(t *testing.T) {
+	s := newTestStorage(t, miniredis.RunT(t))
+
+	// Link targets are link-graph nodes until queued
+	if err := s.SaveLinks([]*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/a"},
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/a"},
+	}); err != nil {
+		t.Fatalf("SaveLinks failed: %v", err)
+	}
+	if links, _ := s.Links(); len(links) != 1 {
+		t.Errorf("Expected duplicate links to be ignored, got %d", len(links))
+	}
+	if status, _ := s.GetURLStatus("https://example.com/a"); status != statusDiscovered {
+		t.Errorf("Expected discovered link target, got %q", status)
+	}
+	if item, _ := s.GetNextFromQueue(); item != nil {
+		t.Errorf("Expected discovered pages not to be claimed, got %+v", item)
+	}
+
+	if err := s.AddToQueue([]string{"https://example.com/b", "https://example.com/a", "https://example.com/b"}); err != nil {
+		t.Fatalf("AddToQueue failed: %v", err)
+	}
+	pending, _, _, _, _ := s.GetQueueStatus()
+	if pending != 2 {
+		t.Errorf("Expected 2 pending pages, got %d", pending)
+	}
+	if urls, _ := s.GetQueuedURLs(1); len(urls) != 1 || urls[0] != "https://example.com/b" {
+		t.Errorf("Expected the oldest queued URL, got %v", urls)
+	}
+
+	first, _ := s.GetNextFromQueue()
+	second, _ := s.GetNextFromQueue()
+	if first == nil || second == nil || first.URL != "https://example.com/b" || second.URL != "https://example.com/a" {
+		t.Fatalf("Expected pages in queue order, got %+v and %+v", first, second)
+	}
+
+	if err := s.SavePageResult(first.ID, &crawler.PageData{URL: first.URL, StatusCode: 200}); err != nil {
+		t.Fatalf("SavePageResult failed: %v", err)
+	}
+	if page, ok := s.Page(first.URL); !ok || page.StatusCode != 200 {
+		t.Errorf("Expected saved page, got %+v", page)
+	}
+
+	// A transient error is retried until maxRetries attempts were made
+	if err := s.SavePageError(second.ID, "network_timeout", "timeout"); err != nil {
+		t.Fatalf("SavePageError failed: %v", err)
+	}
+	if items, _ := s.GetRetryablePages(2); len(items) != 1 || items[0].ID != second.ID {
+		t.Errorf("Expected the errored page to be retryable, got %+v", items)
+	}
+	if n, _ := s.RequeueErrorPages(2); n != 1 {
+		t.Errorf("Expected 1 requeued page, got %d", n)
+	}
+	retry, _ := s.GetNextFromQueue()
+	if retry == nil || retry.ID != second.ID {
+		t.Fatalf("Expected the requeued page, got %+v", retry)
+	}
+	_ = s.SavePageError(retry.ID, "network_timeout", "timeout")
+	if n, _ := s.RequeueErrorPages(2); n != 0 {
+		t.Errorf("Expected no requeue after the last retry, got %d", n)
+	}
+
+	pending, processing, completed, errors, _ := s.GetQueueStatus()
+	if pending != 0 || processing != 0 || completed != 1 || errors != 1 {
+		t.Errorf("Unexpected queue status %d/%d/%d/%d", pending, processing, completed, errors)
+	}
+	if has, _ := s.HasQueuedItems(); has {
+		t.Error("Expected no queued items")
+	}
+}
+
+func TestCleanupStaleProcessing(t *testing.T) {
+	s := newTestStorage(t, miniredis.RunT(t))
+	_ = s.AddToQueue([]string{"https://example.com/"})
+	item, _ := s.GetNextFromQueue()
+
+	if n, _ := s.CleanupStaleProcessing(time.Hour); n != 0 {
+		t.Errorf("Expected nothing recovered, got %d", n)
+	}
+	if items, _ := s.GetProcessingItems(); len(items) != 1 || items[0].ID != item.ID {
+		t.Errorf("Expected a fresh claim to stay processing, got %+v", items)
+	}
+
+	if n, _ := s.CleanupStaleProcessing(-time.Second); n != 1 {
+		t.Errorf("Expected 1 page recovered, got %d", n)
+	}
+	again, _ := s.GetNextFromQueue()
+	if again == nil || again.ID != item.ID {
+		t.Errorf("Expected the stale page to be claimable again, got %+v", again)
+	}
+}
+
+func TestHostRotation(t *testing.T) {
+	s := newTestStorage(t, miniredis.RunT(t))
+	s.SetHostRotation(true)
+	_ = s.AddToQueue([]string{
+		"https://a.example/1", "https://a.example/2", "https://a.example/3",
+		"https://b.example/1", "https://c.example:8080/1",
+	})
+
+	items, _ := s.GetNextBatchFromQueue(4)
+	var hosts []string
+	for _, item := range items {
+		hosts = append(hosts, hostOf(item.URL))
+	}
+	want := []string{"a.example", "b.example", "c.example:8080", "a.example"}
+	if len(hosts) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, hosts)
+	}
+	for i := range want {
+		if hosts[i] != want[i] {
+			t.Errorf("Expected claims to rotate across hosts %v, got %v", want, hosts)
+			break
+		}
+	}
+}
+
+func TestHostRotationReadySet(t *testing.T) {
+	m := miniredis.RunT(t)
+	a, b := newTestStorage(t, m), newTestStorage(t, m)
+	for _, s := range []*Storage{a, b} {
+		s.SetHostRotation(true)
+		s.SetHostLease(time.Minute)
+	}
+	var urls []string
+	for i := 0; i < 40; i++ {
+		urls = append(urls, fmt.Sprintf("https://h%02d.example/%d", i%20, i))
+	}
+	_ = a.AddToQueue(urls)
+
+	// P:ready holds exactly the hosts with pending pages, scored by pages in
+	// flight and then their last turn
+	checkReady := func() {
+		t.Helper()
+		heads, _ := m.ZMembers("test:heads")
+		ready, _ := m.ZMembers("test:ready")
+		if len(ready) != len(heads) {
+			t.Fatalf("P:ready has %d hosts, P:heads %d", len(ready), len(heads))
+		}
+		weight, _ := strconv.ParseFloat(readyInflightWeight, 64)
+		for _, host := range heads {
+			inflight, _ := strconv.Atoi(m.HGet("test:inflight", host))
+			turn, _ := m.ZScore("test:turns", host)
+			if score, err := m.ZScore("test:ready", host); err != nil || score != float64(inflight)*weight+turn {
+				t.Errorf("P:ready score of %s = %v (%v), want %d in flight and turn %v", host, score, err, inflight, turn)
+			}
+		}
+	}
+	checkReady()
+
+	// Each worker's claims go to distinct, unleased hosts
+	first, _ := a.GetNextBatchFromQueue(5)
+	second, _ := b.GetNextBatchFromQueue(5)
+	seen := map[string]bool{}
+	for _, item := range append(first, second...) {
+		if host := hostOf(item.URL); seen[host] {
+			t.Errorf("Host %s was claimed twice while others were idle", host)
+		} else {
+			seen[host] = true
+		}
+	}
+	if len(seen) != 10 {
+		t.Errorf("Expected 10 distinct hosts, got %d", len(seen))
+	}
+	checkReady()
+
+	_ = a.SavePageResult(first[0].ID, &crawler.PageData{URL: first[0].URL, StatusCode: 200})
+	_ = b.SavePageError(second[0].ID, crawler.ErrorTypeTimeout, "timeout")
+	checkReady()
+
+	// A queue written before P:ready existed gets it on the next claim
+	m.Del("test:ready")
+	if item, _ := a.GetNextFromQueue(); item == nil {
+		t.Fatal("Expected a claim after P:ready was removed")
+	}
+	checkReady()
+}
+
+func TestHostLeases(t *testing.T) {
+	m := miniredis.RunT(t)
+	a, b := newTestStorage(t, m), newTestStorage(t, m)
+	for _, s := range []*Storage{a, b} {
+		s.SetHostLease(time.Minute)
+	}
+	_ = a.AddToQueue([]string{"https://a.example/1", "https://a.example/2", "https://b.example/1"})
+
+	first, _ := a.GetNextFromQueue()
+	if first == nil || first.URL != "https://a.example/1" {
+		t.Fatalf("Expected the oldest URL, got %+v", first)
+	}
+
+	// a.example is leased to the first worker, so the second one moves on
+	items, _ := b.GetNextBatchFromQueue(2)
+	if len(items) != 1 || items[0].URL != "https://b.example/1" {
+		t.Fatalf("Expected only the URL of the unleased host, got %+v", items)
+	}
+	if item, _ := b.GetNextFromQueue(); item != nil {
+		t.Errorf("Expected no claimable URL while a.example is leased, got %+v", item)
+	}
+
+	// The lease holder keeps claiming its host
+	if item, _ := a.GetNextFromQueue(); item == nil || item.URL != "https://a.example/2" {
+		t.Errorf("Expected the lease holder to claim its host, got %+v", item)
+	}
+
+	// Leases of a stopped worker expire
+	_ = a.UpdatePageStatus(first.ID, statusPending)
+	m.FastForward(time.Minute)
+	if item, _ := b.GetNextFromQueue(); item == nil || item.ID != first.ID {
+		t.Errorf("Expected the host to be claimable once the lease expired, got %+v", item)
+	}
+}
+
+func TestRedisDriverRegistered(t *testing.T) {
+	m := miniredis.RunT(t)
+	cfg := config.DefaultConfig()
+	cfg.StorageDriver = DriverName
+	cfg.RedisURL = "redis://" + m.Addr()
+	store, err := storage.Open(cfg)
+	if err != nil {
+		t.Fatalf("Failed to open redis driver: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	if s, ok := store.(*Storage); !ok || !s.rotateHosts || s.hostLease != cfg.HostLease {
+		t.Errorf("Expected a redis Storage rotating hosts with leases, got %T", store)
+	}
+
+	cfg.RedisURL = "redis://" + m.Addr() + "/not-a-db"
+	if _, err := storage.Open(cfg); err == nil {
+		t.Error("Expected an invalid redis_url to be rejected")
+	}
+}
@@ -0,0 +1,290 @@
+package redis
+
+import goredis "github.com/redis/go-redis/v9"
+
+// Keys below the crawl's prefix P:
+//
+//	P:next_id          last page ID handed out
+//	P:order            last queue position handed out
+//	P:claims           claims made so far, ordering the host turns
+//	P:ids              hash of URL -> page ID
+//	P:page:<id>        hash of a page: url, host, status, order, retry,
+//	                   error_type, error_message, data (PageData as JSON)
+//	P:counts           hash of status -> number of pages
+//	P:q:<host>         sorted set of the pending page IDs of a host, by position
+//	P:heads            sorted set of hosts with pending pages, by their oldest position
+//	P:turns            sorted set of hosts, by the claim number of their last turn
+//	P:ready            sorted set of hosts with pending pages, by pages in flight
+//	                   times readyInflightWeight plus their last turn
+//	P:inflight         hash of host -> pages of the host being processed
+//	P:lease:<host>     worker holding the host, expiring host_lease after its last claim
+//	P:processing       sorted set of processing page IDs, by claim time (Unix ms)
+//	P:queued           sorted set of pending and processing page IDs, by position
+//	P:errored          sorted set of errored page IDs, by position
+//	P:links, P:errors  lists of saved links and crawl errors as JSON
+//	P:link_keys        set of "source:target" page IDs of saved links
+//	P:checks           hash of page ID -> external check as JSON
+//	P:meta             hash of crawl metadata
+//
+// Every change of a page status runs in a script, so the status counts,
+// host queues and leases stay consistent with any number of workers.
+
+// readyInflightWeight separates the pages in flight from the last turn in a
+// P:ready score, so hosts sort by the first and then the second. Scores stay
+// exact integers up to 2^53, enough for 8192 pages of one host in flight and
+// 2^40 claims.
+const readyInflightWeight = "1099511627776"
+
+// prelude is shared by the scripts. KEYS[1] is the key prefix and ARGV[1]
+// the current time in Unix milliseconds.
+const prelude = `
+local P = KEYS[1]
+local now = tonumber(ARGV[1])
+
+local function update_head(host)
+  local head = redis.call('ZRANGE', P .. 'q:' .. host, 0, 0, 'WITHSCORES')
+  if #head == 0 then
+    redis.call('ZREM', P .. 'heads', host)
+  else
+    redis.call('ZADD', P .. 'heads', head[2], host)
+  end
+end
+
+-- update_ready rescores host in P:ready, or removes it without pending pages
+local function update_ready(host)
+  if redis.call('ZSCORE', P .. 'heads', host) then
+    local inflight = tonumber(redis.call('HGET', P .. 'inflight', host) or 0)
+    local turn = tonumber(redis.call('ZSCORE', P .. 'turns', host) or 0)
+    redis.call('ZADD', P .. 'ready', inflight * ` + readyInflightWeight + ` + turn, host)
+  else
+    redis.call('ZREM', P .. 'ready', host)
+  end
+end
+
+local function queued(status)
+  return status == 'pending' or status == 'processing'
+end
+
+local function set_status(id, status)
+  local pk = P .. 'page:' .. id
+  local fields = redis.call('HMGET', pk, 'status', 'host', 'order')
+  local old, host, order = fields[1], fields[2], fields[3]
+  if old == status or not host then
+    return
+  end
+
+  if old then
+    redis.call('HINCRBY', P .. 'counts', old, -1)
+  end
+  if old == 'pending' then
+    redis.call('ZREM', P .. 'q:' .. host, id)
+    update_head(host)
+  elseif old == 'processing' then
+    redis.call('ZREM', P .. 'processing', id)
+    redis.call('HINCRBY', P .. 'inflight', host, -1)
+  elseif old == 'error' then
+    redis.call('ZREM', P .. 'errored', id)
+  end
+  if queued(old) and not queued(status) then
+    redis.call('ZREM', P .. 'queued', id)
+  end
+
+  redis.call('HSET', pk, 'status', status)
+  redis.call('HINCRBY', P .. 'counts', status, 1)
+  if status == 'pending' then
+    redis.call('ZADD', P .. 'q:' .. host, order, id)
+    update_head(host)
+  elseif status == 'processing' then
+    redis.call('ZADD', P .. 'processing', now, id)
+    redis.call('HINCRBY', P .. 'inflight', host, 1)
+  elseif status == 'error' then
+    redis.call('ZADD', P .. 'errored', order, id)
+  end
+  if queued(status) then
+    redis.call('ZADD', P .. 'queued', order, id)
+  end
+  if queued(old) or queued(status) then
+    update_ready(host)
+  end
+end
+
+-- page_for returns the ID of url, creating a page without a status for an
+-- unknown URL, and whether it was created
+local function page_for(url, host)
+  local id = redis.call('HGET', P .. 'ids', url)
+  if id then
+    return id, false
+  end
+  id = tostring(redis.call('INCR', P .. 'next_id'))
+  redis.call('HSET', P .. 'ids', url, id)
+  redis.call('HSET', P .. 'page:' .. id, 'url', url, 'host', host)
+  return id, true
+end
+
+local function enqueue(id, status)
+  redis.call('HSET', P .. 'page:' .. id, 'order', redis.call('INCR', P .. 'order'))
+  set_status(id, status)
+end
+
+-- node returns the page of url, creating a 'discovered' link-graph node for
+-- an unknown URL
+local function node(url, host)
+  local id, created = page_for(url, host)
+  if created then
+    enqueue(id, 'discovered')
+  end
+  return id
+end
+`
+
+// addScript queues URLs. ARGV[2:] are URL and host pairs.
+var addScript = goredis.NewScript(prelude + `
+for i = 2, #ARGV, 2 do
+  local id, created = page_for(ARGV[i], ARGV[i + 1])
+  if created or redis.call('HGET', P .. 'page:' .. id, 'status') == 'discovered' then
+    enqueue(id, 'pending')
+  end
+end
+`)
+
+// claimScript claims up to ARGV[2] pending pages for worker ARGV[4] and
+// returns their IDs, URLs and failure counts. Hosts leased to other workers are skipped;
+// claiming a page leases its host for ARGV[3] milliseconds (0 = no leases).
+// ARGV[5] is "1" to rotate across hosts: the host with the fewest pages in
+// flight, then the longest since its last turn, goes first. The next host is
+// the first of P:ready (or P:heads without rotation) not leased to another
+// worker, so a claim costs O(log hosts) plus the leased hosts passed over,
+// however many hosts have pending pages.
+var claimScript = goredis.NewScript(prelude + `
+local n, lease, owner, rotate = tonumber(ARGV[2]), tonumber(ARGV[3]), ARGV[4], ARGV[5] == '1'
+local order = rotate and P .. 'ready' or P .. 'heads'
+
+-- Queues written before P:ready existed get it once
+if rotate and redis.call('EXISTS', order) == 0 then
+  for _, host in ipairs(redis.call('ZRANGE', P .. 'heads', 0, -1)) do
+    update_ready(host)
+  end
+end
+
+local leased = {}
+local function next_host()
+  local start = 0
+  while true do
+    local hosts = redis.call('ZRANGE', order, start, start + 15)
+    if #hosts == 0 then
+      return nil
+    end
+    for _, host in ipairs(hosts) do
+      if not leased[host] then
+        local holder = lease > 0 and redis.call('GET', P .. 'lease:' .. host)
+        if holder and holder ~= owner then
+          leased[host] = true
+        else
+          return host
+        end
+      end
+    end
+    start = start + #hosts
+  end
+end
+
+local items = {}
+while #items < 3 * n do
+  local best = next_host()
+  if not best then
+    break
+  end
+
+  local id = redis.call('ZRANGE', P .. 'q:' .. best, 0, 0)[1]
+  set_status(id, 'processing')
+  redis.call('ZADD', P .. 'turns', redis.call('INCR', P .. 'claims'), best)
+  update_ready(best)
+  if lease > 0 then
+    redis.call('SET', P .. 'lease:' .. best, owner, 'PX', lease)
+  end
+  table.insert(items, id)
+  local page = redis.call('HMGET', P .. 'page:' .. id, 'url', 'retry')
+  table.insert(items, page[1])
+  table.insert(items, page[2] or '0')
+end
+return items
+`)
+
+// setStatusScript sets page statuses. ARGV[2:] are page ID and status pairs.
+var setStatusScript = goredis.NewScript(prelude + `
+for i = 2, #ARGV, 2 do
+  set_status(ARGV[i], ARGV[i + 1])
+end
+`)
+
+// completeScript saves crawl results and marks the pages completed. ARGV[2:]
+// are page ID and PageData JSON pairs.
+var completeScript = goredis.NewScript(prelude + `
+for i = 2, #ARGV, 2 do
+  local pk = P .. 'page:' .. ARGV[i]
+  if redis.call('EXISTS', pk) == 1 then
+    redis.call('HSET', pk, 'data', ARGV[i + 1])
+    set_status(ARGV[i], 'completed')
+  end
+end
+`)
+
+// failScript sets page ARGV[2] to status ARGV[3] with error type ARGV[4] and
+// message ARGV[5], counting an attempt when ARGV[6] is "1"
+var failScript = goredis.NewScript(prelude + `
+local pk = P .. 'page:' .. ARGV[2]
+if redis.call('EXISTS', pk) == 1 then
+  redis.call('HSET', pk, 'error_type', ARGV[4], 'error_message', ARGV[5])
+  if ARGV[6] == '1' then
+    redis.call('HINCRBY', pk, 'retry', 1)
+  end
+  set_status(ARGV[2], ARGV[3])
+end
+`)
+
+// linksScript saves links, ignoring a second link between the same pages.
+// ARGV[2:] are source URL, source host, target URL, target host and
+// LinkData JSON.
+var linksScript = goredis.NewScript(prelude + `
+for i = 2, #ARGV, 5 do
+  local source = node(ARGV[i], ARGV[i + 1])
+  local target = node(ARGV[i + 2], ARGV[i + 3])
+  if redis.call('SADD', P .. 'link_keys', source .. ':' .. target) == 1 then
+    redis.call('RPUSH', P .. 'links', ARGV[i + 4])
+  end
+end
+`)
+
+// checkScript saves the external check ARGV[4] of URL ARGV[2] on host ARGV[3]
+var checkScript = goredis.NewScript(prelude + `
+redis.call('HSET', P .. 'checks', node(ARGV[2], ARGV[3]), ARGV[4])
+`)
+
+// staleScript returns pages claimed before ARGV[2] (Unix ms) to 'pending'
+// and returns how many
+var staleScript = goredis.NewScript(prelude + `
+local ids = redis.call('ZRANGEBYSCORE', P .. 'processing', '-inf', '(' .. ARGV[2])
+for _, id in ipairs(ids) do
+  set_status(id, 'pending')
+end
+return #ids
+`)
+
+// requeueScript returns errored pages with fewer than ARGV[2] attempts and
+// an error type among ARGV[3:] to 'pending' and returns how many
+var requeueScript = goredis.NewScript(prelude + `
+local max = tonumber(ARGV[2])
+local types = {}
+for i = 3, #ARGV do
+  types[ARGV[i]] = true
+end
+local n = 0
+for _, id in ipairs(redis.call('ZRANGE', P .. 'errored', 0, -1)) do
+  local fields = redis.call('HMGET', P .. 'page:' .. id, 'retry', 'error_type')
+  if tonumber(fields[1] or 0) < max and types[fields[2]] then
+    set_status(id, 'pending')
+    n = n + 1
+  end
+end
+return n
+`)
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// DriverSQLite is the built-in storage driver and the default storage_driver
+const DriverSQLite = "sqlite"
+
+// ErrUnknownDriver is returned by Open when storage_driver names no registered driver
+var ErrUnknownDriver = errors.New("unknown storage driver")
+
+// Factory opens a storage backend for a crawl configuration
+type Factory func(cfg *config.CrawlConfig) (crawler.Storage, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Factory)
+)
+
+func init() {
+	Register(DriverSQLite, openSQLite)
+}
+
+// Register makes a storage backend available under name, selected with the
+// storage_driver setting. Backends register from an init function, so a
+// blank import of their package in the main package is enough to compile
+// them in. Like database/sql.Register, it panics when name is registered
+// twice or factory is nil.
+func Register(name string, factory Factory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if factory == nil {
+		panic("storage: Register factory is nil")
+	}
+	if _, dup := drivers[name]; dup {
+		panic("storage: Register called twice for driver " + name)
+	}
+	drivers[name] = factory
+}
+
+// Drivers returns the names of the registered storage drivers, sorted
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DriverName returns the storage driver cfg selects ("" = sqlite)
+func DriverName(cfg *config.CrawlConfig) string {
+	if cfg.StorageDriver == "" {
+		return DriverSQLite
+	}
+	return cfg.StorageDriver
+}
+
+// Open opens the storage backend cfg selects (see DriverName)
+func Open(cfg *config.CrawlConfig) (crawler.Storage, error) {
+	name := DriverName(cfg)
+
+	driversMu.RLock()
+	factory, ok := drivers[name]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (registered: %s)", ErrUnknownDriver, name, strings.Join(Drivers(), ", "))
+	}
+	return factory(cfg)
+}
+
+// openSQLite opens the configured database for a crawl, attaching the results
+// database when one is set and upgrading a database written with an older
+// schema
+func openSQLite(cfg *config.CrawlConfig) (crawler.Storage, error) {
+	store, err := MigrateSQLiteStorage(cfg.DatabasePath, cfg.ResultsDatabasePath, cfg.GetDatabasePassphrase())
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range store.AppliedMigrations() {
+		slog.Info("Applied schema migration", "database", cfg.DatabasePath, "migration", name)
+	}
+	store.SetHostRotation(cfg.QueueOrder != config.QueueOrderFIFO)
+	return store, nil
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/masahif/linktadoru/internal/config"
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestOpenRegisteredDriver(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.DatabasePath = filepath.Join(t.TempDir(), "registry.db")
+
+	store, err := Open(cfg)
+	if err != nil {
+		t.Fatalf("Failed to open default driver: %v", err)
+	}
+	if _, ok := store.(*SQLiteStorage); !ok {
+		t.Errorf("Expected the sqlite driver to open a SQLiteStorage, got %T", store)
+	}
+	_ = store.Close()
+
+	var opened *config.CrawlConfig
+	Register("registry-test", func(cfg *config.CrawlConfig) (crawler.Storage, error) {
+		opened = cfg
+		return NewSQLiteStorage(":memory:")
+	})
+	if !slices.Contains(Drivers(), "registry-test") || !slices.Contains(Drivers(), DriverSQLite) {
+		t.Errorf("Expected registered drivers to be listed, got %v", Drivers())
+	}
+
+	cfg.StorageDriver = "registry-test"
+	store, err = Open(cfg)
+	if err != nil {
+		t.Fatalf("Failed to open registered driver: %v", err)
+	}
+	_ = store.Close()
+	if opened != cfg {
+		t.Error("Expected the factory to receive the configuration")
+	}
+
+	cfg.StorageDriver = "clickhouse"
+	if _, err := Open(cfg); !errors.Is(err, ErrUnknownDriver) {
+		t.Errorf("Expected ErrUnknownDriver, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a driver twice to panic")
+		}
+	}()
+	Register(DriverSQLite, openSQLite)
+}
@@ -0,0 +1,168 @@
+// Package storage — crawl summary reports.
+//
+// The queries here back the report command: overall totals, the status code
+// breakdown, the slowest pages and titles shared by several pages. Broken
+// links and redirect chains come from GetBrokenTargets and GetRedirectChains.
+package storage
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ReportTotals are the overall counts of a crawl
+type ReportTotals struct {
+	Pages      int     `json:"pages"`      // Queued or crawled pages (discovered-only nodes excluded)
+	Completed  int     `json:"completed"`  // Pages crawled successfully
+	Errors     int     `json:"errors"`     // Pages that could not be fetched
+	Skipped    int     `json:"skipped"`    // Pages skipped (robots.txt, content type, ...)
+	Pending    int     `json:"pending"`    // Pages still queued or in progress
+	Discovered int     `json:"discovered"` // Link targets that were never queued
+	Links      int     `json:"links"`      // Stored links
+	Bytes      int64   `json:"bytes"`      // Total response size of completed pages
+	AvgTTFBMs  float64 `json:"avg_ttfb_ms"`
+}
+
+// StatusCodeCount is the number of crawled pages answering with a status code
+type StatusCodeCount struct {
+	StatusCode int `json:"status_code"`
+	Pages      int `json:"pages"`
+}
+
+// SlowPage is a crawled page with its timings
+type SlowPage struct {
+	URL            string `json:"url"`
+	StatusCode     int    `json:"status_code"`
+	TTFBMs         int    `json:"ttfb_ms"`
+	DownloadTimeMs int    `json:"download_time_ms"`
+	Bytes          int64  `json:"bytes"`
+}
+
+// DuplicateTitle is a title shared by several crawled pages
+type DuplicateTitle struct {
+	Title string   `json:"title"`
+	URLs  []string `json:"urls"`
+}
+
+// GetReportTotals returns the overall counts of the crawl
+func (s *SQLiteStorage) GetReportTotals() (*ReportTotals, error) {
+	var totals ReportTotals
+	err := s.read.QueryRow(`
+		SELECT
+			COALESCE(SUM(status != 'discovered'), 0),
+			COALESCE(SUM(status = 'completed'), 0),
+			COALESCE(SUM(status = 'error'), 0),
+			COALESCE(SUM(status = 'skipped'), 0),
+			COALESCE(SUM(status IN ('pending', 'processing')), 0),
+			COALESCE(SUM(status = 'discovered'), 0),
+			COALESCE(SUM(CASE WHEN status = 'completed' THEN response_size_bytes END), 0),
+			COALESCE(ROUND(AVG(CASE WHEN status = 'completed' THEN ttfb_ms END), 1), 0)
+		FROM pages
+	`).Scan(&totals.Pages, &totals.Completed, &totals.Errors, &totals.Skipped, &totals.Pending,
+		&totals.Discovered, &totals.Bytes, &totals.AvgTTFBMs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pages: %w", err)
+	}
+	if err := s.read.QueryRow("SELECT COUNT(*) FROM link_relations").Scan(&totals.Links); err != nil {
+		return nil, fmt.Errorf("failed to count links: %w", err)
+	}
+	return &totals, nil
+}
+
+// GetStatusCodeCounts returns the number of crawled pages per HTTP status
+// code, ordered by status code
+func (s *SQLiteStorage) GetStatusCodeCounts() ([]StatusCodeCount, error) {
+	rows, err := s.read.Query(`
+		SELECT status_code, COUNT(*)
+		FROM pages
+		WHERE status = 'completed' AND status_code IS NOT NULL
+		GROUP BY status_code
+		ORDER BY status_code
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status codes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var counts []StatusCodeCount
+	for rows.Next() {
+		var count StatusCodeCount
+		if err := rows.Scan(&count.StatusCode, &count.Pages); err != nil {
+			return nil, fmt.Errorf("failed to scan status code: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
+
+// GetSlowestPages returns up to limit crawled pages with the longest time to
+// first byte plus download time, slowest first
+func (s *SQLiteStorage) GetSlowestPages(limit int) ([]SlowPage, error) {
+	rows, err := s.read.Query(`
+		SELECT url, COALESCE(status_code, 0), COALESCE(ttfb_ms, 0), COALESCE(download_time_ms, 0),
+			COALESCE(response_size_bytes, 0)
+		FROM pages
+		WHERE status = 'completed' AND ttfb_ms IS NOT NULL
+		ORDER BY COALESCE(ttfb_ms, 0) + COALESCE(download_time_ms, 0) DESC, url
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query slowest pages: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var pages []SlowPage
+	for rows.Next() {
+		var page SlowPage
+		if err := rows.Scan(&page.URL, &page.StatusCode, &page.TTFBMs, &page.DownloadTimeMs, &page.Bytes); err != nil {
+			return nil, fmt.Errorf("failed to scan slow page: %w", err)
+		}
+		pages = append(pages, page)
+	}
+	return pages, rows.Err()
+}
+
+// GetDuplicateTitles returns the titles shared by more than one successfully
+// crawled page, most shared first, then by title. Titles are compared after
+// decryption, since encrypted values never match.
+func (s *SQLiteStorage) GetDuplicateTitles() ([]DuplicateTitle, error) {
+	rows, err := s.read.Query(`
+		SELECT url, title
+		FROM pages
+		WHERE status = 'completed' AND status_code < 300 AND title IS NOT NULL AND title != ''
+		ORDER BY url
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query titles: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	byTitle := map[string][]string{}
+	for rows.Next() {
+		var url, title string
+		if err := rows.Scan(&url, &title); err != nil {
+			return nil, fmt.Errorf("failed to scan title: %w", err)
+		}
+		if title, err = s.DecryptField(title); err != nil {
+			return nil, err
+		}
+		byTitle[title] = append(byTitle[title], url)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read titles: %w", err)
+	}
+
+	var duplicates []DuplicateTitle
+	for title, urls := range byTitle {
+		if len(urls) > 1 {
+			duplicates = append(duplicates, DuplicateTitle{Title: title, URLs: urls})
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		if len(duplicates[i].URLs) != len(duplicates[j].URLs) {
+			return len(duplicates[i].URLs) > len(duplicates[j].URLs)
+		}
+		return duplicates[i].Title < duplicates[j].Title
+	})
+	return duplicates, nil
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestReportQueries(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "report.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	pages := map[string]*crawler.PageData{
+		"https://example.com/":        {StatusCode: 200, Title: "Home", TTFB: 50 * time.Millisecond, DownloadTime: 10 * time.Millisecond, ResponseSize: 100},
+		"https://example.com/a":       {StatusCode: 200, Title: "Products", TTFB: 400 * time.Millisecond, DownloadTime: 100 * time.Millisecond, ResponseSize: 200},
+		"https://example.com/b":       {StatusCode: 200, Title: "Products", TTFB: 100 * time.Millisecond, ResponseSize: 300},
+		"https://example.com/missing": {StatusCode: 404, Title: "Products", TTFB: 20 * time.Millisecond},
+	}
+	urls := []string{"https://example.com/", "https://example.com/a", "https://example.com/b", "https://example.com/missing", "https://example.com/down", "https://example.com/next"}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		item, err := store.GetNextFromQueue()
+		if err != nil || item == nil {
+			t.Fatalf("Failed to dequeue: %v", err)
+		}
+		if page, ok := pages[item.URL]; ok {
+			page.URL = item.URL
+			page.HTTPHeaders = map[string]string{}
+			err = store.SavePageResult(item.ID, page)
+		} else {
+			err = store.SavePageError(item.ID, "network_error", "connection refused")
+		}
+		if err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+	if err := store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: urls[0], TargetURL: urls[1], LinkType: "internal"},
+		{SourceURL: urls[0], TargetURL: "https://other.example.net/", LinkType: "external"},
+	}); err != nil {
+		t.Fatalf("Failed to save links: %v", err)
+	}
+
+	totals, err := store.GetReportTotals()
+	if err != nil {
+		t.Fatalf("GetReportTotals failed: %v", err)
+	}
+	want := ReportTotals{Pages: 6, Completed: 4, Errors: 1, Pending: 1, Discovered: 1, Links: 2, Bytes: 600, AvgTTFBMs: 142.5}
+	if *totals != want {
+		t.Errorf("GetReportTotals = %+v, want %+v", *totals, want)
+	}
+
+	counts, err := store.GetStatusCodeCounts()
+	if err != nil {
+		t.Fatalf("GetStatusCodeCounts failed: %v", err)
+	}
+	if !reflect.DeepEqual(counts, []StatusCodeCount{{200, 3}, {404, 1}}) {
+		t.Errorf("GetStatusCodeCounts = %+v", counts)
+	}
+
+	slowest, err := store.GetSlowestPages(2)
+	if err != nil {
+		t.Fatalf("GetSlowestPages failed: %v", err)
+	}
+	if len(slowest) != 2 || slowest[0].URL != "https://example.com/a" || slowest[0].TTFBMs != 400 || slowest[1].URL != "https://example.com/b" {
+		t.Errorf("GetSlowestPages = %+v", slowest)
+	}
+
+	// The 404 page's title does not count
+	duplicates, err := store.GetDuplicateTitles()
+	if err != nil {
+		t.Fatalf("GetDuplicateTitles failed: %v", err)
+	}
+	if !reflect.DeepEqual(duplicates, []DuplicateTitle{{Title: "Products", URLs: []string{"https://example.com/a", "https://example.com/b"}}}) {
+		t.Errorf("GetDuplicateTitles = %+v", duplicates)
+	}
+}
@@ -0,0 +1,163 @@
+// Package storage — separate results database.
+//
+// A crawl database can keep its per-page result tables (links, alternates,
+// headings, external checks, errors, ...) in a second SQLite file that is
+// ATTACHed to every connection under the schema name "results". The queue
+// file then only holds pages and crawl_meta and stays small, while the results
+// file can be rotated or archived between sessions: pointing a later session
+// at a new results file keeps the queue and its scheduling state intact.
+//
+// SQLite cannot enforce foreign keys or persist views across database files,
+// so a split database runs with foreign_keys off and the views that join both
+// files are recreated as TEMP views on each connection.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+
+	"modernc.org/sqlite"
+)
+
+const (
+	// resultsSchema is the name the results database is attached under
+	resultsSchema = "results"
+	// metaResultsDatabase records that a queue database keeps its results in
+	// a separate file, and the path of the file last used
+	metaResultsDatabase = "results_database"
+)
+
+var (
+	// ErrResultsDatabaseRequired is returned when a queue database that keeps
+	// its results in a separate file is opened without one
+	ErrResultsDatabaseRequired = errors.New("database keeps its results in a separate file: a results database is required")
+	// ErrResultsDatabaseUnsupported is returned when a results database is given
+	// for a database that already stores its results itself
+	ErrResultsDatabaseUnsupported = errors.New("database already stores its results: a separate results database cannot be used")
+)
+
+// resultsViewsTempSQL creates the views that join the queue and results files
+// as TEMP views, which may reference tables in any attached database
+var resultsViewsTempSQL = strings.ReplaceAll(resultsViewsSQL, "CREATE VIEW IF NOT EXISTS", "CREATE TEMP VIEW IF NOT EXISTS")
+
+// connector opens connections through a dedicated driver so that connection
+// hooks apply to this storage only
+type connector struct {
+	driver *sqlite.Driver
+	dsn    string
+}
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+
+func (c *connector) Driver() driver.Driver { return c.driver }
+
+// openSplitDB opens dsn with resultsPath attached to every connection. A
+// write pool creates the results file with its tables first, so that the TEMP
+// views set up on each connection resolve against it; a readOnly pool expects
+// them to exist and its connections become query-only once the views do.
+func openSplitDB(dsn, resultsPath string, readOnly bool) (*sql.DB, error) {
+	if !readOnly {
+		if err := initResultsDatabase(resultsPath); err != nil {
+			return nil, err
+		}
+	}
+
+	drv := &sqlite.Driver{}
+	drv.RegisterConnectionHook(func(conn sqlite.ExecQuerierContext, _ string) error {
+		ctx := context.Background()
+		attach := "ATTACH DATABASE ? AS " + resultsSchema
+		if _, err := conn.ExecContext(ctx, attach, []driver.NamedValue{{Ordinal: 1, Value: resultsPath}}); err != nil {
+			return fmt.Errorf("failed to attach results database: %w", err)
+		}
+		// Foreign keys cannot reference pages across database files
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF", nil); err != nil {
+			return err
+		}
+		if _, err := conn.ExecContext(ctx, resultsViewsTempSQL, nil); err != nil {
+			return fmt.Errorf("failed to create result views: %w", err)
+		}
+		if readOnly {
+			if _, err := conn.ExecContext(ctx, "PRAGMA query_only = ON", nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return sql.OpenDB(&connector{driver: drv, dsn: dsn}), nil
+}
+
+// initResultsDatabase creates the result tables in the results file
+func initResultsDatabase(resultsPath string) error {
+	dsn, err := databaseURI(resultsPath, nil)
+	if err != nil {
+		return err
+	}
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open results database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	if _, err := db.Exec("PRAGMA journal_mode = WAL"); err != nil {
+		return fmt.Errorf("failed to execute pragma on results database: %w", err)
+	}
+	if _, err := db.Exec(resultsSchemaSQL); err != nil {
+		return fmt.Errorf("failed to create results schema: %w", err)
+	}
+	return nil
+}
+
+// checkResultsLayout refuses to mix the single-file and split layouts: a
+// database that stores its results itself cannot be given a results file, and
+// a queue database whose results live elsewhere cannot be opened without one
+func (s *SQLiteStorage) checkResultsLayout() error {
+	hasResults, err := s.mainTableExists("link_relations")
+	if err != nil {
+		return err
+	}
+	if s.resultsPath != "" {
+		if hasResults {
+			return ErrResultsDatabaseUnsupported
+		}
+		return nil
+	}
+
+	hasMeta, err := s.mainTableExists("crawl_meta")
+	if err != nil || !hasMeta {
+		return err
+	}
+	previous, err := s.GetMeta(metaResultsDatabase)
+	if err != nil {
+		return err
+	}
+	if previous != "" && !hasResults {
+		return fmt.Errorf("%w (last used: %s)", ErrResultsDatabaseRequired, previous)
+	}
+	return nil
+}
+
+// mainTableExists reports whether the main database file has the named table
+func (s *SQLiteStorage) mainTableExists(name string) (bool, error) {
+	var found string
+	err := s.db.QueryRow(
+		"SELECT name FROM main.sqlite_master WHERE type='table' AND name=?", name,
+	).Scan(&found)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s table: %w", name, err)
+	}
+	return true, nil
+}
+
+// ResultsDatabasePath returns the path of the attached results database, or
+// "" when results are stored in the main database
+func (s *SQLiteStorage) ResultsDatabasePath() string {
+	return s.resultsPath
+}
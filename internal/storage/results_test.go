@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// countRows counts the rows of table in the SQLite file at path
+func countRows(t *testing.T, path, table string) int {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer func() { _ = db.Close() }()
+
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		return -1 // table missing
+	}
+	return n
+}
+
+func TestSeparateResultsDatabase(t *testing.T) {
+	dir := t.TempDir()
+	queuePath := filepath.Join(dir, "queue.db")
+	resultsPath := filepath.Join(dir, "results-1.db")
+
+	store, err := NewSQLiteStorageWithResults(queuePath, resultsPath, "")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	if err := store.AddToQueue([]string{"https://example.com/"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	item, err := store.GetNextFromQueue()
+	if err != nil || item == nil {
+		t.Fatalf("Failed to dequeue: %v", err)
+	}
+	page := &crawler.PageData{
+		URL:         item.URL,
+		StatusCode:  200,
+		HTTPHeaders: map[string]string{},
+		CrawledAt:   time.Now(),
+		Headings:    []crawler.Heading{{Level: 1, Text: "Home"}},
+	}
+	if err := store.SavePageResult(item.ID, page); err != nil {
+		t.Fatalf("Failed to save page: %v", err)
+	}
+	link := &crawler.LinkData{
+		SourceURL: "https://example.com/",
+		TargetURL: "https://example.com/about",
+		LinkType:  "internal",
+		CrawledAt: time.Now(),
+	}
+	if err := store.SaveLink(link); err != nil {
+		t.Fatalf("Failed to save link: %v", err)
+	}
+
+	// Views joining both files work through the attached database
+	var source, target string
+	if err := store.db.QueryRow("SELECT source_url, target_url FROM links").Scan(&source, &target); err != nil {
+		t.Fatalf("Failed to query links view: %v", err)
+	}
+	if source != link.SourceURL || target != link.TargetURL {
+		t.Errorf("Unexpected link %s -> %s", source, target)
+	}
+	_ = store.Close()
+
+	if n := countRows(t, queuePath, "link_relations"); n != -1 {
+		t.Errorf("Expected no link_relations table in the queue file, got %d rows", n)
+	}
+	if n := countRows(t, resultsPath, "link_relations"); n != 1 {
+		t.Errorf("Expected 1 link in the results file, got %d", n)
+	}
+	if n := countRows(t, resultsPath, "page_headings"); n != 1 {
+		t.Errorf("Expected 1 heading in the results file, got %d", n)
+	}
+
+	// The queue file cannot be opened without a results file
+	if _, err := NewSQLiteStorage(queuePath); !errors.Is(err, ErrResultsDatabaseRequired) {
+		t.Errorf("Expected ErrResultsDatabaseRequired, got %v", err)
+	}
+
+	// Rotating to a new results file keeps the queue
+	rotated := filepath.Join(dir, "results-2.db")
+	store, err = NewSQLiteStorageWithResults(queuePath, rotated, "")
+	if err != nil {
+		t.Fatalf("Failed to reopen with a new results file: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if status, exists := store.GetURLStatus("https://example.com/about"); !exists || status != "discovered" {
+		t.Errorf("Expected the discovered page to survive rotation, got %q (exists %t)", status, exists)
+	}
+	if got := store.ResultsDatabasePath(); got != rotated {
+		t.Errorf("Expected results path %s, got %s", rotated, got)
+	}
+	if value, _ := store.GetMeta(metaResultsDatabase); value != rotated {
+		t.Errorf("Expected results_database meta %s, got %q", rotated, value)
+	}
+	if n := countRows(t, rotated, "link_relations"); n != 0 {
+		t.Errorf("Expected an empty link_relations table in the new results file, got %d", n)
+	}
+}
+
+func TestSeparateResultsDatabaseRejectsSingleFile(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "single.db")
+
+	store, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.Close()
+
+	_, err = NewSQLiteStorageWithResults(dbPath, filepath.Join(dir, "results.db"), "")
+	if !errors.Is(err, ErrResultsDatabaseUnsupported) {
+		t.Errorf("Expected ErrResultsDatabaseUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestPageIndexability(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "robots.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	pages := map[string]*crawler.PageData{
+		"https://example.com/":        {},
+		"https://example.com/meta":    {MetaRobots: "NoIndex, follow"},
+		"https://example.com/header":  {XRobotsTag: "nofollow"},
+		"https://example.com/none":    {XRobotsTag: "noarchive, none"},
+		"https://example.com/snippet": {XRobotsTag: "max-snippet: 20"},
+	}
+	urls := make([]string, 0, len(pages))
+	for url := range pages {
+		urls = append(urls, url)
+	}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range pages {
+		item, err := store.GetNextFromQueue()
+		if err != nil || item == nil {
+			t.Fatalf("Failed to dequeue: %v", err)
+		}
+		page := pages[item.URL]
+		page.URL, page.StatusCode, page.HTTPHeaders, page.CrawledAt = item.URL, 200, map[string]string{}, time.Now()
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	expected := map[string][2]bool{
+		"https://example.com/":        {false, false},
+		"https://example.com/meta":    {true, false},
+		"https://example.com/header":  {false, true},
+		"https://example.com/none":    {true, true},
+		"https://example.com/snippet": {false, false},
+	}
+	rows, err := store.db.Query("SELECT url, noindex, nofollow FROM page_indexability")
+	if err != nil {
+		t.Fatalf("Failed to query page_indexability: %v", err)
+	}
+	defer func() { _ = rows.Close() }()
+	seen := 0
+	for rows.Next() {
+		var url string
+		var noindex, nofollow bool
+		if err := rows.Scan(&url, &noindex, &nofollow); err != nil {
+			t.Fatalf("Failed to scan: %v", err)
+		}
+		seen++
+		if want := expected[url]; want != [2]bool{noindex, nofollow} {
+			t.Errorf("%s: expected noindex/nofollow %v, got %v", url, want, [2]bool{noindex, nofollow})
+		}
+	}
+	if seen != len(expected) {
+		t.Errorf("Expected %d rows, got %d", len(expected), seen)
+	}
+}
+
+func TestMigratePagesAddXRobotsTag(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// Remove the column as it was before X-Robots-Tag headers were recorded
+	_, err = store.db.Exec(`
+		DROP VIEW page_indexability;
+		ALTER TABLE pages DROP COLUMN x_robots_tag;
+	`)
+	if err != nil {
+		t.Fatalf("Failed to build legacy table: %v", err)
+	}
+	if err := store.SetMeta(metaSchemaVersion, "13"); err != nil {
+		t.Fatalf("Failed to record legacy schema version: %v", err)
+	}
+
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if _, hasColumn, err := columnState(store.db, "pages", "x_robots_tag"); err != nil || !hasColumn {
+		t.Errorf("Expected pages.x_robots_tag after migration (%v)", err)
+	}
+	if _, err := store.db.Exec("SELECT * FROM page_indexability"); err != nil {
+		t.Errorf("Expected page_indexability view after migration: %v", err)
+	}
+}
@@ -1,6 +1,19 @@
 package storage
 
-const schemaSQL = `
+// The schema is split so the result tables can live in a separate database
+// file (see NewSQLiteStorageWithResults). queueSchemaSQL holds the pages table,
+// which doubles as the crawl queue, and crawl_meta; resultsSchemaSQL holds the
+// per-page result tables keyed by page ID; resultsViewsSQL joins the two. A
+// single-file database runs all three (schemaSQL).
+
+// pagesHostExpr derives the host[:port] of pages.url for the generated host
+// column; it is shared with the migration that adds the column
+const pagesHostExpr = `CASE WHEN instr(substr(url, instr(url, '://') + 3), '/') > 0
+        THEN substr(substr(url, instr(url, '://') + 3), 1, instr(substr(url, instr(url, '://') + 3), '/') - 1)
+        ELSE substr(url, instr(url, '://') + 3) END`
+
+// queueSchemaSQL creates the pages table, its views, crawl_meta and crawl_sessions
+const queueSchemaSQL = `
 -- Pages table now serves as both queue and results storage
 -- status column manages the lifecycle:
 --   discovered -> pending -> processing -> completed/skipped/error
@@ -16,17 +29,20 @@ CREATE TABLE IF NOT EXISTS pages (
     -- Queue-related fields
     added_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     processing_started_at DATETIME,
+    claimed_by TEXT, -- crawl_processes.id of the process that claimed the page
     
     -- Crawl result fields (NULL until crawled)
     status_code INTEGER,
     title TEXT,
     meta_description TEXT,
     meta_robots TEXT,
+    x_robots_tag TEXT,
     canonical_url TEXT,
     content_hash TEXT,
     ttfb_ms INTEGER,
     download_time_ms INTEGER,
     response_size_bytes INTEGER,
+    rendered INTEGER NOT NULL DEFAULT 0, -- 1 when parsed from the DOM a headless browser rendered
     
     -- HTTP headers stored as JSON with generated columns for common headers
     response_http_headers JSON,
@@ -48,6 +64,8 @@ CREATE TABLE IF NOT EXISTS pages (
     server TEXT GENERATED ALWAYS AS (json_extract(response_http_headers, '$.server')) STORED,
     content_encoding TEXT GENERATED ALWAYS AS (json_extract(response_http_headers, '$.content-encoding')) STORED,
     x_cache TEXT GENERATED ALWAYS AS (json_extract(response_http_headers, '$.x-cache')) STORED,
+    -- host[:port] of the URL, for the host frontier (see pagesHostExpr)
+    host TEXT GENERATED ALWAYS AS (` + pagesHostExpr + `) VIRTUAL,
     
     crawled_at DATETIME,
     
@@ -63,6 +81,64 @@ CREATE INDEX IF NOT EXISTS idx_pages_status_added ON pages(status, added_at);
 CREATE INDEX IF NOT EXISTS idx_pages_url ON pages(url);
 CREATE INDEX IF NOT EXISTS idx_pages_content_hash ON pages(content_hash) WHERE content_hash IS NOT NULL;
 CREATE INDEX IF NOT EXISTS idx_pages_status_code ON pages(status_code) WHERE status = 'completed';
+CREATE INDEX IF NOT EXISTS idx_pages_status_host ON pages(status, host, added_at);
+
+-- Host rotation state for queue_order: host. GetNextFromQueue claims from
+-- the pending host with the fewest pages in flight, then the one claimed
+-- longest ago, so a single host cannot monopolize the workers. The page
+-- counts are kept up to date by the triggers below, so choosing a host reads
+-- idx_host_frontier_next instead of every pending page.
+CREATE TABLE IF NOT EXISTS host_frontier (
+    host TEXT PRIMARY KEY,
+    last_claimed_at INTEGER NOT NULL, -- Unix nanoseconds of the host's latest claim (0 = never)
+    pending INTEGER NOT NULL DEFAULT 0, -- Pages of the host with status 'pending'
+    inflight INTEGER NOT NULL DEFAULT 0 -- Pages of the host with status 'processing'
+);
+CREATE INDEX IF NOT EXISTS idx_host_frontier_next ON host_frontier(inflight, last_claimed_at) WHERE pending > 0;
+
+CREATE TRIGGER IF NOT EXISTS pages_frontier_insert AFTER INSERT ON pages
+WHEN NEW.status IN ('pending', 'processing')
+BEGIN
+    INSERT INTO host_frontier (host, last_claimed_at, pending, inflight)
+    VALUES (NEW.host, 0, NEW.status = 'pending', NEW.status = 'processing')
+    ON CONFLICT(host) DO UPDATE SET
+        pending = pending + (NEW.status = 'pending'),
+        inflight = inflight + (NEW.status = 'processing');
+END;
+
+CREATE TRIGGER IF NOT EXISTS pages_frontier_update AFTER UPDATE OF status, url ON pages
+WHEN (OLD.status IS NOT NEW.status OR OLD.url IS NOT NEW.url)
+    AND (OLD.status IN ('pending', 'processing') OR NEW.status IN ('pending', 'processing'))
+BEGIN
+    UPDATE host_frontier SET
+        pending = pending - (OLD.status = 'pending'),
+        inflight = inflight - (OLD.status = 'processing')
+    WHERE host = OLD.host AND OLD.status IN ('pending', 'processing');
+    INSERT INTO host_frontier (host, last_claimed_at, pending, inflight)
+    SELECT NEW.host, 0, NEW.status = 'pending', NEW.status = 'processing'
+    WHERE NEW.status IN ('pending', 'processing')
+    ON CONFLICT(host) DO UPDATE SET
+        pending = pending + (NEW.status = 'pending'),
+        inflight = inflight + (NEW.status = 'processing');
+END;
+
+CREATE TRIGGER IF NOT EXISTS pages_frontier_delete AFTER DELETE ON pages
+WHEN OLD.status IN ('pending', 'processing')
+BEGIN
+    UPDATE host_frontier SET
+        pending = pending - (OLD.status = 'pending'),
+        inflight = inflight - (OLD.status = 'processing')
+    WHERE host = OLD.host;
+END;
+
+-- Crawl processes claiming from this database. Each refreshes heartbeat_at
+-- while it runs and deletes its row when it closes the database; pages
+-- claimed by a process with a recent heartbeat are never requeued as stale.
+CREATE TABLE IF NOT EXISTS crawl_processes (
+    id TEXT PRIMARY KEY, -- hostname/pid/start time in Unix nanoseconds
+    started_at DATETIME NOT NULL,
+    heartbeat_at DATETIME NOT NULL
+);
 
 -- Indexes for generated columns from JSON headers
 CREATE INDEX IF NOT EXISTS idx_pages_content_type ON pages(content_type) WHERE content_type IS NOT NULL;
@@ -80,6 +156,43 @@ SELECT
 FROM pages
 WHERE status = 'completed';
 
+-- Non-HTML responses hashed with hash_assets, for duplicate-asset detection
+-- and change tracking of downloads
+CREATE VIEW IF NOT EXISTS assets AS
+SELECT
+    url, content_type, content_hash, response_size_bytes, last_modified, crawled_at
+FROM pages
+WHERE status = 'completed'
+  AND content_hash IS NOT NULL
+  AND COALESCE(content_type, '') NOT LIKE 'text/html%'
+  AND COALESCE(content_type, '') NOT LIKE 'application/xhtml+xml%';
+
+-- Robots directives of crawled pages from <meta name="robots"> and the
+-- X-Robots-Tag header; noindex and nofollow are set when either source
+-- declares them (directly or through "none")
+CREATE VIEW IF NOT EXISTS page_indexability AS
+SELECT
+    url, status_code, meta_robots, x_robots_tag,
+    (directives LIKE '%,noindex,%' OR directives LIKE '%,none,%') AS noindex,
+    (directives LIKE '%,nofollow,%' OR directives LIKE '%,none,%') AS nofollow
+FROM (
+    SELECT url, status_code, meta_robots, x_robots_tag,
+        ',' || replace(lower(COALESCE(meta_robots, '') || ',' || COALESCE(x_robots_tag, '')), ' ', '') || ',' AS directives
+    FROM pages
+    WHERE status = 'completed'
+);
+
+-- Crawled pages that declare a canonical URL other than their own, with the
+-- crawl result of the canonical target (NULL when it was never queued)
+CREATE VIEW IF NOT EXISTS canonical_pages AS
+SELECT
+    p.url, p.canonical_url, c.status AS canonical_status, c.status_code AS canonical_status_code
+FROM pages p
+LEFT JOIN pages c ON c.url = p.canonical_url
+WHERE p.status = 'completed'
+  AND p.canonical_url IS NOT NULL AND p.canonical_url != ''
+  AND p.canonical_url != p.url;
+
 -- View for queue management
 CREATE VIEW IF NOT EXISTS queue_status AS
 SELECT 
@@ -90,6 +203,62 @@ SELECT
 FROM pages
 GROUP BY status;
 
+-- Ready-made aggregates for BI tools connecting to the database.
+-- Pages per host (scheme and path stripped, port kept); 'discovered' link
+-- targets that were never queued are excluded, as in analyze hosts.
+CREATE VIEW IF NOT EXISTS host_summary AS
+SELECT
+    host,
+    COUNT(*) AS pages,
+    SUM(status = 'completed') AS completed,
+    SUM(status = 'error') AS errors,
+    SUM(status = 'skipped') AS skipped,
+    SUM(status IN ('pending', 'processing')) AS pending,
+    ROUND(AVG(CASE WHEN status = 'completed' THEN ttfb_ms END), 1) AS avg_ttfb_ms,
+    SUM(CASE WHEN status = 'completed' THEN COALESCE(response_size_bytes, 0) ELSE 0 END) AS bytes
+FROM (
+    SELECT
+        CASE WHEN instr(rest, '/') > 0 THEN substr(rest, 1, instr(rest, '/') - 1) ELSE rest END AS host,
+        status, ttfb_ms, response_size_bytes
+    FROM (SELECT substr(url, instr(url, '://') + 3) AS rest, status, ttfb_ms, response_size_bytes
+          FROM pages WHERE status != 'discovered')
+)
+GROUP BY host;
+
+-- Crawled pages per HTTP status code; status_class is '2xx', '3xx', ...
+CREATE VIEW IF NOT EXISTS status_summary AS
+SELECT
+    status_code,
+    (status_code / 100) || 'xx' AS status_class,
+    COUNT(*) AS pages
+FROM pages
+WHERE status_code IS NOT NULL
+GROUP BY status_code;
+
+-- Crawl meta table stores metadata as key-value pairs
+CREATE TABLE IF NOT EXISTS crawl_meta (
+    key TEXT PRIMARY KEY NOT NULL,
+    value TEXT NOT NULL
+);
+
+-- Crawl sessions: one row per crawl run on this database, recorded when the
+-- run ends with the totals of the database at that point, so recurring crawls
+-- can be compared over time (linktadoru trends)
+CREATE TABLE IF NOT EXISTS crawl_sessions (
+    id TEXT PRIMARY KEY, -- Session ID of the run manifest
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME NOT NULL,
+    stop_reason TEXT NOT NULL,
+    pages_crawled INTEGER NOT NULL, -- Pages fetched by this run
+    pages INTEGER NOT NULL,         -- Completed pages in the database
+    error_pages INTEGER NOT NULL,   -- Pages that could not be fetched or answered 4xx/5xx
+    avg_ttfb_ms REAL NOT NULL,
+    broken_links INTEGER NOT NULL
+);
+`
+
+// resultsSchemaSQL creates the per-page result tables
+const resultsSchemaSQL = `
 -- Link relationships table stores normalized link data using page IDs
 -- NOTE: UNIQUE constraint on (source_page_id, target_page_id) ensures no duplicate relationships.
 -- If the same link is found multiple times with different anchor_text or rel_attribute,
@@ -112,6 +281,212 @@ CREATE INDEX IF NOT EXISTS idx_link_relations_source ON link_relations(source_pa
 CREATE INDEX IF NOT EXISTS idx_link_relations_target ON link_relations(target_page_id);
 CREATE INDEX IF NOT EXISTS idx_link_relations_type ON link_relations(link_type);
 
+-- Alternate representations declared by <link rel="alternate"> elements.
+-- kind is one of 'feed', 'print', 'mobile', 'language' or 'other'; source is
+-- 'html' (<link> element) or 'header' (HTTP Link header). A page's rows are
+-- replaced each time the page is crawled.
+CREATE TABLE IF NOT EXISTS page_alternates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    page_id INTEGER NOT NULL,
+    href TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    type TEXT,
+    media TEXT,
+    hreflang TEXT,
+    title TEXT,
+    source TEXT NOT NULL DEFAULT 'html',
+    FOREIGN KEY (page_id) REFERENCES pages(id),
+    UNIQUE(page_id, href)
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_alternates_page ON page_alternates(page_id);
+CREATE INDEX IF NOT EXISTS idx_page_alternates_kind ON page_alternates(kind);
+
+-- canonical, next and prev relations declared by a page. source is 'html'
+-- (<link> element) or 'header' (HTTP Link header); when both declare a
+-- canonical, pages.canonical_url holds the HTML one. A page's rows are
+-- replaced each time the page is crawled.
+CREATE TABLE IF NOT EXISTS page_rels (
+    page_id INTEGER NOT NULL,
+    rel TEXT NOT NULL CHECK (rel IN ('canonical', 'next', 'prev')),
+    href TEXT NOT NULL,
+    source TEXT NOT NULL CHECK (source IN ('html', 'header')),
+    FOREIGN KEY (page_id) REFERENCES pages(id),
+    UNIQUE(page_id, rel, href, source)
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_rels_page ON page_rels(page_id);
+
+-- Main readable text statistics per HTML page, for thin-content detection.
+-- Navigation, headers, footers and scripts are excluded from the text;
+-- text_ratio is the text size divided by the HTML size (0-1); simhash is the
+-- 64-bit SimHash of the text's word shingles (NULL without words), stored as
+-- a signed integer, for near-duplicate detection. language is the ISO 639-1
+-- code of the text's detected language and html_lang the declared <html lang>
+-- (NULL when undetermined or absent).
+CREATE TABLE IF NOT EXISTS page_content (
+    page_id INTEGER PRIMARY KEY,
+    word_count INTEGER NOT NULL,
+    text_ratio REAL NOT NULL,
+    simhash INTEGER,
+    language TEXT,
+    html_lang TEXT,
+    FOREIGN KEY (page_id) REFERENCES pages(id)
+);
+
+-- <h1>-<h6> elements per page in document order (position starts at 0);
+-- a page's rows are replaced each time the page is crawled
+CREATE TABLE IF NOT EXISTS page_headings (
+    page_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    level INTEGER NOT NULL CHECK (level BETWEEN 1 AND 6),
+    text TEXT,
+    FOREIGN KEY (page_id) REFERENCES pages(id),
+    PRIMARY KEY (page_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_headings_level ON page_headings(level);
+
+-- Structured data per page in document order (position starts at 0): JSON-LD
+-- <script> blocks with their raw payload, and top-level microdata items
+-- (payload NULL). valid is 0 for a JSON-LD payload that is not valid JSON.
+CREATE TABLE IF NOT EXISTS page_structured_data (
+    page_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    format TEXT NOT NULL CHECK (format IN ('json-ld', 'microdata')),
+    payload TEXT,
+    valid INTEGER NOT NULL DEFAULT 1,
+    FOREIGN KEY (page_id) REFERENCES pages(id),
+    PRIMARY KEY (page_id, position)
+);
+
+-- Types declared by each structured data item, schema.org prefix removed
+-- (e.g. 'Product', 'BreadcrumbList')
+CREATE TABLE IF NOT EXISTS page_schema_types (
+    page_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    schema_type TEXT NOT NULL,
+    FOREIGN KEY (page_id) REFERENCES pages(id),
+    PRIMARY KEY (page_id, position, schema_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_schema_types_type ON page_schema_types(schema_type);
+
+-- Fields extracted from a page by each registered content extractor, as a
+-- JSON object; error holds the message of an extractor that failed (data
+-- NULL). A page's rows are replaced each time the page is crawled.
+CREATE TABLE IF NOT EXISTS page_extractions (
+    page_id INTEGER NOT NULL,
+    extractor TEXT NOT NULL,
+    data TEXT,
+    error TEXT,
+    FOREIGN KEY (page_id) REFERENCES pages(id),
+    PRIMARY KEY (page_id, extractor)
+);
+
+-- <img> elements per page in document order (position starts at 0). alt is
+-- NULL when the attribute is absent and '' when it is empty; width and height
+-- are the declared pixel sizes, NULL when not declared.
+CREATE TABLE IF NOT EXISTS images (
+    page_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    src TEXT NOT NULL,
+    alt TEXT,
+    width INTEGER,
+    height INTEGER,
+    loading TEXT,
+    FOREIGN KEY (page_id) REFERENCES pages(id),
+    PRIMARY KEY (page_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_images_src ON images(src);
+
+-- Accessibility issues found in the static HTML of a page with
+-- accessibility_checks on, in document order (position starts at 0):
+-- missing_alt, missing_label, empty_link, empty_button, missing_lang or
+-- heading_jump; element describes the offending element. A page's rows are
+-- replaced each time the page is crawled.
+CREATE TABLE IF NOT EXISTS page_accessibility (
+    page_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    issue TEXT NOT NULL,
+    element TEXT,
+    FOREIGN KEY (page_id) REFERENCES pages(id),
+    PRIMARY KEY (page_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_accessibility_issue ON page_accessibility(issue);
+
+-- Redirects followed when a page was fetched, in order (hop starts at 0):
+-- from_url answered with the 3xx status_code, pointing at to_url. Fetching
+-- stops at a loop, when a hop would repeat; the chain then ends with a
+-- redirect back to an earlier URL. A page's rows are replaced each time the
+-- page is crawled.
+CREATE TABLE IF NOT EXISTS redirects (
+    page_id INTEGER NOT NULL,
+    hop INTEGER NOT NULL,
+    status_code INTEGER NOT NULL,
+    from_url TEXT NOT NULL,
+    to_url TEXT NOT NULL,
+    FOREIGN KEY (page_id) REFERENCES pages(id),
+    PRIMARY KEY (page_id, hop)
+);
+
+CREATE INDEX IF NOT EXISTS idx_redirects_to_url ON redirects(to_url);
+
+-- Leaf certificate each HTTPS host presented, as of the last page fetched
+-- from it: subject and issuer are distinguished names, sans the
+-- space-separated subject alternative DNS names and fingerprint the SHA-256
+-- of the certificate. Times are UTC.
+CREATE TABLE IF NOT EXISTS tls_certificates (
+    host TEXT PRIMARY KEY,
+    subject TEXT,
+    issuer TEXT,
+    sans TEXT,
+    not_before DATETIME,
+    not_after DATETIME NOT NULL,
+    fingerprint TEXT NOT NULL,
+    seen_at DATETIME NOT NULL
+);
+
+-- Results of verifying out-of-scope links without crawling them
+-- (check_external: head). The checked page keeps its 'discovered' status;
+-- method is HEAD, or GET when the server rejected HEAD and a ranged GET was used.
+CREATE TABLE IF NOT EXISTS external_checks (
+    page_id INTEGER PRIMARY KEY,
+    method TEXT NOT NULL,
+    status_code INTEGER,
+    error_message TEXT,
+    checked_at DATETIME,
+    FOREIGN KEY (page_id) REFERENCES pages(id)
+);
+
+-- Link graph metrics per page, computed after a crawl by the analyze
+-- commands and NULL until computed (see ComputePageRank, ComputeClickDepths).
+-- click_depth is -1 for pages no seed URL links to.
+CREATE TABLE IF NOT EXISTS page_metrics (
+    page_id INTEGER PRIMARY KEY,
+    pagerank REAL,
+    click_depth INTEGER,
+    FOREIGN KEY (page_id) REFERENCES pages(id)
+);
+
+-- Separate errors table for detailed error tracking
+CREATE TABLE IF NOT EXISTS crawl_errors (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    error_type TEXT NOT NULL,
+    error_message TEXT,
+    occurred_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_errors_url ON crawl_errors(url);
+CREATE INDEX IF NOT EXISTS idx_errors_type ON crawl_errors(error_type);
+CREATE INDEX IF NOT EXISTS idx_errors_occurred ON crawl_errors(occurred_at);
+`
+
+// resultsViewsSQL creates the views joining result tables with pages
+const resultsViewsSQL = `
 -- User-friendly view that presents links with URLs (maintains compatibility)
 CREATE VIEW IF NOT EXISTS links AS
 SELECT 
@@ -126,22 +501,89 @@ FROM link_relations lr
 JOIN pages p1 ON lr.source_page_id = p1.id
 JOIN pages p2 ON lr.target_page_id = p2.id;
 
--- Separate errors table for detailed error tracking
-CREATE TABLE IF NOT EXISTS crawl_errors (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    url TEXT NOT NULL,
-    error_type TEXT NOT NULL,
-    error_message TEXT,
-    occurred_at DATETIME DEFAULT CURRENT_TIMESTAMP
-);
+-- Pages exposing syndication feeds (for content-syndication audits)
+CREATE VIEW IF NOT EXISTS feed_pages AS
+SELECT
+    p.url AS page_url,
+    pa.href AS feed_url,
+    pa.type AS feed_type,
+    pa.title AS feed_title
+FROM page_alternates pa
+JOIN pages p ON pa.page_id = p.id
+WHERE pa.kind = 'feed';
 
-CREATE INDEX IF NOT EXISTS idx_errors_url ON crawl_errors(url);
-CREATE INDEX IF NOT EXISTS idx_errors_type ON crawl_errors(error_type);
-CREATE INDEX IF NOT EXISTS idx_errors_occurred ON crawl_errors(occurred_at);
+-- HTML pages without exactly one <h1>; issue is 'missing_h1' or 'multiple_h1'.
+-- page_content marks the pages that were parsed as HTML.
+CREATE VIEW IF NOT EXISTS heading_issues AS
+SELECT
+    p.url,
+    COUNT(ph.page_id) AS h1_count,
+    CASE WHEN COUNT(ph.page_id) = 0 THEN 'missing_h1' ELSE 'multiple_h1' END AS issue
+FROM page_content pc
+JOIN pages p ON pc.page_id = p.id
+LEFT JOIN page_headings ph ON ph.page_id = pc.page_id AND ph.level = 1
+GROUP BY p.id
+HAVING COUNT(ph.page_id) != 1;
 
--- Crawl meta table stores metadata as key-value pairs
-CREATE TABLE IF NOT EXISTS crawl_meta (
-    key TEXT PRIMARY KEY NOT NULL,
-    value TEXT NOT NULL
-);
+-- Crawl errors per type: occurrences (retries included) and distinct URLs
+CREATE VIEW IF NOT EXISTS errors_by_type AS
+SELECT
+    error_type,
+    COUNT(*) AS occurrences,
+    COUNT(DISTINCT url) AS urls,
+    MIN(occurred_at) AS first_seen,
+    MAX(occurred_at) AS last_seen
+FROM crawl_errors
+GROUP BY error_type;
+
+-- Pages crawled, errors and bytes downloaded per day (UTC date)
+CREATE VIEW IF NOT EXISTS daily_crawl_counts AS
+SELECT day, SUM(pages) AS pages, SUM(errors) AS errors, SUM(bytes) AS bytes
+FROM (
+    SELECT substr(crawled_at, 1, 10) AS day, COUNT(*) AS pages, 0 AS errors,
+           SUM(COALESCE(response_size_bytes, 0)) AS bytes
+    FROM pages
+    WHERE status = 'completed' AND crawled_at IS NOT NULL
+    GROUP BY day
+    UNION ALL
+    SELECT substr(occurred_at, 1, 10) AS day, 0, COUNT(*), 0
+    FROM crawl_errors
+    GROUP BY day
+)
+GROUP BY day;
+
+-- Static resources loaded by each page (crawl_assets) with their fetch
+-- result; kind is 'stylesheet', 'script', 'image' or 'icon'. status is
+-- 'discovered' for assets that were out of scope and never fetched.
+CREATE VIEW IF NOT EXISTS page_assets AS
+SELECT
+    p1.url AS page_url,
+    p2.url AS asset_url,
+    lr.rel_attribute AS kind,
+    p2.status,
+    p2.status_code,
+    p2.content_type,
+    p2.response_size_bytes
+FROM link_relations lr
+JOIN pages p1 ON lr.source_page_id = p1.id
+JOIN pages p2 ON lr.target_page_id = p2.id
+WHERE lr.link_type = 'asset';
+
+-- Outbound links with the status of their verified target
+CREATE VIEW IF NOT EXISTS external_link_status AS
+SELECT
+    p1.url AS source_url,
+    p2.url AS target_url,
+    lr.anchor_text,
+    ec.status_code,
+    ec.method,
+    ec.error_message,
+    ec.checked_at
+FROM link_relations lr
+JOIN pages p1 ON lr.source_page_id = p1.id
+JOIN pages p2 ON lr.target_page_id = p2.id
+JOIN external_checks ec ON ec.page_id = p2.id;
 `
+
+// schemaSQL is the complete schema of a single-file database
+const schemaSQL = queueSchemaSQL + resultsSchemaSQL + resultsViewsSQL
@@ -0,0 +1,85 @@
+// Package storage — crawl session history.
+//
+// Every crawl run on a database ends by recording a crawl_sessions row with
+// the totals of the database at that point: completed pages, error pages,
+// the average TTFB and the number of broken links. A site crawled on a
+// schedule, into the same database or a new one each time, thereby becomes a
+// monitoring dataset that `linktadoru trends` lists over time.
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// CrawlSession is the recorded totals of one crawl run
+type CrawlSession struct {
+	ID           string    `json:"session_id"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+	StopReason   string    `json:"stop_reason"`
+	PagesCrawled int       `json:"pages_crawled"` // Pages fetched by this run
+	Pages        int       `json:"pages"`         // Completed pages in the database
+	ErrorPages   int       `json:"error_pages"`   // Pages that could not be fetched or answered 4xx/5xx
+	AvgTTFBMs    float64   `json:"avg_ttfb_ms"`
+	BrokenLinks  int       `json:"broken_links"`
+}
+
+// RecordCrawlSession fills in the database totals of session and stores it,
+// replacing an earlier record with the same ID
+func (s *SQLiteStorage) RecordCrawlSession(session CrawlSession) (*CrawlSession, error) {
+	err := s.db.QueryRow(`
+		SELECT
+			COALESCE(SUM(status = 'completed'), 0),
+			COALESCE(SUM(status = 'error' OR (status = 'completed' AND status_code >= 400)), 0),
+			COALESCE(ROUND(AVG(CASE WHEN status = 'completed' THEN ttfb_ms END), 1), 0)
+		FROM pages
+	`).Scan(&session.Pages, &session.ErrorPages, &session.AvgTTFBMs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pages: %w", err)
+	}
+	err = s.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM link_relations lr
+		JOIN pages p2 ON lr.target_page_id = p2.id
+		LEFT JOIN external_checks ec ON ec.page_id = p2.id
+		WHERE ` + brokenLinkCondition).Scan(&session.BrokenLinks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count broken links: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT OR REPLACE INTO crawl_sessions
+			(id, started_at, finished_at, stop_reason, pages_crawled, pages, error_pages, avg_ttfb_ms, broken_links)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, session.ID, session.StartedAt.UTC(), session.FinishedAt.UTC(), session.StopReason, session.PagesCrawled,
+		session.Pages, session.ErrorPages, session.AvgTTFBMs, session.BrokenLinks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record crawl session: %w", err)
+	}
+	return &session, nil
+}
+
+// GetCrawlSessions returns the recorded crawl sessions, oldest first
+func (s *SQLiteStorage) GetCrawlSessions() ([]CrawlSession, error) {
+	rows, err := s.read.Query(`
+		SELECT id, started_at, finished_at, stop_reason, pages_crawled, pages, error_pages, avg_ttfb_ms, broken_links
+		FROM crawl_sessions
+		ORDER BY started_at, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query crawl sessions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var sessions []CrawlSession
+	for rows.Next() {
+		var session CrawlSession
+		if err := rows.Scan(&session.ID, &session.StartedAt, &session.FinishedAt, &session.StopReason, &session.PagesCrawled,
+			&session.Pages, &session.ErrorPages, &session.AvgTTFBMs, &session.BrokenLinks); err != nil {
+			return nil, fmt.Errorf("failed to scan crawl session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
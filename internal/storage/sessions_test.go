@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestRecordCrawlSession(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "crawl.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	urls := []string{"https://example.com/", "https://example.com/a", "https://example.com/gone", "https://example.com/down"}
+	_ = store.AddToQueue(urls)
+	pages := map[string]*crawler.PageData{
+		urls[0]: {StatusCode: 200, TTFB: 100 * time.Millisecond},
+		urls[1]: {StatusCode: 200, TTFB: 300 * time.Millisecond},
+		urls[2]: {StatusCode: 404, TTFB: 200 * time.Millisecond},
+	}
+	for range urls {
+		item, _ := store.GetNextFromQueue()
+		if page, ok := pages[item.URL]; ok {
+			page.URL, page.HTTPHeaders, page.CrawledAt = item.URL, map[string]string{}, time.Now()
+			_ = store.SavePageResult(item.ID, page)
+		} else {
+			_ = store.SavePageError(item.ID, "network_timeout", "timeout")
+		}
+	}
+	_ = store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: urls[0], TargetURL: urls[1], LinkType: "internal"},
+		{SourceURL: urls[0], TargetURL: urls[2], LinkType: "internal"},
+		{SourceURL: urls[1], TargetURL: urls[3], LinkType: "internal"},
+	})
+
+	startedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := CrawlSession{ID: "first", StartedAt: startedAt, FinishedAt: startedAt.Add(time.Minute), StopReason: "completed", PagesCrawled: 4}
+	recorded, err := store.RecordCrawlSession(first)
+	if err != nil {
+		t.Fatalf("RecordCrawlSession failed: %v", err)
+	}
+	if recorded.Pages != 3 || recorded.ErrorPages != 2 || recorded.AvgTTFBMs != 200 || recorded.BrokenLinks != 2 {
+		t.Errorf("Unexpected totals: %+v", recorded)
+	}
+
+	// Sessions are listed oldest first, whatever order they were recorded in
+	earlier := CrawlSession{ID: "earlier", StartedAt: startedAt.Add(-time.Hour), FinishedAt: startedAt, StopReason: "limit"}
+	if _, err := store.RecordCrawlSession(earlier); err != nil {
+		t.Fatalf("RecordCrawlSession failed: %v", err)
+	}
+	sessions, err := store.GetCrawlSessions()
+	if err != nil {
+		t.Fatalf("GetCrawlSessions failed: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != "earlier" || sessions[1].ID != "first" {
+		t.Fatalf("Unexpected sessions: %+v", sessions)
+	}
+	if got := sessions[1]; !got.StartedAt.Equal(first.StartedAt) || got.PagesCrawled != 4 || got.BrokenLinks != 2 || got.StopReason != "completed" {
+		t.Errorf("Unexpected session read back: %+v", got)
+	}
+}
@@ -3,9 +3,13 @@
 package storage
 
 import (
+	"crypto/cipher"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/masahif/linktadoru/internal/crawler"
@@ -15,37 +19,143 @@ import (
 
 // SQLiteStorage implements the Storage interface using SQLite
 type SQLiteStorage struct {
-	db *sql.DB
+	db          *sql.DB       // Write pool; also serves reads that must run inside a write
+	read        *sql.DB       // Query-only pool for status queries; db itself for in-memory databases
+	aead        cipher.AEAD   // Column cipher; nil when the database is not encrypted
+	resultsPath string        // Attached results database (see results.go); "" for a single file
+	rotateHosts bool          // Dequeue across hosts instead of in plain FIFO order (SetHostRotation)
+	process     *crawlProcess // This process's claims and heartbeat (see processes.go)
+	migrated    []string      // Migration steps applied when the database was opened (AppliedMigrations)
+	readOnly    bool          // Opened with OpenSQLiteStorageReadOnly; nothing is written
 }
 
 // NewSQLiteStorage creates a new SQLite storage instance
 func NewSQLiteStorage(dbPath string) (*SQLiteStorage, error) {
-	db, err := sql.Open("sqlite", dbPath)
+	return NewSQLiteStorageWithPassphrase(dbPath, "")
+}
+
+// NewSQLiteStorageWithPassphrase creates a new SQLite storage instance that
+// encrypts sensitive columns with a key derived from passphrase (see
+// encryption.go). An empty passphrase opens an unencrypted database and fails
+// with ErrPassphraseRequired if the database is encrypted.
+func NewSQLiteStorageWithPassphrase(dbPath, passphrase string) (*SQLiteStorage, error) {
+	return NewSQLiteStorageWithResults(dbPath, "", passphrase)
+}
+
+// NewSQLiteStorageWithResults is NewSQLiteStorageWithPassphrase with the result
+// tables kept in a separate database file at resultsPath (see results.go). An
+// empty resultsPath stores everything in dbPath.
+//
+// An existing database written with an older schema is refused with
+// ErrSchemaOutdated; MigrateSQLiteStorage upgrades it.
+func NewSQLiteStorageWithResults(dbPath, resultsPath, passphrase string) (*SQLiteStorage, error) {
+	return openSQLiteStorage(dbPath, resultsPath, passphrase, false)
+}
+
+// MigrateSQLiteStorage is NewSQLiteStorageWithResults, first upgrading a
+// database written with an older schema (see Migrate). AppliedMigrations
+// lists the steps applied. It is used by the commands meant to change a
+// database's schema: crawl and `db migrate`.
+func MigrateSQLiteStorage(dbPath, resultsPath, passphrase string) (*SQLiteStorage, error) {
+	return openSQLiteStorage(dbPath, resultsPath, passphrase, true)
+}
+
+// openSQLiteStorage opens a database for writing, migrating an older schema
+// when migrate is set
+func openSQLiteStorage(dbPath, resultsPath, passphrase string, migrate bool) (*SQLiteStorage, error) {
+	db, err := openPool(dbPath, resultsPath, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// Configure connection pool - single connection prevents lock conflicts
-	db.SetMaxOpenConns(1)
-	db.SetMaxIdleConns(1)
+	// Every connection of an in-memory database is a separate database, so
+	// those keep a single connection for both reads and writes
+	memory := isMemoryDatabase(dbPath)
+	writeConns := maxWriteConns
+	if memory {
+		writeConns = 1
+	}
+	db.SetMaxOpenConns(writeConns)
+	db.SetMaxIdleConns(writeConns)
 	db.SetConnMaxLifetime(30 * time.Minute)
 
-	storage := &SQLiteStorage{db: db}
+	storage := &SQLiteStorage{db: db, read: db, resultsPath: resultsPath, process: newCrawlProcess()}
 
 	// Initialize schema
-	if err := storage.InitSchema(); err != nil {
+	if err := storage.initSchema(migrate); err != nil {
 		_ = db.Close()
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
 
+	if err := storage.initEncryption(passphrase); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize encryption: %w", err)
+	}
+
+	// The read pool is opened once the schema exists, since it cannot create it
+	if !memory {
+		read, err := openPool(dbPath, resultsPath, true)
+		if err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to open read connections: %w", err)
+		}
+		read.SetMaxOpenConns(maxReadConns)
+		read.SetMaxIdleConns(maxReadConns)
+		read.SetConnMaxLifetime(30 * time.Minute)
+		storage.read = read
+	}
+
 	return storage, nil
 }
 
-// InitSchema creates the database schema
+// OpenSQLiteStorageReadOnly opens an existing database for reading only, with
+// the result tables in resultsPath when it is set. It never creates, migrates
+// or records anything: a database written with an older schema is refused
+// with ErrSchemaOutdated, one written by a newer build with ErrSchemaTooNew.
+func OpenSQLiteStorageReadOnly(dbPath, resultsPath, passphrase string) (*SQLiteStorage, error) {
+	if isMemoryDatabase(dbPath) {
+		return nil, fmt.Errorf("an in-memory database cannot be opened read-only")
+	}
+	read, err := openPool(dbPath, resultsPath, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	read.SetMaxOpenConns(maxReadConns)
+	read.SetMaxIdleConns(maxReadConns)
+	read.SetConnMaxLifetime(30 * time.Minute)
+
+	storage := &SQLiteStorage{db: read, read: read, resultsPath: resultsPath, readOnly: true, process: newCrawlProcess()}
+	checks := []func() error{storage.checkSchemaVersion, storage.checkResultsLayout, storage.checkSchemaOutdated}
+	for _, check := range checks {
+		if err := check(); err != nil {
+			_ = read.Close()
+			return nil, err
+		}
+	}
+	if err := storage.initEncryption(passphrase); err != nil {
+		_ = read.Close()
+		return nil, fmt.Errorf("failed to initialize encryption: %w", err)
+	}
+	return storage, nil
+}
+
+// InitSchema creates the database schema. It fails with ErrSchemaOutdated on
+// an existing database written with an older schema, which Migrate upgrades.
 func (s *SQLiteStorage) InitSchema() error {
-	// Enable foreign keys and WAL mode for better concurrent access
+	return s.initSchema(false)
+}
+
+// initSchema creates the database schema, first migrating an older one when
+// migrate is set
+func (s *SQLiteStorage) initSchema(migrate bool) error {
+	// Enable foreign keys and WAL mode for better concurrent access. Foreign
+	// keys stay off when the results live in another file (see results.go).
+	foreignKeys := "PRAGMA foreign_keys = ON"
+	if s.resultsPath != "" {
+		foreignKeys = "PRAGMA foreign_keys = OFF"
+	}
 	pragmas := []string{
-		"PRAGMA foreign_keys = ON",
+		foreignKeys,
 		"PRAGMA journal_mode = WAL",
 		"PRAGMA synchronous = NORMAL",
 		"PRAGMA cache_size = -64000", // 64MB cache
@@ -60,23 +170,56 @@ func (s *SQLiteStorage) InitSchema() error {
 		}
 	}
 
-	// Migrate an existing pages table whose CHECK constraint predates the
-	// 'discovered' status (see migratePagesAddDiscovered). No-op on a fresh DB.
-	if err := s.migratePagesAddDiscovered(); err != nil {
-		return fmt.Errorf("failed to migrate pages table: %w", err)
+	// Refuse databases written by a newer, incompatible build before touching them
+	if err := s.checkSchemaVersion(); err != nil {
+		return err
+	}
+
+	if err := s.checkResultsLayout(); err != nil {
+		return err
+	}
+
+	// An older existing schema is upgraded only when asked to (see migrate.go)
+	check := s.checkSchemaOutdated
+	if migrate {
+		check = s.Migrate
+	}
+	if err := check(); err != nil {
+		return err
 	}
 
-	// Create schema (idempotent). After a migration this also recreates the
-	// indexes and views that the table rebuild dropped.
-	if _, err := s.db.Exec(schemaSQL); err != nil {
+	// Create schema (idempotent)
+	schema := schemaSQL
+	if s.resultsPath != "" {
+		schema = queueSchemaSQL
+	}
+	if _, err := s.db.Exec(schema); err != nil {
 		return fmt.Errorf("failed to create schema: %w", err)
 	}
+	if s.resultsPath != "" {
+		// Changing temp_store above dropped the TEMP views the connection was opened with
+		if _, err := s.db.Exec(resultsViewsTempSQL); err != nil {
+			return fmt.Errorf("failed to create result views: %w", err)
+		}
+		if err := s.SetMeta(metaResultsDatabase, s.resultsPath); err != nil {
+			return fmt.Errorf("failed to record results database: %w", err)
+		}
+	}
+
+	// The schema is now current; record it for future compatibility checks
+	if err := s.SetMeta(metaSchemaVersion, strconv.Itoa(SchemaVersion)); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
 
 	return nil
 }
 
 // Close closes the database connection
 func (s *SQLiteStorage) Close() error {
+	s.unregisterProcess()
+	if s.read != s.db {
+		_ = s.read.Close()
+	}
 	return s.db.Close()
 }
 
@@ -135,21 +278,37 @@ func (s *SQLiteStorage) AddToQueue(urls []string) error {
 	return tx.Commit()
 }
 
-// GetNextFromQueue atomically gets and marks the next URL for processing
+// SetHostRotation switches the queue between plain FIFO order (the default)
+// and the host frontier, which rotates claims across hosts (queue_order: host)
+func (s *SQLiteStorage) SetHostRotation(enabled bool) {
+	s.rotateHosts = enabled
+}
+
+// GetNextFromQueue atomically gets and marks the next URL for processing:
+// the oldest pending URL, or with host rotation the oldest pending URL of the
+// host chosen by getNextFromHostFrontier
 func (s *SQLiteStorage) GetNextFromQueue() (*crawler.URLItem, error) {
+	if s.rotateHosts {
+		return s.getNextFromHostFrontier()
+	}
+
+	if err := s.registerProcess(); err != nil {
+		return nil, err
+	}
+
 	var item crawler.URLItem
 
 	err := s.db.QueryRow(`
 		UPDATE pages 
-		SET status = 'processing', processing_started_at = ? 
+		SET status = 'processing', processing_started_at = ?, claimed_by = ?
 		WHERE id = (
 			SELECT id FROM pages 
 			WHERE status = 'pending' 
 			ORDER BY added_at ASC 
 			LIMIT 1
 		) AND status = 'pending'
-		RETURNING id, url
-	`, time.Now()).Scan(&item.ID, &item.URL)
+		RETURNING id, url, COALESCE(retry_count, 0)
+	`, time.Now(), s.process.id).Scan(&item.ID, &item.URL, &item.RetryCount)
 
 	if err == sql.ErrNoRows {
 		return nil, nil // No items in queue
@@ -161,6 +320,129 @@ func (s *SQLiteStorage) GetNextFromQueue() (*crawler.URLItem, error) {
 	return &item, nil
 }
 
+// GetNextBatchFromQueue atomically claims up to n pending URLs for
+// processing in one statement, oldest first, and returns them ordered by ID.
+// It returns an empty slice when the queue is empty. With host rotation the
+// URLs are claimed one host turn at a time, so a batch spans hosts.
+func (s *SQLiteStorage) GetNextBatchFromQueue(n int) ([]crawler.URLItem, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	if err := s.registerProcess(); err != nil {
+		return nil, err
+	}
+
+	if s.rotateHosts {
+		var items []crawler.URLItem
+		for len(items) < n {
+			item, err := s.getNextFromHostFrontier()
+			if err != nil {
+				return items, err
+			}
+			if item == nil {
+				break
+			}
+			items = append(items, *item)
+		}
+		return items, nil
+	}
+
+	rows, err := s.db.Query(`
+		UPDATE pages
+		SET status = 'processing', processing_started_at = ?, claimed_by = ?
+		WHERE id IN (
+			SELECT id FROM pages
+			WHERE status = 'pending'
+			ORDER BY added_at ASC
+			LIMIT ?
+		) AND status = 'pending'
+		RETURNING id, url, COALESCE(retry_count, 0)
+	`, time.Now(), s.process.id, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next batch from queue: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []crawler.URLItem
+	for rows.Next() {
+		var item crawler.URLItem
+		if err := rows.Scan(&item.ID, &item.URL, &item.RetryCount); err != nil {
+			return nil, fmt.Errorf("failed to scan queue item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get next batch from queue: %w", err)
+	}
+
+	// RETURNING does not guarantee an order
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items, nil
+}
+
+// getNextFromHostFrontier claims the oldest pending URL of the host that
+// should be served next: the pending host with the fewest pages in flight,
+// then the one whose last claim is oldest (never-claimed hosts first), then
+// the host seen first. One slow host therefore cannot tie up every
+// worker, and hosts take turns instead of being crawled one after another.
+func (s *SQLiteStorage) getNextFromHostFrontier() (*crawler.URLItem, error) {
+	if err := s.registerProcess(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The page counts of host_frontier are kept by triggers on pages, so the
+	// choice reads one entry of idx_host_frontier_next
+	var host string
+	err = tx.QueryRow(`
+		SELECT host FROM host_frontier
+		WHERE pending > 0
+		ORDER BY inflight ASC, last_claimed_at ASC, rowid ASC
+		LIMIT 1
+	`).Scan(&host)
+	if err == sql.ErrNoRows {
+		return nil, nil // No items in queue
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to choose next host: %w", err)
+	}
+
+	now := time.Now()
+	var item crawler.URLItem
+	err = tx.QueryRow(`
+		UPDATE pages
+		SET status = 'processing', processing_started_at = ?, claimed_by = ?
+		WHERE id = (
+			SELECT id FROM pages
+			WHERE status = 'pending' AND host = ?
+			ORDER BY added_at ASC
+			LIMIT 1
+		) AND status = 'pending'
+		RETURNING id, url, COALESCE(retry_count, 0)
+	`, now, s.process.id, host).Scan(&item.ID, &item.URL, &item.RetryCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next from queue: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO host_frontier (host, last_claimed_at) VALUES (?, ?)
+		ON CONFLICT(host) DO UPDATE SET last_claimed_at = excluded.last_claimed_at
+	`, host, now.UnixNano()); err != nil {
+		return nil, fmt.Errorf("failed to record host claim: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit queue claim: %w", err)
+	}
+	return &item, nil
+}
+
 // UpdatePageStatus updates the status of a page
 func (s *SQLiteStorage) UpdatePageStatus(id int, status string) error {
 	_, err := s.db.Exec(`
@@ -175,6 +457,38 @@ func (s *SQLiteStorage) UpdatePageStatus(id int, status string) error {
 
 // SavePageResult saves the crawl results for a page
 func (s *SQLiteStorage) SavePageResult(id int, page *crawler.PageData) error {
+	return s.SavePageResults([]crawler.CompletedPage{{ID: id, Page: page}})
+}
+
+// SavePageResults saves the crawl results for several pages in one
+// transaction: the completed status, the page columns and the per-page tables
+// of every page are committed together, so a batch costs one commit instead
+// of one per page and table. Either every page is saved or none is.
+func (s *SQLiteStorage) SavePageResults(pages []crawler.CompletedPage) error {
+	if len(pages) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, p := range pages {
+		if err := s.savePageResult(tx, p.ID, p.Page); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit page results: %w", err)
+	}
+	return nil
+}
+
+// savePageResult saves the crawl results for one page within tx
+func (s *SQLiteStorage) savePageResult(tx *sql.Tx, id int, page *crawler.PageData) error {
 	// Serialize HTTP headers to JSON
 	var headersJSON []byte
 	var err error
@@ -185,6 +499,15 @@ func (s *SQLiteStorage) SavePageResult(id int, page *crawler.PageData) error {
 		}
 	}
 
+	title, err := s.encryptField(page.Title)
+	if err != nil {
+		return err
+	}
+	metaDesc, err := s.encryptField(page.MetaDesc)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE pages SET
 			status = 'completed',
@@ -192,27 +515,31 @@ func (s *SQLiteStorage) SavePageResult(id int, page *crawler.PageData) error {
 			title = ?,
 			meta_description = ?,
 			meta_robots = ?,
+			x_robots_tag = ?,
 			canonical_url = ?,
 			content_hash = ?,
 			ttfb_ms = ?,
 			download_time_ms = ?,
 			response_size_bytes = ?,
 			response_http_headers = ?,
+			rendered = ?,
 			crawled_at = ?
 		WHERE id = ?
 	`
 
-	_, err = s.db.Exec(query,
+	_, err = tx.Exec(query,
 		page.StatusCode,
-		page.Title,
-		page.MetaDesc,
+		title,
+		metaDesc,
 		page.MetaRobots,
+		page.XRobotsTag,
 		page.CanonicalURL,
 		page.ContentHash,
 		page.TTFB.Milliseconds(),
 		page.DownloadTime.Milliseconds(),
 		page.ResponseSize,
 		string(headersJSON),
+		page.Rendered,
 		page.CrawledAt,
 		id,
 	)
@@ -220,12 +547,69 @@ func (s *SQLiteStorage) SavePageResult(id int, page *crawler.PageData) error {
 	if err != nil {
 		return fmt.Errorf("failed to save page result: %w", err)
 	}
+
+	if err := s.savePageAlternates(tx, id, page.Alternates); err != nil {
+		return err
+	}
+	if err := s.savePageRels(tx, id, page.Rels); err != nil {
+		return err
+	}
+	if err := s.savePageHeadings(tx, id, page.Headings); err != nil {
+		return err
+	}
+	if err := s.savePageStructuredData(tx, id, page.Structured); err != nil {
+		return err
+	}
+	if err := s.savePageExtractions(tx, id, page.Extractions); err != nil {
+		return err
+	}
+	if err := s.savePageImages(tx, id, page.Images); err != nil {
+		return err
+	}
+	if err := s.savePageAccessibility(tx, id, page.Accessibility); err != nil {
+		return err
+	}
+	if err := s.savePageRedirects(tx, id, page.Redirects); err != nil {
+		return err
+	}
+	if err := s.saveCertificates(tx, page.Certificates, page.CrawledAt); err != nil {
+		return err
+	}
+	return s.savePageContent(tx, id, page.Text)
+}
+
+// savePageContent replaces the text statistics stored for a page; nil clears them
+func (s *SQLiteStorage) savePageContent(tx *sql.Tx, pageID int, text *crawler.PageText) error {
+	if text == nil {
+		if _, err := tx.Exec("DELETE FROM page_content WHERE page_id = ?", pageID); err != nil {
+			return fmt.Errorf("failed to clear page content: %w", err)
+		}
+		return nil
+	}
+
+	// SQLite integers are signed; the hash is stored bit for bit
+	var simHash sql.NullInt64
+	if text.SimHash != 0 {
+		simHash = sql.NullInt64{Int64: int64(text.SimHash), Valid: true}
+	}
+	_, err := tx.Exec(`
+		INSERT OR REPLACE INTO page_content (page_id, word_count, text_ratio, simhash, language, html_lang)
+		VALUES (?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''))
+	`, pageID, text.WordCount, text.TextRatio, simHash, text.Language, text.HTMLLang)
+	if err != nil {
+		return fmt.Errorf("failed to save page content: %w", err)
+	}
 	return nil
 }
 
 // SavePageError marks a page as errored with error details
 func (s *SQLiteStorage) SavePageError(id int, errorType, errorMessage string) error {
-	_, err := s.db.Exec(`
+	errorMessage, err := s.encryptField(errorMessage)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
 		UPDATE pages SET 
 			status = 'error',
 			last_error_type = ?,
@@ -242,7 +626,12 @@ func (s *SQLiteStorage) SavePageError(id int, errorType, errorMessage string) er
 
 // SavePageSkipped marks a page as skipped (e.g., robots.txt disallow)
 func (s *SQLiteStorage) SavePageSkipped(id int, reason, message string) error {
-	_, err := s.db.Exec(`
+	message, err := s.encryptField(message)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
 		UPDATE pages SET 
 			status = 'skipped',
 			last_error_type = ?,
@@ -269,6 +658,11 @@ func (s *SQLiteStorage) SaveLink(link *crawler.LinkData) error {
 		return fmt.Errorf("failed to get target page ID for %s: %w", link.TargetURL, err)
 	}
 
+	anchorText, err := s.encryptField(link.AnchorText)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT OR IGNORE INTO link_relations (
 			source_page_id, target_page_id, anchor_text, link_type, 
@@ -279,7 +673,7 @@ func (s *SQLiteStorage) SaveLink(link *crawler.LinkData) error {
 	_, err = s.db.Exec(query,
 		sourceID,
 		targetID,
-		link.AnchorText,
+		anchorText,
 		link.LinkType,
 		link.RelAttribute,
 		link.CrawledAt,
@@ -395,10 +789,15 @@ func (s *SQLiteStorage) saveLinksBatch(links []*crawler.LinkData) error {
 		sourceID := urlToID[link.SourceURL]
 		targetID := urlToID[link.TargetURL]
 
+		anchorText, err := s.encryptField(link.AnchorText)
+		if err != nil {
+			return err
+		}
+
 		if _, err := stmt.Exec(
 			sourceID,
 			targetID,
-			link.AnchorText,
+			anchorText,
 			link.LinkType,
 			link.RelAttribute,
 			link.CrawledAt,
@@ -412,16 +811,21 @@ func (s *SQLiteStorage) saveLinksBatch(links []*crawler.LinkData) error {
 
 // SaveError saves crawl error details
 func (s *SQLiteStorage) SaveError(crawlErr *crawler.CrawlError) error {
+	errorMessage, err := s.encryptField(crawlErr.ErrorMessage)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO crawl_errors (
 			url, error_type, error_message, occurred_at
 		) VALUES (?, ?, ?, ?)
 	`
 
-	_, err := s.db.Exec(query,
+	_, err = s.db.Exec(query,
 		crawlErr.URL,
 		crawlErr.ErrorType,
-		crawlErr.ErrorMessage,
+		errorMessage,
 		crawlErr.OccurredAt,
 	)
 
@@ -442,7 +846,7 @@ func (s *SQLiteStorage) GetQueueStatus() (pending int, processing int, completed
 		FROM pages
 	`
 
-	err = s.db.QueryRow(query).Scan(&pending, &processing, &completed, &errors)
+	err = s.read.QueryRow(query).Scan(&pending, &processing, &completed, &errors)
 	if err != nil {
 		return 0, 0, 0, 0, fmt.Errorf("failed to get queue status: %w", err)
 	}
@@ -453,7 +857,7 @@ func (s *SQLiteStorage) GetQueueStatus() (pending int, processing int, completed
 // HasQueuedItems checks if there are any items available for processing (pending or processing status)
 func (s *SQLiteStorage) HasQueuedItems() (bool, error) {
 	var count int
-	err := s.db.QueryRow(`
+	err := s.read.QueryRow(`
 		SELECT COUNT(*) 
 		FROM pages 
 		WHERE status IN ('pending', 'processing')
@@ -466,6 +870,13 @@ func (s *SQLiteStorage) HasQueuedItems() (bool, error) {
 	return count > 0, nil
 }
 
+// retryableErrorTypes are the transient error types, quoted for SQL, that
+// GetRetryablePages and RequeueErrorPages retry
+var retryableErrorTypes = "'" + strings.Join([]string{
+	crawler.ErrorTypeTimeout, crawler.ErrorTypeConnectionRefused, crawler.ErrorTypeConnectionReset,
+	crawler.ErrorTypeDNSFailure, crawler.ErrorTypeServerError,
+}, "', '") + "'"
+
 // GetRetryablePages returns pages with error status that can be retried
 func (s *SQLiteStorage) GetRetryablePages(maxRetries int) ([]crawler.URLItem, error) {
 	rows, err := s.db.Query(`
@@ -473,7 +884,7 @@ func (s *SQLiteStorage) GetRetryablePages(maxRetries int) ([]crawler.URLItem, er
 		FROM pages 
 		WHERE status = 'error' 
 		  AND retry_count < ? 
-		  AND last_error_type IN ('network_timeout', 'connection_refused', 'dns_resolution_failed', 'server_error_5xx')
+		  AND last_error_type IN (`+retryableErrorTypes+`)
 		ORDER BY retry_count ASC, added_at ASC
 	`, maxRetries)
 	if err != nil {
@@ -485,8 +896,7 @@ func (s *SQLiteStorage) GetRetryablePages(maxRetries int) ([]crawler.URLItem, er
 	for rows.Next() {
 		var item crawler.URLItem
 		var errorType string
-		var retryCount int
-		if err := rows.Scan(&item.ID, &item.URL, &retryCount, &errorType); err != nil {
+		if err := rows.Scan(&item.ID, &item.URL, &item.RetryCount, &errorType); err != nil {
 			return nil, fmt.Errorf("failed to scan retryable page: %w", err)
 		}
 		items = append(items, item)
@@ -506,7 +916,7 @@ func (s *SQLiteStorage) RequeueErrorPages(maxRetries int) (int, error) {
 		SET status = 'pending', processing_started_at = NULL 
 		WHERE status = 'error' 
 		  AND retry_count < ? 
-		  AND last_error_type IN ('network_timeout', 'connection_refused', 'dns_resolution_failed', 'server_error_5xx')
+		  AND last_error_type IN (`+retryableErrorTypes+`)
 	`, maxRetries)
 	if err != nil {
 		return 0, fmt.Errorf("failed to requeue error pages: %w", err)
@@ -552,26 +962,74 @@ func (s *SQLiteStorage) GetProcessingItems() ([]crawler.URLItem, error) {
 	return items, nil
 }
 
+// GetQueuedURLs returns up to limit URLs still waiting to be crawled
+// ('pending' or 'processing'), oldest first
+func (s *SQLiteStorage) GetQueuedURLs(limit int) ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT url
+		FROM pages
+		WHERE status IN ('pending', 'processing')
+		ORDER BY added_at, id
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query queued URLs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var urls []string
+	for rows.Next() {
+		var u string
+		if err := rows.Scan(&u); err != nil {
+			return nil, fmt.Errorf("failed to scan queued URL: %w", err)
+		}
+		urls = append(urls, u)
+	}
+	return urls, rows.Err()
+}
+
 // CleanupStaleProcessing resets processing items that have been stuck back to
-// 'pending'. A row with a NULL processing_started_at is always reset: `NULL < ?`
+// 'pending' and returns how many were reset. A row with a NULL processing_started_at is always reset: `NULL < ?`
 // is never true in SQL, so without the explicit IS NULL clause such a row would
 // survive cleanup and keep HasQueuedItems() perpetually true, hanging the
 // crawler. The timestamp should never be NULL on the normal path, but the
 // invariant is cheap to enforce here.
-func (s *SQLiteStorage) CleanupStaleProcessing(timeout time.Duration) error {
-	cutoff := time.Now().Add(-timeout)
+//
+// Rows claimed by another crawl process that is still alive (see
+// processes.go) are left alone whatever the timeout, so a second process
+// starting on the database does not requeue pages the first is fetching.
+func (s *SQLiteStorage) CleanupStaleProcessing(timeout time.Duration) (int, error) {
+	now := time.Now()
+	cutoff := now.Add(-timeout)
 
-	_, err := s.db.Exec(`
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM crawl_processes WHERE heartbeat_at < ?`, now.Add(-processLiveness)); err != nil {
+		return 0, fmt.Errorf("failed to remove dead crawl processes: %w", err)
+	}
+	result, err := tx.Exec(`
 		UPDATE pages
-		SET status = 'pending', processing_started_at = NULL
+		SET status = 'pending', processing_started_at = NULL, claimed_by = NULL
 		WHERE status = 'processing'
 		AND (processing_started_at < ? OR processing_started_at IS NULL)
-	`, cutoff)
-
+		AND (claimed_by IS NULL OR claimed_by = ?
+			OR claimed_by NOT IN (SELECT id FROM crawl_processes))
+	`, cutoff, s.process.id)
 	if err != nil {
-		return fmt.Errorf("failed to cleanup stale processing: %w", err)
+		return 0, fmt.Errorf("failed to cleanup stale processing: %w", err)
 	}
-	return nil
+	recovered, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovered processing rows: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit stale processing cleanup: %w", err)
+	}
+	return int(recovered), nil
 }
 
 // GetMeta retrieves a metadata value
@@ -601,7 +1059,7 @@ func (s *SQLiteStorage) SetMeta(key, value string) error {
 
 // GetURLStatus checks if a URL exists and returns its status
 func (s *SQLiteStorage) GetURLStatus(url string) (status string, exists bool) {
-	err := s.db.QueryRow("SELECT status FROM pages WHERE url = ?", url).Scan(&status)
+	err := s.read.QueryRow("SELECT status FROM pages WHERE url = ?", url).Scan(&status)
 	if err == sql.ErrNoRows {
 		return "", false
 	}
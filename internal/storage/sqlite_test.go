@@ -3,6 +3,7 @@ package storage
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -224,7 +225,7 @@ func testQueueOperations(t *testing.T, storage *SQLiteStorage) {
 	}
 
 	// Test cleanup stale processing
-	err = storage.CleanupStaleProcessing(1 * time.Hour)
+	_, err = storage.CleanupStaleProcessing(1 * time.Hour)
 	if err != nil {
 		t.Errorf("Failed to cleanup stale processing: %v", err)
 	}
@@ -1076,3 +1077,709 @@ func testConcurrentQueueOperations(t *testing.T, storage *SQLiteStorage) {
 	t.Logf("Concurrent queue test completed: pending=%d, processing=%d, completed=%d, processed=%d",
 		pending, processing, completed, finalProcessedCount)
 }
+
+func TestGetQueuedURLs(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "queued.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	urls := []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+
+	// 'a' becomes processing, 'b' completes; both 'a' and 'c' are still queued
+	first, _ := store.GetNextFromQueue()
+	second, _ := store.GetNextFromQueue()
+	if err := store.UpdatePageStatus(second.ID, "completed"); err != nil {
+		t.Fatalf("Failed to complete page: %v", err)
+	}
+
+	got, err := store.GetQueuedURLs(10)
+	if err != nil {
+		t.Fatalf("GetQueuedURLs failed: %v", err)
+	}
+	if len(got) != 2 || got[0] != first.URL || got[1] != "https://example.com/c" {
+		t.Errorf("Unexpected queued URLs: %v", got)
+	}
+
+	if got, _ := store.GetQueuedURLs(1); len(got) != 1 {
+		t.Errorf("Expected limit to apply, got %v", got)
+	}
+}
+
+func TestGetNextBatchFromQueue(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "batch.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	urls := []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+
+	batch, err := store.GetNextBatchFromQueue(2)
+	if err != nil {
+		t.Fatalf("GetNextBatchFromQueue failed: %v", err)
+	}
+	if len(batch) != 2 || batch[0].URL != urls[0] || batch[1].URL != urls[1] {
+		t.Fatalf("Expected the two oldest URLs, got %+v", batch)
+	}
+	for _, item := range batch {
+		if status, _ := store.GetURLStatus(item.URL); status != "processing" {
+			t.Errorf("%s status = %q, want processing", item.URL, status)
+		}
+	}
+
+	// Only the remaining pending URL is left to claim
+	batch, err = store.GetNextBatchFromQueue(5)
+	if err != nil || len(batch) != 1 || batch[0].URL != urls[2] {
+		t.Fatalf("Expected the last URL, got %+v (%v)", batch, err)
+	}
+	if batch, err := store.GetNextBatchFromQueue(5); err != nil || len(batch) != 0 {
+		t.Errorf("Expected an empty batch from an empty queue, got %+v (%v)", batch, err)
+	}
+}
+
+func TestSavePageResults(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "results.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	urls := []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	batch, err := store.GetNextBatchFromQueue(3)
+	if err != nil || len(batch) != 3 {
+		t.Fatalf("Failed to claim batch: %+v (%v)", batch, err)
+	}
+
+	var pages []crawler.CompletedPage
+	for _, item := range batch[:2] {
+		pages = append(pages, crawler.CompletedPage{ID: item.ID, Page: &crawler.PageData{
+			URL:         item.URL,
+			StatusCode:  200,
+			Title:       "Page " + item.URL,
+			HTTPHeaders: map[string]string{},
+			CrawledAt:   time.Now(),
+			Headings:    []crawler.Heading{{Level: 1, Text: "Heading"}},
+		}})
+	}
+	if err := store.SavePageResults(pages); err != nil {
+		t.Fatalf("SavePageResults failed: %v", err)
+	}
+	if err := store.SavePageResults(nil); err != nil {
+		t.Errorf("Expected an empty batch to be a no-op, got %v", err)
+	}
+
+	for i, url := range urls {
+		want := "completed"
+		if i == 2 {
+			want = "processing"
+		}
+		if status, _ := store.GetURLStatus(url); status != want {
+			t.Errorf("%s status = %q, want %q", url, status, want)
+		}
+	}
+	if headings, err := store.GetPageHeadings(urls[1]); err != nil || len(headings) != 1 {
+		t.Errorf("Expected the per-page tables to be saved, got %+v (%v)", headings, err)
+	}
+}
+
+func TestGetNextFromQueueRotatesHosts(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "frontier.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	store.SetHostRotation(true)
+
+	urls := []string{
+		"https://a.example.com/1", "https://a.example.com/2", "https://a.example.com/3",
+		"https://b.example.com:8443/1", "https://c.example.com",
+	}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+
+	var host string
+	if err := store.db.QueryRow("SELECT host FROM pages WHERE url = ?", urls[3]).Scan(&host); err != nil || host != "b.example.com:8443" {
+		t.Fatalf("host column = %q (%v), want b.example.com:8443", host, err)
+	}
+
+	// Every host gets a turn before any host gets a second one
+	hosts := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		item, err := store.GetNextFromQueue()
+		if err != nil || item == nil {
+			t.Fatalf("Failed to dequeue: %v", err)
+		}
+		if err := store.db.QueryRow("SELECT host FROM pages WHERE id = ?", item.ID).Scan(&host); err != nil {
+			t.Fatalf("Failed to read host: %v", err)
+		}
+		hosts[host] = true
+	}
+	if len(hosts) != 3 {
+		t.Fatalf("Expected three different hosts in the first three claims, got %v", hosts)
+	}
+
+	// Only a.example.com has pending URLs left; they are claimed oldest first
+	batch, err := store.GetNextBatchFromQueue(5)
+	if err != nil || len(batch) != 2 || batch[0].URL != urls[1] || batch[1].URL != urls[2] {
+		t.Fatalf("Expected the remaining a.example.com URLs, got %+v (%v)", batch, err)
+	}
+
+	var frontier int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM host_frontier").Scan(&frontier); err != nil || frontier != 3 {
+		t.Errorf("host_frontier rows = %d (%v), want 3", frontier, err)
+	}
+}
+
+func TestGetNextFromQueuePrefersIdleHosts(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "frontier.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// Claim a slow host's first URL in FIFO mode, so it has a page in flight
+	// but no recorded claim time
+	if err := store.AddToQueue([]string{"https://slow.example.com/1", "https://slow.example.com/2"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	if item, err := store.GetNextFromQueue(); err != nil || item == nil {
+		t.Fatalf("Failed to dequeue: %v", err)
+	}
+	if err := store.AddToQueue([]string{"https://fast.example.com/1"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+
+	store.SetHostRotation(true)
+	item, err := store.GetNextFromQueue()
+	if err != nil || item == nil || item.URL != "https://fast.example.com/1" {
+		t.Fatalf("Expected the idle host to be served first, got %+v (%v)", item, err)
+	}
+}
+
+// checkHostFrontierCounts fails unless the counts host_frontier keeps match
+// the statuses of pages
+func checkHostFrontierCounts(t *testing.T, store *SQLiteStorage) {
+	t.Helper()
+	rows, err := store.db.Query(`
+		SELECT COALESCE(f.host, p.host), COALESCE(f.pending, 0), COALESCE(f.inflight, 0),
+			COALESCE(p.pending, 0), COALESCE(p.inflight, 0)
+		FROM host_frontier f
+		FULL OUTER JOIN (
+			SELECT host, SUM(status = 'pending') AS pending, SUM(status = 'processing') AS inflight
+			FROM pages GROUP BY host
+		) p ON p.host = f.host
+	`)
+	if err != nil {
+		t.Fatalf("Failed to compare host_frontier counts: %v", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var host string
+		var pending, inflight, wantPending, wantInflight int
+		if err := rows.Scan(&host, &pending, &inflight, &wantPending, &wantInflight); err != nil {
+			t.Fatalf("Failed to scan host_frontier counts: %v", err)
+		}
+		if pending != wantPending || inflight != wantInflight {
+			t.Errorf("host_frontier %s: %d pending, %d in flight; pages have %d and %d",
+				host, pending, inflight, wantPending, wantInflight)
+		}
+	}
+}
+
+func TestHostFrontierCounts(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "frontier.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	store.SetHostRotation(true)
+
+	var urls []string
+	for _, host := range []string{"a.example.com", "b.example.com", "c.example.com:8080"} {
+		for i := 0; i < 4; i++ {
+			urls = append(urls, fmt.Sprintf("https://%s/%d", host, i))
+		}
+	}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	// Links to a page create it as 'discovered', which is not counted
+	if err := store.SaveLinks([]*crawler.LinkData{{SourceURL: urls[0], TargetURL: "https://d.example.com/", LinkType: "internal"}}); err != nil {
+		t.Fatalf("Failed to save links: %v", err)
+	}
+	checkHostFrontierCounts(t, store)
+
+	batch, err := store.GetNextBatchFromQueue(6)
+	if err != nil || len(batch) != 6 {
+		t.Fatalf("Failed to claim a batch: %+v (%v)", batch, err)
+	}
+	checkHostFrontierCounts(t, store)
+
+	_ = store.SavePageResult(batch[0].ID, &crawler.PageData{URL: batch[0].URL, StatusCode: 200, HTTPHeaders: map[string]string{}, CrawledAt: time.Now()})
+	_ = store.SavePageError(batch[1].ID, "network_error", "connection refused")
+	_ = store.SavePageSkipped(batch[2].ID, "robots", "disallowed")
+	_ = store.UpdatePageStatus(batch[3].ID, "pending")
+	checkHostFrontierCounts(t, store)
+
+	if _, err := store.RequeueErrorPages(3); err != nil {
+		t.Fatalf("Failed to requeue errors: %v", err)
+	}
+	if _, err := store.CleanupStaleProcessing(0); err != nil {
+		t.Fatalf("Failed to requeue stale pages: %v", err)
+	}
+	if _, err := store.db.Exec("DELETE FROM pages WHERE url = ?", urls[11]); err != nil {
+		t.Fatalf("Failed to delete a page: %v", err)
+	}
+	checkHostFrontierCounts(t, store)
+
+	// Choosing a host reads the index instead of sorting hosts
+	rows, err := store.db.Query(`EXPLAIN QUERY PLAN
+		SELECT host FROM host_frontier WHERE pending > 0
+		ORDER BY inflight ASC, last_claimed_at ASC, rowid ASC LIMIT 1`)
+	if err != nil {
+		t.Fatalf("Failed to explain the host choice: %v", err)
+	}
+	var plan []string
+	for rows.Next() {
+		var id, parent, unused int
+		var detail string
+		_ = rows.Scan(&id, &parent, &unused, &detail)
+		plan = append(plan, detail)
+	}
+	_ = rows.Close()
+	if joined := strings.Join(plan, "; "); !strings.Contains(joined, "idx_host_frontier_next") || strings.Contains(joined, "TEMP B-TREE") {
+		t.Errorf("Unexpected query plan for the host choice: %s", joined)
+	}
+}
+
+func TestMigrateHostFrontierAddCounts(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// Rebuild host_frontier as it was before it counted pages
+	_, err = store.db.Exec(`
+		DROP TRIGGER pages_frontier_insert;
+		DROP TRIGGER pages_frontier_update;
+		DROP TRIGGER pages_frontier_delete;
+		DROP TABLE host_frontier;
+		CREATE TABLE host_frontier (host TEXT PRIMARY KEY, last_claimed_at INTEGER NOT NULL);
+		INSERT INTO host_frontier (host, last_claimed_at) VALUES ('a.example.com', 42);
+	`)
+	if err != nil {
+		t.Fatalf("Failed to build legacy table: %v", err)
+	}
+	if err := store.AddToQueue([]string{"https://a.example.com/1", "https://a.example.com/2", "https://b.example.com/1"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	if item, err := store.GetNextFromQueue(); err != nil || item == nil {
+		t.Fatalf("Failed to dequeue: %v", err)
+	}
+	if err := store.SetMeta(metaSchemaVersion, "27"); err != nil {
+		t.Fatalf("Failed to record legacy schema version: %v", err)
+	}
+
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	checkHostFrontierCounts(t, store)
+	var claimed int64
+	if err := store.db.QueryRow("SELECT last_claimed_at FROM host_frontier WHERE host = 'a.example.com'").Scan(&claimed); err != nil || claimed != 42 {
+		t.Errorf("last_claimed_at = %d (%v), want the recorded claim kept", claimed, err)
+	}
+
+	// The triggers keep the counts from now on
+	store.SetHostRotation(true)
+	if item, err := store.GetNextFromQueue(); err != nil || item == nil {
+		t.Fatalf("Expected a claim after migration, got %+v (%v)", item, err)
+	}
+	checkHostFrontierCounts(t, store)
+}
+
+func TestAssetsView(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "assets.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	pages := map[string]*crawler.PageData{
+		"https://example.com/":         {StatusCode: 200, ContentHash: "htmlhash", HTTPHeaders: map[string]string{"content-type": "text/html"}},
+		"https://example.com/a.pdf":    {StatusCode: 200, ContentHash: "samehash", ResponseSize: 10, HTTPHeaders: map[string]string{"content-type": "application/pdf"}},
+		"https://example.com/copy.pdf": {StatusCode: 200, ContentHash: "samehash", ResponseSize: 10, HTTPHeaders: map[string]string{"content-type": "application/pdf"}},
+	}
+	urls := []string{"https://example.com/", "https://example.com/a.pdf", "https://example.com/copy.pdf"}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range urls {
+		item, err := store.GetNextFromQueue()
+		if err != nil || item == nil {
+			t.Fatalf("Failed to dequeue: %v", err)
+		}
+		page := pages[item.URL]
+		page.URL = item.URL
+		page.CrawledAt = time.Now()
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	var count, duplicates int
+	err = store.db.QueryRow(
+		"SELECT COUNT(*), COUNT(*) - COUNT(DISTINCT content_hash) FROM assets",
+	).Scan(&count, &duplicates)
+	if err != nil {
+		t.Fatalf("Failed to query assets view: %v", err)
+	}
+	if count != 2 || duplicates != 1 {
+		t.Errorf("Expected 2 assets with 1 duplicate, got %d assets, %d duplicates", count, duplicates)
+	}
+}
+
+func TestPageContent(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "content.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.AddToQueue([]string{"https://example.com/"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	item, err := store.GetNextFromQueue()
+	if err != nil || item == nil {
+		t.Fatalf("Failed to dequeue: %v", err)
+	}
+
+	page := &crawler.PageData{
+		URL:         item.URL,
+		StatusCode:  200,
+		HTTPHeaders: map[string]string{},
+		CrawledAt:   time.Now(),
+		Text:        &crawler.PageText{WordCount: 42, TextRatio: 0.125},
+	}
+	if err := store.SavePageResult(item.ID, page); err != nil {
+		t.Fatalf("Failed to save page: %v", err)
+	}
+
+	var words int
+	var ratio float64
+	err = store.db.QueryRow("SELECT word_count, text_ratio FROM page_content WHERE page_id = ?", item.ID).Scan(&words, &ratio)
+	if err != nil {
+		t.Fatalf("Failed to query page_content: %v", err)
+	}
+	if words != 42 || ratio != 0.125 {
+		t.Errorf("Expected 42 words and ratio 0.125, got %d and %v", words, ratio)
+	}
+
+	// A re-crawl without text statistics clears the row
+	page.Text = nil
+	if err := store.SavePageResult(item.ID, page); err != nil {
+		t.Fatalf("Failed to save page: %v", err)
+	}
+	var count int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM page_content").Scan(&count); err != nil {
+		t.Fatalf("Failed to count page_content: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected page content to be cleared, got %d rows", count)
+	}
+}
+
+func TestSummaryViews(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "summary.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	crawledAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	statusCodes := map[string]int{
+		"https://example.com/":        200,
+		"https://example.com/missing": 404,
+		"https://shop.example.com":    200,
+	}
+	urls := []string{"https://example.com/", "https://example.com/missing", "https://shop.example.com", "https://example.com/down"}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range urls {
+		item, err := store.GetNextFromQueue()
+		if err != nil || item == nil {
+			t.Fatalf("Failed to dequeue: %v", err)
+		}
+		if item.URL == "https://example.com/down" {
+			if err := store.SavePageError(item.ID, "timeout", "timed out"); err != nil {
+				t.Fatalf("Failed to save error: %v", err)
+			}
+			continue
+		}
+		page := &crawler.PageData{
+			URL:          item.URL,
+			StatusCode:   statusCodes[item.URL],
+			ResponseSize: 100,
+			HTTPHeaders:  map[string]string{},
+			CrawledAt:    crawledAt,
+		}
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+	for range 2 {
+		crawlErr := &crawler.CrawlError{URL: "https://example.com/down", ErrorType: "timeout", OccurredAt: crawledAt}
+		if err := store.SaveError(crawlErr); err != nil {
+			t.Fatalf("Failed to save crawl error: %v", err)
+		}
+	}
+
+	var pages, completed, errors int
+	err = store.db.QueryRow("SELECT pages, completed, errors FROM host_summary WHERE host = 'example.com'").Scan(&pages, &completed, &errors)
+	if err != nil {
+		t.Fatalf("Failed to query host_summary: %v", err)
+	}
+	if pages != 3 || completed != 2 || errors != 1 {
+		t.Errorf("Unexpected host_summary for example.com: %d pages, %d completed, %d errors", pages, completed, errors)
+	}
+	if err := store.db.QueryRow("SELECT pages FROM host_summary WHERE host = 'shop.example.com'").Scan(&pages); err != nil || pages != 1 {
+		t.Errorf("Expected 1 page for shop.example.com, got %d (err %v)", pages, err)
+	}
+
+	var class string
+	if err := store.db.QueryRow("SELECT status_class, pages FROM status_summary WHERE status_code = 200").Scan(&class, &pages); err != nil {
+		t.Fatalf("Failed to query status_summary: %v", err)
+	}
+	if class != "2xx" || pages != 2 {
+		t.Errorf("Unexpected status_summary for 200: %s %d", class, pages)
+	}
+
+	var occurrences, distinct int
+	if err := store.db.QueryRow("SELECT occurrences, urls FROM errors_by_type WHERE error_type = 'timeout'").Scan(&occurrences, &distinct); err != nil {
+		t.Fatalf("Failed to query errors_by_type: %v", err)
+	}
+	if occurrences != 2 || distinct != 1 {
+		t.Errorf("Unexpected errors_by_type: %d occurrences, %d urls", occurrences, distinct)
+	}
+
+	var day string
+	var bytes int64
+	if err := store.db.QueryRow("SELECT day, pages, errors, bytes FROM daily_crawl_counts").Scan(&day, &pages, &errors, &bytes); err != nil {
+		t.Fatalf("Failed to query daily_crawl_counts: %v", err)
+	}
+	if day != "2024-06-01" || pages != 3 || errors != 2 || bytes != 300 {
+		t.Errorf("Unexpected daily_crawl_counts: %s %d pages, %d errors, %d bytes", day, pages, errors, bytes)
+	}
+}
+
+func TestMigratePagesAddHost(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// Remove the column as it was before the host frontier
+	_, err = store.db.Exec(`
+		DROP TRIGGER pages_frontier_insert;
+		DROP TRIGGER pages_frontier_update;
+		DROP TRIGGER pages_frontier_delete;
+		DROP INDEX idx_pages_status_host;
+		ALTER TABLE pages DROP COLUMN host;
+		DROP TABLE host_frontier;
+	`)
+	if err != nil {
+		t.Fatalf("Failed to build legacy table: %v", err)
+	}
+	if err := store.AddToQueue([]string{"https://example.com/a"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	if err := store.SetMeta(metaSchemaVersion, "15"); err != nil {
+		t.Fatalf("Failed to record legacy schema version: %v", err)
+	}
+
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	var host string
+	if err := store.db.QueryRow("SELECT host FROM pages WHERE url = ?", "https://example.com/a").Scan(&host); err != nil || host != "example.com" {
+		t.Errorf("host = %q (%v), want example.com", host, err)
+	}
+	store.SetHostRotation(true)
+	if item, err := store.GetNextFromQueue(); err != nil || item == nil {
+		t.Errorf("Expected a claim after migration, got %+v (%v)", item, err)
+	}
+}
+
+func TestMigratePagesAddRendered(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// Remove the column as it was before rendered pages were flagged
+	if _, err := store.db.Exec("ALTER TABLE pages DROP COLUMN rendered"); err != nil {
+		t.Fatalf("Failed to build legacy table: %v", err)
+	}
+	if err := store.AddToQueue([]string{"https://example.com/app"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	if err := store.SetMeta(metaSchemaVersion, "22"); err != nil {
+		t.Fatalf("Failed to record legacy schema version: %v", err)
+	}
+
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	item, err := store.GetNextFromQueue()
+	if err != nil || item == nil {
+		t.Fatalf("Failed to dequeue: %v", err)
+	}
+	page := &crawler.PageData{
+		URL:         item.URL,
+		StatusCode:  200,
+		HTTPHeaders: map[string]string{},
+		CrawledAt:   time.Now(),
+		Rendered:    true,
+	}
+	if err := store.SavePageResult(item.ID, page); err != nil {
+		t.Fatalf("Failed to save page: %v", err)
+	}
+	var rendered bool
+	if err := store.db.QueryRow("SELECT rendered FROM pages WHERE id = ?", item.ID).Scan(&rendered); err != nil || !rendered {
+		t.Errorf("rendered = %v (%v), want true", rendered, err)
+	}
+}
+
+func TestMigratePageContentAddSimHash(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// Remove the column as it was before near-duplicates were detected
+	if _, err := store.db.Exec("ALTER TABLE page_content DROP COLUMN simhash"); err != nil {
+		t.Fatalf("Failed to build legacy table: %v", err)
+	}
+	if err := store.AddToQueue([]string{"https://example.com/"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	if err := store.SetMeta(metaSchemaVersion, "25"); err != nil {
+		t.Fatalf("Failed to record legacy schema version: %v", err)
+	}
+
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	item, err := store.GetNextFromQueue()
+	if err != nil || item == nil {
+		t.Fatalf("Failed to dequeue: %v", err)
+	}
+	page := &crawler.PageData{
+		URL:         item.URL,
+		StatusCode:  200,
+		HTTPHeaders: map[string]string{},
+		CrawledAt:   time.Now(),
+		Text:        &crawler.PageText{WordCount: 10, SimHash: 1 << 63},
+	}
+	if err := store.SavePageResult(item.ID, page); err != nil {
+		t.Fatalf("Failed to save page: %v", err)
+	}
+	var simHash int64
+	if err := store.db.QueryRow("SELECT simhash FROM page_content WHERE page_id = ?", item.ID).Scan(&simHash); err != nil || uint64(simHash) != 1<<63 {
+		t.Errorf("simhash = %x (%v), want %x", uint64(simHash), err, uint64(1<<63))
+	}
+}
+
+func TestMigratePageContentAddLanguage(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// Remove the columns as they were before languages were stored
+	for _, column := range []string{"language", "html_lang"} {
+		if _, err := store.db.Exec("ALTER TABLE page_content DROP COLUMN " + column); err != nil {
+			t.Fatalf("Failed to build legacy table: %v", err)
+		}
+	}
+	if err := store.AddToQueue([]string{"https://example.com/"}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	if err := store.SetMeta(metaSchemaVersion, "26"); err != nil {
+		t.Fatalf("Failed to record legacy schema version: %v", err)
+	}
+
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	item, err := store.GetNextFromQueue()
+	if err != nil || item == nil {
+		t.Fatalf("Failed to dequeue: %v", err)
+	}
+	page := &crawler.PageData{
+		URL:         item.URL,
+		StatusCode:  200,
+		HTTPHeaders: map[string]string{},
+		CrawledAt:   time.Now(),
+		Text:        &crawler.PageText{WordCount: 10, Language: "en", HTMLLang: "en-GB"},
+	}
+	if err := store.SavePageResult(item.ID, page); err != nil {
+		t.Fatalf("Failed to save page: %v", err)
+	}
+	var language, htmlLang string
+	err = store.db.QueryRow("SELECT language, html_lang FROM page_content WHERE page_id = ?", item.ID).Scan(&language, &htmlLang)
+	if err != nil || language != "en" || htmlLang != "en-GB" {
+		t.Errorf("language, html_lang = %q, %q (%v), want en, en-GB", language, htmlLang, err)
+	}
+}
+
+func TestClaimReturnsRetryCount(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "retry.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	url := "https://example.com/flaky"
+	if err := store.AddToQueue([]string{url}); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for want := 0; want < 3; want++ {
+		item, err := store.GetNextFromQueue()
+		if err != nil || item == nil {
+			t.Fatalf("Failed to dequeue: %v", err)
+		}
+		if item.RetryCount != want {
+			t.Errorf("Claim %d: RetryCount = %d, want %d", want+1, item.RetryCount, want)
+		}
+		if err := store.SavePageError(item.ID, crawler.ErrorTypeServerError, "HTTP 503"); err != nil {
+			t.Fatalf("Failed to save page error: %v", err)
+		}
+		if want == 2 {
+			break
+		}
+		if n, err := store.RequeueErrorPages(3); err != nil || n != 1 {
+			t.Fatalf("RequeueErrorPages = %d, %v; want 1", n, err)
+		}
+	}
+	if n, err := store.RequeueErrorPages(3); err != nil || n != 0 {
+		t.Errorf("RequeueErrorPages after three failures = %d, %v; want 0", n, err)
+	}
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+// SchemaCoverage lists the structured data types of an HTML page and the
+// expected types it lacks
+type SchemaCoverage struct {
+	URL     string   `json:"url"`
+	Types   []string `json:"types"`   // Types declared by the page, in document order
+	Missing []string `json:"missing"` // Expected types the page does not declare
+}
+
+// savePageStructuredData replaces the structured data stored for a page
+func (s *SQLiteStorage) savePageStructuredData(tx *sql.Tx, pageID int, items []crawler.StructuredData) error {
+	if _, err := tx.Exec("DELETE FROM page_structured_data WHERE page_id = ?", pageID); err != nil {
+		return fmt.Errorf("failed to clear page structured data: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM page_schema_types WHERE page_id = ?", pageID); err != nil {
+		return fmt.Errorf("failed to clear page schema types: %w", err)
+	}
+
+	for i, item := range items {
+		var payload any
+		if item.Payload != "" {
+			encrypted, err := s.encryptField(item.Payload)
+			if err != nil {
+				return err
+			}
+			payload = encrypted
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO page_structured_data (page_id, position, format, payload, valid) VALUES (?, ?, ?, ?, ?)",
+			pageID, i, item.Format, payload, item.Valid,
+		); err != nil {
+			return fmt.Errorf("failed to save page structured data: %w", err)
+		}
+		for _, schemaType := range item.Types {
+			if _, err := tx.Exec(
+				"INSERT OR IGNORE INTO page_schema_types (page_id, position, schema_type) VALUES (?, ?, ?)",
+				pageID, i, schemaType,
+			); err != nil {
+				return fmt.Errorf("failed to save page schema type: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetPageStructuredData returns the structured data of a page in document order
+func (s *SQLiteStorage) GetPageStructuredData(url string) ([]crawler.StructuredData, error) {
+	rows, err := s.db.Query(`
+		SELECT sd.position, sd.format, COALESCE(sd.payload, ''), sd.valid, COALESCE(st.schema_type, '')
+		FROM page_structured_data sd
+		JOIN pages p ON sd.page_id = p.id
+		LEFT JOIN page_schema_types st ON st.page_id = sd.page_id AND st.position = sd.position
+		WHERE p.url = ?
+		ORDER BY sd.position, st.rowid
+	`, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page structured data: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []crawler.StructuredData
+	last := -1
+	for rows.Next() {
+		var position int
+		var item crawler.StructuredData
+		var schemaType string
+		if err := rows.Scan(&position, &item.Format, &item.Payload, &item.Valid, &schemaType); err != nil {
+			return nil, fmt.Errorf("failed to scan page structured data: %w", err)
+		}
+		if position != last {
+			if item.Payload, err = s.DecryptField(item.Payload); err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			last = position
+		}
+		if schemaType != "" {
+			current := &items[len(items)-1]
+			current.Types = append(current.Types, schemaType)
+		}
+	}
+	return items, rows.Err()
+}
+
+// GetSchemaCoverage returns the HTML pages lacking any of the expected
+// structured data types, with the types they do declare. With no expected
+// types it returns the pages that declare no types at all.
+func (s *SQLiteStorage) GetSchemaCoverage(expected []string) ([]SchemaCoverage, error) {
+	// page_content marks the pages that were parsed as HTML
+	rows, err := s.db.Query(`
+		SELECT p.url, COALESCE(st.schema_type, '')
+		FROM page_content pc
+		JOIN pages p ON pc.page_id = p.id
+		LEFT JOIN page_schema_types st ON st.page_id = pc.page_id
+		ORDER BY p.url, st.position, st.rowid
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page schema types: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var pages []SchemaCoverage
+	for rows.Next() {
+		var url, schemaType string
+		if err := rows.Scan(&url, &schemaType); err != nil {
+			return nil, fmt.Errorf("failed to scan page schema type: %w", err)
+		}
+		if len(pages) == 0 || pages[len(pages)-1].URL != url {
+			pages = append(pages, SchemaCoverage{URL: url, Types: []string{}})
+		}
+		page := &pages[len(pages)-1]
+		if schemaType != "" && !containsString(page.Types, schemaType) {
+			page.Types = append(page.Types, schemaType)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var report []SchemaCoverage
+	for _, page := range pages {
+		page.Missing = []string{}
+		for _, schemaType := range expected {
+			if !containsString(page.Types, schemaType) {
+				page.Missing = append(page.Missing, schemaType)
+			}
+		}
+		if len(page.Missing) > 0 || (len(expected) == 0 && len(page.Types) == 0) {
+			report = append(report, page)
+		}
+	}
+	return report, nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
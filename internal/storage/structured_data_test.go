@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestPageStructuredData(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "structured.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	pages := map[string][]crawler.StructuredData{
+		"https://example.com/product": {
+			{Format: "json-ld", Types: []string{"Product"}, Payload: `{"@type":"Product"}`, Valid: true},
+			{Format: "microdata", Types: []string{"BreadcrumbList", "ItemList"}, Valid: true},
+		},
+		"https://example.com/broken": {
+			{Format: "json-ld", Payload: `{"@type":`, Valid: false},
+		},
+		"https://example.com/plain": nil,
+	}
+	var urls []string
+	for url := range pages {
+		urls = append(urls, url)
+	}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range urls {
+		item, err := store.GetNextFromQueue()
+		if err != nil || item == nil {
+			t.Fatalf("Failed to dequeue: %v", err)
+		}
+		page := &crawler.PageData{
+			URL:         item.URL,
+			StatusCode:  200,
+			HTTPHeaders: map[string]string{},
+			CrawledAt:   time.Now(),
+			Text:        &crawler.PageText{WordCount: 10},
+			Structured:  pages[item.URL],
+		}
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+
+	items, err := store.GetPageStructuredData("https://example.com/product")
+	if err != nil {
+		t.Fatalf("Failed to get structured data: %v", err)
+	}
+	if !reflect.DeepEqual(items, pages["https://example.com/product"]) {
+		t.Errorf("Unexpected structured data: %+v", items)
+	}
+
+	// Without expected types: pages declaring no types at all
+	report, err := store.GetSchemaCoverage(nil)
+	if err != nil {
+		t.Fatalf("Failed to get schema coverage: %v", err)
+	}
+	if len(report) != 2 || report[0].URL != "https://example.com/broken" || report[1].URL != "https://example.com/plain" {
+		t.Errorf("Unexpected pages without types: %+v", report)
+	}
+
+	report, err = store.GetSchemaCoverage([]string{"Product", "BreadcrumbList"})
+	if err != nil {
+		t.Fatalf("Failed to get schema coverage: %v", err)
+	}
+	if len(report) != 2 {
+		t.Fatalf("Expected 2 pages missing types, got %+v", report)
+	}
+	if !reflect.DeepEqual(report[0].Missing, []string{"Product", "BreadcrumbList"}) {
+		t.Errorf("Unexpected missing types: %+v", report[0])
+	}
+
+	report, err = store.GetSchemaCoverage([]string{"ItemList"})
+	if err != nil {
+		t.Fatalf("Failed to get schema coverage: %v", err)
+	}
+	for _, page := range report {
+		if page.URL == "https://example.com/product" {
+			t.Errorf("Expected the product page to declare ItemList, got %+v", page)
+		}
+	}
+
+	// Recrawling replaces the stored items
+	if err := store.SavePageResult(1, &crawler.PageData{StatusCode: 200, HTTPHeaders: map[string]string{}, CrawledAt: time.Now()}); err != nil {
+		t.Fatalf("Failed to clear structured data: %v", err)
+	}
+	var count int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM page_schema_types WHERE page_id = 1").Scan(&count); err != nil || count != 0 {
+		t.Errorf("Expected schema types to be cleared, got %d (err %v)", count, err)
+	}
+}
@@ -0,0 +1,133 @@
+// Package storage — database version tracking.
+//
+// Every database records the schema version it was last written with and the
+// tool version of the last write session in crawl_meta. A binary refuses to
+// open a database whose schema is newer than it understands, because writing
+// to it could silently corrupt columns or statuses it does not know about.
+// Older schemas are upgraded by the versioned migration steps in migrate.go,
+// applied only by `linktadoru db migrate` and crawls (MigrateSQLiteStorage).
+// Every other open refuses them with ErrSchemaOutdated; reporting commands
+// also open databases read-only (OpenSQLiteStorageReadOnly), so inspecting an
+// archived crawl never changes it.
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// SchemaVersion is the database schema version written by this build.
+//
+//	1: original pages/link_relations/crawl_errors/crawl_meta schema
+//	2: 'discovered' page status (issue #46)
+//	3: page_alternates table and feed_pages view
+//	4: external_checks table and external_link_status view
+//	5: assets view
+//	6: page_content table
+//	7: page_headings table and heading_issues view
+//	8: page_rels table and page_alternates.source
+//	9: result tables optionally kept in an attached results database
+//	10: page_structured_data and page_schema_types tables
+//	11: images table
+//	12: host_summary, status_summary, errors_by_type and daily_crawl_counts views
+//	13: page_assets view
+//	14: pages.x_robots_tag and page_indexability view
+//	15: canonical_pages view
+//	16: pages.host generated column and host_frontier table
+//	17: page_metrics table (PageRank)
+//	18: page_metrics.click_depth
+//	19: redirects table
+//	20: pages.claimed_by and crawl_processes table
+//	21: crawl_sessions table
+//	22: page_extractions table
+//	23: pages.rendered
+//	24: tls_certificates table
+//	25: page_accessibility table
+//	26: page_content.simhash
+//	27: page_content.language and page_content.html_lang
+//	28: host_frontier.pending and host_frontier.inflight, kept by triggers
+const SchemaVersion = 28
+
+const (
+	metaSchemaVersion = "schema_version"
+	metaToolVersion   = "tool_version"
+)
+
+// ErrSchemaTooNew is returned when a database was written by a newer, incompatible build
+var ErrSchemaTooNew = errors.New("database schema is newer than this version of linktadoru supports")
+
+// ErrSchemaOutdated is returned when a database was written with an older
+// schema that has to be upgraded with `linktadoru db migrate` first
+var ErrSchemaOutdated = errors.New("database schema is older than this version of linktadoru uses")
+
+// storedSchemaVersion returns the schema version recorded in crawl_meta, or 0
+// for a fresh database or one created before versions were recorded
+func (s *SQLiteStorage) storedSchemaVersion() (int, error) {
+	var name string
+	err := s.db.QueryRow(
+		"SELECT name FROM sqlite_master WHERE type='table' AND name='crawl_meta'",
+	).Scan(&name)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to check crawl_meta table: %w", err)
+	}
+
+	value, err := s.GetMeta(metaSchemaVersion)
+	if err != nil || value == "" {
+		return 0, err
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid schema_version %q in crawl_meta: %w", value, err)
+	}
+	return version, nil
+}
+
+// checkSchemaVersion refuses databases written with a newer schema
+func (s *SQLiteStorage) checkSchemaVersion() error {
+	stored, err := s.storedSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if stored > SchemaVersion {
+		return fmt.Errorf("%w (database schema %d, supported %d): upgrade linktadoru to open this database",
+			ErrSchemaTooNew, stored, SchemaVersion)
+	}
+	return nil
+}
+
+// checkSchemaOutdated refuses existing databases written with an older schema.
+// A database without a pages table is fresh and has nothing to upgrade.
+func (s *SQLiteStorage) checkSchemaOutdated() error {
+	exists, err := s.mainTableExists("pages")
+	if err != nil || !exists {
+		return err
+	}
+	stored, err := s.storedSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if stored < SchemaVersion {
+		return fmt.Errorf("%w (database schema %d, current %d): run `linktadoru db migrate` to upgrade it",
+			ErrSchemaOutdated, stored, SchemaVersion)
+	}
+	return nil
+}
+
+// RecordToolVersion stores the version of the binary starting a write session
+// and returns the version recorded by the previous session ("" if none), so
+// callers can warn when a database changes hands between releases.
+func (s *SQLiteStorage) RecordToolVersion(toolVersion string) (previous string, err error) {
+	previous, err = s.GetMeta(metaToolVersion)
+	if err != nil {
+		return "", err
+	}
+	if err := s.SetMeta(metaToolVersion, toolVersion); err != nil {
+		return "", err
+	}
+	return previous, nil
+}
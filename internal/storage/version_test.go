@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSchemaVersionRecorded(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "version.db")
+
+	store, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	value, err := store.GetMeta(metaSchemaVersion)
+	if err != nil {
+		t.Fatalf("Failed to read schema version: %v", err)
+	}
+	if value != strconv.Itoa(SchemaVersion) {
+		t.Errorf("Expected schema version %d, got %q", SchemaVersion, value)
+	}
+
+	// Simulate a database written by a newer build
+	if err := store.SetMeta(metaSchemaVersion, strconv.Itoa(SchemaVersion+1)); err != nil {
+		t.Fatalf("Failed to set schema version: %v", err)
+	}
+	_ = store.Close()
+
+	if _, err := NewSQLiteStorage(dbPath); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Expected ErrSchemaTooNew, got %v", err)
+	}
+}
+
+func TestRecordToolVersion(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "tool.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	previous, err := store.RecordToolVersion("v1.0.0")
+	if err != nil || previous != "" {
+		t.Errorf("Expected no previous version, got %q (err %v)", previous, err)
+	}
+
+	previous, err = store.RecordToolVersion("v1.1.0")
+	if err != nil || previous != "v1.0.0" {
+		t.Errorf("Expected previous version v1.0.0, got %q (err %v)", previous, err)
+	}
+}
+
+func TestMigrationsApplyNewerSteps(t *testing.T) {
+	for i, m := range migrations {
+		if m.version > SchemaVersion || (i > 0 && m.version <= migrations[i-1].version) {
+			t.Errorf("Migration %q has out-of-order version %d", m.name, m.version)
+		}
+	}
+
+	dbPath := filepath.Join(t.TempDir(), "migrate.db")
+	store, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	if applied := store.AppliedMigrations(); len(applied) != 0 {
+		t.Errorf("Expected no migrations on a fresh database, got %v", applied)
+	}
+
+	// A database last written at schema 13 runs only the later steps
+	if err := store.SetMeta(metaSchemaVersion, "13"); err != nil {
+		t.Fatalf("Failed to set schema version: %v", err)
+	}
+	_ = store.Close()
+
+	// Opening the database does not migrate it
+	if _, err := NewSQLiteStorage(dbPath); !errors.Is(err, ErrSchemaOutdated) {
+		t.Fatalf("Expected ErrSchemaOutdated, got %v", err)
+	}
+
+	store, err = MigrateSQLiteStorage(dbPath, "", "")
+	if err != nil {
+		t.Fatalf("Failed to migrate storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	var want []string
+	for _, m := range migrations {
+		if m.version > 13 {
+			want = append(want, m.name)
+		}
+	}
+	if applied := store.AppliedMigrations(); !slices.Equal(applied, want) {
+		t.Errorf("Expected migrations %v, got %v", want, applied)
+	}
+	if value, _ := store.GetMeta(metaSchemaVersion); value != strconv.Itoa(SchemaVersion) {
+		t.Errorf("Expected schema version %d after migrating, got %q", SchemaVersion, value)
+	}
+}
+
+func TestOpenSQLiteStorageReadOnly(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "readonly.db")
+
+	store, err := NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_ = store.AddToQueue([]string{"https://example.com/"})
+	_ = store.Close()
+
+	reader, err := OpenSQLiteStorageReadOnly(dbPath, "", "")
+	if err != nil {
+		t.Fatalf("Failed to open read-only: %v", err)
+	}
+	if status, _ := reader.GetURLStatus("https://example.com/"); status != "pending" {
+		t.Errorf("Expected the queued page to be readable, got status %q", status)
+	}
+	if err := reader.SetMeta(metaSchemaVersion, "13"); err == nil {
+		t.Error("Expected a read-only database to refuse writes")
+	}
+	_ = reader.Close()
+
+	// An older schema is refused with guidance, not upgraded
+	store, err = NewSQLiteStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+	_ = store.SetMeta(metaSchemaVersion, "13")
+	_ = store.Close()
+
+	_, err = OpenSQLiteStorageReadOnly(dbPath, "", "")
+	if !errors.Is(err, ErrSchemaOutdated) || !strings.Contains(err.Error(), "linktadoru db migrate") {
+		t.Errorf("Expected ErrSchemaOutdated naming db migrate, got %v", err)
+	}
+}
+
+func TestMigrateRollsBackFailedSteps(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "rollback.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	if err := store.SetMeta(metaSchemaVersion, "13"); err != nil {
+		t.Fatalf("Failed to set schema version: %v", err)
+	}
+
+	// A last step that fails after changing the schema
+	saved := migrations
+	defer func() { migrations = saved }()
+	migrations = append(slices.Clone(saved), migration{SchemaVersion, "failing step", func(tx *sql.Tx) error {
+		if _, err := tx.Exec("ALTER TABLE pages ADD COLUMN probe TEXT"); err != nil {
+			return err
+		}
+		return errors.New("step failed")
+	}})
+
+	if err := store.Migrate(); err == nil || !strings.Contains(err.Error(), "failing step") {
+		t.Fatalf("Expected the failing step's error, got %v", err)
+	}
+	if _, hasColumn, err := columnState(store.db, "pages", "probe"); err != nil || hasColumn {
+		t.Errorf("Expected the failed migration to be rolled back (%v)", err)
+	}
+	if value, _ := store.GetMeta(metaSchemaVersion); value != "13" {
+		t.Errorf("Expected schema version 13 after a failed migration, got %q", value)
+	}
+	if applied := store.AppliedMigrations(); len(applied) != 0 {
+		t.Errorf("Expected no applied migrations, got %v", applied)
+	}
+}
@@ -0,0 +1,119 @@
+// Package storage — page weight.
+//
+// With crawl_assets on, the stylesheets, scripts, images and icons a page
+// loads are fetched and linked to it as asset links. Adding their response
+// sizes to the page's own HTML gives the weight of the page, which
+// `linktadoru analyze weight` checks against the configured budgets.
+package storage
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Budgets exceeded by a page, as listed in PageWeight.OverBudget
+const (
+	WeightBudgetTotal      = "total"
+	WeightBudgetScript     = "script"
+	WeightBudgetStylesheet = "stylesheet"
+	WeightBudgetImage      = "image"
+)
+
+// PageWeightBudgets are the most bytes a page may weigh in total and per
+// kind of asset; 0 means no budget
+type PageWeightBudgets struct {
+	Total      int64
+	Script     int64
+	Stylesheet int64
+	Image      int64 // Images and icons
+}
+
+// PageWeight is the weight of a crawled HTML page and the assets it loads
+type PageWeight struct {
+	URL             string   `json:"url"`
+	HTMLBytes       int64    `json:"html_bytes"`
+	ScriptBytes     int64    `json:"script_bytes"`
+	StylesheetBytes int64    `json:"stylesheet_bytes"`
+	ImageBytes      int64    `json:"image_bytes"` // Images and icons
+	TotalBytes      int64    `json:"total_bytes"`
+	Assets          int      `json:"assets"`
+	Unmeasured      int      `json:"unmeasured"`  // Assets without a recorded size, e.g. not fetched or failed
+	OverBudget      []string `json:"over_budget"` // The WeightBudget constants of the budgets exceeded
+}
+
+// GetPageWeights returns the weight of every completed HTML page, heaviest
+// first. An asset loaded twice by a page counts once. Unless all is set,
+// only pages exceeding one of the budgets are returned.
+func (s *SQLiteStorage) GetPageWeights(budgets PageWeightBudgets, all bool) ([]PageWeight, error) {
+	rows, err := s.read.Query(`
+		SELECT p.url, COALESCE(p.content_type, ''), COALESCE(p.response_size_bytes, 0),
+		       COALESCE(SUM(CASE WHEN a.kind = 'script' THEN t.response_size_bytes END), 0),
+		       COALESCE(SUM(CASE WHEN a.kind = 'stylesheet' THEN t.response_size_bytes END), 0),
+		       COALESCE(SUM(CASE WHEN a.kind IN ('image', 'icon') THEN t.response_size_bytes END), 0),
+		       COUNT(a.target_page_id),
+		       COUNT(a.target_page_id) - COUNT(t.response_size_bytes)
+		FROM pages p
+		LEFT JOIN (
+			SELECT source_page_id, target_page_id, MIN(rel_attribute) AS kind
+			FROM link_relations
+			WHERE link_type = 'asset'
+			GROUP BY source_page_id, target_page_id
+		) a ON a.source_page_id = p.id
+		LEFT JOIN pages t ON t.id = a.target_page_id
+		WHERE p.status = 'completed'
+		GROUP BY p.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query page weights: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var weights []PageWeight
+	for rows.Next() {
+		var w PageWeight
+		var contentType string
+		if err := rows.Scan(&w.URL, &contentType, &w.HTMLBytes, &w.ScriptBytes, &w.StylesheetBytes,
+			&w.ImageBytes, &w.Assets, &w.Unmeasured); err != nil {
+			return nil, fmt.Errorf("failed to scan page weight: %w", err)
+		}
+		if !isHTMLContentType(contentType) {
+			continue
+		}
+		w.TotalBytes = w.HTMLBytes + w.ScriptBytes + w.StylesheetBytes + w.ImageBytes
+		w.OverBudget = budgets.exceeded(w)
+		if all || len(w.OverBudget) > 0 {
+			weights = append(weights, w)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read page weights: %w", err)
+	}
+
+	sort.Slice(weights, func(i, j int) bool {
+		if weights[i].TotalBytes != weights[j].TotalBytes {
+			return weights[i].TotalBytes > weights[j].TotalBytes
+		}
+		return weights[i].URL < weights[j].URL
+	})
+	return weights, nil
+}
+
+// exceeded lists the budgets a page is over
+func (b PageWeightBudgets) exceeded(w PageWeight) []string {
+	over := []string{}
+	for _, check := range []struct {
+		name   string
+		budget int64
+		bytes  int64
+	}{
+		{WeightBudgetTotal, b.Total, w.TotalBytes},
+		{WeightBudgetScript, b.Script, w.ScriptBytes},
+		{WeightBudgetStylesheet, b.Stylesheet, w.StylesheetBytes},
+		{WeightBudgetImage, b.Image, w.ImageBytes},
+	} {
+		if check.budget > 0 && check.bytes > check.budget {
+			over = append(over, check.name)
+		}
+	}
+	return over
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/masahif/linktadoru/internal/crawler"
+)
+
+func TestGetPageWeights(t *testing.T) {
+	store, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "weight.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	sizes := map[string]int64{
+		"https://example.com/":         10_000,
+		"https://example.com/light":    2_000,
+		"https://example.com/app.js":   300_000,
+		"https://example.com/site.css": 20_000,
+		"https://example.com/hero.jpg": 500_000,
+	}
+	urls := []string{}
+	for u := range sizes {
+		urls = append(urls, u)
+	}
+	if err := store.AddToQueue(urls); err != nil {
+		t.Fatalf("Failed to add to queue: %v", err)
+	}
+	for range sizes {
+		item, _ := store.GetNextFromQueue()
+		contentType := "text/html; charset=utf-8"
+		switch filepath.Ext(item.URL) {
+		case ".js":
+			contentType = "text/javascript"
+		case ".css":
+			contentType = "text/css"
+		case ".jpg":
+			contentType = "image/jpeg"
+		}
+		page := &crawler.PageData{URL: item.URL, StatusCode: 200, ResponseSize: sizes[item.URL],
+			HTTPHeaders: map[string]string{"content-type": contentType}, CrawledAt: time.Now()}
+		if err := store.SavePageResult(item.ID, page); err != nil {
+			t.Fatalf("Failed to save %s: %v", item.URL, err)
+		}
+	}
+	err = store.SaveLinks([]*crawler.LinkData{
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/app.js", LinkType: crawler.LinkTypeAsset, RelAttribute: "script"},
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/site.css", LinkType: crawler.LinkTypeAsset, RelAttribute: "stylesheet"},
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/hero.jpg", LinkType: crawler.LinkTypeAsset, RelAttribute: "image"},
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/hero.jpg", LinkType: crawler.LinkTypeAsset, RelAttribute: "image"},
+		{SourceURL: "https://example.com/", TargetURL: "https://cdn.example.net/font.css", LinkType: crawler.LinkTypeAsset, RelAttribute: "stylesheet"},
+		{SourceURL: "https://example.com/", TargetURL: "https://example.com/light", LinkType: "internal"},
+		{SourceURL: "https://example.com/light", TargetURL: "https://example.com/site.css", LinkType: crawler.LinkTypeAsset, RelAttribute: "stylesheet"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save links: %v", err)
+	}
+
+	weights, err := store.GetPageWeights(PageWeightBudgets{}, true)
+	if err != nil {
+		t.Fatalf("GetPageWeights failed: %v", err)
+	}
+	want := []PageWeight{
+		{URL: "https://example.com/", HTMLBytes: 10_000, ScriptBytes: 300_000, StylesheetBytes: 20_000, ImageBytes: 500_000,
+			TotalBytes: 830_000, Assets: 4, Unmeasured: 1, OverBudget: []string{}},
+		{URL: "https://example.com/light", HTMLBytes: 2_000, StylesheetBytes: 20_000,
+			TotalBytes: 22_000, Assets: 1, OverBudget: []string{}},
+	}
+	if !reflect.DeepEqual(weights, want) {
+		t.Errorf("Expected %+v, got %+v", want, weights)
+	}
+
+	// Only pages over a budget are returned unless all is set
+	weights, err = store.GetPageWeights(PageWeightBudgets{Total: 500_000, Script: 100_000, Stylesheet: 50_000}, false)
+	if err != nil {
+		t.Fatalf("GetPageWeights failed: %v", err)
+	}
+	if len(weights) != 1 || !reflect.DeepEqual(weights[0].OverBudget, []string{WeightBudgetTotal, WeightBudgetScript}) {
+		t.Errorf("Expected the home page over its total and script budgets, got %+v", weights)
+	}
+}
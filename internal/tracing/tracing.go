@@ -0,0 +1,74 @@
+// Package tracing exports OpenTelemetry traces of a crawl.
+//
+// The crawler creates spans for every page it processes, the HTTP request
+// with its DNS, connect and TLS phases, HTML parsing and the storage writes.
+// They go to the global tracer provider, a no-op until Setup installs an
+// OTLP/HTTP exporter, so an untraced crawl pays nothing for them.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ServiceName is the service.name of exported spans
+const ServiceName = "linktadoru"
+
+// tracesPath is where OTLP/HTTP collectors accept traces
+const tracesPath = "/v1/traces"
+
+// Setup exports traces to the OTLP/HTTP collector at endpoint, e.g.
+// http://localhost:4318, until the returned shutdown function is called; it
+// flushes the spans not yet sent. Traces are posted to /v1/traces unless the
+// endpoint has a path. The standard OTEL_EXPORTER_OTLP_* environment
+// variables (headers, timeout, compression) apply as well. An empty endpoint
+// disables tracing.
+func Setup(ctx context.Context, endpoint, version string) (shutdown func(context.Context) error, err error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	endpointURL, err := TracesURL(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpointURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(ServiceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// TracesURL returns the URL traces are posted to for an otlp_endpoint
+func TracesURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid OTLP endpoint %q: must be an http or https URL", endpoint)
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		u.Path = tracesPath
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTracesURL(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{"http://localhost:4318", "http://localhost:4318/v1/traces"},
+		{"http://localhost:4318/", "http://localhost:4318/v1/traces"},
+		{"https://collector.example.com/custom/traces", "https://collector.example.com/custom/traces"},
+	}
+	for _, tt := range tests {
+		got, err := TracesURL(tt.endpoint)
+		if err != nil || got != tt.want {
+			t.Errorf("TracesURL(%q) = %q, %v; want %q", tt.endpoint, got, err, tt.want)
+		}
+	}
+
+	for _, endpoint := range []string{"localhost:4318", "grpc://localhost:4317", "http://"} {
+		if _, err := TracesURL(endpoint); err == nil {
+			t.Errorf("TracesURL(%q) succeeded, want an error", endpoint)
+		}
+	}
+}
+
+func TestSetupDisabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), "", "test")
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown failed: %v", err)
+	}
+}